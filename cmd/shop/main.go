@@ -16,20 +16,77 @@ import (
 	"github.com/mimatache/go-shop/internal/http/health"
 	"github.com/mimatache/go-shop/internal/http/middleware"
 	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/internal/mail"
+	"github.com/mimatache/go-shop/internal/money"
+	"github.com/mimatache/go-shop/internal/rbac"
 	"github.com/mimatache/go-shop/internal/store"
 	"github.com/mimatache/go-shop/pkg/cart"
+	"github.com/mimatache/go-shop/pkg/cart/abandonment"
 	cartStore "github.com/mimatache/go-shop/pkg/cart/store"
-	"github.com/mimatache/go-shop/pkg/payments"
+	"github.com/mimatache/go-shop/pkg/orders"
+	ordersStore "github.com/mimatache/go-shop/pkg/orders/store"
+	paymentsAPI "github.com/mimatache/go-shop/pkg/payments"
+	"github.com/mimatache/go-shop/pkg/payments/provider"
+	paymentsStore "github.com/mimatache/go-shop/pkg/payments/store"
+	"github.com/mimatache/go-shop/pkg/payments/webhook"
 	"github.com/mimatache/go-shop/pkg/products"
 	productsStore "github.com/mimatache/go-shop/pkg/products/store"
+	"github.com/mimatache/go-shop/pkg/promotions"
+	promotionsStore "github.com/mimatache/go-shop/pkg/promotions/store"
+	"github.com/mimatache/go-shop/pkg/shipping"
+	"github.com/mimatache/go-shop/pkg/tax"
 	"github.com/mimatache/go-shop/pkg/users"
+	"github.com/mimatache/go-shop/pkg/users/lockout"
+	"github.com/mimatache/go-shop/pkg/users/password"
+	"github.com/mimatache/go-shop/pkg/users/sessions"
 	userStore "github.com/mimatache/go-shop/pkg/users/store"
+	"github.com/mimatache/go-shop/pkg/users/twofactor"
+	"github.com/mimatache/go-shop/pkg/users/verification"
+	"github.com/mimatache/go-shop/pkg/wallet"
+	walletStore "github.com/mimatache/go-shop/pkg/wallet/store"
+	"github.com/mimatache/go-shop/pkg/wishlist"
+	wishlistStore "github.com/mimatache/go-shop/pkg/wishlist/store"
+	wishlistLogic "github.com/mimatache/go-shop/pkg/wishlist/wishlist"
 )
 
 var (
-	userSeeds    *os.File
-	productSeeds *os.File
-	port         *string
+	userSeeds       *os.File
+	productSeeds    *os.File
+	couponSeeds     *os.File
+	taxRates        *os.File
+	shippingZones   *os.File
+	payments        *os.File
+	exchangeRates   *os.File
+	taxProvider     *string
+	paymentProvider *string
+	port            *string
+	adminKey        *string
+	webhookSecret   *string
+
+	abandonAfter        *time.Duration
+	expireAfter         *time.Duration
+	recoveryLifetime    *time.Duration
+	abandonmentInterval *time.Duration
+	outbox              *string
+	publicURL           *string
+
+	stockAlerts        *string
+	stockAlertInterval *time.Duration
+
+	authorizationExpiryInterval *time.Duration
+	webhookTolerance            *time.Duration
+	settlements                 *string
+	reconciliationInterval      *time.Duration
+	paymentResilience           provider.ResilienceConfig
+
+	passwordHashing password.Config
+
+	mailOutbox        *string
+	smtp              mail.SMTPConfig
+	emailVerification verification.Config
+	loginLockout      lockout.Config
+	secondFactor      twofactor.Config
+	userSessions      sessions.Config
 )
 
 func main() {
@@ -69,16 +126,49 @@ func main() {
 	// Starting DB instance
 	schema := store.NewSchema()
 	schema.AddToSchema(userStore.GetTable())
+	schema.AddToSchema(userStore.GetTokenTable())
+	schema.AddToSchema(userStore.GetAddressTable())
+	schema.AddToSchema(userStore.GetAttemptsTable())
+	schema.AddToSchema(userStore.GetAuditTable())
+	schema.AddToSchema(userStore.GetTwoFactorTable())
+	schema.AddToSchema(userStore.GetSessionTable())
+	schema.AddToSchema(userStore.GetRefreshTokenTable())
+	schema.AddToSchema(userStore.GetAPIKeyTable())
 	schema.AddToSchema(productsStore.GetTable())
 	schema.AddToSchema(cartStore.GetTable())
+	schema.AddToSchema(cartStore.GetRecoveryTable())
+	schema.AddToSchema(promotionsStore.GetTable())
+	schema.AddToSchema(promotionsStore.GetRedemptionTable())
+	schema.AddToSchema(wishlistStore.GetTable())
+	schema.AddToSchema(wishlistStore.GetAlertTable())
+	schema.AddToSchema(paymentsStore.GetTable())
+	schema.AddToSchema(paymentsStore.GetEventTable())
+	schema.AddToSchema(paymentsStore.GetLedgerTable())
+	schema.AddToSchema(paymentsStore.GetReportTable())
+	schema.AddToSchema(ordersStore.GetTable())
+	schema.AddToSchema(walletStore.GetTable())
+	schema.AddToSchema(walletStore.GetGiftCardTable())
+	schema.AddToSchema(walletStore.GetTenderTable())
 	db, err := store.New(schema)
 	if err != nil {
 		log.Errorf("could not start DB %v", err)
 		return
 	}
 
+	// Starting the API keys, used by the authorization middlewares of every API
+	userLogger := logger.WithFields(log, map[string]interface{}{"api": "users"})
+	apiKeys := users.NewAPIKeys(userLogger, db)
+	userHandler := middleware.UserAuthorization(apiKeys)
+	adminHandler := middleware.Permissions(rbac.DefaultPolicy, *adminKey, apiKeys)
+
+	hasher, err := password.New(passwordHashing)
+	if err != nil {
+		log.Errorf("could not configure password hashing %v", err)
+		return
+	}
+
 	// Loading the seeds to the DB
-	err = userStore.LoadSeeds(userSeeds, db)
+	err = userStore.LoadSeeds(userSeeds, db, hasher)
 	if err != nil {
 		log.Errorf("could not load seeds for user to DB %v", err)
 		return
@@ -88,18 +178,130 @@ func main() {
 		log.Errorf("could not load seeds for product to DB %v", err)
 		return
 	}
+	err = promotionsStore.LoadSeeds(couponSeeds, db)
+	if err != nil {
+		log.Errorf("could not load seeds for coupons to DB %v", err)
+		return
+	}
 
-	// Starting user API
-	userLogger := logger.WithFields(log, map[string]interface{}{"api": "users"})
-	users.NewAPI(userLogger, versionedRouter, db)
+	exchange, err := money.LoadRates(exchangeRates)
+	if err != nil {
+		log.Errorf("could not load exchange rates %v", err)
+		return
+	}
 
 	// Starting product API
 	productLogger := logger.WithFields(log, map[string]interface{}{"api": "products"})
-	productsAPI := products.NewAPI(productLogger, db)
+	productsAPI := products.NewAPI(productLogger, db, exchange)
+
+	// Starting promotions API
+	promotionsLogger := logger.WithFields(log, map[string]interface{}{"api": "promotions"})
+	promotionsAPI := promotions.NewAPI(promotionsLogger, db)
+
+	// Starting tax API
+	taxLogger := logger.WithFields(log, map[string]interface{}{"api": "tax"})
+	taxAPI, err := tax.NewAPI(taxLogger, *taxProvider, taxRates)
+	if err != nil {
+		log.Errorf("could not start tax API %v", err)
+		return
+	}
+
+	// Starting shipping API
+	shippingLogger := logger.WithFields(log, map[string]interface{}{"api": "shipping"})
+	shippingAPI, err := shipping.NewAPI(shippingLogger, shippingZones)
+	if err != nil {
+		log.Errorf("could not start shipping API %v", err)
+		return
+	}
+
+	// Starting payments API
+	paymentsLogger := logger.WithFields(log, map[string]interface{}{"api": "payments"})
+	paymentAPI, paymentLedger, err := paymentsAPI.NewAPI(paymentsLogger, *paymentProvider, payments, paymentResilience, db, versionedRouter, adminHandler, healthProbes)
+	if err != nil {
+		log.Errorf("could not start payments API %v", err)
+		return
+	}
+	paymentAPI.Run(ctx, *authorizationExpiryInterval)
+	if err := os.MkdirAll(*settlements, 0755); err != nil {
+		log.Errorf("could not open settlements directory %v", err)
+		return
+	}
+	paymentLedger.Run(ctx, *settlements, *reconciliationInterval)
+
+	// Starting wallet API
+	walletLogger := logger.WithFields(log, map[string]interface{}{"api": "wallet"})
+	walletAPI := wallet.NewAPI(walletLogger, db, versionedRouter, userHandler, adminHandler)
+
+	// Starting orders API
+	ordersLogger := logger.WithFields(log, map[string]interface{}{"api": "orders"})
+	ordersAPI := orders.NewAPI(ordersLogger, paymentAPI, productsAPI, walletAPI, db, versionedRouter, userHandler, adminHandler)
+	paymentsAPI.NewWebhookAPI(paymentsLogger, *webhookSecret, *webhookTolerance, paymentAPI, ordersAPI, db, versionedRouter)
+
+	// Starting the address book, used both by the cart and the user API
+	addressBook := users.NewAddressBook(userLogger, db)
 
 	// Starting cart API
 	cartLogger := logger.WithFields(log, map[string]interface{}{"api": "cart"})
-	cart.NewAPI(cartLogger, productsAPI, payments.New(), db, versionedRouter, middleware.JWTAuthorization)
+	cartAPI := cart.NewAPI(
+		cartLogger,
+		productsAPI,
+		paymentAPI,
+		walletAPI,
+		ordersAPI,
+		promotionsAPI,
+		taxAPI,
+		shippingAPI,
+		addressBook,
+		exchange,
+		db,
+		versionedRouter,
+		userHandler,
+		middleware.GuestAuthorization(apiKeys),
+	)
+
+	// Starting abandoned cart monitor
+	notifier, err := abandonment.NewFileOutbox(*outbox)
+	if err != nil {
+		log.Errorf("could not open abandoned cart outbox %v", err)
+		return
+	}
+	monitor, err := cart.NewAbandonmentMonitor(cartLogger, db, notifier, abandonment.Config{
+		AbandonAfter:  *abandonAfter,
+		ExpireAfter:   *expireAfter,
+		TokenLifetime: *recoveryLifetime,
+		RecoveryURL:   *publicURL,
+	})
+	if err != nil {
+		log.Errorf("could not start abandoned cart monitor %v", err)
+		return
+	}
+	monitor.Run(ctx, *abandonmentInterval)
+
+	// Starting wishlist API
+	wishlistLogger := logger.WithFields(log, map[string]interface{}{"api": "wishlist"})
+	wishlist.NewAPI(wishlistLogger, productsAPI, cartAPI, db, versionedRouter, userHandler)
+	stockNotifier, err := wishlistLogic.NewFileOutbox(*stockAlerts)
+	if err != nil {
+		log.Errorf("could not open back in stock outbox %v", err)
+		return
+	}
+	wishlist.NewWatcher(wishlistLogger, productsAPI, db, stockNotifier).Run(ctx, *stockAlertInterval)
+
+	// Starting user API
+	var sender mail.Sender = mail.NewSMTP(smtp)
+	if smtp.Address == "" {
+		sender, err = mail.NewOutbox(*mailOutbox)
+		if err != nil {
+			log.Errorf("could not open mail outbox %v", err)
+			return
+		}
+	}
+	emailVerification.PublicURL = *publicURL
+	_, err = users.NewAPI(userLogger, versionedRouter, db, cartAPI, addressBook, apiKeys, db, hasher, sender, emailVerification, loginLockout, secondFactor, userSessions, userHandler, adminHandler)
+	if err != nil {
+		log.Errorf("could not start users API %v", err)
+		return
+	}
 
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%s", *port),
@@ -125,10 +327,60 @@ func main() {
 func readFlagValues(log logger.Logger) {
 	var err error
 	port = flag.String("port", "9090", "Port of server")
+	adminKey = flag.String("admin-key", os.Getenv("SHOP_ADMIN_KEY"), "key expected in the "+middleware.AdminKeyHeader+" header of administrative requests; administrative routes are disabled when empty")
 	userSeedsFile := flag.String("users", "data/users.json", "seed users to store")
 	productSeedsFile := flag.String("products", "data/products.json", "seed products to store")
+	couponSeedsFile := flag.String("coupons", "data/coupons.json", "seed coupons to store")
+	taxRatesFile := flag.String("taxes", "data/taxes.json", "tax rates used by the table tax provider")
+	shippingZonesFile := flag.String("shipping", "data/shipping.json", "shipping zones and methods")
+	exchangeRatesFile := flag.String("exchange-rates", "data/exchange-rates.json", "base currency of the prices and the exchange rates of the other currencies the shop sells in")
+	taxProvider = flag.String("tax-provider", tax.TableProvider, "tax provider to use: table or stub")
+	paymentsFile := flag.String("payments", "data/payments.json", "configuration of the payment provider: the script of the fake provider or the address of the HTTP provider")
+	paymentProvider = flag.String("payment-provider", paymentsAPI.FakeProvider, "payment provider to use: fake or http")
+	abandonAfter = flag.Duration("abandon-after", 24*time.Hour, "idle time after which a cart is considered abandoned")
+	expireAfter = flag.Duration("expire-after", 30*24*time.Hour, "idle time after which a cart is removed")
+	recoveryLifetime = flag.Duration("recovery-lifetime", 7*24*time.Hour, "how long an abandoned cart recovery link can be used")
+	abandonmentInterval = flag.Duration("abandonment-interval", 10*time.Minute, "how often carts are checked for abandonment")
+	outbox = flag.String("outbox", "outbox/abandoned-carts.jsonl", "file where abandoned cart events are written")
+	publicURL = flag.String("public-url", "http://localhost:9090", "public address of the shop, used in recovery links")
+	stockAlerts = flag.String("stock-alerts", "outbox/back-in-stock.jsonl", "file where back in stock events are written")
+	stockAlertInterval = flag.Duration("stock-alert-interval", 10*time.Minute, "how often wishlisted products are checked for stock")
+	authorizationExpiryInterval = flag.Duration("authorization-expiry-interval", 10*time.Minute, "how often payment authorizations are checked for expiry")
+	settlements = flag.String("settlements", "settlements", "directory where the settlement files of the payment provider are dropped for reconciliation")
+	reconciliationInterval = flag.Duration("reconciliation-interval", time.Hour, "how often the settlements directory is checked for new files")
+	flag.DurationVar(&paymentResilience.AttemptTimeout, "payment-attempt-timeout", 2*time.Second, "how long a single call to the payment provider can take")
+	flag.DurationVar(&paymentResilience.Deadline, "payment-deadline", 5*time.Second, "how long a call to the payment provider can take, retries included")
+	flag.IntVar(&paymentResilience.Attempts, "payment-attempts", 3, "how many times a call to an unavailable payment provider is made")
+	flag.IntVar(&paymentResilience.FailureThreshold, "payment-failure-threshold", 5, "how many calls to the payment provider can fail in a row before the calls are paused")
+	flag.DurationVar(&paymentResilience.OpenFor, "payment-pause", 30*time.Second, "how long the calls to a failing payment provider are paused")
+	webhookSecret = flag.String("webhook-secret", os.Getenv("SHOP_WEBHOOK_SECRET"), "secret the payment provider signs its events with; events are rejected when empty")
+	webhookTolerance = flag.Duration("webhook-tolerance", webhook.DefaultTolerance, "how old the signature of a payment provider event can be")
+	flag.StringVar(&passwordHashing.Algorithm, "password-hash", password.Argon2id, "algorithm passwords are hashed with: argon2id or bcrypt; passwords hashed otherwise are rehashed on login")
+	flag.IntVar(&passwordHashing.BcryptCost, "bcrypt-cost", 10, "cost of the bcrypt password hashes")
+	argon2Time := flag.Uint("argon2-time", 1, "number of passes of the argon2id password hashes")
+	argon2Memory := flag.Uint("argon2-memory", 64*1024, "memory used by the argon2id password hashes, in KiB")
+	argon2Threads := flag.Uint("argon2-threads", 4, "number of threads used by the argon2id password hashes")
+	mailOutbox = flag.String("mail-outbox", "outbox/mail.jsonl", "file where emails are written when no SMTP server is set")
+	flag.StringVar(&smtp.Address, "smtp-addr", "", "host and port of the SMTP server emails are sent through; emails are written to the mail outbox when empty")
+	flag.StringVar(&smtp.Username, "smtp-username", "", "username to authenticate to the SMTP server with; no authentication is made when empty")
+	flag.StringVar(&smtp.Password, "smtp-password", os.Getenv("SHOP_SMTP_PASSWORD"), "password to authenticate to the SMTP server with")
+	flag.StringVar(&smtp.From, "mail-from", "shop@localhost", "address emails are sent from")
+	flag.DurationVar(&emailVerification.ResetLifetime, "reset-token-lifetime", time.Hour, "how long a password reset token can be used")
+	flag.DurationVar(&emailVerification.VerificationLifetime, "verification-token-lifetime", 48*time.Hour, "how long an email verification token can be used")
+	flag.UintVar(&loginLockout.AccountFailures, "login-max-failures", 5, "how many logins can fail for an account before it is locked out")
+	flag.UintVar(&loginLockout.IPFailures, "login-max-ip-failures", 20, "how many logins can fail from an IP address before it is locked out")
+	flag.DurationVar(&loginLockout.BaseDelay, "login-backoff", time.Second, "how long to wait after a failed login; the wait doubles with every failure")
+	flag.DurationVar(&loginLockout.MaxDelay, "login-max-backoff", time.Minute, "longest wait between failed logins")
+	flag.DurationVar(&loginLockout.LockoutDuration, "lockout-duration", 15*time.Minute, "how long an account or IP address is locked out, unless an administrator unlocks it")
+	flag.DurationVar(&loginLockout.ResetAfter, "login-failures-reset", time.Hour, "how long after the last failed login the failures are forgotten")
+	flag.StringVar(&secondFactor.Issuer, "totp-issuer", "go-shop", "name of the shop in the authenticator apps of the users with two-factor authentication")
+	flag.DurationVar(&userSessions.RefreshLifetime, "refresh-token-lifetime", 30*24*time.Hour, "how long a refresh token can be used; every refresh extends the session by as much")
 	flag.Parse()
 
+	passwordHashing.Argon2Time = uint32(*argon2Time)
+	passwordHashing.Argon2Memory = uint32(*argon2Memory)
+	passwordHashing.Argon2Threads = uint8(*argon2Threads)
+
 	log.Infof("Reading user seed file: %s", *userSeedsFile)
 	userSeeds, err = os.Open(*userSeedsFile)
 	if err != nil {
@@ -142,4 +394,39 @@ func readFlagValues(log logger.Logger) {
 		log.Errorf("could not read contents of seed file: %v", err)
 		os.Exit(1)
 	}
+
+	log.Infof("Reading coupon seed file: %s", *couponSeedsFile)
+	couponSeeds, err = os.Open(*couponSeedsFile)
+	if err != nil {
+		log.Errorf("could not read contents of seed file: %v", err)
+		os.Exit(1)
+	}
+
+	log.Infof("Reading tax rates file: %s", *taxRatesFile)
+	taxRates, err = os.Open(*taxRatesFile)
+	if err != nil {
+		log.Errorf("could not read contents of tax rates file: %v", err)
+		os.Exit(1)
+	}
+
+	log.Infof("Reading payments file: %s", *paymentsFile)
+	payments, err = os.Open(*paymentsFile)
+	if err != nil {
+		log.Errorf("could not read contents of payments file: %v", err)
+		os.Exit(1)
+	}
+
+	log.Infof("Reading shipping zones file: %s", *shippingZonesFile)
+	shippingZones, err = os.Open(*shippingZonesFile)
+	if err != nil {
+		log.Errorf("could not read contents of shipping zones file: %v", err)
+		os.Exit(1)
+	}
+
+	log.Infof("Reading exchange rates file: %s", *exchangeRatesFile)
+	exchangeRates, err = os.Open(*exchangeRatesFile)
+	if err != nil {
+		log.Errorf("could not read contents of exchange rates file: %v", err)
+		os.Exit(1)
+	}
 }
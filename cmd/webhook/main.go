@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/mimatache/go-shop/pkg/payments/webhook"
+)
+
+func main() {
+	url := flag.String("url", "http://localhost:9090/api/v1/payments/webhooks", "address of the webhook receiver")
+	secret := flag.String("secret", os.Getenv("SHOP_WEBHOOK_SECRET"), "secret the event is signed with")
+	eventType := flag.String("type", string(webhook.AuthorizationSucceeded), "type of the event: authorization.succeeded, authorization.failed or authorization.expired")
+	authorizationID := flag.String("authorization", "", "ID of the authorization the event refers to")
+	eventID := flag.String("id", "", "ID of the event; a random one is used when empty. Repeat an ID to simulate a repeated delivery")
+	expiresAt := flag.Duration("expires-in", 0, "validity of a confirmed authorization; the current one is kept when 0")
+	skew := flag.Duration("skew", 0, "how far in the past the event is signed, to simulate a delayed delivery")
+
+	flag.Parse()
+
+	if *authorizationID == "" {
+		fmt.Println("the authorization must be given")
+		os.Exit(1)
+	}
+	if *eventID == "" {
+		*eventID = newEventID()
+	}
+
+	sentAt := time.Now().Add(-*skew)
+	event := &webhook.Event{
+		ID:      *eventID,
+		Type:    webhook.EventType(*eventType),
+		Created: sentAt,
+		Data:    webhook.Data{AuthorizationID: *authorizationID},
+	}
+	if *expiresAt != 0 {
+		event.Data.ExpiresAt = sentAt.Add(*expiresAt)
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		panic(err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, *url, bytes.NewReader(body))
+	if err != nil {
+		panic(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(*secret, sentAt, body))
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		fmt.Printf("could not send event %s: %v\n", event.ID, err)
+		os.Exit(1)
+	}
+	defer resp.Body.Close()
+	response, _ := ioutil.ReadAll(resp.Body)
+	fmt.Printf("event %s: %s %s", event.ID, resp.Status, response)
+	if resp.StatusCode != http.StatusOK {
+		os.Exit(1)
+	}
+}
+
+func newEventID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return "evt_" + hex.EncodeToString(b)
+}
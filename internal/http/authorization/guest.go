@@ -0,0 +1,84 @@
+package authorization
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// This should be read from a secret/secret key and be given by a provider
+	guestKey      = "q3t6w9z$C&F)J@NcRfUjWnZr4u7x!A%D*G-KaPdSgVkYp2s5v8y/B?E(H+MbQeTh"
+	guestCookie   = "guest-cart"
+	guestPrefix   = "guest:"
+	guestLifetime = 30 * 24 * time.Hour
+	guestIDBytes  = 16
+)
+
+// NewGuestID generates a random identifier for an anonymous visitor
+func NewGuestID() (string, error) {
+	b := make([]byte, guestIDBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// GuestUserID returns the user ID under which the data of a guest is stored
+func GuestUserID(guestID string) string {
+	return guestPrefix + guestID
+}
+
+// IsGuest checks if a user ID belongs to an anonymous visitor
+func IsGuest(userID string) bool {
+	return strings.HasPrefix(userID, guestPrefix)
+}
+
+// SetGuestCookie adds a signed cookie identifying the guest to the response writer
+func SetGuestCookie(w http.ResponseWriter, guestID string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     guestCookie,
+		Value:    fmt.Sprintf("%s.%s", guestID, signGuestID(guestID)),
+		Path:     "/",
+		Expires:  time.Now().Add(guestLifetime),
+		HttpOnly: true,
+	})
+}
+
+// ClearGuestCookie removes the guest cookie from the client
+func ClearGuestCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     guestCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+}
+
+// GetGuestID extracts the guest ID from the guest cookie of the request, after verifying its signature
+func GetGuestID(r *http.Request) (string, error) {
+	c, err := r.Cookie(guestCookie)
+	if err != nil {
+		return "", err
+	}
+	parts := strings.SplitN(c.Value, ".", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", fmt.Errorf("malformed guest cookie")
+	}
+	if !hmac.Equal([]byte(parts[1]), []byte(signGuestID(parts[0]))) {
+		return "", fmt.Errorf("invalid guest cookie signature")
+	}
+	return parts[0], nil
+}
+
+func signGuestID(guestID string) string {
+	mac := hmac.New(sha256.New, []byte(guestKey))
+	_, _ = mac.Write([]byte(guestID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -6,13 +6,18 @@ import (
 	"time"
 
 	jwt "github.com/dgrijalva/jwt-go"
+
+	"github.com/mimatache/go-shop/internal/rbac"
 )
 
 const (
 	// This should be read from a secret/secret key and be given by a provider
-	jwtKey    = "Zq4t7w!z%C*F-J@NcRfUjXn2r5u8x/A?D(G+KbPdSgVkYp3s6v9y$B&E)H@McQfThWmZq4t7w!z%C*F-JaNdRgUkXn2r5u8x/A?D(G+KbPeShVmYq3s6v9y$B&E)H@Mc"
-	userIDKey = "user-id"
-	authToken = "auth-token"
+	jwtKey           = "Zq4t7w!z%C*F-J@NcRfUjXn2r5u8x/A?D(G+KbPdSgVkYp3s6v9y$B&E)H@McQfThWmZq4t7w!z%C*F-JaNdRgUkXn2r5u8x/A?D(G+KbPeShVmYq3s6v9y$B&E)H@Mc"
+	userIDKey        = "user-id"
+	authToken        = "auth-token"
+	partialAuthToken = "partial-auth-token"
+	// partialLifetime is how long users have to give their second factor after their password
+	partialLifetime = 5 * time.Minute
 )
 
 var blackListedTokens = map[string]struct{}{}
@@ -30,14 +35,19 @@ func CleanBlacklist(cleanInterval time.Duration) {
 // Claim uses the standard JWT Claim to create a custom claim
 type Claim struct {
 	Username string `json:"username"`
+	// Roles are the roles of the user when the token was generated
+	Roles []rbac.Role `json:"roles,omitempty"`
+	// Partial is set when the user still has to give their second factor. Such tokens only allow completing the login
+	Partial bool `json:"partial,omitempty"`
 	jwt.StandardClaims
 }
 
-// GenerateJWTToken generates a JWT token for the provided username
-func GenerateJWTToken(username string) (string, time.Time, error) {
+// GenerateJWTToken generates a JWT token for the provided username, holding its roles
+func GenerateJWTToken(username string, roles []rbac.Role) (string, time.Time, error) {
 	expirationTime := time.Now().Add(5 * time.Minute)
 	claims := &Claim{
 		Username: username,
+		Roles:    roles,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: expirationTime.Unix(),
 		},
@@ -47,6 +57,34 @@ func GenerateJWTToken(username string) (string, time.Time, error) {
 	return tokenString, expirationTime, err
 }
 
+// GeneratePartialToken generates a JWT token for the provided username, proving that the password was checked.
+// It only allows completing the login with the second factor
+func GeneratePartialToken(username string) (string, time.Time, error) {
+	expirationTime := time.Now().Add(partialLifetime)
+	claims := &Claim{
+		Username: username,
+		Partial:  true,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: expirationTime.Unix(),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(jwtKey))
+	return tokenString, expirationTime, err
+}
+
+// ValidatePartialToken returns the claim of a partial token, if it is valid and was not used yet
+func ValidatePartialToken(tknStr string) (*Claim, error) {
+	valid, claim, err := ValidateToken(tknStr)
+	if err != nil {
+		return nil, err
+	}
+	if !valid || !claim.Partial || IsBlacklisted(tknStr) {
+		return nil, fmt.Errorf("invalid token")
+	}
+	return claim, nil
+}
+
 // ValidateToken validates whether the value of the receoved key is a valid token
 func ValidateToken(tknStr string) (bool, *Claim, error) {
 	claim := &Claim{}
@@ -59,9 +97,15 @@ func ValidateToken(tknStr string) (bool, *Claim, error) {
 	return tkn.Valid, claim, nil
 }
 
-// AddUserIDHeader adds a header to the request that represents the user ID
+// AddUserIDHeader adds a header to the request that represents the user ID.
+// Any value sent by the client is overwritten, so the user ID cannot be spoofed
 func AddUserIDHeader(r *http.Request, claim *Claim) {
-	r.Header.Add(userIDKey, fmt.Sprint(claim.Username))
+	r.Header.Set(userIDKey, fmt.Sprint(claim.Username))
+}
+
+// AddGuestIDHeader adds a header to the request that represents the user ID of a guest
+func AddGuestIDHeader(r *http.Request, guestID string) {
+	r.Header.Set(userIDKey, GuestUserID(guestID))
 }
 
 // RemoveUserIDHeader removes the header that contains the user ID
@@ -78,15 +122,48 @@ func GetUserIDFromRequest(r *http.Request) (string, error) {
 	return id, nil
 }
 
-// SetAuthCookie add an auth cookie to the response writer
+// SetAuthCookie add an auth cookie to the response writer. Its path is set, so that logins completed under
+// /login/2fa give a cookie usable everywhere
 func SetAuthCookie(w http.ResponseWriter, tokenString string, expires time.Time) {
 	http.SetCookie(w, &http.Cookie{
 		Name:    authToken,
 		Value:   tokenString,
+		Path:    "/",
 		Expires: expires,
 	})
 }
 
+// SetPartialAuthCookie adds a cookie holding a partial token to the response writer
+func SetPartialAuthCookie(w http.ResponseWriter, tokenString string, expires time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     partialAuthToken,
+		Value:    tokenString,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+	})
+}
+
+// ClearPartialAuthCookie removes the partial token cookie from the client
+func ClearPartialAuthCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     partialAuthToken,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+}
+
+// GetPartialAuthToken extracts the partial token from a request
+func GetPartialAuthToken(r *http.Request) (string, error) {
+	c, err := r.Cookie(partialAuthToken)
+	if err != nil {
+		return "", err
+	}
+	return c.Value, nil
+}
+
 // GetAuthToken extracts the auth token from a request
 func GetAuthToken(r *http.Request) (string, error) {
 	c, err := r.Cookie(authToken)
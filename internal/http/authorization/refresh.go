@@ -0,0 +1,39 @@
+package authorization
+
+import (
+	"net/http"
+	"time"
+)
+
+const refreshToken = "refresh-token"
+
+// SetRefreshCookie adds a cookie holding the refresh token to the response writer. It cannot be read by scripts
+func SetRefreshCookie(w http.ResponseWriter, token string, expires time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     refreshToken,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+	})
+}
+
+// ClearRefreshCookie removes the refresh token cookie from the client
+func ClearRefreshCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     refreshToken,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+}
+
+// GetRefreshToken extracts the refresh token from a request
+func GetRefreshToken(r *http.Request) (string, error) {
+	c, err := r.Cookie(refreshToken)
+	if err != nil {
+		return "", err
+	}
+	return c.Value, nil
+}
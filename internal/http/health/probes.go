@@ -8,6 +8,9 @@ import (
 	"github.com/mimatache/go-shop/internal/http/helpers"
 )
 
+// MetricsSource defines the API for functions that report the counters of a piece of the system
+type MetricsSource func() interface{}
+
 // ConditionCheck defines the API for functions that check the condition of the system
 type ConditionCheck func() Condition
 
@@ -39,6 +42,7 @@ func NewAPI(app string, instance string) *Check {
 		instance:         instance,
 		healthConditions: []ConditionCheck{alwaysGood},
 		readyConditions:  []ConditionCheck{alwaysGood},
+		metrics:          map[string]MetricsSource{},
 	}
 }
 
@@ -48,6 +52,7 @@ type Check struct {
 	instance         string
 	healthConditions []ConditionCheck
 	readyConditions  []ConditionCheck
+	metrics          map[string]MetricsSource
 }
 
 // RegisterHealthCondition registers functions that determin whether the systeam is healthy
@@ -60,11 +65,17 @@ func (h *Check) RegisterReadynessCondition(condition ConditionCheck) {
 	h.readyConditions = append(h.readyConditions, condition)
 }
 
-// AddHandlersTo add the liveness, readiness and info handlers to the router
+// RegisterMetrics registers functions that report the counters of a piece of the system under the given name
+func (h *Check) RegisterMetrics(name string, source MetricsSource) {
+	h.metrics[name] = source
+}
+
+// AddHandlersTo add the liveness, readiness, metrics and info handlers to the router
 func (h *Check) AddHandlersTo(router *mux.Router) {
 	r := router.PathPrefix("/info").Subrouter()
 	r.HandleFunc("/alive", h.livenessHandler)
 	r.HandleFunc("/ready", h.readinessHandler)
+	r.HandleFunc("/metrics", h.metricsHandler)
 	r.HandleFunc("/", h.aboutHandler)
 }
 
@@ -76,6 +87,14 @@ func (h *Check) readinessHandler(w http.ResponseWriter, r *http.Request) {
 	h.conditionHandler(w, h.readyConditions)
 }
 
+func (h *Check) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	metrics := map[string]interface{}{}
+	for name, source := range h.metrics {
+		metrics[name] = source()
+	}
+	helpers.FormatResponse(w, metrics, http.StatusOK)
+}
+
 func (h *Check) aboutHandler(w http.ResponseWriter, r *http.Request) {
 	helpers.FormatResponse(w, &status{App: h.app, Instance: h.instance}, http.StatusOK)
 }
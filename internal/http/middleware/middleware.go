@@ -1,12 +1,16 @@
 package middleware
 
 import (
+	"crypto/subtle"
+	"fmt"
 	"net/http"
 	"runtime/debug"
+	"strings"
 
 	jwt "github.com/dgrijalva/jwt-go"
 
 	"github.com/mimatache/go-shop/internal/http/authorization"
+	"github.com/mimatache/go-shop/internal/rbac"
 )
 
 type responseWriter struct {
@@ -71,38 +75,222 @@ func Logging(logger logger) func(http.Handler) http.Handler {
 // JWTAuthorization verifies a request has a valid JWT token associated
 func JWTAuthorization(next http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
-		token, err := authorization.GetAuthToken(r)
+		claim, code, err := getClaim(r)
 		if err != nil {
-			if err == http.ErrNoCookie {
-				w.WriteHeader(http.StatusUnauthorized)
+			if code == http.StatusUnauthorized {
+				w.WriteHeader(code)
 				return
 			}
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			http.Error(w, err.Error(), code)
 			return
 		}
-		valid, claim, err := authorization.ValidateToken(token)
-		if err != nil {
-			if err == jwt.ErrSignatureInvalid {
+
+		authorization.AddUserIDHeader(r, claim)
+		defer authorization.RemoveUserIDHeader(r)
+		next.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// APIKeyHeader is the header holding the API key of machine clients, which can also send it as a bearer token
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyVerifier identifies the owners of API keys
+type APIKeyVerifier interface {
+	// VerifyAPIKey returns the owner of the key, their current roles and the scopes of the key
+	VerifyAPIKey(key string) (string, []rbac.Role, []rbac.Permission, error)
+}
+
+// UserAuthorization verifies a request has a valid JWT token or API key associated.
+// API keys are only accepted with the account:use scope
+func UserAuthorization(keys APIKeyVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		withToken := JWTAuthorization(next)
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			key, ok := getAPIKey(r)
+			if !ok {
+				withToken.ServeHTTP(w, r)
+				return
+			}
+			claim, code, err := authorizeAPIKey(keys, rbac.DefaultPolicy, key, rbac.UseAccount)
+			if err != nil {
+				http.Error(w, err.Error(), code)
+				return
+			}
+			authorization.AddUserIDHeader(r, claim)
+			defer authorization.RemoveUserIDHeader(r)
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// GuestAuthorization lets through requests without a valid JWT token or API key.
+// Logged in users are identified from their token, machine clients from their API key, which needs the account:use
+// scope, while anonymous visitors are identified by a signed guest cookie, which is created if missing or invalid
+func GuestAuthorization(keys APIKeyVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		withKey := UserAuthorization(keys)(next)
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			// a wrong key is refused rather than ignored, so that scripts do not fill a guest cart by mistake
+			if _, ok := getAPIKey(r); ok {
+				withKey.ServeHTTP(w, r)
+				return
+			}
+			claim, _, err := getClaim(r)
+			if err == nil {
+				authorization.AddUserIDHeader(r, claim)
+				defer authorization.RemoveUserIDHeader(r)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			guestID, err := authorization.GetGuestID(r)
+			if err != nil {
+				guestID, err = authorization.NewGuestID()
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+				authorization.SetGuestCookie(w, guestID)
+			}
+			authorization.AddGuestIDHeader(r, guestID)
+			defer authorization.RemoveUserIDHeader(r)
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// AdminKeyHeader is the header holding the key of administrative requests
+const AdminKeyHeader = "X-Admin-Key"
+
+// AdminKey lets through only the requests holding the given administrator key.
+// Administrative routes are disabled when the key is empty
+func AdminKey(key string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if key == "" {
+				http.Error(w, "administrative routes are disabled", http.StatusForbidden)
+				return
+			}
+			given := r.Header.Get(AdminKeyHeader)
+			if subtle.ConstantTimeCompare([]byte(given), []byte(key)) != 1 {
 				w.WriteHeader(http.StatusUnauthorized)
 				return
 			}
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
+			next.ServeHTTP(w, r)
 		}
-		if !valid {
-			w.WriteHeader(http.StatusUnauthorized)
-			return
+		return http.HandlerFunc(fn)
+	}
+}
+
+// Permissions returns the middlewares enforcing the permission a route requires. Requests are let through when they
+// are made by a logged in user whose roles grant the permission under the policy, with an API key holding the
+// permission as a scope, as long as the roles of its owner still grant it, or when they hold the administrator
+// key in the X-Admin-Key header, so that scripts can keep using it. Logged in users and API keys lacking the
+// permission are refused with a 403. Requests holding a key are never checked against the token, and a wrong key
+// is refused
+func Permissions(policy rbac.Policy, adminKey string, keys APIKeyVerifier) func(permission rbac.Permission) func(http.Handler) http.Handler {
+	keyHandler := AdminKey(adminKey)
+	return func(permission rbac.Permission) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			withKey := keyHandler(next)
+			fn := func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get(AdminKeyHeader) != "" {
+					withKey.ServeHTTP(w, r)
+					return
+				}
+				if key, ok := getAPIKey(r); ok {
+					claim, code, err := authorizeAPIKey(keys, policy, key, permission)
+					if err != nil {
+						http.Error(w, err.Error(), code)
+						return
+					}
+					authorization.AddUserIDHeader(r, claim)
+					defer authorization.RemoveUserIDHeader(r)
+					next.ServeHTTP(w, r)
+					return
+				}
+				claim, code, err := getClaim(r)
+				if err != nil {
+					if code == http.StatusUnauthorized {
+						w.WriteHeader(code)
+						return
+					}
+					http.Error(w, err.Error(), code)
+					return
+				}
+				if !policy.Allows(claim.Roles, permission) {
+					http.Error(w, fmt.Sprintf("the %s permission is required", permission), http.StatusForbidden)
+					return
+				}
+				authorization.AddUserIDHeader(r, claim)
+				defer authorization.RemoveUserIDHeader(r)
+				next.ServeHTTP(w, r)
+			}
+			return http.HandlerFunc(fn)
 		}
+	}
+}
 
-		ok := authorization.IsBlacklisted(token)
-		if ok {
-			w.WriteHeader(http.StatusUnauthorized)
-			return
+// getAPIKey returns the API key of the request, given either as a bearer token or in the X-API-Key header
+func getAPIKey(r *http.Request) (string, bool) {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key, true
+	}
+	const prefix = "Bearer "
+	header := r.Header.Get("Authorization")
+	if len(header) > len(prefix) && strings.EqualFold(header[:len(prefix)], prefix) {
+		return strings.TrimSpace(header[len(prefix):]), true
+	}
+	return "", false
+}
+
+// authorizeAPIKey returns a claim for the owner of the API key, if the key holds the permission as a scope and the
+// roles of its owner grant it. On failure, it also returns the status code that should be sent to the client
+func authorizeAPIKey(keys APIKeyVerifier, policy rbac.Policy, key string, permission rbac.Permission) (*authorization.Claim, int, error) {
+	// any failure refuses the key, so that no request is let through when the keys cannot be checked
+	owner, roles, scopes, err := keys.VerifyAPIKey(key)
+	if err != nil {
+		return nil, http.StatusUnauthorized, fmt.Errorf("invalid API key")
+	}
+	granted := false
+	for _, scope := range scopes {
+		if scope == permission {
+			granted = true
+			break
 		}
+	}
+	if !granted {
+		return nil, http.StatusForbidden, fmt.Errorf("the API key lacks the %s scope", permission)
+	}
+	if !policy.Allows(roles, permission) {
+		return nil, http.StatusForbidden, fmt.Errorf("the %s permission is required", permission)
+	}
+	return &authorization.Claim{Username: owner, Roles: roles}, http.StatusOK, nil
+}
 
-		authorization.AddUserIDHeader(r, claim)
-		defer authorization.RemoveUserIDHeader(r)
-		next.ServeHTTP(w, r)
+// getClaim returns the claim of the valid JWT token associated with the request.
+// On failure, it also returns the status code that should be sent to the client
+func getClaim(r *http.Request) (*authorization.Claim, int, error) {
+	token, err := authorization.GetAuthToken(r)
+	if err != nil {
+		if err == http.ErrNoCookie {
+			return nil, http.StatusUnauthorized, err
+		}
+		return nil, http.StatusBadRequest, err
 	}
-	return http.HandlerFunc(fn)
+	valid, claim, err := authorization.ValidateToken(token)
+	if err != nil {
+		if err == jwt.ErrSignatureInvalid {
+			return nil, http.StatusUnauthorized, err
+		}
+		return nil, http.StatusBadRequest, err
+	}
+	// partial tokens only allow completing the login with the second factor
+	if !valid || claim.Partial || authorization.IsBlacklisted(token) {
+		return nil, http.StatusUnauthorized, fmt.Errorf("invalid token")
+	}
+	return claim, http.StatusOK, nil
 }
@@ -0,0 +1,106 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/mimatache/go-shop/internal/outbox"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Sender delivers emails
+type Sender interface {
+	Send(message *Message) error
+}
+
+// NewOutbox creates a sender that appends every message, as a line of JSON, to a local outbox file instead of
+// delivering it. This is meant for local use, where the messages can be read from the file
+func NewOutbox(path string) (*Outbox, error) {
+	file, err := outbox.NewFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Outbox{file: file, now: time.Now}, nil
+}
+
+// Outbox writes the messages to a local file
+type Outbox struct {
+	file *outbox.File
+	now  func() time.Time
+}
+
+type outboxRecord struct {
+	*Message
+	SentAt time.Time `json:"sentAt"`
+}
+
+// Send appends the message to the outbox file
+func (o *Outbox) Send(message *Message) error {
+	return o.file.Append(&outboxRecord{Message: message, SentAt: o.now()})
+}
+
+// SMTPConfig is the server the emails are delivered through
+type SMTPConfig struct {
+	// Address is the host and port of the server
+	Address string
+	// Username and Password authenticate to the server. No authentication is made when the username is empty
+	Username string
+	Password string
+	// From is the address the emails are sent from
+	From string
+}
+
+// NewSMTP creates a sender that delivers the messages through an SMTP server
+func NewSMTP(config SMTPConfig) *SMTP {
+	return &SMTP{config: config, send: smtp.SendMail, now: time.Now}
+}
+
+// SMTP delivers the messages through an SMTP server
+type SMTP struct {
+	config SMTPConfig
+	send   func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+	now    func() time.Time
+}
+
+// Send delivers the message
+func (s *SMTP) Send(message *Message) error {
+	var auth smtp.Auth
+	if s.config.Username != "" {
+		host, _, err := net.SplitHostPort(s.config.Address)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", s.config.Username, s.config.Password, host)
+	}
+	return s.send(s.config.Address, auth, s.config.From, []string{message.To}, s.format(message))
+}
+
+// format builds the message with its headers. Line breaks are dropped from the header values,
+// so that they cannot add headers of their own
+func (s *SMTP) format(message *Message) []byte {
+	b := bytes.NewBufferString("")
+	headers := [][2]string{
+		{"From", s.config.From},
+		{"To", message.To},
+		{"Subject", message.Subject},
+		{"Date", s.now().Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "text/plain; charset=UTF-8"},
+	}
+	for _, header := range headers {
+		_, _ = fmt.Fprintf(b, "%s: %s\r\n", header[0], strings.NewReplacer("\r", "", "\n", "").Replace(header[1]))
+	}
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(message.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return b.Bytes()
+}
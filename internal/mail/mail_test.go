@@ -0,0 +1,107 @@
+package mail_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/mail"
+)
+
+func TestOutbox_Send(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "mail")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	outbox, err := mail.NewOutbox(filepath.Join(dir, "mail.jsonl"))
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	g.Expect(outbox.Send(&mail.Message{To: "user@email.com", Subject: "Hello", Body: "Hi"})).To(Succeed())
+
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "mail.jsonl"))
+	g.Expect(err).ShouldNot(HaveOccurred())
+	sent := map[string]interface{}{}
+	g.Expect(json.Unmarshal(contents, &sent)).To(Succeed())
+	g.Expect(sent).To(HaveKeyWithValue("to", "user@email.com"))
+	g.Expect(sent).To(HaveKeyWithValue("subject", "Hello"))
+	g.Expect(sent).To(HaveKeyWithValue("body", "Hi"))
+	g.Expect(sent).To(HaveKey("sentAt"))
+}
+
+// serveSMTP accepts a single delivery, without extensions, and returns what was received
+func serveSMTP(t *testing.T, listener net.Listener) <-chan []string {
+	received := make(chan []string, 1)
+	go func() {
+		defer close(received)
+		conn, err := listener.Accept()
+		if err != nil {
+			t.Logf("could not accept connection %v", err)
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+		lines := []string{}
+		data := false
+		reply("220 localhost")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			lines = append(lines, line)
+			switch {
+			case data && line == ".":
+				data = false
+				reply("250 queued")
+			case data:
+			case strings.HasPrefix(line, "DATA"):
+				data = true
+				reply("354 go ahead")
+			case strings.HasPrefix(line, "QUIT"):
+				reply("221 bye")
+				received <- lines
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return received
+}
+
+func TestSMTP_Send(t *testing.T) {
+	g := NewWithT(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	defer listener.Close()
+	received := serveSMTP(t, listener)
+
+	sender := mail.NewSMTP(mail.SMTPConfig{Address: listener.Addr().String(), From: "shop@localhost"})
+
+	err = sender.Send(&mail.Message{
+		To:      "user@email.com",
+		Subject: "Hello\r\nBcc: other@email.com",
+		Body:    "first line\nsecond line",
+	})
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	lines := <-received
+	g.Expect(lines).To(ContainElement("MAIL FROM:<shop@localhost>"))
+	g.Expect(lines).To(ContainElement("RCPT TO:<user@email.com>"))
+	g.Expect(lines).To(ContainElement("Subject: HelloBcc: other@email.com"), "line breaks cannot add headers")
+	g.Expect(lines).ShouldNot(ContainElement("Bcc: other@email.com"))
+	g.Expect(lines).To(ContainElement("first line"))
+	g.Expect(lines).To(ContainElement("second line"))
+}
@@ -0,0 +1,225 @@
+package money
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// maxAmount is the highest amount that can be represented
+const maxAmount = ^uint(0)
+
+// Currency is an ISO 4217 currency code
+type Currency string
+
+// exponents holds the number of minor units of the supported currencies
+var exponents = map[Currency]uint{
+	"CHF": 2,
+	"CZK": 2,
+	"DKK": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"HUF": 2,
+	"JPY": 0,
+	"NOK": 2,
+	"PLN": 2,
+	"RON": 2,
+	"SEK": 2,
+	"USD": 2,
+}
+
+// ParseCurrency returns the currency with the given code, which is not case sensitive
+func ParseCurrency(code string) (Currency, error) {
+	currency := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if err := currency.Validate(); err != nil {
+		return "", err
+	}
+	return currency, nil
+}
+
+// Validate checks that the currency is known
+func (c Currency) Validate() error {
+	if _, ok := exponents[c]; !ok {
+		return NewUnknownCurrency(c)
+	}
+	return nil
+}
+
+// Exponent returns the number of digits of the minor unit of the currency, 2 for cents
+func (c Currency) Exponent() uint {
+	return exponents[c]
+}
+
+type unknownCurrency struct {
+	msg string
+}
+
+func (u unknownCurrency) Error() string {
+	return u.msg
+}
+
+// IsUnknownCurrencyError verifies if a given error refers to a currency that is not supported
+func IsUnknownCurrencyError(err error) bool {
+	switch err.(type) {
+	case unknownCurrency:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewUnknownCurrency creates a new unknown currency error
+func NewUnknownCurrency(currency Currency) error {
+	return unknownCurrency{msg: fmt.Sprintf("currency %q is not supported", currency)}
+}
+
+type overflow struct {
+	msg string
+}
+
+func (o overflow) Error() string {
+	return o.msg
+}
+
+// IsOverflowError verifies if a given error refers to an amount that cannot be represented
+func IsOverflowError(err error) bool {
+	switch err.(type) {
+	case overflow:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewOverflow creates a new overflow error
+func NewOverflow(operation string, a, b uint) error {
+	return overflow{msg: fmt.Sprintf("the result of %d %s %d is out of range", a, operation, b)}
+}
+
+// Add returns the sum of two amounts, failing instead of wrapping around
+func Add(a, b uint) (uint, error) {
+	if a+b < a {
+		return 0, NewOverflow("+", a, b)
+	}
+	return a + b, nil
+}
+
+// Sub returns the difference of two amounts, failing instead of wrapping around
+func Sub(a, b uint) (uint, error) {
+	if b > a {
+		return 0, NewOverflow("-", a, b)
+	}
+	return a - b, nil
+}
+
+// Mul returns the product of two amounts, failing instead of wrapping around
+func Mul(a, b uint) (uint, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	product := a * b
+	if product/b != a {
+		return 0, NewOverflow("*", a, b)
+	}
+	return product, nil
+}
+
+// Sum adds up all the given amounts, failing instead of wrapping around
+func Sum(amounts ...uint) (uint, error) {
+	var total uint
+	for _, amount := range amounts {
+		var err error
+		total, err = Add(total, amount)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+// Scale returns amount * to / from, rounded half up. It is used to carry a share of a value over to another value,
+// like the discount of a line priced in another currency. A from of 0 scales everything to 0
+func Scale(amount, to, from uint) (uint, error) {
+	if from == 0 {
+		return 0, nil
+	}
+	value := new(big.Rat).SetFrac(
+		new(big.Int).Mul(new(big.Int).SetUint64(uint64(amount)), new(big.Int).SetUint64(uint64(to))),
+		new(big.Int).SetUint64(uint64(from)),
+	)
+	return round(value)
+}
+
+// round returns the value rounded half up, failing if it does not fit an amount
+func round(value *big.Rat) (uint, error) {
+	numerator := new(big.Int).Mul(value.Num(), big.NewInt(2))
+	numerator.Add(numerator, value.Denom())
+	denominator := new(big.Int).Mul(value.Denom(), big.NewInt(2))
+	rounded := new(big.Int).Quo(numerator, denominator)
+	if !rounded.IsUint64() || rounded.Uint64() > uint64(maxAmount) {
+		return 0, overflow{msg: fmt.Sprintf("%s is out of range", rounded)}
+	}
+	return uint(rounded.Uint64()), nil
+}
+
+// Money is an amount, given in the minor unit of its currency
+type Money struct {
+	Amount   uint     `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+// New returns an amount of the given currency
+func New(amount uint, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Add returns the sum of two amounts of the same currency
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	amount, err := Add(m.Amount, other.Amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(amount, m.Currency), nil
+}
+
+// Sub returns the difference of two amounts of the same currency
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	amount, err := Sub(m.Amount, other.Amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(amount, m.Currency), nil
+}
+
+// Mul returns the amount multiplied by a quantity
+func (m Money) Mul(quantity uint) (Money, error) {
+	amount, err := Mul(m.Amount, quantity)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(amount, m.Currency), nil
+}
+
+// String formats the amount in the major unit of its currency, like 12.34 EUR
+func (m Money) String() string {
+	exponent := m.Currency.Exponent()
+	if exponent == 0 {
+		return fmt.Sprintf("%d %s", m.Amount, m.Currency)
+	}
+	unit := uint(math.Pow10(int(exponent)))
+	return fmt.Sprintf("%d.%0*d %s", m.Amount/unit, int(exponent), m.Amount%unit, m.Currency)
+}
+
+func (m Money) sameCurrency(other Money) error {
+	if m.Currency != other.Currency {
+		return fmt.Errorf("cannot combine %s and %s amounts", m.Currency, other.Currency)
+	}
+	return nil
+}
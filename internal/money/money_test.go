@@ -0,0 +1,117 @@
+package money_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/money"
+)
+
+const maxAmount = ^uint(0)
+
+func TestAdd_Overflow(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := money.Add(maxAmount, 1)
+
+	g.Expect(money.IsOverflowError(err)).To(BeTrue())
+}
+
+func TestMul_Overflow(t *testing.T) {
+	g := NewWithT(t)
+
+	product, err := money.Mul(maxAmount/2, 2)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(product).To(Equal(maxAmount - 1))
+
+	_, err = money.Mul(maxAmount/2+1, 2)
+	g.Expect(money.IsOverflowError(err)).To(BeTrue())
+}
+
+func TestSub_BelowZero(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := money.Sub(1, 2)
+
+	g.Expect(money.IsOverflowError(err)).To(BeTrue())
+}
+
+func TestScale(t *testing.T) {
+	g := NewWithT(t)
+
+	scaled, err := money.Scale(25, 217, 200)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(scaled).To(Equal(uint(27)))
+}
+
+func TestMoney_Add_DifferentCurrencies(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := money.New(100, "EUR").Add(money.New(100, "USD"))
+
+	g.Expect(err).Should(HaveOccurred())
+}
+
+func TestMoney_String(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(money.New(1205, "EUR").String()).To(Equal("12.05 EUR"))
+	g.Expect(money.New(1205, "JPY").String()).To(Equal("1205 JPY"))
+}
+
+func TestParseCurrency(t *testing.T) {
+	g := NewWithT(t)
+
+	currency, err := money.ParseCurrency(" usd")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(currency).To(Equal(money.Currency("USD")))
+
+	_, err = money.ParseCurrency("XYZ")
+	g.Expect(money.IsUnknownCurrencyError(err)).To(BeTrue())
+}
+
+func TestLoadRates_Convert(t *testing.T) {
+	g := NewWithT(t)
+
+	rates, err := money.LoadRates(strings.NewReader(`{"Base": "EUR", "Rates": {"USD": 1.0842, "JPY": 162.5}}`))
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(rates.Currencies()).To(Equal([]money.Currency{"EUR", "JPY", "USD"}))
+
+	converted, err := rates.Convert(money.New(1000, "EUR"), "USD")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(converted).To(Equal(money.New(1084, "USD")))
+
+	converted, err = rates.Convert(money.New(1000, "EUR"), "JPY")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(converted).To(Equal(money.New(1625, "JPY")))
+
+	converted, err = rates.Convert(money.New(1084, "USD"), "EUR")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(converted).To(Equal(money.New(1000, "EUR")))
+}
+
+func TestLoadRates_Invalid(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := money.LoadRates(strings.NewReader(`{"Base": "EUR", "Rates": {"USD": 0}}`))
+	g.Expect(err).Should(HaveOccurred())
+
+	_, err = money.LoadRates(strings.NewReader(`{"Base": "ABC"}`))
+	g.Expect(money.IsUnknownCurrencyError(err)).To(BeTrue())
+}
+
+func TestRates_Parse(t *testing.T) {
+	g := NewWithT(t)
+
+	rates := money.NewRates("EUR")
+
+	currency, err := rates.Parse("")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(currency).To(Equal(money.Currency("EUR")))
+
+	_, err = rates.Parse("USD")
+	g.Expect(money.IsUnknownCurrencyError(err)).To(BeTrue())
+}
@@ -0,0 +1,130 @@
+package money
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+)
+
+// ratesFile is the layout of the exchange rates file
+type ratesFile struct {
+	Base  string                 `json:"Base"`
+	Rates map[string]json.Number `json:"Rates"`
+}
+
+// NewRates returns exchange rates that only support the base currency
+func NewRates(base Currency) *Rates {
+	return &Rates{
+		base:  base,
+		rates: map[Currency]*big.Rat{base: big.NewRat(1, 1)},
+	}
+}
+
+// LoadRates reads the exchange rates from a JSON document giving the base currency and, for every other currency,
+// how much one unit of the base currency is worth in it, like {"Base": "EUR", "Rates": {"USD": 1.0842}}
+func LoadRates(r io.Reader) (*Rates, error) {
+	file := &ratesFile{}
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	if err := decoder.Decode(file); err != nil {
+		return nil, err
+	}
+	base, err := ParseCurrency(file.Base)
+	if err != nil {
+		return nil, err
+	}
+	rates := NewRates(base)
+	for code, value := range file.Rates {
+		currency, err := ParseCurrency(code)
+		if err != nil {
+			return nil, err
+		}
+		rate, ok := new(big.Rat).SetString(value.String())
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("the exchange rate of %s must be a positive number", currency)
+		}
+		if currency == base && rate.Cmp(big.NewRat(1, 1)) != 0 {
+			return nil, fmt.Errorf("the exchange rate of the base currency %s must be 1", base)
+		}
+		rates.rates[currency] = rate
+	}
+	return rates, nil
+}
+
+// Rates converts amounts between the currencies the shop sells in
+type Rates struct {
+	base  Currency
+	rates map[Currency]*big.Rat
+}
+
+// Base returns the currency the prices are kept in
+func (r *Rates) Base() Currency {
+	return r.base
+}
+
+// Currencies returns the currencies amounts can be converted to, sorted by code
+func (r *Rates) Currencies() []Currency {
+	currencies := make([]Currency, 0, len(r.rates))
+	for currency := range r.rates {
+		currencies = append(currencies, currency)
+	}
+	sort.Slice(currencies, func(i, j int) bool { return currencies[i] < currencies[j] })
+	return currencies
+}
+
+// Parse returns the currency with the given code, if amounts can be converted to it.
+// An empty code stands for the base currency
+func (r *Rates) Parse(code string) (Currency, error) {
+	if code == "" {
+		return r.base, nil
+	}
+	currency, err := ParseCurrency(code)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := r.rates[currency]; !ok {
+		return "", NewUnknownCurrency(currency)
+	}
+	return currency, nil
+}
+
+// Convert returns the amount in another currency, rounded half up to the minor unit of that currency
+func (r *Rates) Convert(amount Money, to Currency) (Money, error) {
+	if amount.Currency == to {
+		return amount, nil
+	}
+	from, ok := r.rates[amount.Currency]
+	if !ok {
+		return Money{}, NewUnknownCurrency(amount.Currency)
+	}
+	rate, ok := r.rates[to]
+	if !ok {
+		return Money{}, NewUnknownCurrency(to)
+	}
+	value := new(big.Rat).SetInt(new(big.Int).SetUint64(uint64(amount.Amount)))
+	value.Mul(value, new(big.Rat).Quo(rate, from))
+	value.Mul(value, pow10(int(to.Exponent())-int(amount.Currency.Exponent())))
+	converted, err := round(value)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(converted, to), nil
+}
+
+// pow10 returns 10 raised to the given, possibly negative, power
+func pow10(exponent int) *big.Rat {
+	power := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(exponent))), nil)
+	if exponent < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), power)
+	}
+	return new(big.Rat).SetInt(power)
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
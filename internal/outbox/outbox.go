@@ -0,0 +1,39 @@
+package outbox
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// NewFile creates an outbox that appends every record, as a line of JSON, to the file at the given path.
+// The records can then be picked up and delivered by a separate process
+func NewFile(path string) (*File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return &File{path: path}, nil
+}
+
+// File is an outbox writing the records to a local file
+type File struct {
+	path string
+	sync.Mutex
+}
+
+// Append writes the record at the end of the outbox file
+func (f *File) Append(record interface{}) error {
+	f.Lock()
+	defer f.Unlock()
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	err = json.NewEncoder(file).Encode(record)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
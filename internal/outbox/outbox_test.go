@@ -0,0 +1,44 @@
+package outbox_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/outbox"
+)
+
+type record struct {
+	UserID string `json:"userID"`
+}
+
+func TestFile_Append(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "outbox")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	file, err := outbox.NewFile(filepath.Join(dir, "events", "carts.jsonl"))
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	g.Expect(file.Append(&record{UserID: "first"})).To(Succeed())
+	g.Expect(file.Append(&record{UserID: "second"})).To(Succeed())
+
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "events", "carts.jsonl"))
+	g.Expect(err).ShouldNot(HaveOccurred())
+	scanner := bufio.NewScanner(strings.NewReader(string(contents)))
+	users := []string{}
+	for scanner.Scan() {
+		var event record
+		g.Expect(json.Unmarshal(scanner.Bytes(), &event)).To(Succeed())
+		users = append(users, event.UserID)
+	}
+	g.Expect(users).To(Equal([]string{"first", "second"}))
+}
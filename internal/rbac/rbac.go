@@ -0,0 +1,103 @@
+package rbac
+
+import (
+	"fmt"
+)
+
+// Role is a set of permissions given to users
+type Role string
+
+const (
+	// Admin runs the shop
+	Admin Role = "admin"
+	// Customer buys from the shop. Every user is a customer unless given other roles
+	Customer Role = "customer"
+)
+
+// Permission allows an action that is not limited to the resources of the user
+type Permission string
+
+const (
+	// UseAccount allows acting on the own resources of the user, such as their cart, orders, wallet and profile.
+	// Logged in users always have it, while API keys need it as a scope
+	UseAccount Permission = "account:use"
+	// ManageCatalog allows changing the products and their stock
+	ManageCatalog Permission = "catalog:manage"
+	// ManageOrders allows shipping and cancelling the orders of every user
+	ManageOrders Permission = "orders:manage"
+	// IssueRefunds allows giving back money paid for orders
+	IssueRefunds Permission = "refunds:issue"
+	// ViewPayments allows reading the payments, the ledger and the reconciliation reports
+	ViewPayments Permission = "payments:view"
+	// ReconcilePayments allows reconciling settlement files
+	ReconcilePayments Permission = "payments:reconcile"
+	// ManageGiftCards allows issuing gift cards and reading their balance
+	ManageGiftCards Permission = "gift-cards:manage"
+	// ManageUsers allows changing the roles of the users and lifting the lockouts of their logins
+	ManageUsers Permission = "users:manage"
+)
+
+// Validate checks that the role is known
+func (r Role) Validate() error {
+	if _, ok := DefaultPolicy[r]; !ok {
+		return fmt.Errorf("unknown role: %s", r)
+	}
+	return nil
+}
+
+// Policy gives the permissions of every role
+type Policy map[Role][]Permission
+
+// DefaultPolicy is the policy the shop is run with. Customers can only reach their own resources
+var DefaultPolicy = Policy{
+	Admin: {
+		UseAccount,
+		ManageCatalog,
+		ManageOrders,
+		IssueRefunds,
+		ViewPayments,
+		ReconcilePayments,
+		ManageGiftCards,
+		ManageUsers,
+	},
+	Customer: {UseAccount},
+}
+
+// Allows checks if any of the roles grants the permission. Unknown roles grant nothing
+func (p Policy) Allows(roles []Role, permission Permission) bool {
+	for _, role := range roles {
+		for _, granted := range p[role] {
+			if granted == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsKnown checks if the permission is granted by any role of the policy
+func (p Policy) IsKnown(permission Permission) bool {
+	for _, permissions := range p {
+		for _, granted := range permissions {
+			if granted == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Permissions returns every permission granted by the roles, without duplicates
+func (p Policy) Permissions(roles []Role) []Permission {
+	seen := map[Permission]bool{}
+	permissions := []Permission{}
+	for _, role := range roles {
+		for _, permission := range p[role] {
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions
+}
@@ -0,0 +1,63 @@
+package rbac_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/rbac"
+)
+
+func TestDefaultPolicy_Allows(t *testing.T) {
+	tests := []struct {
+		roles      []rbac.Role
+		permission rbac.Permission
+		allowed    bool
+	}{
+		{roles: []rbac.Role{rbac.Admin}, permission: rbac.ManageCatalog, allowed: true},
+		{roles: []rbac.Role{rbac.Admin}, permission: rbac.IssueRefunds, allowed: true},
+		{roles: []rbac.Role{rbac.Customer, rbac.Admin}, permission: rbac.ManageOrders, allowed: true},
+		{roles: []rbac.Role{rbac.Customer}, permission: rbac.ManageOrders, allowed: false},
+		{roles: []rbac.Role{rbac.Customer}, permission: rbac.IssueRefunds, allowed: false},
+		{roles: []rbac.Role{rbac.Customer}, permission: rbac.UseAccount, allowed: true},
+		{roles: []rbac.Role{"root"}, permission: rbac.ManageUsers, allowed: false},
+		{roles: nil, permission: rbac.ViewPayments, allowed: false},
+	}
+	for _, test := range tests {
+		t.Run(string(test.permission), func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(rbac.DefaultPolicy.Allows(test.roles, test.permission)).To(Equal(test.allowed), "%v", test.roles)
+		})
+	}
+}
+
+func TestPolicy_Permissions(t *testing.T) {
+	g := NewWithT(t)
+
+	policy := rbac.Policy{
+		"support":  {rbac.ViewPayments, rbac.ManageOrders},
+		"finance":  {rbac.ViewPayments, rbac.IssueRefunds},
+		"customer": {},
+	}
+
+	g.Expect(policy.Permissions([]rbac.Role{"support", "finance"})).
+		To(Equal([]rbac.Permission{rbac.ViewPayments, rbac.ManageOrders, rbac.IssueRefunds}))
+	g.Expect(policy.Permissions([]rbac.Role{"customer"})).To(BeEmpty())
+}
+
+func TestPolicy_IsKnown(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(rbac.DefaultPolicy.IsKnown(rbac.UseAccount)).To(BeTrue())
+	g.Expect(rbac.DefaultPolicy.IsKnown(rbac.ManageUsers)).To(BeTrue())
+	g.Expect(rbac.DefaultPolicy.IsKnown("orders:delete")).To(BeFalse())
+}
+
+func TestRole_Validate(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(rbac.Admin.Validate()).To(Succeed())
+	g.Expect(rbac.Customer.Validate()).To(Succeed())
+	g.Expect(rbac.Role("root").Validate()).ShouldNot(Succeed())
+}
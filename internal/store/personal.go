@@ -0,0 +1,120 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-memdb"
+)
+
+// PersonalTable is a table holding personal data of the users, which they can export and have erased
+type PersonalTable interface {
+	Table
+	// GetPersonalRows returns the rows of the table about the user
+	GetPersonalRows(db Reader, userID string) ([]interface{}, error)
+	// Export returns what the user is given of one of their rows, leaving out secrets such as password hashes
+	Export(row interface{}) interface{}
+	// Anonymize returns the row stripped of the personal data of the user, replaced by the pseudonym, or nil when
+	// the row has to be removed. Rows that cannot be erased yet are reported with a CannotErase error
+	Anonymize(row interface{}, userID string, pseudonym string) (interface{}, error)
+}
+
+// Reader reads the rows of the tables
+type Reader interface {
+	ReadAll(table string, key string, args ...interface{}) ([]interface{}, error)
+}
+
+// CannotErase is returned when the personal data of a user cannot be erased yet
+type CannotErase struct {
+	Msg string
+}
+
+func (c CannotErase) Error() string {
+	return c.Msg
+}
+
+// NewCannotEraseError returns a cannot erase error for a row of the given table
+func NewCannotEraseError(tableName string, reason string) error {
+	return CannotErase{fmt.Sprintf("cannot erase %s: %s", tableName, reason)}
+}
+
+// IsCannotEraseError checks if an error is of type CannotErase
+func IsCannotEraseError(err error) bool {
+	switch err.(type) {
+	case CannotErase:
+		return true
+	default:
+		return false
+	}
+}
+
+// Export returns the rows about the user of every table holding personal data, by table name
+func (s *Store) Export(userID string) (map[string][]interface{}, error) {
+	txn := s.db.Txn(false)
+	export := map[string][]interface{}{}
+	for _, table := range s.personal {
+		rows, err := table.GetPersonalRows(&reader{txn: txn}, userID)
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		exported := make([]interface{}, 0, len(rows))
+		for _, row := range rows {
+			exported = append(exported, table.Export(row))
+		}
+		export[table.GetName()] = exported
+	}
+	return export, nil
+}
+
+// Erase removes or anonymizes the rows about the user of every table holding personal data, all at once.
+// Nothing is changed when any of the rows cannot be erased
+func (s *Store) Erase(userID string, pseudonym string) error {
+	txn := s.db.Txn(true)
+	// every row is read before any is changed, since tables can find the rows of the user through other tables
+	rows := make([][]interface{}, len(s.personal))
+	for i, table := range s.personal {
+		found, err := table.GetPersonalRows(&reader{txn: txn}, userID)
+		if err != nil {
+			txn.Abort()
+			return err
+		}
+		rows[i] = found
+	}
+	for i, table := range s.personal {
+		for _, row := range rows[i] {
+			anonymized, err := table.Anonymize(row, userID, pseudonym)
+			if err == nil {
+				if anonymized == nil {
+					err = txn.Delete(table.GetName(), row)
+				} else {
+					err = txn.Insert(table.GetName(), anonymized)
+				}
+			}
+			if err != nil {
+				txn.Abort()
+				return err
+			}
+		}
+	}
+	txn.Commit()
+	return nil
+}
+
+// reader reads the rows of the tables within a transaction
+type reader struct {
+	txn *memdb.Txn
+}
+
+func (r *reader) ReadAll(table string, key string, args ...interface{}) ([]interface{}, error) {
+	it, err := r.txn.Get(table, key, args...)
+	if err != nil {
+		return nil, err
+	}
+	rows := []interface{}{}
+	for obj := it.Next(); obj != nil; obj = it.Next() {
+		rows = append(rows, obj)
+	}
+	return rows, nil
+}
@@ -0,0 +1,165 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-memdb"
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/store"
+)
+
+type row struct {
+	ID     string
+	UserID string
+	// Secret is left out of the exports
+	Secret string
+	// Kept rows are anonymized rather than removed
+	Kept bool
+	// Locked rows cannot be erased
+	Locked bool
+}
+
+type personalTable struct{}
+
+func (p personalTable) GetName() string {
+	return "personal"
+}
+
+func (p personalTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: p.GetName(),
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:    "id",
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "ID"},
+			},
+			"user": {
+				Name:    "user",
+				Unique:  false,
+				Indexer: &memdb.StringFieldIndex{Field: "UserID"},
+			},
+		},
+	}
+}
+
+func (p personalTable) GetPersonalRows(db store.Reader, userID string) ([]interface{}, error) {
+	return db.ReadAll(p.GetName(), "user", userID)
+}
+
+func (p personalTable) Export(r interface{}) interface{} {
+	exported := *r.(*row)
+	exported.Secret = ""
+	return &exported
+}
+
+func (p personalTable) Anonymize(r interface{}, userID string, pseudonym string) (interface{}, error) {
+	stored := r.(*row)
+	if stored.Locked {
+		return nil, store.NewCannotEraseError(p.GetName(), "the row is locked")
+	}
+	if !stored.Kept {
+		return nil, nil
+	}
+	anonymized := *stored
+	anonymized.UserID = pseudonym
+	return &anonymized, nil
+}
+
+// otherTable does not hold personal data
+type otherTable struct{}
+
+func (o otherTable) GetName() string {
+	return "other"
+}
+
+func (o otherTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: o.GetName(),
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:    "id",
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "ID"},
+			},
+		},
+	}
+}
+
+func newStore(t *testing.T, rows ...*row) *store.Store {
+	g := NewWithT(t)
+
+	schema := store.NewSchema()
+	schema.AddToSchema(personalTable{})
+	schema.AddToSchema(otherTable{})
+	db, err := store.New(schema)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	for _, r := range rows {
+		g.Expect(db.Write(personalTable{}.GetName(), r)).To(Succeed())
+	}
+	g.Expect(db.Write(otherTable{}.GetName(), &row{ID: "other", UserID: "user"})).To(Succeed())
+	return db
+}
+
+func TestStore_Export(t *testing.T) {
+	g := NewWithT(t)
+
+	db := newStore(t,
+		&row{ID: "first", UserID: "user", Secret: "secret"},
+		&row{ID: "second", UserID: "someone else"},
+	)
+
+	export, err := db.Export("user")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(export).To(Equal(map[string][]interface{}{
+		"personal": {&row{ID: "first", UserID: "user"}},
+	}))
+
+	export, err = db.Export("nobody")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(export).To(BeEmpty())
+}
+
+func TestStore_Erase(t *testing.T) {
+	g := NewWithT(t)
+
+	db := newStore(t,
+		&row{ID: "removed", UserID: "user"},
+		&row{ID: "kept", UserID: "user", Kept: true},
+		&row{ID: "untouched", UserID: "someone else"},
+	)
+
+	g.Expect(db.Erase("user", "deleted-1")).To(Succeed())
+
+	_, err := db.Read("personal", "id", "removed")
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue())
+	kept, err := db.Read("personal", "id", "kept")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(kept).To(Equal(&row{ID: "kept", UserID: "deleted-1", Kept: true}))
+	_, err = db.Read("personal", "id", "untouched")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	_, err = db.Read("other", "id", "other")
+	g.Expect(err).ShouldNot(HaveOccurred(), "tables without personal data are left alone")
+
+	export, err := db.Export("user")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(export).To(BeEmpty())
+}
+
+func TestStore_Erase_CannotErase(t *testing.T) {
+	g := NewWithT(t)
+
+	db := newStore(t,
+		// the rows are erased in the order of their IDs, so the first one is removed before the second one fails
+		&row{ID: "1-removed", UserID: "user"},
+		&row{ID: "2-locked", UserID: "user", Locked: true},
+	)
+
+	err := db.Erase("user", "deleted-1")
+	g.Expect(store.IsCannotEraseError(err)).To(BeTrue())
+
+	export, err := db.Export("user")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(export["personal"]).To(HaveLen(2), "nothing is erased when a row cannot be")
+}
@@ -1,6 +1,8 @@
 package store
 
 import (
+	"sort"
+
 	"github.com/hashicorp/go-memdb"
 )
 
@@ -19,15 +21,33 @@ type Table interface {
 type Schema interface {
 	AddToSchema(table Table)
 	initDB() (*memdb.MemDB, error)
+	personalTables() []PersonalTable
 }
 
 type schema struct {
-	schema *memdb.DBSchema
+	schema   *memdb.DBSchema
+	personal map[string]PersonalTable
 }
 
-// AddToSchema add a table schema to the schema
+// AddToSchema add a table schema to the schema. Tables holding personal data are also registered for the exports
+// and erasures of the data of the users
 func (s *schema) AddToSchema(table Table) {
 	s.schema.Tables[table.GetName()] = table.GetTableSchema()
+	if personal, ok := table.(PersonalTable); ok {
+		s.personal[table.GetName()] = personal
+	}
+}
+
+// personalTables returns the tables holding personal data, sorted by name
+func (s *schema) personalTables() []PersonalTable {
+	tables := make([]PersonalTable, 0, len(s.personal))
+	for _, table := range s.personal {
+		tables = append(tables, table)
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].GetName() < tables[j].GetName()
+	})
+	return tables
 }
 
 func (s *schema) initDB() (*memdb.MemDB, error) {
@@ -37,5 +57,5 @@ func (s *schema) initDB() (*memdb.MemDB, error) {
 // NewSchema start a new DB schema
 func NewSchema() Schema {
 	tableShema := make(map[string]*memdb.TableSchema)
-	return &schema{schema: &memdb.DBSchema{Tables: tableShema}}
+	return &schema{schema: &memdb.DBSchema{Tables: tableShema}, personal: map[string]PersonalTable{}}
 }
@@ -43,12 +43,13 @@ func New(schema Schema) (*Store, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Store{db: db}, nil
+	return &Store{db: db, personal: schema.personalTables()}, nil
 }
 
 // Store is used to connect to the database
 type Store struct {
-	db *memdb.MemDB
+	db       *memdb.MemDB
+	personal []PersonalTable
 }
 
 // Write inserts a row into the table
@@ -87,6 +88,13 @@ func (s *Store) Read(table string, key string, value interface{}) (interface{},
 	return raw, err
 }
 
+// ReadAll returns all the rows of a DB table that match the given index value.
+// Use the "_prefix" suffix on string indexes to match by prefix, for example ("id_prefix", "") returns every row
+func (s *Store) ReadAll(table string, key string, args ...interface{}) ([]interface{}, error) {
+	r := &reader{txn: s.db.Txn(false)}
+	return r.ReadAll(table, key, args...)
+}
+
 // Remove removes a row from the DB table
 func (s *Store) Remove(table string, key string, value interface{}) error {
 	txn := s.db.Txn(true)
@@ -0,0 +1,240 @@
+package abandonment
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/mimatache/go-shop/internal/http/authorization"
+	shoppingCart "github.com/mimatache/go-shop/pkg/cart/store"
+)
+
+type errors []error
+
+func (e errors) Error() string {
+	b := bytes.NewBufferString("")
+	for _, err := range e {
+		_, _ = fmt.Fprintf(b, "\t%s\n", err)
+	}
+	return b.String()
+}
+
+//go:generate mockgen -source ./abandonment.go -destination mocks/abandonment.go
+
+const tokenBytes = 32
+
+type logger interface {
+	Infof(msg string, args ...interface{})
+	Errorf(msg string, args ...interface{})
+}
+
+// CartStore represents the methods needed from the shopping cart store
+type CartStore interface {
+	// GetCarts returns the carts of all the users
+	GetCarts() ([]*shoppingCart.CartItem, error)
+	// MarkAbandoned records the moment a cart was detected as abandoned
+	MarkAbandoned(userID string, at time.Time) error
+	// ClearCartFor removes the cart of a user
+	ClearCartFor(userID string) error
+}
+
+// RecoveryStore represents the methods needed from the cart recovery store
+type RecoveryStore interface {
+	AddRecoveryToken(token *shoppingCart.RecoveryToken) error
+	GetRecoveryTokens() ([]*shoppingCart.RecoveryToken, error)
+	RemoveRecoveryToken(token string) error
+}
+
+// Notifier delivers abandoned cart events to the owners of the carts
+type Notifier interface {
+	Notify(event *Event) error
+}
+
+// Event is emitted when a cart is detected as abandoned
+type Event struct {
+	UserID       string        `json:"userID"`
+	Products     map[uint]uint `json:"products"`
+	Coupons      []string      `json:"coupons,omitempty"`
+	LastActivity time.Time     `json:"lastActivity"`
+	// RecoveryLink restores the cart when followed by its owner. It can be used only once
+	RecoveryLink string    `json:"recoveryLink"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
+// Config holds the thresholds used to detect abandoned carts
+type Config struct {
+	// AbandonAfter is how long a cart has to be idle to be considered abandoned
+	AbandonAfter time.Duration
+	// ExpireAfter is how long a cart has to be idle to be removed
+	ExpireAfter time.Duration
+	// TokenLifetime is how long a recovery link can be used
+	TokenLifetime time.Duration
+	// RecoveryURL is the public address of the shop, used to build the recovery links
+	RecoveryURL string
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+}
+
+// Validate checks that the thresholds are consistent
+func (c Config) Validate() error {
+	var errs errors
+	if c.AbandonAfter <= 0 {
+		errs = append(errs, fmt.Errorf("abandon threshold must be positive"))
+	}
+	if c.ExpireAfter <= c.AbandonAfter {
+		errs = append(errs, fmt.Errorf("expire threshold must be greater than the abandon threshold"))
+	}
+	if c.TokenLifetime <= 0 {
+		errs = append(errs, fmt.Errorf("recovery link lifetime must be positive"))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// New creates a monitor for idle carts
+func New(log logger, carts CartStore, tokens RecoveryStore, notifier Notifier, config Config) (*Monitor, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	now := config.Now
+	if now == nil {
+		now = time.Now
+	}
+	return &Monitor{
+		log:      log,
+		carts:    carts,
+		tokens:   tokens,
+		notifier: notifier,
+		config:   config,
+		now:      now,
+	}, nil
+}
+
+// Monitor detects abandoned carts, notifies their owners and removes the carts that have been idle for too long
+type Monitor struct {
+	log      logger
+	carts    CartStore
+	tokens   RecoveryStore
+	notifier Notifier
+	config   Config
+	now      func() time.Time
+}
+
+// Run starts a go routine that scans the carts at every interval, until the context is done
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := m.Scan(); err != nil {
+					m.log.Errorf("could not scan for abandoned carts:\n%s", err)
+				}
+			}
+		}
+	}()
+}
+
+// Scan goes once over all the carts. Carts idle for longer than the expiry threshold are removed, while carts of
+// logged in users idle for longer than the abandon threshold are reported once, until the user is active again.
+// Guest carts are never reported, as there is no one to notify. Expired recovery tokens are removed as well
+func (m *Monitor) Scan() error {
+	now := m.now()
+	carts, err := m.carts.GetCarts()
+	if err != nil {
+		return err
+	}
+	var errs errors
+	for _, cartItem := range carts {
+		idle := now.Sub(cartItem.UpdatedAt)
+		switch {
+		case idle >= m.config.ExpireAfter:
+			err = m.carts.ClearCartFor(cartItem.ID)
+		case idle >= m.config.AbandonAfter && cartItem.AbandonedAt.IsZero() &&
+			len(cartItem.Products) > 0 && !authorization.IsGuest(cartItem.ID):
+			err = m.abandon(cartItem, now)
+		default:
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	tokens, err := m.tokens.GetRecoveryTokens()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, token := range tokens {
+		if !token.IsExpired(now) {
+			continue
+		}
+		if err := m.tokens.RemoveRecoveryToken(token.Token); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (m *Monitor) abandon(cartItem *shoppingCart.CartItem, now time.Time) error {
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+	recovery := &shoppingCart.RecoveryToken{
+		Token:     token,
+		UserID:    cartItem.ID,
+		Products:  map[uint]uint{},
+		Prices:    map[uint]uint{},
+		Coupons:   append([]string{}, cartItem.Coupons...),
+		ExpiresAt: now.Add(m.config.TokenLifetime),
+	}
+	for k, v := range cartItem.Products {
+		recovery.Products[k] = v
+	}
+	for k, v := range cartItem.Prices {
+		recovery.Prices[k] = v
+	}
+	err = m.tokens.AddRecoveryToken(recovery)
+	if err != nil {
+		return err
+	}
+
+	err = m.notifier.Notify(&Event{
+		UserID:       cartItem.ID,
+		Products:     recovery.Products,
+		Coupons:      recovery.Coupons,
+		LastActivity: cartItem.UpdatedAt,
+		RecoveryLink: fmt.Sprintf("%s/api/v1/cart/recover/%s", m.config.RecoveryURL, token),
+		ExpiresAt:    recovery.ExpiresAt,
+	})
+	if err != nil {
+		// the cart is not marked, so it is reported again on the next scan
+		if removeErr := m.tokens.RemoveRecoveryToken(token); removeErr != nil {
+			return errors{err, removeErr}
+		}
+		return err
+	}
+	m.log.Infof("cart of user %s abandoned since %s", cartItem.ID, cartItem.UpdatedAt)
+	return m.carts.MarkAbandoned(cartItem.ID, now)
+}
+
+func newToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,176 @@
+package abandonment_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/pkg/cart/abandonment"
+	mock_abandonment "github.com/mimatache/go-shop/pkg/cart/abandonment/mocks"
+	shoppingCart "github.com/mimatache/go-shop/pkg/cart/store"
+)
+
+const userID = "user@email.com"
+
+var now = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+type nopLogger struct{}
+
+func (nopLogger) Infof(msg string, args ...interface{})  {}
+func (nopLogger) Errorf(msg string, args ...interface{}) {}
+
+type mocks struct {
+	carts    *mock_abandonment.MockCartStore
+	tokens   *mock_abandonment.MockRecoveryStore
+	notifier *mock_abandonment.MockNotifier
+}
+
+func newMonitor(t *testing.T) (*abandonment.Monitor, *mocks, func()) {
+	ctrl := gomock.NewController(t)
+	m := &mocks{
+		carts:    mock_abandonment.NewMockCartStore(ctrl),
+		tokens:   mock_abandonment.NewMockRecoveryStore(ctrl),
+		notifier: mock_abandonment.NewMockNotifier(ctrl),
+	}
+	monitor, err := abandonment.New(nopLogger{}, m.carts, m.tokens, m.notifier, abandonment.Config{
+		AbandonAfter:  time.Hour,
+		ExpireAfter:   24 * time.Hour,
+		TokenLifetime: 2 * time.Hour,
+		RecoveryURL:   "http://shop",
+		Now:           func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return monitor, m, ctrl.Finish
+}
+
+func (m *mocks) expectCarts(carts ...*shoppingCart.CartItem) {
+	m.carts.EXPECT().GetCarts().Return(carts, nil)
+	m.tokens.EXPECT().GetRecoveryTokens().Return(nil, nil)
+}
+
+func TestMonitor_Scan_NotifiesAbandonedCart(t *testing.T) {
+	g := NewWithT(t)
+
+	monitor, m, finish := newMonitor(t)
+	defer finish()
+
+	lastActivity := now.Add(-2 * time.Hour)
+	m.expectCarts(&shoppingCart.CartItem{ID: userID, Products: map[uint]uint{1: 2}, Coupons: []string{"WELCOME10"}, UpdatedAt: lastActivity})
+	var token *shoppingCart.RecoveryToken
+	m.tokens.EXPECT().AddRecoveryToken(gomock.Any()).DoAndReturn(func(t *shoppingCart.RecoveryToken) error {
+		token = t
+		return nil
+	})
+	var event *abandonment.Event
+	m.notifier.EXPECT().Notify(gomock.Any()).DoAndReturn(func(e *abandonment.Event) error {
+		event = e
+		return nil
+	})
+	m.carts.EXPECT().MarkAbandoned(userID, now).Return(nil)
+
+	err := monitor.Scan()
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(token.UserID).To(Equal(userID))
+	g.Expect(token.Products).To(Equal(map[uint]uint{1: 2}))
+	g.Expect(token.ExpiresAt).To(Equal(now.Add(2 * time.Hour)))
+	g.Expect(event.RecoveryLink).To(Equal("http://shop/api/v1/cart/recover/" + token.Token))
+	g.Expect(event.LastActivity).To(Equal(lastActivity))
+	g.Expect(event.Coupons).To(ConsistOf("WELCOME10"))
+}
+
+func TestMonitor_Scan_SkipsCarts(t *testing.T) {
+	tests := []struct {
+		name string
+		cart *shoppingCart.CartItem
+	}{
+		{name: "active", cart: &shoppingCart.CartItem{ID: userID, Products: map[uint]uint{1: 1}, UpdatedAt: now.Add(-time.Minute)}},
+		{name: "already notified", cart: &shoppingCart.CartItem{ID: userID, Products: map[uint]uint{1: 1}, UpdatedAt: now.Add(-2 * time.Hour), AbandonedAt: now.Add(-time.Hour)}},
+		{name: "guest", cart: &shoppingCart.CartItem{ID: "guest:abc", Products: map[uint]uint{1: 1}, UpdatedAt: now.Add(-2 * time.Hour)}},
+		{name: "empty", cart: &shoppingCart.CartItem{ID: userID, Products: map[uint]uint{}, Coupons: []string{"WELCOME10"}, UpdatedAt: now.Add(-2 * time.Hour)}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			monitor, m, finish := newMonitor(t)
+			defer finish()
+
+			m.expectCarts(test.cart)
+
+			g.Expect(monitor.Scan()).To(Succeed())
+		})
+	}
+}
+
+func TestMonitor_Scan_ExpiresOldCarts(t *testing.T) {
+	g := NewWithT(t)
+
+	monitor, m, finish := newMonitor(t)
+	defer finish()
+
+	m.expectCarts(
+		&shoppingCart.CartItem{ID: userID, Products: map[uint]uint{1: 1}, UpdatedAt: now.Add(-25 * time.Hour), AbandonedAt: now.Add(-24 * time.Hour)},
+		&shoppingCart.CartItem{ID: "guest:abc", Products: map[uint]uint{1: 1}, UpdatedAt: now.Add(-24 * time.Hour)},
+	)
+	m.carts.EXPECT().ClearCartFor(userID).Return(nil)
+	m.carts.EXPECT().ClearCartFor("guest:abc").Return(nil)
+
+	g.Expect(monitor.Scan()).To(Succeed())
+}
+
+func TestMonitor_Scan_RemovesExpiredTokens(t *testing.T) {
+	g := NewWithT(t)
+
+	monitor, m, finish := newMonitor(t)
+	defer finish()
+
+	m.carts.EXPECT().GetCarts().Return(nil, nil)
+	m.tokens.EXPECT().GetRecoveryTokens().Return([]*shoppingCart.RecoveryToken{
+		{Token: "expired", UserID: userID, ExpiresAt: now},
+		{Token: "valid", UserID: userID, ExpiresAt: now.Add(time.Minute)},
+	}, nil)
+	m.tokens.EXPECT().RemoveRecoveryToken("expired").Return(nil)
+
+	g.Expect(monitor.Scan()).To(Succeed())
+}
+
+func TestMonitor_Scan_NotifierFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	monitor, m, finish := newMonitor(t)
+	defer finish()
+
+	m.expectCarts(&shoppingCart.CartItem{ID: userID, Products: map[uint]uint{1: 2}, UpdatedAt: now.Add(-2 * time.Hour)})
+	var token string
+	m.tokens.EXPECT().AddRecoveryToken(gomock.Any()).DoAndReturn(func(t *shoppingCart.RecoveryToken) error {
+		token = t.Token
+		return nil
+	})
+	m.notifier.EXPECT().Notify(gomock.Any()).Return(fmt.Errorf("an error"))
+	m.tokens.EXPECT().RemoveRecoveryToken(gomock.Any()).DoAndReturn(func(t string) error {
+		g.Expect(t).To(Equal(token))
+		return nil
+	})
+
+	err := monitor.Scan()
+
+	g.Expect(err).Should(HaveOccurred(), "the cart should not be marked, so that it is reported again")
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := abandonment.New(nopLogger{}, nil, nil, nil, abandonment.Config{
+		AbandonAfter:  time.Hour,
+		ExpireAfter:   time.Hour,
+		TokenLifetime: time.Hour,
+	})
+
+	g.Expect(err).Should(HaveOccurred())
+}
@@ -0,0 +1,239 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./abandonment.go
+
+// Package mock_abandonment is a generated GoMock package.
+package mock_abandonment
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	abandonment "github.com/mimatache/go-shop/pkg/cart/abandonment"
+	store "github.com/mimatache/go-shop/pkg/cart/store"
+	reflect "reflect"
+	time "time"
+)
+
+// Mocklogger is a mock of logger interface
+type Mocklogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockloggerMockRecorder
+}
+
+// MockloggerMockRecorder is the mock recorder for Mocklogger
+type MockloggerMockRecorder struct {
+	mock *Mocklogger
+}
+
+// NewMocklogger creates a new mock instance
+func NewMocklogger(ctrl *gomock.Controller) *Mocklogger {
+	mock := &Mocklogger{ctrl: ctrl}
+	mock.recorder = &MockloggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *Mocklogger) EXPECT() *MockloggerMockRecorder {
+	return m.recorder
+}
+
+// Infof mocks base method
+func (m *Mocklogger) Infof(msg string, args ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Infof", varargs...)
+}
+
+// Infof indicates an expected call of Infof
+func (mr *MockloggerMockRecorder) Infof(msg interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Infof", reflect.TypeOf((*Mocklogger)(nil).Infof), varargs...)
+}
+
+// Errorf mocks base method
+func (m *Mocklogger) Errorf(msg string, args ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Errorf", varargs...)
+}
+
+// Errorf indicates an expected call of Errorf
+func (mr *MockloggerMockRecorder) Errorf(msg interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Errorf", reflect.TypeOf((*Mocklogger)(nil).Errorf), varargs...)
+}
+
+// MockCartStore is a mock of CartStore interface
+type MockCartStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockCartStoreMockRecorder
+}
+
+// MockCartStoreMockRecorder is the mock recorder for MockCartStore
+type MockCartStoreMockRecorder struct {
+	mock *MockCartStore
+}
+
+// NewMockCartStore creates a new mock instance
+func NewMockCartStore(ctrl *gomock.Controller) *MockCartStore {
+	mock := &MockCartStore{ctrl: ctrl}
+	mock.recorder = &MockCartStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCartStore) EXPECT() *MockCartStoreMockRecorder {
+	return m.recorder
+}
+
+// GetCarts mocks base method
+func (m *MockCartStore) GetCarts() ([]*store.CartItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCarts")
+	ret0, _ := ret[0].([]*store.CartItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCarts indicates an expected call of GetCarts
+func (mr *MockCartStoreMockRecorder) GetCarts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCarts", reflect.TypeOf((*MockCartStore)(nil).GetCarts))
+}
+
+// MarkAbandoned mocks base method
+func (m *MockCartStore) MarkAbandoned(userID string, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAbandoned", userID, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAbandoned indicates an expected call of MarkAbandoned
+func (mr *MockCartStoreMockRecorder) MarkAbandoned(userID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAbandoned", reflect.TypeOf((*MockCartStore)(nil).MarkAbandoned), userID, at)
+}
+
+// ClearCartFor mocks base method
+func (m *MockCartStore) ClearCartFor(userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearCartFor", userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearCartFor indicates an expected call of ClearCartFor
+func (mr *MockCartStoreMockRecorder) ClearCartFor(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearCartFor", reflect.TypeOf((*MockCartStore)(nil).ClearCartFor), userID)
+}
+
+// MockRecoveryStore is a mock of RecoveryStore interface
+type MockRecoveryStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockRecoveryStoreMockRecorder
+}
+
+// MockRecoveryStoreMockRecorder is the mock recorder for MockRecoveryStore
+type MockRecoveryStoreMockRecorder struct {
+	mock *MockRecoveryStore
+}
+
+// NewMockRecoveryStore creates a new mock instance
+func NewMockRecoveryStore(ctrl *gomock.Controller) *MockRecoveryStore {
+	mock := &MockRecoveryStore{ctrl: ctrl}
+	mock.recorder = &MockRecoveryStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRecoveryStore) EXPECT() *MockRecoveryStoreMockRecorder {
+	return m.recorder
+}
+
+// AddRecoveryToken mocks base method
+func (m *MockRecoveryStore) AddRecoveryToken(token *store.RecoveryToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRecoveryToken", token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRecoveryToken indicates an expected call of AddRecoveryToken
+func (mr *MockRecoveryStoreMockRecorder) AddRecoveryToken(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRecoveryToken", reflect.TypeOf((*MockRecoveryStore)(nil).AddRecoveryToken), token)
+}
+
+// GetRecoveryTokens mocks base method
+func (m *MockRecoveryStore) GetRecoveryTokens() ([]*store.RecoveryToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecoveryTokens")
+	ret0, _ := ret[0].([]*store.RecoveryToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecoveryTokens indicates an expected call of GetRecoveryTokens
+func (mr *MockRecoveryStoreMockRecorder) GetRecoveryTokens() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecoveryTokens", reflect.TypeOf((*MockRecoveryStore)(nil).GetRecoveryTokens))
+}
+
+// RemoveRecoveryToken mocks base method
+func (m *MockRecoveryStore) RemoveRecoveryToken(token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveRecoveryToken", token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveRecoveryToken indicates an expected call of RemoveRecoveryToken
+func (mr *MockRecoveryStoreMockRecorder) RemoveRecoveryToken(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveRecoveryToken", reflect.TypeOf((*MockRecoveryStore)(nil).RemoveRecoveryToken), token)
+}
+
+// MockNotifier is a mock of Notifier interface
+type MockNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotifierMockRecorder
+}
+
+// MockNotifierMockRecorder is the mock recorder for MockNotifier
+type MockNotifierMockRecorder struct {
+	mock *MockNotifier
+}
+
+// NewMockNotifier creates a new mock instance
+func NewMockNotifier(ctrl *gomock.Controller) *MockNotifier {
+	mock := &MockNotifier{ctrl: ctrl}
+	mock.recorder = &MockNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockNotifier) EXPECT() *MockNotifierMockRecorder {
+	return m.recorder
+}
+
+// Notify mocks base method
+func (m *MockNotifier) Notify(event *abandonment.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Notify", event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Notify indicates an expected call of Notify
+func (mr *MockNotifierMockRecorder) Notify(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*MockNotifier)(nil).Notify), event)
+}
@@ -0,0 +1,24 @@
+package abandonment
+
+import (
+	"github.com/mimatache/go-shop/internal/outbox"
+)
+
+// NewFileOutbox creates a notifier that appends every event to a local outbox file,
+// from where it can be picked up and delivered by a separate process
+func NewFileOutbox(path string) (Notifier, error) {
+	file, err := outbox.NewFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &fileOutbox{file: file}, nil
+}
+
+type fileOutbox struct {
+	file *outbox.File
+}
+
+// Notify appends the event to the outbox file
+func (f *fileOutbox) Notify(event *Event) error {
+	return f.file.Append(event)
+}
@@ -6,7 +6,9 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/internal/money"
 
+	"github.com/mimatache/go-shop/pkg/cart/abandonment"
 	"github.com/mimatache/go-shop/pkg/cart/cart"
 	"github.com/mimatache/go-shop/pkg/cart/http"
 	"github.com/mimatache/go-shop/pkg/cart/store"
@@ -17,12 +19,32 @@ func NewAPI(
 	logger logger.Logger,
 	inventory cart.InventoryAPI,
 	payments cart.PaymentsAPI,
+	wallet cart.WalletAPI,
+	orders cart.OrdersAPI,
+	promotions cart.PromotionsAPI,
+	taxes cart.TaxAPI,
+	shipping cart.ShippingAPI,
+	addresses cart.AddressBookAPI,
+	exchange *money.Rates,
 	db store.UnderlyingStore,
 	router *mux.Router,
-	handlers ...func(netHTTP.Handler) netHTTP.Handler,
-) {
+	userHandler func(netHTTP.Handler) netHTTP.Handler,
+	guestHandler func(netHTTP.Handler) netHTTP.Handler,
+) *cart.Cart {
 	cartStore := store.New(logger, db)
-	cart := cart.New(inventory, payments, cartStore)
+	recoveryStore := store.NewRecoveryStore(logger, db)
+	cart := cart.New(inventory, payments, wallet, orders, promotions, taxes, shipping, addresses, exchange, cartStore, recoveryStore)
 	shoppingCart := http.New(cart)
-	shoppingCart.AddRoutes(router, handlers...)
+	shoppingCart.AddRoutes(router, userHandler, guestHandler)
+	return cart
+}
+
+// NewAbandonmentMonitor instantiates the monitor that reports abandoned carts and removes the expired ones
+func NewAbandonmentMonitor(
+	logger logger.Logger,
+	db store.UnderlyingStore,
+	notifier abandonment.Notifier,
+	config abandonment.Config,
+) (*abandonment.Monitor, error) {
+	return abandonment.New(logger, store.New(logger, db), store.NewRecoveryStore(logger, db), notifier, config)
 }
@@ -2,10 +2,19 @@ package cart
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 
+	"github.com/mimatache/go-shop/internal/money"
 	"github.com/mimatache/go-shop/internal/store"
 	shoppingCart "github.com/mimatache/go-shop/pkg/cart/store"
+	paymentStore "github.com/mimatache/go-shop/pkg/payments/store"
+	"github.com/mimatache/go-shop/pkg/promotions/engine"
+	"github.com/mimatache/go-shop/pkg/shipping/rates"
+	"github.com/mimatache/go-shop/pkg/tax/calculator"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+	walletStore "github.com/mimatache/go-shop/pkg/wallet/store"
+	"github.com/mimatache/go-shop/pkg/wallet/wallet"
 )
 
 type errors []error
@@ -18,20 +27,85 @@ func (e errors) Error() string {
 	return b.String()
 }
 
+//go:generate mockgen -source ./cart.go -destination mocks/cart.go
+
 // InventoryAPI represents the methods that need to be implemented by the inventory API
 type InventoryAPI interface {
 	// Check if product is in sufficient stock
 	HasInStock(productID uint, quantity uint) (bool, error)
-	// GetPrice returns the price of an item
+	// GetProductStock returns the quantity of an item left in stock
+	GetProductStock(productID uint) (uint, error)
+	// GetPrice returns the price of an item in the base currency
 	GetPrice(productID uint) (uint, error)
+	// GetPriceIn returns the price of an item in the given currency
+	GetPriceIn(productID uint, currency money.Currency) (money.Money, error)
+	// GetCategory returns the category of an item
+	GetCategory(productID uint) (string, error)
+	// GetTaxClass returns the tax class of an item
+	GetTaxClass(productID uint) (string, error)
+	// GetShippingWeight returns the weight, in grams, of an item when shipped
+	GetShippingWeight(productID uint) (uint, error)
 	// Renove from stock removes items from stock, but restores the previous values of False is sent over the commitChan
 	RemoveFromStock(items map[uint]uint, commitChan <-chan bool, errorChan chan<- error) error
+	// ReturnToStock adds the items back to the stock
+	ReturnToStock(items map[uint]uint) error
 }
 
 // PaymentsAPI represents the methods that need to be implemented by the payments API
 type PaymentsAPI interface {
-	// MakePayment tries to call the payment API
-	MakePayment(client string, money uint) error
+	// Authorize reserves the money from the user, to be captured when the order is shipped
+	Authorize(ctx context.Context, userID string, amount money.Money) (*paymentStore.Payment, error)
+	// Void releases the money reserved by a payment
+	Void(ctx context.Context, paymentID string) (*paymentStore.Payment, error)
+}
+
+// WalletAPI represents the methods that need to be implemented by the wallet API
+type WalletAPI interface {
+	// Spend takes up to amount from the gift cards and the store credit of the user
+	Spend(userID string, amount uint, giftCards []string, storeCredit bool) (*walletStore.Tender, error)
+	// Reverse gives back everything taken by a tender
+	Reverse(tenderID string) error
+}
+
+// OrdersAPI represents the methods that need to be implemented by the orders API
+type OrdersAPI interface {
+	// PlaceOrder records the checked out cart, paid by the given payment and the tender of the contents,
+	// and returns the ID of the order
+	PlaceOrder(userID string, paymentID string, contents *Contents) (string, error)
+}
+
+// PromotionsAPI represents the methods that need to be implemented by the promotions API
+type PromotionsAPI interface {
+	// CheckCoupon verifies that a coupon can be used
+	CheckCoupon(code string) error
+	// Apply computes the discounts the coupons grant for the given lines
+	Apply(lines []engine.Line, codes []string) (*engine.Result, error)
+	// Redeem marks the applied promotions as used
+	Redeem(userID string, result *engine.Result) error
+	// Release reverts a previous redemption
+	Release(userID string, result *engine.Result) error
+}
+
+// TaxAPI represents the methods that need to be implemented by the tax API
+type TaxAPI interface {
+	// Calculate computes the taxes owed in a region for the given lines
+	Calculate(region string, lines []calculator.Line) (*calculator.Breakdown, error)
+}
+
+// ShippingAPI represents the methods that need to be implemented by the shipping API
+type ShippingAPI interface {
+	// Quote returns the cost of every shipping method that can deliver the parcel to the region
+	Quote(region string, parcel rates.Parcel) ([]*rates.Quote, error)
+	// Cost returns the cost of delivering the parcel to the region with the given method
+	Cost(region string, methodID string, parcel rates.Parcel) (*rates.Quote, error)
+}
+
+// AddressBookAPI represents the methods that need to be implemented by the address book of the users
+type AddressBookAPI interface {
+	// GetAddress returns an address from the address book of the user
+	GetAddress(userID string, addressID uint) (*userStore.Address, error)
+	// GetDefaultShippingAddress returns the address the user ships to by default, or nil if the user has none
+	GetDefaultShippingAddress(userID string) (*userStore.Address, error)
 }
 
 // Product represents a product added to the cart
@@ -40,17 +114,50 @@ type Product struct {
 	Quantity uint `json:"quantity"`
 }
 
+// Coupon represents a coupon code added to the cart
+type Coupon struct {
+	Code string `json:"code"`
+}
+
 // Contents represents the contents of the cart
 type Contents struct {
 	Products []*Product `json:"products"`
+	Coupons  []string   `json:"coupons,omitempty"`
+	Warnings []*Warning `json:"warnings,omitempty"`
+	Summary  *Summary   `json:"summary,omitempty"`
+	Delivery *Delivery  `json:"delivery,omitempty"`
+	// Tender is what was paid with gift cards and store credit when checking out the contents
+	Tender *walletStore.Tender `json:"tender,omitempty"`
+	// OrderID is the order placed when checking out the contents
+	OrderID string `json:"orderID,omitempty"`
 }
 
 // New starts a new cart
-func New(inventory InventoryAPI, payments PaymentsAPI, cartContents shoppingCart.CartStore) *Cart {
+func New(
+	inventory InventoryAPI,
+	payments PaymentsAPI,
+	wallet WalletAPI,
+	orders OrdersAPI,
+	promotions PromotionsAPI,
+	taxes TaxAPI,
+	shipping ShippingAPI,
+	addresses AddressBookAPI,
+	exchange *money.Rates,
+	cartContents shoppingCart.CartStore,
+	recovery shoppingCart.RecoveryStore,
+) *Cart {
 	return &Cart{
 		inventory:    inventory,
 		payments:     payments,
+		wallet:       wallet,
+		orders:       orders,
+		promotions:   promotions,
+		taxes:        taxes,
+		shipping:     shipping,
+		addresses:    addresses,
+		rates:        exchange,
 		cartContents: cartContents,
+		recovery:     recovery,
 	}
 }
 
@@ -58,25 +165,120 @@ func New(inventory InventoryAPI, payments PaymentsAPI, cartContents shoppingCart
 type Cart struct {
 	inventory    InventoryAPI
 	payments     PaymentsAPI
+	wallet       WalletAPI
+	orders       OrdersAPI
+	promotions   PromotionsAPI
+	taxes        TaxAPI
+	shipping     ShippingAPI
+	addresses    AddressBookAPI
+	rates        *money.Rates
 	cartContents shoppingCart.CartStore
+	recovery     shoppingCart.RecoveryStore
+}
+
+// ParseCurrency returns the currency with the given code, if the cart can be priced in it.
+// An empty code stands for the base currency
+func (c *Cart) ParseCurrency(code string) (money.Currency, error) {
+	return c.rates.Parse(code)
 }
 
-// Checkout attempts to perform checkout of the current cart contents
-func (c *Cart) Checkout(userID string) (*Contents, error) {
+// GetContents returns the current contents of the cart, priced in the given currency for the given tax region
+func (c *Cart) GetContents(userID string, region string, currency money.Currency) (*Contents, error) {
+	currentContents, err := c.getContents(userID)
+	if err != nil {
+		if store.IsNotFoundError(err) {
+			return &Contents{Products: []*Product{}}, nil
+		}
+		return nil, err
+	}
+	currentContents.Summary, err = c.summarize(currentContents, region, "", currency)
+	if err != nil {
+		return nil, err
+	}
+	return currentContents, nil
+}
+
+// QuoteShipping returns the cost, in the given currency, of every shipping method that can deliver the cart contents
+// to the region
+func (c *Cart) QuoteShipping(userID string, region string, currency money.Currency) ([]*rates.Quote, error) {
+	currentContents, err := c.getContents(userID)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := c.summarize(currentContents, region, "", currency)
+	if err != nil {
+		return nil, err
+	}
+	quotes, err := c.shipping.Quote(region, summary.parcel)
+	if err != nil {
+		return nil, err
+	}
+	for i, quote := range quotes {
+		quotes[i], err = c.localizeQuote(quote, currency)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return quotes, nil
+}
+
+// Checkout attempts to perform checkout of the current cart contents, delivering them as requested.
+// The amount paid includes the shipping cost. It is only authorized when the order is placed and captured
+// as the order is shipped. The tender pays for a part of the amount, taken right away, and only the rest is authorized.
+// Checkout is refused while the cart has changes that were not acknowledged. Everything is paid in the given currency,
+// and gift cards and store credit, which hold amounts of the base currency, can only pay in the base currency.
+// The delivery address is either given, taken from the address book of the user by its ID, or, when neither is given,
+// the default shipping address of the user
+func (c *Cart) Checkout(ctx context.Context, userID string, delivery Delivery, tender Tender, currency money.Currency) (*Contents, error) {
+	delivery, err := c.resolveAddress(userID, delivery)
+	if err != nil {
+		return nil, err
+	}
+	if err := delivery.Validate(); err != nil {
+		return nil, NewInvalidDelivery(err)
+	}
+	if currency != c.rates.Base() && !tender.IsEmpty() {
+		return nil, wallet.NewInvalidTender(fmt.Sprintf("gift cards and store credit can only pay in %s", c.rates.Base()))
+	}
 	cartContents, err := c.getContents(userID)
 	if err != nil {
 		return nil, err
 	}
+	if len(cartContents.Warnings) > 0 {
+		return nil, NewUnacknowledgedChanges(cartContents.Warnings)
+	}
 
-	var cost uint
+	summary, err := c.summarize(cartContents, delivery.Address.Region, delivery.ShippingMethod, currency)
+	if err != nil {
+		return nil, err
+	}
 	items := map[uint]uint{}
 	for _, item := range cartContents.Products {
-		price, err := c.inventory.GetPrice(item.ID)
+		items[item.ID] = item.Quantity
+	}
+
+	err = c.promotions.Redeem(userID, summary.promotions)
+	if err != nil {
+		return nil, err
+	}
+
+	var paid *walletStore.Tender
+	remaining := summary.Total
+	if !tender.IsEmpty() {
+		paid, err = c.wallet.Spend(userID, summary.Total, tender.GiftCards, tender.StoreCredit)
 		if err != nil {
-			return nil, err
+			return nil, c.rollback(userID, summary, nil, "", nil, err)
 		}
-		cost += price * item.Quantity
-		items[item.ID] = item.Quantity
+		remaining -= paid.Total
+	}
+
+	paymentID := ""
+	if remaining > 0 {
+		payment, err := c.payments.Authorize(ctx, userID, money.New(remaining, currency))
+		if err != nil {
+			return nil, c.rollback(userID, summary, paid, "", nil, err)
+		}
+		paymentID = payment.ID
 	}
 
 	errChan := make(chan error)
@@ -85,25 +287,24 @@ func (c *Cart) Checkout(userID string) (*Contents, error) {
 	defer close(errChan)
 	defer close(commitChan)
 
+	// the stock is reserved in a transaction that blocks every other write, so it is committed right away
+	// and returned to the stock if the order cannot be placed
 	err = c.inventory.RemoveFromStock(items, commitChan, errChan)
 	if err != nil {
-		return nil, err
-	}
-	errs := errors{}
-	err = c.payments.MakePayment(userID, cost)
-	if err != nil {
-		commitChan <- false
-		errs = append(errs, err)
-	} else {
-		commitChan <- true
+		return nil, c.rollback(userID, summary, paid, paymentID, nil, err)
 	}
+	commitChan <- true
 	err = <-errChan
 	if err != nil {
-		errs = append(errs, err)
+		return nil, c.rollback(userID, summary, paid, paymentID, nil, err)
 	}
 
-	if len(errs) > 0 {
-		return nil, errs
+	cartContents.Summary = summary
+	cartContents.Delivery = &delivery
+	cartContents.Tender = paid
+	cartContents.OrderID, err = c.orders.PlaceOrder(userID, paymentID, cartContents)
+	if err != nil {
+		return nil, c.rollback(userID, summary, paid, paymentID, items, err)
 	}
 
 	err = c.cartContents.ClearCartFor(userID)
@@ -113,6 +314,36 @@ func (c *Cart) Checkout(userID string) (*Contents, error) {
 	return cartContents, err
 }
 
+// rollback undoes a failed checkout: the reserved items are returned to the stock, the payment authorization
+// is released, the gift cards and store credit are given back and the promotions are made available again.
+// The returned error holds the cause of the failure, followed by anything that failed while undoing the checkout
+func (c *Cart) rollback(userID string, summary *Summary, tender *walletStore.Tender, paymentID string, items map[uint]uint, cause error) error {
+	errs := errors{cause}
+	if items != nil {
+		if err := c.inventory.ReturnToStock(items); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if paymentID != "" {
+		// the authorization is released even when the checkout failed because the caller gave up
+		if _, err := c.payments.Void(context.Background(), paymentID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if tender != nil {
+		if err := c.wallet.Reverse(tender.ID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := c.promotions.Release(userID, summary.promotions); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) == 1 {
+		return cause
+	}
+	return errs
+}
+
 // AddProductToCart adds a bew product to the cart (or updates the existing item quantity if some already present)
 func (c *Cart) AddProductToCart(userID string, prod Product) (*Contents, error) {
 	currentProducts, err := c.cartContents.GetProductsForUser(userID)
@@ -128,8 +359,12 @@ func (c *Cart) AddProductToCart(userID string, prod Product) (*Contents, error)
 	if !hasStock {
 		return nil, fmt.Errorf("insuficient stock")
 	}
+	price, err := c.inventory.GetPrice(prod.ID)
+	if err != nil {
+		return nil, err
+	}
 
-	_, err = c.cartContents.AddProduct(userID, prod.ID, prod.Quantity)
+	_, err = c.cartContents.AddProduct(userID, prod.ID, prod.Quantity, price)
 	if err != nil {
 		return nil, err
 	}
@@ -142,14 +377,103 @@ func (c *Cart) AddProductToCart(userID string, prod Product) (*Contents, error)
 	return currentContents, nil
 }
 
+type invalidQuantity struct {
+	msg string
+}
+
+func (i invalidQuantity) Error() string {
+	return i.msg
+}
+
+// IsInvalidQuantityError verifies if a given error refers to a quantity that cannot be taken out of the cart
+func IsInvalidQuantityError(err error) bool {
+	switch err.(type) {
+	case invalidQuantity:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewInvalidQuantity creates a new invalid quantity error
+func NewInvalidQuantity(prodID uint, requested uint, available uint) error {
+	return invalidQuantity{msg: fmt.Sprintf("cannot remove %d of product %d, the cart holds %d", requested, prodID, available)}
+}
+
+// RemoveProductFromCart decreases the quantity of a product in the cart. The product is removed when the
+// quantity is 0 or the whole quantity in the cart
+func (c *Cart) RemoveProductFromCart(userID string, prod Product) (*Contents, error) {
+	currentProducts, err := c.cartContents.GetProductsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	quantity, ok := currentProducts[prod.ID]
+	if !ok {
+		return nil, store.NewNotFoundError("shoppingCart", "product", prod.ID)
+	}
+	prices, err := c.cartContents.GetPricesForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if prod.Quantity > quantity {
+		return nil, NewInvalidQuantity(prod.ID, prod.Quantity, quantity)
+	}
+	remaining := uint(0)
+	if prod.Quantity > 0 {
+		remaining = quantity - prod.Quantity
+	}
+	err = c.cartContents.UpdateProduct(userID, prod.ID, remaining, prices[prod.ID])
+	if err != nil {
+		return nil, err
+	}
+	return c.getContents(userID)
+}
+
+// AddCoupon adds a coupon code to the cart and returns the contents with the resulting discounts
+func (c *Cart) AddCoupon(userID string, coupon Coupon, region string, currency money.Currency) (*Contents, error) {
+	err := c.promotions.CheckCoupon(coupon.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.cartContents.AddCoupon(userID, coupon.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	currentContents, err := c.getContents(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	currentContents.Summary, err = c.summarize(currentContents, region, "", currency)
+	if err != nil {
+		return nil, err
+	}
+	return currentContents, nil
+}
+
+// getContents returns the contents of the cart, revalidated against the current prices and stock
 func (c *Cart) getContents(userID string) (*Contents, error) {
 	currentProducts, err := c.cartContents.GetProductsForUser(userID)
 	if err != nil {
 		return nil, err
 	}
-	currentContents := &Contents{Products: []*Product{}}
+	coupons, err := c.cartContents.GetCouponsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	prices, err := c.cartContents.GetPricesForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	currentContents := &Contents{Products: []*Product{}, Coupons: coupons}
 	for k, v := range currentProducts {
 		currentContents.Products = append(currentContents.Products, &Product{ID: k, Quantity: v})
 	}
+	err = c.revalidate(currentContents, prices)
+	if err != nil {
+		return nil, err
+	}
 	return currentContents, nil
 }
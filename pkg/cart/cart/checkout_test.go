@@ -0,0 +1,326 @@
+package cart_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/money"
+	"github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/cart/cart"
+	"github.com/mimatache/go-shop/pkg/payments/provider"
+	paymentStore "github.com/mimatache/go-shop/pkg/payments/store"
+	"github.com/mimatache/go-shop/pkg/promotions/engine"
+	"github.com/mimatache/go-shop/pkg/shipping/rates"
+	"github.com/mimatache/go-shop/pkg/tax/calculator"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+	walletStore "github.com/mimatache/go-shop/pkg/wallet/store"
+	"github.com/mimatache/go-shop/pkg/wallet/wallet"
+)
+
+const paymentID = "payment"
+
+var delivery = cart.Delivery{
+	ShippingMethod: "standard",
+	Address:        &cart.Address{Name: "John Doe", Street: "Main Street", City: "Bucharest", Region: "RO"},
+}
+
+// expectCheckout expects the checkout of two items of product 1 until the stock is reserved.
+// The commit channel receives whether the reservation is kept
+func (m *mocks) expectCheckout(commit chan<- bool) {
+	m.expectContents(map[uint]uint{1: 2}, map[uint]uint{1: price}, map[uint]current{1: {price: price, stock: 5}})
+	m.expectSummary()
+	m.payments.EXPECT().Authorize(gomock.Any(), userID, money.New(25, base)).Return(&paymentStore.Payment{ID: paymentID}, nil)
+	m.expectReservation(commit)
+}
+
+// expectReservation expects the stock of two items of product 1 to be reserved
+func (m *mocks) expectReservation(commit chan<- bool) {
+	m.inventory.EXPECT().
+		RemoveFromStock(map[uint]uint{1: 2}, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(items map[uint]uint, commitChan <-chan bool, errorChan chan<- error) error {
+			go func() {
+				commit <- <-commitChan
+				errorChan <- nil
+			}()
+			return nil
+		})
+}
+
+// expectSummary expects the pricing of two items of product 1, costing 20 and delivered for 5, and the redemption of the promotions
+func (m *mocks) expectSummary() {
+	m.inventory.EXPECT().GetPrice(uint(1)).Return(price, nil)
+	m.inventory.EXPECT().GetCategory(uint(1)).Return("books", nil)
+	m.inventory.EXPECT().GetTaxClass(uint(1)).Return("standard", nil)
+	m.inventory.EXPECT().GetShippingWeight(uint(1)).Return(uint(100), nil)
+	m.promotions.EXPECT().Apply(gomock.Any(), gomock.Any()).Return(&engine.Result{Subtotal: 20, Total: 20, LineDiscounts: []uint{0}}, nil)
+	m.taxes.EXPECT().Calculate("RO", gomock.Any()).Return(&calculator.Breakdown{
+		Lines: []*calculator.LineTax{{ProductID: 1, Net: 20, Gross: 20}},
+		Net:   20,
+		Gross: 20,
+	}, nil)
+	m.shipping.EXPECT().Cost("RO", "standard", gomock.Any()).Return(&rates.Quote{MethodID: "standard", Cost: 5}, nil)
+	m.promotions.EXPECT().Redeem(userID, gomock.Any()).Return(nil)
+}
+
+func TestCart_Checkout_PlacesOrder(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	commit := make(chan bool, 1)
+	m.expectCheckout(commit)
+	m.orders.EXPECT().PlaceOrder(userID, paymentID, gomock.Any()).Return("order", nil)
+	m.cartContents.EXPECT().ClearCartFor(userID).Return(nil)
+
+	contents, err := shoppingCart.Checkout(context.Background(), userID, delivery, cart.Tender{}, base)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(contents.OrderID).To(Equal("order"))
+	g.Expect(contents.Summary.Total).To(Equal(uint(25)))
+	g.Expect(<-commit).To(BeTrue())
+}
+
+func TestCart_Checkout_VoidsPaymentWhenOrderFails(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	commit := make(chan bool, 1)
+	m.expectCheckout(commit)
+	m.orders.EXPECT().PlaceOrder(userID, paymentID, gomock.Any()).Return("", fmt.Errorf("boom"))
+	m.inventory.EXPECT().ReturnToStock(map[uint]uint{1: 2}).Return(nil)
+	m.payments.EXPECT().Void(gomock.Any(), paymentID).Return(&paymentStore.Payment{ID: paymentID, Status: paymentStore.Voided}, nil)
+	m.promotions.EXPECT().Release(userID, gomock.Any()).Return(nil)
+
+	_, err := shoppingCart.Checkout(context.Background(), userID, delivery, cart.Tender{}, base)
+
+	g.Expect(err).To(MatchError("boom"))
+}
+
+func TestCart_Checkout_Declined(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	m.expectContents(map[uint]uint{1: 2}, map[uint]uint{1: price}, map[uint]current{1: {price: price, stock: 5}})
+	m.expectSummary()
+	m.payments.EXPECT().Authorize(gomock.Any(), userID, money.New(25, base)).Return(nil, provider.NewDeclined(provider.InsufficientFunds, ""))
+	m.promotions.EXPECT().Release(userID, gomock.Any()).Return(nil)
+
+	_, err := shoppingCart.Checkout(context.Background(), userID, delivery, cart.Tender{}, base)
+
+	g.Expect(provider.IsDeclinedError(err)).To(BeTrue())
+}
+
+func TestCart_Checkout_SplitTender(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	tender := cart.Tender{GiftCards: []string{"ABCD-EFGH-JKLM-NPQR"}, StoreCredit: true}
+	paid := &walletStore.Tender{ID: "tender", Total: 15}
+	commit := make(chan bool, 1)
+	m.expectContents(map[uint]uint{1: 2}, map[uint]uint{1: price}, map[uint]current{1: {price: price, stock: 5}})
+	m.expectSummary()
+	m.wallet.EXPECT().Spend(userID, uint(25), tender.GiftCards, true).Return(paid, nil)
+	m.payments.EXPECT().Authorize(gomock.Any(), userID, money.New(10, base)).Return(&paymentStore.Payment{ID: paymentID}, nil)
+	m.expectReservation(commit)
+	m.orders.EXPECT().PlaceOrder(userID, paymentID, gomock.Any()).Return("order", nil)
+	m.cartContents.EXPECT().ClearCartFor(userID).Return(nil)
+
+	contents, err := shoppingCart.Checkout(context.Background(), userID, delivery, tender, base)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(contents.Tender).To(Equal(paid))
+	g.Expect(<-commit).To(BeTrue())
+}
+
+func TestCart_Checkout_PaidByTender(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	tender := cart.Tender{StoreCredit: true}
+	commit := make(chan bool, 1)
+	m.expectContents(map[uint]uint{1: 2}, map[uint]uint{1: price}, map[uint]current{1: {price: price, stock: 5}})
+	m.expectSummary()
+	m.wallet.EXPECT().Spend(userID, uint(25), nil, true).Return(&walletStore.Tender{ID: "tender", Total: 25}, nil)
+	m.expectReservation(commit)
+	m.orders.EXPECT().PlaceOrder(userID, "", gomock.Any()).Return("order", nil)
+	m.cartContents.EXPECT().ClearCartFor(userID).Return(nil)
+
+	_, err := shoppingCart.Checkout(context.Background(), userID, delivery, tender, base)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+}
+
+func TestCart_Checkout_ReversesTenderWhenDeclined(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	tender := cart.Tender{StoreCredit: true}
+	m.expectContents(map[uint]uint{1: 2}, map[uint]uint{1: price}, map[uint]current{1: {price: price, stock: 5}})
+	m.expectSummary()
+	m.wallet.EXPECT().Spend(userID, uint(25), nil, true).Return(&walletStore.Tender{ID: "tender", Total: 5}, nil)
+	m.payments.EXPECT().Authorize(gomock.Any(), userID, money.New(20, base)).Return(nil, provider.NewDeclined(provider.CardDeclined, ""))
+	m.wallet.EXPECT().Reverse("tender").Return(nil)
+	m.promotions.EXPECT().Release(userID, gomock.Any()).Return(nil)
+
+	_, err := shoppingCart.Checkout(context.Background(), userID, delivery, tender, base)
+
+	g.Expect(provider.IsDeclinedError(err)).To(BeTrue())
+}
+
+func TestCart_Checkout_OtherCurrency(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	commit := make(chan bool, 1)
+	m.expectContents(map[uint]uint{1: 2}, map[uint]uint{1: price}, map[uint]current{1: {price: price, stock: 5}})
+	m.inventory.EXPECT().GetPrice(uint(1)).Return(price, nil)
+	m.inventory.EXPECT().GetPriceIn(uint(1), money.Currency("USD")).Return(money.New(16, "USD"), nil)
+	m.inventory.EXPECT().GetCategory(uint(1)).Return("books", nil)
+	m.inventory.EXPECT().GetTaxClass(uint(1)).Return("standard", nil)
+	m.inventory.EXPECT().GetShippingWeight(uint(1)).Return(uint(100), nil)
+	m.promotions.EXPECT().Apply(gomock.Any(), gomock.Any()).Return(&engine.Result{
+		Subtotal:      20,
+		Discount:      5,
+		Total:         15,
+		Promotions:    []*engine.Applied{{Code: "SAVE", Discount: 5}},
+		LineDiscounts: []uint{5},
+	}, nil)
+	m.taxes.EXPECT().
+		Calculate("RO", []calculator.Line{{ProductID: 1, Class: "standard", Amount: 24}}).
+		Return(&calculator.Breakdown{Net: 24, Gross: 24}, nil)
+	m.shipping.EXPECT().Cost("RO", "standard", rates.Parcel{Weight: 200, Value: 16}).Return(&rates.Quote{MethodID: "standard", Cost: 5}, nil)
+	m.promotions.EXPECT().Redeem(userID, gomock.Any()).Return(nil)
+	m.payments.EXPECT().Authorize(gomock.Any(), userID, money.New(32, "USD")).Return(&paymentStore.Payment{ID: paymentID}, nil)
+	m.expectReservation(commit)
+	m.orders.EXPECT().PlaceOrder(userID, paymentID, gomock.Any()).Return("order", nil)
+	m.cartContents.EXPECT().ClearCartFor(userID).Return(nil)
+
+	contents, err := shoppingCart.Checkout(context.Background(), userID, delivery, cart.Tender{}, "USD")
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(contents.Summary.Currency).To(Equal(money.Currency("USD")))
+	g.Expect(contents.Summary.Subtotal).To(Equal(uint(32)))
+	g.Expect(contents.Summary.Discount).To(Equal(uint(8)))
+	g.Expect(contents.Summary.Promotions[0].Discount).To(Equal(uint(8)))
+	g.Expect(contents.Summary.Shipping.Cost).To(Equal(uint(8)))
+	g.Expect(contents.Summary.Total).To(Equal(uint(32)))
+	g.Expect(<-commit).To(BeTrue())
+}
+
+func TestCart_Checkout_TenderInOtherCurrency(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, _, finish := newCart(t)
+	defer finish()
+
+	_, err := shoppingCart.Checkout(context.Background(), userID, delivery, cart.Tender{StoreCredit: true}, "USD")
+
+	g.Expect(wallet.IsInvalidTenderError(err)).To(BeTrue())
+}
+
+func TestCart_Checkout_AddressFromAddressBook(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	commit := make(chan bool, 1)
+	m.addresses.EXPECT().GetAddress(userID, uint(3)).Return(&userStore.Address{
+		ID:     3,
+		UserID: userID,
+		Name:   "John Doe",
+		Street: "Main Street",
+		City:   "Bucharest",
+		Region: "RO",
+	}, nil)
+	m.expectCheckout(commit)
+	m.orders.EXPECT().PlaceOrder(userID, paymentID, gomock.Any()).Return("order", nil)
+	m.cartContents.EXPECT().ClearCartFor(userID).Return(nil)
+
+	contents, err := shoppingCart.Checkout(context.Background(), userID, cart.Delivery{ShippingMethod: "standard", AddressID: 3}, cart.Tender{}, base)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(contents.Delivery.Address).To(Equal(delivery.Address))
+	g.Expect(<-commit).To(BeTrue())
+}
+
+func TestCart_Checkout_DefaultShippingAddress(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	commit := make(chan bool, 1)
+	m.addresses.EXPECT().GetDefaultShippingAddress(userID).Return(&userStore.Address{
+		ID:              4,
+		UserID:          userID,
+		Name:            "John Doe",
+		Street:          "Main Street",
+		City:            "Bucharest",
+		Region:          "RO",
+		DefaultShipping: true,
+	}, nil)
+	m.expectCheckout(commit)
+	m.orders.EXPECT().PlaceOrder(userID, paymentID, gomock.Any()).Return("order", nil)
+	m.cartContents.EXPECT().ClearCartFor(userID).Return(nil)
+
+	contents, err := shoppingCart.Checkout(context.Background(), userID, cart.Delivery{ShippingMethod: "standard"}, cart.Tender{}, base)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(contents.Delivery.AddressID).To(Equal(uint(4)))
+	g.Expect(<-commit).To(BeTrue())
+}
+
+func TestCart_Checkout_InvalidAddressBookDelivery(t *testing.T) {
+	deliveries := map[string]struct {
+		delivery cart.Delivery
+		expect   func(m *mocks)
+	}{
+		"unknown address": {
+			delivery: cart.Delivery{ShippingMethod: "standard", AddressID: 3},
+			expect: func(m *mocks) {
+				m.addresses.EXPECT().GetAddress(userID, uint(3)).Return(nil, store.NewNotFoundError("userAddress", "id", uint(3)))
+			},
+		},
+		"address and address ID": {
+			delivery: cart.Delivery{ShippingMethod: "standard", AddressID: 3, Address: delivery.Address},
+			expect:   func(m *mocks) {},
+		},
+		"no default address": {
+			delivery: cart.Delivery{ShippingMethod: "standard"},
+			expect: func(m *mocks) {
+				m.addresses.EXPECT().GetDefaultShippingAddress(userID).Return(nil, nil)
+			},
+		},
+	}
+	for name, test := range deliveries {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			shoppingCart, m, finish := newCart(t)
+			defer finish()
+			test.expect(m)
+
+			_, err := shoppingCart.Checkout(context.Background(), userID, test.delivery, cart.Tender{}, base)
+
+			g.Expect(cart.IsInvalidDeliveryError(err)).To(BeTrue(), "%v", err)
+		})
+	}
+}
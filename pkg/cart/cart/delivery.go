@@ -0,0 +1,125 @@
+package cart
+
+import (
+	"fmt"
+
+	"github.com/mimatache/go-shop/internal/store"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+)
+
+type invalidDelivery struct {
+	msg string
+}
+
+func (i invalidDelivery) Error() string {
+	return i.msg
+}
+
+// IsInvalidDeliveryError verifies if a given error refers to missing or incorrect delivery details
+func IsInvalidDeliveryError(err error) bool {
+	switch err.(type) {
+	case invalidDelivery:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewInvalidDelivery creates a new invalid delivery error
+func NewInvalidDelivery(reason error) error {
+	return invalidDelivery{msg: fmt.Sprintf("invalid delivery details:\n%s", reason)}
+}
+
+// Address is where an order is delivered
+type Address struct {
+	Name       string `json:"name"`
+	Street     string `json:"street"`
+	City       string `json:"city"`
+	PostalCode string `json:"postalCode"`
+	// Region is the code of the country of the address. It selects both the tax region and the shipping zone
+	Region string `json:"region"`
+}
+
+// Validate checks that an address adheres to constraints
+func (a Address) Validate() error {
+	var errs errors
+	if a.Name == "" {
+		errs = append(errs, fmt.Errorf("name is mandatory"))
+	}
+	if a.Street == "" {
+		errs = append(errs, fmt.Errorf("street is mandatory"))
+	}
+	if a.City == "" {
+		errs = append(errs, fmt.Errorf("city is mandatory"))
+	}
+	if a.Region == "" {
+		errs = append(errs, fmt.Errorf("region is mandatory"))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// fromAddressBook copies an address of the address book of the user
+func fromAddressBook(address *userStore.Address) *Address {
+	return &Address{
+		Name:       address.Name,
+		Street:     address.Street,
+		City:       address.City,
+		PostalCode: address.PostalCode,
+		Region:     address.Region,
+	}
+}
+
+// Delivery holds the shipping details required to checkout
+type Delivery struct {
+	ShippingMethod string   `json:"shippingMethod"`
+	Address        *Address `json:"address"`
+	// AddressID selects an address from the address book of the user instead of giving it
+	AddressID uint `json:"addressID,omitempty"`
+}
+
+// Validate checks that the delivery details are complete
+func (d Delivery) Validate() error {
+	var errs errors
+	if d.ShippingMethod == "" {
+		errs = append(errs, fmt.Errorf("shipping method is mandatory"))
+	}
+	if d.Address == nil {
+		errs = append(errs, fmt.Errorf("address is mandatory"))
+	} else if err := d.Address.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// resolveAddress fills in the address of the delivery from the address book of the user, when it is not given
+func (c *Cart) resolveAddress(userID string, delivery Delivery) (Delivery, error) {
+	switch {
+	case delivery.AddressID != 0 && delivery.Address != nil:
+		return delivery, NewInvalidDelivery(fmt.Errorf("either an address or an address ID can be given"))
+	case delivery.AddressID != 0:
+		address, err := c.addresses.GetAddress(userID, delivery.AddressID)
+		if err != nil {
+			if store.IsNotFoundError(err) {
+				return delivery, NewInvalidDelivery(fmt.Errorf("address %d is not in the address book", delivery.AddressID))
+			}
+			return delivery, err
+		}
+		delivery.Address = fromAddressBook(address)
+	case delivery.Address == nil:
+		address, err := c.addresses.GetDefaultShippingAddress(userID)
+		if err != nil {
+			return delivery, err
+		}
+		if address != nil {
+			delivery.Address = fromAddressBook(address)
+			delivery.AddressID = address.ID
+		}
+	}
+	return delivery, nil
+}
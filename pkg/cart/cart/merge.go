@@ -0,0 +1,115 @@
+package cart
+
+import (
+	"time"
+
+	"github.com/mimatache/go-shop/internal/store"
+)
+
+// MergeCarts moves the contents of the guest cart into the cart of the user and removes the guest cart.
+// When a product is present in both carts the quantities are summed, but never above the stock available.
+// Products that are no longer sold are dropped and the coupons of both carts are kept
+func (c *Cart) MergeCarts(guestID string, userID string) error {
+	guestProducts, err := c.cartContents.GetProductsForUser(guestID)
+	if err != nil {
+		if store.IsNotFoundError(err) {
+			return nil
+		}
+		return err
+	}
+	guestCoupons, err := c.cartContents.GetCouponsForUser(guestID)
+	if err != nil {
+		return err
+	}
+	guestPrices, err := c.cartContents.GetPricesForUser(guestID)
+	if err != nil {
+		return err
+	}
+	err = c.combine(userID, guestProducts, guestPrices, guestCoupons, func(current, incoming uint) uint {
+		return current + incoming
+	})
+	if err != nil {
+		return err
+	}
+	return c.cartContents.ClearCartFor(guestID)
+}
+
+// Recover restores the snapshot held by a recovery token into the cart of the user. Every product ends up with at
+// least the quantity it had when the cart was abandoned, stock permitting. A token can be used only once
+func (c *Cart) Recover(userID string, token string) (*Contents, error) {
+	recovery, err := c.recovery.GetRecoveryToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if recovery.UserID != userID || recovery.IsExpired(time.Now()) {
+		return nil, store.NewNotFoundError("cartRecovery", "token", token)
+	}
+	err = c.recovery.RemoveRecoveryToken(token)
+	if err != nil {
+		return nil, err
+	}
+	err = c.combine(userID, recovery.Products, recovery.Prices, recovery.Coupons, func(current, incoming uint) uint {
+		if incoming > current {
+			return incoming
+		}
+		return current
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.getContents(userID)
+}
+
+// combine adds products and coupons to the cart of the user. The quantity of every product is decided by the
+// merge function, from the quantity already in the cart and the incoming one, and is capped by the stock available.
+// The unit prices seen by the user are carried over, so that price changes are still reported.
+// Products that are no longer sold are dropped
+func (c *Cart) combine(
+	userID string,
+	products map[uint]uint,
+	prices map[uint]uint,
+	coupons []string,
+	merge func(current, incoming uint) uint,
+) error {
+	userProducts, err := c.cartContents.GetProductsForUser(userID)
+	if err != nil && !store.IsNotFoundError(err) {
+		return err
+	}
+
+	for prodID, quantity := range products {
+		stock, err := c.inventory.GetProductStock(prodID)
+		if err != nil {
+			if store.IsNotFoundError(err) {
+				continue
+			}
+			return err
+		}
+		current := userProducts[prodID]
+		merged := merge(current, quantity)
+		if merged > stock {
+			merged = stock
+		}
+		if merged <= current {
+			continue
+		}
+		price, ok := prices[prodID]
+		if !ok {
+			price, err = c.inventory.GetPrice(prodID)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = c.cartContents.AddProduct(userID, prodID, merged-current, price)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, code := range coupons {
+		_, err = c.cartContents.AddCoupon(userID, code)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+package cart_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/money"
+	"github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/cart/cart"
+	mock_cart "github.com/mimatache/go-shop/pkg/cart/cart/mocks"
+	cartStore "github.com/mimatache/go-shop/pkg/cart/store"
+	mock_store "github.com/mimatache/go-shop/pkg/cart/store/mocks"
+)
+
+const (
+	guestID = "guest:abc"
+	userID  = "user@email.com"
+	price   = uint(10)
+	base    = money.Currency("EUR")
+)
+
+var exchange, _ = money.LoadRates(strings.NewReader(`{"Base": "EUR", "Rates": {"USD": 1.5}}`))
+
+type mocks struct {
+	inventory    *mock_cart.MockInventoryAPI
+	payments     *mock_cart.MockPaymentsAPI
+	wallet       *mock_cart.MockWalletAPI
+	orders       *mock_cart.MockOrdersAPI
+	promotions   *mock_cart.MockPromotionsAPI
+	taxes        *mock_cart.MockTaxAPI
+	shipping     *mock_cart.MockShippingAPI
+	addresses    *mock_cart.MockAddressBookAPI
+	cartContents *mock_store.MockCartStore
+	recovery     *mock_store.MockRecoveryStore
+}
+
+func newCart(t *testing.T) (*cart.Cart, *mocks, func()) {
+	ctrl := gomock.NewController(t)
+	m := &mocks{
+		inventory:    mock_cart.NewMockInventoryAPI(ctrl),
+		payments:     mock_cart.NewMockPaymentsAPI(ctrl),
+		wallet:       mock_cart.NewMockWalletAPI(ctrl),
+		orders:       mock_cart.NewMockOrdersAPI(ctrl),
+		promotions:   mock_cart.NewMockPromotionsAPI(ctrl),
+		taxes:        mock_cart.NewMockTaxAPI(ctrl),
+		shipping:     mock_cart.NewMockShippingAPI(ctrl),
+		addresses:    mock_cart.NewMockAddressBookAPI(ctrl),
+		cartContents: mock_store.NewMockCartStore(ctrl),
+		recovery:     mock_store.NewMockRecoveryStore(ctrl),
+	}
+	shoppingCart := cart.New(
+		m.inventory,
+		m.payments,
+		m.wallet,
+		m.orders,
+		m.promotions,
+		m.taxes,
+		m.shipping,
+		m.addresses,
+		exchange,
+		m.cartContents,
+		m.recovery,
+	)
+	return shoppingCart, m, ctrl.Finish
+}
+
+func (m *mocks) expectCarts(guest map[uint]uint, guestCoupons []string, user map[uint]uint) {
+	m.cartContents.EXPECT().GetProductsForUser(guestID).Return(guest, nil)
+	m.cartContents.EXPECT().GetCouponsForUser(guestID).Return(guestCoupons, nil)
+	guestPrices := map[uint]uint{}
+	for prodID := range guest {
+		guestPrices[prodID] = price
+	}
+	m.cartContents.EXPECT().GetPricesForUser(guestID).Return(guestPrices, nil)
+	if user == nil {
+		m.cartContents.EXPECT().GetProductsForUser(userID).Return(nil, store.NewNotFoundError("shoppingCart", "id", userID))
+		return
+	}
+	m.cartContents.EXPECT().GetProductsForUser(userID).Return(user, nil)
+}
+
+func TestCart_MergeCarts_NoGuestCart(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	m.cartContents.EXPECT().GetProductsForUser(guestID).Return(nil, store.NewNotFoundError("shoppingCart", "id", guestID))
+
+	err := shoppingCart.MergeCarts(guestID, userID)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+}
+
+func TestCart_MergeCarts_UserWithoutCart(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	m.expectCarts(map[uint]uint{1: 2}, nil, nil)
+	m.inventory.EXPECT().GetProductStock(uint(1)).Return(uint(5), nil)
+	m.cartContents.EXPECT().AddProduct(userID, uint(1), uint(2), price).Return(uint(2), nil)
+	m.cartContents.EXPECT().ClearCartFor(guestID).Return(nil)
+
+	err := shoppingCart.MergeCarts(guestID, userID)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+}
+
+func TestCart_MergeCarts_SumsQuantities(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	m.expectCarts(map[uint]uint{1: 2, 2: 1}, nil, map[uint]uint{1: 1, 3: 4})
+	m.inventory.EXPECT().GetProductStock(uint(1)).Return(uint(5), nil)
+	m.inventory.EXPECT().GetProductStock(uint(2)).Return(uint(5), nil)
+	m.cartContents.EXPECT().AddProduct(userID, uint(1), uint(2), price).Return(uint(3), nil)
+	m.cartContents.EXPECT().AddProduct(userID, uint(2), uint(1), price).Return(uint(1), nil)
+	m.cartContents.EXPECT().ClearCartFor(guestID).Return(nil)
+
+	err := shoppingCart.MergeCarts(guestID, userID)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+}
+
+func TestCart_MergeCarts_CappedByStock(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	m.expectCarts(map[uint]uint{1: 3}, nil, map[uint]uint{1: 2})
+	m.inventory.EXPECT().GetProductStock(uint(1)).Return(uint(4), nil)
+	m.cartContents.EXPECT().AddProduct(userID, uint(1), uint(2), price).Return(uint(4), nil)
+	m.cartContents.EXPECT().ClearCartFor(guestID).Return(nil)
+
+	err := shoppingCart.MergeCarts(guestID, userID)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+}
+
+func TestCart_MergeCarts_UserAlreadyAboveStock(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	m.expectCarts(map[uint]uint{1: 1}, nil, map[uint]uint{1: 3})
+	m.inventory.EXPECT().GetProductStock(uint(1)).Return(uint(2), nil)
+	m.cartContents.EXPECT().ClearCartFor(guestID).Return(nil)
+
+	err := shoppingCart.MergeCarts(guestID, userID)
+
+	g.Expect(err).ShouldNot(HaveOccurred(), "the quantity in the user cart should be left untouched")
+}
+
+func TestCart_MergeCarts_OutOfStock(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	m.expectCarts(map[uint]uint{1: 1}, nil, nil)
+	m.inventory.EXPECT().GetProductStock(uint(1)).Return(uint(0), nil)
+	m.cartContents.EXPECT().ClearCartFor(guestID).Return(nil)
+
+	err := shoppingCart.MergeCarts(guestID, userID)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+}
+
+func TestCart_MergeCarts_DiscontinuedProduct(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	m.expectCarts(map[uint]uint{9: 1}, nil, nil)
+	m.inventory.EXPECT().GetProductStock(uint(9)).Return(uint(0), store.NewNotFoundError("products", "id", 9))
+	m.cartContents.EXPECT().ClearCartFor(guestID).Return(nil)
+
+	err := shoppingCart.MergeCarts(guestID, userID)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+}
+
+func TestCart_MergeCarts_KeepsCoupons(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	m.expectCarts(map[uint]uint{}, []string{"WELCOME10"}, map[uint]uint{1: 1})
+	m.cartContents.EXPECT().AddCoupon(userID, "WELCOME10").Return([]string{"WELCOME10"}, nil)
+	m.cartContents.EXPECT().ClearCartFor(guestID).Return(nil)
+
+	err := shoppingCart.MergeCarts(guestID, userID)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+}
+
+func TestCart_MergeCarts_KeepsGuestCartOnError(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	m.expectCarts(map[uint]uint{1: 1}, nil, nil)
+	m.inventory.EXPECT().GetProductStock(uint(1)).Return(uint(0), fmt.Errorf("an error"))
+
+	err := shoppingCart.MergeCarts(guestID, userID)
+
+	g.Expect(err).Should(HaveOccurred())
+}
+
+func TestCart_Recover(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	m.recovery.EXPECT().GetRecoveryToken("token").Return(&cartStore.RecoveryToken{
+		Token:     "token",
+		UserID:    userID,
+		Products:  map[uint]uint{1: 2, 2: 3},
+		Prices:    map[uint]uint{1: price, 2: price},
+		Coupons:   []string{"WELCOME10"},
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	m.recovery.EXPECT().RemoveRecoveryToken("token").Return(nil)
+	m.cartContents.EXPECT().GetProductsForUser(userID).Return(map[uint]uint{1: 1, 2: 3}, nil)
+	m.inventory.EXPECT().GetProductStock(uint(1)).Return(uint(5), nil)
+	m.inventory.EXPECT().GetProductStock(uint(2)).Return(uint(5), nil)
+	m.cartContents.EXPECT().AddProduct(userID, uint(1), uint(1), price).Return(uint(2), nil)
+	m.cartContents.EXPECT().AddCoupon(userID, "WELCOME10").Return([]string{"WELCOME10"}, nil)
+	m.cartContents.EXPECT().GetProductsForUser(userID).Return(map[uint]uint{1: 2, 2: 3}, nil)
+	m.cartContents.EXPECT().GetCouponsForUser(userID).Return([]string{"WELCOME10"}, nil)
+	m.cartContents.EXPECT().GetPricesForUser(userID).Return(map[uint]uint{1: price, 2: price}, nil)
+	m.inventory.EXPECT().GetPrice(uint(1)).Return(price, nil)
+	m.inventory.EXPECT().GetPrice(uint(2)).Return(price, nil)
+	m.inventory.EXPECT().GetProductStock(uint(1)).Return(uint(5), nil)
+	m.inventory.EXPECT().GetProductStock(uint(2)).Return(uint(5), nil)
+
+	contents, err := shoppingCart.Recover(userID, "token")
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(contents.Products).To(HaveLen(2), "quantities still in the cart should not be doubled")
+}
+
+func TestCart_Recover_OtherUser(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	m.recovery.EXPECT().GetRecoveryToken("token").Return(&cartStore.RecoveryToken{
+		Token:     "token",
+		UserID:    "other@email.com",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+
+	_, err := shoppingCart.Recover(userID, "token")
+
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue())
+}
+
+func TestCart_Recover_Expired(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	m.recovery.EXPECT().GetRecoveryToken("token").Return(&cartStore.RecoveryToken{
+		Token:     "token",
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}, nil)
+
+	_, err := shoppingCart.Recover(userID, "token")
+
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue())
+}
@@ -0,0 +1,542 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./cart.go
+
+// Package mock_cart is a generated GoMock package.
+package mock_cart
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	money "github.com/mimatache/go-shop/internal/money"
+	cart "github.com/mimatache/go-shop/pkg/cart/cart"
+	store "github.com/mimatache/go-shop/pkg/payments/store"
+	engine "github.com/mimatache/go-shop/pkg/promotions/engine"
+	rates "github.com/mimatache/go-shop/pkg/shipping/rates"
+	calculator "github.com/mimatache/go-shop/pkg/tax/calculator"
+	store0 "github.com/mimatache/go-shop/pkg/users/store"
+	store1 "github.com/mimatache/go-shop/pkg/wallet/store"
+	reflect "reflect"
+)
+
+// MockInventoryAPI is a mock of InventoryAPI interface
+type MockInventoryAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockInventoryAPIMockRecorder
+}
+
+// MockInventoryAPIMockRecorder is the mock recorder for MockInventoryAPI
+type MockInventoryAPIMockRecorder struct {
+	mock *MockInventoryAPI
+}
+
+// NewMockInventoryAPI creates a new mock instance
+func NewMockInventoryAPI(ctrl *gomock.Controller) *MockInventoryAPI {
+	mock := &MockInventoryAPI{ctrl: ctrl}
+	mock.recorder = &MockInventoryAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockInventoryAPI) EXPECT() *MockInventoryAPIMockRecorder {
+	return m.recorder
+}
+
+// HasInStock mocks base method
+func (m *MockInventoryAPI) HasInStock(productID, quantity uint) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasInStock", productID, quantity)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasInStock indicates an expected call of HasInStock
+func (mr *MockInventoryAPIMockRecorder) HasInStock(productID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasInStock", reflect.TypeOf((*MockInventoryAPI)(nil).HasInStock), productID, quantity)
+}
+
+// GetProductStock mocks base method
+func (m *MockInventoryAPI) GetProductStock(productID uint) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductStock", productID)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductStock indicates an expected call of GetProductStock
+func (mr *MockInventoryAPIMockRecorder) GetProductStock(productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductStock", reflect.TypeOf((*MockInventoryAPI)(nil).GetProductStock), productID)
+}
+
+// GetPrice mocks base method
+func (m *MockInventoryAPI) GetPrice(productID uint) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrice", productID)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrice indicates an expected call of GetPrice
+func (mr *MockInventoryAPIMockRecorder) GetPrice(productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrice", reflect.TypeOf((*MockInventoryAPI)(nil).GetPrice), productID)
+}
+
+// GetPriceIn mocks base method
+func (m *MockInventoryAPI) GetPriceIn(productID uint, currency money.Currency) (money.Money, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPriceIn", productID, currency)
+	ret0, _ := ret[0].(money.Money)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPriceIn indicates an expected call of GetPriceIn
+func (mr *MockInventoryAPIMockRecorder) GetPriceIn(productID, currency interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPriceIn", reflect.TypeOf((*MockInventoryAPI)(nil).GetPriceIn), productID, currency)
+}
+
+// GetCategory mocks base method
+func (m *MockInventoryAPI) GetCategory(productID uint) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategory", productID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategory indicates an expected call of GetCategory
+func (mr *MockInventoryAPIMockRecorder) GetCategory(productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategory", reflect.TypeOf((*MockInventoryAPI)(nil).GetCategory), productID)
+}
+
+// GetTaxClass mocks base method
+func (m *MockInventoryAPI) GetTaxClass(productID uint) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaxClass", productID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaxClass indicates an expected call of GetTaxClass
+func (mr *MockInventoryAPIMockRecorder) GetTaxClass(productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaxClass", reflect.TypeOf((*MockInventoryAPI)(nil).GetTaxClass), productID)
+}
+
+// GetShippingWeight mocks base method
+func (m *MockInventoryAPI) GetShippingWeight(productID uint) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShippingWeight", productID)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShippingWeight indicates an expected call of GetShippingWeight
+func (mr *MockInventoryAPIMockRecorder) GetShippingWeight(productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShippingWeight", reflect.TypeOf((*MockInventoryAPI)(nil).GetShippingWeight), productID)
+}
+
+// RemoveFromStock mocks base method
+func (m *MockInventoryAPI) RemoveFromStock(items map[uint]uint, commitChan <-chan bool, errorChan chan<- error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveFromStock", items, commitChan, errorChan)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveFromStock indicates an expected call of RemoveFromStock
+func (mr *MockInventoryAPIMockRecorder) RemoveFromStock(items, commitChan, errorChan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFromStock", reflect.TypeOf((*MockInventoryAPI)(nil).RemoveFromStock), items, commitChan, errorChan)
+}
+
+// ReturnToStock mocks base method
+func (m *MockInventoryAPI) ReturnToStock(items map[uint]uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReturnToStock", items)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReturnToStock indicates an expected call of ReturnToStock
+func (mr *MockInventoryAPIMockRecorder) ReturnToStock(items interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReturnToStock", reflect.TypeOf((*MockInventoryAPI)(nil).ReturnToStock), items)
+}
+
+// MockPaymentsAPI is a mock of PaymentsAPI interface
+type MockPaymentsAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentsAPIMockRecorder
+}
+
+// MockPaymentsAPIMockRecorder is the mock recorder for MockPaymentsAPI
+type MockPaymentsAPIMockRecorder struct {
+	mock *MockPaymentsAPI
+}
+
+// NewMockPaymentsAPI creates a new mock instance
+func NewMockPaymentsAPI(ctrl *gomock.Controller) *MockPaymentsAPI {
+	mock := &MockPaymentsAPI{ctrl: ctrl}
+	mock.recorder = &MockPaymentsAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPaymentsAPI) EXPECT() *MockPaymentsAPIMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method
+func (m *MockPaymentsAPI) Authorize(ctx context.Context, userID string, amount money.Money) (*store.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, userID, amount)
+	ret0, _ := ret[0].(*store.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authorize indicates an expected call of Authorize
+func (mr *MockPaymentsAPIMockRecorder) Authorize(ctx, userID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockPaymentsAPI)(nil).Authorize), ctx, userID, amount)
+}
+
+// Void mocks base method
+func (m *MockPaymentsAPI) Void(ctx context.Context, paymentID string) (*store.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Void", ctx, paymentID)
+	ret0, _ := ret[0].(*store.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Void indicates an expected call of Void
+func (mr *MockPaymentsAPIMockRecorder) Void(ctx, paymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Void", reflect.TypeOf((*MockPaymentsAPI)(nil).Void), ctx, paymentID)
+}
+
+// MockWalletAPI is a mock of WalletAPI interface
+type MockWalletAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockWalletAPIMockRecorder
+}
+
+// MockWalletAPIMockRecorder is the mock recorder for MockWalletAPI
+type MockWalletAPIMockRecorder struct {
+	mock *MockWalletAPI
+}
+
+// NewMockWalletAPI creates a new mock instance
+func NewMockWalletAPI(ctrl *gomock.Controller) *MockWalletAPI {
+	mock := &MockWalletAPI{ctrl: ctrl}
+	mock.recorder = &MockWalletAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWalletAPI) EXPECT() *MockWalletAPIMockRecorder {
+	return m.recorder
+}
+
+// Spend mocks base method
+func (m *MockWalletAPI) Spend(userID string, amount uint, giftCards []string, storeCredit bool) (*store1.Tender, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Spend", userID, amount, giftCards, storeCredit)
+	ret0, _ := ret[0].(*store1.Tender)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Spend indicates an expected call of Spend
+func (mr *MockWalletAPIMockRecorder) Spend(userID, amount, giftCards, storeCredit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Spend", reflect.TypeOf((*MockWalletAPI)(nil).Spend), userID, amount, giftCards, storeCredit)
+}
+
+// Reverse mocks base method
+func (m *MockWalletAPI) Reverse(tenderID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reverse", tenderID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reverse indicates an expected call of Reverse
+func (mr *MockWalletAPIMockRecorder) Reverse(tenderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reverse", reflect.TypeOf((*MockWalletAPI)(nil).Reverse), tenderID)
+}
+
+// MockOrdersAPI is a mock of OrdersAPI interface
+type MockOrdersAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrdersAPIMockRecorder
+}
+
+// MockOrdersAPIMockRecorder is the mock recorder for MockOrdersAPI
+type MockOrdersAPIMockRecorder struct {
+	mock *MockOrdersAPI
+}
+
+// NewMockOrdersAPI creates a new mock instance
+func NewMockOrdersAPI(ctrl *gomock.Controller) *MockOrdersAPI {
+	mock := &MockOrdersAPI{ctrl: ctrl}
+	mock.recorder = &MockOrdersAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockOrdersAPI) EXPECT() *MockOrdersAPIMockRecorder {
+	return m.recorder
+}
+
+// PlaceOrder mocks base method
+func (m *MockOrdersAPI) PlaceOrder(userID, paymentID string, contents *cart.Contents) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlaceOrder", userID, paymentID, contents)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PlaceOrder indicates an expected call of PlaceOrder
+func (mr *MockOrdersAPIMockRecorder) PlaceOrder(userID, paymentID, contents interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlaceOrder", reflect.TypeOf((*MockOrdersAPI)(nil).PlaceOrder), userID, paymentID, contents)
+}
+
+// MockPromotionsAPI is a mock of PromotionsAPI interface
+type MockPromotionsAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockPromotionsAPIMockRecorder
+}
+
+// MockPromotionsAPIMockRecorder is the mock recorder for MockPromotionsAPI
+type MockPromotionsAPIMockRecorder struct {
+	mock *MockPromotionsAPI
+}
+
+// NewMockPromotionsAPI creates a new mock instance
+func NewMockPromotionsAPI(ctrl *gomock.Controller) *MockPromotionsAPI {
+	mock := &MockPromotionsAPI{ctrl: ctrl}
+	mock.recorder = &MockPromotionsAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPromotionsAPI) EXPECT() *MockPromotionsAPIMockRecorder {
+	return m.recorder
+}
+
+// CheckCoupon mocks base method
+func (m *MockPromotionsAPI) CheckCoupon(code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckCoupon", code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckCoupon indicates an expected call of CheckCoupon
+func (mr *MockPromotionsAPIMockRecorder) CheckCoupon(code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckCoupon", reflect.TypeOf((*MockPromotionsAPI)(nil).CheckCoupon), code)
+}
+
+// Apply mocks base method
+func (m *MockPromotionsAPI) Apply(lines []engine.Line, codes []string) (*engine.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Apply", lines, codes)
+	ret0, _ := ret[0].(*engine.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Apply indicates an expected call of Apply
+func (mr *MockPromotionsAPIMockRecorder) Apply(lines, codes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockPromotionsAPI)(nil).Apply), lines, codes)
+}
+
+// Redeem mocks base method
+func (m *MockPromotionsAPI) Redeem(userID string, result *engine.Result) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeem", userID, result)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Redeem indicates an expected call of Redeem
+func (mr *MockPromotionsAPIMockRecorder) Redeem(userID, result interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeem", reflect.TypeOf((*MockPromotionsAPI)(nil).Redeem), userID, result)
+}
+
+// Release mocks base method
+func (m *MockPromotionsAPI) Release(userID string, result *engine.Result) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", userID, result)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release
+func (mr *MockPromotionsAPIMockRecorder) Release(userID, result interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockPromotionsAPI)(nil).Release), userID, result)
+}
+
+// MockTaxAPI is a mock of TaxAPI interface
+type MockTaxAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaxAPIMockRecorder
+}
+
+// MockTaxAPIMockRecorder is the mock recorder for MockTaxAPI
+type MockTaxAPIMockRecorder struct {
+	mock *MockTaxAPI
+}
+
+// NewMockTaxAPI creates a new mock instance
+func NewMockTaxAPI(ctrl *gomock.Controller) *MockTaxAPI {
+	mock := &MockTaxAPI{ctrl: ctrl}
+	mock.recorder = &MockTaxAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTaxAPI) EXPECT() *MockTaxAPIMockRecorder {
+	return m.recorder
+}
+
+// Calculate mocks base method
+func (m *MockTaxAPI) Calculate(region string, lines []calculator.Line) (*calculator.Breakdown, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Calculate", region, lines)
+	ret0, _ := ret[0].(*calculator.Breakdown)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Calculate indicates an expected call of Calculate
+func (mr *MockTaxAPIMockRecorder) Calculate(region, lines interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Calculate", reflect.TypeOf((*MockTaxAPI)(nil).Calculate), region, lines)
+}
+
+// MockShippingAPI is a mock of ShippingAPI interface
+type MockShippingAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockShippingAPIMockRecorder
+}
+
+// MockShippingAPIMockRecorder is the mock recorder for MockShippingAPI
+type MockShippingAPIMockRecorder struct {
+	mock *MockShippingAPI
+}
+
+// NewMockShippingAPI creates a new mock instance
+func NewMockShippingAPI(ctrl *gomock.Controller) *MockShippingAPI {
+	mock := &MockShippingAPI{ctrl: ctrl}
+	mock.recorder = &MockShippingAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockShippingAPI) EXPECT() *MockShippingAPIMockRecorder {
+	return m.recorder
+}
+
+// Quote mocks base method
+func (m *MockShippingAPI) Quote(region string, parcel rates.Parcel) ([]*rates.Quote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Quote", region, parcel)
+	ret0, _ := ret[0].([]*rates.Quote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Quote indicates an expected call of Quote
+func (mr *MockShippingAPIMockRecorder) Quote(region, parcel interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Quote", reflect.TypeOf((*MockShippingAPI)(nil).Quote), region, parcel)
+}
+
+// Cost mocks base method
+func (m *MockShippingAPI) Cost(region, methodID string, parcel rates.Parcel) (*rates.Quote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cost", region, methodID, parcel)
+	ret0, _ := ret[0].(*rates.Quote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Cost indicates an expected call of Cost
+func (mr *MockShippingAPIMockRecorder) Cost(region, methodID, parcel interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cost", reflect.TypeOf((*MockShippingAPI)(nil).Cost), region, methodID, parcel)
+}
+
+// MockAddressBookAPI is a mock of AddressBookAPI interface
+type MockAddressBookAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockAddressBookAPIMockRecorder
+}
+
+// MockAddressBookAPIMockRecorder is the mock recorder for MockAddressBookAPI
+type MockAddressBookAPIMockRecorder struct {
+	mock *MockAddressBookAPI
+}
+
+// NewMockAddressBookAPI creates a new mock instance
+func NewMockAddressBookAPI(ctrl *gomock.Controller) *MockAddressBookAPI {
+	mock := &MockAddressBookAPI{ctrl: ctrl}
+	mock.recorder = &MockAddressBookAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAddressBookAPI) EXPECT() *MockAddressBookAPIMockRecorder {
+	return m.recorder
+}
+
+// GetAddress mocks base method
+func (m *MockAddressBookAPI) GetAddress(userID string, addressID uint) (*store0.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAddress", userID, addressID)
+	ret0, _ := ret[0].(*store0.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAddress indicates an expected call of GetAddress
+func (mr *MockAddressBookAPIMockRecorder) GetAddress(userID, addressID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAddress", reflect.TypeOf((*MockAddressBookAPI)(nil).GetAddress), userID, addressID)
+}
+
+// GetDefaultShippingAddress mocks base method
+func (m *MockAddressBookAPI) GetDefaultShippingAddress(userID string) (*store0.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDefaultShippingAddress", userID)
+	ret0, _ := ret[0].(*store0.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDefaultShippingAddress indicates an expected call of GetDefaultShippingAddress
+func (mr *MockAddressBookAPIMockRecorder) GetDefaultShippingAddress(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDefaultShippingAddress", reflect.TypeOf((*MockAddressBookAPI)(nil).GetDefaultShippingAddress), userID)
+}
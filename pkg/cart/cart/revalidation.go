@@ -0,0 +1,179 @@
+package cart
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mimatache/go-shop/internal/store"
+)
+
+// WarningType describes how a product in the cart changed since it was added
+type WarningType string
+
+const (
+	// PriceIncreased the unit price is higher than when the product was added
+	PriceIncreased WarningType = "price_increased"
+	// PriceDecreased the unit price is lower than when the product was added
+	PriceDecreased WarningType = "price_decreased"
+	// QuantityReduced there is less stock available than the quantity in the cart
+	QuantityReduced WarningType = "quantity_reduced"
+	// Discontinued the product is no longer sold
+	Discontinued WarningType = "discontinued"
+)
+
+// Warning reports a change of a product in the cart that the user has to acknowledge before checkout.
+// Previous and Current hold either unit prices or quantities, depending on the type of the warning
+type Warning struct {
+	ProductID uint        `json:"productID"`
+	Type      WarningType `json:"type"`
+	Previous  uint        `json:"previous"`
+	Current   uint        `json:"current"`
+}
+
+// Acknowledgement holds the warnings the user has seen and accepts
+type Acknowledgement struct {
+	Warnings []*Warning `json:"warnings"`
+}
+
+type unacknowledgedChanges struct {
+	warnings []*Warning
+}
+
+func (u unacknowledgedChanges) Error() string {
+	if len(u.warnings) == 0 {
+		return "the cart has no changes to acknowledge"
+	}
+	return fmt.Sprintf("the cart has %d changes that have to be acknowledged", len(u.warnings))
+}
+
+// IsUnacknowledgedChangesError verifies if a given error refers to changes of the cart that were not acknowledged
+func IsUnacknowledgedChangesError(err error) bool {
+	switch err.(type) {
+	case unacknowledgedChanges:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewUnacknowledgedChanges creates a new unacknowledged changes error
+func NewUnacknowledgedChanges(warnings []*Warning) error {
+	return unacknowledgedChanges{warnings: warnings}
+}
+
+// GetWarnings returns the warnings carried by an unacknowledged changes error
+func GetWarnings(err error) []*Warning {
+	if u, ok := err.(unacknowledgedChanges); ok {
+		return u.warnings
+	}
+	return nil
+}
+
+// Acknowledge accepts the changes of the cart, as long as the given warnings are exactly the ones currently
+// reported. Quantities are reduced to the stock available, discontinued products are removed and the current
+// prices are recorded
+func (c *Cart) Acknowledge(userID string, acknowledgement Acknowledgement) (*Contents, error) {
+	currentContents, err := c.getContents(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !sameWarnings(currentContents.Warnings, acknowledgement.Warnings) {
+		return nil, NewUnacknowledgedChanges(currentContents.Warnings)
+	}
+	if len(currentContents.Warnings) == 0 {
+		return currentContents, nil
+	}
+
+	quantities := map[uint]uint{}
+	for _, item := range currentContents.Products {
+		quantities[item.ID] = item.Quantity
+	}
+	changed := map[uint]bool{}
+	for _, warning := range currentContents.Warnings {
+		if changed[warning.ProductID] {
+			continue
+		}
+		changed[warning.ProductID] = true
+		var price uint
+		if warning.Type != Discontinued {
+			price, err = c.inventory.GetPrice(warning.ProductID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		err = c.cartContents.UpdateProduct(userID, warning.ProductID, quantities[warning.ProductID], price)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.getContents(userID)
+}
+
+// revalidate compares the products in the cart with their current price and stock. The contents are changed to
+// what can still be bought: quantities are reduced to the stock available and discontinued products are dropped.
+// Every difference is reported as a warning
+func (c *Cart) revalidate(contents *Contents, prices map[uint]uint) error {
+	products := make([]*Product, 0, len(contents.Products))
+	warnings := []*Warning{}
+	for _, item := range contents.Products {
+		price, err := c.inventory.GetPrice(item.ID)
+		if err != nil {
+			if store.IsNotFoundError(err) {
+				warnings = append(warnings, &Warning{ProductID: item.ID, Type: Discontinued, Previous: item.Quantity})
+				continue
+			}
+			return err
+		}
+		stock, err := c.inventory.GetProductStock(item.ID)
+		if err != nil {
+			return err
+		}
+		if item.Quantity > stock {
+			warnings = append(warnings, &Warning{ProductID: item.ID, Type: QuantityReduced, Previous: item.Quantity, Current: stock})
+			item = &Product{ID: item.ID, Quantity: stock}
+		}
+		if previous, ok := prices[item.ID]; ok && previous != price {
+			warning := &Warning{ProductID: item.ID, Type: PriceIncreased, Previous: previous, Current: price}
+			if price < previous {
+				warning.Type = PriceDecreased
+			}
+			warnings = append(warnings, warning)
+		}
+		if item.Quantity > 0 {
+			products = append(products, item)
+		}
+	}
+	sortWarnings(warnings)
+	contents.Products = products
+	contents.Warnings = warnings
+	return nil
+}
+
+func sortWarnings(warnings []*Warning) {
+	sort.Slice(warnings, func(i, j int) bool {
+		if warnings[i].ProductID != warnings[j].ProductID {
+			return warnings[i].ProductID < warnings[j].ProductID
+		}
+		return warnings[i].Type < warnings[j].Type
+	})
+}
+
+func sameWarnings(current []*Warning, acknowledged []*Warning) bool {
+	if len(current) != len(acknowledged) {
+		return false
+	}
+	sorted := make([]*Warning, 0, len(acknowledged))
+	for _, warning := range acknowledged {
+		if warning == nil {
+			return false
+		}
+		sorted = append(sorted, warning)
+	}
+	sortWarnings(sorted)
+	for i := range current {
+		if *current[i] != *sorted[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+package cart_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/cart/cart"
+)
+
+type current struct {
+	price        uint
+	stock        uint
+	discontinued bool
+}
+
+func (m *mocks) expectContents(products map[uint]uint, prices map[uint]uint, inventory map[uint]current) {
+	m.cartContents.EXPECT().GetProductsForUser(userID).Return(products, nil)
+	m.cartContents.EXPECT().GetCouponsForUser(userID).Return(nil, nil)
+	m.cartContents.EXPECT().GetPricesForUser(userID).Return(prices, nil)
+	for prodID, product := range inventory {
+		if product.discontinued {
+			m.inventory.EXPECT().GetPrice(prodID).Return(uint(0), store.NewNotFoundError("products", "id", prodID))
+			continue
+		}
+		m.inventory.EXPECT().GetPrice(prodID).Return(product.price, nil)
+		m.inventory.EXPECT().GetProductStock(prodID).Return(product.stock, nil)
+	}
+}
+
+var changedCart = struct {
+	products  map[uint]uint
+	prices    map[uint]uint
+	inventory map[uint]current
+	warnings  []*cart.Warning
+}{
+	products: map[uint]uint{1: 2, 2: 1, 3: 4, 4: 1, 5: 1},
+	prices:   map[uint]uint{1: 10, 2: 10, 3: 10, 4: 10, 5: 10},
+	inventory: map[uint]current{
+		1: {price: 10, stock: 5},
+		2: {price: 12, stock: 5},
+		3: {price: 8, stock: 3},
+		4: {discontinued: true},
+		5: {price: 10, stock: 0},
+	},
+	warnings: []*cart.Warning{
+		{ProductID: 2, Type: cart.PriceIncreased, Previous: 10, Current: 12},
+		{ProductID: 3, Type: cart.PriceDecreased, Previous: 10, Current: 8},
+		{ProductID: 3, Type: cart.QuantityReduced, Previous: 4, Current: 3},
+		{ProductID: 4, Type: cart.Discontinued, Previous: 1},
+		{ProductID: 5, Type: cart.QuantityReduced, Previous: 1},
+	},
+}
+
+func TestCart_Checkout_UnacknowledgedChanges(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	m.expectContents(changedCart.products, changedCart.prices, changedCart.inventory)
+
+	_, err := shoppingCart.Checkout(context.Background(), userID, cart.Delivery{
+		ShippingMethod: "standard",
+		Address:        &cart.Address{Name: "John Doe", Street: "Main Street", City: "Bucharest", Region: "RO"},
+	}, cart.Tender{}, base)
+
+	g.Expect(cart.IsUnacknowledgedChangesError(err)).To(BeTrue())
+	g.Expect(cart.GetWarnings(err)).To(Equal(changedCart.warnings))
+}
+
+func TestCart_Acknowledge(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	m.expectContents(changedCart.products, changedCart.prices, changedCart.inventory)
+	m.inventory.EXPECT().GetPrice(uint(2)).Return(uint(12), nil)
+	m.inventory.EXPECT().GetPrice(uint(3)).Return(uint(8), nil)
+	m.inventory.EXPECT().GetPrice(uint(5)).Return(uint(10), nil)
+	m.cartContents.EXPECT().UpdateProduct(userID, uint(2), uint(1), uint(12)).Return(nil)
+	m.cartContents.EXPECT().UpdateProduct(userID, uint(3), uint(3), uint(8)).Return(nil)
+	m.cartContents.EXPECT().UpdateProduct(userID, uint(4), uint(0), uint(0)).Return(nil)
+	m.cartContents.EXPECT().UpdateProduct(userID, uint(5), uint(0), uint(10)).Return(nil)
+	m.expectContents(
+		map[uint]uint{1: 2, 2: 1, 3: 3},
+		map[uint]uint{1: 10, 2: 12, 3: 8},
+		map[uint]current{1: {price: 10, stock: 5}, 2: {price: 12, stock: 5}, 3: {price: 8, stock: 3}},
+	)
+
+	acknowledged := []*cart.Warning{}
+	for i := len(changedCart.warnings) - 1; i >= 0; i-- {
+		warning := *changedCart.warnings[i]
+		acknowledged = append(acknowledged, &warning)
+	}
+	contents, err := shoppingCart.Acknowledge(userID, cart.Acknowledgement{Warnings: acknowledged})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(contents.Warnings).To(BeEmpty())
+	g.Expect(contents.Products).To(HaveLen(3))
+}
+
+func TestCart_Acknowledge_NewChanges(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	m.expectContents(changedCart.products, changedCart.prices, changedCart.inventory)
+
+	_, err := shoppingCart.Acknowledge(userID, cart.Acknowledgement{Warnings: changedCart.warnings[:2]})
+
+	g.Expect(cart.IsUnacknowledgedChangesError(err)).To(BeTrue(), "changes the user has not seen should not be accepted")
+	g.Expect(cart.GetWarnings(err)).To(Equal(changedCart.warnings))
+}
@@ -0,0 +1,192 @@
+package cart
+
+import (
+	"github.com/mimatache/go-shop/internal/money"
+	"github.com/mimatache/go-shop/pkg/promotions/engine"
+	"github.com/mimatache/go-shop/pkg/shipping/rates"
+	"github.com/mimatache/go-shop/pkg/tax/calculator"
+)
+
+// Summary represents the cost of the cart contents. Every amount is given in the minor unit of the currency
+type Summary struct {
+	Currency   money.Currency        `json:"currency"`
+	Subtotal   uint                  `json:"subtotal"`
+	Discount   uint                  `json:"discount"`
+	Promotions []*engine.Applied     `json:"promotions,omitempty"`
+	Tax        *calculator.Breakdown `json:"tax"`
+	Shipping   *rates.Quote          `json:"shipping,omitempty"`
+	Total      uint                  `json:"total"`
+
+	promotions *engine.Result
+	parcel     rates.Parcel
+}
+
+// summarize computes the cost of the cart contents in the given currency. Promotions are applied first and the taxes
+// are computed on the discounted amounts. If a shipping method is given, its cost is added to the total.
+// Promotions and shipping rates are set in the base currency, so in any other currency every line keeps the share
+// of its price the promotions took off, and the shipping cost is converted
+func (c *Cart) summarize(contents *Contents, region string, shippingMethod string, currency money.Currency) (*Summary, error) {
+	base := c.rates.Base()
+	lines := make([]engine.Line, 0, len(contents.Products))
+	amounts := make([]uint, 0, len(contents.Products))
+	classes := make([]string, 0, len(contents.Products))
+	var weight uint
+	for _, item := range contents.Products {
+		price, err := c.inventory.GetPrice(item.ID)
+		if err != nil {
+			return nil, err
+		}
+		unitPrice := price
+		if currency != base {
+			localPrice, err := c.inventory.GetPriceIn(item.ID, currency)
+			if err != nil {
+				return nil, err
+			}
+			unitPrice = localPrice.Amount
+		}
+		amount, err := money.Mul(unitPrice, item.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		category, err := c.inventory.GetCategory(item.ID)
+		if err != nil {
+			return nil, err
+		}
+		class, err := c.inventory.GetTaxClass(item.ID)
+		if err != nil {
+			return nil, err
+		}
+		itemWeight, err := c.inventory.GetShippingWeight(item.ID)
+		if err != nil {
+			return nil, err
+		}
+		lineWeight, err := money.Mul(itemWeight, item.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		if weight, err = money.Add(weight, lineWeight); err != nil {
+			return nil, err
+		}
+		lines = append(lines, engine.Line{
+			ProductID: item.ID,
+			Category:  category,
+			UnitPrice: price,
+			Quantity:  item.Quantity,
+		})
+		amounts = append(amounts, amount)
+		classes = append(classes, class)
+	}
+
+	promotions, err := c.promotions.Apply(lines, contents.Coupons)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{
+		Currency:   currency,
+		Subtotal:   promotions.Subtotal,
+		Discount:   promotions.Discount,
+		Promotions: promotions.Promotions,
+		promotions: promotions,
+	}
+	discounts := promotions.LineDiscounts
+	if currency != base {
+		discounts = make([]uint, len(lines))
+		summary.Subtotal, summary.Discount = 0, 0
+		for i, line := range lines {
+			baseAmount, err := money.Mul(line.UnitPrice, line.Quantity)
+			if err != nil {
+				return nil, err
+			}
+			discounts[i], err = money.Scale(promotions.LineDiscounts[i], amounts[i], baseAmount)
+			if err != nil {
+				return nil, err
+			}
+			if summary.Subtotal, err = money.Add(summary.Subtotal, amounts[i]); err != nil {
+				return nil, err
+			}
+			if summary.Discount, err = money.Add(summary.Discount, discounts[i]); err != nil {
+				return nil, err
+			}
+		}
+		summary.Promotions, err = localizePromotions(promotions.Promotions, summary.Discount, promotions.Discount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	taxLines := make([]calculator.Line, len(lines))
+	for i, line := range lines {
+		amount, err := money.Sub(amounts[i], discounts[i])
+		if err != nil {
+			return nil, err
+		}
+		taxLines[i] = calculator.Line{
+			ProductID: line.ProductID,
+			Class:     classes[i],
+			Amount:    amount,
+		}
+	}
+	summary.Tax, err = c.taxes.Calculate(region, taxLines)
+	if err != nil {
+		return nil, err
+	}
+	summary.Total = summary.Tax.Gross
+
+	value, err := c.rates.Convert(money.New(summary.Tax.Gross, currency), base)
+	if err != nil {
+		return nil, err
+	}
+	summary.parcel = rates.Parcel{Weight: weight, Value: value.Amount}
+	if shippingMethod == "" {
+		return summary, nil
+	}
+	quote, err := c.shipping.Cost(region, shippingMethod, summary.parcel)
+	if err != nil {
+		return nil, err
+	}
+	summary.Shipping, err = c.localizeQuote(quote, currency)
+	if err != nil {
+		return nil, err
+	}
+	summary.Total, err = money.Add(summary.Total, summary.Shipping.Cost)
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// localizeQuote returns the shipping quote with its cost converted from the base currency
+func (c *Cart) localizeQuote(quote *rates.Quote, currency money.Currency) (*rates.Quote, error) {
+	if currency == c.rates.Base() {
+		return quote, nil
+	}
+	cost, err := c.rates.Convert(money.New(quote.Cost, c.rates.Base()), currency)
+	if err != nil {
+		return nil, err
+	}
+	localized := *quote
+	localized.Cost = cost.Amount
+	return &localized, nil
+}
+
+// localizePromotions spreads a discount given in another currency over the applied promotions, in proportion to
+// what each of them took off in the base currency. The last promotion takes what is left, so that they add up
+func localizePromotions(applied []*engine.Applied, discount uint, baseDiscount uint) ([]*engine.Applied, error) {
+	localized := make([]*engine.Applied, len(applied))
+	left := discount
+	for i, promotion := range applied {
+		share, err := money.Scale(promotion.Discount, discount, baseDiscount)
+		if err != nil {
+			return nil, err
+		}
+		if i == len(applied)-1 || share > left {
+			share = left
+		}
+		copied := *promotion
+		copied.Discount = share
+		localized[i] = &copied
+		left -= share
+	}
+	return localized, nil
+}
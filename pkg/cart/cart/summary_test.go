@@ -0,0 +1,46 @@
+package cart_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/money"
+	"github.com/mimatache/go-shop/pkg/promotions/engine"
+)
+
+func TestCart_GetContents_WeightOverflow(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	m.expectContents(map[uint]uint{1: 2}, map[uint]uint{1: price}, map[uint]current{1: {price: price, stock: 5}})
+	m.inventory.EXPECT().GetPrice(uint(1)).Return(price, nil)
+	m.inventory.EXPECT().GetCategory(uint(1)).Return("books", nil)
+	m.inventory.EXPECT().GetTaxClass(uint(1)).Return("standard", nil)
+	m.inventory.EXPECT().GetShippingWeight(uint(1)).Return(^uint(0)/2+1, nil)
+
+	_, err := shoppingCart.GetContents(userID, "RO", base)
+
+	g.Expect(money.IsOverflowError(err)).To(BeTrue())
+}
+
+func TestCart_GetContents_DiscountAbovePrice(t *testing.T) {
+	g := NewWithT(t)
+
+	shoppingCart, m, finish := newCart(t)
+	defer finish()
+
+	m.expectContents(map[uint]uint{1: 2}, map[uint]uint{1: price}, map[uint]current{1: {price: price, stock: 5}})
+	m.inventory.EXPECT().GetPrice(uint(1)).Return(price, nil)
+	m.inventory.EXPECT().GetCategory(uint(1)).Return("books", nil)
+	m.inventory.EXPECT().GetTaxClass(uint(1)).Return("standard", nil)
+	m.inventory.EXPECT().GetShippingWeight(uint(1)).Return(uint(100), nil)
+	m.promotions.EXPECT().Apply(gomock.Any(), gomock.Any()).Return(&engine.Result{Subtotal: 20, Discount: 30, LineDiscounts: []uint{30}}, nil)
+
+	_, err := shoppingCart.GetContents(userID, "RO", base)
+
+	g.Expect(money.IsOverflowError(err)).To(BeTrue(), "a line cannot cost less than nothing")
+}
@@ -0,0 +1,13 @@
+package cart
+
+// Tender selects what pays for a checkout before the payment provider. Gift cards are used first, in the given order,
+// followed by the store credit of the user when asked to. The payment provider is charged whatever is left
+type Tender struct {
+	StoreCredit bool     `json:"storeCredit,omitempty"`
+	GiftCards   []string `json:"giftCards,omitempty"`
+}
+
+// IsEmpty checks if the whole checkout is paid through the payment provider
+func (t Tender) IsEmpty() bool {
+	return !t.StoreCredit && len(t.GiftCards) == 0
+}
@@ -8,9 +8,44 @@ import (
 
 	"github.com/mimatache/go-shop/internal/http/authorization"
 	"github.com/mimatache/go-shop/internal/http/helpers"
+	"github.com/mimatache/go-shop/internal/money"
+	"github.com/mimatache/go-shop/internal/store"
 	"github.com/mimatache/go-shop/pkg/cart/cart"
+	"github.com/mimatache/go-shop/pkg/payments/provider"
+	"github.com/mimatache/go-shop/pkg/promotions/engine"
+	"github.com/mimatache/go-shop/pkg/shipping/rates"
+	"github.com/mimatache/go-shop/pkg/tax/calculator"
+	"github.com/mimatache/go-shop/pkg/wallet/wallet"
 )
 
+const (
+	// regionParam is the query parameter used to select the tax region
+	regionParam = "region"
+	// currencyParam is the query parameter used to select the currency the cart is priced in
+	currencyParam = "currency"
+)
+
+// checkoutRequest holds the delivery details of a checkout together with the gift cards and store credit paying for it
+type checkoutRequest struct {
+	cart.Delivery
+	cart.Tender
+}
+
+// changesError is returned when the cart has changes that the user has to acknowledge
+type changesError struct {
+	Error    string          `json:"error"`
+	Code     int             `json:"code"`
+	Warnings []*cart.Warning `json:"warnings"`
+}
+
+func formatChangesError(w http.ResponseWriter, err error) {
+	helpers.FormatResponse(w, &changesError{
+		Error:    err.Error(),
+		Code:     http.StatusConflict,
+		Warnings: cart.GetWarnings(err),
+	}, http.StatusConflict)
+}
+
 func New(cart *cart.Cart) *ShoppingCart {
 	return &ShoppingCart{
 		cart: cart,
@@ -21,6 +56,17 @@ type ShoppingCart struct {
 	cart *cart.Cart
 }
 
+// getCurrency returns the currency selected by the request, or the base currency if none is selected.
+// If the currency is not supported, the error is written to the response
+func (s *ShoppingCart) getCurrency(w http.ResponseWriter, r *http.Request) (money.Currency, bool) {
+	currency, err := s.cart.ParseCurrency(r.URL.Query().Get(currencyParam))
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+		return "", false
+	}
+	return currency, true
+}
+
 func (s *ShoppingCart) addProductToCart(w http.ResponseWriter, r *http.Request) {
 	userID, err := authorization.GetUserIDFromRequest(r)
 	if err != nil {
@@ -45,14 +91,161 @@ func (s *ShoppingCart) addProductToCart(w http.ResponseWriter, r *http.Request)
 	helpers.FormatResponse(w, currentContents, http.StatusOK)
 }
 
+func (s *ShoppingCart) removeProductFromCart(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var prod cart.Product
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&prod)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	currentContents, err := s.cart.RemoveProductFromCart(userID, prod)
+	if err != nil {
+		if cart.IsInvalidQuantityError(err) {
+			helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if store.IsNotFoundError(err) {
+			helpers.FormatError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.FormatResponse(w, currentContents, http.StatusOK)
+}
+
+func (s *ShoppingCart) addCoupon(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	currency, ok := s.getCurrency(w, r)
+	if !ok {
+		return
+	}
+	var coupon cart.Coupon
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&coupon)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	currentContents, err := s.cart.AddCoupon(userID, coupon, r.URL.Query().Get(regionParam), currency)
+	if err != nil {
+		if engine.IsInvalidCouponError(err) || calculator.IsUnknownRateError(err) || money.IsOverflowError(err) {
+			helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.FormatResponse(w, currentContents, http.StatusOK)
+}
+
+func (s *ShoppingCart) getCart(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	currency, ok := s.getCurrency(w, r)
+	if !ok {
+		return
+	}
+	contents, err := s.cart.GetContents(userID, r.URL.Query().Get(regionParam), currency)
+	if err != nil {
+		if calculator.IsUnknownRateError(err) || money.IsOverflowError(err) {
+			helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.FormatResponse(w, contents, http.StatusOK)
+}
+
+func (s *ShoppingCart) quoteShipping(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	region := r.URL.Query().Get(regionParam)
+	if region == "" {
+		helpers.FormatError(w, "the region to ship to is mandatory", http.StatusBadRequest)
+		return
+	}
+	currency, ok := s.getCurrency(w, r)
+	if !ok {
+		return
+	}
+	quotes, err := s.cart.QuoteShipping(userID, region, currency)
+	if err != nil {
+		if store.IsNotFoundError(err) {
+			helpers.FormatError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if rates.IsUnavailableError(err) || calculator.IsUnknownRateError(err) || money.IsOverflowError(err) {
+			helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.FormatResponse(w, quotes, http.StatusOK)
+}
+
 func (s *ShoppingCart) checkout(w http.ResponseWriter, r *http.Request) {
 	userID, err := authorization.GetUserIDFromRequest(r)
 	if err != nil {
 		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
 		return
 	}
-	contents, err := s.cart.Checkout(userID)
+	currency, ok := s.getCurrency(w, r)
+	if !ok {
+		return
+	}
+	var request checkoutRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&request)
 	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	contents, err := s.cart.Checkout(r.Context(), userID, request.Delivery, request.Tender, currency)
+	if err != nil {
+		if cart.IsUnacknowledgedChangesError(err) {
+			formatChangesError(w, err)
+			return
+		}
+		if cart.IsInvalidDeliveryError(err) || rates.IsUnavailableError(err) || calculator.IsUnknownRateError(err) || wallet.IsInvalidTenderError(err) || money.IsOverflowError(err) {
+			helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if provider.IsDeclinedError(err) {
+			helpers.FormatError(w, err.Error(), http.StatusPaymentRequired)
+			return
+		}
+		if provider.IsUnavailableError(err) {
+			helpers.FormatError(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
 		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
 		return
 	}
@@ -60,12 +253,68 @@ func (s *ShoppingCart) checkout(w http.ResponseWriter, r *http.Request) {
 	helpers.FormatResponse(w, contents, http.StatusOK)
 }
 
-// AddRoutes registers the API routes to a router
-func (s *ShoppingCart) AddRoutes(router *mux.Router, handlers ...func(http.Handler) http.Handler) {
-	cartRouter := router.PathPrefix("/cart").Subrouter()
-	cartRouter.HandleFunc("/add", s.addProductToCart).Methods(http.MethodPost)
-	cartRouter.HandleFunc("/checkout", s.checkout).Methods(http.MethodPost)
-	for _, v := range handlers {
-		cartRouter.Use(v)
+func (s *ShoppingCart) acknowledge(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var acknowledgement cart.Acknowledgement
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&acknowledgement)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	contents, err := s.cart.Acknowledge(userID, acknowledgement)
+	if err != nil {
+		if cart.IsUnacknowledgedChangesError(err) {
+			formatChangesError(w, err)
+			return
+		}
+		if store.IsNotFoundError(err) {
+			helpers.FormatError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.FormatResponse(w, contents, http.StatusOK)
+}
+
+func (s *ShoppingCart) recoverCart(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
 	}
+	contents, err := s.cart.Recover(userID, mux.Vars(r)["token"])
+	if err != nil {
+		if store.IsNotFoundError(err) {
+			helpers.FormatError(w, "the recovery link is invalid or has expired", http.StatusNotFound)
+			return
+		}
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.FormatResponse(w, contents, http.StatusOK)
+}
+
+// AddRoutes registers the API routes to a router.
+// Checkout and cart recovery are wrapped by the user handler, as only logged in users can buy and recovery links
+// belong to a user. The other routes are wrapped
+// by the guest handler, so that visitors can fill a cart before logging in
+func (s *ShoppingCart) AddRoutes(router *mux.Router, userHandler, guestHandler func(http.Handler) http.Handler) {
+	cartRouter := router.PathPrefix("/cart").Subrouter()
+	cartRouter.Handle("", guestHandler(http.HandlerFunc(s.getCart))).Methods(http.MethodGet)
+	cartRouter.Handle("/add", guestHandler(http.HandlerFunc(s.addProductToCart))).Methods(http.MethodPost)
+	cartRouter.Handle("/remove", guestHandler(http.HandlerFunc(s.removeProductFromCart))).Methods(http.MethodPost)
+	cartRouter.Handle("/coupons", guestHandler(http.HandlerFunc(s.addCoupon))).Methods(http.MethodPost)
+	cartRouter.Handle("/acknowledge", guestHandler(http.HandlerFunc(s.acknowledge))).Methods(http.MethodPost)
+	cartRouter.Handle("/shipping", guestHandler(http.HandlerFunc(s.quoteShipping))).Methods(http.MethodGet)
+	cartRouter.Handle("/checkout", userHandler(http.HandlerFunc(s.checkout))).Methods(http.MethodPost)
+	cartRouter.Handle("/recover/{token}", userHandler(http.HandlerFunc(s.recoverCart))).Methods(http.MethodGet)
 }
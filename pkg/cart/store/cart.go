@@ -3,6 +3,7 @@ package store
 import (
 	"bytes"
 	"fmt"
+	"time"
 )
 
 type errors []error
@@ -23,6 +24,14 @@ type Validatable interface {
 type CartItem struct {
 	ID       string        `json:"id"`
 	Products map[uint]uint `json:"products"`
+	Coupons  []string      `json:"coupons"`
+	// Prices holds the unit price of every product when it was first added to the cart, or when a change of price
+	// was last acknowledged by the owner
+	Prices map[uint]uint `json:"prices"`
+	// UpdatedAt is the last time the owner changed the cart
+	UpdatedAt time.Time `json:"updatedAt"`
+	// AbandonedAt is set when the cart is detected as abandoned and reset by any later activity
+	AbandonedAt time.Time `json:"abandonedAt,omitempty"`
 }
 
 func (c CartItem) Validate() error {
@@ -36,10 +45,12 @@ func (c CartItem) Validate() error {
 	return nil
 }
 
-func NewCartItem(user string, product uint, quantity uint) (*CartItem, error) {
+func NewCartItem(user string, product uint, quantity uint, unitPrice uint) (*CartItem, error) {
 	c := CartItem{
-		ID:       user,
-		Products: map[uint]uint{product: quantity},
+		ID:        user,
+		Products:  map[uint]uint{product: quantity},
+		Prices:    map[uint]uint{product: unitPrice},
+		UpdatedAt: time.Now(),
 	}
 	err := c.Validate()
 	if err != nil {
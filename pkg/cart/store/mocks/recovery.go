@@ -0,0 +1,92 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./recovery.go
+
+// Package mock_store is a generated GoMock package.
+package mock_store
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	store "github.com/mimatache/go-shop/pkg/cart/store"
+	reflect "reflect"
+)
+
+// MockRecoveryStore is a mock of RecoveryStore interface
+type MockRecoveryStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockRecoveryStoreMockRecorder
+}
+
+// MockRecoveryStoreMockRecorder is the mock recorder for MockRecoveryStore
+type MockRecoveryStoreMockRecorder struct {
+	mock *MockRecoveryStore
+}
+
+// NewMockRecoveryStore creates a new mock instance
+func NewMockRecoveryStore(ctrl *gomock.Controller) *MockRecoveryStore {
+	mock := &MockRecoveryStore{ctrl: ctrl}
+	mock.recorder = &MockRecoveryStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRecoveryStore) EXPECT() *MockRecoveryStoreMockRecorder {
+	return m.recorder
+}
+
+// AddRecoveryToken mocks base method
+func (m *MockRecoveryStore) AddRecoveryToken(token *store.RecoveryToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRecoveryToken", token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRecoveryToken indicates an expected call of AddRecoveryToken
+func (mr *MockRecoveryStoreMockRecorder) AddRecoveryToken(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRecoveryToken", reflect.TypeOf((*MockRecoveryStore)(nil).AddRecoveryToken), token)
+}
+
+// GetRecoveryToken mocks base method
+func (m *MockRecoveryStore) GetRecoveryToken(token string) (*store.RecoveryToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecoveryToken", token)
+	ret0, _ := ret[0].(*store.RecoveryToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecoveryToken indicates an expected call of GetRecoveryToken
+func (mr *MockRecoveryStoreMockRecorder) GetRecoveryToken(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecoveryToken", reflect.TypeOf((*MockRecoveryStore)(nil).GetRecoveryToken), token)
+}
+
+// GetRecoveryTokens mocks base method
+func (m *MockRecoveryStore) GetRecoveryTokens() ([]*store.RecoveryToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecoveryTokens")
+	ret0, _ := ret[0].([]*store.RecoveryToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecoveryTokens indicates an expected call of GetRecoveryTokens
+func (mr *MockRecoveryStoreMockRecorder) GetRecoveryTokens() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecoveryTokens", reflect.TypeOf((*MockRecoveryStore)(nil).GetRecoveryTokens))
+}
+
+// RemoveRecoveryToken mocks base method
+func (m *MockRecoveryStore) RemoveRecoveryToken(token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveRecoveryToken", token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveRecoveryToken indicates an expected call of RemoveRecoveryToken
+func (mr *MockRecoveryStoreMockRecorder) RemoveRecoveryToken(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveRecoveryToken", reflect.TypeOf((*MockRecoveryStore)(nil).RemoveRecoveryToken), token)
+}
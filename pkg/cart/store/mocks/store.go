@@ -0,0 +1,332 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./store.go
+
+// Package mock_store is a generated GoMock package.
+package mock_store
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	store "github.com/mimatache/go-shop/pkg/cart/store"
+	reflect "reflect"
+	time "time"
+)
+
+// Mocklogger is a mock of logger interface
+type Mocklogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockloggerMockRecorder
+}
+
+// MockloggerMockRecorder is the mock recorder for Mocklogger
+type MockloggerMockRecorder struct {
+	mock *Mocklogger
+}
+
+// NewMocklogger creates a new mock instance
+func NewMocklogger(ctrl *gomock.Controller) *Mocklogger {
+	mock := &Mocklogger{ctrl: ctrl}
+	mock.recorder = &MockloggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *Mocklogger) EXPECT() *MockloggerMockRecorder {
+	return m.recorder
+}
+
+// Infof mocks base method
+func (m *Mocklogger) Infof(msg string, args ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Infof", varargs...)
+}
+
+// Infof indicates an expected call of Infof
+func (mr *MockloggerMockRecorder) Infof(msg interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Infof", reflect.TypeOf((*Mocklogger)(nil).Infof), varargs...)
+}
+
+// Debugf mocks base method
+func (m *Mocklogger) Debugf(msg string, args ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Debugf", varargs...)
+}
+
+// Debugf indicates an expected call of Debugf
+func (mr *MockloggerMockRecorder) Debugf(msg interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Debugf", reflect.TypeOf((*Mocklogger)(nil).Debugf), varargs...)
+}
+
+// Debugw mocks base method
+func (m *Mocklogger) Debugw(msg string, keysAndValues ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range keysAndValues {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Debugw", varargs...)
+}
+
+// Debugw indicates an expected call of Debugw
+func (mr *MockloggerMockRecorder) Debugw(msg interface{}, keysAndValues ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, keysAndValues...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Debugw", reflect.TypeOf((*Mocklogger)(nil).Debugw), varargs...)
+}
+
+// MockUnderlyingStore is a mock of UnderlyingStore interface
+type MockUnderlyingStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockUnderlyingStoreMockRecorder
+}
+
+// MockUnderlyingStoreMockRecorder is the mock recorder for MockUnderlyingStore
+type MockUnderlyingStoreMockRecorder struct {
+	mock *MockUnderlyingStore
+}
+
+// NewMockUnderlyingStore creates a new mock instance
+func NewMockUnderlyingStore(ctrl *gomock.Controller) *MockUnderlyingStore {
+	mock := &MockUnderlyingStore{ctrl: ctrl}
+	mock.recorder = &MockUnderlyingStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockUnderlyingStore) EXPECT() *MockUnderlyingStoreMockRecorder {
+	return m.recorder
+}
+
+// Read mocks base method
+func (m *MockUnderlyingStore) Read(table, key string, value interface{}) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", table, key, value)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read
+func (mr *MockUnderlyingStoreMockRecorder) Read(table, key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockUnderlyingStore)(nil).Read), table, key, value)
+}
+
+// ReadAll mocks base method
+func (m *MockUnderlyingStore) ReadAll(table, key string, args ...interface{}) ([]interface{}, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{table, key}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReadAll", varargs...)
+	ret0, _ := ret[0].([]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAll indicates an expected call of ReadAll
+func (mr *MockUnderlyingStoreMockRecorder) ReadAll(table, key interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{table, key}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAll", reflect.TypeOf((*MockUnderlyingStore)(nil).ReadAll), varargs...)
+}
+
+// Write mocks base method
+func (m *MockUnderlyingStore) Write(table string, objs ...interface{}) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{table}
+	for _, a := range objs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Write", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Write indicates an expected call of Write
+func (mr *MockUnderlyingStoreMockRecorder) Write(table interface{}, objs ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{table}, objs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockUnderlyingStore)(nil).Write), varargs...)
+}
+
+// Remove mocks base method
+func (m *MockUnderlyingStore) Remove(table, key string, value interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", table, key, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove
+func (mr *MockUnderlyingStoreMockRecorder) Remove(table, key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockUnderlyingStore)(nil).Remove), table, key, value)
+}
+
+// MockCartStore is a mock of CartStore interface
+type MockCartStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockCartStoreMockRecorder
+}
+
+// MockCartStoreMockRecorder is the mock recorder for MockCartStore
+type MockCartStoreMockRecorder struct {
+	mock *MockCartStore
+}
+
+// NewMockCartStore creates a new mock instance
+func NewMockCartStore(ctrl *gomock.Controller) *MockCartStore {
+	mock := &MockCartStore{ctrl: ctrl}
+	mock.recorder = &MockCartStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCartStore) EXPECT() *MockCartStoreMockRecorder {
+	return m.recorder
+}
+
+// AddProduct mocks base method
+func (m *MockCartStore) AddProduct(userID string, prodID, quantity, unitPrice uint) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddProduct", userID, prodID, quantity, unitPrice)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddProduct indicates an expected call of AddProduct
+func (mr *MockCartStoreMockRecorder) AddProduct(userID, prodID, quantity, unitPrice interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProduct", reflect.TypeOf((*MockCartStore)(nil).AddProduct), userID, prodID, quantity, unitPrice)
+}
+
+// UpdateProduct mocks base method
+func (m *MockCartStore) UpdateProduct(userID string, prodID, quantity, unitPrice uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProduct", userID, prodID, quantity, unitPrice)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProduct indicates an expected call of UpdateProduct
+func (mr *MockCartStoreMockRecorder) UpdateProduct(userID, prodID, quantity, unitPrice interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockCartStore)(nil).UpdateProduct), userID, prodID, quantity, unitPrice)
+}
+
+// GetProductsForUser mocks base method
+func (m *MockCartStore) GetProductsForUser(userID string) (map[uint]uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductsForUser", userID)
+	ret0, _ := ret[0].(map[uint]uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductsForUser indicates an expected call of GetProductsForUser
+func (mr *MockCartStoreMockRecorder) GetProductsForUser(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductsForUser", reflect.TypeOf((*MockCartStore)(nil).GetProductsForUser), userID)
+}
+
+// GetPricesForUser mocks base method
+func (m *MockCartStore) GetPricesForUser(userID string) (map[uint]uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPricesForUser", userID)
+	ret0, _ := ret[0].(map[uint]uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPricesForUser indicates an expected call of GetPricesForUser
+func (mr *MockCartStoreMockRecorder) GetPricesForUser(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPricesForUser", reflect.TypeOf((*MockCartStore)(nil).GetPricesForUser), userID)
+}
+
+// AddCoupon mocks base method
+func (m *MockCartStore) AddCoupon(userID, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCoupon", userID, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCoupon indicates an expected call of AddCoupon
+func (mr *MockCartStoreMockRecorder) AddCoupon(userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCoupon", reflect.TypeOf((*MockCartStore)(nil).AddCoupon), userID, code)
+}
+
+// GetCouponsForUser mocks base method
+func (m *MockCartStore) GetCouponsForUser(userID string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCouponsForUser", userID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCouponsForUser indicates an expected call of GetCouponsForUser
+func (mr *MockCartStoreMockRecorder) GetCouponsForUser(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCouponsForUser", reflect.TypeOf((*MockCartStore)(nil).GetCouponsForUser), userID)
+}
+
+// ClearCartFor mocks base method
+func (m *MockCartStore) ClearCartFor(userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearCartFor", userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearCartFor indicates an expected call of ClearCartFor
+func (mr *MockCartStoreMockRecorder) ClearCartFor(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearCartFor", reflect.TypeOf((*MockCartStore)(nil).ClearCartFor), userID)
+}
+
+// GetCarts mocks base method
+func (m *MockCartStore) GetCarts() ([]*store.CartItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCarts")
+	ret0, _ := ret[0].([]*store.CartItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCarts indicates an expected call of GetCarts
+func (mr *MockCartStoreMockRecorder) GetCarts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCarts", reflect.TypeOf((*MockCartStore)(nil).GetCarts))
+}
+
+// MarkAbandoned mocks base method
+func (m *MockCartStore) MarkAbandoned(userID string, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAbandoned", userID, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAbandoned indicates an expected call of MarkAbandoned
+func (mr *MockCartStoreMockRecorder) MarkAbandoned(userID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAbandoned", reflect.TypeOf((*MockCartStore)(nil).MarkAbandoned), userID, at)
+}
@@ -0,0 +1,216 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/mimatache/go-shop/internal/store"
+)
+
+//go:generate mockgen -source ./recovery.go -destination mocks/recovery.go
+
+var (
+	recoveryTable = &RecoveryTable{name: "cartRecovery"}
+)
+
+// GetRecoveryTable returns the table holding the cart recovery tokens
+func GetRecoveryTable() *RecoveryTable {
+	return recoveryTable
+}
+
+// RecoveryTable the cart recovery table schema
+type RecoveryTable struct {
+	name string
+}
+
+// GetName returns the name of the cart recovery table
+func (r *RecoveryTable) GetName() string {
+	return r.name
+}
+
+// GetTableSchema returns the schema of the cart recovery table
+func (r *RecoveryTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: r.name,
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:    "id",
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "Token"},
+			},
+			"user": {
+				Name:    "user",
+				Unique:  false,
+				Indexer: &memdb.StringFieldIndex{Field: "UserID"},
+			},
+		},
+	}
+}
+
+// GetPersonalRows returns the recovery tokens of the abandoned carts of the user
+func (r *RecoveryTable) GetPersonalRows(db store.Reader, userID string) ([]interface{}, error) {
+	return db.ReadAll(r.name, "user", userID)
+}
+
+// Export returns the recovery token
+func (r *RecoveryTable) Export(row interface{}) interface{} {
+	return row
+}
+
+// Anonymize removes the recovery token
+func (r *RecoveryTable) Anonymize(row interface{}, userID string, pseudonym string) (interface{}, error) {
+	return nil, nil
+}
+
+// RecoveryToken is a one time token that restores a snapshot of an abandoned cart
+type RecoveryToken struct {
+	Token     string        `json:"token"`
+	UserID    string        `json:"userID"`
+	Products  map[uint]uint `json:"products"`
+	Prices    map[uint]uint `json:"prices"`
+	Coupons   []string      `json:"coupons"`
+	ExpiresAt time.Time     `json:"expiresAt"`
+}
+
+// IsExpired checks if the token can no longer be used at the given moment
+func (r RecoveryToken) IsExpired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+// Validate checks that a recovery token adheres to constraints
+func (r RecoveryToken) Validate() error {
+	var errs errors
+	if r.Token == "" {
+		errs = append(errs, fmt.Errorf("token cannot be empty"))
+	}
+	if r.UserID == "" {
+		errs = append(errs, fmt.Errorf("user ID cannot be empty"))
+	}
+	if r.ExpiresAt.IsZero() {
+		errs = append(errs, fmt.Errorf("expiry is mandatory"))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// RecoveryStore represents the store of cart recovery tokens
+type RecoveryStore interface {
+	AddRecoveryToken(token *RecoveryToken) error
+	GetRecoveryToken(token string) (*RecoveryToken, error)
+	GetRecoveryTokens() ([]*RecoveryToken, error)
+	RemoveRecoveryToken(token string) error
+}
+
+// NewRecoveryStore start a new instance of the cart recovery store
+func NewRecoveryStore(log logger, db UnderlyingStore) RecoveryStore {
+	return &recoveryLogger{
+		log:  log,
+		next: &recoveryStore{db: db},
+	}
+}
+
+type recoveryStore struct {
+	db UnderlyingStore
+}
+
+// AddRecoveryToken stores a new recovery token
+func (r *recoveryStore) AddRecoveryToken(token *RecoveryToken) error {
+	if err := token.Validate(); err != nil {
+		return err
+	}
+	return r.db.Write(recoveryTable.GetName(), token)
+}
+
+// GetRecoveryToken returns the recovery token with the given value
+func (r *recoveryStore) GetRecoveryToken(token string) (*RecoveryToken, error) {
+	item, err := r.db.Read(recoveryTable.GetName(), id, token)
+	if err != nil {
+		return nil, err
+	}
+	return item.(*RecoveryToken), nil
+}
+
+// GetRecoveryTokens returns all the recovery tokens
+func (r *recoveryStore) GetRecoveryTokens() ([]*RecoveryToken, error) {
+	rows, err := r.db.ReadAll(recoveryTable.GetName(), id+"_prefix", "")
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]*RecoveryToken, 0, len(rows))
+	for _, row := range rows {
+		tokens = append(tokens, row.(*RecoveryToken))
+	}
+	return tokens, nil
+}
+
+// RemoveRecoveryToken removes a recovery token
+func (r *recoveryStore) RemoveRecoveryToken(token string) error {
+	return r.db.Remove(recoveryTable.GetName(), id, token)
+}
+
+type recoveryLogger struct {
+	log  logger
+	next RecoveryStore
+}
+
+func (r *recoveryLogger) AddRecoveryToken(token *RecoveryToken) error {
+	var err error
+	defer func() {
+		if err != nil {
+			r.log.Debugf("could not add recovery token for user %s err: %s", token.UserID, err.Error())
+			return
+		}
+		r.log.Debugw("added recovery token", "user", token.UserID, "expiresAt", token.ExpiresAt)
+	}()
+
+	err = r.next.AddRecoveryToken(token)
+	return err
+}
+
+func (r *recoveryLogger) GetRecoveryToken(token string) (*RecoveryToken, error) {
+	var err error
+	var recovery *RecoveryToken
+	defer func() {
+		if err != nil {
+			r.log.Debugf("could not retrieve recovery token err: %s", err.Error())
+			return
+		}
+		r.log.Debugf("retrieved recovery token for user %s", recovery.UserID)
+	}()
+
+	recovery, err = r.next.GetRecoveryToken(token)
+	return recovery, err
+}
+
+func (r *recoveryLogger) GetRecoveryTokens() ([]*RecoveryToken, error) {
+	var err error
+	var tokens []*RecoveryToken
+	defer func() {
+		if err != nil {
+			r.log.Debugf("could not retrieve recovery tokens err: %s", err.Error())
+			return
+		}
+		r.log.Debugf("retrieved %d recovery tokens", len(tokens))
+	}()
+
+	tokens, err = r.next.GetRecoveryTokens()
+	return tokens, err
+}
+
+func (r *recoveryLogger) RemoveRecoveryToken(token string) error {
+	var err error
+	defer func() {
+		if err != nil {
+			r.log.Debugf("could not remove recovery token err: %s", err.Error())
+			return
+		}
+		r.log.Debugf("removed recovery token")
+	}()
+
+	err = r.next.RemoveRecoveryToken(token)
+	return err
+}
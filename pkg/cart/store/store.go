@@ -1,11 +1,15 @@
 package store
 
 import (
+	"time"
+
 	"github.com/hashicorp/go-memdb"
 
 	"github.com/mimatache/go-shop/internal/store"
 )
 
+//go:generate mockgen -source ./store.go -destination mocks/store.go
+
 type logger interface {
 	Infof(msg string, args ...interface{})
 	Debugf(msg string, args ...interface{})
@@ -51,18 +55,40 @@ func (u *ShoppingCartTable) GetTableSchema() *memdb.TableSchema {
 	}
 }
 
+// GetPersonalRows returns the cart of the user
+func (s *ShoppingCartTable) GetPersonalRows(db store.Reader, userID string) ([]interface{}, error) {
+	return db.ReadAll(s.name, id, userID)
+}
+
+// Export returns the cart
+func (s *ShoppingCartTable) Export(row interface{}) interface{} {
+	return row
+}
+
+// Anonymize removes the cart
+func (s *ShoppingCartTable) Anonymize(row interface{}, userID string, pseudonym string) (interface{}, error) {
+	return nil, nil
+}
+
 // UnderlyingStore represents the interface that the DB should implement to be usable
 type UnderlyingStore interface {
 	Read(table string, key string, value interface{}) (interface{}, error)
+	ReadAll(table string, key string, args ...interface{}) ([]interface{}, error)
 	Write(table string, objs ...interface{}) error
 	Remove(table string, key string, value interface{}) error
 }
 
 // CartStore represents the shopping cart store
 type CartStore interface {
-	AddProduct(userID string, prodID uint, quantity uint) (uint, error)
+	AddProduct(userID string, prodID uint, quantity uint, unitPrice uint) (uint, error)
+	UpdateProduct(userID string, prodID uint, quantity uint, unitPrice uint) error
 	GetProductsForUser(userID string) (map[uint]uint, error)
+	GetPricesForUser(userID string) (map[uint]uint, error)
+	AddCoupon(userID string, code string) ([]string, error)
+	GetCouponsForUser(userID string) ([]string, error)
 	ClearCartFor(userID string) error
+	GetCarts() ([]*CartItem, error)
+	MarkAbandoned(userID string, at time.Time) error
 }
 
 // New start a new instance of cart store
@@ -77,19 +103,22 @@ type cartStore struct {
 	db UnderlyingStore
 }
 
-// AddProduct adds a product to the cart or increases the quantity of the item if already present2
-func (c *cartStore) AddProduct(userID string, prodID uint, quantity uint) (uint, error) {
+// AddProduct adds a product to the cart or increases the quantity of the item if already present.
+// The unit price is recorded only for products that are not already in the cart, so that later price changes
+// of the existing items are still reported to the user
+func (c *cartStore) AddProduct(userID string, prodID uint, quantity uint, unitPrice uint) (uint, error) {
 	var cartItem *CartItem
 	cartItem, err := c.getProductsForUser(userID)
 	switch err.(type) {
 	case nil:
-		if val, ok := cartItem.Products[prodID]; ok {
-			cartItem.Products[prodID] = val + quantity
-		} else {
-			cartItem.Products[prodID] = quantity
+		updated := copyCartItem(cartItem)
+		if _, ok := updated.Products[prodID]; !ok {
+			updated.Prices[prodID] = unitPrice
 		}
+		updated.Products[prodID] += quantity
+		cartItem = updated
 	case store.NotFound:
-		cartItem, err = NewCartItem(userID, prodID, quantity)
+		cartItem, err = NewCartItem(userID, prodID, quantity, unitPrice)
 		if err != nil {
 			return 0, err
 		}
@@ -100,11 +129,113 @@ func (c *cartStore) AddProduct(userID string, prodID uint, quantity uint) (uint,
 	return cartItem.Products[prodID], err
 }
 
+// UpdateProduct sets the quantity and the unit price of a product in the cart. A quantity of 0 removes the product
+func (c *cartStore) UpdateProduct(userID string, prodID uint, quantity uint, unitPrice uint) error {
+	cartItem, err := c.getProductsForUser(userID)
+	if err != nil {
+		return err
+	}
+	updated := copyCartItem(cartItem)
+	if quantity == 0 {
+		delete(updated.Products, prodID)
+		delete(updated.Prices, prodID)
+	} else {
+		updated.Products[prodID] = quantity
+		updated.Prices[prodID] = unitPrice
+	}
+	return c.db.Write(table.GetName(), updated)
+}
+
+// copyCartItem copies a stored cart so that it can be changed, and marks it as changed by the user
+func copyCartItem(cartItem *CartItem) *CartItem {
+	updated := *cartItem
+	updated.Products = map[uint]uint{}
+	for k, v := range cartItem.Products {
+		updated.Products[k] = v
+	}
+	updated.Prices = map[uint]uint{}
+	for k, v := range cartItem.Prices {
+		updated.Prices[k] = v
+	}
+	updated.UpdatedAt = time.Now()
+	updated.AbandonedAt = time.Time{}
+	return &updated
+}
+
+// AddCoupon adds a coupon code to the cart of the user, if not already present
+func (c *cartStore) AddCoupon(userID string, code string) ([]string, error) {
+	cartItem, err := c.getProductsForUser(userID)
+	switch err.(type) {
+	case nil:
+		for _, v := range cartItem.Coupons {
+			if v == code {
+				return cartItem.Coupons, nil
+			}
+		}
+		updated := *cartItem
+		updated.Coupons = append(append([]string{}, cartItem.Coupons...), code)
+		updated.UpdatedAt = time.Now()
+		updated.AbandonedAt = time.Time{}
+		cartItem = &updated
+	case store.NotFound:
+		cartItem = &CartItem{ID: userID, Products: map[uint]uint{}, Prices: map[uint]uint{}, Coupons: []string{code}, UpdatedAt: time.Now()}
+		if err = cartItem.Validate(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+	err = c.db.Write(table.GetName(), cartItem)
+	return cartItem.Coupons, err
+}
+
+// GetCouponsForUser returns the coupon codes added to the cart of the user
+func (c *cartStore) GetCouponsForUser(userID string) ([]string, error) {
+	cart, err := c.getProductsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return cart.Coupons, nil
+}
+
 // Removes the cart for the user
 func (c *cartStore) ClearCartFor(userID string) error {
 	return c.db.Remove(table.GetName(), id, userID)
 }
 
+// GetPricesForUser returns the unit prices recorded for the products in the cart of the user
+func (c *cartStore) GetPricesForUser(userID string) (map[uint]uint, error) {
+	cart, err := c.getProductsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return cart.Prices, nil
+}
+
+// GetCarts returns the carts of all the users
+func (c *cartStore) GetCarts() ([]*CartItem, error) {
+	rows, err := c.db.ReadAll(table.GetName(), id+"_prefix", "")
+	if err != nil {
+		return nil, err
+	}
+	carts := make([]*CartItem, 0, len(rows))
+	for _, row := range rows {
+		carts = append(carts, row.(*CartItem))
+	}
+	return carts, nil
+}
+
+// MarkAbandoned records the moment the cart of the user was detected as abandoned
+func (c *cartStore) MarkAbandoned(userID string, at time.Time) error {
+	cartItem, err := c.getProductsForUser(userID)
+	if err != nil {
+		return err
+	}
+	updated := *cartItem
+	updated.AbandonedAt = at
+	return c.db.Write(table.GetName(), &updated)
+}
+
 // Returns the cart of the user
 func (c *cartStore) GetProductsForUser(userID string) (map[uint]uint, error) {
 	cart, err := c.getProductsForUser(userID)
@@ -128,7 +259,7 @@ type cartLogger struct {
 	next CartStore
 }
 
-func (c *cartLogger) AddProduct(userID string, prodID uint, quantity uint) (uint, error) {
+func (c *cartLogger) AddProduct(userID string, prodID uint, quantity uint, unitPrice uint) (uint, error) {
 	var err error
 
 	defer func() {
@@ -138,10 +269,24 @@ func (c *cartLogger) AddProduct(userID string, prodID uint, quantity uint) (uint
 		}
 		c.log.Debugf("updated cart for user %d for product %d with %d", userID, prodID, quantity)
 	}()
-	quantity, err = c.next.AddProduct(userID, prodID, quantity)
+	quantity, err = c.next.AddProduct(userID, prodID, quantity, unitPrice)
 	return quantity, err
 }
 
+func (c *cartLogger) UpdateProduct(userID string, prodID uint, quantity uint, unitPrice uint) error {
+	var err error
+	defer func() {
+		if err != nil {
+			c.log.Debugf("could not update product %d in the cart of user %s err: %s", prodID, userID, err.Error())
+			return
+		}
+		c.log.Debugw("updated product in cart", "user", userID, "product", prodID, "quantity", quantity, "unitPrice", unitPrice)
+	}()
+
+	err = c.next.UpdateProduct(userID, prodID, quantity, unitPrice)
+	return err
+}
+
 func (c *cartLogger) GetProductsForUser(userID string) (map[uint]uint, error) {
 	var err error
 	var items map[uint]uint
@@ -158,6 +303,51 @@ func (c *cartLogger) GetProductsForUser(userID string) (map[uint]uint, error) {
 	return items, err
 }
 
+func (c *cartLogger) GetPricesForUser(userID string) (map[uint]uint, error) {
+	var err error
+	var prices map[uint]uint
+	defer func() {
+		if err != nil {
+			c.log.Debugf("could not retrieve the prices for user %s err: %s", userID, err.Error())
+			return
+		}
+		c.log.Debugw("current prices for user", "user", userID, "prices", prices)
+	}()
+
+	prices, err = c.next.GetPricesForUser(userID)
+	return prices, err
+}
+
+func (c *cartLogger) AddCoupon(userID string, code string) ([]string, error) {
+	var err error
+	var coupons []string
+	defer func() {
+		if err != nil {
+			c.log.Debugf("could not add coupon %s for user %s err: %s", code, userID, err.Error())
+			return
+		}
+		c.log.Debugw("added coupon to cart", "user", userID, "coupons", coupons)
+	}()
+
+	coupons, err = c.next.AddCoupon(userID, code)
+	return coupons, err
+}
+
+func (c *cartLogger) GetCouponsForUser(userID string) ([]string, error) {
+	var err error
+	var coupons []string
+	defer func() {
+		if err != nil {
+			c.log.Debugf("could not retrieve the coupons for user %s err: %s", userID, err.Error())
+			return
+		}
+		c.log.Debugw("current coupons for user", "user", userID, "coupons", coupons)
+	}()
+
+	coupons, err = c.next.GetCouponsForUser(userID)
+	return coupons, err
+}
+
 func (c *cartLogger) ClearCartFor(userID string) error {
 	var err error
 	defer func() {
@@ -171,3 +361,32 @@ func (c *cartLogger) ClearCartFor(userID string) error {
 	err = c.next.ClearCartFor(userID)
 	return err
 }
+
+func (c *cartLogger) GetCarts() ([]*CartItem, error) {
+	var err error
+	var carts []*CartItem
+	defer func() {
+		if err != nil {
+			c.log.Debugf("could not retrieve the carts err: %s", err.Error())
+			return
+		}
+		c.log.Debugf("retrieved %d carts", len(carts))
+	}()
+
+	carts, err = c.next.GetCarts()
+	return carts, err
+}
+
+func (c *cartLogger) MarkAbandoned(userID string, at time.Time) error {
+	var err error
+	defer func() {
+		if err != nil {
+			c.log.Debugf("could not mark the cart of user %s as abandoned err: %s", userID, err.Error())
+			return
+		}
+		c.log.Debugf("marked the cart of user %s as abandoned at %s", userID, at)
+	}()
+
+	err = c.next.MarkAbandoned(userID, at)
+	return err
+}
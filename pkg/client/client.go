@@ -21,6 +21,19 @@ type Product struct {
 	Quantity uint `json:"quantity"`
 }
 
+type Address struct {
+	Name       string `json:"name"`
+	Street     string `json:"street"`
+	City       string `json:"city"`
+	PostalCode string `json:"postalCode"`
+	Region     string `json:"region"`
+}
+
+type Delivery struct {
+	ShippingMethod string   `json:"shippingMethod"`
+	Address        *Address `json:"address"`
+}
+
 type ErrorBody struct {
 	Error string `json:"error"`
 	Code  int    `json:"code"`
@@ -29,7 +42,7 @@ type ErrorBody struct {
 type Client interface {
 	Login() error
 	AddToCart(prodID, quantity uint) error
-	Checkout() error
+	Checkout(delivery *Delivery) error
 }
 
 type Actor interface {
@@ -89,8 +102,12 @@ func (s *ShopClient) AddToCart(prodID, qunatity uint) error {
 	return nil
 }
 
-func (s *ShopClient) Checkout() error {
-	req, err := http.NewRequest(http.MethodPost, s.makeURL("cart/checkout"), bytes.NewBuffer([]byte{}))
+func (s *ShopClient) Checkout(delivery *Delivery) error {
+	reqBody, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.makeURL("cart/checkout"), bytes.NewBuffer(reqBody))
 	if err != nil {
 		return err
 	}
@@ -120,7 +137,16 @@ func (s *ShopClient) PerformActionLoop(itemsToBuy map[uint]uint) error {
 			}
 		}
 	}
-	err = s.Checkout()
+	err = s.Checkout(&Delivery{
+		ShippingMethod: "standard",
+		Address: &Address{
+			Name:       s.Name,
+			Street:     "1 Main Street",
+			City:       "Bucharest",
+			PostalCode: "010011",
+			Region:     "RO",
+		},
+	})
 	if err != nil {
 		return fmt.Errorf("error adding doing checkout %v", err)
 	}
@@ -0,0 +1,152 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mimatache/go-shop/internal/http/authorization"
+	"github.com/mimatache/go-shop/internal/http/helpers"
+	"github.com/mimatache/go-shop/internal/rbac"
+	"github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/orders/orders"
+	"github.com/mimatache/go-shop/pkg/payments/processor"
+	"github.com/mimatache/go-shop/pkg/payments/provider"
+)
+
+// items lists the items a request refers to. An empty list refers to everything still pending
+type items struct {
+	Items []orders.Item `json:"items"`
+}
+
+func New(orders *orders.Orders) *OrdersAPI {
+	return &OrdersAPI{
+		orders: orders,
+	}
+}
+
+type OrdersAPI struct {
+	orders *orders.Orders
+}
+
+func (o *OrdersAPI) list(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	userOrders, err := o.orders.List(userID)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, userOrders, http.StatusOK)
+}
+
+func (o *OrdersAPI) get(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	order, err := o.orders.Get(userID, mux.Vars(r)["id"])
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, order, http.StatusOK)
+}
+
+func (o *OrdersAPI) cancel(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	order, err := o.orders.CancelForUser(r.Context(), userID, mux.Vars(r)["id"])
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, order, http.StatusOK)
+}
+
+func (o *OrdersAPI) ship(w http.ResponseWriter, r *http.Request) {
+	var request items
+	if !decode(w, r, &request) {
+		return
+	}
+	order, err := o.orders.Ship(r.Context(), mux.Vars(r)["id"], request.Items)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, order, http.StatusOK)
+}
+
+func (o *OrdersAPI) cancelItems(w http.ResponseWriter, r *http.Request) {
+	var request items
+	if !decode(w, r, &request) {
+		return
+	}
+	order, err := o.orders.Cancel(r.Context(), mux.Vars(r)["id"], request.Items)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, order, http.StatusOK)
+}
+
+func (o *OrdersAPI) refund(w http.ResponseWriter, r *http.Request) {
+	var request orders.RefundRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	order, err := o.orders.Refund(r.Context(), mux.Vars(r)["id"], &request)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, order, http.StatusOK)
+}
+
+func decode(w http.ResponseWriter, r *http.Request, value interface{}) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(value)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func formatError(w http.ResponseWriter, err error) {
+	switch {
+	case store.IsNotFoundError(err):
+		helpers.FormatError(w, err.Error(), http.StatusNotFound)
+	case orders.IsInvalidOrderError(err):
+		helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+	case processor.IsInvalidPaymentError(err):
+		helpers.FormatError(w, err.Error(), http.StatusConflict)
+	case provider.IsUnavailableError(err):
+		helpers.FormatError(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// AddRoutes registers the API routes to a router.
+// Users can see and cancel their orders, while shipping and cancelling items are administrative operations
+func (o *OrdersAPI) AddRoutes(router *mux.Router, userHandler func(http.Handler) http.Handler, adminHandler func(permission rbac.Permission) func(http.Handler) http.Handler) {
+	ordersRouter := router.PathPrefix("/orders").Subrouter()
+	ordersRouter.Handle("", userHandler(http.HandlerFunc(o.list))).Methods(http.MethodGet)
+	ordersRouter.Handle("/{id}", userHandler(http.HandlerFunc(o.get))).Methods(http.MethodGet)
+	ordersRouter.Handle("/{id}/cancel", userHandler(http.HandlerFunc(o.cancel))).Methods(http.MethodPost)
+
+	adminRouter := router.PathPrefix("/admin/orders").Subrouter()
+	adminRouter.Handle("/{id}/ship", adminHandler(rbac.ManageOrders)(http.HandlerFunc(o.ship))).Methods(http.MethodPost)
+	adminRouter.Handle("/{id}/cancel", adminHandler(rbac.ManageOrders)(http.HandlerFunc(o.cancelItems))).Methods(http.MethodPost)
+	adminRouter.Handle("/{id}/refunds", adminHandler(rbac.IssueRefunds)(http.HandlerFunc(o.refund))).Methods(http.MethodPost)
+}
@@ -0,0 +1,32 @@
+package orders
+
+import (
+	netHTTP "net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/internal/rbac"
+
+	"github.com/mimatache/go-shop/pkg/orders/http"
+	"github.com/mimatache/go-shop/pkg/orders/orders"
+	"github.com/mimatache/go-shop/pkg/orders/store"
+)
+
+// NewAPI instantiates a new orders API
+func NewAPI(
+	logger logger.Logger,
+	payments orders.PaymentsAPI,
+	inventory orders.InventoryAPI,
+	wallet orders.WalletAPI,
+	db store.UnderlyingStore,
+	router *mux.Router,
+	userHandler func(netHTTP.Handler) netHTTP.Handler,
+	adminHandler func(permission rbac.Permission) func(netHTTP.Handler) netHTTP.Handler,
+) *orders.Orders {
+	orderStore := store.New(logger, db)
+	orderBook := orders.New(orderStore, payments, inventory, wallet)
+	ordersAPI := http.New(orderBook)
+	ordersAPI.AddRoutes(router, userHandler, adminHandler)
+	return orderBook
+}
@@ -0,0 +1,157 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./orders.go
+
+// Package mock_orders is a generated GoMock package.
+package mock_orders
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	provider "github.com/mimatache/go-shop/pkg/payments/provider"
+	store "github.com/mimatache/go-shop/pkg/payments/store"
+	store0 "github.com/mimatache/go-shop/pkg/wallet/store"
+	reflect "reflect"
+)
+
+// MockPaymentsAPI is a mock of PaymentsAPI interface
+type MockPaymentsAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentsAPIMockRecorder
+}
+
+// MockPaymentsAPIMockRecorder is the mock recorder for MockPaymentsAPI
+type MockPaymentsAPIMockRecorder struct {
+	mock *MockPaymentsAPI
+}
+
+// NewMockPaymentsAPI creates a new mock instance
+func NewMockPaymentsAPI(ctrl *gomock.Controller) *MockPaymentsAPI {
+	mock := &MockPaymentsAPI{ctrl: ctrl}
+	mock.recorder = &MockPaymentsAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPaymentsAPI) EXPECT() *MockPaymentsAPIMockRecorder {
+	return m.recorder
+}
+
+// Capture mocks base method
+func (m *MockPaymentsAPI) Capture(ctx context.Context, paymentID string, amount uint, final bool) (*store.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Capture", ctx, paymentID, amount, final)
+	ret0, _ := ret[0].(*store.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Capture indicates an expected call of Capture
+func (mr *MockPaymentsAPIMockRecorder) Capture(ctx, paymentID, amount, final interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*MockPaymentsAPI)(nil).Capture), ctx, paymentID, amount, final)
+}
+
+// Void mocks base method
+func (m *MockPaymentsAPI) Void(ctx context.Context, paymentID string) (*store.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Void", ctx, paymentID)
+	ret0, _ := ret[0].(*store.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Void indicates an expected call of Void
+func (mr *MockPaymentsAPIMockRecorder) Void(ctx, paymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Void", reflect.TypeOf((*MockPaymentsAPI)(nil).Void), ctx, paymentID)
+}
+
+// Refund mocks base method
+func (m *MockPaymentsAPI) Refund(ctx context.Context, paymentID string, amount uint, reason provider.RefundReason) (*store.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refund", ctx, paymentID, amount, reason)
+	ret0, _ := ret[0].(*store.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refund indicates an expected call of Refund
+func (mr *MockPaymentsAPIMockRecorder) Refund(ctx, paymentID, amount, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockPaymentsAPI)(nil).Refund), ctx, paymentID, amount, reason)
+}
+
+// MockInventoryAPI is a mock of InventoryAPI interface
+type MockInventoryAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockInventoryAPIMockRecorder
+}
+
+// MockInventoryAPIMockRecorder is the mock recorder for MockInventoryAPI
+type MockInventoryAPIMockRecorder struct {
+	mock *MockInventoryAPI
+}
+
+// NewMockInventoryAPI creates a new mock instance
+func NewMockInventoryAPI(ctrl *gomock.Controller) *MockInventoryAPI {
+	mock := &MockInventoryAPI{ctrl: ctrl}
+	mock.recorder = &MockInventoryAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockInventoryAPI) EXPECT() *MockInventoryAPIMockRecorder {
+	return m.recorder
+}
+
+// ReturnToStock mocks base method
+func (m *MockInventoryAPI) ReturnToStock(items map[uint]uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReturnToStock", items)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReturnToStock indicates an expected call of ReturnToStock
+func (mr *MockInventoryAPIMockRecorder) ReturnToStock(items interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReturnToStock", reflect.TypeOf((*MockInventoryAPI)(nil).ReturnToStock), items)
+}
+
+// MockWalletAPI is a mock of WalletAPI interface
+type MockWalletAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockWalletAPIMockRecorder
+}
+
+// MockWalletAPIMockRecorder is the mock recorder for MockWalletAPI
+type MockWalletAPIMockRecorder struct {
+	mock *MockWalletAPI
+}
+
+// NewMockWalletAPI creates a new mock instance
+func NewMockWalletAPI(ctrl *gomock.Controller) *MockWalletAPI {
+	mock := &MockWalletAPI{ctrl: ctrl}
+	mock.recorder = &MockWalletAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWalletAPI) EXPECT() *MockWalletAPIMockRecorder {
+	return m.recorder
+}
+
+// Refund mocks base method
+func (m *MockWalletAPI) Refund(userID string, amount uint, reference string) (*store0.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refund", userID, amount, reference)
+	ret0, _ := ret[0].(*store0.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refund indicates an expected call of Refund
+func (mr *MockWalletAPIMockRecorder) Refund(userID, amount, reference interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockWalletAPI)(nil).Refund), userID, amount, reference)
+}
@@ -0,0 +1,527 @@
+package orders
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mimatache/go-shop/internal/money"
+	internalStore "github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/cart/cart"
+	"github.com/mimatache/go-shop/pkg/orders/store"
+	"github.com/mimatache/go-shop/pkg/payments/provider"
+	paymentStore "github.com/mimatache/go-shop/pkg/payments/store"
+	walletStore "github.com/mimatache/go-shop/pkg/wallet/store"
+)
+
+const idBytes = 8
+
+//go:generate mockgen -source ./orders.go -destination mocks/orders.go
+
+// PaymentsAPI represents the methods that need to be implemented by the payments API
+type PaymentsAPI interface {
+	// Capture takes money from the authorization of a payment
+	Capture(ctx context.Context, paymentID string, amount uint, final bool) (*paymentStore.Payment, error)
+	// Void releases what is left of the authorization of a payment
+	Void(ctx context.Context, paymentID string) (*paymentStore.Payment, error)
+	// Refund gives back money captured by a payment
+	Refund(ctx context.Context, paymentID string, amount uint, reason provider.RefundReason) (*paymentStore.Payment, error)
+}
+
+// InventoryAPI represents the methods that need to be implemented by the inventory API
+type InventoryAPI interface {
+	// ReturnToStock adds the items back to the stock
+	ReturnToStock(items map[uint]uint) error
+}
+
+// WalletAPI represents the methods that need to be implemented by the wallet API
+type WalletAPI interface {
+	// Refund gives money back to the user as store credit
+	Refund(userID string, amount uint, reference string) (*walletStore.Entry, error)
+}
+
+type invalidOrder struct {
+	msg string
+}
+
+func (i invalidOrder) Error() string {
+	return i.msg
+}
+
+// IsInvalidOrderError verifies if a given error refers to an operation the order does not allow
+func IsInvalidOrderError(err error) bool {
+	switch err.(type) {
+	case invalidOrder:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewInvalidOrder creates a new invalid order error
+func NewInvalidOrder(orderID string, reason string) error {
+	return invalidOrder{msg: fmt.Sprintf("order %s: %s", orderID, reason)}
+}
+
+// Item is a number of items of a product of the order
+type Item struct {
+	ProductID uint `json:"productID"`
+	Quantity  uint `json:"quantity"`
+}
+
+// RefundRequest describes the money to give back for an order
+type RefundRequest struct {
+	Reason provider.RefundReason `json:"reason"`
+	// Amount is the money to give back. When missing, it is the price paid for the lines
+	Amount uint `json:"amount"`
+	// Lines are the shipped items the refund is for. A refund without lines or amount gives back everything left
+	Lines []*store.RefundLine `json:"lines"`
+}
+
+// New creates the order book
+func New(storage store.OrderStore, payments PaymentsAPI, inventory InventoryAPI, wallet WalletAPI) *Orders {
+	return &Orders{
+		storage:   storage,
+		payments:  payments,
+		inventory: inventory,
+		wallet:    wallet,
+		now:       time.Now,
+	}
+}
+
+// Orders keeps the checked out carts until they are shipped. The payment of an order is only authorized at checkout,
+// the money is captured as the items are shipped and the authorization is released for the items that are cancelled.
+// What was prepaid with gift cards and store credit pays for the first shipped items, and what is left of it once
+// nothing is pending anymore is given back as store credit
+type Orders struct {
+	storage   store.OrderStore
+	payments  PaymentsAPI
+	inventory InventoryAPI
+	wallet    WalletAPI
+	now       func() time.Time
+	sync.Mutex
+}
+
+// PlaceOrder records the checked out contents of the cart, paid by the given payment and by the tender of the contents,
+// and returns the ID of the order
+func (o *Orders) PlaceOrder(userID string, paymentID string, contents *cart.Contents) (string, error) {
+	if contents.Summary == nil || contents.Summary.Tax == nil {
+		return "", fmt.Errorf("cannot place an order without a summary")
+	}
+	id, err := newID()
+	if err != nil {
+		return "", err
+	}
+	amounts := map[uint]uint{}
+	for _, line := range contents.Summary.Tax.Lines {
+		if amounts[line.ProductID], err = money.Add(amounts[line.ProductID], line.Gross); err != nil {
+			return "", err
+		}
+	}
+	now := o.now()
+	order := &store.Order{
+		ID:           id,
+		UserID:       userID,
+		Status:       store.Placed,
+		ShippingCost: money.New(0, contents.Summary.Currency),
+		Total:        money.New(contents.Summary.Total, contents.Summary.Currency),
+		PaymentID:    paymentID,
+		Delivery:     contents.Delivery,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if contents.Summary.Shipping != nil {
+		order.ShippingCost.Amount = contents.Summary.Shipping.Cost
+	}
+	if contents.Tender != nil {
+		order.TenderID = contents.Tender.ID
+		order.Prepaid = contents.Tender.Total
+	}
+	var linesTotal uint
+	for _, product := range contents.Products {
+		order.Lines = append(order.Lines, &store.Line{
+			ProductID: product.ID,
+			Quantity:  product.Quantity,
+			Amount:    amounts[product.ID],
+		})
+		if linesTotal, err = money.Add(linesTotal, amounts[product.ID]); err != nil {
+			return "", err
+		}
+	}
+	// taxes rounded per order can make the lines differ slightly from the total, so the last line absorbs the difference
+	if len(order.Lines) > 0 {
+		last := order.Lines[len(order.Lines)-1]
+		others, err := money.Sub(linesTotal, last.Amount)
+		if err != nil {
+			return "", err
+		}
+		if last.Amount, err = money.Sub(order.Total.Amount, order.ShippingCost.Amount); err != nil {
+			return "", err
+		}
+		if last.Amount, err = money.Sub(last.Amount, others); err != nil {
+			return "", err
+		}
+	}
+	if err := o.storage.SetOrder(order); err != nil {
+		return "", err
+	}
+	return order.ID, nil
+}
+
+// List returns the orders of the user, newest first
+func (o *Orders) List(userID string) ([]*store.Order, error) {
+	orders, err := o.storage.GetOrdersForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreatedAt.After(orders[j].CreatedAt)
+	})
+	return orders, nil
+}
+
+// Get returns an order of the user
+func (o *Orders) Get(userID string, orderID string) (*store.Order, error) {
+	order, err := o.storage.GetOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, internalStore.NewNotFoundError(store.GetTable().GetName(), "id", orderID)
+	}
+	return order, nil
+}
+
+// Ship marks the items as shipped and captures their price from the payment. The shipping cost is captured
+// with the first shipment. When no items are given, everything still pending is shipped
+func (o *Orders) Ship(ctx context.Context, orderID string, items []Item) (*store.Order, error) {
+	o.Lock()
+	defer o.Unlock()
+	order, quantities, err := o.prepare(orderID, items)
+	if err != nil {
+		return nil, err
+	}
+	var amount uint
+	if !order.HasShipped() {
+		amount = order.ShippingCost.Amount
+	}
+	for _, line := range order.Lines {
+		quantity := quantities[line.ProductID]
+		lineAmount, err := line.AmountBetween(line.Shipped, line.Shipped+quantity)
+		if err != nil {
+			return nil, err
+		}
+		if amount, err = money.Add(amount, lineAmount); err != nil {
+			return nil, err
+		}
+		line.Shipped += quantity
+	}
+	prepaid := min(amount, order.Prepaid-order.PrepaidUsed)
+	capture := amount - prepaid
+	captured, err := money.Add(order.Captured, capture)
+	if err != nil {
+		return nil, err
+	}
+	final := order.Pending() == 0
+	if order.PaymentID != "" && (capture > 0 || final) {
+		if _, err := o.payments.Capture(ctx, order.PaymentID, capture, final); err != nil {
+			return nil, err
+		}
+	}
+	order.PrepaidUsed += prepaid
+	order.Captured = captured
+	if err := o.settle(order); err != nil {
+		return nil, err
+	}
+	return o.save(order)
+}
+
+// Cancel cancels items that were not shipped yet, returns them to the stock and releases their price from the payment.
+// When no items are given, everything still pending is cancelled
+func (o *Orders) Cancel(ctx context.Context, orderID string, items []Item) (*store.Order, error) {
+	o.Lock()
+	defer o.Unlock()
+	order, quantities, err := o.prepare(orderID, items)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range order.Lines {
+		line.Cancelled += quantities[line.ProductID]
+	}
+	if order.Pending() == 0 && order.PaymentID != "" {
+		if _, err := o.payments.Void(ctx, order.PaymentID); err != nil {
+			return nil, err
+		}
+	}
+	if err := o.inventory.ReturnToStock(quantities); err != nil {
+		return nil, err
+	}
+	if err := o.settle(order); err != nil {
+		return nil, err
+	}
+	return o.save(order)
+}
+
+// CancelUnpaid cancels everything still pending from the order paid by the given payment, because the provider
+// refused the payment after the order was placed. The items are returned to the stock
+func (o *Orders) CancelUnpaid(paymentID string) (*store.Order, error) {
+	o.Lock()
+	defer o.Unlock()
+	stored, err := o.storage.GetOrderForPayment(paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if stored.Pending() == 0 {
+		return stored, nil
+	}
+	order, quantities, err := o.prepare(stored.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range order.Lines {
+		line.Cancelled += quantities[line.ProductID]
+	}
+	if err := o.inventory.ReturnToStock(quantities); err != nil {
+		return nil, err
+	}
+	if err := o.settle(order); err != nil {
+		return nil, err
+	}
+	return o.save(order)
+}
+
+// CancelForUser cancels everything the user is still waiting for from the order
+func (o *Orders) CancelForUser(ctx context.Context, userID string, orderID string) (*store.Order, error) {
+	if _, err := o.Get(userID, orderID); err != nil {
+		return nil, err
+	}
+	return o.Cancel(ctx, orderID, nil)
+}
+
+// Refund gives back money paid for the order, either for some of the shipped items or as a plain amount.
+// The refunds of an order never exceed what was paid for it. Money captured from the payment is given back
+// through the payment first, and money prepaid with gift cards and store credit is given back as store credit. Refunded items are returned to the stock
+// when their line asks for it. A refund interrupted by a failure stays pending on the order, and the next refund of the order
+// completes it instead of starting a new one
+func (o *Orders) Refund(ctx context.Context, orderID string, request *RefundRequest) (*store.Order, error) {
+	o.Lock()
+	defer o.Unlock()
+	stored, err := o.storage.GetOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	order := stored.Copy()
+	if index, ok := order.PendingRefund(); ok {
+		return o.completeRefund(ctx, order, index)
+	}
+	if !request.Reason.IsKnown() {
+		return nil, NewInvalidOrder(orderID, fmt.Sprintf("unknown refund reason %s", request.Reason))
+	}
+	quantities := map[uint]uint{}
+	for _, refundLine := range request.Lines {
+		line, ok := order.Line(refundLine.ProductID)
+		if !ok {
+			return nil, NewInvalidOrder(orderID, fmt.Sprintf("product %d is not part of the order", refundLine.ProductID))
+		}
+		if refundLine.Quantity == 0 {
+			return nil, NewInvalidOrder(orderID, fmt.Sprintf("the quantity of product %d must be greater than 0", refundLine.ProductID))
+		}
+		quantities[line.ProductID] += refundLine.Quantity
+		if quantities[line.ProductID] > line.Shipped-line.Refunded {
+			return nil, NewInvalidOrder(orderID, fmt.Sprintf("only %d shipped items of product %d can be refunded", line.Shipped-line.Refunded, line.ProductID))
+		}
+	}
+
+	amount := request.Amount
+	if amount == 0 {
+		for _, line := range order.Lines {
+			quantity := quantities[line.ProductID]
+			lineAmount, err := line.AmountBetween(line.Refunded, line.Refunded+quantity)
+			if err != nil {
+				return nil, err
+			}
+			if amount, err = money.Add(amount, lineAmount); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if amount == 0 && len(request.Lines) == 0 {
+		amount = order.Paid() - order.Refunded
+	}
+	if amount == 0 {
+		return nil, NewInvalidOrder(orderID, "nothing to refund")
+	}
+	if amount > order.Paid()-order.Refunded {
+		return nil, NewInvalidOrder(orderID, fmt.Sprintf("cannot refund %d, only %d was paid and not refunded", amount, order.Paid()-order.Refunded))
+	}
+	refunded, err := money.Add(order.Refunded, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	// the refund is recorded before any money is given back, so that a failure halfway leaves it pending
+	toPayment := min(amount, order.Captured-order.RefundedToPayment())
+	order.Refunds = append(order.Refunds, &store.Refund{
+		Amount:      amount,
+		Reason:      request.Reason,
+		StoreCredit: amount - toPayment,
+		Lines:       request.Lines,
+		CreatedAt:   o.now(),
+		Pending:     true,
+	})
+	for _, line := range order.Lines {
+		line.Refunded += quantities[line.ProductID]
+	}
+	order.Refunded = refunded
+	if order, err = o.checkpoint(order); err != nil {
+		return nil, err
+	}
+	return o.completeRefund(ctx, order, len(order.Refunds)-1)
+}
+
+// completeRefund does the steps of the pending refund at the given position that were not done yet,
+// saving the order after each of them
+func (o *Orders) completeRefund(ctx context.Context, order *store.Order, index int) (*store.Order, error) {
+	refund := order.Refunds[index]
+	if refund.ToPayment() > 0 && !refund.PaidBack {
+		payment, err := o.payments.Refund(ctx, order.PaymentID, refund.ToPayment(), refund.Reason)
+		if err != nil {
+			return nil, err
+		}
+		if len(payment.Refunds) > 0 {
+			refund.ID = payment.Refunds[len(payment.Refunds)-1].ID
+		}
+		refund.PaidBack = true
+		if order, err = o.checkpoint(order); err != nil {
+			return nil, err
+		}
+		refund = order.Refunds[index]
+	}
+	if refund.StoreCredit > 0 && !refund.Credited {
+		entry, err := o.wallet.Refund(order.UserID, refund.StoreCredit, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		if refund.ID == "" {
+			refund.ID = entry.ID
+		}
+		refund.Credited = true
+		if order, err = o.checkpoint(order); err != nil {
+			return nil, err
+		}
+		refund = order.Refunds[index]
+	}
+	if restock := refund.Restock(); len(restock) > 0 {
+		if err := o.inventory.ReturnToStock(restock); err != nil {
+			return nil, err
+		}
+	}
+	refund.Pending = false
+	return o.save(order)
+}
+
+// checkpoint saves the order and returns a copy of it to carry on with
+func (o *Orders) checkpoint(order *store.Order) (*store.Order, error) {
+	saved, err := o.save(order)
+	if err != nil {
+		return nil, err
+	}
+	return saved.Copy(), nil
+}
+
+// prepare returns a copy of the order and the quantity of every product the items refer to,
+// after checking that all of them are still pending
+func (o *Orders) prepare(orderID string, items []Item) (*store.Order, map[uint]uint, error) {
+	stored, err := o.storage.GetOrder(orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	order := stored.Copy()
+	if order.Pending() == 0 {
+		return nil, nil, NewInvalidOrder(orderID, fmt.Sprintf("nothing left to ship, the order is %s", order.Status))
+	}
+	quantities := map[uint]uint{}
+	if len(items) == 0 {
+		for _, line := range order.Lines {
+			if line.Pending() > 0 {
+				quantities[line.ProductID] = line.Pending()
+			}
+		}
+		return order, quantities, nil
+	}
+	for _, item := range items {
+		line, ok := order.Line(item.ProductID)
+		if !ok {
+			return nil, nil, NewInvalidOrder(orderID, fmt.Sprintf("product %d is not part of the order", item.ProductID))
+		}
+		if item.Quantity == 0 {
+			return nil, nil, NewInvalidOrder(orderID, fmt.Sprintf("the quantity of product %d must be greater than 0", item.ProductID))
+		}
+		quantities[item.ProductID] += item.Quantity
+		if quantities[item.ProductID] > line.Pending() {
+			return nil, nil, NewInvalidOrder(orderID, fmt.Sprintf("only %d items of product %d are pending", line.Pending(), item.ProductID))
+		}
+	}
+	return order, quantities, nil
+}
+
+// settle gives back as store credit what is left of the prepaid amount, once nothing is pending anymore
+func (o *Orders) settle(order *store.Order) error {
+	left := order.Prepaid - order.PrepaidUsed - order.Credited
+	if order.Pending() > 0 || left == 0 {
+		return nil
+	}
+	credited, err := money.Add(order.Credited, left)
+	if err != nil {
+		return err
+	}
+	if _, err := o.wallet.Refund(order.UserID, left, order.ID); err != nil {
+		return err
+	}
+	order.Credited = credited
+	return nil
+}
+
+func hasPendingRefund(order *store.Order) bool {
+	_, ok := order.PendingRefund()
+	return ok
+}
+
+func (o *Orders) save(order *store.Order) (*store.Order, error) {
+	switch {
+	case order.Pending() == 0 && order.Paid() > 0 && order.Refunded == order.Paid() && !hasPendingRefund(order):
+		order.Status = store.Refunded
+	case order.Pending() > 0 && order.HasShipped():
+		order.Status = store.PartiallyShipped
+	case order.Pending() > 0:
+		order.Status = store.Placed
+	case order.HasShipped():
+		order.Status = store.Shipped
+	default:
+		order.Status = store.Cancelled
+	}
+	order.UpdatedAt = o.now()
+	if err := o.storage.SetOrder(order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func min(a, b uint) uint {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func newID() (string, error) {
+	b := make([]byte, idBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,467 @@
+package orders_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/money"
+	internalStore "github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/cart/cart"
+	"github.com/mimatache/go-shop/pkg/orders/orders"
+	mock_orders "github.com/mimatache/go-shop/pkg/orders/orders/mocks"
+	"github.com/mimatache/go-shop/pkg/orders/store"
+	mock_store "github.com/mimatache/go-shop/pkg/orders/store/mocks"
+	"github.com/mimatache/go-shop/pkg/payments/provider"
+	paymentStore "github.com/mimatache/go-shop/pkg/payments/store"
+	"github.com/mimatache/go-shop/pkg/shipping/rates"
+	"github.com/mimatache/go-shop/pkg/tax/calculator"
+	walletStore "github.com/mimatache/go-shop/pkg/wallet/store"
+)
+
+const (
+	userID    = "user@email.com"
+	orderID   = "order"
+	paymentID = "payment"
+)
+
+type mocks struct {
+	storage   *mock_store.MockOrderStore
+	payments  *mock_orders.MockPaymentsAPI
+	inventory *mock_orders.MockInventoryAPI
+	wallet    *mock_orders.MockWalletAPI
+}
+
+func newOrders(t *testing.T) (*orders.Orders, *mocks, func()) {
+	ctrl := gomock.NewController(t)
+	m := &mocks{
+		storage:   mock_store.NewMockOrderStore(ctrl),
+		payments:  mock_orders.NewMockPaymentsAPI(ctrl),
+		inventory: mock_orders.NewMockInventoryAPI(ctrl),
+		wallet:    mock_orders.NewMockWalletAPI(ctrl),
+	}
+	return orders.New(m.storage, m.payments, m.inventory, m.wallet), m, ctrl.Finish
+}
+
+// newOrder returns an order of 3 items of product 1 paying 100 and 1 item of product 2 paying 50, shipped for 10
+func newOrder() *store.Order {
+	return &store.Order{
+		ID:     orderID,
+		UserID: userID,
+		Status: store.Placed,
+		Lines: []*store.Line{
+			{ProductID: 1, Quantity: 3, Amount: 100},
+			{ProductID: 2, Quantity: 1, Amount: 50},
+		},
+		ShippingCost: money.New(10, "EUR"),
+		Total:        money.New(160, "EUR"),
+		PaymentID:    paymentID,
+	}
+}
+
+func TestOrders_PlaceOrder(t *testing.T) {
+	g := NewWithT(t)
+
+	orderBook, m, finish := newOrders(t)
+	defer finish()
+
+	var placed *store.Order
+	m.storage.EXPECT().SetOrder(gomock.Any()).DoAndReturn(func(order *store.Order) error {
+		placed = order
+		return nil
+	})
+
+	id, err := orderBook.PlaceOrder(userID, paymentID, &cart.Contents{
+		Products: []*cart.Product{{ID: 1, Quantity: 3}, {ID: 2, Quantity: 1}},
+		Summary: &cart.Summary{
+			Currency: "USD",
+			Tax: &calculator.Breakdown{Lines: []*calculator.LineTax{
+				{ProductID: 1, Gross: 100},
+				{ProductID: 2, Gross: 49},
+			}},
+			Shipping: &rates.Quote{Cost: 10},
+			Total:    160,
+		},
+	})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(id).To(Equal(placed.ID))
+	g.Expect(placed.Status).To(Equal(store.Placed))
+	g.Expect(placed.PaymentID).To(Equal(paymentID))
+	g.Expect(placed.Total).To(Equal(money.New(160, "USD")))
+	g.Expect(placed.ShippingCost).To(Equal(money.New(10, "USD")))
+	g.Expect(placed.Lines).To(Equal(newOrder().Lines))
+}
+
+func TestOrders_PlaceOrder_Overflow(t *testing.T) {
+	g := NewWithT(t)
+
+	orderBook, _, finish := newOrders(t)
+	defer finish()
+
+	_, err := orderBook.PlaceOrder(userID, paymentID, &cart.Contents{
+		Products: []*cart.Product{{ID: 1, Quantity: 1}, {ID: 2, Quantity: 1}},
+		Summary: &cart.Summary{
+			Currency: "USD",
+			Tax: &calculator.Breakdown{Lines: []*calculator.LineTax{
+				{ProductID: 1, Gross: ^uint(0)},
+				{ProductID: 2, Gross: 1},
+			}},
+			Total: 160,
+		},
+	})
+
+	g.Expect(money.IsOverflowError(err)).To(BeTrue())
+}
+
+func TestOrders_Ship_LineOverflow(t *testing.T) {
+	g := NewWithT(t)
+
+	orderBook, m, finish := newOrders(t)
+	defer finish()
+
+	order := newOrder()
+	order.Lines[0].Amount = ^uint(0) / 2
+	m.storage.EXPECT().GetOrder(orderID).Return(order, nil)
+
+	_, err := orderBook.Ship(context.Background(), orderID, []orders.Item{{ProductID: 1, Quantity: 3}})
+
+	g.Expect(money.IsOverflowError(err)).To(BeTrue())
+}
+
+func TestOrders_Ship_Partially(t *testing.T) {
+	g := NewWithT(t)
+
+	orderBook, m, finish := newOrders(t)
+	defer finish()
+
+	m.storage.EXPECT().GetOrder(orderID).Return(newOrder(), nil)
+	m.payments.EXPECT().Capture(gomock.Any(), paymentID, uint(10+33), false).Return(&paymentStore.Payment{}, nil)
+	m.storage.EXPECT().SetOrder(gomock.Any()).Return(nil)
+
+	order, err := orderBook.Ship(context.Background(), orderID, []orders.Item{{ProductID: 1, Quantity: 1}})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(order.Status).To(Equal(store.PartiallyShipped))
+	g.Expect(order.Lines[0].Shipped).To(Equal(uint(1)))
+	g.Expect(order.Captured).To(Equal(uint(43)))
+}
+
+func TestOrders_Ship_Remaining(t *testing.T) {
+	g := NewWithT(t)
+
+	orderBook, m, finish := newOrders(t)
+	defer finish()
+
+	partial := newOrder()
+	partial.Lines[0].Shipped = 1
+	partial.Status = store.PartiallyShipped
+	m.storage.EXPECT().GetOrder(orderID).Return(partial, nil)
+	m.payments.EXPECT().Capture(gomock.Any(), paymentID, uint(67+50), true).Return(&paymentStore.Payment{}, nil)
+	m.storage.EXPECT().SetOrder(gomock.Any()).Return(nil)
+
+	order, err := orderBook.Ship(context.Background(), orderID, nil)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(order.Status).To(Equal(store.Shipped))
+	g.Expect(order.Pending()).To(BeZero())
+}
+
+func TestOrders_Ship_TooMany(t *testing.T) {
+	g := NewWithT(t)
+
+	orderBook, m, finish := newOrders(t)
+	defer finish()
+
+	m.storage.EXPECT().GetOrder(orderID).Return(newOrder(), nil)
+
+	_, err := orderBook.Ship(context.Background(), orderID, []orders.Item{{ProductID: 2, Quantity: 2}})
+
+	g.Expect(orders.IsInvalidOrderError(err)).To(BeTrue())
+}
+
+func TestOrders_Cancel_ReleasesPayment(t *testing.T) {
+	g := NewWithT(t)
+
+	orderBook, m, finish := newOrders(t)
+	defer finish()
+
+	partial := newOrder()
+	partial.Lines[0].Shipped = 3
+	m.storage.EXPECT().GetOrder(orderID).Return(partial, nil)
+	m.payments.EXPECT().Void(gomock.Any(), paymentID).Return(&paymentStore.Payment{}, nil)
+	m.inventory.EXPECT().ReturnToStock(map[uint]uint{2: 1}).Return(nil)
+	m.storage.EXPECT().SetOrder(gomock.Any()).Return(nil)
+
+	order, err := orderBook.Cancel(context.Background(), orderID, nil)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(order.Status).To(Equal(store.Shipped))
+	g.Expect(order.Lines[1].Cancelled).To(Equal(uint(1)))
+}
+
+func TestOrders_Cancel_KeepsAuthorizationForPendingItems(t *testing.T) {
+	g := NewWithT(t)
+
+	orderBook, m, finish := newOrders(t)
+	defer finish()
+
+	m.storage.EXPECT().GetOrder(orderID).Return(newOrder(), nil)
+	m.inventory.EXPECT().ReturnToStock(map[uint]uint{1: 2}).Return(nil)
+	m.storage.EXPECT().SetOrder(gomock.Any()).Return(nil)
+
+	order, err := orderBook.Cancel(context.Background(), orderID, []orders.Item{{ProductID: 1, Quantity: 2}})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(order.Status).To(Equal(store.Placed))
+	g.Expect(order.Pending()).To(Equal(uint(2)))
+}
+
+func TestOrders_CancelForUser_OtherUser(t *testing.T) {
+	g := NewWithT(t)
+
+	orderBook, m, finish := newOrders(t)
+	defer finish()
+
+	m.storage.EXPECT().GetOrder(orderID).Return(newOrder(), nil)
+
+	_, err := orderBook.CancelForUser(context.Background(), "other@email.com", orderID)
+
+	g.Expect(internalStore.IsNotFoundError(err)).To(BeTrue())
+}
+
+// shippedOrder returns the order with every item shipped and paid
+func shippedOrder() *store.Order {
+	order := newOrder()
+	order.Lines[0].Shipped = 3
+	order.Lines[1].Shipped = 1
+	order.Captured = 160
+	order.Status = store.Shipped
+	return order
+}
+
+func refunded(amount uint) *paymentStore.Payment {
+	return &paymentStore.Payment{ID: paymentID, Refunds: []*paymentStore.Refund{{ID: "refund", Amount: money.New(amount, "EUR")}}}
+}
+
+func TestOrders_Refund_Lines(t *testing.T) {
+	g := NewWithT(t)
+
+	orderBook, m, finish := newOrders(t)
+	defer finish()
+
+	m.storage.EXPECT().GetOrder(orderID).Return(shippedOrder(), nil)
+	m.payments.EXPECT().Refund(gomock.Any(), paymentID, uint(33+50), provider.Damaged).Return(refunded(83), nil)
+	m.storage.EXPECT().SetOrder(gomock.Any()).Return(nil).Times(3)
+	m.inventory.EXPECT().ReturnToStock(map[uint]uint{2: 1}).Return(nil)
+
+	order, err := orderBook.Refund(context.Background(), orderID, &orders.RefundRequest{
+		Reason: provider.Damaged,
+		Lines: []*store.RefundLine{
+			{ProductID: 1, Quantity: 1},
+			{ProductID: 2, Quantity: 1, Restock: true},
+		},
+	})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(order.Refunded).To(Equal(uint(83)))
+	g.Expect(order.Lines[0].Refunded).To(Equal(uint(1)))
+	g.Expect(order.Refunds).To(HaveLen(1))
+	g.Expect(order.Refunds[0].ID).To(Equal("refund"))
+	g.Expect(order.Status).To(Equal(store.Shipped))
+}
+
+func TestOrders_Refund_Everything(t *testing.T) {
+	g := NewWithT(t)
+
+	orderBook, m, finish := newOrders(t)
+	defer finish()
+
+	partial := shippedOrder()
+	partial.Refunded = 60
+	m.storage.EXPECT().GetOrder(orderID).Return(partial, nil)
+	m.payments.EXPECT().Refund(gomock.Any(), paymentID, uint(100), provider.RequestedByCustomer).Return(refunded(100), nil)
+	m.storage.EXPECT().SetOrder(gomock.Any()).Return(nil).Times(3)
+
+	order, err := orderBook.Refund(context.Background(), orderID, &orders.RefundRequest{Reason: provider.RequestedByCustomer})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(order.Status).To(Equal(store.Refunded))
+}
+
+func TestOrders_Refund_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		request *orders.RefundRequest
+	}{
+		{name: "more than paid", request: &orders.RefundRequest{Reason: provider.Goodwill, Amount: 161}},
+		{name: "unknown reason", request: &orders.RefundRequest{Reason: "bored", Amount: 10}},
+		{name: "more items than shipped", request: &orders.RefundRequest{Reason: provider.Damaged, Lines: []*store.RefundLine{{ProductID: 2, Quantity: 2}}}},
+		{name: "unknown product", request: &orders.RefundRequest{Reason: provider.Damaged, Lines: []*store.RefundLine{{ProductID: 3, Quantity: 1}}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			orderBook, m, finish := newOrders(t)
+			defer finish()
+
+			m.storage.EXPECT().GetOrder(orderID).Return(shippedOrder(), nil)
+
+			_, err := orderBook.Refund(context.Background(), orderID, test.request)
+
+			g.Expect(orders.IsInvalidOrderError(err)).To(BeTrue())
+		})
+	}
+}
+
+func TestOrders_CancelUnpaid(t *testing.T) {
+	g := NewWithT(t)
+
+	orderBook, m, finish := newOrders(t)
+	defer finish()
+
+	m.storage.EXPECT().GetOrderForPayment(paymentID).Return(newOrder(), nil)
+	m.storage.EXPECT().GetOrder(orderID).Return(newOrder(), nil)
+	m.inventory.EXPECT().ReturnToStock(map[uint]uint{1: 3, 2: 1}).Return(nil)
+	m.storage.EXPECT().SetOrder(gomock.Any()).Return(nil)
+
+	order, err := orderBook.CancelUnpaid(paymentID)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(order.Status).To(Equal(store.Cancelled))
+}
+
+// newPrepaidOrder returns the order of newOrder, of which 60 was paid with gift cards and store credit
+func newPrepaidOrder() *store.Order {
+	order := newOrder()
+	order.TenderID = "tender"
+	order.Prepaid = 60
+	return order
+}
+
+func TestOrders_Ship_UsesPrepaidFirst(t *testing.T) {
+	g := NewWithT(t)
+
+	orderBook, m, finish := newOrders(t)
+	defer finish()
+
+	m.storage.EXPECT().GetOrder(orderID).Return(newPrepaidOrder(), nil)
+	m.payments.EXPECT().Capture(gomock.Any(), paymentID, uint(10+66-60), false).Return(&paymentStore.Payment{}, nil)
+	m.storage.EXPECT().SetOrder(gomock.Any()).Return(nil)
+
+	order, err := orderBook.Ship(context.Background(), orderID, []orders.Item{{ProductID: 1, Quantity: 2}})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(order.PrepaidUsed).To(Equal(uint(60)))
+	g.Expect(order.Captured).To(Equal(uint(16)))
+}
+
+func TestOrders_Cancel_CreditsUnusedPrepaid(t *testing.T) {
+	g := NewWithT(t)
+
+	orderBook, m, finish := newOrders(t)
+	defer finish()
+
+	partial := newPrepaidOrder()
+	partial.Lines[1].Shipped = 1
+	partial.PrepaidUsed = 60
+	m.storage.EXPECT().GetOrder(orderID).Return(partial, nil)
+	m.payments.EXPECT().Void(gomock.Any(), paymentID).Return(&paymentStore.Payment{}, nil)
+	m.inventory.EXPECT().ReturnToStock(map[uint]uint{1: 3}).Return(nil)
+	m.storage.EXPECT().SetOrder(gomock.Any()).Return(nil)
+
+	order, err := orderBook.Cancel(context.Background(), orderID, nil)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(order.Credited).To(BeZero())
+
+	unshipped := newPrepaidOrder()
+	unshipped.PaymentID = ""
+	unshipped.Prepaid = unshipped.Total.Amount
+	m.storage.EXPECT().GetOrder(orderID).Return(unshipped, nil)
+	m.inventory.EXPECT().ReturnToStock(map[uint]uint{1: 3, 2: 1}).Return(nil)
+	m.wallet.EXPECT().Refund(userID, uint(160), orderID).Return(&walletStore.Entry{ID: "entry"}, nil)
+	m.storage.EXPECT().SetOrder(gomock.Any()).Return(nil)
+
+	order, err = orderBook.Cancel(context.Background(), orderID, nil)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(order.Credited).To(Equal(uint(160)))
+	g.Expect(order.Status).To(Equal(store.Cancelled))
+}
+
+func TestOrders_Refund_SplitsBetweenPaymentAndStoreCredit(t *testing.T) {
+	g := NewWithT(t)
+
+	orderBook, m, finish := newOrders(t)
+	defer finish()
+
+	shipped := newPrepaidOrder()
+	shipped.Lines[0].Shipped = 3
+	shipped.Lines[1].Shipped = 1
+	shipped.Status = store.Shipped
+	shipped.PrepaidUsed = 60
+	shipped.Captured = 100
+	m.storage.EXPECT().GetOrder(orderID).Return(shipped, nil)
+	m.payments.EXPECT().Refund(gomock.Any(), paymentID, uint(100), provider.Goodwill).Return(&paymentStore.Payment{
+		Refunds: []*paymentStore.Refund{{ID: "refund", Amount: money.New(100, "EUR")}},
+	}, nil)
+	m.wallet.EXPECT().Refund(userID, uint(20), orderID).Return(&walletStore.Entry{ID: "entry"}, nil)
+	m.storage.EXPECT().SetOrder(gomock.Any()).Return(nil).Times(4)
+
+	order, err := orderBook.Refund(context.Background(), orderID, &orders.RefundRequest{Reason: provider.Goodwill, Amount: 120})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(order.Refunds[0].ID).To(Equal("refund"))
+	g.Expect(order.Refunds[0].StoreCredit).To(Equal(uint(20)))
+	g.Expect(order.Refunds[0].Pending).To(BeFalse())
+	g.Expect(order.RefundedToPayment()).To(Equal(uint(100)))
+}
+
+func TestOrders_Refund_ResumesAfterWalletFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	orderBook, m, finish := newOrders(t)
+	defer finish()
+
+	shipped := newPrepaidOrder()
+	shipped.Lines[0].Shipped = 3
+	shipped.Lines[1].Shipped = 1
+	shipped.Status = store.Shipped
+	shipped.PrepaidUsed = 60
+	shipped.Captured = 100
+	var saved *store.Order
+	m.storage.EXPECT().GetOrder(orderID).Return(shipped, nil)
+	m.storage.EXPECT().SetOrder(gomock.Any()).DoAndReturn(func(order *store.Order) error {
+		saved = order
+		return nil
+	}).Times(2)
+	m.payments.EXPECT().Refund(gomock.Any(), paymentID, uint(100), provider.Goodwill).Return(refunded(100), nil)
+	m.wallet.EXPECT().Refund(userID, uint(20), orderID).Return(nil, fmt.Errorf("wallet unavailable"))
+
+	_, err := orderBook.Refund(context.Background(), orderID, &orders.RefundRequest{Reason: provider.Goodwill, Amount: 120})
+
+	g.Expect(err).Should(HaveOccurred())
+	g.Expect(saved.Refunded).To(Equal(uint(120)))
+	g.Expect(saved.Refunds).To(HaveLen(1))
+	g.Expect(saved.Refunds[0].Pending).To(BeTrue())
+	g.Expect(saved.Refunds[0].PaidBack).To(BeTrue())
+
+	// retrying gives the store credit without refunding the payment again
+	m.storage.EXPECT().GetOrder(orderID).Return(saved, nil)
+	m.wallet.EXPECT().Refund(userID, uint(20), orderID).Return(&walletStore.Entry{ID: "entry"}, nil)
+	m.storage.EXPECT().SetOrder(gomock.Any()).Return(nil).Times(2)
+
+	order, err := orderBook.Refund(context.Background(), orderID, &orders.RefundRequest{Reason: provider.Goodwill, Amount: 120})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(order.Refunded).To(Equal(uint(120)))
+	g.Expect(order.Refunds).To(HaveLen(1))
+	g.Expect(order.Refunds[0].ID).To(Equal("refund"))
+	g.Expect(order.Refunds[0].Pending).To(BeFalse())
+	g.Expect(order.Status).To(Equal(store.Shipped))
+}
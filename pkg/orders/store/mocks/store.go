@@ -0,0 +1,244 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./store.go
+
+// Package mock_store is a generated GoMock package.
+package mock_store
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	store "github.com/mimatache/go-shop/pkg/orders/store"
+	reflect "reflect"
+)
+
+// Mocklogger is a mock of logger interface
+type Mocklogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockloggerMockRecorder
+}
+
+// MockloggerMockRecorder is the mock recorder for Mocklogger
+type MockloggerMockRecorder struct {
+	mock *Mocklogger
+}
+
+// NewMocklogger creates a new mock instance
+func NewMocklogger(ctrl *gomock.Controller) *Mocklogger {
+	mock := &Mocklogger{ctrl: ctrl}
+	mock.recorder = &MockloggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *Mocklogger) EXPECT() *MockloggerMockRecorder {
+	return m.recorder
+}
+
+// Infof mocks base method
+func (m *Mocklogger) Infof(msg string, args ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Infof", varargs...)
+}
+
+// Infof indicates an expected call of Infof
+func (mr *MockloggerMockRecorder) Infof(msg interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Infof", reflect.TypeOf((*Mocklogger)(nil).Infof), varargs...)
+}
+
+// Debugf mocks base method
+func (m *Mocklogger) Debugf(msg string, args ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Debugf", varargs...)
+}
+
+// Debugf indicates an expected call of Debugf
+func (mr *MockloggerMockRecorder) Debugf(msg interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Debugf", reflect.TypeOf((*Mocklogger)(nil).Debugf), varargs...)
+}
+
+// Debugw mocks base method
+func (m *Mocklogger) Debugw(msg string, keysAndValues ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range keysAndValues {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Debugw", varargs...)
+}
+
+// Debugw indicates an expected call of Debugw
+func (mr *MockloggerMockRecorder) Debugw(msg interface{}, keysAndValues ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, keysAndValues...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Debugw", reflect.TypeOf((*Mocklogger)(nil).Debugw), varargs...)
+}
+
+// MockUnderlyingStore is a mock of UnderlyingStore interface
+type MockUnderlyingStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockUnderlyingStoreMockRecorder
+}
+
+// MockUnderlyingStoreMockRecorder is the mock recorder for MockUnderlyingStore
+type MockUnderlyingStoreMockRecorder struct {
+	mock *MockUnderlyingStore
+}
+
+// NewMockUnderlyingStore creates a new mock instance
+func NewMockUnderlyingStore(ctrl *gomock.Controller) *MockUnderlyingStore {
+	mock := &MockUnderlyingStore{ctrl: ctrl}
+	mock.recorder = &MockUnderlyingStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockUnderlyingStore) EXPECT() *MockUnderlyingStoreMockRecorder {
+	return m.recorder
+}
+
+// Read mocks base method
+func (m *MockUnderlyingStore) Read(table, key string, value interface{}) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", table, key, value)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read
+func (mr *MockUnderlyingStoreMockRecorder) Read(table, key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockUnderlyingStore)(nil).Read), table, key, value)
+}
+
+// ReadAll mocks base method
+func (m *MockUnderlyingStore) ReadAll(table, key string, args ...interface{}) ([]interface{}, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{table, key}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReadAll", varargs...)
+	ret0, _ := ret[0].([]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAll indicates an expected call of ReadAll
+func (mr *MockUnderlyingStoreMockRecorder) ReadAll(table, key interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{table, key}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAll", reflect.TypeOf((*MockUnderlyingStore)(nil).ReadAll), varargs...)
+}
+
+// Write mocks base method
+func (m *MockUnderlyingStore) Write(table string, objs ...interface{}) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{table}
+	for _, a := range objs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Write", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Write indicates an expected call of Write
+func (mr *MockUnderlyingStoreMockRecorder) Write(table interface{}, objs ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{table}, objs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockUnderlyingStore)(nil).Write), varargs...)
+}
+
+// MockOrderStore is a mock of OrderStore interface
+type MockOrderStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderStoreMockRecorder
+}
+
+// MockOrderStoreMockRecorder is the mock recorder for MockOrderStore
+type MockOrderStoreMockRecorder struct {
+	mock *MockOrderStore
+}
+
+// NewMockOrderStore creates a new mock instance
+func NewMockOrderStore(ctrl *gomock.Controller) *MockOrderStore {
+	mock := &MockOrderStore{ctrl: ctrl}
+	mock.recorder = &MockOrderStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockOrderStore) EXPECT() *MockOrderStoreMockRecorder {
+	return m.recorder
+}
+
+// SetOrder mocks base method
+func (m *MockOrderStore) SetOrder(order *store.Order) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOrder", order)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetOrder indicates an expected call of SetOrder
+func (mr *MockOrderStoreMockRecorder) SetOrder(order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOrder", reflect.TypeOf((*MockOrderStore)(nil).SetOrder), order)
+}
+
+// GetOrder mocks base method
+func (m *MockOrderStore) GetOrder(orderID string) (*store.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrder", orderID)
+	ret0, _ := ret[0].(*store.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrder indicates an expected call of GetOrder
+func (mr *MockOrderStoreMockRecorder) GetOrder(orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockOrderStore)(nil).GetOrder), orderID)
+}
+
+// GetOrderForPayment mocks base method
+func (m *MockOrderStore) GetOrderForPayment(paymentID string) (*store.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderForPayment", paymentID)
+	ret0, _ := ret[0].(*store.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderForPayment indicates an expected call of GetOrderForPayment
+func (mr *MockOrderStoreMockRecorder) GetOrderForPayment(paymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderForPayment", reflect.TypeOf((*MockOrderStore)(nil).GetOrderForPayment), paymentID)
+}
+
+// GetOrdersForUser mocks base method
+func (m *MockOrderStore) GetOrdersForUser(userID string) ([]*store.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrdersForUser", userID)
+	ret0, _ := ret[0].([]*store.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrdersForUser indicates an expected call of GetOrdersForUser
+func (mr *MockOrderStoreMockRecorder) GetOrdersForUser(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersForUser", reflect.TypeOf((*MockOrderStore)(nil).GetOrdersForUser), userID)
+}
@@ -0,0 +1,265 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/mimatache/go-shop/internal/money"
+	"github.com/mimatache/go-shop/pkg/cart/cart"
+	"github.com/mimatache/go-shop/pkg/payments/provider"
+)
+
+type errors []error
+
+func (e errors) Error() string {
+	b := bytes.NewBufferString("")
+	for _, err := range e {
+		_, _ = fmt.Fprintf(b, "\t%s\n", err)
+	}
+	return b.String()
+}
+
+// Status is the state of an order
+type Status string
+
+const (
+	// Placed nothing was shipped yet
+	Placed Status = "placed"
+	// PartiallyShipped some items were shipped and others are still waiting
+	PartiallyShipped Status = "partially_shipped"
+	// Shipped every item that was not cancelled was shipped
+	Shipped Status = "shipped"
+	// Cancelled every item was cancelled before being shipped
+	Cancelled Status = "cancelled"
+	// Refunded everything paid for the order was given back
+	Refunded Status = "refunded"
+)
+
+// Line is a product bought with the order
+type Line struct {
+	ProductID uint `json:"productID"`
+	Quantity  uint `json:"quantity"`
+	// Amount is what the user pays for the whole line, after discounts and taxes
+	Amount    uint `json:"amount"`
+	Shipped   uint `json:"shipped"`
+	Cancelled uint `json:"cancelled"`
+	// Refunded is how many of the shipped items were refunded
+	Refunded uint `json:"refunded"`
+}
+
+// Pending returns how many items still have to be shipped
+func (l Line) Pending() uint {
+	return l.Quantity - l.Shipped - l.Cancelled
+}
+
+// AmountFor returns what the user pays for the given number of items of the line.
+// The result is rounded down, so the whole line amount is only reached when all the items are counted
+func (l Line) AmountFor(quantity uint) (uint, error) {
+	if l.Quantity == 0 {
+		return 0, nil
+	}
+	amount, err := money.Mul(l.Amount, quantity)
+	if err != nil {
+		return 0, err
+	}
+	return amount / l.Quantity, nil
+}
+
+// AmountBetween returns what the user pays for the items of the line counted after the first from items, up to the to items
+func (l Line) AmountBetween(from, to uint) (uint, error) {
+	upper, err := l.AmountFor(to)
+	if err != nil {
+		return 0, err
+	}
+	lower, err := l.AmountFor(from)
+	if err != nil {
+		return 0, err
+	}
+	return money.Sub(upper, lower)
+}
+
+// RefundLine is a number of refunded items of a product
+type RefundLine struct {
+	ProductID uint `json:"productID"`
+	Quantity  uint `json:"quantity"`
+	// Restock returns the refunded items to the stock
+	Restock bool `json:"restock"`
+}
+
+// Refund is money given back for the order
+type Refund struct {
+	// ID is the ID of the refund given by the payment provider, or of the wallet entry when it was all given as store credit
+	ID     string                `json:"id"`
+	Amount uint                  `json:"amount"`
+	Reason provider.RefundReason `json:"reason"`
+	// StoreCredit is the part of the amount given back to the wallet of the user instead of the payment
+	StoreCredit uint `json:"storeCredit,omitempty"`
+	// Lines are the items the refund is for. Refunds that are not for specific items have no lines
+	Lines     []*RefundLine `json:"lines,omitempty"`
+	CreatedAt time.Time     `json:"createdAt"`
+	// Pending is set until every step of the refund was done. The order is saved after each step,
+	// so a refund interrupted by a failure resumes where it stopped instead of giving the money back twice
+	Pending bool `json:"pending,omitempty"`
+	// PaidBack is set once the payment gave back its part of the amount
+	PaidBack bool `json:"-"`
+	// Credited is set once the store credit part of the amount was given to the wallet of the user
+	Credited bool `json:"-"`
+}
+
+// ToPayment returns the part of the amount given back through the payment
+func (r Refund) ToPayment() uint {
+	return r.Amount - r.StoreCredit
+}
+
+// Restock returns the number of refunded items of every product that go back to the stock
+func (r Refund) Restock() map[uint]uint {
+	restock := map[uint]uint{}
+	for _, line := range r.Lines {
+		if line.Restock {
+			restock[line.ProductID] += line.Quantity
+		}
+	}
+	return restock
+}
+
+// Order is a checked out cart, together with the payment authorized for it
+type Order struct {
+	ID     string  `json:"id"`
+	UserID string  `json:"-"`
+	Status Status  `json:"status"`
+	Lines  []*Line `json:"lines"`
+	// ShippingCost and Total set the currency of the order, which the other amounts are given in
+	ShippingCost money.Money `json:"shippingCost"`
+	Total        money.Money `json:"total"`
+	// PaymentID is the payment authorized for the order. It is empty when gift cards and store credit paid for everything
+	PaymentID string `json:"paymentID,omitempty"`
+	// TenderID is what gift cards and store credit paid at checkout, if anything
+	TenderID string `json:"tenderID,omitempty"`
+	// Prepaid is the amount paid at checkout with gift cards and store credit. It pays for the shipped items
+	// before anything is captured from the payment
+	Prepaid uint `json:"prepaid,omitempty"`
+	// PrepaidUsed is the part of the prepaid amount that paid for shipped items
+	PrepaidUsed uint `json:"prepaidUsed,omitempty"`
+	// Credited is the part of the prepaid amount given back as store credit because the items were cancelled
+	Credited uint `json:"credited,omitempty"`
+	// Captured is what was taken from the payment for the shipped items
+	Captured  uint           `json:"captured"`
+	Refunded  uint           `json:"refunded"`
+	Refunds   []*Refund      `json:"refunds,omitempty"`
+	Delivery  *cart.Delivery `json:"delivery,omitempty"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+}
+
+// Currency returns the currency of every amount of the order
+func (o Order) Currency() money.Currency {
+	return o.Total.Currency
+}
+
+// Pending returns how many items of the order still have to be shipped
+func (o Order) Pending() uint {
+	var pending uint
+	for _, line := range o.Lines {
+		pending += line.Pending()
+	}
+	return pending
+}
+
+// Paid returns what was paid for the shipped items, from the payment and from the prepaid amount
+func (o Order) Paid() uint {
+	return o.Captured + o.PrepaidUsed
+}
+
+// RefundedToPayment returns the part of the refunds given back through the payment
+func (o Order) RefundedToPayment() uint {
+	refunded := o.Refunded
+	for _, refund := range o.Refunds {
+		refunded -= refund.StoreCredit
+	}
+	return refunded
+}
+
+// PendingRefund returns the position of the refund that was not completed yet, if any
+func (o Order) PendingRefund() (int, bool) {
+	for i, refund := range o.Refunds {
+		if refund.Pending {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// HasShipped checks if any item of the order was shipped
+func (o Order) HasShipped() bool {
+	for _, line := range o.Lines {
+		if line.Shipped > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Line returns the line of the given product
+func (o Order) Line(productID uint) (*Line, bool) {
+	for _, line := range o.Lines {
+		if line.ProductID == productID {
+			return line, true
+		}
+	}
+	return nil, false
+}
+
+// Validate checks that an order adheres to constraints
+func (o Order) Validate() error {
+	var errs errors
+	if o.ID == "" {
+		errs = append(errs, fmt.Errorf("ID cannot be empty"))
+	}
+	if o.UserID == "" {
+		errs = append(errs, fmt.Errorf("user ID cannot be empty"))
+	}
+	if o.ShippingCost.Currency != o.Currency() {
+		errs = append(errs, fmt.Errorf("the shipping cost must be in %s", o.Currency()))
+	}
+	if o.PaymentID == "" && o.Prepaid < o.Total.Amount {
+		errs = append(errs, fmt.Errorf("payment ID cannot be empty when the order is not prepaid"))
+	}
+	if o.PrepaidUsed+o.Credited > o.Prepaid {
+		errs = append(errs, fmt.Errorf("used %d and credited %d are more than the prepaid %d", o.PrepaidUsed, o.Credited, o.Prepaid))
+	}
+	if len(o.Lines) == 0 {
+		errs = append(errs, fmt.Errorf("an order needs at least one line"))
+	}
+	for _, line := range o.Lines {
+		if line.Shipped+line.Cancelled > line.Quantity {
+			errs = append(errs, fmt.Errorf("more items of product %d were shipped or cancelled than ordered", line.ProductID))
+		}
+		if line.Refunded > line.Shipped {
+			errs = append(errs, fmt.Errorf("more items of product %d were refunded than shipped", line.ProductID))
+		}
+	}
+	if o.Refunded > o.Paid() {
+		errs = append(errs, fmt.Errorf("refunded %d is more than the paid %d", o.Refunded, o.Paid()))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Copy returns a copy of the order that can be changed without altering the stored one
+func (o *Order) Copy() *Order {
+	order := *o
+	order.Lines = make([]*Line, len(o.Lines))
+	for i, line := range o.Lines {
+		l := *line
+		order.Lines[i] = &l
+	}
+	order.Refunds = make([]*Refund, len(o.Refunds))
+	for i, refund := range o.Refunds {
+		r := *refund
+		order.Refunds[i] = &r
+	}
+	return &order
+}
@@ -0,0 +1,224 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/cart/cart"
+)
+
+//go:generate mockgen -source ./store.go -destination mocks/store.go
+
+type logger interface {
+	Infof(msg string, args ...interface{})
+	Debugf(msg string, args ...interface{})
+	Debugw(msg string, keysAndValues ...interface{})
+}
+
+const (
+	id      = "id"
+	user    = "user"
+	payment = "payment"
+)
+
+var table = &OrderTable{name: "order"}
+
+// GetTable returns the order table
+func GetTable() *OrderTable {
+	return table
+}
+
+// OrderTable the order table schema
+type OrderTable struct {
+	name string
+}
+
+// GetName returns the name of the order table
+func (o *OrderTable) GetName() string {
+	return o.name
+}
+
+// GetTableSchema returns the schema of the order table
+func (o *OrderTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: o.name,
+		Indexes: map[string]*memdb.IndexSchema{
+			id: {
+				Name:    id,
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "ID"},
+			},
+			user: {
+				Name:    user,
+				Unique:  false,
+				Indexer: &memdb.StringFieldIndex{Field: "UserID"},
+			},
+			payment: {
+				Name:         payment,
+				Unique:       true,
+				AllowMissing: true,
+				Indexer:      &memdb.StringFieldIndex{Field: "PaymentID"},
+			},
+		},
+	}
+}
+
+// GetPersonalRows returns the orders of the user
+func (o *OrderTable) GetPersonalRows(db store.Reader, userID string) ([]interface{}, error) {
+	return db.ReadAll(o.name, user, userID)
+}
+
+// Export returns the order
+func (o *OrderTable) Export(row interface{}) interface{} {
+	return row
+}
+
+// Anonymize keeps the order for the pseudonym, since it is needed for the accounts of the shop, and only keeps the
+// region of its delivery address. Orders with items still to ship cannot be anonymized, since they need the address
+func (o *OrderTable) Anonymize(row interface{}, userID string, pseudonym string) (interface{}, error) {
+	order := row.(*Order)
+	if order.Pending() > 0 {
+		return nil, store.NewCannotEraseError(o.name, fmt.Sprintf("order %s still has items to ship", order.ID))
+	}
+	anonymized := order.Copy()
+	anonymized.UserID = pseudonym
+	if order.Delivery != nil {
+		anonymized.Delivery = &cart.Delivery{ShippingMethod: order.Delivery.ShippingMethod}
+		if order.Delivery.Address != nil {
+			anonymized.Delivery.Address = &cart.Address{Region: order.Delivery.Address.Region}
+		}
+	}
+	return anonymized, nil
+}
+
+// UnderlyingStore represents the interface that the DB should implement to be usable
+type UnderlyingStore interface {
+	Read(table string, key string, value interface{}) (interface{}, error)
+	ReadAll(table string, key string, args ...interface{}) ([]interface{}, error)
+	Write(table string, objs ...interface{}) error
+}
+
+// OrderStore represents the order store
+type OrderStore interface {
+	SetOrder(order *Order) error
+	GetOrder(orderID string) (*Order, error)
+	GetOrderForPayment(paymentID string) (*Order, error)
+	GetOrdersForUser(userID string) ([]*Order, error)
+}
+
+// New start a new instance of the order store
+func New(log logger, db UnderlyingStore) OrderStore {
+	return &orderLogger{
+		log:  log,
+		next: &orderStore{db: db},
+	}
+}
+
+type orderStore struct {
+	db UnderlyingStore
+}
+
+// SetOrder creates or replaces an order
+func (o *orderStore) SetOrder(order *Order) error {
+	if err := order.Validate(); err != nil {
+		return err
+	}
+	return o.db.Write(table.GetName(), order)
+}
+
+// GetOrder returns the order with the given ID
+func (o *orderStore) GetOrder(orderID string) (*Order, error) {
+	item, err := o.db.Read(table.GetName(), id, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return item.(*Order), nil
+}
+
+// GetOrderForPayment returns the order paid by the given payment
+func (o *orderStore) GetOrderForPayment(paymentID string) (*Order, error) {
+	item, err := o.db.Read(table.GetName(), payment, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	return item.(*Order), nil
+}
+
+// GetOrdersForUser returns all the orders of a user
+func (o *orderStore) GetOrdersForUser(userID string) ([]*Order, error) {
+	rows, err := o.db.ReadAll(table.GetName(), user, userID)
+	if err != nil {
+		return nil, err
+	}
+	orders := make([]*Order, 0, len(rows))
+	for _, row := range rows {
+		orders = append(orders, row.(*Order))
+	}
+	return orders, nil
+}
+
+type orderLogger struct {
+	log  logger
+	next OrderStore
+}
+
+func (o *orderLogger) SetOrder(order *Order) error {
+	var err error
+	defer func() {
+		if err != nil {
+			o.log.Debugf("could not store order %s err: %s", order.ID, err.Error())
+			return
+		}
+		o.log.Debugw("stored order", "id", order.ID, "user", order.UserID, "status", order.Status)
+	}()
+
+	err = o.next.SetOrder(order)
+	return err
+}
+
+func (o *orderLogger) GetOrder(orderID string) (*Order, error) {
+	var err error
+	var order *Order
+	defer func() {
+		if err != nil {
+			o.log.Debugf("could not retrieve order %s err: %s", orderID, err.Error())
+			return
+		}
+		o.log.Debugf("retrieved order %s", orderID)
+	}()
+
+	order, err = o.next.GetOrder(orderID)
+	return order, err
+}
+
+func (o *orderLogger) GetOrderForPayment(paymentID string) (*Order, error) {
+	var err error
+	var order *Order
+	defer func() {
+		if err != nil {
+			o.log.Debugf("could not retrieve order of payment %s err: %s", paymentID, err.Error())
+			return
+		}
+		o.log.Debugf("retrieved order %s of payment %s", order.ID, paymentID)
+	}()
+
+	order, err = o.next.GetOrderForPayment(paymentID)
+	return order, err
+}
+
+func (o *orderLogger) GetOrdersForUser(userID string) ([]*Order, error) {
+	var err error
+	var orders []*Order
+	defer func() {
+		if err != nil {
+			o.log.Debugf("could not retrieve the orders of user %s err: %s", userID, err.Error())
+			return
+		}
+		o.log.Debugf("retrieved %d orders of user %s", len(orders), userID)
+	}()
+
+	orders, err = o.next.GetOrdersForUser(userID)
+	return orders, err
+}
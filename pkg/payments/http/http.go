@@ -0,0 +1,117 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mimatache/go-shop/internal/http/helpers"
+	"github.com/mimatache/go-shop/internal/rbac"
+	"github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/payments/ledger"
+	"github.com/mimatache/go-shop/pkg/payments/processor"
+)
+
+func New(payments *processor.Processor, paymentLedger *ledger.Ledger) *PaymentsAPI {
+	return &PaymentsAPI{
+		payments: payments,
+		ledger:   paymentLedger,
+	}
+}
+
+type PaymentsAPI struct {
+	payments *processor.Processor
+	ledger   *ledger.Ledger
+}
+
+func (p *PaymentsAPI) getPayment(w http.ResponseWriter, r *http.Request) {
+	payment, err := p.payments.GetPayment(mux.Vars(r)["id"])
+	if err != nil {
+		if store.IsNotFoundError(err) {
+			helpers.FormatError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	helpers.FormatResponse(w, payment, http.StatusOK)
+}
+
+func (p *PaymentsAPI) getPaymentLedger(w http.ResponseWriter, r *http.Request) {
+	payment, err := p.payments.GetPayment(mux.Vars(r)["id"])
+	if err != nil {
+		if store.IsNotFoundError(err) {
+			helpers.FormatError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	transactions, err := p.ledger.GetTransactions(payment.AuthorizationID)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	helpers.FormatResponse(w, transactions, http.StatusOK)
+}
+
+func (p *PaymentsAPI) getBalances(w http.ResponseWriter, r *http.Request) {
+	balances, err := p.ledger.Balances()
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	helpers.FormatResponse(w, balances, http.StatusOK)
+}
+
+func (p *PaymentsAPI) getReports(w http.ResponseWriter, r *http.Request) {
+	reports, err := p.ledger.GetReports()
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	helpers.FormatResponse(w, reports, http.StatusOK)
+}
+
+func (p *PaymentsAPI) getReport(w http.ResponseWriter, r *http.Request) {
+	report, err := p.ledger.GetReport(mux.Vars(r)["id"])
+	if err != nil {
+		if store.IsNotFoundError(err) {
+			helpers.FormatError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	helpers.FormatResponse(w, report, http.StatusOK)
+}
+
+// reconcile compares the settlement file given as the CSV body of the request with the ledger
+func (p *PaymentsAPI) reconcile(w http.ResponseWriter, r *http.Request) {
+	report, err := p.ledger.Reconcile(mux.Vars(r)["id"], r.Body)
+	if err != nil {
+		if ledger.IsInvalidSettlementError(err) {
+			helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	helpers.FormatResponse(w, report, http.StatusOK)
+}
+
+// AddRoutes registers the API routes to a router. Payment records, the ledger and the settlement reconciliation
+// reports can only be accessed by administrators
+func (p *PaymentsAPI) AddRoutes(router *mux.Router, adminHandler func(permission rbac.Permission) func(http.Handler) http.Handler) {
+	viewHandler := adminHandler(rbac.ViewPayments)
+	paymentsRouter := router.PathPrefix("/admin/payments").Subrouter()
+	paymentsRouter.Handle("/{id}", viewHandler(http.HandlerFunc(p.getPayment))).Methods(http.MethodGet)
+	paymentsRouter.Handle("/{id}/ledger", viewHandler(http.HandlerFunc(p.getPaymentLedger))).Methods(http.MethodGet)
+
+	router.Handle("/admin/ledger/balances", viewHandler(http.HandlerFunc(p.getBalances))).Methods(http.MethodGet)
+
+	reconciliationsRouter := router.PathPrefix("/admin/reconciliations").Subrouter()
+	reconciliationsRouter.Handle("", viewHandler(http.HandlerFunc(p.getReports))).Methods(http.MethodGet)
+	reconciliationsRouter.Handle("/{id}", viewHandler(http.HandlerFunc(p.getReport))).Methods(http.MethodGet)
+	reconciliationsRouter.Handle("/{id}", adminHandler(rbac.ReconcilePayments)(http.HandlerFunc(p.reconcile))).Methods(http.MethodPut)
+}
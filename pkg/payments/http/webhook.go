@@ -0,0 +1,66 @@
+package http
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mimatache/go-shop/internal/http/helpers"
+	"github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/payments/processor"
+	"github.com/mimatache/go-shop/pkg/payments/webhook"
+)
+
+// maxEventSize is the largest event body accepted from the provider
+const maxEventSize = 64 * 1024
+
+func NewWebhookAPI(receiver *webhook.Receiver, enabled bool) *WebhookAPI {
+	return &WebhookAPI{
+		receiver: receiver,
+		enabled:  enabled,
+	}
+}
+
+type WebhookAPI struct {
+	receiver *webhook.Receiver
+	enabled  bool
+}
+
+func (wh *WebhookAPI) receive(w http.ResponseWriter, r *http.Request) {
+	if !wh.enabled {
+		helpers.FormatError(w, "webhooks are disabled", http.StatusForbidden)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxEventSize))
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	event, err := wh.receiver.Receive(r.Header.Get(webhook.SignatureHeader), body)
+	if err != nil {
+		formatWebhookError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, map[string]string{"id": event.ID}, http.StatusOK)
+}
+
+// AddRoutes registers the route the payment provider sends its events to. The events are authenticated by their signature
+func (wh *WebhookAPI) AddRoutes(router *mux.Router) {
+	router.HandleFunc("/payments/webhooks", wh.receive).Methods(http.MethodPost)
+}
+
+func formatWebhookError(w http.ResponseWriter, err error) {
+	switch {
+	case webhook.IsInvalidSignatureError(err):
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+	case webhook.IsInvalidEventError(err):
+		helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+	case store.IsNotFoundError(err):
+		helpers.FormatError(w, err.Error(), http.StatusNotFound)
+	case processor.IsInvalidPaymentError(err):
+		helpers.FormatError(w, err.Error(), http.StatusConflict)
+	default:
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+	}
+}
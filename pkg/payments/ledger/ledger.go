@@ -0,0 +1,239 @@
+package ledger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mimatache/go-shop/internal/money"
+	"github.com/mimatache/go-shop/pkg/payments/provider"
+	"github.com/mimatache/go-shop/pkg/payments/store"
+)
+
+const idBytes = 16
+
+type logger interface {
+	Infow(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+}
+
+// New wraps the payment provider in a ledger, recording every call made to it
+func New(log logger, next provider.Provider, storage store.LedgerStore) *Ledger {
+	return &Ledger{
+		log:     log,
+		next:    next,
+		storage: storage,
+		now:     time.Now,
+	}
+}
+
+// Ledger records every call made to the payment provider and its outcome. The money taken by charges and captures
+// and given back by refunds is posted in double entry to the provider clearing and customer payments accounts,
+// so that it can be reconciled against what the provider settles
+type Ledger struct {
+	log     logger
+	next    provider.Provider
+	storage store.LedgerStore
+	now     func() time.Time
+	sync.Mutex
+}
+
+// Charge takes money from the user and records the call
+func (l *Ledger) Charge(ctx context.Context, request *provider.ChargeRequest) (*provider.Charge, error) {
+	charge, err := l.next.Charge(ctx, request)
+	transaction := &store.Transaction{
+		Type:      store.ChargeTransaction,
+		Reference: request.Reference,
+		Amount:    request.Amount,
+		Currency:  request.Currency,
+	}
+	if err == nil {
+		transaction.ProviderID = charge.ID
+	}
+	l.record(transaction, err)
+	return charge, err
+}
+
+// Authorize reserves money from the user and records the call
+func (l *Ledger) Authorize(ctx context.Context, request *provider.ChargeRequest) (*provider.Authorization, error) {
+	authorization, err := l.next.Authorize(ctx, request)
+	transaction := &store.Transaction{
+		Type:      store.AuthorizationTransaction,
+		Reference: request.Reference,
+		Amount:    request.Amount,
+		Currency:  request.Currency,
+	}
+	if err == nil {
+		transaction.AuthorizationID = authorization.ID
+	}
+	l.record(transaction, err)
+	return authorization, err
+}
+
+// Capture takes money reserved by an authorization and records the call
+func (l *Ledger) Capture(ctx context.Context, request *provider.CaptureRequest) (*provider.Capture, error) {
+	capture, err := l.next.Capture(ctx, request)
+	transaction := &store.Transaction{
+		Type:            store.CaptureTransaction,
+		AuthorizationID: request.AuthorizationID,
+		Reference:       request.Reference,
+		Amount:          request.Amount,
+		Currency:        l.currency(request.AuthorizationID),
+	}
+	if err == nil {
+		transaction.ProviderID = capture.ID
+	}
+	l.record(transaction, err)
+	return capture, err
+}
+
+// Void releases an authorization and records the call
+func (l *Ledger) Void(ctx context.Context, authorizationID string) error {
+	err := l.next.Void(ctx, authorizationID)
+	l.record(&store.Transaction{
+		Type:            store.VoidTransaction,
+		AuthorizationID: authorizationID,
+		Currency:        l.currency(authorizationID),
+	}, err)
+	return err
+}
+
+// Refund gives back captured money and records the call
+func (l *Ledger) Refund(ctx context.Context, request *provider.RefundRequest) (*provider.Refund, error) {
+	refund, err := l.next.Refund(ctx, request)
+	transaction := &store.Transaction{
+		Type:            store.RefundTransaction,
+		AuthorizationID: request.AuthorizationID,
+		Reference:       request.Reference,
+		Amount:          request.Amount,
+		Currency:        l.currency(request.AuthorizationID),
+	}
+	if err == nil {
+		transaction.ProviderID = refund.ID
+	}
+	l.record(transaction, err)
+	return refund, err
+}
+
+// GetTransactions returns the calls made for an authorization, in the order they were made
+func (l *Ledger) GetTransactions(authorizationID string) ([]*store.Transaction, error) {
+	transactions, err := l.storage.GetTransactionsForAuthorization(authorizationID)
+	if err != nil {
+		return nil, err
+	}
+	sortTransactions(transactions)
+	return transactions, nil
+}
+
+// Balance is the money posted to an account in a currency
+type Balance struct {
+	Account  store.Account  `json:"account"`
+	Currency money.Currency `json:"currency"`
+	Debit    uint           `json:"debit"`
+	Credit   uint           `json:"credit"`
+}
+
+// Balances returns the money posted to every account, by currency
+func (l *Ledger) Balances() ([]*Balance, error) {
+	transactions, err := l.storage.GetTransactions()
+	if err != nil {
+		return nil, err
+	}
+	balances := []*Balance{}
+	byAccount := map[store.Account]map[money.Currency]*Balance{}
+	for _, transaction := range transactions {
+		for _, posting := range transaction.Postings {
+			if byAccount[posting.Account] == nil {
+				byAccount[posting.Account] = map[money.Currency]*Balance{}
+			}
+			balance, ok := byAccount[posting.Account][transaction.Currency]
+			if !ok {
+				balance = &Balance{Account: posting.Account, Currency: transaction.Currency}
+				byAccount[posting.Account][transaction.Currency] = balance
+				balances = append(balances, balance)
+			}
+			balance.Debit += posting.Debit
+			balance.Credit += posting.Credit
+		}
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		if balances[i].Account != balances[j].Account {
+			return balances[i].Account < balances[j].Account
+		}
+		return balances[i].Currency < balances[j].Currency
+	})
+	return balances, nil
+}
+
+// record stores the call with its outcome. A call that cannot be recorded is only logged,
+// since the provider already carried it out
+func (l *Ledger) record(transaction *store.Transaction, err error) {
+	id, idErr := newID()
+	if idErr != nil {
+		l.log.Errorw("could not record payment provider call", "type", transaction.Type, "reference", transaction.Reference, "error", idErr)
+		return
+	}
+	transaction.ID = id
+	transaction.CreatedAt = l.now()
+	switch {
+	case err == nil:
+		transaction.Status = store.TransactionSucceeded
+		transaction.Postings = postings(transaction)
+	case provider.IsDeclinedError(err):
+		transaction.Status = store.TransactionDeclined
+		transaction.Error = err.Error()
+	default:
+		transaction.Status = store.TransactionFailed
+		transaction.Error = err.Error()
+	}
+	if err := l.storage.AddTransaction(transaction); err != nil {
+		l.log.Errorw("could not record payment provider call", "type", transaction.Type, "reference", transaction.Reference, "error", err)
+	}
+}
+
+// currency returns the currency of an authorization, as recorded when it was made
+func (l *Ledger) currency(authorizationID string) money.Currency {
+	transactions, err := l.storage.GetTransactionsForAuthorization(authorizationID)
+	if err != nil {
+		return ""
+	}
+	for _, transaction := range transactions {
+		if transaction.Type == store.AuthorizationTransaction && transaction.Status == store.TransactionSucceeded {
+			return transaction.Currency
+		}
+	}
+	return ""
+}
+
+// postings returns the entries of a succeeded call. Charges and captures move the money from the customers to the
+// provider, which owes it to the shop until it is settled, and refunds move it back
+func postings(transaction *store.Transaction) []*store.Posting {
+	if !transaction.Type.MovesMoney() || transaction.Amount == 0 {
+		return nil
+	}
+	debited, credited := store.ProviderClearing, store.CustomerPayments
+	if transaction.Type == store.RefundTransaction {
+		debited, credited = credited, debited
+	}
+	return []*store.Posting{
+		{Account: debited, Debit: transaction.Amount},
+		{Account: credited, Credit: transaction.Amount},
+	}
+}
+
+func sortTransactions(transactions []*store.Transaction) {
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].CreatedAt.Before(transactions[j].CreatedAt)
+	})
+}
+
+func newID() (string, error) {
+	b := make([]byte, idBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,208 @@
+package ledger_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/money"
+	"github.com/mimatache/go-shop/pkg/payments/ledger"
+	"github.com/mimatache/go-shop/pkg/payments/provider"
+	mock_provider "github.com/mimatache/go-shop/pkg/payments/provider/mocks"
+	"github.com/mimatache/go-shop/pkg/payments/store"
+	mock_store "github.com/mimatache/go-shop/pkg/payments/store/mocks"
+)
+
+const authorizationID = "auth"
+
+type nopLogger struct{}
+
+func (nopLogger) Infow(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Errorw(msg string, keysAndValues ...interface{}) {}
+
+type mocks struct {
+	provider *mock_provider.MockProvider
+	storage  *mock_store.MockLedgerStore
+}
+
+func newLedger(t *testing.T) (*ledger.Ledger, *mocks, func()) {
+	ctrl := gomock.NewController(t)
+	m := &mocks{
+		provider: mock_provider.NewMockProvider(ctrl),
+		storage:  mock_store.NewMockLedgerStore(ctrl),
+	}
+	return ledger.New(nopLogger{}, m.provider, m.storage), m, ctrl.Finish
+}
+
+// authorized returns the recorded authorization the captures, voids and refunds take their currency from
+func authorized() []*store.Transaction {
+	return []*store.Transaction{{
+		ID:              "authorization",
+		Type:            store.AuthorizationTransaction,
+		Status:          store.TransactionSucceeded,
+		AuthorizationID: authorizationID,
+		Amount:          100,
+		Currency:        "USD",
+	}}
+}
+
+func TestLedger_Authorize(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder, m, finish := newLedger(t)
+	defer finish()
+
+	m.provider.EXPECT().Authorize(gomock.Any(), gomock.Any()).Return(&provider.Authorization{ID: authorizationID, Amount: 100}, nil)
+	m.storage.EXPECT().AddTransaction(gomock.Any()).DoAndReturn(func(transaction *store.Transaction) error {
+		g.Expect(transaction.ID).ShouldNot(BeEmpty())
+		g.Expect(transaction.Type).To(Equal(store.AuthorizationTransaction))
+		g.Expect(transaction.Status).To(Equal(store.TransactionSucceeded))
+		g.Expect(transaction.AuthorizationID).To(Equal(authorizationID))
+		g.Expect(transaction.Reference).To(Equal("payment"))
+		g.Expect(transaction.Currency).To(Equal(money.Currency("USD")))
+		g.Expect(transaction.Postings).To(BeEmpty())
+		return nil
+	})
+
+	authorization, err := recorder.Authorize(context.Background(), &provider.ChargeRequest{Reference: "payment", Amount: 100, Currency: "USD"})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(authorization.ID).To(Equal(authorizationID))
+}
+
+func TestLedger_Authorize_Declined(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder, m, finish := newLedger(t)
+	defer finish()
+
+	m.provider.EXPECT().Authorize(gomock.Any(), gomock.Any()).Return(nil, provider.NewDeclined(provider.InsufficientFunds, "no money"))
+	m.storage.EXPECT().AddTransaction(gomock.Any()).DoAndReturn(func(transaction *store.Transaction) error {
+		g.Expect(transaction.Status).To(Equal(store.TransactionDeclined))
+		g.Expect(transaction.Error).ShouldNot(BeEmpty())
+		g.Expect(transaction.AuthorizationID).To(BeEmpty())
+		return nil
+	})
+
+	_, err := recorder.Authorize(context.Background(), &provider.ChargeRequest{Reference: "payment", Amount: 100, Currency: "USD"})
+
+	g.Expect(provider.IsDeclinedError(err)).To(BeTrue())
+}
+
+func TestLedger_Capture(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder, m, finish := newLedger(t)
+	defer finish()
+
+	m.provider.EXPECT().Capture(gomock.Any(), gomock.Any()).Return(&provider.Capture{ID: "capture", AuthorizationID: authorizationID, Amount: 60}, nil)
+	m.storage.EXPECT().GetTransactionsForAuthorization(authorizationID).Return(authorized(), nil)
+	m.storage.EXPECT().AddTransaction(gomock.Any()).DoAndReturn(func(transaction *store.Transaction) error {
+		g.Expect(transaction.Validate()).To(Succeed())
+		g.Expect(transaction.Status).To(Equal(store.TransactionSucceeded))
+		g.Expect(transaction.ProviderID).To(Equal("capture"))
+		g.Expect(transaction.Currency).To(Equal(money.Currency("USD")))
+		g.Expect(transaction.Postings).To(ConsistOf(
+			&store.Posting{Account: store.ProviderClearing, Debit: 60},
+			&store.Posting{Account: store.CustomerPayments, Credit: 60},
+		))
+		return nil
+	})
+
+	_, err := recorder.Capture(context.Background(), &provider.CaptureRequest{AuthorizationID: authorizationID, Reference: "payment-1", Amount: 60})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+}
+
+func TestLedger_Capture_Failed(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder, m, finish := newLedger(t)
+	defer finish()
+
+	m.provider.EXPECT().Capture(gomock.Any(), gomock.Any()).Return(nil, provider.NewUnavailable("timeout"))
+	m.storage.EXPECT().GetTransactionsForAuthorization(authorizationID).Return(authorized(), nil)
+	m.storage.EXPECT().AddTransaction(gomock.Any()).DoAndReturn(func(transaction *store.Transaction) error {
+		g.Expect(transaction.Status).To(Equal(store.TransactionFailed))
+		g.Expect(transaction.ProviderID).To(BeEmpty())
+		g.Expect(transaction.Postings).To(BeEmpty())
+		return nil
+	})
+
+	_, err := recorder.Capture(context.Background(), &provider.CaptureRequest{AuthorizationID: authorizationID, Reference: "payment-1", Amount: 60})
+
+	g.Expect(err).Should(HaveOccurred())
+}
+
+func TestLedger_Refund(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder, m, finish := newLedger(t)
+	defer finish()
+
+	m.provider.EXPECT().Refund(gomock.Any(), gomock.Any()).Return(&provider.Refund{ID: "refund", AuthorizationID: authorizationID, Amount: 20}, nil)
+	m.storage.EXPECT().GetTransactionsForAuthorization(authorizationID).Return(authorized(), nil)
+	m.storage.EXPECT().AddTransaction(gomock.Any()).DoAndReturn(func(transaction *store.Transaction) error {
+		g.Expect(transaction.Validate()).To(Succeed())
+		g.Expect(transaction.Postings).To(ConsistOf(
+			&store.Posting{Account: store.CustomerPayments, Debit: 20},
+			&store.Posting{Account: store.ProviderClearing, Credit: 20},
+		))
+		return nil
+	})
+
+	_, err := recorder.Refund(context.Background(), &provider.RefundRequest{AuthorizationID: authorizationID, Reference: "payment-r1", Amount: 20})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+}
+
+func TestLedger_Void_NotRecorded(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder, m, finish := newLedger(t)
+	defer finish()
+
+	m.provider.EXPECT().Void(gomock.Any(), authorizationID).Return(nil)
+	m.storage.EXPECT().GetTransactionsForAuthorization(authorizationID).Return(authorized(), nil)
+	m.storage.EXPECT().AddTransaction(gomock.Any()).Return(fmt.Errorf("store down"))
+
+	err := recorder.Void(context.Background(), authorizationID)
+
+	g.Expect(err).ShouldNot(HaveOccurred(), "the void went through, even if it could not be recorded")
+}
+
+func TestLedger_Balances(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder, m, finish := newLedger(t)
+	defer finish()
+
+	m.storage.EXPECT().GetTransactions().Return([]*store.Transaction{
+		{Type: store.CaptureTransaction, Currency: "USD", Postings: []*store.Posting{
+			{Account: store.ProviderClearing, Debit: 60},
+			{Account: store.CustomerPayments, Credit: 60},
+		}},
+		{Type: store.RefundTransaction, Currency: "USD", Postings: []*store.Posting{
+			{Account: store.CustomerPayments, Debit: 20},
+			{Account: store.ProviderClearing, Credit: 20},
+		}},
+		{Type: store.ChargeTransaction, Currency: "EUR", Postings: []*store.Posting{
+			{Account: store.ProviderClearing, Debit: 30},
+			{Account: store.CustomerPayments, Credit: 30},
+		}},
+		{Type: store.AuthorizationTransaction, Currency: "EUR"},
+	}, nil)
+
+	balances, err := recorder.Balances()
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(balances).To(Equal([]*ledger.Balance{
+		{Account: store.CustomerPayments, Currency: "EUR", Credit: 30},
+		{Account: store.CustomerPayments, Currency: "USD", Debit: 20, Credit: 60},
+		{Account: store.ProviderClearing, Currency: "EUR", Debit: 30},
+		{Account: store.ProviderClearing, Currency: "USD", Debit: 60, Credit: 20},
+	}))
+}
@@ -0,0 +1,193 @@
+package ledger
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	internalStore "github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/payments/store"
+)
+
+// settlementExtension is the extension of the settlement files picked up from the settlements directory
+const settlementExtension = ".csv"
+
+// Reconcile compares a settlement file of the provider with the ledger and stores the report under the given ID,
+// replacing any previous report with the same ID. The settled transactions are matched by the ID the provider gave
+// them, and the ones settled for another amount, currency or type are reported as mismatched. The charges, captures
+// and refunds of the ledger made on the days the file covers, but missing from it, are reported as well
+func (l *Ledger) Reconcile(reportID string, r io.Reader) (*store.Report, error) {
+	settlements, err := ReadSettlements(r)
+	if err != nil {
+		return nil, err
+	}
+	l.Lock()
+	defer l.Unlock()
+	transactions, err := l.storage.GetTransactions()
+	if err != nil {
+		return nil, err
+	}
+	report := reconcile(settlements, transactions)
+	report.ID = reportID
+	report.CreatedAt = l.now()
+	if err := l.storage.SetReport(report); err != nil {
+		return nil, err
+	}
+	l.log.Infow("settlement reconciled", "report", report.ID, "matched", report.Matched, "discrepancies", report.Discrepancies)
+	return report, nil
+}
+
+// GetReport returns the reconciliation report with the given ID
+func (l *Ledger) GetReport(reportID string) (*store.Report, error) {
+	return l.storage.GetReport(reportID)
+}
+
+// GetReports returns all the reconciliation reports, the latest first
+func (l *Ledger) GetReports() ([]*store.Report, error) {
+	reports, err := l.storage.GetReports()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].CreatedAt.After(reports[j].CreatedAt)
+	})
+	return reports, nil
+}
+
+// Run starts a go routine that reconciles the settlement files dropped in the directory at every interval,
+// until the context is done
+func (l *Ledger) Run(ctx context.Context, directory string, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := l.Scan(directory); err != nil {
+					l.log.Errorw("could not reconcile settlement files", "directory", directory, "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Scan reconciles the CSV files of the directory that have no report yet. The reports are named after the files,
+// without their extension. Files that cannot be read are skipped and tried again at the next scan
+func (l *Ledger) Scan(directory string) error {
+	files, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != settlementExtension {
+			continue
+		}
+		reportID := strings.TrimSuffix(file.Name(), settlementExtension)
+		_, err := l.storage.GetReport(reportID)
+		if err == nil {
+			continue
+		}
+		if !internalStore.IsNotFoundError(err) {
+			return err
+		}
+		if err := l.reconcileFile(reportID, filepath.Join(directory, file.Name())); err != nil {
+			if IsInvalidSettlementError(err) {
+				l.log.Errorw("could not read settlement file", "file", file.Name(), "error", err)
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Ledger) reconcileFile(reportID string, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = l.Reconcile(reportID, file)
+	return err
+}
+
+// reconcile matches the settled transactions with the succeeded charges, captures and refunds of the ledger
+func reconcile(settlements []*Settlement, transactions []*store.Transaction) *store.Report {
+	recorded := map[string]*store.Transaction{}
+	for _, transaction := range transactions {
+		if transaction.Status == store.TransactionSucceeded && transaction.Type.MovesMoney() && transaction.ProviderID != "" {
+			recorded[transaction.ProviderID] = transaction
+		}
+	}
+
+	report := &store.Report{Days: []*store.Day{}}
+	days := map[string]*store.Day{}
+	day := func(date string) *store.Day {
+		if _, ok := days[date]; !ok {
+			days[date] = &store.Day{Date: date}
+			report.Days = append(report.Days, days[date])
+		}
+		return days[date]
+	}
+	discrepancy := func(date string, discrepancy *store.Discrepancy) {
+		day(date).Discrepancies = append(day(date).Discrepancies, discrepancy)
+		report.Discrepancies++
+	}
+
+	first, last := "", ""
+	for _, settlement := range settlements {
+		if first == "" || settlement.Date < first {
+			first = settlement.Date
+		}
+		if settlement.Date > last {
+			last = settlement.Date
+		}
+		settled := &store.Settled{Type: settlement.Type, Amount: settlement.Amount, Currency: settlement.Currency}
+		transaction, ok := recorded[settlement.ProviderID]
+		if !ok {
+			discrepancy(settlement.Date, &store.Discrepancy{Type: store.MissingFromLedger, ProviderID: settlement.ProviderID, Settlement: settled})
+			continue
+		}
+		delete(recorded, settlement.ProviderID)
+		if transaction.Type != settlement.Type || transaction.Amount != settlement.Amount || transaction.Currency != settlement.Currency {
+			discrepancy(settlement.Date, &store.Discrepancy{
+				Type:       store.AmountMismatch,
+				ProviderID: settlement.ProviderID,
+				Ledger:     settledFrom(transaction),
+				Settlement: settled,
+			})
+			continue
+		}
+		day(settlement.Date).Matched++
+		report.Matched++
+	}
+
+	unsettled := make([]*store.Transaction, 0, len(recorded))
+	for _, transaction := range recorded {
+		unsettled = append(unsettled, transaction)
+	}
+	sortTransactions(unsettled)
+	for _, transaction := range unsettled {
+		date := transaction.CreatedAt.UTC().Format(dateLayout)
+		if date < first || date > last {
+			continue
+		}
+		discrepancy(date, &store.Discrepancy{Type: store.MissingFromSettlement, ProviderID: transaction.ProviderID, Ledger: settledFrom(transaction)})
+	}
+
+	sort.Slice(report.Days, func(i, j int) bool {
+		return report.Days[i].Date < report.Days[j].Date
+	})
+	return report
+}
+
+func settledFrom(transaction *store.Transaction) *store.Settled {
+	return &store.Settled{Type: transaction.Type, Amount: transaction.Amount, Currency: transaction.Currency}
+}
@@ -0,0 +1,164 @@
+package ledger_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/money"
+	internalStore "github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/payments/ledger"
+	"github.com/mimatache/go-shop/pkg/payments/store"
+)
+
+const settlementFile = `date,id,type,amount,currency
+2026-10-17,capture-1,capture,60,USD
+2026-10-17,refund-1,refund,20,USD
+2026-10-18,capture-2,capture,45,EUR
+2026-10-18,unknown,capture,10,EUR
+`
+
+func recorded(providerID string, transactionType store.TransactionType, amount uint, currency string, at string) *store.Transaction {
+	createdAt, _ := time.Parse(time.RFC3339, at)
+	return &store.Transaction{
+		ID:         "transaction-" + providerID,
+		Type:       transactionType,
+		Status:     store.TransactionSucceeded,
+		ProviderID: providerID,
+		Amount:     amount,
+		Currency:   money.Currency(currency),
+		CreatedAt:  createdAt,
+	}
+}
+
+func TestReadSettlements(t *testing.T) {
+	g := NewWithT(t)
+
+	settlements, err := ledger.ReadSettlements(strings.NewReader("currency, Amount,type,id,date\neur,1205,CAPTURE,capture-1,2026-10-17\n"))
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(settlements).To(Equal([]*ledger.Settlement{
+		{Date: "2026-10-17", ProviderID: "capture-1", Type: store.CaptureTransaction, Amount: 1205, Currency: "EUR"},
+	}))
+}
+
+func TestReadSettlements_Invalid(t *testing.T) {
+	files := map[string]string{
+		"empty":             "",
+		"missing column":    "date,id,type,amount\n",
+		"bad date":          "date,id,type,amount,currency\n17/10/2026,capture-1,capture,60,USD\n",
+		"authorization":     "date,id,type,amount,currency\n2026-10-17,auth,authorization,60,USD\n",
+		"decimal amount":    "date,id,type,amount,currency\n2026-10-17,capture-1,capture,0.60,USD\n",
+		"unknown currency":  "date,id,type,amount,currency\n2026-10-17,capture-1,capture,60,XYZ\n",
+		"missing field":     "date,id,type,amount,currency\n2026-10-17,capture-1,capture,60\n",
+		"settled twice":     "date,id,type,amount,currency\n2026-10-17,capture-1,capture,60,USD\n2026-10-18,capture-1,capture,60,USD\n",
+		"empty transaction": "date,id,type,amount,currency\n2026-10-17,,capture,60,USD\n",
+	}
+	for name, file := range files {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			_, err := ledger.ReadSettlements(strings.NewReader(file))
+
+			g.Expect(ledger.IsInvalidSettlementError(err)).To(BeTrue(), "%v", err)
+		})
+	}
+}
+
+func TestLedger_Reconcile(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder, m, finish := newLedger(t)
+	defer finish()
+
+	failed := recorded("capture-failed", store.CaptureTransaction, 30, "USD", "2026-10-18T10:00:00Z")
+	failed.Status = store.TransactionFailed
+	m.storage.EXPECT().GetTransactions().Return([]*store.Transaction{
+		recorded("capture-1", store.CaptureTransaction, 60, "USD", "2026-10-17T10:00:00Z"),
+		recorded("refund-1", store.RefundTransaction, 25, "USD", "2026-10-17T11:00:00Z"),
+		recorded("capture-2", store.CaptureTransaction, 45, "EUR", "2026-10-18T09:00:00Z"),
+		recorded("capture-3", store.CaptureTransaction, 15, "USD", "2026-10-18T12:00:00Z"),
+		recorded("capture-later", store.CaptureTransaction, 15, "USD", "2026-10-19T12:00:00Z"),
+		failed,
+	}, nil)
+	m.storage.EXPECT().SetReport(gomock.Any()).Return(nil)
+
+	report, err := recorder.Reconcile("2026-10-18", strings.NewReader(settlementFile))
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(report.ID).To(Equal("2026-10-18"))
+	g.Expect(report.Matched).To(Equal(uint(2)))
+	g.Expect(report.Discrepancies).To(Equal(uint(3)))
+	g.Expect(report.Days).To(Equal([]*store.Day{
+		{
+			Date:    "2026-10-17",
+			Matched: 1,
+			Discrepancies: []*store.Discrepancy{{
+				Type:       store.AmountMismatch,
+				ProviderID: "refund-1",
+				Ledger:     &store.Settled{Type: store.RefundTransaction, Amount: 25, Currency: "USD"},
+				Settlement: &store.Settled{Type: store.RefundTransaction, Amount: 20, Currency: "USD"},
+			}},
+		},
+		{
+			Date:    "2026-10-18",
+			Matched: 1,
+			Discrepancies: []*store.Discrepancy{
+				{
+					Type:       store.MissingFromLedger,
+					ProviderID: "unknown",
+					Settlement: &store.Settled{Type: store.CaptureTransaction, Amount: 10, Currency: "EUR"},
+				},
+				{
+					Type:       store.MissingFromSettlement,
+					ProviderID: "capture-3",
+					Ledger:     &store.Settled{Type: store.CaptureTransaction, Amount: 15, Currency: "USD"},
+				},
+			},
+		},
+	}))
+}
+
+func TestLedger_Reconcile_InvalidFile(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder, _, finish := newLedger(t)
+	defer finish()
+
+	_, err := recorder.Reconcile("2026-10-18", strings.NewReader("date,id\n"))
+
+	g.Expect(ledger.IsInvalidSettlementError(err)).To(BeTrue())
+}
+
+func TestLedger_Scan(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder, m, finish := newLedger(t)
+	defer finish()
+
+	directory, err := ioutil.TempDir("", "settlements")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	defer os.RemoveAll(directory)
+	g.Expect(ioutil.WriteFile(filepath.Join(directory, "2026-10-17.csv"), []byte(settlementFile), 0600)).To(Succeed())
+	g.Expect(ioutil.WriteFile(filepath.Join(directory, "2026-10-18.csv"), []byte(settlementFile), 0600)).To(Succeed())
+	g.Expect(ioutil.WriteFile(filepath.Join(directory, "broken.csv"), []byte("date\n"), 0600)).To(Succeed())
+	g.Expect(ioutil.WriteFile(filepath.Join(directory, "notes.txt"), []byte("not a settlement"), 0600)).To(Succeed())
+
+	m.storage.EXPECT().GetReport("2026-10-17").Return(&store.Report{ID: "2026-10-17"}, nil)
+	m.storage.EXPECT().GetReport("2026-10-18").Return(nil, internalStore.NewNotFoundError("reconciliationReport", "id", "2026-10-18"))
+	m.storage.EXPECT().GetReport("broken").Return(nil, internalStore.NewNotFoundError("reconciliationReport", "id", "broken"))
+	m.storage.EXPECT().GetTransactions().Return(nil, nil)
+	m.storage.EXPECT().SetReport(gomock.Any()).DoAndReturn(func(report *store.Report) error {
+		g.Expect(report.ID).To(Equal("2026-10-18"))
+		g.Expect(report.Discrepancies).To(Equal(uint(4)))
+		return nil
+	})
+
+	g.Expect(recorder.Scan(directory)).To(Succeed())
+}
@@ -0,0 +1,133 @@
+package ledger
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mimatache/go-shop/internal/money"
+	"github.com/mimatache/go-shop/pkg/payments/store"
+)
+
+// dateLayout is the layout of the dates in the settlement files and reports
+const dateLayout = "2006-01-02"
+
+// settlementColumns are the columns every settlement file has, in any order
+var settlementColumns = []string{"date", "id", "type", "amount", "currency"}
+
+type invalidSettlement struct {
+	line   int
+	reason string
+}
+
+func (i invalidSettlement) Error() string {
+	if i.line == 0 {
+		return fmt.Sprintf("invalid settlement file: %s", i.reason)
+	}
+	return fmt.Sprintf("invalid settlement file, line %d: %s", i.line, i.reason)
+}
+
+// IsInvalidSettlementError verifies if a given error refers to a settlement file that cannot be read
+func IsInvalidSettlementError(err error) bool {
+	switch err.(type) {
+	case invalidSettlement:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewInvalidSettlement creates a new invalid settlement error for the given line of the file
+func NewInvalidSettlement(line int, reason string) error {
+	return invalidSettlement{line: line, reason: reason}
+}
+
+// Settlement is a transaction the provider settled
+type Settlement struct {
+	Date       string
+	ProviderID string
+	Type       store.TransactionType
+	Amount     uint
+	Currency   money.Currency
+}
+
+// ReadSettlements reads a settlement file of the provider. The file is a CSV whose header names the date, id, type,
+// amount and currency columns. Dates are given as YYYY-MM-DD, types are charge, capture or refund and amounts
+// are given in the minor unit of the currency
+func ReadSettlements(r io.Reader) ([]*Settlement, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, NewInvalidSettlement(0, "the file is empty")
+	}
+	if err != nil {
+		return nil, NewInvalidSettlement(0, err.Error())
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range settlementColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, NewInvalidSettlement(1, fmt.Sprintf("missing column %s", name))
+		}
+	}
+
+	settlements := []*Settlement{}
+	seen := map[string]bool{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return settlements, nil
+		}
+		if err != nil {
+			return nil, NewInvalidSettlement(line, err.Error())
+		}
+		settlement, err := parseSettlement(record, columns)
+		if err != nil {
+			return nil, NewInvalidSettlement(line, err.Error())
+		}
+		if seen[settlement.ProviderID] {
+			return nil, NewInvalidSettlement(line, fmt.Sprintf("transaction %s is settled twice", settlement.ProviderID))
+		}
+		seen[settlement.ProviderID] = true
+		settlements = append(settlements, settlement)
+	}
+}
+
+func parseSettlement(record []string, columns map[string]int) (*Settlement, error) {
+	field := func(name string) string {
+		return strings.TrimSpace(record[columns[name]])
+	}
+	date, err := time.Parse(dateLayout, field("date"))
+	if err != nil {
+		return nil, fmt.Errorf("date %q is not formatted as YYYY-MM-DD", field("date"))
+	}
+	providerID := field("id")
+	if providerID == "" {
+		return nil, fmt.Errorf("id cannot be empty")
+	}
+	transactionType := store.TransactionType(strings.ToLower(field("type")))
+	if !transactionType.MovesMoney() {
+		return nil, fmt.Errorf("type %q is not one of charge, capture or refund", field("type"))
+	}
+	amount, err := strconv.ParseUint(field("amount"), 10, 0)
+	if err != nil {
+		return nil, fmt.Errorf("amount %q is not a whole number of minor units", field("amount"))
+	}
+	currency, err := money.ParseCurrency(field("currency"))
+	if err != nil {
+		return nil, err
+	}
+	return &Settlement{
+		Date:       date.Format(dateLayout),
+		ProviderID: providerID,
+		Type:       transactionType,
+		Amount:     uint(amount),
+		Currency:   currency,
+	}, nil
+}
@@ -1,50 +1,109 @@
 package payments
 
 import (
-	"crypto/rand"
-	"encoding/json"
 	"fmt"
-	"net/http"
+	"io"
+	netHTTP "net/http"
 	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mimatache/go-shop/internal/http/health"
+	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/internal/rbac"
+	"github.com/mimatache/go-shop/pkg/payments/http"
+	"github.com/mimatache/go-shop/pkg/payments/ledger"
+	"github.com/mimatache/go-shop/pkg/payments/processor"
+	"github.com/mimatache/go-shop/pkg/payments/provider"
+	"github.com/mimatache/go-shop/pkg/payments/store"
+	"github.com/mimatache/go-shop/pkg/payments/webhook"
 )
 
-type Luke struct {
-	Name string `json:"name"`
-}
+const (
+	// FakeProvider is a local provider with scripted outcomes
+	FakeProvider = "fake"
+	// HTTPProvider forwards the payments to a remote provider
+	HTTPProvider = "http"
+)
 
-func New() *API {
-	return &API{
-		client: &http.Client{Timeout: 2 * time.Second},
+// NewAPI instantiates the payments API on top of the given provider. The calls to the provider are bounded and retried
+// as configured by resilience, and the state of the provider is reported through the health probes.
+// Every call is recorded in the returned ledger, which reconciles the settlement files of the provider
+func NewAPI(
+	log logger.Logger,
+	name string,
+	config io.Reader,
+	resilience provider.ResilienceConfig,
+	db store.UnderlyingStore,
+	router *mux.Router,
+	adminHandler func(permission rbac.Permission) func(netHTTP.Handler) netHTTP.Handler,
+	probes *health.Check,
+) (*processor.Processor, *ledger.Ledger, error) {
+	paymentProvider, err := NewProvider(log, name, config)
+	if err != nil {
+		return nil, nil, err
 	}
+	resilient := provider.NewResilient(paymentProvider, resilience)
+	probes.RegisterReadynessCondition(providerCondition(resilient))
+	probes.RegisterMetrics("payments", func() interface{} {
+		return resilient.Metrics()
+	})
+	// the calls are recorded once, whatever number of attempts the resilient provider made
+	paymentLedger := ledger.New(log, resilient, store.NewLedgerStore(log, db))
+	payments := processor.New(log, paymentLedger, store.New(log, db))
+	paymentsAPI := http.New(payments, paymentLedger)
+	paymentsAPI.AddRoutes(router, adminHandler)
+	return payments, paymentLedger, nil
 }
 
-type API struct {
-	client *http.Client
+// NewWebhookAPI instantiates the receiver of the events signed by the payment provider with the given secret.
+// The events are rejected when no secret is given
+func NewWebhookAPI(
+	log logger.Logger,
+	secret string,
+	tolerance time.Duration,
+	payments webhook.PaymentsAPI,
+	orders webhook.OrdersAPI,
+	db store.UnderlyingStore,
+	router *mux.Router,
+) *webhook.Receiver {
+	receiver := webhook.NewReceiver(log, store.New(log, db), payments, orders, webhook.Config{Secret: secret, Tolerance: tolerance})
+	webhookAPI := http.NewWebhookAPI(receiver, secret != "")
+	webhookAPI.AddRoutes(router)
+	return receiver
 }
 
-// MakePayment randomly tells you that you can't pay for things
-func (a *API) MakePayment(user string, money uint) error {
-	time.Sleep(2 * time.Second)
-	r, err := a.client.Get("https://swapi.dev/api/people/1/")
-	if err != nil {
-		return err
-	}
-	luke := &Luke{}
-	defer r.Body.Close()
-	if err := json.NewDecoder(r.Body).Decode(luke); err != nil {
-		luke.Name = "Darth Vader"
+// NewProvider instantiates the payment provider with the given name
+func NewProvider(log logger.Logger, name string, config io.Reader) (provider.Provider, error) {
+	switch name {
+	case FakeProvider:
+		fakeConfig, err := provider.LoadFakeConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("Using fake payment provider with %d rules and %dms latency", len(fakeConfig.Rules), fakeConfig.LatencyMs)
+		return provider.NewFake(fakeConfig), nil
+	case HTTPProvider:
+		httpConfig, err := provider.LoadHTTPConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("Using payment provider at %s", httpConfig.URL)
+		// the calls are bounded by the contexts given by the resilient provider
+		return provider.NewHTTP(httpConfig, &netHTTP.Client{}), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %s", name)
 	}
-	if (randomNumber() % 2) == 0 {
-		return fmt.Errorf("request blocked by %s. don't spend %d", luke.Name, money)
-	}
-	return nil
 }
 
-func randomNumber() int {
-	b := make([]byte, 1)
-	_, err := rand.Read(b)
-	if err != nil {
-		return 1
+// providerCondition reports the payment provider as not ready while its circuit breaker is open
+func providerCondition(resilient *provider.Resilient) health.ConditionCheck {
+	return func() health.Condition {
+		state := resilient.State()
+		return health.Condition{
+			Ready:   state != provider.Open,
+			Message: fmt.Sprintf("circuit breaker is %s", state),
+			Name:    "payment provider",
+		}
 	}
-	return int(b[0])
 }
@@ -0,0 +1,61 @@
+package processor
+
+import (
+	"context"
+	"time"
+)
+
+// Run starts a go routine that expires the outdated authorizations at every interval, until the context is done
+func (p *Processor) Run(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := p.Scan(ctx); err != nil {
+					p.log.Errorw("could not expire payment authorizations", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Scan marks the payments whose authorization expired before being fully captured as expired.
+// The provider drops expired authorizations on its own, but it is still asked to release them,
+// in case its clock is behind
+func (p *Processor) Scan(ctx context.Context) error {
+	payments, err := p.storage.GetPayments()
+	if err != nil {
+		return err
+	}
+	now := p.now()
+	for _, found := range payments {
+		if !found.Status.IsOpen() || now.Before(found.ExpiresAt) {
+			continue
+		}
+		if err := p.expireStale(ctx, found.ID, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expireStale releases and expires the authorization of the payment, unless it changed since it was found
+func (p *Processor) expireStale(ctx context.Context, paymentID string, now time.Time) error {
+	defer p.lock(paymentID)()
+	stored, err := p.storage.GetPayment(paymentID)
+	if err != nil {
+		return err
+	}
+	if !stored.Status.IsOpen() || now.Before(stored.ExpiresAt) {
+		return nil
+	}
+	payment := stored.Copy()
+	if err := p.provider.Void(ctx, payment.AuthorizationID); err != nil {
+		p.log.Infow("could not release expired authorization", "payment", payment.ID, "error", err)
+	}
+	return p.expire(payment)
+}
@@ -0,0 +1,394 @@
+package processor
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mimatache/go-shop/internal/money"
+	"github.com/mimatache/go-shop/pkg/payments/provider"
+	"github.com/mimatache/go-shop/pkg/payments/store"
+)
+
+const referenceBytes = 16
+
+type logger interface {
+	Infow(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+}
+
+type invalidPayment struct {
+	paymentID string
+	reason    string
+}
+
+func (i invalidPayment) Error() string {
+	return fmt.Sprintf("payment %s: %s", i.paymentID, i.reason)
+}
+
+// IsInvalidPaymentError verifies if a given error refers to an operation the payment does not allow in its current state
+func IsInvalidPaymentError(err error) bool {
+	switch err.(type) {
+	case invalidPayment:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewInvalidPayment creates a new invalid payment error
+func NewInvalidPayment(paymentID string, reason string) error {
+	return invalidPayment{paymentID: paymentID, reason: reason}
+}
+
+// New creates the payment processor
+func New(log logger, paymentProvider provider.Provider, storage store.PaymentStore) *Processor {
+	return &Processor{
+		log:      log,
+		provider: paymentProvider,
+		storage:  storage,
+		now:      time.Now,
+		locks:    map[string]*paymentLock{},
+	}
+}
+
+// Processor takes payments in two steps: the money is authorized when the order is placed
+// and captured, possibly in several parts, when the items are shipped. Every payment is recorded,
+// together with its captures. The operations on a payment wait for each other, but not for the ones on other payments,
+// so that a slow provider only holds up the payment it is working on
+type Processor struct {
+	log      logger
+	provider provider.Provider
+	storage  store.PaymentStore
+	now      func() time.Time
+	// locks holds a lock for every payment with operations in progress
+	locks map[string]*paymentLock
+	sync.Mutex
+}
+
+// paymentLock is held during an operation on a payment. It is dropped once no operation uses it anymore
+type paymentLock struct {
+	users int
+	sync.Mutex
+}
+
+// Authorize reserves the amount from the user and records the payment
+func (p *Processor) Authorize(ctx context.Context, userID string, amount money.Money) (*store.Payment, error) {
+	reference, err := newReference()
+	if err != nil {
+		return nil, err
+	}
+	authorization, err := p.provider.Authorize(ctx, &provider.ChargeRequest{
+		Reference: reference,
+		UserID:    userID,
+		Amount:    amount.Amount,
+		Currency:  amount.Currency,
+	})
+	if err != nil {
+		if provider.IsDeclinedError(err) {
+			p.log.Infow("payment declined", "user", userID, "amount", amount.String(), "code", provider.GetDeclineCode(err))
+			return nil, err
+		}
+		p.log.Errorw("payment authorization failed", "user", userID, "amount", amount.String(), "error", err)
+		return nil, err
+	}
+	now := p.now()
+	status := store.Authorized
+	if authorization.Status == provider.Pending {
+		status = store.Pending
+	}
+	payment := &store.Payment{
+		ID:              reference,
+		UserID:          userID,
+		AuthorizationID: authorization.ID,
+		Status:          status,
+		Authorized:      amount,
+		Captured:        money.New(0, amount.Currency),
+		Refunded:        money.New(0, amount.Currency),
+		ExpiresAt:       authorization.ExpiresAt,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := p.storage.SetPayment(payment); err != nil {
+		// the authorization is released even when the caller gave up
+		if voidErr := p.provider.Void(context.Background(), authorization.ID); voidErr != nil {
+			p.log.Errorw("could not release unrecorded authorization", "authorization", authorization.ID, "error", voidErr)
+		}
+		return nil, err
+	}
+	p.log.Infow("payment authorized", "user", userID, "amount", amount.String(), "payment", payment.ID, "status", payment.Status)
+	return payment, nil
+}
+
+// Confirm marks the payment of a pending authorization as authorized, once the provider confirms it.
+// The expiry is updated when given
+func (p *Processor) Confirm(authorizationID string, expiresAt time.Time) (*store.Payment, error) {
+	return p.advance(authorizationID, func(payment *store.Payment) (bool, error) {
+		switch payment.Status {
+		case store.Pending:
+			payment.Status = store.Authorized
+			if !expiresAt.IsZero() {
+				payment.ExpiresAt = expiresAt
+			}
+			return true, nil
+		case store.Failed:
+			return false, NewInvalidPayment(payment.ID, "the authorization already failed")
+		default:
+			return false, nil
+		}
+	})
+}
+
+// Fail marks the payment of a pending authorization as failed, once the provider refuses it
+func (p *Processor) Fail(authorizationID string) (*store.Payment, error) {
+	return p.advance(authorizationID, func(payment *store.Payment) (bool, error) {
+		switch payment.Status {
+		case store.Pending:
+			payment.Status = store.Failed
+			return true, nil
+		case store.Failed:
+			return false, nil
+		default:
+			return false, NewInvalidPayment(payment.ID, fmt.Sprintf("cannot fail a payment that is %s", payment.Status))
+		}
+	})
+}
+
+// Expire marks the payment as expired, once the provider drops its authorization
+func (p *Processor) Expire(authorizationID string) (*store.Payment, error) {
+	return p.advance(authorizationID, func(payment *store.Payment) (bool, error) {
+		if payment.Status.IsOpen() || payment.Status == store.Pending {
+			payment.Status = store.Expired
+			return true, nil
+		}
+		return false, nil
+	})
+}
+
+// advance applies a change announced by the provider to the payment of the authorization.
+// The change reports whether it altered the payment, so that repeated announcements are ignored
+func (p *Processor) advance(authorizationID string, change func(payment *store.Payment) (bool, error)) (*store.Payment, error) {
+	found, err := p.storage.GetPaymentByAuthorization(authorizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer p.lock(found.ID)()
+	// the payment is read again, as it may have changed while waiting for the lock
+	stored, err := p.storage.GetPayment(found.ID)
+	if err != nil {
+		return nil, err
+	}
+	payment := stored.Copy()
+	changed, err := change(payment)
+	if err != nil || !changed {
+		return stored, err
+	}
+	payment.UpdatedAt = p.now()
+	if err := p.storage.SetPayment(payment); err != nil {
+		return nil, err
+	}
+	p.log.Infow("payment updated by the provider", "payment", payment.ID, "status", payment.Status)
+	return payment, nil
+}
+
+// Capture takes the amount from the authorization of the payment. A final capture releases whatever is left
+// of the authorization, so nothing more can be captured afterwards
+func (p *Processor) Capture(ctx context.Context, paymentID string, amount uint, final bool) (*store.Payment, error) {
+	defer p.lock(paymentID)()
+	payment, err := p.open(paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if amount == 0 {
+		if final {
+			return p.void(ctx, payment)
+		}
+		return nil, NewInvalidPayment(paymentID, "the captured amount must be greater than 0")
+	}
+	remaining, err := payment.Remaining()
+	if err != nil {
+		return nil, err
+	}
+	captured := money.New(amount, payment.Currency())
+	if amount > remaining.Amount {
+		return nil, NewInvalidPayment(paymentID, fmt.Sprintf("cannot capture %s, only %s left", captured, remaining))
+	}
+	total, err := payment.Captured.Add(captured)
+	if err != nil {
+		return nil, err
+	}
+	capture, err := p.provider.Capture(ctx, &provider.CaptureRequest{
+		AuthorizationID: payment.AuthorizationID,
+		Reference:       fmt.Sprintf("%s-%d", payment.ID, len(payment.Captures)+1),
+		Amount:          amount,
+		Final:           final,
+	})
+	if err != nil {
+		p.log.Errorw("payment capture failed", "payment", paymentID, "amount", amount, "error", err)
+		return nil, err
+	}
+	now := p.now()
+	payment.Captures = append(payment.Captures, &store.Capture{ID: capture.ID, Amount: captured, CapturedAt: now})
+	payment.Captured = total
+	payment.Status = store.PartiallyCaptured
+	if final || payment.Captured == payment.Authorized {
+		payment.Status = store.Captured
+	}
+	payment.UpdatedAt = now
+	if err := p.storage.SetPayment(payment); err != nil {
+		return nil, err
+	}
+	p.log.Infow("payment captured", "payment", paymentID, "amount", captured.String(), "captured", payment.Captured.String())
+	return payment, nil
+}
+
+// Void releases what is left of the authorization of the payment. Payments with nothing captured are voided,
+// while the ones partially captured are complete. Pending authorizations can be voided before being confirmed
+func (p *Processor) Void(ctx context.Context, paymentID string) (*store.Payment, error) {
+	defer p.lock(paymentID)()
+	stored, err := p.storage.GetPayment(paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if stored.Status == store.Pending {
+		return p.void(ctx, stored.Copy())
+	}
+	payment, err := p.open(paymentID)
+	if err != nil {
+		return nil, err
+	}
+	return p.void(ctx, payment)
+}
+
+// Refund gives back some of the money captured by the payment. The refunds of a payment never exceed what was captured
+func (p *Processor) Refund(ctx context.Context, paymentID string, amount uint, reason provider.RefundReason) (*store.Payment, error) {
+	defer p.lock(paymentID)()
+	stored, err := p.storage.GetPayment(paymentID)
+	if err != nil {
+		return nil, err
+	}
+	payment := stored.Copy()
+	if !reason.IsKnown() {
+		return nil, NewInvalidPayment(paymentID, fmt.Sprintf("unknown refund reason %s", reason))
+	}
+	if amount == 0 {
+		return nil, NewInvalidPayment(paymentID, "the refunded amount must be greater than 0")
+	}
+	refundable, err := payment.Refundable()
+	if err != nil {
+		return nil, err
+	}
+	refunded := money.New(amount, payment.Currency())
+	if amount > refundable.Amount {
+		return nil, NewInvalidPayment(paymentID, fmt.Sprintf("cannot refund %s, only %s captured and not refunded", refunded, refundable))
+	}
+	total, err := payment.Refunded.Add(refunded)
+	if err != nil {
+		return nil, err
+	}
+	refund, err := p.provider.Refund(ctx, &provider.RefundRequest{
+		AuthorizationID: payment.AuthorizationID,
+		Reference:       fmt.Sprintf("%s-r%d", payment.ID, len(payment.Refunds)+1),
+		Amount:          amount,
+		Reason:          reason,
+	})
+	if err != nil {
+		p.log.Errorw("payment refund failed", "payment", paymentID, "amount", amount, "error", err)
+		return nil, err
+	}
+	now := p.now()
+	payment.Refunds = append(payment.Refunds, &store.Refund{ID: refund.ID, Amount: refunded, Reason: reason, RefundedAt: now})
+	payment.Refunded = total
+	payment.UpdatedAt = now
+	if err := p.storage.SetPayment(payment); err != nil {
+		return nil, err
+	}
+	p.log.Infow("payment refunded", "payment", paymentID, "amount", refunded.String(), "reason", reason, "refunded", payment.Refunded.String())
+	return payment, nil
+}
+
+// GetPayment returns the record of a payment
+func (p *Processor) GetPayment(paymentID string) (*store.Payment, error) {
+	return p.storage.GetPayment(paymentID)
+}
+
+func (p *Processor) void(ctx context.Context, payment *store.Payment) (*store.Payment, error) {
+	err := p.provider.Void(ctx, payment.AuthorizationID)
+	if err != nil {
+		p.log.Errorw("payment void failed", "payment", payment.ID, "error", err)
+		return nil, err
+	}
+	payment.Status = store.Voided
+	if payment.Captured.Amount > 0 {
+		payment.Status = store.Captured
+	}
+	payment.UpdatedAt = p.now()
+	if err := p.storage.SetPayment(payment); err != nil {
+		return nil, err
+	}
+	p.log.Infow("payment authorization released", "payment", payment.ID, "captured", payment.Captured.String())
+	return payment, nil
+}
+
+// open returns a copy of the payment, if its authorization can still be used.
+// Payments whose authorization expired are marked as such
+func (p *Processor) open(paymentID string) (*store.Payment, error) {
+	stored, err := p.storage.GetPayment(paymentID)
+	if err != nil {
+		return nil, err
+	}
+	payment := stored.Copy()
+	if !payment.Status.IsOpen() {
+		return nil, NewInvalidPayment(paymentID, fmt.Sprintf("the payment is %s", payment.Status))
+	}
+	if !p.now().Before(payment.ExpiresAt) {
+		if err := p.expire(payment); err != nil {
+			return nil, err
+		}
+		return nil, NewInvalidPayment(paymentID, "the authorization expired")
+	}
+	return payment, nil
+}
+
+func (p *Processor) expire(payment *store.Payment) error {
+	payment.Status = store.Expired
+	payment.UpdatedAt = p.now()
+	if err := p.storage.SetPayment(payment); err != nil {
+		return err
+	}
+	p.log.Infow("payment authorization expired", "payment", payment.ID, "authorized", payment.Authorized.String(), "captured", payment.Captured.String())
+	return nil
+}
+
+// lock waits for the operations in progress on the payment and returns the function that lets the next one in
+func (p *Processor) lock(paymentID string) func() {
+	p.Lock()
+	l, ok := p.locks[paymentID]
+	if !ok {
+		l = &paymentLock{}
+		p.locks[paymentID] = l
+	}
+	l.users++
+	p.Unlock()
+	l.Lock()
+	return func() {
+		l.Unlock()
+		p.Lock()
+		defer p.Unlock()
+		l.users--
+		if l.users == 0 {
+			delete(p.locks, paymentID)
+		}
+	}
+}
+
+func newReference() (string, error) {
+	b := make([]byte, referenceBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,412 @@
+package processor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/money"
+	"github.com/mimatache/go-shop/pkg/payments/processor"
+	"github.com/mimatache/go-shop/pkg/payments/provider"
+	mock_provider "github.com/mimatache/go-shop/pkg/payments/provider/mocks"
+	"github.com/mimatache/go-shop/pkg/payments/store"
+	mock_store "github.com/mimatache/go-shop/pkg/payments/store/mocks"
+)
+
+const (
+	userID          = "user@email.com"
+	paymentID       = "payment"
+	authorizationID = "auth"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Infow(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Errorw(msg string, keysAndValues ...interface{}) {}
+
+type mocks struct {
+	provider *mock_provider.MockProvider
+	storage  *mock_store.MockPaymentStore
+}
+
+func newProcessor(t *testing.T) (*processor.Processor, *mocks, func()) {
+	ctrl := gomock.NewController(t)
+	m := &mocks{
+		provider: mock_provider.NewMockProvider(ctrl),
+		storage:  mock_store.NewMockPaymentStore(ctrl),
+	}
+	return processor.New(nopLogger{}, m.provider, m.storage), m, ctrl.Finish
+}
+
+func newPayment(captured uint, status store.Status) *store.Payment {
+	return &store.Payment{
+		ID:              paymentID,
+		UserID:          userID,
+		AuthorizationID: authorizationID,
+		Status:          status,
+		Authorized:      money.New(100, "USD"),
+		Captured:        money.New(captured, "USD"),
+		Refunded:        money.New(0, "USD"),
+		ExpiresAt:       time.Now().Add(time.Hour),
+	}
+}
+
+// expectAuthorization expects the payment to be looked up by its authorization, then read again once it is locked
+func expectAuthorization(m *mocks, payment *store.Payment) {
+	m.storage.EXPECT().GetPaymentByAuthorization(authorizationID).Return(payment, nil)
+	m.storage.EXPECT().GetPayment(paymentID).Return(payment, nil)
+}
+
+func TestProcessor_Authorize(t *testing.T) {
+	g := NewWithT(t)
+
+	payments, m, finish := newProcessor(t)
+	defer finish()
+
+	expiresAt := time.Now().Add(time.Hour)
+	m.provider.EXPECT().Authorize(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, request *provider.ChargeRequest) (*provider.Authorization, error) {
+		g.Expect(request.Currency).To(Equal(money.Currency("USD")))
+		return &provider.Authorization{ID: authorizationID, Amount: 100, ExpiresAt: expiresAt}, nil
+	})
+	m.storage.EXPECT().SetPayment(gomock.Any()).Return(nil)
+
+	payment, err := payments.Authorize(context.Background(), userID, money.New(100, "USD"))
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(payment.Status).To(Equal(store.Authorized))
+	g.Expect(payment.AuthorizationID).To(Equal(authorizationID))
+	g.Expect(payment.ExpiresAt).To(Equal(expiresAt))
+	g.Expect(payment.Currency()).To(Equal(money.Currency("USD")))
+}
+
+func TestProcessor_Authorize_Declined(t *testing.T) {
+	g := NewWithT(t)
+
+	payments, m, finish := newProcessor(t)
+	defer finish()
+
+	m.provider.EXPECT().Authorize(gomock.Any(), gomock.Any()).Return(nil, provider.NewDeclined(provider.CardDeclined, ""))
+
+	_, err := payments.Authorize(context.Background(), userID, money.New(100, "USD"))
+
+	g.Expect(provider.IsDeclinedError(err)).To(BeTrue())
+}
+
+func TestProcessor_Capture(t *testing.T) {
+	tests := []struct {
+		name     string
+		captured uint
+		amount   uint
+		final    bool
+		status   store.Status
+	}{
+		{name: "partial", amount: 40, status: store.PartiallyCaptured},
+		{name: "final", amount: 40, final: true, status: store.Captured},
+		{name: "everything left", captured: 60, amount: 40, status: store.Captured},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			payments, m, finish := newProcessor(t)
+			defer finish()
+
+			status := store.Authorized
+			if test.captured > 0 {
+				status = store.PartiallyCaptured
+			}
+			m.storage.EXPECT().GetPayment(paymentID).Return(newPayment(test.captured, status), nil)
+			m.provider.EXPECT().
+				Capture(gomock.Any(), &provider.CaptureRequest{AuthorizationID: authorizationID, Reference: paymentID + "-1", Amount: test.amount, Final: test.final}).
+				Return(&provider.Capture{ID: "capture", Amount: test.amount}, nil)
+			m.storage.EXPECT().SetPayment(gomock.Any()).Return(nil)
+
+			payment, err := payments.Capture(context.Background(), paymentID, test.amount, test.final)
+
+			g.Expect(err).ShouldNot(HaveOccurred())
+			g.Expect(payment.Status).To(Equal(test.status))
+			g.Expect(payment.Captured).To(Equal(money.New(test.captured+test.amount, "USD")))
+			g.Expect(payment.Captures).To(HaveLen(1))
+		})
+	}
+}
+
+type contextKey struct{}
+
+func TestProcessor_Capture_PassesContext(t *testing.T) {
+	g := NewWithT(t)
+
+	payments, m, finish := newProcessor(t)
+	defer finish()
+
+	ctx := context.WithValue(context.Background(), contextKey{}, "request")
+	m.storage.EXPECT().GetPayment(paymentID).Return(newPayment(0, store.Authorized), nil)
+	m.provider.EXPECT().Capture(gomock.Any(), gomock.Any()).DoAndReturn(func(given context.Context, _ *provider.CaptureRequest) (*provider.Capture, error) {
+		g.Expect(given.Value(contextKey{})).To(Equal("request"))
+		return &provider.Capture{ID: "capture", Amount: 40}, nil
+	})
+	m.storage.EXPECT().SetPayment(gomock.Any()).Return(nil)
+
+	_, err := payments.Capture(ctx, paymentID, 40, false)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+}
+
+func TestProcessor_Capture_DoesNotHoldUpOtherPayments(t *testing.T) {
+	g := NewWithT(t)
+
+	payments, m, finish := newProcessor(t)
+	defer finish()
+
+	other := newPayment(0, store.Authorized)
+	other.ID = "other"
+	other.AuthorizationID = "other-auth"
+	release := make(chan struct{})
+	m.storage.EXPECT().GetPayment(paymentID).Return(newPayment(0, store.Authorized), nil)
+	m.storage.EXPECT().GetPayment(other.ID).Return(other, nil)
+	m.provider.EXPECT().Capture(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, request *provider.CaptureRequest) (*provider.Capture, error) {
+		if request.AuthorizationID == authorizationID {
+			<-release
+		}
+		return &provider.Capture{ID: "capture", Amount: request.Amount}, nil
+	}).Times(2)
+	m.storage.EXPECT().SetPayment(gomock.Any()).Return(nil).Times(2)
+
+	slow := make(chan error)
+	go func() {
+		_, err := payments.Capture(context.Background(), paymentID, 40, false)
+		slow <- err
+	}()
+
+	_, err := payments.Capture(context.Background(), other.ID, 40, false)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	close(release)
+	g.Expect(<-slow).ShouldNot(HaveOccurred())
+}
+
+func TestProcessor_Capture_MoreThanAuthorized(t *testing.T) {
+	g := NewWithT(t)
+
+	payments, m, finish := newProcessor(t)
+	defer finish()
+
+	m.storage.EXPECT().GetPayment(paymentID).Return(newPayment(60, store.PartiallyCaptured), nil)
+
+	_, err := payments.Capture(context.Background(), paymentID, 50, false)
+
+	g.Expect(processor.IsInvalidPaymentError(err)).To(BeTrue())
+}
+
+func TestProcessor_Capture_Expired(t *testing.T) {
+	g := NewWithT(t)
+
+	payments, m, finish := newProcessor(t)
+	defer finish()
+
+	expired := newPayment(0, store.Authorized)
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	m.storage.EXPECT().GetPayment(paymentID).Return(expired, nil)
+	m.storage.EXPECT().SetPayment(gomock.Any()).DoAndReturn(func(payment *store.Payment) error {
+		g.Expect(payment.Status).To(Equal(store.Expired))
+		return nil
+	})
+
+	_, err := payments.Capture(context.Background(), paymentID, 50, false)
+
+	g.Expect(processor.IsInvalidPaymentError(err)).To(BeTrue())
+}
+
+func TestProcessor_Void(t *testing.T) {
+	tests := []struct {
+		name     string
+		captured uint
+		status   store.Status
+	}{
+		{name: "nothing captured", status: store.Voided},
+		{name: "partially captured", captured: 30, status: store.Captured},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			payments, m, finish := newProcessor(t)
+			defer finish()
+
+			m.storage.EXPECT().GetPayment(paymentID).Return(newPayment(test.captured, store.PartiallyCaptured), nil).Times(2)
+			m.provider.EXPECT().Void(gomock.Any(), authorizationID).Return(nil)
+			m.storage.EXPECT().SetPayment(gomock.Any()).Return(nil)
+
+			payment, err := payments.Void(context.Background(), paymentID)
+
+			g.Expect(err).ShouldNot(HaveOccurred())
+			g.Expect(payment.Status).To(Equal(test.status))
+		})
+	}
+}
+
+func TestProcessor_Void_Closed(t *testing.T) {
+	g := NewWithT(t)
+
+	payments, m, finish := newProcessor(t)
+	defer finish()
+
+	m.storage.EXPECT().GetPayment(paymentID).Return(newPayment(100, store.Captured), nil).Times(2)
+
+	_, err := payments.Void(context.Background(), paymentID)
+
+	g.Expect(processor.IsInvalidPaymentError(err)).To(BeTrue())
+}
+
+func TestProcessor_Scan(t *testing.T) {
+	g := NewWithT(t)
+
+	payments, m, finish := newProcessor(t)
+	defer finish()
+
+	expired := newPayment(30, store.PartiallyCaptured)
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	m.storage.EXPECT().GetPayments().Return([]*store.Payment{
+		expired,
+		newPayment(0, store.Authorized),
+		newPayment(100, store.Captured),
+	}, nil)
+	m.storage.EXPECT().GetPayment(paymentID).Return(expired, nil)
+	m.provider.EXPECT().Void(gomock.Any(), authorizationID).Return(nil)
+	m.storage.EXPECT().SetPayment(gomock.Any()).DoAndReturn(func(payment *store.Payment) error {
+		g.Expect(payment.Status).To(Equal(store.Expired))
+		g.Expect(payment.Captured).To(Equal(money.New(30, "USD")))
+		return nil
+	})
+
+	err := payments.Scan(context.Background())
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+}
+
+func TestProcessor_Refund(t *testing.T) {
+	g := NewWithT(t)
+
+	payments, m, finish := newProcessor(t)
+	defer finish()
+
+	captured := newPayment(80, store.Captured)
+	captured.Refunded = money.New(30, "USD")
+	m.storage.EXPECT().GetPayment(paymentID).Return(captured, nil)
+	m.provider.EXPECT().
+		Refund(gomock.Any(), &provider.RefundRequest{AuthorizationID: authorizationID, Reference: paymentID + "-r1", Amount: 50, Reason: provider.Damaged}).
+		Return(&provider.Refund{ID: "refund", Amount: 50}, nil)
+	m.storage.EXPECT().SetPayment(gomock.Any()).Return(nil)
+
+	payment, err := payments.Refund(context.Background(), paymentID, 50, provider.Damaged)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(payment.Refunded).To(Equal(money.New(80, "USD")))
+	g.Expect(payment.Refundable()).To(Equal(money.New(0, "USD")))
+	g.Expect(payment.Refunds[0].ID).To(Equal("refund"))
+}
+
+func TestProcessor_Refund_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount uint
+		reason provider.RefundReason
+	}{
+		{name: "more than captured", amount: 51, reason: provider.Damaged},
+		{name: "nothing", reason: provider.Damaged},
+		{name: "unknown reason", amount: 10, reason: "bored"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			payments, m, finish := newProcessor(t)
+			defer finish()
+
+			captured := newPayment(80, store.Captured)
+			captured.Refunded = money.New(30, "USD")
+			m.storage.EXPECT().GetPayment(paymentID).Return(captured, nil)
+
+			_, err := payments.Refund(context.Background(), paymentID, test.amount, test.reason)
+
+			g.Expect(processor.IsInvalidPaymentError(err)).To(BeTrue())
+		})
+	}
+}
+
+func TestProcessor_Authorize_Pending(t *testing.T) {
+	g := NewWithT(t)
+
+	payments, m, finish := newProcessor(t)
+	defer finish()
+
+	m.provider.EXPECT().Authorize(gomock.Any(), gomock.Any()).Return(&provider.Authorization{ID: authorizationID, Amount: 100, Status: provider.Pending}, nil)
+	m.storage.EXPECT().SetPayment(gomock.Any()).Return(nil)
+
+	payment, err := payments.Authorize(context.Background(), userID, money.New(100, "USD"))
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(payment.Status).To(Equal(store.Pending))
+}
+
+func TestProcessor_Confirm(t *testing.T) {
+	g := NewWithT(t)
+
+	payments, m, finish := newProcessor(t)
+	defer finish()
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	expectAuthorization(m, newPayment(0, store.Pending))
+	m.storage.EXPECT().SetPayment(gomock.Any()).Return(nil)
+
+	payment, err := payments.Confirm(authorizationID, expiresAt)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(payment.Status).To(Equal(store.Authorized))
+	g.Expect(payment.ExpiresAt).To(Equal(expiresAt))
+}
+
+func TestProcessor_Confirm_Repeated(t *testing.T) {
+	g := NewWithT(t)
+
+	payments, m, finish := newProcessor(t)
+	defer finish()
+
+	expectAuthorization(m, newPayment(40, store.PartiallyCaptured))
+
+	payment, err := payments.Confirm(authorizationID, time.Time{})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(payment.Status).To(Equal(store.PartiallyCaptured))
+}
+
+func TestProcessor_Fail(t *testing.T) {
+	g := NewWithT(t)
+
+	payments, m, finish := newProcessor(t)
+	defer finish()
+
+	expectAuthorization(m, newPayment(0, store.Pending))
+	m.storage.EXPECT().SetPayment(gomock.Any()).Return(nil)
+
+	payment, err := payments.Fail(authorizationID)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(payment.Status).To(Equal(store.Failed))
+}
+
+func TestProcessor_Fail_Authorized(t *testing.T) {
+	g := NewWithT(t)
+
+	payments, m, finish := newProcessor(t)
+	defer finish()
+
+	expectAuthorization(m, newPayment(0, store.Authorized))
+
+	_, err := payments.Fail(authorizationID)
+
+	g.Expect(processor.IsInvalidPaymentError(err)).To(BeTrue())
+}
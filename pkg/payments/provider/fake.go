@@ -0,0 +1,298 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Rule scripts the outcome of the payments it matches. Empty fields match any payment
+type Rule struct {
+	User string `json:"user"`
+	// Amount matches a payment of exactly this amount
+	Amount uint `json:"amount"`
+	// MinAmount and MaxAmount match the payments in the interval, inclusive
+	MinAmount uint `json:"minAmount"`
+	MaxAmount uint `json:"maxAmount"`
+	// Decline refuses the matching payments with the given code
+	Decline DeclineCode `json:"decline"`
+	// Error fails the matching payments as if the provider was unavailable
+	Error string `json:"error"`
+	// Pending leaves the matching authorizations waiting for a confirmation sent through a webhook
+	Pending bool `json:"pending"`
+}
+
+// Validate checks that a rule has a single, known, outcome
+func (r Rule) Validate() error {
+	var errs errors
+	outcomes := 0
+	for _, set := range []bool{r.Decline != "", r.Error != "", r.Pending} {
+		if set {
+			outcomes++
+		}
+	}
+	if outcomes > 1 {
+		errs = append(errs, fmt.Errorf("a rule can either decline, fail or leave the payment pending"))
+	}
+	if r.Decline != "" && !r.Decline.IsKnown() {
+		errs = append(errs, fmt.Errorf("unknown decline code %s", r.Decline))
+	}
+	if r.MaxAmount != 0 && r.MaxAmount < r.MinAmount {
+		errs = append(errs, fmt.Errorf("maximum amount %d is lower than the minimum amount %d", r.MaxAmount, r.MinAmount))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (r Rule) matches(request *ChargeRequest) bool {
+	if r.User != "" && r.User != request.UserID {
+		return false
+	}
+	if r.Amount != 0 && r.Amount != request.Amount {
+		return false
+	}
+	if request.Amount < r.MinAmount {
+		return false
+	}
+	if r.MaxAmount != 0 && request.Amount > r.MaxAmount {
+		return false
+	}
+	return true
+}
+
+// defaultAuthorizationHours is how long authorizations are valid, if not configured otherwise
+const defaultAuthorizationHours = 7 * 24
+
+// FakeConfig scripts the behaviour of the fake provider
+type FakeConfig struct {
+	// LatencyMs is how long, in milliseconds, every call takes
+	LatencyMs uint `json:"latencyMs"`
+	// AuthorizationHours is how long authorizations can be captured. Defaults to 7 days
+	AuthorizationHours uint `json:"authorizationHours"`
+	// Rules are checked in order and the first matching rule decides the outcome.
+	// Payments that match no rule are approved
+	Rules []Rule `json:"rules"`
+}
+
+// LoadFakeConfig reads the script of the fake provider
+func LoadFakeConfig(r io.Reader) (*FakeConfig, error) {
+	config := &FakeConfig{}
+	err := json.NewDecoder(r).Decode(config)
+	if err != nil {
+		return nil, err
+	}
+	for i, rule := range config.Rules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid rule %d:\n%s", i, err)
+		}
+	}
+	return config, nil
+}
+
+// NewFake creates a provider whose outcomes are scripted by the configuration
+func NewFake(config *FakeConfig) *Fake {
+	return &Fake{
+		config:         config,
+		authorizations: map[string]*fakeAuthorization{},
+		now:            time.Now,
+	}
+}
+
+// fakeAuthorization tracks what happened to an authorization given by the fake provider
+type fakeAuthorization struct {
+	amount    uint
+	captured  uint
+	refunded  uint
+	closed    bool
+	expiresAt time.Time
+}
+
+// Fake is a local, deterministic, payment provider
+type Fake struct {
+	config         *FakeConfig
+	charges        uint
+	captures       uint
+	refunds        uint
+	authorizations map[string]*fakeAuthorization
+	now            func() time.Time
+	sync.Mutex
+}
+
+// Charge approves, declines or fails the payment as scripted
+func (f *Fake) Charge(ctx context.Context, request *ChargeRequest) (*Charge, error) {
+	if err := f.approve(ctx, request); err != nil {
+		return nil, err
+	}
+	f.Lock()
+	defer f.Unlock()
+	f.charges++
+	return &Charge{
+		ID:        fmt.Sprintf("fake_ch_%d", f.charges),
+		Reference: request.Reference,
+		Amount:    request.Amount,
+	}, nil
+}
+
+// Authorize approves, declines, fails or leaves pending the authorization as scripted.
+// Pending authorizations can be captured as soon as the shop receives their confirmation
+func (f *Fake) Authorize(ctx context.Context, request *ChargeRequest) (*Authorization, error) {
+	if err := f.approve(ctx, request); err != nil {
+		return nil, err
+	}
+	status := Approved
+	if rule := f.match(request); rule != nil && rule.Pending {
+		status = Pending
+	}
+	f.Lock()
+	defer f.Unlock()
+	id := fmt.Sprintf("fake_auth_%d", len(f.authorizations)+1)
+	hours := f.config.AuthorizationHours
+	if hours == 0 {
+		hours = defaultAuthorizationHours
+	}
+	authorization := &fakeAuthorization{
+		amount:    request.Amount,
+		expiresAt: f.now().Add(time.Duration(hours) * time.Hour),
+	}
+	f.authorizations[id] = authorization
+	return &Authorization{
+		ID:        id,
+		Reference: request.Reference,
+		Amount:    request.Amount,
+		Status:    status,
+		ExpiresAt: authorization.expiresAt,
+	}, nil
+}
+
+// Capture takes money from an open authorization
+func (f *Fake) Capture(ctx context.Context, request *CaptureRequest) (*Capture, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	if err := f.wait(ctx); err != nil {
+		return nil, err
+	}
+	f.Lock()
+	defer f.Unlock()
+	authorization, err := f.open(request.AuthorizationID)
+	if err != nil {
+		return nil, err
+	}
+	if authorization.captured+request.Amount > authorization.amount {
+		return nil, fmt.Errorf("cannot capture %d, only %d left on authorization %s", request.Amount, authorization.amount-authorization.captured, request.AuthorizationID)
+	}
+	authorization.captured += request.Amount
+	authorization.closed = request.Final || authorization.captured == authorization.amount
+	f.captures++
+	return &Capture{
+		ID:              fmt.Sprintf("fake_cp_%d", f.captures),
+		AuthorizationID: request.AuthorizationID,
+		Amount:          request.Amount,
+	}, nil
+}
+
+// Void releases an open authorization
+func (f *Fake) Void(ctx context.Context, authorizationID string) error {
+	if err := f.wait(ctx); err != nil {
+		return err
+	}
+	f.Lock()
+	defer f.Unlock()
+	authorization, err := f.open(authorizationID)
+	if err != nil {
+		return err
+	}
+	authorization.closed = true
+	return nil
+}
+
+// Refund gives back money captured from an authorization, even after the authorization was closed
+func (f *Fake) Refund(ctx context.Context, request *RefundRequest) (*Refund, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	if err := f.wait(ctx); err != nil {
+		return nil, err
+	}
+	f.Lock()
+	defer f.Unlock()
+	authorization, ok := f.authorizations[request.AuthorizationID]
+	if !ok {
+		return nil, fmt.Errorf("unknown authorization %s", request.AuthorizationID)
+	}
+	if authorization.refunded+request.Amount > authorization.captured {
+		return nil, fmt.Errorf("cannot refund %d, only %d captured and not refunded on authorization %s", request.Amount, authorization.captured-authorization.refunded, request.AuthorizationID)
+	}
+	authorization.refunded += request.Amount
+	f.refunds++
+	return &Refund{
+		ID:              fmt.Sprintf("fake_rf_%d", f.refunds),
+		AuthorizationID: request.AuthorizationID,
+		Amount:          request.Amount,
+	}, nil
+}
+
+// open returns the authorization, if it can still be used
+func (f *Fake) open(authorizationID string) (*fakeAuthorization, error) {
+	authorization, ok := f.authorizations[authorizationID]
+	if !ok {
+		return nil, fmt.Errorf("unknown authorization %s", authorizationID)
+	}
+	if authorization.closed {
+		return nil, fmt.Errorf("authorization %s is closed", authorizationID)
+	}
+	if !f.now().Before(authorization.expiresAt) {
+		return nil, fmt.Errorf("authorization %s expired", authorizationID)
+	}
+	return authorization, nil
+}
+
+// approve waits for the configured latency and applies the first rule matching the request
+func (f *Fake) approve(ctx context.Context, request *ChargeRequest) error {
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	if err := f.wait(ctx); err != nil {
+		return err
+	}
+	rule := f.match(request)
+	switch {
+	case rule == nil:
+		return nil
+	case rule.Decline != "":
+		return NewDeclined(rule.Decline, "")
+	case rule.Error != "":
+		return NewUnavailable(rule.Error)
+	default:
+		return nil
+	}
+}
+
+// match returns the first rule matching the request, if any
+func (f *Fake) match(request *ChargeRequest) *Rule {
+	for i := range f.config.Rules {
+		if f.config.Rules[i].matches(request) {
+			return &f.config.Rules[i]
+		}
+	}
+	return nil
+}
+
+func (f *Fake) wait(ctx context.Context) error {
+	if f.config.LatencyMs == 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(time.Duration(f.config.LatencyMs) * time.Millisecond)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
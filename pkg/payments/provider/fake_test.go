@@ -0,0 +1,154 @@
+package provider_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/pkg/payments/provider"
+)
+
+func TestFake_Charge(t *testing.T) {
+	fake := provider.NewFake(&provider.FakeConfig{
+		Rules: []provider.Rule{
+			{User: "broke@email.com", Decline: provider.InsufficientFunds},
+			{Amount: 500, Error: "unavailable"},
+			{MinAmount: 1000, MaxAmount: 2000, Decline: provider.FraudSuspected},
+		},
+	})
+	tests := []struct {
+		name    string
+		user    string
+		amount  uint
+		decline provider.DeclineCode
+		fails   bool
+	}{
+		{name: "approved", user: "user@email.com", amount: 100},
+		{name: "declined for user", user: "broke@email.com", amount: 100, decline: provider.InsufficientFunds},
+		{name: "first rule wins", user: "broke@email.com", amount: 500, decline: provider.InsufficientFunds},
+		{name: "provider error", user: "user@email.com", amount: 500, fails: true},
+		{name: "declined in interval", user: "user@email.com", amount: 2000, decline: provider.FraudSuspected},
+		{name: "above interval", user: "user@email.com", amount: 2001},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			charge, err := fake.Charge(context.Background(), &provider.ChargeRequest{Reference: "ref", UserID: test.user, Amount: test.amount, Currency: "EUR"})
+
+			switch {
+			case test.decline != "":
+				g.Expect(provider.IsDeclinedError(err)).To(BeTrue())
+				g.Expect(provider.GetDeclineCode(err)).To(Equal(test.decline))
+			case test.fails:
+				g.Expect(err).Should(HaveOccurred())
+				g.Expect(provider.IsDeclinedError(err)).To(BeFalse())
+			default:
+				g.Expect(err).ShouldNot(HaveOccurred())
+				g.Expect(charge.Amount).To(Equal(test.amount))
+				g.Expect(charge.Reference).To(Equal("ref"))
+			}
+		})
+	}
+}
+
+func TestFake_Charge_Latency(t *testing.T) {
+	g := NewWithT(t)
+
+	fake := provider.NewFake(&provider.FakeConfig{LatencyMs: 1000})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := fake.Charge(ctx, &provider.ChargeRequest{Reference: "ref", UserID: "user@email.com", Amount: 100, Currency: "EUR"})
+
+	g.Expect(err).To(Equal(context.DeadlineExceeded))
+}
+
+func TestLoadFakeConfig_Invalid(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := provider.LoadFakeConfig(strings.NewReader(`{"rules": [{"decline": "stolen_piggy_bank"}]}`))
+
+	g.Expect(err).Should(HaveOccurred())
+}
+
+func TestFake_Capture(t *testing.T) {
+	g := NewWithT(t)
+
+	fake := provider.NewFake(&provider.FakeConfig{})
+	ctx := context.Background()
+	authorization, err := fake.Authorize(ctx, &provider.ChargeRequest{Reference: "ref", UserID: "user@email.com", Amount: 100, Currency: "EUR"})
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(authorization.ExpiresAt).To(BeTemporally(">", time.Now()))
+
+	_, err = fake.Capture(ctx, &provider.CaptureRequest{AuthorizationID: authorization.ID, Reference: "ref-1", Amount: 60})
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	_, err = fake.Capture(ctx, &provider.CaptureRequest{AuthorizationID: authorization.ID, Reference: "ref-2", Amount: 50})
+	g.Expect(err).Should(HaveOccurred())
+
+	_, err = fake.Capture(ctx, &provider.CaptureRequest{AuthorizationID: authorization.ID, Reference: "ref-2", Amount: 30, Final: true})
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	err = fake.Void(ctx, authorization.ID)
+	g.Expect(err).Should(HaveOccurred())
+}
+
+func TestFake_Void(t *testing.T) {
+	g := NewWithT(t)
+
+	fake := provider.NewFake(&provider.FakeConfig{})
+	ctx := context.Background()
+	authorization, err := fake.Authorize(ctx, &provider.ChargeRequest{Reference: "ref", UserID: "user@email.com", Amount: 100, Currency: "EUR"})
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	err = fake.Void(ctx, authorization.ID)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	_, err = fake.Capture(ctx, &provider.CaptureRequest{AuthorizationID: authorization.ID, Reference: "ref-1", Amount: 10})
+	g.Expect(err).Should(HaveOccurred())
+}
+
+func TestFake_Authorize_Declined(t *testing.T) {
+	g := NewWithT(t)
+
+	fake := provider.NewFake(&provider.FakeConfig{Rules: []provider.Rule{{User: "broke@email.com", Decline: provider.InsufficientFunds}}})
+
+	_, err := fake.Authorize(context.Background(), &provider.ChargeRequest{Reference: "ref", UserID: "broke@email.com", Amount: 100, Currency: "EUR"})
+
+	g.Expect(provider.GetDeclineCode(err)).To(Equal(provider.InsufficientFunds))
+}
+
+func TestFake_Refund(t *testing.T) {
+	g := NewWithT(t)
+
+	fake := provider.NewFake(&provider.FakeConfig{})
+	ctx := context.Background()
+	authorization, err := fake.Authorize(ctx, &provider.ChargeRequest{Reference: "ref", UserID: "user@email.com", Amount: 100, Currency: "EUR"})
+	g.Expect(err).ShouldNot(HaveOccurred())
+	_, err = fake.Capture(ctx, &provider.CaptureRequest{AuthorizationID: authorization.ID, Reference: "ref-1", Amount: 60, Final: true})
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	_, err = fake.Refund(ctx, &provider.RefundRequest{AuthorizationID: authorization.ID, Reference: "ref-r1", Amount: 40, Reason: provider.Damaged})
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	_, err = fake.Refund(ctx, &provider.RefundRequest{AuthorizationID: authorization.ID, Reference: "ref-r2", Amount: 30, Reason: provider.Damaged})
+	g.Expect(err).Should(HaveOccurred())
+
+	_, err = fake.Refund(ctx, &provider.RefundRequest{AuthorizationID: authorization.ID, Reference: "ref-r2", Amount: 20, Reason: "bored"})
+	g.Expect(err).Should(HaveOccurred())
+}
+
+func TestFake_Authorize_Pending(t *testing.T) {
+	g := NewWithT(t)
+
+	fake := provider.NewFake(&provider.FakeConfig{Rules: []provider.Rule{{User: "slow@email.com", Pending: true}}})
+
+	authorization, err := fake.Authorize(context.Background(), &provider.ChargeRequest{Reference: "ref", UserID: "slow@email.com", Amount: 100, Currency: "EUR"})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(authorization.Status).To(Equal(provider.Pending))
+}
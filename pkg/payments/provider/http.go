@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// HTTPConfig holds the details needed to reach a remote payment provider
+type HTTPConfig struct {
+	// URL is the base address of the provider API
+	URL string `json:"url"`
+	// APIKey authenticates the shop to the provider
+	APIKey string `json:"apiKey"`
+}
+
+// LoadHTTPConfig reads the configuration of the HTTP provider
+func LoadHTTPConfig(r io.Reader) (*HTTPConfig, error) {
+	config := &HTTPConfig{}
+	err := json.NewDecoder(r).Decode(config)
+	if err != nil {
+		return nil, err
+	}
+	if config.URL == "" {
+		return nil, fmt.Errorf("the URL of the payment provider is mandatory")
+	}
+	config.URL = strings.TrimSuffix(config.URL, "/")
+	return config, nil
+}
+
+// providerError is the body the provider returns for failed requests
+type providerError struct {
+	Code    DeclineCode `json:"code"`
+	Message string      `json:"message"`
+}
+
+// NewHTTP creates a provider that forwards the calls to a remote payment provider
+func NewHTTP(config *HTTPConfig, client *http.Client) *HTTP {
+	return &HTTP{config: config, client: client}
+}
+
+// HTTP is an adapter for a remote payment provider. Charges are sent as JSON to POST {url}/charges and authorizations
+// to POST {url}/authorizations. Authorizations are captured with POST {url}/authorizations/{id}/captures and released
+// with POST {url}/authorizations/{id}/void. Captured money is given back with POST {url}/authorizations/{id}/refunds.
+// The provider answers with the created resource, or with a 402 status code and the decline code when refusing the payment.
+// Requests that could not be sent, and the ones answered with 429 or a 5xx status code, fail as unavailable
+type HTTP struct {
+	config *HTTPConfig
+	client *http.Client
+}
+
+// Charge asks the remote provider to take money from the user
+func (h *HTTP) Charge(ctx context.Context, request *ChargeRequest) (*Charge, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	charge := &Charge{}
+	err := h.post(ctx, "/charges", request, charge)
+	if err != nil {
+		return nil, err
+	}
+	return charge, nil
+}
+
+// Authorize asks the remote provider to reserve money from the user
+func (h *HTTP) Authorize(ctx context.Context, request *ChargeRequest) (*Authorization, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	authorization := &Authorization{}
+	err := h.post(ctx, "/authorizations", request, authorization)
+	if err != nil {
+		return nil, err
+	}
+	return authorization, nil
+}
+
+// Capture asks the remote provider to take money reserved by an authorization
+func (h *HTTP) Capture(ctx context.Context, request *CaptureRequest) (*Capture, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	capture := &Capture{}
+	err := h.post(ctx, fmt.Sprintf("/authorizations/%s/captures", url.PathEscape(request.AuthorizationID)), request, capture)
+	if err != nil {
+		return nil, err
+	}
+	return capture, nil
+}
+
+// Void asks the remote provider to release an authorization
+func (h *HTTP) Void(ctx context.Context, authorizationID string) error {
+	return h.post(ctx, fmt.Sprintf("/authorizations/%s/void", url.PathEscape(authorizationID)), struct{}{}, nil)
+}
+
+// Refund asks the remote provider to give back money captured from an authorization
+func (h *HTTP) Refund(ctx context.Context, request *RefundRequest) (*Refund, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	refund := &Refund{}
+	err := h.post(ctx, fmt.Sprintf("/authorizations/%s/refunds", url.PathEscape(request.AuthorizationID)), request, refund)
+	if err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+func (h *HTTP) post(ctx context.Context, path string, body interface{}, response interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	r, err := http.NewRequest(http.MethodPost, h.config.URL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	r = r.WithContext(ctx)
+	r.Header.Set("Content-Type", "application/json")
+	if h.config.APIKey != "" {
+		r.Header.Set("Authorization", "Bearer "+h.config.APIKey)
+	}
+	resp, err := h.client.Do(r)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return NewUnavailable(err.Error())
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPaymentRequired:
+		failure := &providerError{}
+		if err := json.NewDecoder(resp.Body).Decode(failure); err != nil || !failure.Code.IsKnown() {
+			failure.Code = CardDeclined
+		}
+		return NewDeclined(failure.Code, failure.Message)
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+		failure := &providerError{}
+		_ = json.NewDecoder(resp.Body).Decode(failure)
+		return NewUnavailable(fmt.Sprintf("returned %d: %s", resp.StatusCode, failure.Message))
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		failure := &providerError{}
+		_ = json.NewDecoder(resp.Body).Decode(failure)
+		return fmt.Errorf("payment provider returned %d: %s", resp.StatusCode, failure.Message)
+	}
+	if response == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(response)
+}
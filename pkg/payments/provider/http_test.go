@@ -0,0 +1,131 @@
+package provider_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/pkg/payments/provider"
+)
+
+func newServer(t *testing.T, status int, body interface{}) *httptest.Server {
+	return newServerFor(t, "/charges", status, body)
+}
+
+func newServerFor(t *testing.T, path string, status int, body interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("unexpected request %s %s", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+}
+
+func adapter(server *httptest.Server) *provider.HTTP {
+	return provider.NewHTTP(&provider.HTTPConfig{URL: server.URL, APIKey: "key"}, server.Client())
+}
+
+func charge(server *httptest.Server) (*provider.Charge, error) {
+	return adapter(server).Charge(context.Background(), &provider.ChargeRequest{Reference: "ref", UserID: "user@email.com", Amount: 100, Currency: "EUR"})
+}
+
+func TestHTTP_Charge(t *testing.T) {
+	g := NewWithT(t)
+
+	server := newServer(t, http.StatusCreated, &provider.Charge{ID: "ch_1", Reference: "ref", Amount: 100})
+	defer server.Close()
+
+	result, err := charge(server)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(result.ID).To(Equal("ch_1"))
+}
+
+func TestHTTP_Charge_Declined(t *testing.T) {
+	g := NewWithT(t)
+
+	server := newServer(t, http.StatusPaymentRequired, map[string]string{"code": "expired_card", "message": "card expired"})
+	defer server.Close()
+
+	_, err := charge(server)
+
+	g.Expect(provider.IsDeclinedError(err)).To(BeTrue())
+	g.Expect(provider.GetDeclineCode(err)).To(Equal(provider.ExpiredCard))
+}
+
+func TestHTTP_Charge_ProviderError(t *testing.T) {
+	g := NewWithT(t)
+
+	server := newServer(t, http.StatusBadGateway, map[string]string{"message": "upstream down"})
+	defer server.Close()
+
+	_, err := charge(server)
+
+	g.Expect(err).Should(HaveOccurred())
+	g.Expect(provider.IsDeclinedError(err)).To(BeFalse())
+	g.Expect(provider.IsUnavailableError(err)).To(BeTrue())
+}
+
+func TestHTTP_Charge_Rejected(t *testing.T) {
+	g := NewWithT(t)
+
+	server := newServer(t, http.StatusBadRequest, map[string]string{"message": "unknown currency"})
+	defer server.Close()
+
+	_, err := charge(server)
+
+	g.Expect(err).Should(HaveOccurred())
+	g.Expect(provider.IsUnavailableError(err)).To(BeFalse())
+}
+
+func TestHTTP_Authorize(t *testing.T) {
+	g := NewWithT(t)
+
+	server := newServerFor(t, "/authorizations", http.StatusCreated, &provider.Authorization{ID: "auth_1", Reference: "ref", Amount: 100})
+	defer server.Close()
+
+	result, err := adapter(server).Authorize(context.Background(), &provider.ChargeRequest{Reference: "ref", UserID: "user@email.com", Amount: 100, Currency: "EUR"})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(result.ID).To(Equal("auth_1"))
+}
+
+func TestHTTP_Capture(t *testing.T) {
+	g := NewWithT(t)
+
+	server := newServerFor(t, "/authorizations/auth_1/captures", http.StatusCreated, &provider.Capture{ID: "cp_1", AuthorizationID: "auth_1", Amount: 40})
+	defer server.Close()
+
+	result, err := adapter(server).Capture(context.Background(), &provider.CaptureRequest{AuthorizationID: "auth_1", Reference: "ref", Amount: 40})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(result.Amount).To(Equal(uint(40)))
+}
+
+func TestHTTP_Void(t *testing.T) {
+	g := NewWithT(t)
+
+	server := newServerFor(t, "/authorizations/auth_1/void", http.StatusNoContent, nil)
+	defer server.Close()
+
+	err := adapter(server).Void(context.Background(), "auth_1")
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+}
+
+func TestHTTP_Refund(t *testing.T) {
+	g := NewWithT(t)
+
+	server := newServerFor(t, "/authorizations/auth_1/refunds", http.StatusCreated, &provider.Refund{ID: "rf_1", AuthorizationID: "auth_1", Amount: 40})
+	defer server.Close()
+
+	result, err := adapter(server).Refund(context.Background(), &provider.RefundRequest{AuthorizationID: "auth_1", Reference: "ref", Amount: 40, Reason: provider.WrongItem})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(result.ID).To(Equal("rf_1"))
+}
@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./provider.go
+
+// Package mock_provider is a generated GoMock package.
+package mock_provider
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	provider "github.com/mimatache/go-shop/pkg/payments/provider"
+	reflect "reflect"
+)
+
+// MockProvider is a mock of Provider interface
+type MockProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockProviderMockRecorder
+}
+
+// MockProviderMockRecorder is the mock recorder for MockProvider
+type MockProviderMockRecorder struct {
+	mock *MockProvider
+}
+
+// NewMockProvider creates a new mock instance
+func NewMockProvider(ctrl *gomock.Controller) *MockProvider {
+	mock := &MockProvider{ctrl: ctrl}
+	mock.recorder = &MockProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockProvider) EXPECT() *MockProviderMockRecorder {
+	return m.recorder
+}
+
+// Charge mocks base method
+func (m *MockProvider) Charge(ctx context.Context, request *provider.ChargeRequest) (*provider.Charge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Charge", ctx, request)
+	ret0, _ := ret[0].(*provider.Charge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Charge indicates an expected call of Charge
+func (mr *MockProviderMockRecorder) Charge(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Charge", reflect.TypeOf((*MockProvider)(nil).Charge), ctx, request)
+}
+
+// Authorize mocks base method
+func (m *MockProvider) Authorize(ctx context.Context, request *provider.ChargeRequest) (*provider.Authorization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, request)
+	ret0, _ := ret[0].(*provider.Authorization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authorize indicates an expected call of Authorize
+func (mr *MockProviderMockRecorder) Authorize(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockProvider)(nil).Authorize), ctx, request)
+}
+
+// Capture mocks base method
+func (m *MockProvider) Capture(ctx context.Context, request *provider.CaptureRequest) (*provider.Capture, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Capture", ctx, request)
+	ret0, _ := ret[0].(*provider.Capture)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Capture indicates an expected call of Capture
+func (mr *MockProviderMockRecorder) Capture(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*MockProvider)(nil).Capture), ctx, request)
+}
+
+// Void mocks base method
+func (m *MockProvider) Void(ctx context.Context, authorizationID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Void", ctx, authorizationID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Void indicates an expected call of Void
+func (mr *MockProviderMockRecorder) Void(ctx, authorizationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Void", reflect.TypeOf((*MockProvider)(nil).Void), ctx, authorizationID)
+}
+
+// Refund mocks base method
+func (m *MockProvider) Refund(ctx context.Context, request *provider.RefundRequest) (*provider.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refund", ctx, request)
+	ret0, _ := ret[0].(*provider.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refund indicates an expected call of Refund
+func (mr *MockProviderMockRecorder) Refund(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockProvider)(nil).Refund), ctx, request)
+}
@@ -0,0 +1,275 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mimatache/go-shop/internal/money"
+)
+
+type errors []error
+
+func (e errors) Error() string {
+	b := bytes.NewBufferString("")
+	for _, err := range e {
+		_, _ = fmt.Fprintf(b, "\t%s\n", err)
+	}
+	return b.String()
+}
+
+//go:generate mockgen -source ./provider.go -destination mocks/provider.go
+
+// DeclineCode explains why a payment was refused
+type DeclineCode string
+
+const (
+	// CardDeclined the card was refused without a specific reason
+	CardDeclined DeclineCode = "card_declined"
+	// InsufficientFunds the card does not have enough funds
+	InsufficientFunds DeclineCode = "insufficient_funds"
+	// ExpiredCard the card is no longer valid
+	ExpiredCard DeclineCode = "expired_card"
+	// FraudSuspected the payment was flagged as fraudulent
+	FraudSuspected DeclineCode = "fraud_suspected"
+)
+
+// IsKnown checks if the decline code is one the shop understands
+func (d DeclineCode) IsKnown() bool {
+	switch d {
+	case CardDeclined, InsufficientFunds, ExpiredCard, FraudSuspected:
+		return true
+	default:
+		return false
+	}
+}
+
+// RefundReason explains why money is given back to the user
+type RefundReason string
+
+const (
+	// RequestedByCustomer the user changed their mind
+	RequestedByCustomer RefundReason = "requested_by_customer"
+	// Damaged the items arrived damaged
+	Damaged RefundReason = "damaged"
+	// WrongItem the user received other items than the ones ordered
+	WrongItem RefundReason = "wrong_item"
+	// NotReceived the items never arrived
+	NotReceived RefundReason = "not_received"
+	// Goodwill the money is given back as a gesture of goodwill
+	Goodwill RefundReason = "goodwill"
+)
+
+// IsKnown checks if the refund reason is one the shop understands
+func (r RefundReason) IsKnown() bool {
+	switch r {
+	case RequestedByCustomer, Damaged, WrongItem, NotReceived, Goodwill:
+		return true
+	default:
+		return false
+	}
+}
+
+type declined struct {
+	code DeclineCode
+	msg  string
+}
+
+func (d declined) Error() string {
+	return fmt.Sprintf("payment declined: %s", d.msg)
+}
+
+// IsDeclinedError verifies if a given error refers to a payment refused by the provider.
+// Declined payments are final and should not be retried
+func IsDeclinedError(err error) bool {
+	switch err.(type) {
+	case declined:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewDeclined creates a new declined payment error
+func NewDeclined(code DeclineCode, msg string) error {
+	if msg == "" {
+		msg = string(code)
+	}
+	return declined{code: code, msg: msg}
+}
+
+// GetDeclineCode returns the reason of a declined payment error
+func GetDeclineCode(err error) DeclineCode {
+	if d, ok := err.(declined); ok {
+		return d.code
+	}
+	return ""
+}
+
+type unavailable struct {
+	msg string
+}
+
+func (u unavailable) Error() string {
+	return fmt.Sprintf("payment provider unavailable: %s", u.msg)
+}
+
+// IsUnavailableError verifies if a given error refers to a provider that could not be reached or failed to answer.
+// The request may not have been processed, so it can be retried with the same reference
+func IsUnavailableError(err error) bool {
+	switch err.(type) {
+	case unavailable:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewUnavailable creates a new unavailable provider error
+func NewUnavailable(msg string) error {
+	return unavailable{msg: msg}
+}
+
+// ChargeRequest asks the provider to take money from a user
+type ChargeRequest struct {
+	// Reference identifies the request, so that the provider can recognize repeated requests
+	Reference string `json:"reference"`
+	UserID    string `json:"userID"`
+	// Amount is given in the minor unit of the currency
+	Amount   uint           `json:"amount"`
+	Currency money.Currency `json:"currency"`
+}
+
+// Validate checks that a charge request is complete
+func (c ChargeRequest) Validate() error {
+	var errs errors
+	if c.Reference == "" {
+		errs = append(errs, fmt.Errorf("reference is mandatory"))
+	}
+	if c.UserID == "" {
+		errs = append(errs, fmt.Errorf("user ID is mandatory"))
+	}
+	if err := c.Currency.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Charge is the money taken by the provider
+type Charge struct {
+	ID        string `json:"id"`
+	Reference string `json:"reference"`
+	Amount    uint   `json:"amount"`
+}
+
+// AuthorizationStatus tells if the provider reserved the money right away
+type AuthorizationStatus string
+
+const (
+	// Approved the money is reserved
+	Approved AuthorizationStatus = "approved"
+	// Pending the provider confirms or refuses the authorization later, through a webhook event
+	Pending AuthorizationStatus = "pending"
+)
+
+// Authorization is money reserved by the provider, which can be captured until it expires
+type Authorization struct {
+	ID        string `json:"id"`
+	Reference string `json:"reference"`
+	Amount    uint   `json:"amount"`
+	// Status is approved when missing
+	Status    AuthorizationStatus `json:"status,omitempty"`
+	ExpiresAt time.Time           `json:"expiresAt"`
+}
+
+// CaptureRequest asks the provider to take some of the money reserved by an authorization
+type CaptureRequest struct {
+	AuthorizationID string `json:"authorizationID"`
+	// Reference identifies the request, so that the provider can recognize repeated requests
+	Reference string `json:"reference"`
+	Amount    uint   `json:"amount"`
+	// Final releases whatever is left of the authorization after this capture
+	Final bool `json:"final"`
+}
+
+// Validate checks that a capture request is complete
+func (c CaptureRequest) Validate() error {
+	var errs errors
+	if c.AuthorizationID == "" {
+		errs = append(errs, fmt.Errorf("authorization ID is mandatory"))
+	}
+	if c.Reference == "" {
+		errs = append(errs, fmt.Errorf("reference is mandatory"))
+	}
+	if c.Amount == 0 {
+		errs = append(errs, fmt.Errorf("amount must be greater than 0"))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Capture is the money taken from an authorization
+type Capture struct {
+	ID              string `json:"id"`
+	AuthorizationID string `json:"authorizationID"`
+	Amount          uint   `json:"amount"`
+}
+
+// RefundRequest asks the provider to give back money captured from an authorization
+type RefundRequest struct {
+	AuthorizationID string `json:"authorizationID"`
+	// Reference identifies the request, so that the provider can recognize repeated requests
+	Reference string       `json:"reference"`
+	Amount    uint         `json:"amount"`
+	Reason    RefundReason `json:"reason"`
+}
+
+// Validate checks that a refund request is complete
+func (r RefundRequest) Validate() error {
+	var errs errors
+	if r.AuthorizationID == "" {
+		errs = append(errs, fmt.Errorf("authorization ID is mandatory"))
+	}
+	if r.Reference == "" {
+		errs = append(errs, fmt.Errorf("reference is mandatory"))
+	}
+	if r.Amount == 0 {
+		errs = append(errs, fmt.Errorf("amount must be greater than 0"))
+	}
+	if !r.Reason.IsKnown() {
+		errs = append(errs, fmt.Errorf("unknown refund reason %s", r.Reason))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Refund is the money given back to the user
+type Refund struct {
+	ID              string `json:"id"`
+	AuthorizationID string `json:"authorizationID"`
+	Amount          uint   `json:"amount"`
+}
+
+// Provider is the contract every payment provider implements
+type Provider interface {
+	// Charge takes money from the user. A declined error is returned if the provider refuses the payment
+	Charge(ctx context.Context, request *ChargeRequest) (*Charge, error)
+	// Authorize reserves money from the user. A declined error is returned if the provider refuses the payment
+	Authorize(ctx context.Context, request *ChargeRequest) (*Authorization, error)
+	// Capture takes money reserved by an authorization. An authorization can be captured several times,
+	// as long as the total does not exceed the authorized amount
+	Capture(ctx context.Context, request *CaptureRequest) (*Capture, error)
+	// Void releases the money of an authorization that was not captured
+	Void(ctx context.Context, authorizationID string) error
+	// Refund gives back money captured from an authorization. The refunds of an authorization
+	// never exceed what was captured from it
+	Refund(ctx context.Context, request *RefundRequest) (*Refund, error)
+}
@@ -0,0 +1,294 @@
+package provider
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// BreakerState tells if the calls are sent to the provider
+type BreakerState string
+
+const (
+	// Closed the calls are sent to the provider
+	Closed BreakerState = "closed"
+	// Open the provider failed too often and the calls are refused without reaching it
+	Open BreakerState = "open"
+	// HalfOpen a single call is sent to the provider to check if it recovered
+	HalfOpen BreakerState = "half_open"
+)
+
+const (
+	defaultAttemptTimeout   = 2 * time.Second
+	defaultDeadline         = 5 * time.Second
+	defaultAttempts         = 3
+	defaultBackoff          = 100 * time.Millisecond
+	defaultMaxBackoff       = time.Second
+	defaultFailureThreshold = 5
+	defaultOpenFor          = 30 * time.Second
+)
+
+// ResilienceConfig limits how long and how often the provider is called. Zero values are replaced by defaults
+type ResilienceConfig struct {
+	// AttemptTimeout bounds every call to the provider
+	AttemptTimeout time.Duration
+	// Deadline bounds a call together with its retries
+	Deadline time.Duration
+	// Attempts is how many times a call is made before giving up
+	Attempts int
+	// Backoff is the wait before the first retry. The wait doubles with every retry, up to MaxBackoff, and is jittered
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// FailureThreshold is how many calls in a row can fail before the breaker opens
+	FailureThreshold int
+	// OpenFor is how long the breaker stays open before letting a call through
+	OpenFor time.Duration
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+}
+
+func (r ResilienceConfig) withDefaults() ResilienceConfig {
+	if r.AttemptTimeout == 0 {
+		r.AttemptTimeout = defaultAttemptTimeout
+	}
+	if r.Deadline == 0 {
+		r.Deadline = defaultDeadline
+	}
+	if r.Attempts == 0 {
+		r.Attempts = defaultAttempts
+	}
+	if r.Backoff == 0 {
+		r.Backoff = defaultBackoff
+	}
+	if r.MaxBackoff == 0 {
+		r.MaxBackoff = defaultMaxBackoff
+	}
+	if r.FailureThreshold == 0 {
+		r.FailureThreshold = defaultFailureThreshold
+	}
+	if r.OpenFor == 0 {
+		r.OpenFor = defaultOpenFor
+	}
+	if r.Now == nil {
+		r.Now = time.Now
+	}
+	return r
+}
+
+// Metrics counts the calls made through a resilient provider
+type Metrics struct {
+	State    BreakerState `json:"state"`
+	Calls    uint64       `json:"calls"`
+	Attempts uint64       `json:"attempts"`
+	Retries  uint64       `json:"retries"`
+	Failures uint64       `json:"failures"`
+	Rejected uint64       `json:"rejected"`
+	Opened   uint64       `json:"opened"`
+}
+
+// NewResilient wraps a provider so that every call has a deadline, calls failing because the provider is unavailable
+// are retried and calls are refused right away while the provider keeps failing
+func NewResilient(next Provider, config ResilienceConfig) *Resilient {
+	return &Resilient{
+		next:   next,
+		config: config.withDefaults(),
+		sleep:  sleep,
+		state:  Closed,
+	}
+}
+
+// Resilient protects the shop from a slow or failing provider. Only calls failing as unavailable are retried: the
+// provider recognizes repeated requests by their reference, and voiding an authorization twice has no further effect.
+// Declined payments and invalid requests are answers of the provider and are neither retried nor counted as failures
+type Resilient struct {
+	sync.Mutex
+	next   Provider
+	config ResilienceConfig
+	sleep  func(ctx context.Context, d time.Duration) error
+
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	trial    bool
+	metrics  Metrics
+}
+
+// State returns the state of the circuit breaker
+func (r *Resilient) State() BreakerState {
+	r.Lock()
+	defer r.Unlock()
+	return r.current()
+}
+
+// Metrics returns the counters of the calls made so far
+func (r *Resilient) Metrics() Metrics {
+	r.Lock()
+	defer r.Unlock()
+	metrics := r.metrics
+	metrics.State = r.current()
+	return metrics
+}
+
+// Charge takes money from the user
+func (r *Resilient) Charge(ctx context.Context, request *ChargeRequest) (*Charge, error) {
+	var charge *Charge
+	err := r.call(ctx, func(ctx context.Context) error {
+		var err error
+		charge, err = r.next.Charge(ctx, request)
+		return err
+	})
+	return charge, err
+}
+
+// Authorize reserves money from the user
+func (r *Resilient) Authorize(ctx context.Context, request *ChargeRequest) (*Authorization, error) {
+	var authorization *Authorization
+	err := r.call(ctx, func(ctx context.Context) error {
+		var err error
+		authorization, err = r.next.Authorize(ctx, request)
+		return err
+	})
+	return authorization, err
+}
+
+// Capture takes money reserved by an authorization
+func (r *Resilient) Capture(ctx context.Context, request *CaptureRequest) (*Capture, error) {
+	var capture *Capture
+	err := r.call(ctx, func(ctx context.Context) error {
+		var err error
+		capture, err = r.next.Capture(ctx, request)
+		return err
+	})
+	return capture, err
+}
+
+// Void releases an authorization
+func (r *Resilient) Void(ctx context.Context, authorizationID string) error {
+	return r.call(ctx, func(ctx context.Context) error {
+		return r.next.Void(ctx, authorizationID)
+	})
+}
+
+// Refund gives back money captured from an authorization
+func (r *Resilient) Refund(ctx context.Context, request *RefundRequest) (*Refund, error) {
+	var refund *Refund
+	err := r.call(ctx, func(ctx context.Context) error {
+		var err error
+		refund, err = r.next.Refund(ctx, request)
+		return err
+	})
+	return refund, err
+}
+
+// call makes the attempts of a call within its deadline, waiting longer and longer between them
+func (r *Resilient) call(ctx context.Context, attempt func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, r.config.Deadline)
+	defer cancel()
+	r.count(func(m *Metrics) { m.Calls++ })
+
+	var err error
+	for i := 0; i < r.config.Attempts; i++ {
+		if i > 0 {
+			r.count(func(m *Metrics) { m.Retries++ })
+			if sleepErr := r.sleep(ctx, r.backoff(i)); sleepErr != nil {
+				return err
+			}
+		}
+		if !r.allow() {
+			r.count(func(m *Metrics) { m.Rejected++ })
+			return NewUnavailable("too many failures, calls are paused")
+		}
+		err = r.try(ctx, attempt)
+		r.record(err)
+		if !IsUnavailableError(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// try makes a single attempt, bounded by the attempt timeout. An attempt that runs out of time fails as unavailable
+func (r *Resilient) try(ctx context.Context, attempt func(ctx context.Context) error) error {
+	r.count(func(m *Metrics) { m.Attempts++ })
+	attemptCtx, cancel := context.WithTimeout(ctx, r.config.AttemptTimeout)
+	defer cancel()
+	err := attempt(attemptCtx)
+	if err != nil && attemptCtx.Err() == context.DeadlineExceeded {
+		return NewUnavailable(err.Error())
+	}
+	return err
+}
+
+// backoff returns the wait before the given retry, picked at random between half and the whole of the exponential backoff
+func (r *Resilient) backoff(retry int) time.Duration {
+	wait := r.config.Backoff << uint(retry-1)
+	if wait > r.config.MaxBackoff || wait <= 0 {
+		wait = r.config.MaxBackoff
+	}
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(wait-half)+1))
+}
+
+// allow checks if an attempt can be made. Once the breaker was open for long enough, a single attempt is let through
+func (r *Resilient) allow() bool {
+	r.Lock()
+	defer r.Unlock()
+	switch r.current() {
+	case Closed:
+		return true
+	case HalfOpen:
+		if r.trial {
+			return false
+		}
+		r.trial = true
+		return true
+	default:
+		return false
+	}
+}
+
+// record updates the breaker with the outcome of an attempt
+func (r *Resilient) record(err error) {
+	r.Lock()
+	defer r.Unlock()
+	state := r.current()
+	r.trial = false
+	if !IsUnavailableError(err) {
+		r.failures = 0
+		r.state = Closed
+		return
+	}
+	r.metrics.Failures++
+	r.failures++
+	if state == HalfOpen || r.failures >= r.config.FailureThreshold {
+		r.state = Open
+		r.openedAt = r.config.Now()
+		r.metrics.Opened++
+	}
+}
+
+// current returns the state of the breaker, which is half open once it was open for long enough
+func (r *Resilient) current() BreakerState {
+	if r.state == Open && r.config.Now().Sub(r.openedAt) >= r.config.OpenFor {
+		return HalfOpen
+	}
+	return r.state
+}
+
+func (r *Resilient) count(update func(m *Metrics)) {
+	r.Lock()
+	defer r.Unlock()
+	update(&r.metrics)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
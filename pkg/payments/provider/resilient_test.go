@@ -0,0 +1,127 @@
+package provider_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/pkg/payments/provider"
+	mock_provider "github.com/mimatache/go-shop/pkg/payments/provider/mocks"
+)
+
+var request = &provider.ChargeRequest{Reference: "ref", UserID: "user@email.com", Amount: 100, Currency: "EUR"}
+
+// clock is moved forward by the tests instead of waiting
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newResilient(t *testing.T, config provider.ResilienceConfig) (*provider.Resilient, *mock_provider.MockProvider, func()) {
+	ctrl := gomock.NewController(t)
+	next := mock_provider.NewMockProvider(ctrl)
+	config.Backoff = time.Millisecond
+	config.MaxBackoff = time.Millisecond
+	return provider.NewResilient(next, config), next, ctrl.Finish
+}
+
+func TestResilient_RetriesUnavailable(t *testing.T) {
+	g := NewWithT(t)
+
+	resilient, next, finish := newResilient(t, provider.ResilienceConfig{Attempts: 3})
+	defer finish()
+
+	gomock.InOrder(
+		next.EXPECT().Authorize(gomock.Any(), request).Return(nil, provider.NewUnavailable("down")),
+		next.EXPECT().Authorize(gomock.Any(), request).Return(&provider.Authorization{ID: "auth_1"}, nil),
+	)
+
+	authorization, err := resilient.Authorize(context.Background(), request)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(authorization.ID).To(Equal("auth_1"))
+	g.Expect(resilient.Metrics()).To(Equal(provider.Metrics{State: provider.Closed, Calls: 1, Attempts: 2, Retries: 1, Failures: 1}))
+}
+
+func TestResilient_DoesNotRetryDeclined(t *testing.T) {
+	g := NewWithT(t)
+
+	resilient, next, finish := newResilient(t, provider.ResilienceConfig{Attempts: 3})
+	defer finish()
+
+	next.EXPECT().Charge(gomock.Any(), request).Return(nil, provider.NewDeclined(provider.ExpiredCard, ""))
+
+	_, err := resilient.Charge(context.Background(), request)
+
+	g.Expect(provider.IsDeclinedError(err)).To(BeTrue())
+}
+
+func TestResilient_AttemptTimeout(t *testing.T) {
+	g := NewWithT(t)
+
+	resilient, next, finish := newResilient(t, provider.ResilienceConfig{Attempts: 2, AttemptTimeout: 10 * time.Millisecond})
+	defer finish()
+
+	next.EXPECT().Void(gomock.Any(), "auth_1").Times(2).DoAndReturn(func(ctx context.Context, authorizationID string) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	err := resilient.Void(context.Background(), "auth_1")
+
+	g.Expect(provider.IsUnavailableError(err)).To(BeTrue())
+}
+
+func TestResilient_Breaker(t *testing.T) {
+	g := NewWithT(t)
+
+	c := &clock{now: time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)}
+	resilient, next, finish := newResilient(t, provider.ResilienceConfig{Attempts: 1, FailureThreshold: 2, OpenFor: time.Minute, Now: c.Now})
+	defer finish()
+
+	next.EXPECT().Void(gomock.Any(), "auth_1").Times(2).Return(provider.NewUnavailable("down"))
+	g.Expect(resilient.Void(context.Background(), "auth_1")).ShouldNot(Succeed())
+	g.Expect(resilient.State()).To(Equal(provider.Closed))
+	g.Expect(resilient.Void(context.Background(), "auth_1")).ShouldNot(Succeed())
+	g.Expect(resilient.State()).To(Equal(provider.Open))
+
+	err := resilient.Void(context.Background(), "auth_1")
+	g.Expect(provider.IsUnavailableError(err)).To(BeTrue())
+	g.Expect(resilient.Metrics().Rejected).To(Equal(uint64(1)))
+
+	c.Advance(time.Minute - time.Second)
+	g.Expect(resilient.State()).To(Equal(provider.Open))
+	c.Advance(time.Second)
+	g.Expect(resilient.State()).To(Equal(provider.HalfOpen))
+
+	next.EXPECT().Void(gomock.Any(), "auth_1").Return(nil)
+	g.Expect(resilient.Void(context.Background(), "auth_1")).To(Succeed())
+	g.Expect(resilient.State()).To(Equal(provider.Closed))
+}
+
+func TestResilient_Breaker_FailedTrial(t *testing.T) {
+	g := NewWithT(t)
+
+	c := &clock{now: time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)}
+	resilient, next, finish := newResilient(t, provider.ResilienceConfig{Attempts: 1, FailureThreshold: 1, OpenFor: time.Minute, Now: c.Now})
+	defer finish()
+
+	next.EXPECT().Void(gomock.Any(), "auth_1").Times(2).Return(provider.NewUnavailable("down"))
+	g.Expect(resilient.Void(context.Background(), "auth_1")).ShouldNot(Succeed())
+
+	c.Advance(time.Minute)
+	g.Expect(resilient.Void(context.Background(), "auth_1")).ShouldNot(Succeed())
+
+	g.Expect(resilient.State()).To(Equal(provider.Open))
+	g.Expect(resilient.Metrics().Opened).To(Equal(uint64(2)))
+}
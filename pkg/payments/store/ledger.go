@@ -0,0 +1,407 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/mimatache/go-shop/internal/money"
+)
+
+//go:generate mockgen -source ./ledger.go -destination mocks/ledger.go
+
+var (
+	ledgerTable = &LedgerTable{name: "paymentLedger"}
+	reportTable = &ReportTable{name: "reconciliationReport"}
+)
+
+// GetLedgerTable returns the table of the calls made to the payment provider
+func GetLedgerTable() *LedgerTable {
+	return ledgerTable
+}
+
+// GetReportTable returns the table of the settlement reconciliation reports
+func GetReportTable() *ReportTable {
+	return reportTable
+}
+
+// LedgerTable the schema of the ledger table
+type LedgerTable struct {
+	name string
+}
+
+// GetName returns the name of the ledger table
+func (l *LedgerTable) GetName() string {
+	return l.name
+}
+
+// GetTableSchema returns the schema of the ledger table
+func (l *LedgerTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: l.name,
+		Indexes: map[string]*memdb.IndexSchema{
+			id: {
+				Name:    id,
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "ID"},
+			},
+			authorization: {
+				Name:         authorization,
+				Unique:       false,
+				AllowMissing: true,
+				Indexer:      &memdb.StringFieldIndex{Field: "AuthorizationID"},
+			},
+		},
+	}
+}
+
+// ReportTable the schema of the reconciliation report table
+type ReportTable struct {
+	name string
+}
+
+// GetName returns the name of the reconciliation report table
+func (r *ReportTable) GetName() string {
+	return r.name
+}
+
+// GetTableSchema returns the schema of the reconciliation report table
+func (r *ReportTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: r.name,
+		Indexes: map[string]*memdb.IndexSchema{
+			id: {
+				Name:    id,
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "ID"},
+			},
+		},
+	}
+}
+
+// TransactionType is the call made to the payment provider
+type TransactionType string
+
+const (
+	// ChargeTransaction takes money from the user in one step
+	ChargeTransaction TransactionType = "charge"
+	// AuthorizationTransaction reserves money from the user
+	AuthorizationTransaction TransactionType = "authorization"
+	// CaptureTransaction takes money reserved by an authorization
+	CaptureTransaction TransactionType = "capture"
+	// VoidTransaction releases an authorization
+	VoidTransaction TransactionType = "void"
+	// RefundTransaction gives back captured money
+	RefundTransaction TransactionType = "refund"
+)
+
+// MovesMoney checks if the transaction takes or gives back money, as opposed to only reserving or releasing it
+func (t TransactionType) MovesMoney() bool {
+	return t == ChargeTransaction || t == CaptureTransaction || t == RefundTransaction
+}
+
+// TransactionStatus is the outcome of a call made to the payment provider
+type TransactionStatus string
+
+const (
+	// TransactionSucceeded is a call the provider carried out
+	TransactionSucceeded TransactionStatus = "succeeded"
+	// TransactionDeclined is a call the provider refused
+	TransactionDeclined TransactionStatus = "declined"
+	// TransactionFailed is a call that could not be completed
+	TransactionFailed TransactionStatus = "failed"
+)
+
+// Account is a ledger account money is posted to
+type Account string
+
+const (
+	// ProviderClearing holds the money the provider collected for the shop and still has to settle.
+	// Captures are debited to it and refunds credited
+	ProviderClearing Account = "provider_clearing"
+	// CustomerPayments holds the money customers paid, less what was given back to them.
+	// Captures are credited to it and refunds debited
+	CustomerPayments Account = "customer_payments"
+)
+
+// Posting is the money a transaction moved in or out of an account
+type Posting struct {
+	Account Account `json:"account"`
+	Debit   uint    `json:"debit,omitempty"`
+	Credit  uint    `json:"credit,omitempty"`
+}
+
+// Transaction is a call made to the payment provider and its outcome. The money moved by succeeded
+// charges, captures and refunds is posted to the ledger accounts, with the debits matching the credits
+type Transaction struct {
+	ID     string            `json:"id"`
+	Type   TransactionType   `json:"type"`
+	Status TransactionStatus `json:"status"`
+	// AuthorizationID is the authorization the call was made for. It is empty for failed authorizations and charges
+	AuthorizationID string `json:"authorizationID,omitempty"`
+	// Reference is the reference the shop gave the call
+	Reference string `json:"reference,omitempty"`
+	// ProviderID is the ID the provider gave the charge, capture or refund. The settlement files refer to it
+	ProviderID string         `json:"providerID,omitempty"`
+	Amount     uint           `json:"amount"`
+	Currency   money.Currency `json:"currency,omitempty"`
+	Postings   []*Posting     `json:"postings,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	CreatedAt  time.Time      `json:"createdAt"`
+}
+
+// Validate checks that a transaction adheres to constraints
+func (t Transaction) Validate() error {
+	var errs errors
+	if t.ID == "" {
+		errs = append(errs, fmt.Errorf("ID cannot be empty"))
+	}
+	if t.Type == "" {
+		errs = append(errs, fmt.Errorf("type cannot be empty"))
+	}
+	if t.Status == "" {
+		errs = append(errs, fmt.Errorf("status cannot be empty"))
+	}
+	var debits, credits uint
+	for _, posting := range t.Postings {
+		debits += posting.Debit
+		credits += posting.Credit
+	}
+	if debits != credits {
+		errs = append(errs, fmt.Errorf("debits of %d do not match credits of %d", debits, credits))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// DiscrepancyType tells how a transaction differs between the ledger and the settlement file
+type DiscrepancyType string
+
+const (
+	// MissingFromSettlement is a transaction of the ledger that the provider did not settle
+	MissingFromSettlement DiscrepancyType = "missing_from_settlement"
+	// MissingFromLedger is a transaction settled by the provider that the ledger does not know about
+	MissingFromLedger DiscrepancyType = "missing_from_ledger"
+	// AmountMismatch is a transaction settled for another amount, currency or type than the one in the ledger
+	AmountMismatch DiscrepancyType = "amount_mismatch"
+)
+
+// Discrepancy is a transaction that does not match between the ledger and the settlement file
+type Discrepancy struct {
+	Type       DiscrepancyType `json:"type"`
+	ProviderID string          `json:"providerID"`
+	// Ledger is the transaction as recorded by the shop, when it has one
+	Ledger *Settled `json:"ledger,omitempty"`
+	// Settlement is the transaction as settled by the provider, when it has one
+	Settlement *Settled `json:"settlement,omitempty"`
+}
+
+// Settled is a transaction as seen by either the ledger or the settlement file
+type Settled struct {
+	Type     TransactionType `json:"type"`
+	Amount   uint            `json:"amount"`
+	Currency money.Currency  `json:"currency"`
+}
+
+// Day is the reconciliation of the transactions of a day
+type Day struct {
+	Date          string         `json:"date"`
+	Matched       uint           `json:"matched"`
+	Discrepancies []*Discrepancy `json:"discrepancies,omitempty"`
+}
+
+// Report is the reconciliation of a settlement file against the ledger
+type Report struct {
+	// ID identifies the settlement file
+	ID            string    `json:"id"`
+	Days          []*Day    `json:"days"`
+	Matched       uint      `json:"matched"`
+	Discrepancies uint      `json:"discrepancies"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// Validate checks that a report adheres to constraints
+func (r Report) Validate() error {
+	if r.ID == "" {
+		return fmt.Errorf("ID cannot be empty")
+	}
+	return nil
+}
+
+// LedgerStore represents the store of the calls made to the payment provider and of the reconciliation reports
+type LedgerStore interface {
+	AddTransaction(transaction *Transaction) error
+	GetTransactions() ([]*Transaction, error)
+	GetTransactionsForAuthorization(authorizationID string) ([]*Transaction, error)
+	SetReport(report *Report) error
+	GetReport(reportID string) (*Report, error)
+	GetReports() ([]*Report, error)
+}
+
+// NewLedgerStore start a new instance of the ledger store
+func NewLedgerStore(log logger, db UnderlyingStore) LedgerStore {
+	return &ledgerLogger{
+		log:  log,
+		next: &ledgerStore{db: db},
+	}
+}
+
+type ledgerStore struct {
+	db UnderlyingStore
+}
+
+// AddTransaction records a call made to the payment provider
+func (l *ledgerStore) AddTransaction(transaction *Transaction) error {
+	if err := transaction.Validate(); err != nil {
+		return err
+	}
+	return l.db.Write(ledgerTable.GetName(), transaction)
+}
+
+// GetTransactions returns every call made to the payment provider
+func (l *ledgerStore) GetTransactions() ([]*Transaction, error) {
+	return l.readTransactions(id+"_prefix", "")
+}
+
+// GetTransactionsForAuthorization returns the calls made for an authorization
+func (l *ledgerStore) GetTransactionsForAuthorization(authorizationID string) ([]*Transaction, error) {
+	return l.readTransactions(authorization, authorizationID)
+}
+
+func (l *ledgerStore) readTransactions(key string, value string) ([]*Transaction, error) {
+	rows, err := l.db.ReadAll(ledgerTable.GetName(), key, value)
+	if err != nil {
+		return nil, err
+	}
+	transactions := make([]*Transaction, 0, len(rows))
+	for _, row := range rows {
+		transactions = append(transactions, row.(*Transaction))
+	}
+	return transactions, nil
+}
+
+// SetReport stores a reconciliation report
+func (l *ledgerStore) SetReport(report *Report) error {
+	if err := report.Validate(); err != nil {
+		return err
+	}
+	return l.db.Write(reportTable.GetName(), report)
+}
+
+// GetReport returns the reconciliation report with the given ID
+func (l *ledgerStore) GetReport(reportID string) (*Report, error) {
+	item, err := l.db.Read(reportTable.GetName(), id, reportID)
+	if err != nil {
+		return nil, err
+	}
+	return item.(*Report), nil
+}
+
+// GetReports returns all the reconciliation reports
+func (l *ledgerStore) GetReports() ([]*Report, error) {
+	rows, err := l.db.ReadAll(reportTable.GetName(), id+"_prefix", "")
+	if err != nil {
+		return nil, err
+	}
+	reports := make([]*Report, 0, len(rows))
+	for _, row := range rows {
+		reports = append(reports, row.(*Report))
+	}
+	return reports, nil
+}
+
+type ledgerLogger struct {
+	log  logger
+	next LedgerStore
+}
+
+func (l *ledgerLogger) AddTransaction(transaction *Transaction) error {
+	var err error
+	defer func() {
+		if err != nil {
+			l.log.Debugf("could not record %s transaction %s err: %s", transaction.Type, transaction.ID, err.Error())
+			return
+		}
+		l.log.Debugw("recorded transaction", "id", transaction.ID, "type", transaction.Type, "status", transaction.Status, "amount", transaction.Amount)
+	}()
+
+	err = l.next.AddTransaction(transaction)
+	return err
+}
+
+func (l *ledgerLogger) GetTransactions() ([]*Transaction, error) {
+	var err error
+	var transactions []*Transaction
+	defer func() {
+		if err != nil {
+			l.log.Debugf("could not retrieve transactions err: %s", err.Error())
+			return
+		}
+		l.log.Debugf("retrieved %d transactions", len(transactions))
+	}()
+
+	transactions, err = l.next.GetTransactions()
+	return transactions, err
+}
+
+func (l *ledgerLogger) GetTransactionsForAuthorization(authorizationID string) ([]*Transaction, error) {
+	var err error
+	var transactions []*Transaction
+	defer func() {
+		if err != nil {
+			l.log.Debugf("could not retrieve transactions of authorization %s err: %s", authorizationID, err.Error())
+			return
+		}
+		l.log.Debugf("retrieved %d transactions of authorization %s", len(transactions), authorizationID)
+	}()
+
+	transactions, err = l.next.GetTransactionsForAuthorization(authorizationID)
+	return transactions, err
+}
+
+func (l *ledgerLogger) SetReport(report *Report) error {
+	var err error
+	defer func() {
+		if err != nil {
+			l.log.Debugf("could not store reconciliation report %s err: %s", report.ID, err.Error())
+			return
+		}
+		l.log.Debugw("stored reconciliation report", "id", report.ID, "matched", report.Matched, "discrepancies", report.Discrepancies)
+	}()
+
+	err = l.next.SetReport(report)
+	return err
+}
+
+func (l *ledgerLogger) GetReport(reportID string) (*Report, error) {
+	var err error
+	var report *Report
+	defer func() {
+		if err != nil {
+			l.log.Debugf("could not retrieve reconciliation report %s err: %s", reportID, err.Error())
+			return
+		}
+		l.log.Debugf("retrieved reconciliation report %s", reportID)
+	}()
+
+	report, err = l.next.GetReport(reportID)
+	return report, err
+}
+
+func (l *ledgerLogger) GetReports() ([]*Report, error) {
+	var err error
+	var reports []*Report
+	defer func() {
+		if err != nil {
+			l.log.Debugf("could not retrieve reconciliation reports err: %s", err.Error())
+			return
+		}
+		l.log.Debugf("retrieved %d reconciliation reports", len(reports))
+	}()
+
+	reports, err = l.next.GetReports()
+	return reports, err
+}
@@ -0,0 +1,122 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./ledger.go
+
+// Package mock_store is a generated GoMock package.
+package mock_store
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	store "github.com/mimatache/go-shop/pkg/payments/store"
+	reflect "reflect"
+)
+
+// MockLedgerStore is a mock of LedgerStore interface
+type MockLedgerStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockLedgerStoreMockRecorder
+}
+
+// MockLedgerStoreMockRecorder is the mock recorder for MockLedgerStore
+type MockLedgerStoreMockRecorder struct {
+	mock *MockLedgerStore
+}
+
+// NewMockLedgerStore creates a new mock instance
+func NewMockLedgerStore(ctrl *gomock.Controller) *MockLedgerStore {
+	mock := &MockLedgerStore{ctrl: ctrl}
+	mock.recorder = &MockLedgerStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockLedgerStore) EXPECT() *MockLedgerStoreMockRecorder {
+	return m.recorder
+}
+
+// AddTransaction mocks base method
+func (m *MockLedgerStore) AddTransaction(transaction *store.Transaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTransaction", transaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddTransaction indicates an expected call of AddTransaction
+func (mr *MockLedgerStoreMockRecorder) AddTransaction(transaction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTransaction", reflect.TypeOf((*MockLedgerStore)(nil).AddTransaction), transaction)
+}
+
+// GetTransactions mocks base method
+func (m *MockLedgerStore) GetTransactions() ([]*store.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactions")
+	ret0, _ := ret[0].([]*store.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactions indicates an expected call of GetTransactions
+func (mr *MockLedgerStoreMockRecorder) GetTransactions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactions", reflect.TypeOf((*MockLedgerStore)(nil).GetTransactions))
+}
+
+// GetTransactionsForAuthorization mocks base method
+func (m *MockLedgerStore) GetTransactionsForAuthorization(authorizationID string) ([]*store.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactionsForAuthorization", authorizationID)
+	ret0, _ := ret[0].([]*store.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactionsForAuthorization indicates an expected call of GetTransactionsForAuthorization
+func (mr *MockLedgerStoreMockRecorder) GetTransactionsForAuthorization(authorizationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionsForAuthorization", reflect.TypeOf((*MockLedgerStore)(nil).GetTransactionsForAuthorization), authorizationID)
+}
+
+// SetReport mocks base method
+func (m *MockLedgerStore) SetReport(report *store.Report) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReport", report)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetReport indicates an expected call of SetReport
+func (mr *MockLedgerStoreMockRecorder) SetReport(report interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReport", reflect.TypeOf((*MockLedgerStore)(nil).SetReport), report)
+}
+
+// GetReport mocks base method
+func (m *MockLedgerStore) GetReport(reportID string) (*store.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReport", reportID)
+	ret0, _ := ret[0].(*store.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReport indicates an expected call of GetReport
+func (mr *MockLedgerStoreMockRecorder) GetReport(reportID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReport", reflect.TypeOf((*MockLedgerStore)(nil).GetReport), reportID)
+}
+
+// GetReports mocks base method
+func (m *MockLedgerStore) GetReports() ([]*store.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReports")
+	ret0, _ := ret[0].([]*store.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReports indicates an expected call of GetReports
+func (mr *MockLedgerStoreMockRecorder) GetReports() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReports", reflect.TypeOf((*MockLedgerStore)(nil).GetReports))
+}
@@ -0,0 +1,273 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./store.go
+
+// Package mock_store is a generated GoMock package.
+package mock_store
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	store "github.com/mimatache/go-shop/pkg/payments/store"
+	reflect "reflect"
+)
+
+// Mocklogger is a mock of logger interface
+type Mocklogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockloggerMockRecorder
+}
+
+// MockloggerMockRecorder is the mock recorder for Mocklogger
+type MockloggerMockRecorder struct {
+	mock *Mocklogger
+}
+
+// NewMocklogger creates a new mock instance
+func NewMocklogger(ctrl *gomock.Controller) *Mocklogger {
+	mock := &Mocklogger{ctrl: ctrl}
+	mock.recorder = &MockloggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *Mocklogger) EXPECT() *MockloggerMockRecorder {
+	return m.recorder
+}
+
+// Infof mocks base method
+func (m *Mocklogger) Infof(msg string, args ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Infof", varargs...)
+}
+
+// Infof indicates an expected call of Infof
+func (mr *MockloggerMockRecorder) Infof(msg interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Infof", reflect.TypeOf((*Mocklogger)(nil).Infof), varargs...)
+}
+
+// Debugf mocks base method
+func (m *Mocklogger) Debugf(msg string, args ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Debugf", varargs...)
+}
+
+// Debugf indicates an expected call of Debugf
+func (mr *MockloggerMockRecorder) Debugf(msg interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Debugf", reflect.TypeOf((*Mocklogger)(nil).Debugf), varargs...)
+}
+
+// Debugw mocks base method
+func (m *Mocklogger) Debugw(msg string, keysAndValues ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range keysAndValues {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Debugw", varargs...)
+}
+
+// Debugw indicates an expected call of Debugw
+func (mr *MockloggerMockRecorder) Debugw(msg interface{}, keysAndValues ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, keysAndValues...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Debugw", reflect.TypeOf((*Mocklogger)(nil).Debugw), varargs...)
+}
+
+// MockUnderlyingStore is a mock of UnderlyingStore interface
+type MockUnderlyingStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockUnderlyingStoreMockRecorder
+}
+
+// MockUnderlyingStoreMockRecorder is the mock recorder for MockUnderlyingStore
+type MockUnderlyingStoreMockRecorder struct {
+	mock *MockUnderlyingStore
+}
+
+// NewMockUnderlyingStore creates a new mock instance
+func NewMockUnderlyingStore(ctrl *gomock.Controller) *MockUnderlyingStore {
+	mock := &MockUnderlyingStore{ctrl: ctrl}
+	mock.recorder = &MockUnderlyingStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockUnderlyingStore) EXPECT() *MockUnderlyingStoreMockRecorder {
+	return m.recorder
+}
+
+// Read mocks base method
+func (m *MockUnderlyingStore) Read(table, key string, value interface{}) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", table, key, value)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read
+func (mr *MockUnderlyingStoreMockRecorder) Read(table, key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockUnderlyingStore)(nil).Read), table, key, value)
+}
+
+// ReadAll mocks base method
+func (m *MockUnderlyingStore) ReadAll(table, key string, args ...interface{}) ([]interface{}, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{table, key}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReadAll", varargs...)
+	ret0, _ := ret[0].([]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAll indicates an expected call of ReadAll
+func (mr *MockUnderlyingStoreMockRecorder) ReadAll(table, key interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{table, key}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAll", reflect.TypeOf((*MockUnderlyingStore)(nil).ReadAll), varargs...)
+}
+
+// Write mocks base method
+func (m *MockUnderlyingStore) Write(table string, objs ...interface{}) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{table}
+	for _, a := range objs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Write", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Write indicates an expected call of Write
+func (mr *MockUnderlyingStoreMockRecorder) Write(table interface{}, objs ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{table}, objs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockUnderlyingStore)(nil).Write), varargs...)
+}
+
+// MockPaymentStore is a mock of PaymentStore interface
+type MockPaymentStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentStoreMockRecorder
+}
+
+// MockPaymentStoreMockRecorder is the mock recorder for MockPaymentStore
+type MockPaymentStoreMockRecorder struct {
+	mock *MockPaymentStore
+}
+
+// NewMockPaymentStore creates a new mock instance
+func NewMockPaymentStore(ctrl *gomock.Controller) *MockPaymentStore {
+	mock := &MockPaymentStore{ctrl: ctrl}
+	mock.recorder = &MockPaymentStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPaymentStore) EXPECT() *MockPaymentStoreMockRecorder {
+	return m.recorder
+}
+
+// SetPayment mocks base method
+func (m *MockPaymentStore) SetPayment(payment *store.Payment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPayment", payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPayment indicates an expected call of SetPayment
+func (mr *MockPaymentStoreMockRecorder) SetPayment(payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPayment", reflect.TypeOf((*MockPaymentStore)(nil).SetPayment), payment)
+}
+
+// GetPayment mocks base method
+func (m *MockPaymentStore) GetPayment(paymentID string) (*store.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPayment", paymentID)
+	ret0, _ := ret[0].(*store.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPayment indicates an expected call of GetPayment
+func (mr *MockPaymentStoreMockRecorder) GetPayment(paymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPayment", reflect.TypeOf((*MockPaymentStore)(nil).GetPayment), paymentID)
+}
+
+// GetPaymentByAuthorization mocks base method
+func (m *MockPaymentStore) GetPaymentByAuthorization(authorizationID string) (*store.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentByAuthorization", authorizationID)
+	ret0, _ := ret[0].(*store.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentByAuthorization indicates an expected call of GetPaymentByAuthorization
+func (mr *MockPaymentStoreMockRecorder) GetPaymentByAuthorization(authorizationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentByAuthorization", reflect.TypeOf((*MockPaymentStore)(nil).GetPaymentByAuthorization), authorizationID)
+}
+
+// GetPayments mocks base method
+func (m *MockPaymentStore) GetPayments() ([]*store.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPayments")
+	ret0, _ := ret[0].([]*store.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPayments indicates an expected call of GetPayments
+func (mr *MockPaymentStoreMockRecorder) GetPayments() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPayments", reflect.TypeOf((*MockPaymentStore)(nil).GetPayments))
+}
+
+// AddEvent mocks base method
+func (m *MockPaymentStore) AddEvent(event *store.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddEvent", event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddEvent indicates an expected call of AddEvent
+func (mr *MockPaymentStoreMockRecorder) AddEvent(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddEvent", reflect.TypeOf((*MockPaymentStore)(nil).AddEvent), event)
+}
+
+// GetEvent mocks base method
+func (m *MockPaymentStore) GetEvent(eventID string) (*store.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEvent", eventID)
+	ret0, _ := ret[0].(*store.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEvent indicates an expected call of GetEvent
+func (mr *MockPaymentStoreMockRecorder) GetEvent(eventID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEvent", reflect.TypeOf((*MockPaymentStore)(nil).GetEvent), eventID)
+}
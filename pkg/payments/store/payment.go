@@ -0,0 +1,157 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/mimatache/go-shop/internal/money"
+	"github.com/mimatache/go-shop/pkg/payments/provider"
+)
+
+type errors []error
+
+func (e errors) Error() string {
+	b := bytes.NewBufferString("")
+	for _, err := range e {
+		_, _ = fmt.Fprintf(b, "\t%s\n", err)
+	}
+	return b.String()
+}
+
+// Status is the state of a payment
+type Status string
+
+const (
+	// Pending the provider has not confirmed the authorization yet
+	Pending Status = "pending"
+	// Failed the provider refused the authorization after it was pending
+	Failed Status = "failed"
+	// Authorized the money is reserved and nothing was captured yet
+	Authorized Status = "authorized"
+	// PartiallyCaptured some of the money was captured and the rest is still reserved
+	PartiallyCaptured Status = "partially_captured"
+	// Captured the payment is complete and nothing is reserved anymore
+	Captured Status = "captured"
+	// Voided the authorization was released without capturing anything
+	Voided Status = "voided"
+	// Expired the authorization expired before being fully captured
+	Expired Status = "expired"
+)
+
+// IsOpen checks if money is still reserved for the payment
+func (s Status) IsOpen() bool {
+	return s == Authorized || s == PartiallyCaptured
+}
+
+// Capture is money taken from the authorization of a payment
+type Capture struct {
+	ID         string      `json:"id"`
+	Amount     money.Money `json:"amount"`
+	CapturedAt time.Time   `json:"capturedAt"`
+}
+
+// Refund is money given back to the user from what was captured
+type Refund struct {
+	ID         string                `json:"id"`
+	Amount     money.Money           `json:"amount"`
+	Reason     provider.RefundReason `json:"reason"`
+	RefundedAt time.Time             `json:"refundedAt"`
+}
+
+// Payment records the authorization of a user payment and everything captured from it
+type Payment struct {
+	ID              string `json:"id"`
+	UserID          string `json:"userID"`
+	AuthorizationID string `json:"authorizationID"`
+	Status          Status `json:"status"`
+	// Authorized sets the currency of every amount of the payment
+	Authorized money.Money `json:"authorized"`
+	Captured   money.Money `json:"captured"`
+	Captures   []*Capture  `json:"captures,omitempty"`
+	Refunded   money.Money `json:"refunded"`
+	Refunds    []*Refund   `json:"refunds,omitempty"`
+	ExpiresAt  time.Time   `json:"expiresAt"`
+	CreatedAt  time.Time   `json:"createdAt"`
+	UpdatedAt  time.Time   `json:"updatedAt"`
+}
+
+// Currency returns the currency of the payment
+func (p Payment) Currency() money.Currency {
+	return p.Authorized.Currency
+}
+
+// Remaining returns the amount that can still be captured
+func (p Payment) Remaining() (money.Money, error) {
+	if !p.Status.IsOpen() {
+		return money.New(0, p.Currency()), nil
+	}
+	return p.Authorized.Sub(p.Captured)
+}
+
+// Refundable returns the amount that was captured and not refunded yet
+func (p Payment) Refundable() (money.Money, error) {
+	return p.Captured.Sub(p.Refunded)
+}
+
+// Validate checks that a payment adheres to constraints
+func (p Payment) Validate() error {
+	var errs errors
+	if p.ID == "" {
+		errs = append(errs, fmt.Errorf("ID cannot be empty"))
+	}
+	if p.UserID == "" {
+		errs = append(errs, fmt.Errorf("user ID cannot be empty"))
+	}
+	if p.AuthorizationID == "" {
+		errs = append(errs, fmt.Errorf("authorization ID cannot be empty"))
+	}
+	if err := p.Currency().Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if p.Captured.Currency != p.Currency() || p.Refunded.Currency != p.Currency() {
+		errs = append(errs, fmt.Errorf("every amount must be in %s", p.Currency()))
+	}
+	if p.Captured.Amount > p.Authorized.Amount {
+		errs = append(errs, fmt.Errorf("captured %s is more than the authorized %s", p.Captured, p.Authorized))
+	}
+	if p.Refunded.Amount > p.Captured.Amount {
+		errs = append(errs, fmt.Errorf("refunded %s is more than the captured %s", p.Refunded, p.Captured))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Copy returns a copy of the payment that can be changed without altering the stored one
+func (p *Payment) Copy() *Payment {
+	payment := *p
+	payment.Captures = make([]*Capture, len(p.Captures))
+	copy(payment.Captures, p.Captures)
+	payment.Refunds = make([]*Refund, len(p.Refunds))
+	copy(payment.Refunds, p.Refunds)
+	return &payment
+}
+
+// Event is a notification of the payment provider that was already processed
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	ReceivedAt time.Time `json:"receivedAt"`
+}
+
+// Validate checks that an event adheres to constraints
+func (e Event) Validate() error {
+	var errs errors
+	if e.ID == "" {
+		errs = append(errs, fmt.Errorf("ID cannot be empty"))
+	}
+	if e.Type == "" {
+		errs = append(errs, fmt.Errorf("type cannot be empty"))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
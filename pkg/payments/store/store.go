@@ -0,0 +1,289 @@
+package store
+
+import (
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/mimatache/go-shop/internal/store"
+)
+
+//go:generate mockgen -source ./store.go -destination mocks/store.go
+
+type logger interface {
+	Infof(msg string, args ...interface{})
+	Debugf(msg string, args ...interface{})
+	Debugw(msg string, keysAndValues ...interface{})
+}
+
+const (
+	id            = "id"
+	user          = "user"
+	authorization = "authorization"
+)
+
+var (
+	table      = &PaymentTable{name: "payment"}
+	eventTable = &EventTable{name: "paymentEvent"}
+)
+
+// GetTable returns the payment table
+func GetTable() *PaymentTable {
+	return table
+}
+
+// GetEventTable returns the table of the processed provider events
+func GetEventTable() *EventTable {
+	return eventTable
+}
+
+// PaymentTable the payment table schema
+type PaymentTable struct {
+	name string
+}
+
+// GetName returns the name of the payment table
+func (p *PaymentTable) GetName() string {
+	return p.name
+}
+
+// GetTableSchema returns the schema of the payment table
+func (p *PaymentTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: p.name,
+		Indexes: map[string]*memdb.IndexSchema{
+			id: {
+				Name:    id,
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "ID"},
+			},
+			user: {
+				Name:    user,
+				Unique:  false,
+				Indexer: &memdb.StringFieldIndex{Field: "UserID"},
+			},
+			authorization: {
+				Name:    authorization,
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "AuthorizationID"},
+			},
+		},
+	}
+}
+
+// GetPersonalRows returns the payments of the user
+func (p *PaymentTable) GetPersonalRows(db store.Reader, userID string) ([]interface{}, error) {
+	return db.ReadAll(p.name, user, userID)
+}
+
+// Export returns the payment
+func (p *PaymentTable) Export(row interface{}) interface{} {
+	return row
+}
+
+// Anonymize keeps the payment for the pseudonym, since it is needed for the accounts of the shop
+func (p *PaymentTable) Anonymize(row interface{}, userID string, pseudonym string) (interface{}, error) {
+	anonymized := *row.(*Payment)
+	anonymized.UserID = pseudonym
+	return &anonymized, nil
+}
+
+// EventTable the schema of the table of processed provider events
+type EventTable struct {
+	name string
+}
+
+// GetName returns the name of the event table
+func (e *EventTable) GetName() string {
+	return e.name
+}
+
+// GetTableSchema returns the schema of the event table
+func (e *EventTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: e.name,
+		Indexes: map[string]*memdb.IndexSchema{
+			id: {
+				Name:    id,
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "ID"},
+			},
+		},
+	}
+}
+
+// UnderlyingStore represents the interface that the DB should implement to be usable
+type UnderlyingStore interface {
+	Read(table string, key string, value interface{}) (interface{}, error)
+	ReadAll(table string, key string, args ...interface{}) ([]interface{}, error)
+	Write(table string, objs ...interface{}) error
+}
+
+// PaymentStore represents the payment store
+type PaymentStore interface {
+	SetPayment(payment *Payment) error
+	GetPayment(paymentID string) (*Payment, error)
+	GetPaymentByAuthorization(authorizationID string) (*Payment, error)
+	GetPayments() ([]*Payment, error)
+	AddEvent(event *Event) error
+	GetEvent(eventID string) (*Event, error)
+}
+
+// New start a new instance of the payment store
+func New(log logger, db UnderlyingStore) PaymentStore {
+	return &paymentLogger{
+		log:  log,
+		next: &paymentStore{db: db},
+	}
+}
+
+type paymentStore struct {
+	db UnderlyingStore
+}
+
+// SetPayment creates or replaces a payment record
+func (p *paymentStore) SetPayment(payment *Payment) error {
+	if err := payment.Validate(); err != nil {
+		return err
+	}
+	return p.db.Write(table.GetName(), payment)
+}
+
+// GetPayment returns the payment with the given ID
+func (p *paymentStore) GetPayment(paymentID string) (*Payment, error) {
+	item, err := p.db.Read(table.GetName(), id, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	return item.(*Payment), nil
+}
+
+// GetPaymentByAuthorization returns the payment of the given provider authorization
+func (p *paymentStore) GetPaymentByAuthorization(authorizationID string) (*Payment, error) {
+	item, err := p.db.Read(table.GetName(), authorization, authorizationID)
+	if err != nil {
+		return nil, err
+	}
+	return item.(*Payment), nil
+}
+
+// GetPayments returns all the payments
+func (p *paymentStore) GetPayments() ([]*Payment, error) {
+	rows, err := p.db.ReadAll(table.GetName(), id+"_prefix", "")
+	if err != nil {
+		return nil, err
+	}
+	payments := make([]*Payment, 0, len(rows))
+	for _, row := range rows {
+		payments = append(payments, row.(*Payment))
+	}
+	return payments, nil
+}
+
+// AddEvent records a processed provider event
+func (p *paymentStore) AddEvent(event *Event) error {
+	if err := event.Validate(); err != nil {
+		return err
+	}
+	return p.db.Write(eventTable.GetName(), event)
+}
+
+// GetEvent returns the processed provider event with the given ID
+func (p *paymentStore) GetEvent(eventID string) (*Event, error) {
+	item, err := p.db.Read(eventTable.GetName(), id, eventID)
+	if err != nil {
+		return nil, err
+	}
+	return item.(*Event), nil
+}
+
+type paymentLogger struct {
+	log  logger
+	next PaymentStore
+}
+
+func (p *paymentLogger) SetPayment(payment *Payment) error {
+	var err error
+	defer func() {
+		if err != nil {
+			p.log.Debugf("could not store payment %s err: %s", payment.ID, err.Error())
+			return
+		}
+		p.log.Debugw("stored payment", "id", payment.ID, "status", payment.Status, "authorized", payment.Authorized.String(), "captured", payment.Captured.String())
+	}()
+
+	err = p.next.SetPayment(payment)
+	return err
+}
+
+func (p *paymentLogger) GetPayment(paymentID string) (*Payment, error) {
+	var err error
+	var payment *Payment
+	defer func() {
+		if err != nil {
+			p.log.Debugf("could not retrieve payment %s err: %s", paymentID, err.Error())
+			return
+		}
+		p.log.Debugf("retrieved payment %s", paymentID)
+	}()
+
+	payment, err = p.next.GetPayment(paymentID)
+	return payment, err
+}
+
+func (p *paymentLogger) GetPaymentByAuthorization(authorizationID string) (*Payment, error) {
+	var err error
+	var payment *Payment
+	defer func() {
+		if err != nil {
+			p.log.Debugf("could not retrieve payment of authorization %s err: %s", authorizationID, err.Error())
+			return
+		}
+		p.log.Debugf("retrieved payment %s of authorization %s", payment.ID, authorizationID)
+	}()
+
+	payment, err = p.next.GetPaymentByAuthorization(authorizationID)
+	return payment, err
+}
+
+func (p *paymentLogger) GetPayments() ([]*Payment, error) {
+	var err error
+	var payments []*Payment
+	defer func() {
+		if err != nil {
+			p.log.Debugf("could not retrieve payments err: %s", err.Error())
+			return
+		}
+		p.log.Debugf("retrieved %d payments", len(payments))
+	}()
+
+	payments, err = p.next.GetPayments()
+	return payments, err
+}
+
+func (p *paymentLogger) AddEvent(event *Event) error {
+	var err error
+	defer func() {
+		if err != nil {
+			p.log.Debugf("could not record provider event %s err: %s", event.ID, err.Error())
+			return
+		}
+		p.log.Debugw("recorded provider event", "id", event.ID, "type", event.Type)
+	}()
+
+	err = p.next.AddEvent(event)
+	return err
+}
+
+func (p *paymentLogger) GetEvent(eventID string) (*Event, error) {
+	var err error
+	var event *Event
+	defer func() {
+		if err != nil {
+			p.log.Debugf("could not retrieve provider event %s err: %s", eventID, err.Error())
+			return
+		}
+		p.log.Debugf("retrieved provider event %s", eventID)
+	}()
+
+	event, err = p.next.GetEvent(eventID)
+	return event, err
+}
@@ -0,0 +1,159 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./webhook.go
+
+// Package mock_webhook is a generated GoMock package.
+package mock_webhook
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	store "github.com/mimatache/go-shop/pkg/orders/store"
+	store0 "github.com/mimatache/go-shop/pkg/payments/store"
+	reflect "reflect"
+	time "time"
+)
+
+// MockPaymentsAPI is a mock of PaymentsAPI interface
+type MockPaymentsAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentsAPIMockRecorder
+}
+
+// MockPaymentsAPIMockRecorder is the mock recorder for MockPaymentsAPI
+type MockPaymentsAPIMockRecorder struct {
+	mock *MockPaymentsAPI
+}
+
+// NewMockPaymentsAPI creates a new mock instance
+func NewMockPaymentsAPI(ctrl *gomock.Controller) *MockPaymentsAPI {
+	mock := &MockPaymentsAPI{ctrl: ctrl}
+	mock.recorder = &MockPaymentsAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPaymentsAPI) EXPECT() *MockPaymentsAPIMockRecorder {
+	return m.recorder
+}
+
+// Confirm mocks base method
+func (m *MockPaymentsAPI) Confirm(authorizationID string, expiresAt time.Time) (*store0.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Confirm", authorizationID, expiresAt)
+	ret0, _ := ret[0].(*store0.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Confirm indicates an expected call of Confirm
+func (mr *MockPaymentsAPIMockRecorder) Confirm(authorizationID, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Confirm", reflect.TypeOf((*MockPaymentsAPI)(nil).Confirm), authorizationID, expiresAt)
+}
+
+// Fail mocks base method
+func (m *MockPaymentsAPI) Fail(authorizationID string) (*store0.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fail", authorizationID)
+	ret0, _ := ret[0].(*store0.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Fail indicates an expected call of Fail
+func (mr *MockPaymentsAPIMockRecorder) Fail(authorizationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fail", reflect.TypeOf((*MockPaymentsAPI)(nil).Fail), authorizationID)
+}
+
+// Expire mocks base method
+func (m *MockPaymentsAPI) Expire(authorizationID string) (*store0.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Expire", authorizationID)
+	ret0, _ := ret[0].(*store0.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Expire indicates an expected call of Expire
+func (mr *MockPaymentsAPIMockRecorder) Expire(authorizationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Expire", reflect.TypeOf((*MockPaymentsAPI)(nil).Expire), authorizationID)
+}
+
+// MockOrdersAPI is a mock of OrdersAPI interface
+type MockOrdersAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrdersAPIMockRecorder
+}
+
+// MockOrdersAPIMockRecorder is the mock recorder for MockOrdersAPI
+type MockOrdersAPIMockRecorder struct {
+	mock *MockOrdersAPI
+}
+
+// NewMockOrdersAPI creates a new mock instance
+func NewMockOrdersAPI(ctrl *gomock.Controller) *MockOrdersAPI {
+	mock := &MockOrdersAPI{ctrl: ctrl}
+	mock.recorder = &MockOrdersAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockOrdersAPI) EXPECT() *MockOrdersAPIMockRecorder {
+	return m.recorder
+}
+
+// CancelUnpaid mocks base method
+func (m *MockOrdersAPI) CancelUnpaid(paymentID string) (*store.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelUnpaid", paymentID)
+	ret0, _ := ret[0].(*store.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelUnpaid indicates an expected call of CancelUnpaid
+func (mr *MockOrdersAPIMockRecorder) CancelUnpaid(paymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelUnpaid", reflect.TypeOf((*MockOrdersAPI)(nil).CancelUnpaid), paymentID)
+}
+
+// Mocklogger is a mock of logger interface
+type Mocklogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockloggerMockRecorder
+}
+
+// MockloggerMockRecorder is the mock recorder for Mocklogger
+type MockloggerMockRecorder struct {
+	mock *Mocklogger
+}
+
+// NewMocklogger creates a new mock instance
+func NewMocklogger(ctrl *gomock.Controller) *Mocklogger {
+	mock := &Mocklogger{ctrl: ctrl}
+	mock.recorder = &MockloggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *Mocklogger) EXPECT() *MockloggerMockRecorder {
+	return m.recorder
+}
+
+// Infow mocks base method
+func (m *Mocklogger) Infow(msg string, keysAndValues ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range keysAndValues {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Infow", varargs...)
+}
+
+// Infow indicates an expected call of Infow
+func (mr *MockloggerMockRecorder) Infow(msg interface{}, keysAndValues ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, keysAndValues...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Infow", reflect.TypeOf((*Mocklogger)(nil).Infow), varargs...)
+}
@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader is the header carrying the signature of a provider event
+const SignatureHeader = "X-Signature"
+
+type invalidSignature struct {
+	reason string
+}
+
+func (i invalidSignature) Error() string {
+	return fmt.Sprintf("invalid signature: %s", i.reason)
+}
+
+// IsInvalidSignatureError checks if the event was rejected because of its signature
+func IsInvalidSignatureError(err error) bool {
+	_, ok := err.(invalidSignature)
+	return ok
+}
+
+// Sign returns the value of the signature header for an event body sent at the given time.
+// The signature is the HMAC-SHA256 of the unix timestamp and the body, joined by a dot
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", unix, digest(secret, unix, body))
+}
+
+// Verify checks that the signature header matches the body and that it was created less than tolerance ago
+func Verify(secret string, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var unix string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		pair := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(pair) != 2 {
+			continue
+		}
+		switch pair[0] {
+		case "t":
+			unix = pair[1]
+		case "v1":
+			signatures = append(signatures, pair[1])
+		}
+	}
+	if unix == "" || len(signatures) == 0 {
+		return invalidSignature{reason: "missing timestamp or signature"}
+	}
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil {
+		return invalidSignature{reason: "malformed timestamp"}
+	}
+	age := now.Sub(time.Unix(seconds, 0))
+	if age > tolerance || age < -tolerance {
+		return invalidSignature{reason: fmt.Sprintf("timestamp is outside the tolerance of %s", tolerance)}
+	}
+	expected := []byte(digest(secret, unix, body))
+	for _, signature := range signatures {
+		if hmac.Equal(expected, []byte(signature)) {
+			return nil
+		}
+	}
+	return invalidSignature{reason: "signature does not match"}
+}
+
+func digest(secret string, unix string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(unix))
+	_, _ = mac.Write([]byte("."))
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
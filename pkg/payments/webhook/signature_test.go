@@ -0,0 +1,49 @@
+package webhook_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/pkg/payments/webhook"
+)
+
+func TestVerify(t *testing.T) {
+	g := NewWithT(t)
+
+	sentAt := time.Now()
+	body := []byte(`{"id":"evt_1"}`)
+	signature := webhook.Sign("secret", sentAt, body)
+
+	g.Expect(webhook.Verify("secret", signature, body, time.Minute, sentAt.Add(30*time.Second))).To(Succeed())
+}
+
+func TestVerify_Invalid(t *testing.T) {
+	sentAt := time.Now()
+	body := []byte(`{"id":"evt_1"}`)
+	signature := webhook.Sign("secret", sentAt, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		body      []byte
+		now       time.Time
+	}{
+		{name: "wrong secret", secret: "other", signature: signature, body: body, now: sentAt},
+		{name: "changed body", secret: "secret", signature: signature, body: []byte(`{"id":"evt_2"}`), now: sentAt},
+		{name: "too old", secret: "secret", signature: signature, body: body, now: sentAt.Add(2 * time.Minute)},
+		{name: "missing", secret: "secret", signature: "", body: body, now: sentAt},
+		{name: "malformed timestamp", secret: "secret", signature: "t=yesterday,v1=abc", body: body, now: sentAt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := webhook.Verify(tt.secret, tt.signature, tt.body, time.Minute, tt.now)
+
+			g.Expect(webhook.IsInvalidSignatureError(err)).To(BeTrue())
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	internalStore "github.com/mimatache/go-shop/internal/store"
+	orderStore "github.com/mimatache/go-shop/pkg/orders/store"
+	"github.com/mimatache/go-shop/pkg/payments/store"
+)
+
+//go:generate mockgen -source ./webhook.go -destination mocks/webhook.go
+
+// DefaultTolerance is how old the signature of an event can be when no tolerance is configured
+const DefaultTolerance = 5 * time.Minute
+
+// EventType is the kind of change announced by the payment provider
+type EventType string
+
+const (
+	// AuthorizationSucceeded the provider confirmed a pending authorization
+	AuthorizationSucceeded EventType = "authorization.succeeded"
+	// AuthorizationFailed the provider refused a pending authorization
+	AuthorizationFailed EventType = "authorization.failed"
+	// AuthorizationExpired the provider dropped an authorization
+	AuthorizationExpired EventType = "authorization.expired"
+)
+
+// Data is the authorization an event refers to
+type Data struct {
+	AuthorizationID string    `json:"authorizationID"`
+	ExpiresAt       time.Time `json:"expiresAt,omitempty"`
+}
+
+// Event is a notification sent by the payment provider
+type Event struct {
+	ID      string    `json:"id"`
+	Type    EventType `json:"type"`
+	Created time.Time `json:"created"`
+	Data    Data      `json:"data"`
+}
+
+type invalidEvent struct {
+	msg string
+}
+
+func (i invalidEvent) Error() string {
+	return i.msg
+}
+
+// IsInvalidEventError checks if the event could not be understood
+func IsInvalidEventError(err error) bool {
+	_, ok := err.(invalidEvent)
+	return ok
+}
+
+// PaymentsAPI represents the methods that need to be implemented by the payments API
+type PaymentsAPI interface {
+	// Confirm marks the payment of a pending authorization as authorized
+	Confirm(authorizationID string, expiresAt time.Time) (*store.Payment, error)
+	// Fail marks the payment of a pending authorization as failed
+	Fail(authorizationID string) (*store.Payment, error)
+	// Expire marks the payment of an authorization as expired
+	Expire(authorizationID string) (*store.Payment, error)
+}
+
+// OrdersAPI represents the methods that need to be implemented by the orders API
+type OrdersAPI interface {
+	// CancelUnpaid cancels what is left of the order paid by a failed payment
+	CancelUnpaid(paymentID string) (*orderStore.Order, error)
+}
+
+type logger interface {
+	Infow(msg string, keysAndValues ...interface{})
+}
+
+// Config sets how the events of the payment provider are verified
+type Config struct {
+	// Secret is shared with the provider, which signs the events with it
+	Secret string
+	// Tolerance is how old the signature of an event can be. Defaults to DefaultTolerance
+	Tolerance time.Duration
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+}
+
+// Receiver verifies the events sent by the payment provider and applies them to the payments and orders.
+// Every event is applied once, repeated deliveries are acknowledged without doing anything
+type Receiver struct {
+	sync.Mutex
+	log      logger
+	storage  store.PaymentStore
+	payments PaymentsAPI
+	orders   OrdersAPI
+	config   Config
+}
+
+// NewReceiver instantiates a receiver of the events signed with the configured secret
+func NewReceiver(log logger, storage store.PaymentStore, payments PaymentsAPI, orders OrdersAPI, config Config) *Receiver {
+	if config.Tolerance == 0 {
+		config.Tolerance = DefaultTolerance
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	return &Receiver{
+		log:      log,
+		storage:  storage,
+		payments: payments,
+		orders:   orders,
+		config:   config,
+	}
+}
+
+// Receive verifies the signature of an event and applies it. The event is recorded only once it was applied,
+// so that the provider retries the delivery when applying it fails
+func (r *Receiver) Receive(signature string, body []byte) (*Event, error) {
+	if err := Verify(r.config.Secret, signature, body, r.config.Tolerance, r.config.Now()); err != nil {
+		return nil, err
+	}
+	event := &Event{}
+	if err := json.Unmarshal(body, event); err != nil {
+		return nil, invalidEvent{msg: fmt.Sprintf("could not decode event: %v", err)}
+	}
+	if event.ID == "" {
+		return nil, invalidEvent{msg: "event ID cannot be empty"}
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	_, err := r.storage.GetEvent(event.ID)
+	if err == nil {
+		r.log.Infow("ignoring repeated event", "event", event.ID, "type", event.Type)
+		return event, nil
+	}
+	if !internalStore.IsNotFoundError(err) {
+		return nil, err
+	}
+	if err := r.apply(event); err != nil {
+		return nil, err
+	}
+	return event, r.storage.AddEvent(&store.Event{ID: event.ID, Type: string(event.Type), ReceivedAt: r.config.Now()})
+}
+
+func (r *Receiver) apply(event *Event) error {
+	authorizationID := event.Data.AuthorizationID
+	switch event.Type {
+	case AuthorizationSucceeded, AuthorizationFailed, AuthorizationExpired:
+		if authorizationID == "" {
+			return invalidEvent{msg: fmt.Sprintf("event %s does not name an authorization", event.ID)}
+		}
+	default:
+		r.log.Infow("ignoring unknown event", "event", event.ID, "type", event.Type)
+		return nil
+	}
+
+	var err error
+	switch event.Type {
+	case AuthorizationSucceeded:
+		_, err = r.payments.Confirm(authorizationID, event.Data.ExpiresAt)
+	case AuthorizationFailed:
+		var payment *store.Payment
+		payment, err = r.payments.Fail(authorizationID)
+		if err == nil {
+			_, err = r.orders.CancelUnpaid(payment.ID)
+			if internalStore.IsNotFoundError(err) {
+				err = nil
+			}
+		}
+	case AuthorizationExpired:
+		_, err = r.payments.Expire(authorizationID)
+	}
+	if err != nil {
+		return err
+	}
+	r.log.Infow("applied provider event", "event", event.ID, "type", event.Type, "authorization", authorizationID)
+	return nil
+}
@@ -0,0 +1,146 @@
+package webhook_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	internalStore "github.com/mimatache/go-shop/internal/store"
+	orderStore "github.com/mimatache/go-shop/pkg/orders/store"
+	"github.com/mimatache/go-shop/pkg/payments/store"
+	mock_store "github.com/mimatache/go-shop/pkg/payments/store/mocks"
+	"github.com/mimatache/go-shop/pkg/payments/webhook"
+	mock_webhook "github.com/mimatache/go-shop/pkg/payments/webhook/mocks"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Infow(msg string, keysAndValues ...interface{}) {}
+
+// clock is moved forward by the tests instead of waiting
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// signedAt is when the events of the tests are signed
+var signedAt = time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+type mockSet struct {
+	storage  *mock_store.MockPaymentStore
+	payments *mock_webhook.MockPaymentsAPI
+	orders   *mock_webhook.MockOrdersAPI
+	clock    *clock
+}
+
+func newReceiver(t *testing.T) (*webhook.Receiver, *mockSet, func()) {
+	ctrl := gomock.NewController(t)
+	m := &mockSet{
+		storage:  mock_store.NewMockPaymentStore(ctrl),
+		payments: mock_webhook.NewMockPaymentsAPI(ctrl),
+		orders:   mock_webhook.NewMockOrdersAPI(ctrl),
+		clock:    &clock{now: signedAt},
+	}
+	config := webhook.Config{Secret: "secret", Tolerance: time.Minute, Now: m.clock.Now}
+	return webhook.NewReceiver(nopLogger{}, m.storage, m.payments, m.orders, config), m, ctrl.Finish
+}
+
+func signed(eventType webhook.EventType) (string, []byte) {
+	body := []byte(fmt.Sprintf(`{"id":"evt_1","type":"%s","data":{"authorizationID":"auth_1"}}`, eventType))
+	return webhook.Sign("secret", signedAt, body), body
+}
+
+func TestReceiver_Receive_Succeeded(t *testing.T) {
+	g := NewWithT(t)
+
+	receiver, m, finish := newReceiver(t)
+	defer finish()
+
+	m.storage.EXPECT().GetEvent("evt_1").Return(nil, internalStore.NewNotFoundError("paymentEvent", "id", "evt_1"))
+	m.payments.EXPECT().Confirm("auth_1", time.Time{}).Return(&store.Payment{ID: "pay_1", Status: store.Authorized}, nil)
+	m.storage.EXPECT().AddEvent(&store.Event{ID: "evt_1", Type: string(webhook.AuthorizationSucceeded), ReceivedAt: signedAt}).Return(nil)
+
+	event, err := receiver.Receive(signed(webhook.AuthorizationSucceeded))
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(event.Type).To(Equal(webhook.AuthorizationSucceeded))
+}
+
+func TestReceiver_Receive_Failed(t *testing.T) {
+	g := NewWithT(t)
+
+	receiver, m, finish := newReceiver(t)
+	defer finish()
+
+	m.storage.EXPECT().GetEvent("evt_1").Return(nil, internalStore.NewNotFoundError("paymentEvent", "id", "evt_1"))
+	m.payments.EXPECT().Fail("auth_1").Return(&store.Payment{ID: "pay_1", Status: store.Failed}, nil)
+	m.orders.EXPECT().CancelUnpaid("pay_1").Return(&orderStore.Order{ID: "order_1", Status: orderStore.Cancelled}, nil)
+	m.storage.EXPECT().AddEvent(gomock.Any()).Return(nil)
+
+	_, err := receiver.Receive(signed(webhook.AuthorizationFailed))
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+}
+
+func TestReceiver_Receive_Repeated(t *testing.T) {
+	g := NewWithT(t)
+
+	receiver, m, finish := newReceiver(t)
+	defer finish()
+
+	m.storage.EXPECT().GetEvent("evt_1").Return(&store.Event{ID: "evt_1", Type: string(webhook.AuthorizationSucceeded)}, nil)
+
+	_, err := receiver.Receive(signed(webhook.AuthorizationSucceeded))
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+}
+
+func TestReceiver_Receive_NotRecordedOnError(t *testing.T) {
+	g := NewWithT(t)
+
+	receiver, m, finish := newReceiver(t)
+	defer finish()
+
+	m.storage.EXPECT().GetEvent("evt_1").Return(nil, internalStore.NewNotFoundError("paymentEvent", "id", "evt_1"))
+	m.payments.EXPECT().Expire("auth_1").Return(nil, fmt.Errorf("db unavailable"))
+
+	_, err := receiver.Receive(signed(webhook.AuthorizationExpired))
+
+	g.Expect(err).Should(HaveOccurred())
+}
+
+func TestReceiver_Receive_BadSignature(t *testing.T) {
+	g := NewWithT(t)
+
+	receiver, _, finish := newReceiver(t)
+	defer finish()
+
+	_, body := signed(webhook.AuthorizationSucceeded)
+
+	_, err := receiver.Receive(webhook.Sign("other", signedAt, body), body)
+
+	g.Expect(webhook.IsInvalidSignatureError(err)).To(BeTrue())
+}
+
+func TestReceiver_Receive_Stale(t *testing.T) {
+	g := NewWithT(t)
+
+	receiver, m, finish := newReceiver(t)
+	defer finish()
+
+	m.clock.Advance(time.Minute + time.Second)
+
+	_, err := receiver.Receive(signed(webhook.AuthorizationSucceeded))
+
+	g.Expect(webhook.IsInvalidSignatureError(err)).To(BeTrue(), "signatures older than the tolerance are refused")
+}
@@ -3,6 +3,7 @@ package inventory
 import (
 	"sync"
 
+	"github.com/mimatache/go-shop/internal/money"
 	"github.com/mimatache/go-shop/pkg/products/store"
 )
 
@@ -14,14 +15,15 @@ type UnderlyingStore interface {
 	SetProducts(products ...*store.Product) (*store.ProductTransaction, error)
 }
 
-// New returns a new instance of inventory
-func New(store UnderlyingStore) *Inventory {
-	return &Inventory{stock: store}
+// New returns a new instance of inventory, pricing the products in other currencies with the given exchange rates
+func New(store UnderlyingStore, rates *money.Rates) *Inventory {
+	return &Inventory{stock: store, rates: rates}
 }
 
 // Inventory represents methods to manage the inventory
 type Inventory struct {
 	stock UnderlyingStore
+	rates *money.Rates
 	sync.RWMutex
 }
 
@@ -43,13 +45,53 @@ func (i *Inventory) HasInStock(id uint, quantity uint) (bool, error) {
 	return val >= quantity, err
 }
 
-// GetPrice returns the price of a product
+// GetPrice returns the price of a product in the base currency, given in its minor unit
 func (i *Inventory) GetPrice(productID uint) (uint, error) {
+	price, err := i.GetPriceIn(productID, i.rates.Base())
+	if err != nil {
+		return 0, err
+	}
+	return price.Amount, nil
+}
+
+// GetPriceIn returns the price of a product in the given currency. This is the price set for the currency
+// by the product or, if it has none, its price converted with the exchange rates
+func (i *Inventory) GetPriceIn(productID uint, currency money.Currency) (money.Money, error) {
+	product, err := i.stock.GetProductByID(productID)
+	if err != nil {
+		return money.Money{}, err
+	}
+	if price, ok := product.GetPriceIn(currency); ok {
+		return price, nil
+	}
+	return i.rates.Convert(product.GetPrice(), currency)
+}
+
+// GetCategory returns the category of a product
+func (i *Inventory) GetCategory(productID uint) (string, error) {
+	product, err := i.stock.GetProductByID(productID)
+	if err != nil {
+		return "", err
+	}
+	return product.GetCategory(), nil
+}
+
+// GetTaxClass returns the tax class of a product
+func (i *Inventory) GetTaxClass(productID uint) (string, error) {
+	product, err := i.stock.GetProductByID(productID)
+	if err != nil {
+		return "", err
+	}
+	return product.GetTaxClass(), nil
+}
+
+// GetShippingWeight returns the weight, in grams, used when shipping one unit of a product
+func (i *Inventory) GetShippingWeight(productID uint) (uint, error) {
 	product, err := i.stock.GetProductByID(productID)
 	if err != nil {
 		return 0, err
 	}
-	return product.GetPrice(), nil
+	return product.GetShippingWeight(), nil
 }
 
 // RemoveFromStock removes the requested quantity for each product from stock
@@ -93,3 +135,25 @@ func (i *Inventory) RemoveFromStock(items map[uint]uint, commitChan <-chan bool,
 	}()
 	return nil
 }
+
+// ReturnToStock adds the given quantity for each product back to the stock
+func (i *Inventory) ReturnToStock(items map[uint]uint) error {
+	i.Lock()
+	defer i.Unlock()
+	products := []*store.Product{}
+	for prodID, quantity := range items {
+		stored, err := i.stock.GetProductByID(prodID)
+		if err != nil {
+			return err
+		}
+		product := *stored
+		product.IncreaseStock(quantity)
+		products = append(products, &product)
+	}
+	transaction, err := i.stock.SetProducts(products...)
+	if err != nil {
+		return err
+	}
+	transaction.Commit()
+	return nil
+}
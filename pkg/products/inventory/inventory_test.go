@@ -2,28 +2,37 @@ package inventory_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/gomega"
 
+	"github.com/mimatache/go-shop/internal/money"
 	"github.com/mimatache/go-shop/pkg/products/inventory"
 	mock_inventory "github.com/mimatache/go-shop/pkg/products/inventory/mocks"
 	"github.com/mimatache/go-shop/pkg/products/store"
 )
 
 const (
-	itemID uint = 1
-	stock  uint = 3
-	price  uint = 100
+	itemID   uint = 1
+	stock    uint = 3
+	price    uint = 100
+	category      = "books"
+	taxClass      = "reduced"
+	weight   uint = 500
 )
 
 var (
-	product = store.Product{
-		ID:    itemID,
-		Name:  "Product 1",
-		Price: price,
-		Stock: stock,
+	rates, _ = money.LoadRates(strings.NewReader(`{"Base": "EUR", "Rates": {"USD": 1.1, "GBP": 0.85}}`))
+	product  = store.Product{
+		ID:       itemID,
+		Name:     "Product 1",
+		Category: category,
+		TaxClass: taxClass,
+		Price:    money.New(price, "EUR"),
+		Stock:    stock,
+		Weight:   weight,
 	}
 )
 
@@ -34,7 +43,7 @@ func TestInventory_GetProductStock(t *testing.T) {
 	defer ctrl.Finish()
 	mockInventory := mock_inventory.NewMockUnderlyingStore(ctrl)
 
-	productInventory := inventory.New(mockInventory)
+	productInventory := inventory.New(mockInventory, rates)
 
 	mockInventory.
 		EXPECT().
@@ -54,7 +63,7 @@ func TestInventory_GetProductStock_Error(t *testing.T) {
 	defer ctrl.Finish()
 	mockInventory := mock_inventory.NewMockUnderlyingStore(ctrl)
 
-	productInventory := inventory.New(mockInventory)
+	productInventory := inventory.New(mockInventory, rates)
 
 	mockInventory.
 		EXPECT().
@@ -73,7 +82,7 @@ func TestInventory_HasInStock(t *testing.T) {
 	defer ctrl.Finish()
 	mockInventory := mock_inventory.NewMockUnderlyingStore(ctrl)
 
-	productInventory := inventory.New(mockInventory)
+	productInventory := inventory.New(mockInventory, rates)
 
 	mockInventory.
 		EXPECT().
@@ -93,7 +102,7 @@ func TestInventory_HasInStock_False(t *testing.T) {
 	defer ctrl.Finish()
 	mockInventory := mock_inventory.NewMockUnderlyingStore(ctrl)
 
-	productInventory := inventory.New(mockInventory)
+	productInventory := inventory.New(mockInventory, rates)
 
 	mockInventory.
 		EXPECT().
@@ -113,7 +122,7 @@ func TestInventory_HasInStock_Error(t *testing.T) {
 	defer ctrl.Finish()
 	mockInventory := mock_inventory.NewMockUnderlyingStore(ctrl)
 
-	productInventory := inventory.New(mockInventory)
+	productInventory := inventory.New(mockInventory, rates)
 
 	mockInventory.
 		EXPECT().
@@ -132,7 +141,7 @@ func TestInventory_GetPrice(t *testing.T) {
 	defer ctrl.Finish()
 	mockInventory := mock_inventory.NewMockUnderlyingStore(ctrl)
 
-	productInventory := inventory.New(mockInventory)
+	productInventory := inventory.New(mockInventory, rates)
 
 	mockInventory.
 		EXPECT().
@@ -152,7 +161,7 @@ func TestInventory_GetPrice_Error(t *testing.T) {
 	defer ctrl.Finish()
 	mockInventory := mock_inventory.NewMockUnderlyingStore(ctrl)
 
-	productInventory := inventory.New(mockInventory)
+	productInventory := inventory.New(mockInventory, rates)
 
 	mockInventory.
 		EXPECT().
@@ -163,3 +172,156 @@ func TestInventory_GetPrice_Error(t *testing.T) {
 
 	g.Expect(err).Should(HaveOccurred())
 }
+
+func TestInventory_GetPrice_OtherCurrency(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockInventory := mock_inventory.NewMockUnderlyingStore(ctrl)
+
+	productInventory := inventory.New(mockInventory, rates)
+
+	imported := product
+	imported.Price = money.New(85, "GBP")
+	mockInventory.
+		EXPECT().
+		GetProductByID(itemID).
+		Return(&imported, nil)
+
+	productPrice, err := productInventory.GetPrice(itemID)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(productPrice).To(Equal(price), "the price is converted to the base currency")
+}
+
+func TestInventory_GetCategory(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockInventory := mock_inventory.NewMockUnderlyingStore(ctrl)
+
+	productInventory := inventory.New(mockInventory, rates)
+
+	mockInventory.
+		EXPECT().
+		GetProductByID(itemID).
+		Return(&product, nil)
+
+	productCategory, err := productInventory.GetCategory(itemID)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(productCategory).To(Equal(category))
+}
+
+func TestInventory_GetCategory_Error(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockInventory := mock_inventory.NewMockUnderlyingStore(ctrl)
+
+	productInventory := inventory.New(mockInventory, rates)
+
+	mockInventory.
+		EXPECT().
+		GetProductByID(itemID).
+		Return(nil, fmt.Errorf("an error"))
+
+	_, err := productInventory.GetCategory(itemID)
+
+	g.Expect(err).Should(HaveOccurred())
+}
+
+func TestInventory_GetTaxClass(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockInventory := mock_inventory.NewMockUnderlyingStore(ctrl)
+
+	productInventory := inventory.New(mockInventory, rates)
+
+	mockInventory.
+		EXPECT().
+		GetProductByID(itemID).
+		Return(&product, nil)
+
+	productTaxClass, err := productInventory.GetTaxClass(itemID)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(productTaxClass).To(Equal(taxClass))
+}
+
+func TestInventory_GetShippingWeight(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockInventory := mock_inventory.NewMockUnderlyingStore(ctrl)
+
+	productInventory := inventory.New(mockInventory, rates)
+
+	mockInventory.
+		EXPECT().
+		GetProductByID(itemID).
+		Return(&product, nil)
+
+	productWeight, err := productInventory.GetShippingWeight(itemID)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(productWeight).To(Equal(weight))
+}
+
+func TestInventory_GetShippingWeight_Volumetric(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockInventory := mock_inventory.NewMockUnderlyingStore(ctrl)
+
+	productInventory := inventory.New(mockInventory, rates)
+
+	bulky := product
+	bulky.Dimensions = &store.Dimensions{Length: 200, Width: 100, Height: 250}
+	mockInventory.
+		EXPECT().
+		GetProductByID(itemID).
+		Return(&bulky, nil)
+
+	productWeight, err := productInventory.GetShippingWeight(itemID)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(productWeight).To(Equal(uint(1000)))
+}
+
+func TestInventory_GetPriceIn(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockInventory := mock_inventory.NewMockUnderlyingStore(ctrl)
+
+	productInventory := inventory.New(mockInventory, rates)
+
+	listed := product
+	listed.Prices = []money.Money{money.New(90, "GBP")}
+	mockInventory.
+		EXPECT().
+		GetProductByID(itemID).
+		Return(&listed, nil).
+		Times(3)
+
+	productPrice, err := productInventory.GetPriceIn(itemID, "EUR")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(productPrice).To(Equal(money.New(price, "EUR")))
+
+	productPrice, err = productInventory.GetPriceIn(itemID, "GBP")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(productPrice).To(Equal(money.New(90, "GBP")))
+
+	productPrice, err = productInventory.GetPriceIn(itemID, "USD")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(productPrice).To(Equal(money.New(110, "USD")))
+}
@@ -2,11 +2,12 @@ package products
 
 import (
 	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/internal/money"
 	"github.com/mimatache/go-shop/pkg/products/inventory"
 	"github.com/mimatache/go-shop/pkg/products/store"
 )
 
-func NewAPI(log logger.Logger, db store.UnderlyingStore) *inventory.Inventory {
+func NewAPI(log logger.Logger, db store.UnderlyingStore, rates *money.Rates) *inventory.Inventory {
 	stock := store.New(log, db)
-	return inventory.New(stock)
+	return inventory.New(stock, rates)
 }
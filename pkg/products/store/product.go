@@ -3,6 +3,8 @@ package store
 import (
 	"bytes"
 	"fmt"
+
+	"github.com/mimatache/go-shop/internal/money"
 )
 
 type errors []error
@@ -20,19 +22,73 @@ type Validatable interface {
 	Validate() error
 }
 
+// volumetricDivisor converts a volume in cubic millimeters to grams, using the common 5000 cm3/kg courier factor
+const volumetricDivisor = 5000
+
+// Dimensions holds the size of a product package, in millimeters
+type Dimensions struct {
+	Length uint `json:"Length"`
+	Width  uint `json:"Width"`
+	Height uint `json:"Height"`
+}
+
 // Product models a shop product
 type Product struct {
-	ID    uint   `json:"ID"`
-	Name  string `json:"Name"`
-	Price uint   `json:"Price"`
-	Stock uint   `json:"Stock"`
+	ID       uint   `json:"ID"`
+	Name     string `json:"Name"`
+	Category string `json:"Category"`
+	TaxClass string `json:"TaxClass"`
+	// Price is usually given in the base currency of the shop
+	Price money.Money `json:"Price"`
+	// Prices holds the prices set for other currencies. The currencies missing from it are converted from Price
+	Prices []money.Money `json:"Prices,omitempty"`
+	Stock  uint          `json:"Stock"`
+	// Weight is given in grams
+	Weight     uint        `json:"Weight,omitempty"`
+	Dimensions *Dimensions `json:"Dimensions,omitempty"`
+}
+
+// GetShippingWeight returns the weight, in grams, used when shipping this product.
+// This is the highest of the actual weight and the volumetric weight of the package
+func (p *Product) GetShippingWeight() uint {
+	if p.Dimensions == nil {
+		return p.Weight
+	}
+	volumetric := p.Dimensions.Length * p.Dimensions.Width * p.Dimensions.Height / volumetricDivisor
+	if volumetric > p.Weight {
+		return volumetric
+	}
+	return p.Weight
+}
+
+// GetCategory returns the category this product belongs to
+func (p *Product) GetCategory() string {
+	return p.Category
 }
 
-// GetPrice returns the amount of this product left in stock
-func (p *Product) GetPrice() uint {
+// GetPrice returns the price of this product
+func (p *Product) GetPrice() money.Money {
 	return p.Price
 }
 
+// GetPriceIn returns the price set for the given currency, if there is one
+func (p *Product) GetPriceIn(currency money.Currency) (money.Money, bool) {
+	if p.Price.Currency == currency {
+		return p.Price, true
+	}
+	for _, price := range p.Prices {
+		if price.Currency == currency {
+			return price, true
+		}
+	}
+	return money.Money{}, false
+}
+
+// GetTaxClass returns the tax class of this product
+func (p *Product) GetTaxClass() string {
+	return p.TaxClass
+}
+
 // GetStock returns the amount of this product left in stock
 func (p *Product) GetStock() uint {
 	return p.Stock
@@ -66,6 +122,19 @@ func (p *Product) Validate() error {
 	if p.Name == "" {
 		errs = append(errs, fmt.Errorf("name is mandatory"))
 	}
+	if err := p.Price.Currency.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	priced := map[money.Currency]bool{p.Price.Currency: true}
+	for _, price := range p.Prices {
+		if err := price.Currency.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+		if priced[price.Currency] {
+			errs = append(errs, fmt.Errorf("more than one price is set for %s", price.Currency))
+		}
+		priced[price.Currency] = true
+	}
 	if len(errs) > 0 {
 		return errs
 	}
@@ -97,11 +97,6 @@ func (u *ProductTable) GetTableSchema() *memdb.TableSchema {
 				Unique:  false,
 				Indexer: &memdb.StringFieldIndex{Field: "Name"},
 			},
-			"price": {
-				Name:    "price",
-				Unique:  false,
-				Indexer: &memdb.UintFieldIndex{Field: "Price"},
-			},
 			"stock": {
 				Name:    "stock",
 				Unique:  true,
@@ -2,12 +2,14 @@ package store_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/gomega"
 
 	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/internal/money"
 	"github.com/mimatache/go-shop/internal/store"
 	productStore "github.com/mimatache/go-shop/pkg/products/store"
 	mock_store "github.com/mimatache/go-shop/pkg/products/store/mocks"
@@ -21,7 +23,7 @@ var (
 	item = &productStore.Product{
 		ID:    productID,
 		Name:  "awesome product",
-		Price: 10,
+		Price: money.New(10, "EUR"),
 		Stock: 2,
 	}
 
@@ -116,3 +118,38 @@ func TestProductStore_SetProducts_Error(t *testing.T) {
 
 	g.Expect(err).Should(HaveOccurred())
 }
+
+func TestLoadSeeds(t *testing.T) {
+	g := NewWithT(t)
+
+	log, _, _ := logger.New("test", true)
+
+	schema := store.NewSchema()
+	schema.AddToSchema(productStore.GetTable())
+	db, err := store.New(schema)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	seeds := `[{"ID": 1, "Name": "priced", "Price": {"amount": 200, "currency": "EUR"}, "Prices": [{"amount": 220, "currency": "USD"}], "Stock": 3}]`
+	g.Expect(productStore.LoadSeeds(strings.NewReader(seeds), db)).To(Succeed())
+
+	product, err := productStore.New(log, db).GetProductByID(1)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(product.GetPrice()).To(Equal(money.New(200, "EUR")))
+	price, ok := product.GetPriceIn("USD")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(price).To(Equal(money.New(220, "USD")))
+}
+
+func TestProduct_Validate_Prices(t *testing.T) {
+	g := NewWithT(t)
+
+	product := &productStore.Product{ID: productID, Name: "priced", Price: money.New(10, "EUR"), Prices: []money.Money{money.New(11, "USD")}}
+	g.Expect(product.Validate()).To(Succeed())
+
+	product.Prices = append(product.Prices, money.New(12, "USD"), money.New(9, "EUR"))
+	g.Expect(product.Validate()).Should(MatchError(ContainSubstring("more than one price is set for USD")))
+	g.Expect(product.Validate()).Should(MatchError(ContainSubstring("more than one price is set for EUR")))
+
+	product = &productStore.Product{ID: productID, Name: "unpriced", Price: money.New(10, "XXX")}
+	g.Expect(product.Validate()).Should(MatchError(ContainSubstring(`currency "XXX" is not supported`)))
+}
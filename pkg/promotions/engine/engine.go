@@ -0,0 +1,413 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mimatache/go-shop/internal/money"
+	internalStore "github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/promotions/store"
+)
+
+//go:generate mockgen -source ./engine.go -destination mocks/engine.go
+
+type errors []error
+
+func (e errors) Error() string {
+	b := bytes.NewBufferString("")
+	for _, err := range e {
+		_, _ = fmt.Fprintf(b, "\t%s\n", err)
+	}
+	return b.String()
+}
+
+// UnderlyingStore represents the interface the promotions store has to implement
+type UnderlyingStore interface {
+	GetCoupon(code string) (*store.Coupon, error)
+	SetCoupon(coupon *store.Coupon) error
+	AddRedemption(redemption *store.Redemption) error
+	RemoveRedemption(id string) error
+}
+
+type invalidCoupon struct {
+	msg string
+}
+
+func (i invalidCoupon) Error() string {
+	return i.msg
+}
+
+// IsInvalidCouponError verifies if a given error refers to a coupon that cannot be used
+func IsInvalidCouponError(err error) bool {
+	switch err.(type) {
+	case invalidCoupon:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewInvalidCoupon creates a new invalid coupon error
+func NewInvalidCoupon(code string, reason string) error {
+	return invalidCoupon{msg: fmt.Sprintf("coupon %s %s", code, reason)}
+}
+
+// Line is a product line the promotions are computed for
+type Line struct {
+	ProductID uint
+	Category  string
+	UnitPrice uint
+	Quantity  uint
+}
+
+// Applied is a promotion that was applied to a set of lines
+type Applied struct {
+	Code         string `json:"code"`
+	Description  string `json:"description"`
+	Discount     uint   `json:"discount"`
+	RedemptionID string `json:"-"`
+}
+
+// Result contains the cost of a set of lines after the promotions were applied
+type Result struct {
+	Subtotal   uint       `json:"subtotal"`
+	Discount   uint       `json:"discount"`
+	Total      uint       `json:"total"`
+	Promotions []*Applied `json:"promotions,omitempty"`
+	// LineDiscounts holds the discount of each of the lines, in the order they were given
+	LineDiscounts []uint `json:"-"`
+}
+
+// New returns a new promotions engine
+func New(storage UnderlyingStore) *Engine {
+	return &Engine{
+		storage: storage,
+		now:     time.Now,
+	}
+}
+
+// Engine computes and redeems promotions
+type Engine struct {
+	storage UnderlyingStore
+	now     func() time.Time
+	sync.Mutex
+}
+
+// CheckCoupon verifies that a coupon exists and can be used right now
+func (e *Engine) CheckCoupon(code string) error {
+	_, err := e.getUsableCoupon(code)
+	return err
+}
+
+// Apply computes the discount the given coupons grant for the lines.
+// Coupons that can no longer be used are ignored. Stackable coupons are combined with each other,
+// while a coupon that is not stackable can only be used on its own. The combination giving the highest discount wins.
+// The discount of a line never exceeds its price. Lines worth more than can be represented are refused.
+func (e *Engine) Apply(lines []Line, codes []string) (*Result, error) {
+	var subtotal uint
+	for _, line := range lines {
+		amount, err := money.Mul(line.UnitPrice, line.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		subtotal, err = money.Add(subtotal, amount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	stackable := []*store.Coupon{}
+	exclusive := []*store.Coupon{}
+	seen := map[string]struct{}{}
+	for _, code := range codes {
+		if _, ok := seen[code]; ok {
+			continue
+		}
+		seen[code] = struct{}{}
+		coupon, err := e.getUsableCoupon(code)
+		if err != nil {
+			if IsInvalidCouponError(err) {
+				continue
+			}
+			return nil, err
+		}
+		if coupon.Stackable {
+			stackable = append(stackable, coupon)
+			continue
+		}
+		exclusive = append(exclusive, coupon)
+	}
+
+	best, lineDiscounts, err := applyCoupons(stackable, lines)
+	if err != nil {
+		return nil, err
+	}
+	discount, err := totalDiscount(best)
+	if err != nil {
+		return nil, err
+	}
+	for _, coupon := range exclusive {
+		applied, discounts, err := applyCoupons([]*store.Coupon{coupon}, lines)
+		if err != nil {
+			return nil, err
+		}
+		total, err := totalDiscount(applied)
+		if err != nil {
+			return nil, err
+		}
+		if total > discount {
+			best, lineDiscounts, discount = applied, discounts, total
+		}
+	}
+
+	total, err := money.Sub(subtotal, discount)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Subtotal:      subtotal,
+		Discount:      discount,
+		Total:         total,
+		Promotions:    best,
+		LineDiscounts: lineDiscounts,
+	}, nil
+}
+
+// Redeem marks the promotions in the result as used by the user.
+// If any of the coupons can no longer be used, the ones already redeemed are released and an error is returned.
+func (e *Engine) Redeem(userID string, result *Result) error {
+	e.Lock()
+	defer e.Unlock()
+	for i, applied := range result.Promotions {
+		coupon, err := e.getUsableCoupon(applied.Code)
+		if err == nil {
+			err = e.redeem(userID, coupon, applied)
+		}
+		if err != nil {
+			_ = e.release(result.Promotions[:i])
+			return err
+		}
+	}
+	return nil
+}
+
+// Release reverts the redemption of the promotions in the result
+func (e *Engine) Release(userID string, result *Result) error {
+	e.Lock()
+	defer e.Unlock()
+	return e.release(result.Promotions)
+}
+
+func (e *Engine) redeem(userID string, coupon *store.Coupon, applied *Applied) error {
+	updated := *coupon
+	updated.Used++
+	if err := e.storage.SetCoupon(&updated); err != nil {
+		return err
+	}
+	now := e.now()
+	redemption := &store.Redemption{
+		ID:       fmt.Sprintf("%s-%s-%d", coupon.Code, userID, now.UnixNano()),
+		Code:     coupon.Code,
+		UserID:   userID,
+		Discount: applied.Discount,
+		Time:     now,
+	}
+	if err := e.storage.AddRedemption(redemption); err != nil {
+		_ = e.storage.SetCoupon(coupon)
+		return err
+	}
+	applied.RedemptionID = redemption.ID
+	return nil
+}
+
+func (e *Engine) release(promotions []*Applied) error {
+	errs := errors{}
+	for _, applied := range promotions {
+		if applied.RedemptionID == "" {
+			continue
+		}
+		coupon, err := e.storage.GetCoupon(applied.Code)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		updated := *coupon
+		if updated.Used > 0 {
+			updated.Used--
+		}
+		if err := e.storage.SetCoupon(&updated); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := e.storage.RemoveRedemption(applied.RedemptionID); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		applied.RedemptionID = ""
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (e *Engine) getUsableCoupon(code string) (*store.Coupon, error) {
+	coupon, err := e.storage.GetCoupon(code)
+	if err != nil {
+		if internalStore.IsNotFoundError(err) {
+			return nil, NewInvalidCoupon(code, "does not exist")
+		}
+		return nil, err
+	}
+	if !coupon.IsActive(e.now()) {
+		return nil, NewInvalidCoupon(code, "is not active")
+	}
+	if coupon.IsExhausted() {
+		return nil, NewInvalidCoupon(code, "reached its usage limit")
+	}
+	return coupon, nil
+}
+
+// applyCoupons applies the coupons one after the other. A coupon can only discount what is left to pay for a line
+// after the previous coupons were applied. It returns the applied promotions and the discount for each line
+func applyCoupons(coupons []*store.Coupon, lines []Line) ([]*Applied, []uint, error) {
+	applied := []*Applied{}
+	left := make([]uint, len(lines))
+	for i, line := range lines {
+		amount, err := money.Mul(line.UnitPrice, line.Quantity)
+		if err != nil {
+			return nil, nil, err
+		}
+		left[i] = amount
+	}
+	lineDiscounts := make([]uint, len(lines))
+	for _, coupon := range coupons {
+		discounts, err := computeDiscount(coupon.Rule, lines)
+		if err != nil {
+			return nil, nil, err
+		}
+		var discount uint
+		for i, lineDiscount := range discounts {
+			if lineDiscount > left[i] {
+				lineDiscount = left[i]
+			}
+			left[i] -= lineDiscount
+			// a line is never discounted more than its amount, so neither sum can overflow
+			lineDiscounts[i] += lineDiscount
+			if discount, err = money.Add(discount, lineDiscount); err != nil {
+				return nil, nil, err
+			}
+		}
+		if discount == 0 {
+			continue
+		}
+		applied = append(applied, &Applied{
+			Code:        coupon.Code,
+			Description: coupon.Description,
+			Discount:    discount,
+		})
+	}
+	return applied, lineDiscounts, nil
+}
+
+func totalDiscount(applied []*Applied) (uint, error) {
+	discounts := make([]uint, 0, len(applied))
+	for _, v := range applied {
+		discounts = append(discounts, v.Discount)
+	}
+	return money.Sum(discounts...)
+}
+
+// computeDiscount returns the discount a rule grants for each of the given lines
+func computeDiscount(rule store.Rule, lines []Line) ([]uint, error) {
+	discounts := make([]uint, len(lines))
+	amounts := make([]uint, len(lines))
+	var eligibleSubtotal uint
+	for i, line := range lines {
+		if rule.Category != "" && line.Category != rule.Category {
+			continue
+		}
+		if rule.ProductID != 0 && line.ProductID != rule.ProductID {
+			continue
+		}
+		amount, err := money.Mul(line.UnitPrice, line.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		amounts[i] = amount
+		if eligibleSubtotal, err = money.Add(eligibleSubtotal, amount); err != nil {
+			return nil, err
+		}
+	}
+
+	switch rule.Type {
+	case store.PercentageOff:
+		discount, err := money.Scale(eligibleSubtotal, rule.Percent, 100)
+		if err != nil {
+			return nil, err
+		}
+		return spread(discount, amounts, eligibleSubtotal)
+	case store.FixedAmount:
+		if rule.Amount > eligibleSubtotal {
+			return amounts, nil
+		}
+		return spread(rule.Amount, amounts, eligibleSubtotal)
+	case store.BuyXGetY:
+		for i, line := range lines {
+			if amounts[i] == 0 {
+				continue
+			}
+			free := line.Quantity / (rule.BuyQuantity + rule.GetQuantity) * rule.GetQuantity
+			discount, err := money.Mul(free, line.UnitPrice)
+			if err != nil {
+				return nil, err
+			}
+			discounts[i] = discount
+		}
+	case store.FreeItemThreshold:
+		if eligibleSubtotal < rule.Threshold {
+			return discounts, nil
+		}
+		for i, line := range lines {
+			if line.ProductID == rule.FreeProductID && line.Quantity > 0 {
+				discounts[i] = line.UnitPrice
+				break
+			}
+		}
+	}
+	return discounts, nil
+}
+
+// spread divides the discount over the amounts, proportionally to their value.
+// The total is the sum of the amounts and the discount must not be higher than it
+func spread(discount uint, amounts []uint, total uint) ([]uint, error) {
+	shares := make([]uint, len(amounts))
+	if discount == 0 || total == 0 {
+		return shares, nil
+	}
+	left := discount
+	for i, amount := range amounts {
+		share, err := money.Scale(discount, amount, total)
+		if err != nil {
+			return nil, err
+		}
+		if share > left {
+			share = left
+		}
+		shares[i] = share
+		left -= share
+	}
+	// the rounding leftovers go to the first amounts that can still be discounted
+	for i, amount := range amounts {
+		if left == 0 {
+			break
+		}
+		if shares[i] < amount {
+			shares[i]++
+			left--
+		}
+	}
+	return shares, nil
+}
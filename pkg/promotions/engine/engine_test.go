@@ -0,0 +1,363 @@
+package engine_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/money"
+	internalStore "github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/promotions/engine"
+	mock_engine "github.com/mimatache/go-shop/pkg/promotions/engine/mocks"
+	"github.com/mimatache/go-shop/pkg/promotions/store"
+)
+
+const userID = "user@email.com"
+
+var (
+	lines = []engine.Line{
+		{ProductID: 1, Category: "books", UnitPrice: 100, Quantity: 2},
+		{ProductID: 2, Category: "games", UnitPrice: 200, Quantity: 3},
+	}
+)
+
+func expectCoupons(mockStore *mock_engine.MockUnderlyingStore, coupons ...*store.Coupon) {
+	for _, coupon := range coupons {
+		mockStore.
+			EXPECT().
+			GetCoupon(coupon.Code).
+			Return(coupon, nil).
+			AnyTimes()
+	}
+}
+
+func TestEngine_Apply_Rules(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     store.Rule
+		discount uint
+	}{
+		{
+			name:     "percentage",
+			rule:     store.Rule{Type: store.PercentageOff, Percent: 10},
+			discount: 80,
+		},
+		{
+			name:     "fixed amount",
+			rule:     store.Rule{Type: store.FixedAmount, Amount: 150},
+			discount: 150,
+		},
+		{
+			name:     "fixed amount capped by eligible items",
+			rule:     store.Rule{Type: store.FixedAmount, Category: "books", Amount: 500},
+			discount: 200,
+		},
+		{
+			name:     "category scoped percentage",
+			rule:     store.Rule{Type: store.PercentageOff, Category: "games", Percent: 50},
+			discount: 300,
+		},
+		{
+			name:     "buy x get y",
+			rule:     store.Rule{Type: store.BuyXGetY, BuyQuantity: 1, GetQuantity: 1},
+			discount: 300,
+		},
+		{
+			name:     "buy x get y for product",
+			rule:     store.Rule{Type: store.BuyXGetY, ProductID: 2, BuyQuantity: 2, GetQuantity: 1},
+			discount: 200,
+		},
+		{
+			name:     "free item threshold reached",
+			rule:     store.Rule{Type: store.FreeItemThreshold, Threshold: 800, FreeProductID: 1},
+			discount: 100,
+		},
+		{
+			name:     "free item threshold not reached",
+			rule:     store.Rule{Type: store.FreeItemThreshold, Threshold: 801, FreeProductID: 1},
+			discount: 0,
+		},
+		{
+			name:     "free item not in cart",
+			rule:     store.Rule{Type: store.FreeItemThreshold, Threshold: 100, FreeProductID: 3},
+			discount: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockStore := mock_engine.NewMockUnderlyingStore(ctrl)
+			expectCoupons(mockStore, &store.Coupon{Code: "CODE", Rule: test.rule})
+
+			result, err := engine.New(mockStore).Apply(lines, []string{"CODE"})
+
+			g.Expect(err).ShouldNot(HaveOccurred())
+			g.Expect(result.Subtotal).To(Equal(uint(800)))
+			g.Expect(result.Discount).To(Equal(test.discount))
+			g.Expect(result.Total).To(Equal(800 - test.discount))
+		})
+	}
+}
+
+func TestEngine_Apply_Stacking(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStore := mock_engine.NewMockUnderlyingStore(ctrl)
+	expectCoupons(mockStore,
+		&store.Coupon{Code: "STACK1", Stackable: true, Rule: store.Rule{Type: store.FixedAmount, Amount: 100}},
+		&store.Coupon{Code: "STACK2", Stackable: true, Rule: store.Rule{Type: store.FixedAmount, Amount: 150}},
+		&store.Coupon{Code: "ALONE", Rule: store.Rule{Type: store.FixedAmount, Amount: 200}},
+	)
+
+	result, err := engine.New(mockStore).Apply(lines, []string{"STACK1", "ALONE", "STACK2"})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(result.Discount).To(Equal(uint(250)))
+	g.Expect(result.Promotions).To(HaveLen(2))
+}
+
+func TestEngine_Apply_ExclusiveWins(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStore := mock_engine.NewMockUnderlyingStore(ctrl)
+	expectCoupons(mockStore,
+		&store.Coupon{Code: "STACK", Stackable: true, Rule: store.Rule{Type: store.FixedAmount, Amount: 100}},
+		&store.Coupon{Code: "ALONE", Rule: store.Rule{Type: store.PercentageOff, Percent: 50}},
+	)
+
+	result, err := engine.New(mockStore).Apply(lines, []string{"STACK", "ALONE"})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(result.Discount).To(Equal(uint(400)))
+	g.Expect(result.Promotions).To(HaveLen(1))
+	g.Expect(result.Promotions[0].Code).To(Equal("ALONE"))
+}
+
+func TestEngine_Apply_DiscountCappedBySubtotal(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStore := mock_engine.NewMockUnderlyingStore(ctrl)
+	expectCoupons(mockStore,
+		&store.Coupon{Code: "STACK1", Stackable: true, Rule: store.Rule{Type: store.PercentageOff, Percent: 100}},
+		&store.Coupon{Code: "STACK2", Stackable: true, Rule: store.Rule{Type: store.FixedAmount, Amount: 100}},
+	)
+
+	result, err := engine.New(mockStore).Apply(lines, []string{"STACK1", "STACK2"})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(result.Discount).To(Equal(uint(800)))
+	g.Expect(result.Total).To(BeZero())
+}
+
+func TestEngine_Apply_IgnoresUnusableCoupons(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStore := mock_engine.NewMockUnderlyingStore(ctrl)
+	rule := store.Rule{Type: store.FixedAmount, Amount: 100}
+	expectCoupons(mockStore,
+		&store.Coupon{Code: "EXPIRED", Rule: rule, ValidUntil: time.Now().Add(-time.Hour)},
+		&store.Coupon{Code: "FUTURE", Rule: rule, ValidFrom: time.Now().Add(time.Hour)},
+		&store.Coupon{Code: "USED", Rule: rule, UsageLimit: 1, Used: 1},
+	)
+	mockStore.
+		EXPECT().
+		GetCoupon("MISSING").
+		Return(nil, internalStore.NewNotFoundError("coupon", "id", "MISSING"))
+
+	result, err := engine.New(mockStore).Apply(lines, []string{"EXPIRED", "FUTURE", "USED", "MISSING"})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(result.Discount).To(BeZero())
+	g.Expect(result.Promotions).To(BeEmpty())
+}
+
+func TestEngine_Apply_StoreError(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStore := mock_engine.NewMockUnderlyingStore(ctrl)
+	mockStore.
+		EXPECT().
+		GetCoupon("CODE").
+		Return(nil, fmt.Errorf("an error"))
+
+	_, err := engine.New(mockStore).Apply(lines, []string{"CODE"})
+
+	g.Expect(err).Should(HaveOccurred())
+	g.Expect(engine.IsInvalidCouponError(err)).To(BeFalse())
+}
+
+func TestEngine_CheckCoupon(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStore := mock_engine.NewMockUnderlyingStore(ctrl)
+	expectCoupons(mockStore, &store.Coupon{Code: "USED", UsageLimit: 2, Used: 2})
+
+	err := engine.New(mockStore).CheckCoupon("USED")
+
+	g.Expect(err).Should(HaveOccurred())
+	g.Expect(engine.IsInvalidCouponError(err)).To(BeTrue())
+}
+
+func TestEngine_Redeem(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStore := mock_engine.NewMockUnderlyingStore(ctrl)
+	coupon := &store.Coupon{Code: "CODE", UsageLimit: 2, Used: 1, Rule: store.Rule{Type: store.FixedAmount, Amount: 100}}
+	expectCoupons(mockStore, coupon)
+	mockStore.
+		EXPECT().
+		SetCoupon(gomock.Any()).
+		DoAndReturn(func(updated *store.Coupon) error {
+			g.Expect(updated.Used).To(Equal(uint(2)))
+			return nil
+		})
+	mockStore.
+		EXPECT().
+		AddRedemption(gomock.Any()).
+		DoAndReturn(func(redemption *store.Redemption) error {
+			g.Expect(redemption.Code).To(Equal("CODE"))
+			g.Expect(redemption.UserID).To(Equal(userID))
+			g.Expect(redemption.Discount).To(Equal(uint(100)))
+			return nil
+		})
+
+	promotions := engine.New(mockStore)
+	result, err := promotions.Apply(lines, []string{"CODE"})
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	err = promotions.Redeem(userID, result)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(result.Promotions[0].RedemptionID).ShouldNot(BeEmpty())
+	g.Expect(coupon.Used).To(Equal(uint(1)), "the stored coupon should not be modified in place")
+}
+
+func TestEngine_Redeem_ReleasesOnFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStore := mock_engine.NewMockUnderlyingStore(ctrl)
+	rule := store.Rule{Type: store.FixedAmount, Amount: 100}
+	expectCoupons(mockStore,
+		&store.Coupon{Code: "GOOD", Stackable: true, Rule: rule},
+		&store.Coupon{Code: "EXHAUSTED", Stackable: true, Rule: rule, UsageLimit: 1, Used: 1},
+	)
+	mockStore.EXPECT().SetCoupon(gomock.Any()).Return(nil).Times(2)
+	mockStore.EXPECT().AddRedemption(gomock.Any()).Return(nil)
+	mockStore.EXPECT().RemoveRedemption(gomock.Any()).Return(nil)
+
+	result := &engine.Result{
+		Promotions: []*engine.Applied{
+			{Code: "GOOD", Discount: 100},
+			{Code: "EXHAUSTED", Discount: 100},
+		},
+	}
+	err := engine.New(mockStore).Redeem(userID, result)
+
+	g.Expect(err).Should(HaveOccurred())
+	g.Expect(engine.IsInvalidCouponError(err)).To(BeTrue())
+	g.Expect(result.Promotions[0].RedemptionID).To(BeEmpty())
+}
+
+func TestEngine_Apply_LineDiscounts(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStore := mock_engine.NewMockUnderlyingStore(ctrl)
+	expectCoupons(mockStore,
+		&store.Coupon{Code: "BOOKS", Stackable: true, Rule: store.Rule{Type: store.FixedAmount, Category: "books", Amount: 50}},
+		&store.Coupon{Code: "ALL", Stackable: true, Rule: store.Rule{Type: store.FixedAmount, Amount: 101}},
+	)
+
+	result, err := engine.New(mockStore).Apply(lines, []string{"BOOKS", "ALL"})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(result.Discount).To(Equal(uint(151)))
+	// 101 is spread as 25.25 and 75.75, each share being rounded to the nearest unit
+	g.Expect(result.LineDiscounts).To(Equal([]uint{75, 76}))
+}
+
+func TestEngine_Apply_Overflow(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStore := mock_engine.NewMockUnderlyingStore(ctrl)
+
+	_, err := engine.New(mockStore).Apply([]engine.Line{{ProductID: 1, UnitPrice: ^uint(0) / 2, Quantity: 3}}, nil)
+
+	g.Expect(money.IsOverflowError(err)).To(BeTrue())
+}
+
+func TestEngine_Apply_LargeLine(t *testing.T) {
+	line := []engine.Line{{ProductID: 1, UnitPrice: 5000000000, Quantity: 1}}
+	tests := []struct {
+		name     string
+		rule     store.Rule
+		discount uint
+	}{
+		{name: "fixed amount", rule: store.Rule{Type: store.FixedAmount, Amount: 4000000000}, discount: 4000000000},
+		{name: "percentage", rule: store.Rule{Type: store.PercentageOff, Percent: 100}, discount: 5000000000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockStore := mock_engine.NewMockUnderlyingStore(ctrl)
+			expectCoupons(mockStore, &store.Coupon{Code: "BIG", Rule: tt.rule})
+
+			result, err := engine.New(mockStore).Apply(line, []string{"BIG"})
+
+			g.Expect(err).ShouldNot(HaveOccurred())
+			g.Expect(result.Discount).To(Equal(tt.discount))
+			g.Expect(result.LineDiscounts).To(Equal([]uint{tt.discount}))
+			g.Expect(result.Total).To(Equal(5000000000 - tt.discount))
+		})
+	}
+}
+
+func TestEngine_Apply_NearLimit(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStore := mock_engine.NewMockUnderlyingStore(ctrl)
+	expectCoupons(mockStore, &store.Coupon{Code: "HALF", Rule: store.Rule{Type: store.PercentageOff, Percent: 50}})
+	half := ^uint(0) / 2
+	lines := []engine.Line{
+		{ProductID: 1, UnitPrice: half, Quantity: 1},
+		{ProductID: 2, UnitPrice: half - 1, Quantity: 1},
+	}
+
+	result, err := engine.New(mockStore).Apply(lines, []string{"HALF"})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(result.Discount).To(Equal(half))
+	g.Expect(result.LineDiscounts).To(Equal([]uint{half/2 + 1, half / 2}))
+	g.Expect(result.Total).To(Equal(half - 1))
+}
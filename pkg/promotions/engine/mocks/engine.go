@@ -0,0 +1,91 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./engine.go
+
+// Package mock_engine is a generated GoMock package.
+package mock_engine
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	store "github.com/mimatache/go-shop/pkg/promotions/store"
+	reflect "reflect"
+)
+
+// MockUnderlyingStore is a mock of UnderlyingStore interface
+type MockUnderlyingStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockUnderlyingStoreMockRecorder
+}
+
+// MockUnderlyingStoreMockRecorder is the mock recorder for MockUnderlyingStore
+type MockUnderlyingStoreMockRecorder struct {
+	mock *MockUnderlyingStore
+}
+
+// NewMockUnderlyingStore creates a new mock instance
+func NewMockUnderlyingStore(ctrl *gomock.Controller) *MockUnderlyingStore {
+	mock := &MockUnderlyingStore{ctrl: ctrl}
+	mock.recorder = &MockUnderlyingStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockUnderlyingStore) EXPECT() *MockUnderlyingStoreMockRecorder {
+	return m.recorder
+}
+
+// GetCoupon mocks base method
+func (m *MockUnderlyingStore) GetCoupon(code string) (*store.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCoupon", code)
+	ret0, _ := ret[0].(*store.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCoupon indicates an expected call of GetCoupon
+func (mr *MockUnderlyingStoreMockRecorder) GetCoupon(code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCoupon", reflect.TypeOf((*MockUnderlyingStore)(nil).GetCoupon), code)
+}
+
+// SetCoupon mocks base method
+func (m *MockUnderlyingStore) SetCoupon(coupon *store.Coupon) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCoupon", coupon)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCoupon indicates an expected call of SetCoupon
+func (mr *MockUnderlyingStoreMockRecorder) SetCoupon(coupon interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCoupon", reflect.TypeOf((*MockUnderlyingStore)(nil).SetCoupon), coupon)
+}
+
+// AddRedemption mocks base method
+func (m *MockUnderlyingStore) AddRedemption(redemption *store.Redemption) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRedemption", redemption)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRedemption indicates an expected call of AddRedemption
+func (mr *MockUnderlyingStoreMockRecorder) AddRedemption(redemption interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRedemption", reflect.TypeOf((*MockUnderlyingStore)(nil).AddRedemption), redemption)
+}
+
+// RemoveRedemption mocks base method
+func (m *MockUnderlyingStore) RemoveRedemption(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveRedemption", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveRedemption indicates an expected call of RemoveRedemption
+func (mr *MockUnderlyingStoreMockRecorder) RemoveRedemption(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveRedemption", reflect.TypeOf((*MockUnderlyingStore)(nil).RemoveRedemption), id)
+}
@@ -0,0 +1,13 @@
+package promotions
+
+import (
+	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/pkg/promotions/engine"
+	"github.com/mimatache/go-shop/pkg/promotions/store"
+)
+
+// NewAPI instantiates a new promotions engine and storage
+func NewAPI(log logger.Logger, db store.UnderlyingStore) *engine.Engine {
+	coupons := store.New(log, db)
+	return engine.New(coupons)
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+type errors []error
+
+func (e errors) Error() string {
+	b := bytes.NewBufferString("")
+	for _, err := range e {
+		_, _ = fmt.Fprintf(b, "\t%s\n", err)
+	}
+	return b.String()
+}
+
+// Validatable is an item which has to adhere to certain convetions and knows how to check that it is correct
+type Validatable interface {
+	Validate() error
+}
+
+// RuleType identifies how a promotion computes its discount
+type RuleType string
+
+const (
+	// PercentageOff removes a percentage of the price of the eligible items
+	PercentageOff RuleType = "percentage"
+	// FixedAmount removes a fixed amount from the price of the eligible items
+	FixedAmount RuleType = "fixed"
+	// BuyXGetY gives GetQuantity items for free for every BuyQuantity items bought
+	BuyXGetY RuleType = "buy_x_get_y"
+	// FreeItemThreshold gives one FreeProductID item for free once the cart is worth at least Threshold
+	FreeItemThreshold RuleType = "free_item_threshold"
+)
+
+// Rule describes the discount a promotion grants
+type Rule struct {
+	Type RuleType `json:"Type"`
+	// Category restricts the rule to products of the given category. Empty means all products
+	Category string `json:"Category,omitempty"`
+	// ProductID restricts the rule to the given product. 0 means all products
+	ProductID     uint `json:"ProductID,omitempty"`
+	Percent       uint `json:"Percent,omitempty"`
+	Amount        uint `json:"Amount,omitempty"`
+	BuyQuantity   uint `json:"BuyQuantity,omitempty"`
+	GetQuantity   uint `json:"GetQuantity,omitempty"`
+	Threshold     uint `json:"Threshold,omitempty"`
+	FreeProductID uint `json:"FreeProductID,omitempty"`
+}
+
+// Validate checks that a rule has all the values needed for its type
+func (r Rule) Validate() error {
+	var errs errors
+	switch r.Type {
+	case PercentageOff:
+		if r.Percent == 0 || r.Percent > 100 {
+			errs = append(errs, fmt.Errorf("percent must be between 1 and 100"))
+		}
+	case FixedAmount:
+		if r.Amount == 0 {
+			errs = append(errs, fmt.Errorf("amount cannot be 0"))
+		}
+	case BuyXGetY:
+		if r.BuyQuantity == 0 {
+			errs = append(errs, fmt.Errorf("buy quantity cannot be 0"))
+		}
+		if r.GetQuantity == 0 {
+			errs = append(errs, fmt.Errorf("get quantity cannot be 0"))
+		}
+	case FreeItemThreshold:
+		if r.FreeProductID == 0 {
+			errs = append(errs, fmt.Errorf("free product ID cannot be 0"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown rule type %q", r.Type))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Coupon models a promotion that is unlocked by a code
+type Coupon struct {
+	Code        string `json:"Code"`
+	Description string `json:"Description"`
+	Rule        Rule   `json:"Rule"`
+	// Stackable coupons can be combined with other stackable coupons
+	Stackable bool `json:"Stackable"`
+	// UsageLimit is the number of times this coupon can be redeemed. 0 means unlimited
+	UsageLimit uint      `json:"UsageLimit"`
+	Used       uint      `json:"Used"`
+	ValidFrom  time.Time `json:"ValidFrom"`
+	ValidUntil time.Time `json:"ValidUntil"`
+}
+
+// IsActive checks if the coupon can be used at the given moment
+func (c *Coupon) IsActive(at time.Time) bool {
+	if !c.ValidFrom.IsZero() && at.Before(c.ValidFrom) {
+		return false
+	}
+	if !c.ValidUntil.IsZero() && at.After(c.ValidUntil) {
+		return false
+	}
+	return true
+}
+
+// IsExhausted checks if the coupon reached its usage limit
+func (c *Coupon) IsExhausted() bool {
+	return c.UsageLimit != 0 && c.Used >= c.UsageLimit
+}
+
+// Validate checks that a coupon adheres to constraints
+func (c *Coupon) Validate() error {
+	var errs errors
+	if c.Code == "" {
+		errs = append(errs, fmt.Errorf("code is mandatory"))
+	}
+	if err := c.Rule.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if !c.ValidFrom.IsZero() && !c.ValidUntil.IsZero() && c.ValidUntil.Before(c.ValidFrom) {
+		errs = append(errs, fmt.Errorf("coupon cannot expire before it becomes valid"))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Redemption records a coupon being used by a user
+type Redemption struct {
+	ID       string    `json:"ID"`
+	Code     string    `json:"Code"`
+	UserID   string    `json:"UserID"`
+	Discount uint      `json:"Discount"`
+	Time     time.Time `json:"Time"`
+}
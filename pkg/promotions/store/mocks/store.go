@@ -0,0 +1,236 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./store.go
+
+// Package mock_store is a generated GoMock package.
+package mock_store
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	store "github.com/mimatache/go-shop/pkg/promotions/store"
+	reflect "reflect"
+)
+
+// Mocklogger is a mock of logger interface
+type Mocklogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockloggerMockRecorder
+}
+
+// MockloggerMockRecorder is the mock recorder for Mocklogger
+type MockloggerMockRecorder struct {
+	mock *Mocklogger
+}
+
+// NewMocklogger creates a new mock instance
+func NewMocklogger(ctrl *gomock.Controller) *Mocklogger {
+	mock := &Mocklogger{ctrl: ctrl}
+	mock.recorder = &MockloggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *Mocklogger) EXPECT() *MockloggerMockRecorder {
+	return m.recorder
+}
+
+// Infof mocks base method
+func (m *Mocklogger) Infof(msg string, args ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Infof", varargs...)
+}
+
+// Infof indicates an expected call of Infof
+func (mr *MockloggerMockRecorder) Infof(msg interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Infof", reflect.TypeOf((*Mocklogger)(nil).Infof), varargs...)
+}
+
+// Debugf mocks base method
+func (m *Mocklogger) Debugf(msg string, args ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Debugf", varargs...)
+}
+
+// Debugf indicates an expected call of Debugf
+func (mr *MockloggerMockRecorder) Debugf(msg interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Debugf", reflect.TypeOf((*Mocklogger)(nil).Debugf), varargs...)
+}
+
+// Debugw mocks base method
+func (m *Mocklogger) Debugw(msg string, keysAndValues ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range keysAndValues {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Debugw", varargs...)
+}
+
+// Debugw indicates an expected call of Debugw
+func (mr *MockloggerMockRecorder) Debugw(msg interface{}, keysAndValues ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, keysAndValues...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Debugw", reflect.TypeOf((*Mocklogger)(nil).Debugw), varargs...)
+}
+
+// MockUnderlyingStore is a mock of UnderlyingStore interface
+type MockUnderlyingStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockUnderlyingStoreMockRecorder
+}
+
+// MockUnderlyingStoreMockRecorder is the mock recorder for MockUnderlyingStore
+type MockUnderlyingStoreMockRecorder struct {
+	mock *MockUnderlyingStore
+}
+
+// NewMockUnderlyingStore creates a new mock instance
+func NewMockUnderlyingStore(ctrl *gomock.Controller) *MockUnderlyingStore {
+	mock := &MockUnderlyingStore{ctrl: ctrl}
+	mock.recorder = &MockUnderlyingStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockUnderlyingStore) EXPECT() *MockUnderlyingStoreMockRecorder {
+	return m.recorder
+}
+
+// Read mocks base method
+func (m *MockUnderlyingStore) Read(table, key string, value interface{}) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", table, key, value)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read
+func (mr *MockUnderlyingStoreMockRecorder) Read(table, key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockUnderlyingStore)(nil).Read), table, key, value)
+}
+
+// Write mocks base method
+func (m *MockUnderlyingStore) Write(table string, value ...interface{}) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{table}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Write", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Write indicates an expected call of Write
+func (mr *MockUnderlyingStoreMockRecorder) Write(table interface{}, value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{table}, value...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockUnderlyingStore)(nil).Write), varargs...)
+}
+
+// Remove mocks base method
+func (m *MockUnderlyingStore) Remove(table, key string, value interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", table, key, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove
+func (mr *MockUnderlyingStoreMockRecorder) Remove(table, key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockUnderlyingStore)(nil).Remove), table, key, value)
+}
+
+// MockPromotionStore is a mock of PromotionStore interface
+type MockPromotionStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockPromotionStoreMockRecorder
+}
+
+// MockPromotionStoreMockRecorder is the mock recorder for MockPromotionStore
+type MockPromotionStoreMockRecorder struct {
+	mock *MockPromotionStore
+}
+
+// NewMockPromotionStore creates a new mock instance
+func NewMockPromotionStore(ctrl *gomock.Controller) *MockPromotionStore {
+	mock := &MockPromotionStore{ctrl: ctrl}
+	mock.recorder = &MockPromotionStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPromotionStore) EXPECT() *MockPromotionStoreMockRecorder {
+	return m.recorder
+}
+
+// GetCoupon mocks base method
+func (m *MockPromotionStore) GetCoupon(code string) (*store.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCoupon", code)
+	ret0, _ := ret[0].(*store.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCoupon indicates an expected call of GetCoupon
+func (mr *MockPromotionStoreMockRecorder) GetCoupon(code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCoupon", reflect.TypeOf((*MockPromotionStore)(nil).GetCoupon), code)
+}
+
+// SetCoupon mocks base method
+func (m *MockPromotionStore) SetCoupon(coupon *store.Coupon) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCoupon", coupon)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCoupon indicates an expected call of SetCoupon
+func (mr *MockPromotionStoreMockRecorder) SetCoupon(coupon interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCoupon", reflect.TypeOf((*MockPromotionStore)(nil).SetCoupon), coupon)
+}
+
+// AddRedemption mocks base method
+func (m *MockPromotionStore) AddRedemption(redemption *store.Redemption) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRedemption", redemption)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRedemption indicates an expected call of AddRedemption
+func (mr *MockPromotionStoreMockRecorder) AddRedemption(redemption interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRedemption", reflect.TypeOf((*MockPromotionStore)(nil).AddRedemption), redemption)
+}
+
+// RemoveRedemption mocks base method
+func (m *MockPromotionStore) RemoveRedemption(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveRedemption", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveRedemption indicates an expected call of RemoveRedemption
+func (mr *MockPromotionStoreMockRecorder) RemoveRedemption(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveRedemption", reflect.TypeOf((*MockPromotionStore)(nil).RemoveRedemption), id)
+}
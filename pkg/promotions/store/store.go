@@ -0,0 +1,237 @@
+package store
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/mimatache/go-shop/internal/store"
+)
+
+//go:generate mockgen -source ./store.go -destination mocks/store.go
+
+type logger interface {
+	Infof(msg string, args ...interface{})
+	Debugf(msg string, args ...interface{})
+	Debugw(msg string, keysAndValues ...interface{})
+}
+
+var (
+	table           = &CouponTable{name: "coupon"}
+	redemptionTable = &RedemptionTable{name: "couponRedemption"}
+)
+
+// GetTable returns the coupon table for the schema
+func GetTable() *CouponTable {
+	return table
+}
+
+// GetRedemptionTable returns the coupon redemption table for the schema
+func GetRedemptionTable() *RedemptionTable {
+	return redemptionTable
+}
+
+// CouponTable represents the coupon table in the DB
+type CouponTable struct {
+	name string
+}
+
+// GetName returns the name of the coupon table
+func (c *CouponTable) GetName() string {
+	return c.name
+}
+
+// GetTableSchema returns the schema for the coupon table
+func (c *CouponTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: c.name,
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:    "id",
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "Code"},
+			},
+		},
+	}
+}
+
+// RedemptionTable represents the coupon redemption table in the DB
+type RedemptionTable struct {
+	name string
+}
+
+// GetName returns the name of the coupon redemption table
+func (r *RedemptionTable) GetName() string {
+	return r.name
+}
+
+// GetTableSchema returns the schema for the coupon redemption table
+func (r *RedemptionTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: r.name,
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:    "id",
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "ID"},
+			},
+			"code": {
+				Name:    "code",
+				Unique:  false,
+				Indexer: &memdb.StringFieldIndex{Field: "Code"},
+			},
+			"user": {
+				Name:    "user",
+				Unique:  false,
+				Indexer: &memdb.StringFieldIndex{Field: "UserID"},
+			},
+		},
+	}
+}
+
+// GetPersonalRows returns the coupon redemptions of the user
+func (r *RedemptionTable) GetPersonalRows(db store.Reader, userID string) ([]interface{}, error) {
+	return db.ReadAll(r.name, "user", userID)
+}
+
+// Export returns the coupon redemption
+func (r *RedemptionTable) Export(row interface{}) interface{} {
+	return row
+}
+
+// Anonymize keeps the coupon redemption for the pseudonym, since it counts towards the usage limit of the coupon
+func (r *RedemptionTable) Anonymize(row interface{}, userID string, pseudonym string) (interface{}, error) {
+	anonymized := *row.(*Redemption)
+	anonymized.UserID = pseudonym
+	return &anonymized, nil
+}
+
+// UnderlyingStore represents the interface that the DB should implement to be usable
+type UnderlyingStore interface {
+	Read(table string, key string, value interface{}) (interface{}, error)
+	Write(table string, value ...interface{}) error
+	Remove(table string, key string, value interface{}) error
+}
+
+// LoadSeeds write the seed information to the DB.
+func LoadSeeds(seed io.Reader, db UnderlyingStore) error {
+	var coupons []*Coupon
+
+	err := json.NewDecoder(seed).Decode(&coupons)
+	if err != nil {
+		return err
+	}
+	for _, coupon := range coupons {
+		if err = coupon.Validate(); err != nil {
+			return err
+		}
+		err = db.Write(table.GetName(), coupon)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// New returns a new instance of PromotionStore
+func New(log logger, db UnderlyingStore) PromotionStore {
+	return &promotionLogger{
+		log:  log,
+		next: &promotionStore{db: db},
+	}
+}
+
+// PromotionStore models the coupon DB
+type PromotionStore interface {
+	GetCoupon(code string) (*Coupon, error)
+	SetCoupon(coupon *Coupon) error
+	AddRedemption(redemption *Redemption) error
+	RemoveRedemption(id string) error
+}
+
+type promotionStore struct {
+	db UnderlyingStore
+}
+
+// GetCoupon returns the coupon with the given code
+func (p *promotionStore) GetCoupon(code string) (*Coupon, error) {
+	raw, err := p.db.Read(table.GetName(), "id", code)
+	if err != nil {
+		return nil, err
+	}
+	return raw.(*Coupon), nil
+}
+
+// SetCoupon inserts or updates a coupon
+func (p *promotionStore) SetCoupon(coupon *Coupon) error {
+	return p.db.Write(table.GetName(), coupon)
+}
+
+// AddRedemption records a coupon redemption
+func (p *promotionStore) AddRedemption(redemption *Redemption) error {
+	return p.db.Write(redemptionTable.GetName(), redemption)
+}
+
+// RemoveRedemption deletes a coupon redemption
+func (p *promotionStore) RemoveRedemption(id string) error {
+	return p.db.Remove(redemptionTable.GetName(), "id", id)
+}
+
+type promotionLogger struct {
+	log  logger
+	next PromotionStore
+}
+
+func (p *promotionLogger) GetCoupon(code string) (*Coupon, error) {
+	var err error
+	var coupon *Coupon
+	defer func() {
+		if err != nil {
+			p.log.Debugw("error occurred when retrieving coupon", "code", code, "err", err)
+			return
+		}
+		p.log.Debugf("Retrieved coupon %s", code)
+	}()
+	coupon, err = p.next.GetCoupon(code)
+	return coupon, err
+}
+
+func (p *promotionLogger) SetCoupon(coupon *Coupon) error {
+	var err error
+	defer func() {
+		if err != nil {
+			p.log.Debugw("error occurred when setting coupon", "code", coupon.Code, "err", err)
+			return
+		}
+		p.log.Debugw("Coupon successfully updated", "code", coupon.Code, "used", coupon.Used)
+	}()
+	err = p.next.SetCoupon(coupon)
+	return err
+}
+
+func (p *promotionLogger) AddRedemption(redemption *Redemption) error {
+	var err error
+	defer func() {
+		if err != nil {
+			p.log.Debugw("error occurred when recording redemption", "code", redemption.Code, "user", redemption.UserID, "err", err)
+			return
+		}
+		p.log.Debugw("Redemption recorded", "code", redemption.Code, "user", redemption.UserID, "discount", redemption.Discount)
+	}()
+	err = p.next.AddRedemption(redemption)
+	return err
+}
+
+func (p *promotionLogger) RemoveRedemption(id string) error {
+	var err error
+	defer func() {
+		if err != nil {
+			p.log.Debugw("error occurred when removing redemption", "id", id, "err", err)
+			return
+		}
+		p.log.Debugf("Removed redemption %s", id)
+	}()
+	err = p.next.RemoveRedemption(id)
+	return err
+}
@@ -0,0 +1,123 @@
+package rates
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+type errors []error
+
+func (e errors) Error() string {
+	b := bytes.NewBufferString("")
+	for _, err := range e {
+		_, _ = fmt.Fprintf(b, "\t%s\n", err)
+	}
+	return b.String()
+}
+
+// MethodType identifies how the cost of a shipping method is computed
+type MethodType string
+
+const (
+	// FlatRate costs the same for any parcel
+	FlatRate MethodType = "flat"
+	// WeightBased costs a base price plus a price for every started kilogram
+	WeightBased MethodType = "weight"
+	// FreeOverThreshold costs a flat price, unless the parcel is worth at least the threshold
+	FreeOverThreshold MethodType = "free_over_threshold"
+)
+
+// gramsPerKilogram is used to convert the parcel weight for weight based methods
+const gramsPerKilogram = 1000
+
+// Method is a way of delivering parcels within a zone
+type Method struct {
+	ID   string     `json:"ID"`
+	Name string     `json:"Name"`
+	Type MethodType `json:"Type"`
+	// Price is the flat price, or the base price for weight based methods
+	Price        uint `json:"Price"`
+	PerKilogram  uint `json:"PerKilogram,omitempty"`
+	Threshold    uint `json:"Threshold,omitempty"`
+	MaxWeight    uint `json:"MaxWeight,omitempty"`
+	DeliveryDays uint `json:"DeliveryDays,omitempty"`
+}
+
+// Validate checks that a method has all the values needed for its type
+func (m Method) Validate() error {
+	var errs errors
+	if m.ID == "" {
+		errs = append(errs, fmt.Errorf("shipping method ID is mandatory"))
+	}
+	switch m.Type {
+	case FlatRate:
+	case WeightBased:
+		if m.PerKilogram == 0 {
+			errs = append(errs, fmt.Errorf("weight based method %s needs a price per kilogram", m.ID))
+		}
+	case FreeOverThreshold:
+		if m.Threshold == 0 {
+			errs = append(errs, fmt.Errorf("method %s needs a threshold", m.ID))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown shipping method type %q", m.Type))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Zone groups the regions that share the same shipping methods
+type Zone struct {
+	Name    string   `json:"Name"`
+	Regions []string `json:"Regions"`
+	Methods []Method `json:"Methods"`
+}
+
+// Config holds the shipping zones
+type Config struct {
+	Zones []Zone `json:"Zones"`
+}
+
+// Validate checks that a configuration adheres to constraints
+func (c *Config) Validate() error {
+	var errs errors
+	regions := map[string]string{}
+	for _, zone := range c.Zones {
+		for _, region := range zone.Regions {
+			if other, ok := regions[region]; ok {
+				errs = append(errs, fmt.Errorf("region %s is part of both %s and %s zones", region, other, zone.Name))
+			}
+			regions[region] = zone.Name
+		}
+		methods := map[string]struct{}{}
+		for _, method := range zone.Methods {
+			if err := method.Validate(); err != nil {
+				errs = append(errs, err)
+			}
+			if _, ok := methods[method.ID]; ok {
+				errs = append(errs, fmt.Errorf("duplicate method %s in zone %s", method.ID, zone.Name))
+			}
+			methods[method.ID] = struct{}{}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// LoadConfig reads a shipping configuration
+func LoadConfig(r io.Reader) (*Config, error) {
+	config := &Config{}
+	if err := json.NewDecoder(r).Decode(config); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
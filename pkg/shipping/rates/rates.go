@@ -0,0 +1,124 @@
+package rates
+
+import (
+	"fmt"
+)
+
+type unavailable struct {
+	msg string
+}
+
+func (u unavailable) Error() string {
+	return u.msg
+}
+
+// IsUnavailableError verifies if a given error refers to a shipping method that cannot be used
+func IsUnavailableError(err error) bool {
+	switch err.(type) {
+	case unavailable:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewUnavailable creates a new unavailable shipping error
+func NewUnavailable(format string, args ...interface{}) error {
+	return unavailable{msg: fmt.Sprintf(format, args...)}
+}
+
+// Parcel describes what has to be shipped
+type Parcel struct {
+	// Weight is given in grams
+	Weight uint
+	// Value is what the customer pays for the parcel contents
+	Value uint
+}
+
+// Quote is the cost of shipping a parcel with a given method
+type Quote struct {
+	MethodID     string `json:"methodID"`
+	Name         string `json:"name"`
+	Zone         string `json:"zone"`
+	Cost         uint   `json:"cost"`
+	DeliveryDays uint   `json:"deliveryDays,omitempty"`
+}
+
+// New returns a calculator for the shipping zones in the configuration
+func New(config *Config) *Calculator {
+	zones := map[string]*Zone{}
+	for i := range config.Zones {
+		for _, region := range config.Zones[i].Regions {
+			zones[region] = &config.Zones[i]
+		}
+	}
+	return &Calculator{zones: zones}
+}
+
+// Calculator computes shipping costs
+type Calculator struct {
+	zones map[string]*Zone
+}
+
+// Quote returns the cost of every method that can ship the parcel to the region
+func (c *Calculator) Quote(region string, parcel Parcel) ([]*Quote, error) {
+	zone, ok := c.zones[region]
+	if !ok {
+		return nil, NewUnavailable("no shipping available to region %s", region)
+	}
+	quotes := []*Quote{}
+	for _, method := range zone.Methods {
+		if !canShip(method, parcel) {
+			continue
+		}
+		quotes = append(quotes, quote(zone, method, parcel))
+	}
+	return quotes, nil
+}
+
+// Cost returns the cost of shipping the parcel to the region with the given method
+func (c *Calculator) Cost(region string, methodID string, parcel Parcel) (*Quote, error) {
+	zone, ok := c.zones[region]
+	if !ok {
+		return nil, NewUnavailable("no shipping available to region %s", region)
+	}
+	for _, method := range zone.Methods {
+		if method.ID != methodID {
+			continue
+		}
+		if !canShip(method, parcel) {
+			return nil, NewUnavailable("shipping method %s cannot deliver parcels of %d grams", methodID, parcel.Weight)
+		}
+		return quote(zone, method, parcel), nil
+	}
+	return nil, NewUnavailable("shipping method %s is not available in region %s", methodID, region)
+}
+
+func canShip(method Method, parcel Parcel) bool {
+	return method.MaxWeight == 0 || parcel.Weight <= method.MaxWeight
+}
+
+func quote(zone *Zone, method Method, parcel Parcel) *Quote {
+	return &Quote{
+		MethodID:     method.ID,
+		Name:         method.Name,
+		Zone:         zone.Name,
+		Cost:         cost(method, parcel),
+		DeliveryDays: method.DeliveryDays,
+	}
+}
+
+func cost(method Method, parcel Parcel) uint {
+	switch method.Type {
+	case WeightBased:
+		kilograms := (parcel.Weight + gramsPerKilogram - 1) / gramsPerKilogram
+		return method.Price + kilograms*method.PerKilogram
+	case FreeOverThreshold:
+		if parcel.Value >= method.Threshold {
+			return 0
+		}
+		return method.Price
+	default:
+		return method.Price
+	}
+}
@@ -0,0 +1,104 @@
+package rates_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/pkg/shipping/rates"
+)
+
+var (
+	config = &rates.Config{
+		Zones: []rates.Zone{
+			{
+				Name:    "domestic",
+				Regions: []string{"RO"},
+				Methods: []rates.Method{
+					{ID: "standard", Type: rates.FreeOverThreshold, Price: 50, Threshold: 1000},
+					{ID: "courier", Type: rates.WeightBased, Price: 30, PerKilogram: 10},
+					{ID: "letter", Type: rates.FlatRate, Price: 5, MaxWeight: 100},
+				},
+			},
+			{
+				Name:    "europe",
+				Regions: []string{"DE", "FR"},
+				Methods: []rates.Method{
+					{ID: "standard", Type: rates.FlatRate, Price: 120},
+				},
+			},
+		},
+	}
+)
+
+func TestCalculator_Quote(t *testing.T) {
+	g := NewWithT(t)
+
+	quotes, err := rates.New(config).Quote("RO", rates.Parcel{Weight: 2500, Value: 400})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(quotes).To(HaveLen(2), "parcel is too heavy to be sent as a letter")
+	g.Expect(quotes[0].Cost).To(Equal(uint(50)))
+	g.Expect(quotes[1].Cost).To(Equal(uint(60)), "weight based costs should round up to the next kilogram")
+}
+
+func TestCalculator_Quote_UnknownRegion(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := rates.New(config).Quote("US", rates.Parcel{Weight: 100, Value: 400})
+
+	g.Expect(err).Should(HaveOccurred())
+	g.Expect(rates.IsUnavailableError(err)).To(BeTrue())
+}
+
+func TestCalculator_Cost(t *testing.T) {
+	tests := []struct {
+		name   string
+		region string
+		method string
+		parcel rates.Parcel
+		cost   uint
+	}{
+		{name: "flat", region: "FR", method: "standard", parcel: rates.Parcel{Weight: 10000, Value: 10}, cost: 120},
+		{name: "under threshold", region: "RO", method: "standard", parcel: rates.Parcel{Value: 999}, cost: 50},
+		{name: "over threshold", region: "RO", method: "standard", parcel: rates.Parcel{Value: 1000}, cost: 0},
+		{name: "weight based", region: "RO", method: "courier", parcel: rates.Parcel{Weight: 1000}, cost: 40},
+		{name: "weightless", region: "RO", method: "courier", parcel: rates.Parcel{}, cost: 30},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			quote, err := rates.New(config).Cost(test.region, test.method, test.parcel)
+
+			g.Expect(err).ShouldNot(HaveOccurred())
+			g.Expect(quote.Cost).To(Equal(test.cost))
+		})
+	}
+}
+
+func TestCalculator_Cost_Unavailable(t *testing.T) {
+	g := NewWithT(t)
+
+	calculator := rates.New(config)
+
+	_, err := calculator.Cost("DE", "courier", rates.Parcel{})
+	g.Expect(rates.IsUnavailableError(err)).To(BeTrue())
+
+	_, err = calculator.Cost("RO", "letter", rates.Parcel{Weight: 101})
+	g.Expect(rates.IsUnavailableError(err)).To(BeTrue())
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := rates.LoadConfig(strings.NewReader(`{
+		"Zones": [
+			{"Name": "a", "Regions": ["RO"], "Methods": [{"ID": "courier", "Type": "weight", "Price": 10}]},
+			{"Name": "b", "Regions": ["RO"], "Methods": [{"ID": "pigeon", "Type": "bird"}]}
+		]
+	}`))
+
+	g.Expect(err).Should(HaveOccurred())
+}
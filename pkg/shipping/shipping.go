@@ -0,0 +1,18 @@
+package shipping
+
+import (
+	"io"
+
+	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/pkg/shipping/rates"
+)
+
+// NewAPI instantiates the shipping rate calculator from the given zone configuration
+func NewAPI(log logger.Logger, config io.Reader) (*rates.Calculator, error) {
+	shippingConfig, err := rates.LoadConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Loaded %d shipping zones", len(shippingConfig.Zones))
+	return rates.New(shippingConfig), nil
+}
@@ -0,0 +1,172 @@
+package calculator
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/mimatache/go-shop/internal/money"
+)
+
+// basisPointsPerUnit is the number of basis points that make up a rate of 100%
+const basisPointsPerUnit = 10000
+
+type unknownRate struct {
+	msg string
+}
+
+func (u unknownRate) Error() string {
+	return u.msg
+}
+
+// IsUnknownRateError verifies if a given error refers to a missing tax rate
+func IsUnknownRateError(err error) bool {
+	switch err.(type) {
+	case unknownRate:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewUnknownRate creates a new unknown rate error
+func NewUnknownRate(region, class string) error {
+	return unknownRate{msg: fmt.Sprintf("no tax rate defined for class %s in region %s", class, region)}
+}
+
+// Calculator computes the taxes owed for a set of lines
+type Calculator interface {
+	Calculate(region string, lines []Line) (*Breakdown, error)
+}
+
+// Line is the amount paid for a product, after any discount was applied
+type Line struct {
+	ProductID uint
+	Class     string
+	Amount    uint
+}
+
+// LineTax is the tax owed for a single line
+type LineTax struct {
+	ProductID   uint   `json:"productID"`
+	Class       string `json:"class"`
+	BasisPoints uint   `json:"basisPoints"`
+	Net         uint   `json:"net"`
+	Tax         uint   `json:"tax"`
+	Gross       uint   `json:"gross"`
+}
+
+// Breakdown details the taxes owed for a set of lines
+type Breakdown struct {
+	Region   string     `json:"region"`
+	Mode     Mode       `json:"mode"`
+	Rounding Rounding   `json:"rounding"`
+	Lines    []*LineTax `json:"lines"`
+	Net      uint       `json:"net"`
+	Tax      uint       `json:"tax"`
+	Gross    uint       `json:"gross"`
+}
+
+// NewTable returns a calculator that uses the rates in the given configuration
+func NewTable(config *Config) *Table {
+	rates := map[string]uint{}
+	for _, rate := range config.Rates {
+		rates[rateKey(rate.Region, rate.Class)] = rate.BasisPoints
+	}
+	return &Table{
+		config: config,
+		rates:  rates,
+	}
+}
+
+// Table computes taxes based on a table of rates per region and tax class
+type Table struct {
+	config *Config
+	rates  map[string]uint
+}
+
+// Calculate computes the taxes owed in the region for the given lines. If no region is given, the default one is used.
+// When rounding per order, the taxes shown for each line are rounded but the total is computed from the exact values
+func (t *Table) Calculate(region string, lines []Line) (*Breakdown, error) {
+	if region == "" {
+		region = t.config.DefaultRegion
+	}
+	breakdown := &Breakdown{
+		Region:   region,
+		Mode:     t.config.Mode,
+		Rounding: t.config.Rounding,
+		Lines:    []*LineTax{},
+	}
+	exactTax := new(big.Rat)
+	var amount uint
+	for _, line := range lines {
+		class := line.Class
+		if class == "" {
+			class = DefaultClass
+		}
+		basisPoints, ok := t.rates[rateKey(region, class)]
+		if !ok {
+			return nil, NewUnknownRate(region, class)
+		}
+		tax := lineTax(line.Amount, basisPoints, t.config.Mode)
+		exactTax.Add(exactTax, tax)
+		taxed := &LineTax{
+			ProductID:   line.ProductID,
+			Class:       class,
+			BasisPoints: basisPoints,
+			Tax:         round(tax),
+		}
+		var err error
+		if taxed.Net, taxed.Gross, err = split(line.Amount, taxed.Tax, t.config.Mode); err != nil {
+			return nil, err
+		}
+		breakdown.Lines = append(breakdown.Lines, taxed)
+		if amount, err = money.Add(amount, line.Amount); err != nil {
+			return nil, err
+		}
+		if t.config.Rounding == PerLine {
+			if breakdown.Tax, err = money.Add(breakdown.Tax, taxed.Tax); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if t.config.Rounding == PerOrder {
+		breakdown.Tax = round(exactTax)
+	}
+	var err error
+	if breakdown.Net, breakdown.Gross, err = split(amount, breakdown.Tax, t.config.Mode); err != nil {
+		return nil, err
+	}
+	return breakdown, nil
+}
+
+func rateKey(region, class string) string {
+	return region + "/" + class
+}
+
+// lineTax returns the exact tax owed for an amount
+func lineTax(amount, basisPoints uint, mode Mode) *big.Rat {
+	numerator := new(big.Int).Mul(new(big.Int).SetUint64(uint64(amount)), new(big.Int).SetUint64(uint64(basisPoints)))
+	denominator := big.NewInt(basisPointsPerUnit)
+	if mode == Inclusive {
+		denominator.Add(denominator, new(big.Int).SetUint64(uint64(basisPoints)))
+	}
+	return new(big.Rat).SetFrac(numerator, denominator)
+}
+
+// split returns the net and gross values of an amount depending on whether it includes the tax or not
+func split(amount, tax uint, mode Mode) (uint, uint, error) {
+	if mode == Inclusive {
+		net, err := money.Sub(amount, tax)
+		return net, amount, err
+	}
+	gross, err := money.Add(amount, tax)
+	return amount, gross, err
+}
+
+// round rounds a non negative value to the closest integer, with halves rounded up
+func round(value *big.Rat) uint {
+	numerator := new(big.Int).Mul(value.Num(), big.NewInt(2))
+	numerator.Add(numerator, value.Denom())
+	denominator := new(big.Int).Mul(value.Denom(), big.NewInt(2))
+	return uint(new(big.Int).Quo(numerator, denominator).Uint64())
+}
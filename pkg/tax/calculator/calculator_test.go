@@ -0,0 +1,144 @@
+package calculator_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/money"
+	"github.com/mimatache/go-shop/pkg/tax/calculator"
+)
+
+var (
+	rates = []calculator.Rate{
+		{Region: "RO", Class: "standard", BasisPoints: 1900},
+		{Region: "RO", Class: "reduced", BasisPoints: 500},
+		{Region: "DE", Class: "standard", BasisPoints: 1900},
+	}
+
+	lines = []calculator.Line{
+		{ProductID: 1, Class: "reduced", Amount: 110},
+		{ProductID: 2, Amount: 110},
+		{ProductID: 3, Class: "standard", Amount: 110},
+	}
+)
+
+func newTable(mode calculator.Mode, rounding calculator.Rounding) *calculator.Table {
+	return calculator.NewTable(&calculator.Config{
+		DefaultRegion: "RO",
+		Mode:          mode,
+		Rounding:      rounding,
+		Rates:         rates,
+	})
+}
+
+func TestTable_Exclusive_PerLine(t *testing.T) {
+	g := NewWithT(t)
+
+	breakdown, err := newTable(calculator.Exclusive, calculator.PerLine).Calculate("RO", lines)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	// 5.5 rounds to 6 and 20.9 rounds to 21
+	g.Expect(breakdown.Lines[0].Tax).To(Equal(uint(6)))
+	g.Expect(breakdown.Lines[1].Tax).To(Equal(uint(21)))
+	g.Expect(breakdown.Lines[1].Class).To(Equal(calculator.DefaultClass))
+	g.Expect(breakdown.Tax).To(Equal(uint(48)))
+	g.Expect(breakdown.Net).To(Equal(uint(330)))
+	g.Expect(breakdown.Gross).To(Equal(uint(378)))
+}
+
+func TestTable_Exclusive_PerOrder(t *testing.T) {
+	g := NewWithT(t)
+
+	breakdown, err := newTable(calculator.Exclusive, calculator.PerOrder).Calculate("RO", lines)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	// 5.5 + 20.9 + 20.9 = 47.3
+	g.Expect(breakdown.Tax).To(Equal(uint(47)))
+	g.Expect(breakdown.Net).To(Equal(uint(330)))
+	g.Expect(breakdown.Gross).To(Equal(uint(377)))
+}
+
+func TestTable_Inclusive(t *testing.T) {
+	g := NewWithT(t)
+
+	breakdown, err := newTable(calculator.Inclusive, calculator.PerLine).Calculate("RO", []calculator.Line{
+		{ProductID: 1, Class: "standard", Amount: 119},
+	})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(breakdown.Tax).To(Equal(uint(19)))
+	g.Expect(breakdown.Net).To(Equal(uint(100)))
+	g.Expect(breakdown.Gross).To(Equal(uint(119)))
+	g.Expect(breakdown.Lines[0].Net).To(Equal(uint(100)))
+}
+
+func TestTable_DefaultRegion(t *testing.T) {
+	g := NewWithT(t)
+
+	breakdown, err := newTable(calculator.Exclusive, calculator.PerLine).Calculate("", lines)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(breakdown.Region).To(Equal("RO"))
+}
+
+func TestTable_UnknownRate(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := newTable(calculator.Exclusive, calculator.PerLine).Calculate("DE", lines)
+
+	g.Expect(err).Should(HaveOccurred())
+	g.Expect(calculator.IsUnknownRateError(err)).To(BeTrue())
+}
+
+func TestTable_Overflow(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := newTable(calculator.Exclusive, calculator.PerLine).Calculate("RO", []calculator.Line{
+		{ProductID: 1, Amount: ^uint(0) / 2},
+		{ProductID: 2, Amount: ^uint(0) / 2},
+	})
+
+	g.Expect(money.IsOverflowError(err)).To(BeTrue())
+}
+
+func TestStub_NoTax(t *testing.T) {
+	g := NewWithT(t)
+
+	breakdown, err := calculator.NewStub().Calculate("RO", lines)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(breakdown.Tax).To(BeZero())
+	g.Expect(breakdown.Gross).To(Equal(uint(330)))
+}
+
+func TestLoadConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	config, err := calculator.LoadConfig(strings.NewReader(`{
+		"DefaultRegion": "RO",
+		"Mode": "inclusive",
+		"Rounding": "order",
+		"Rates": [{"Region": "RO", "Class": "standard", "BasisPoints": 1900}]
+	}`))
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(config.Mode).To(Equal(calculator.Inclusive))
+	g.Expect(config.Rates).To(HaveLen(1))
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := calculator.LoadConfig(strings.NewReader(`{
+		"Mode": "sometimes",
+		"Rounding": "order",
+		"Rates": [
+			{"Region": "RO", "Class": "standard", "BasisPoints": 1900},
+			{"Region": "RO", "Class": "standard", "BasisPoints": 900}
+		]
+	}`))
+
+	g.Expect(err).Should(HaveOccurred())
+}
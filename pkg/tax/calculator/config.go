@@ -0,0 +1,96 @@
+package calculator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+type errors []error
+
+func (e errors) Error() string {
+	b := bytes.NewBufferString("")
+	for _, err := range e {
+		_, _ = fmt.Fprintf(b, "\t%s\n", err)
+	}
+	return b.String()
+}
+
+// Mode tells whether the product prices already contain the tax
+type Mode string
+
+const (
+	// Exclusive prices do not contain the tax, which is added on top of them
+	Exclusive Mode = "exclusive"
+	// Inclusive prices already contain the tax
+	Inclusive Mode = "inclusive"
+)
+
+// Rounding tells when the tax amounts are rounded to whole units
+type Rounding string
+
+const (
+	// PerLine rounds the tax of every line and sums the rounded values
+	PerLine Rounding = "line"
+	// PerOrder sums the exact taxes of all the lines and rounds only the total
+	PerOrder Rounding = "order"
+)
+
+// DefaultClass is the tax class used for products that do not specify one
+const DefaultClass = "standard"
+
+// Rate is the tax rate, in basis points, applied to a tax class in a region
+type Rate struct {
+	Region string `json:"Region"`
+	Class  string `json:"Class"`
+	// BasisPoints is the rate expressed in hundredths of a percent. 1900 means 19%
+	BasisPoints uint `json:"BasisPoints"`
+}
+
+// Config holds the tax rates and the way they are applied
+type Config struct {
+	DefaultRegion string   `json:"DefaultRegion"`
+	Mode          Mode     `json:"Mode"`
+	Rounding      Rounding `json:"Rounding"`
+	Rates         []Rate   `json:"Rates"`
+}
+
+// Validate checks that a configuration adheres to constraints
+func (c *Config) Validate() error {
+	var errs errors
+	if c.Mode != Exclusive && c.Mode != Inclusive {
+		errs = append(errs, fmt.Errorf("unknown pricing mode %q", c.Mode))
+	}
+	if c.Rounding != PerLine && c.Rounding != PerOrder {
+		errs = append(errs, fmt.Errorf("unknown rounding %q", c.Rounding))
+	}
+	seen := map[string]struct{}{}
+	for _, rate := range c.Rates {
+		if rate.Region == "" || rate.Class == "" {
+			errs = append(errs, fmt.Errorf("tax rates need both a region and a class"))
+			continue
+		}
+		key := rate.Region + "/" + rate.Class
+		if _, ok := seen[key]; ok {
+			errs = append(errs, fmt.Errorf("duplicate tax rate for class %s in region %s", rate.Class, rate.Region))
+		}
+		seen[key] = struct{}{}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// LoadConfig reads a tax configuration
+func LoadConfig(r io.Reader) (*Config, error) {
+	config := &Config{}
+	if err := json.NewDecoder(r).Decode(config); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
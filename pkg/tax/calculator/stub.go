@@ -0,0 +1,31 @@
+package calculator
+
+// NewStub returns a calculator that stands in for an external tax provider
+func NewStub() *Stub {
+	return &Stub{}
+}
+
+// Stub mimics an external tax provider that reports no tax for any region.
+// It is meant for local runs, where the real provider cannot be reached.
+type Stub struct{}
+
+// Calculate returns a breakdown with no tax owed for the given lines
+func (s *Stub) Calculate(region string, lines []Line) (*Breakdown, error) {
+	breakdown := &Breakdown{
+		Region:   region,
+		Mode:     Exclusive,
+		Rounding: PerOrder,
+		Lines:    []*LineTax{},
+	}
+	for _, line := range lines {
+		breakdown.Lines = append(breakdown.Lines, &LineTax{
+			ProductID: line.ProductID,
+			Class:     line.Class,
+			Net:       line.Amount,
+			Gross:     line.Amount,
+		})
+		breakdown.Net += line.Amount
+	}
+	breakdown.Gross = breakdown.Net
+	return breakdown, nil
+}
@@ -0,0 +1,34 @@
+package tax
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/pkg/tax/calculator"
+)
+
+const (
+	// TableProvider computes taxes from the configured rates
+	TableProvider = "table"
+	// StubProvider stands in for an external tax provider
+	StubProvider = "stub"
+)
+
+// NewAPI instantiates the tax calculator of the given provider
+func NewAPI(log logger.Logger, provider string, config io.Reader) (calculator.Calculator, error) {
+	switch provider {
+	case TableProvider:
+		taxConfig, err := calculator.LoadConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("Using tax table with %d rates, %s prices rounded per %s", len(taxConfig.Rates), taxConfig.Mode, taxConfig.Rounding)
+		return calculator.NewTable(taxConfig), nil
+	case StubProvider:
+		log.Infof("Using stub tax provider")
+		return calculator.NewStub(), nil
+	default:
+		return nil, fmt.Errorf("unknown tax provider %s", provider)
+	}
+}
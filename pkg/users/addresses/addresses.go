@@ -0,0 +1,91 @@
+package addresses
+
+import (
+	"strings"
+
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+)
+
+//go:generate mockgen -source ./addresses.go -destination mocks/addresses.go
+
+// AddressRegistry stores the address books
+type AddressRegistry interface {
+	GetAddresses(userID string) ([]*userStore.Address, error)
+	GetAddress(userID string, addressID uint) (*userStore.Address, error)
+	SaveAddress(address *userStore.Address) error
+	RemoveAddress(userID string, addressID uint) error
+}
+
+// New creates a new address Book
+func New(addresses AddressRegistry) *Book {
+	return &Book{addresses: addresses}
+}
+
+// Book manages the addresses the users ship and bill to
+type Book struct {
+	addresses AddressRegistry
+}
+
+// GetAddresses returns the addresses of the user
+func (b *Book) GetAddresses(userID string) ([]*userStore.Address, error) {
+	return b.addresses.GetAddresses(userID)
+}
+
+// GetAddress returns an address of the user
+func (b *Book) GetAddress(userID string, addressID uint) (*userStore.Address, error) {
+	return b.addresses.GetAddress(userID, addressID)
+}
+
+// GetDefaultShippingAddress returns the address the user ships to by default, or nil if the user has none
+func (b *Book) GetDefaultShippingAddress(userID string) (*userStore.Address, error) {
+	addresses, err := b.addresses.GetAddresses(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, address := range addresses {
+		if address.DefaultShipping {
+			return address, nil
+		}
+	}
+	return nil, nil
+}
+
+// AddAddress adds a new address to the address book of the user
+func (b *Book) AddAddress(userID string, address *userStore.Address) (*userStore.Address, error) {
+	added := trim(address)
+	added.ID = 0
+	added.UserID = userID
+	if err := b.addresses.SaveAddress(added); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// UpdateAddress replaces an address of the user
+func (b *Book) UpdateAddress(userID string, addressID uint, address *userStore.Address) (*userStore.Address, error) {
+	updated := trim(address)
+	updated.ID = addressID
+	updated.UserID = userID
+	if err := b.addresses.SaveAddress(updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// RemoveAddress removes an address of the user
+func (b *Book) RemoveAddress(userID string, addressID uint) error {
+	return b.addresses.RemoveAddress(userID, addressID)
+}
+
+// trim returns a copy of the address with its fields trimmed of surrounding spaces. Regions are upper case codes
+func trim(address *userStore.Address) *userStore.Address {
+	trimmed := *address
+	trimmed.Label = strings.TrimSpace(trimmed.Label)
+	trimmed.Name = strings.TrimSpace(trimmed.Name)
+	trimmed.Street = strings.TrimSpace(trimmed.Street)
+	trimmed.City = strings.TrimSpace(trimmed.City)
+	trimmed.PostalCode = strings.TrimSpace(trimmed.PostalCode)
+	trimmed.Region = strings.ToUpper(strings.TrimSpace(trimmed.Region))
+	trimmed.Phone = strings.TrimSpace(trimmed.Phone)
+	return &trimmed
+}
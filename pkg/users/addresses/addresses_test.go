@@ -0,0 +1,61 @@
+package addresses_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/pkg/users/addresses"
+	mock_addresses "github.com/mimatache/go-shop/pkg/users/addresses/mocks"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+)
+
+const userID = "user@email.com"
+
+func TestBook_AddAddress(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	registry := mock_addresses.NewMockAddressRegistry(ctrl)
+	book := addresses.New(registry)
+
+	registry.
+		EXPECT().
+		SaveAddress(gomock.Any()).
+		DoAndReturn(func(address *userStore.Address) error {
+			g.Expect(address.UserID).To(Equal(userID))
+			g.Expect(address.Name).To(Equal("John Doe"))
+			g.Expect(address.Region).To(Equal("RO"))
+			address.ID = 1
+			return nil
+		})
+
+	added, err := book.AddAddress(userID, &userStore.Address{ID: 7, UserID: "other@email.com", Name: " John Doe ", Region: " ro"})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(added.ID).To(Equal(uint(1)), "new addresses are given the next free ID")
+}
+
+func TestBook_GetDefaultShippingAddress(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	registry := mock_addresses.NewMockAddressRegistry(ctrl)
+	book := addresses.New(registry)
+
+	home := &userStore.Address{ID: 1, DefaultBilling: true}
+	work := &userStore.Address{ID: 2, DefaultShipping: true}
+	registry.EXPECT().GetAddresses(userID).Return([]*userStore.Address{home, work}, nil)
+	registry.EXPECT().GetAddresses(userID).Return([]*userStore.Address{home}, nil)
+
+	address, err := book.GetDefaultShippingAddress(userID)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(address).To(Equal(work))
+
+	address, err = book.GetDefaultShippingAddress(userID)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(address).To(BeNil())
+}
@@ -0,0 +1,92 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./addresses.go
+
+// Package mock_addresses is a generated GoMock package.
+package mock_addresses
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	store "github.com/mimatache/go-shop/pkg/users/store"
+	reflect "reflect"
+)
+
+// MockAddressRegistry is a mock of AddressRegistry interface
+type MockAddressRegistry struct {
+	ctrl     *gomock.Controller
+	recorder *MockAddressRegistryMockRecorder
+}
+
+// MockAddressRegistryMockRecorder is the mock recorder for MockAddressRegistry
+type MockAddressRegistryMockRecorder struct {
+	mock *MockAddressRegistry
+}
+
+// NewMockAddressRegistry creates a new mock instance
+func NewMockAddressRegistry(ctrl *gomock.Controller) *MockAddressRegistry {
+	mock := &MockAddressRegistry{ctrl: ctrl}
+	mock.recorder = &MockAddressRegistryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAddressRegistry) EXPECT() *MockAddressRegistryMockRecorder {
+	return m.recorder
+}
+
+// GetAddresses mocks base method
+func (m *MockAddressRegistry) GetAddresses(userID string) ([]*store.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAddresses", userID)
+	ret0, _ := ret[0].([]*store.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAddresses indicates an expected call of GetAddresses
+func (mr *MockAddressRegistryMockRecorder) GetAddresses(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAddresses", reflect.TypeOf((*MockAddressRegistry)(nil).GetAddresses), userID)
+}
+
+// GetAddress mocks base method
+func (m *MockAddressRegistry) GetAddress(userID string, addressID uint) (*store.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAddress", userID, addressID)
+	ret0, _ := ret[0].(*store.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAddress indicates an expected call of GetAddress
+func (mr *MockAddressRegistryMockRecorder) GetAddress(userID, addressID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAddress", reflect.TypeOf((*MockAddressRegistry)(nil).GetAddress), userID, addressID)
+}
+
+// SaveAddress mocks base method
+func (m *MockAddressRegistry) SaveAddress(address *store.Address) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAddress", address)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAddress indicates an expected call of SaveAddress
+func (mr *MockAddressRegistryMockRecorder) SaveAddress(address interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAddress", reflect.TypeOf((*MockAddressRegistry)(nil).SaveAddress), address)
+}
+
+// RemoveAddress mocks base method
+func (m *MockAddressRegistry) RemoveAddress(userID string, addressID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveAddress", userID, addressID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveAddress indicates an expected call of RemoveAddress
+func (mr *MockAddressRegistryMockRecorder) RemoveAddress(userID, addressID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAddress", reflect.TypeOf((*MockAddressRegistry)(nil).RemoveAddress), userID, addressID)
+}
@@ -0,0 +1,242 @@
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mimatache/go-shop/internal/rbac"
+	"github.com/mimatache/go-shop/internal/store"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+)
+
+//go:generate mockgen -source ./apikeys.go -destination mocks/apikeys.go
+
+const (
+	// keyPrefix starts every key, so that leaked keys are easy to recognize
+	keyPrefix = "shop_"
+	keyBytes  = 32
+	idBytes   = 8
+	// shownPrefix is how many characters of a key are kept to tell it apart
+	shownPrefix   = len(keyPrefix) + 6
+	maxNameLength = 64
+	// lastUsedPrecision is how often the last use of a key is recorded, so that busy clients do not write on every request
+	lastUsedPrecision = time.Minute
+)
+
+// APIKeyRegistry stores the API keys
+type APIKeyRegistry interface {
+	GetAPIKey(id string) (*userStore.APIKey, error)
+	GetAPIKeyByHash(hash string) (*userStore.APIKey, error)
+	GetAPIKeysFor(email string) ([]*userStore.APIKey, error)
+	SaveAPIKey(key *userStore.APIKey) error
+	RemoveAPIKey(id string) error
+}
+
+// UserRegistry gives access to the owners of the keys
+type UserRegistry interface {
+	GetUser(email string) (*userStore.User, error)
+}
+
+type logger interface {
+	Infow(msg string, keysAndValues ...interface{})
+}
+
+type invalidAPIKey struct {
+	reason error
+}
+
+func (i invalidAPIKey) Error() string {
+	return fmt.Sprintf("invalid API key: %s", i.reason)
+}
+
+// IsInvalidAPIKeyError verifies if a given error refers to an API key that cannot be created or used
+func IsInvalidAPIKeyError(err error) bool {
+	switch err.(type) {
+	case invalidAPIKey:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewInvalidAPIKey creates a new invalid API key error
+func NewInvalidAPIKey(reason error) error {
+	return invalidAPIKey{reason: reason}
+}
+
+// CreatedAPIKey is a new API key, the only time the key itself is given
+type CreatedAPIKey struct {
+	*userStore.APIKey
+	Key string `json:"key"`
+}
+
+// Config sets which scopes the keys can hold
+type Config struct {
+	// Policy gives the permissions the keys can hold as scopes, which are those granted to their owners
+	Policy rbac.Policy
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+}
+
+// New creates a new Keyring
+func New(log logger, keys APIKeyRegistry, users UserRegistry, config Config) *Keyring {
+	now := config.Now
+	if now == nil {
+		now = time.Now
+	}
+	return &Keyring{
+		log:    log,
+		keys:   keys,
+		users:  users,
+		policy: config.Policy,
+		now:    now,
+	}
+}
+
+// Keyring manages the API keys the users give to their machine clients. Every key holds scopes, which are the
+// permissions it can be used for, and which have to be granted to its owner, both when it is created and when it
+// is used
+type Keyring struct {
+	log    logger
+	keys   APIKeyRegistry
+	users  UserRegistry
+	policy rbac.Policy
+	now    func() time.Time
+}
+
+// Create creates a new API key for the user
+func (k *Keyring) Create(email string, name string, scopes []rbac.Permission) (*CreatedAPIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, NewInvalidAPIKey(fmt.Errorf("name is mandatory"))
+	}
+	if len(name) > maxNameLength {
+		return nil, NewInvalidAPIKey(fmt.Errorf("name cannot be longer than %d characters", maxNameLength))
+	}
+	user, err := k.users.GetUser(email)
+	if err != nil {
+		return nil, err
+	}
+	if err := k.validateScopes(user.GetRoles(), scopes); err != nil {
+		return nil, err
+	}
+	id, err := randomString(idBytes, hex.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomString(keyBytes, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+	key := keyPrefix + secret
+	stored := &userStore.APIKey{
+		ID:        id,
+		Email:     email,
+		Name:      name,
+		Prefix:    key[:shownPrefix],
+		Hash:      hash(key),
+		Scopes:    scopes,
+		CreatedAt: k.now(),
+	}
+	if err := k.keys.SaveAPIKey(stored); err != nil {
+		return nil, err
+	}
+	k.log.Infow("API key created", "user", email, "key", id, "scopes", scopes)
+	return &CreatedAPIKey{APIKey: stored, Key: key}, nil
+}
+
+// GetAPIKeys returns the API keys of the user, the oldest first
+func (k *Keyring) GetAPIKeys(email string) ([]*userStore.APIKey, error) {
+	keys, err := k.keys.GetAPIKeysFor(email)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// Revoke removes the API key of the user
+func (k *Keyring) Revoke(email string, id string) error {
+	key, err := k.keys.GetAPIKey(id)
+	if err != nil {
+		return err
+	}
+	// the keys of other users are reported as missing, so that their IDs cannot be probed
+	if key.Email != email {
+		return store.NewNotFoundError(userStore.GetAPIKeyTable().GetName(), "id", id)
+	}
+	if err := k.keys.RemoveAPIKey(id); err != nil {
+		return err
+	}
+	k.log.Infow("API key revoked", "user", email, "key", id)
+	return nil
+}
+
+// VerifyAPIKey returns the owner of the key, their current roles and the scopes of the key, recording its use
+func (k *Keyring) VerifyAPIKey(key string) (string, []rbac.Role, []rbac.Permission, error) {
+	if !strings.HasPrefix(key, keyPrefix) {
+		return "", nil, nil, NewInvalidAPIKey(fmt.Errorf("unknown key"))
+	}
+	stored, err := k.keys.GetAPIKeyByHash(hash(key))
+	if err != nil {
+		if store.IsNotFoundError(err) {
+			return "", nil, nil, NewInvalidAPIKey(fmt.Errorf("unknown key"))
+		}
+		return "", nil, nil, err
+	}
+	user, err := k.users.GetUser(stored.Email)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	now := k.now()
+	if stored.LastUsedAt == nil || now.Sub(*stored.LastUsedAt) >= lastUsedPrecision {
+		used := *stored
+		used.LastUsedAt = &now
+		if err := k.keys.SaveAPIKey(&used); err != nil {
+			return "", nil, nil, err
+		}
+	}
+	return stored.Email, user.GetRoles(), stored.Scopes, nil
+}
+
+// validateScopes checks that the scopes are known, given once and granted by the roles
+func (k *Keyring) validateScopes(roles []rbac.Role, scopes []rbac.Permission) error {
+	if len(scopes) == 0 {
+		return NewInvalidAPIKey(fmt.Errorf("at least one scope is mandatory"))
+	}
+	seen := map[rbac.Permission]bool{}
+	for _, scope := range scopes {
+		if !k.policy.IsKnown(scope) {
+			return NewInvalidAPIKey(fmt.Errorf("unknown scope: %s", scope))
+		}
+		if seen[scope] {
+			return NewInvalidAPIKey(fmt.Errorf("scope %s is given twice", scope))
+		}
+		seen[scope] = true
+		if !k.policy.Allows(roles, scope) {
+			return NewInvalidAPIKey(fmt.Errorf("scope %s is not granted to your roles", scope))
+		}
+	}
+	return nil
+}
+
+func randomString(size int, encode func([]byte) string) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encode(b), nil
+}
+
+func hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,193 @@
+package apikeys_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/internal/rbac"
+	"github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/users/apikeys"
+	mock_apikeys "github.com/mimatache/go-shop/pkg/users/apikeys/mocks"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+)
+
+const (
+	userEmail  = "user@email.com"
+	adminEmail = "admin@email.com"
+)
+
+var (
+	customer = &userStore.User{ID: 1, Name: "user", Email: userEmail, Password: "$argon2id$hash"}
+	admin    = &userStore.User{ID: 2, Name: "admin", Email: adminEmail, Password: "$argon2id$hash", Roles: []rbac.Role{rbac.Admin}}
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Infow(msg string, keysAndValues ...interface{}) {}
+
+// clock is moved forward by the tests instead of waiting
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newKeyring(t *testing.T) (*apikeys.Keyring, *mock_apikeys.MockUserRegistry, *clock, func()) {
+	g := NewWithT(t)
+
+	log, _, _ := logger.New("test", true)
+
+	schema := store.NewSchema()
+	schema.AddToSchema(userStore.GetAPIKeyTable())
+	db, err := store.New(schema)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	ctrl := gomock.NewController(t)
+	users := mock_apikeys.NewMockUserRegistry(ctrl)
+	users.EXPECT().GetUser(userEmail).Return(customer, nil).AnyTimes()
+	users.EXPECT().GetUser(adminEmail).Return(admin, nil).AnyTimes()
+
+	c := &clock{now: time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)}
+	keyring := apikeys.New(nopLogger{}, userStore.NewAPIKeyStore(log, db), users, apikeys.Config{Policy: rbac.DefaultPolicy, Now: c.Now})
+	return keyring, users, c, ctrl.Finish
+}
+
+func TestKeyring_Create(t *testing.T) {
+	g := NewWithT(t)
+
+	keyring, _, c, finish := newKeyring(t)
+	defer finish()
+
+	created, err := keyring.Create(adminEmail, " reports ", []rbac.Permission{rbac.ViewPayments, rbac.UseAccount})
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(created.Key).To(HavePrefix("shop_"))
+	g.Expect(strings.HasPrefix(created.Key, created.Prefix)).To(BeTrue())
+	g.Expect(created.Hash).ToNot(ContainSubstring(created.Key))
+	g.Expect(created.Name).To(Equal("reports"))
+	g.Expect(created.CreatedAt).To(Equal(c.Now()))
+
+	email, roles, scopes, err := keyring.VerifyAPIKey(created.Key)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(email).To(Equal(adminEmail))
+	g.Expect(roles).To(Equal([]rbac.Role{rbac.Admin}))
+	g.Expect(scopes).To(Equal([]rbac.Permission{rbac.ViewPayments, rbac.UseAccount}))
+}
+
+func TestKeyring_Create_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		email  string
+		key    string
+		scopes []rbac.Permission
+	}{
+		{name: "missing name", email: userEmail, key: " ", scopes: []rbac.Permission{rbac.UseAccount}},
+		{name: "long name", email: userEmail, key: strings.Repeat("a", 65), scopes: []rbac.Permission{rbac.UseAccount}},
+		{name: "no scope", email: userEmail, key: "script"},
+		{name: "unknown scope", email: adminEmail, key: "script", scopes: []rbac.Permission{"orders:delete"}},
+		{name: "duplicate scope", email: userEmail, key: "script", scopes: []rbac.Permission{rbac.UseAccount, rbac.UseAccount}},
+		{name: "scope not granted", email: userEmail, key: "script", scopes: []rbac.Permission{rbac.ManageOrders}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			keyring, _, _, finish := newKeyring(t)
+			defer finish()
+
+			_, err := keyring.Create(test.email, test.key, test.scopes)
+			g.Expect(apikeys.IsInvalidAPIKeyError(err)).To(BeTrue(), "%v", err)
+		})
+	}
+}
+
+func TestKeyring_VerifyAPIKey(t *testing.T) {
+	g := NewWithT(t)
+
+	keyring, _, c, finish := newKeyring(t)
+	defer finish()
+
+	created, err := keyring.Create(userEmail, "script", []rbac.Permission{rbac.UseAccount})
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	_, _, _, err = keyring.VerifyAPIKey("shop_unknown")
+	g.Expect(apikeys.IsInvalidAPIKeyError(err)).To(BeTrue())
+	_, _, _, err = keyring.VerifyAPIKey(strings.TrimPrefix(created.Key, "shop_"))
+	g.Expect(apikeys.IsInvalidAPIKeyError(err)).To(BeTrue())
+
+	c.Advance(time.Hour)
+	_, roles, _, err := keyring.VerifyAPIKey(created.Key)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(roles).To(Equal([]rbac.Role{rbac.Customer}))
+	firstUse := c.Now()
+
+	c.Advance(time.Second)
+	_, _, _, err = keyring.VerifyAPIKey(created.Key)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	keys, err := keyring.GetAPIKeys(userEmail)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(keys).To(HaveLen(1))
+	g.Expect(*keys[0].LastUsedAt).To(Equal(firstUse), "the last use is recorded once a minute")
+
+	c.Advance(time.Minute)
+	_, _, _, err = keyring.VerifyAPIKey(created.Key)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	keys, err = keyring.GetAPIKeys(userEmail)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(*keys[0].LastUsedAt).To(Equal(c.Now()))
+}
+
+func TestKeyring_Revoke(t *testing.T) {
+	g := NewWithT(t)
+
+	keyring, _, c, finish := newKeyring(t)
+	defer finish()
+
+	first, err := keyring.Create(userEmail, "first", []rbac.Permission{rbac.UseAccount})
+	g.Expect(err).ShouldNot(HaveOccurred())
+	c.Advance(time.Minute)
+	second, err := keyring.Create(userEmail, "second", []rbac.Permission{rbac.UseAccount})
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	keys, err := keyring.GetAPIKeys(userEmail)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(keys).To(HaveLen(2))
+	g.Expect(keys[0].Name).To(Equal("first"), "the oldest key comes first")
+
+	err = keyring.Revoke(adminEmail, first.ID)
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue(), "the key belongs to another user")
+
+	g.Expect(keyring.Revoke(userEmail, first.ID)).To(Succeed())
+	_, _, _, err = keyring.VerifyAPIKey(first.Key)
+	g.Expect(apikeys.IsInvalidAPIKeyError(err)).To(BeTrue())
+	_, _, _, err = keyring.VerifyAPIKey(second.Key)
+	g.Expect(err).ShouldNot(HaveOccurred())
+}
+
+func TestKeyring_VerifyAPIKey_StoreFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	keys := mock_apikeys.NewMockAPIKeyRegistry(ctrl)
+	users := mock_apikeys.NewMockUserRegistry(ctrl)
+	keyring := apikeys.New(nopLogger{}, keys, users, apikeys.Config{Policy: rbac.DefaultPolicy})
+
+	keys.EXPECT().GetAPIKeyByHash(gomock.Any()).Return(nil, fmt.Errorf("store unavailable"))
+
+	_, _, _, err := keyring.VerifyAPIKey("shop_key")
+	g.Expect(err).Should(HaveOccurred())
+	g.Expect(apikeys.IsInvalidAPIKeyError(err)).To(BeFalse())
+}
@@ -0,0 +1,185 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./apikeys.go
+
+// Package mock_apikeys is a generated GoMock package.
+package mock_apikeys
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	store "github.com/mimatache/go-shop/pkg/users/store"
+	reflect "reflect"
+)
+
+// MockAPIKeyRegistry is a mock of APIKeyRegistry interface
+type MockAPIKeyRegistry struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyRegistryMockRecorder
+}
+
+// MockAPIKeyRegistryMockRecorder is the mock recorder for MockAPIKeyRegistry
+type MockAPIKeyRegistryMockRecorder struct {
+	mock *MockAPIKeyRegistry
+}
+
+// NewMockAPIKeyRegistry creates a new mock instance
+func NewMockAPIKeyRegistry(ctrl *gomock.Controller) *MockAPIKeyRegistry {
+	mock := &MockAPIKeyRegistry{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyRegistryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAPIKeyRegistry) EXPECT() *MockAPIKeyRegistryMockRecorder {
+	return m.recorder
+}
+
+// GetAPIKey mocks base method
+func (m *MockAPIKeyRegistry) GetAPIKey(id string) (*store.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKey", id)
+	ret0, _ := ret[0].(*store.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKey indicates an expected call of GetAPIKey
+func (mr *MockAPIKeyRegistryMockRecorder) GetAPIKey(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKey", reflect.TypeOf((*MockAPIKeyRegistry)(nil).GetAPIKey), id)
+}
+
+// GetAPIKeyByHash mocks base method
+func (m *MockAPIKeyRegistry) GetAPIKeyByHash(hash string) (*store.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByHash", hash)
+	ret0, _ := ret[0].(*store.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByHash indicates an expected call of GetAPIKeyByHash
+func (mr *MockAPIKeyRegistryMockRecorder) GetAPIKeyByHash(hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByHash", reflect.TypeOf((*MockAPIKeyRegistry)(nil).GetAPIKeyByHash), hash)
+}
+
+// GetAPIKeysFor mocks base method
+func (m *MockAPIKeyRegistry) GetAPIKeysFor(email string) ([]*store.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeysFor", email)
+	ret0, _ := ret[0].([]*store.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeysFor indicates an expected call of GetAPIKeysFor
+func (mr *MockAPIKeyRegistryMockRecorder) GetAPIKeysFor(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeysFor", reflect.TypeOf((*MockAPIKeyRegistry)(nil).GetAPIKeysFor), email)
+}
+
+// SaveAPIKey mocks base method
+func (m *MockAPIKeyRegistry) SaveAPIKey(key *store.APIKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAPIKey", key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAPIKey indicates an expected call of SaveAPIKey
+func (mr *MockAPIKeyRegistryMockRecorder) SaveAPIKey(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAPIKey", reflect.TypeOf((*MockAPIKeyRegistry)(nil).SaveAPIKey), key)
+}
+
+// RemoveAPIKey mocks base method
+func (m *MockAPIKeyRegistry) RemoveAPIKey(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveAPIKey", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveAPIKey indicates an expected call of RemoveAPIKey
+func (mr *MockAPIKeyRegistryMockRecorder) RemoveAPIKey(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAPIKey", reflect.TypeOf((*MockAPIKeyRegistry)(nil).RemoveAPIKey), id)
+}
+
+// MockUserRegistry is a mock of UserRegistry interface
+type MockUserRegistry struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserRegistryMockRecorder
+}
+
+// MockUserRegistryMockRecorder is the mock recorder for MockUserRegistry
+type MockUserRegistryMockRecorder struct {
+	mock *MockUserRegistry
+}
+
+// NewMockUserRegistry creates a new mock instance
+func NewMockUserRegistry(ctrl *gomock.Controller) *MockUserRegistry {
+	mock := &MockUserRegistry{ctrl: ctrl}
+	mock.recorder = &MockUserRegistryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockUserRegistry) EXPECT() *MockUserRegistryMockRecorder {
+	return m.recorder
+}
+
+// GetUser mocks base method
+func (m *MockUserRegistry) GetUser(email string) (*store.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", email)
+	ret0, _ := ret[0].(*store.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser
+func (mr *MockUserRegistryMockRecorder) GetUser(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockUserRegistry)(nil).GetUser), email)
+}
+
+// Mocklogger is a mock of logger interface
+type Mocklogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockloggerMockRecorder
+}
+
+// MockloggerMockRecorder is the mock recorder for Mocklogger
+type MockloggerMockRecorder struct {
+	mock *Mocklogger
+}
+
+// NewMocklogger creates a new mock instance
+func NewMocklogger(ctrl *gomock.Controller) *Mocklogger {
+	mock := &Mocklogger{ctrl: ctrl}
+	mock.recorder = &MockloggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *Mocklogger) EXPECT() *MockloggerMockRecorder {
+	return m.recorder
+}
+
+// Infow mocks base method
+func (m *Mocklogger) Infow(msg string, keysAndValues ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range keysAndValues {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Infow", varargs...)
+}
+
+// Infow indicates an expected call of Infow
+func (mr *MockloggerMockRecorder) Infow(msg interface{}, keysAndValues ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, keysAndValues...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Infow", reflect.TypeOf((*Mocklogger)(nil).Infow), varargs...)
+}
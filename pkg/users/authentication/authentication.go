@@ -2,8 +2,12 @@ package authentication
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 
+	"github.com/mimatache/go-shop/internal/rbac"
 	"github.com/mimatache/go-shop/internal/store"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
 )
 
 //go:generate mockgen -source ./authentication.go -destination mocks/authentication.go
@@ -11,6 +15,23 @@ import (
 // UserRegistry abstracts aways the storage from the logic
 type UserRegistry interface {
 	GetPasswordFor(email string) (string, error)
+	SetPasswordFor(email string, hash string) error
+	AddUser(user *userStore.User) error
+	GetUser(email string) (*userStore.User, error)
+	GetUserByID(id uint) (*userStore.User, error)
+	UpdateProfile(email string, update *userStore.ProfileUpdate) (*userStore.User, error)
+	SetRolesFor(email string, roles []rbac.Role) (*userStore.User, error)
+}
+
+// PasswordHasher hashes the passwords and checks them against their hashes
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(hash string, password string) (bool, error)
+	NeedsRehash(hash string) bool
+}
+
+type logger interface {
+	Errorw(msg string, keysAndValues ...interface{})
 }
 
 type invalidCredentials struct {
@@ -37,31 +58,137 @@ func NewInvalidCredentials(username string) error {
 }
 
 // New creates a new User instance
-func New(storage UserRegistry) *User {
-	return &User{storage}
+func New(storage UserRegistry, hasher PasswordHasher, log logger) *User {
+	return &User{storage: storage, hasher: hasher, log: log}
 }
 
 // User manages any user related actions
 type User struct {
 	storage UserRegistry
+	hasher  PasswordHasher
+	log     logger
+	// decoy is checked against the passwords given for unknown users, so that they take as long to refuse as known ones
+	decoy     string
+	decoyOnce sync.Once
 }
 
-// IsValid checks if a username
+// IsValid checks if a username and password match. Passwords hashed with other parameters than the current ones
+// are hashed again, once they are known to be right
 func (u *User) IsValid(username, password string) error {
-	passwd, err := u.storage.GetPasswordFor(username)
+	hash, err := u.storage.GetPasswordFor(username)
 	if err != nil {
 		if store.IsNotFoundError(err) {
+			u.verifyDecoy(password)
 			return NewInvalidCredentials(username)
 		}
 		return err
 	}
-	if passwd != password {
+	ok, err := u.hasher.Verify(hash, password)
+	if err != nil {
+		return err
+	}
+	if !ok {
 		return NewInvalidCredentials(username)
 	}
+	if u.hasher.NeedsRehash(hash) {
+		u.rehash(username, password)
+	}
 	return nil
 }
 
-// Deprecated: do not use this
+// rehash replaces the hash of the password with one made with the current parameters.
+// The login goes on with the old hash if this fails
+func (u *User) rehash(username string, password string) {
+	hash, err := u.hasher.Hash(password)
+	if err == nil {
+		err = u.storage.SetPasswordFor(username, hash)
+	}
+	if err != nil {
+		u.log.Errorw("could not rehash password", "user", username, "error", err)
+	}
+}
+
+func (u *User) verifyDecoy(password string) {
+	u.decoyOnce.Do(func() {
+		u.decoy, _ = u.hasher.Hash("decoy")
+	})
+	if u.decoy != "" {
+		_, _ = u.hasher.Verify(u.decoy, password)
+	}
+}
+
+// Register creates a new user with the given credentials and returns its profile. The name is trimmed and the email
+// address normalized, the password is stored hashed and registering an email address that is already used fails
+func (u *User) Register(name string, email string, password string) (*userStore.Profile, error) {
+	user := &userStore.User{
+		Name:  strings.TrimSpace(name),
+		Email: userStore.Email(userStore.NormalizeEmail(email)),
+		Roles: []rbac.Role{rbac.Customer},
+	}
+	// an empty password is left empty, to be refused with the other constraints of the user
+	if password != "" {
+		hash, err := u.hasher.Hash(password)
+		if err != nil {
+			return nil, err
+		}
+		user.Password = hash
+	}
+	if err := u.storage.AddUser(user); err != nil {
+		return nil, err
+	}
+	return user.Profile(), nil
+}
+
+// GetProfile returns the profile of the user with the given email address
+func (u *User) GetProfile(email string) (*userStore.Profile, error) {
+	user, err := u.storage.GetUser(email)
+	if err != nil {
+		return nil, err
+	}
+	return user.Profile(), nil
+}
+
+// UpdateProfile changes the fields of the profile that are given and returns the updated profile.
+// The values are trimmed of surrounding spaces
+func (u *User) UpdateProfile(email string, update *userStore.ProfileUpdate) (*userStore.Profile, error) {
+	trimmed := &userStore.ProfileUpdate{}
+	if update.Name != nil {
+		name := strings.TrimSpace(*update.Name)
+		trimmed.Name = &name
+	}
+	if update.Phone != nil {
+		phone := strings.TrimSpace(*update.Phone)
+		trimmed.Phone = &phone
+	}
+	user, err := u.storage.UpdateProfile(email, trimmed)
+	if err != nil {
+		return nil, err
+	}
+	return user.Profile(), nil
+}
+
+// SetRoles replaces the roles of the user with the given ID and returns the updated profile. A user needs at least
+// one role. The roles are embedded in the tokens given at login, so the change applies from the next login
+func (u *User) SetRoles(id uint, roles []rbac.Role) (*userStore.Profile, error) {
+	if len(roles) == 0 {
+		return nil, userStore.NewInvalidUser(fmt.Errorf("at least one role is mandatory"))
+	}
+	user, err := u.storage.GetUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := u.storage.SetRolesFor(string(user.Email), roles)
+	if err != nil {
+		return nil, err
+	}
+	return updated.Profile(), nil
+}
+
+// GetEmailForUser returns the email address of the user with the given ID
 func (u *User) GetEmailForUser(id uint) (string, error) {
-	return "", fmt.Errorf("not implemented")
+	user, err := u.storage.GetUserByID(id)
+	if err != nil {
+		return "", err
+	}
+	return string(user.Email), nil
 }
@@ -2,14 +2,17 @@ package authentication_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/gomega"
 
+	"github.com/mimatache/go-shop/internal/rbac"
 	"github.com/mimatache/go-shop/internal/store"
 	"github.com/mimatache/go-shop/pkg/users/authentication"
 	mock_authentication "github.com/mimatache/go-shop/pkg/users/authentication/mocks"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
 )
 
 const (
@@ -17,40 +20,90 @@ const (
 	invalidUser = "baduser@mail.com"
 	goodPasswd  = "testpassword"
 	badPassword = "badpassword"
+	goodHash    = "$argon2id$good"
 )
 
-func TestUser_ValidCredentials(t *testing.T) {
-	g := NewWithT(t)
+type nopLogger struct{}
+
+func (nopLogger) Errorw(msg string, keysAndValues ...interface{}) {}
 
+func newUsers(t *testing.T) (*authentication.User, *mock_authentication.MockUserRegistry, *mock_authentication.MockPasswordHasher, func()) {
 	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
 	registry := mock_authentication.NewMockUserRegistry(ctrl)
+	hasher := mock_authentication.NewMockPasswordHasher(ctrl)
+	return authentication.New(registry, hasher, nopLogger{}), registry, hasher, ctrl.Finish
+}
+
+func TestUser_ValidCredentials(t *testing.T) {
+	g := NewWithT(t)
+
+	users, registry, hasher, finish := newUsers(t)
+	defer finish()
 
 	registry.
 		EXPECT().
 		GetPasswordFor(goodUser).
-		Return(goodPasswd, nil)
-
-	users := authentication.New(registry)
+		Return(goodHash, nil)
+	hasher.EXPECT().Verify(goodHash, goodPasswd).Return(true, nil)
+	hasher.EXPECT().NeedsRehash(goodHash).Return(false)
 
 	err := users.IsValid(goodUser, goodPasswd)
 
 	g.Expect(err).ShouldNot(HaveOccurred(), "valid user password combo returned an error")
 }
 
+func TestUser_ValidCredentials_Rehash(t *testing.T) {
+	g := NewWithT(t)
+
+	users, registry, hasher, finish := newUsers(t)
+	defer finish()
+
+	registry.
+		EXPECT().
+		GetPasswordFor(goodUser).
+		Return("$2a$04$old", nil)
+	hasher.EXPECT().Verify("$2a$04$old", goodPasswd).Return(true, nil)
+	hasher.EXPECT().NeedsRehash("$2a$04$old").Return(true)
+	hasher.EXPECT().Hash(goodPasswd).Return(goodHash, nil)
+	registry.EXPECT().SetPasswordFor(goodUser, goodHash).Return(nil)
+
+	err := users.IsValid(goodUser, goodPasswd)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+}
+
+func TestUser_ValidCredentials_RehashFails(t *testing.T) {
+	g := NewWithT(t)
+
+	users, registry, hasher, finish := newUsers(t)
+	defer finish()
+
+	registry.
+		EXPECT().
+		GetPasswordFor(goodUser).
+		Return("$2a$04$old", nil)
+	hasher.EXPECT().Verify("$2a$04$old", goodPasswd).Return(true, nil)
+	hasher.EXPECT().NeedsRehash("$2a$04$old").Return(true)
+	hasher.EXPECT().Hash(goodPasswd).Return(goodHash, nil)
+	registry.EXPECT().SetPasswordFor(goodUser, goodHash).Return(fmt.Errorf("store down"))
+
+	err := users.IsValid(goodUser, goodPasswd)
+
+	g.Expect(err).ShouldNot(HaveOccurred(), "the login goes on with the old hash")
+}
+
 func TestUser_InvalidUsername(t *testing.T) {
 	g := NewWithT(t)
 
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-	registry := mock_authentication.NewMockUserRegistry(ctrl)
+	users, registry, hasher, finish := newUsers(t)
+	defer finish()
 
 	registry.
 		EXPECT().
 		GetPasswordFor(invalidUser).
 		Return("", store.NewNotFoundError("users", "name", "name"))
-
-	users := authentication.New(registry)
+	hasher.EXPECT().Hash(gomock.Any()).Return(goodHash, nil)
+	hasher.EXPECT().Verify(goodHash, goodPasswd).Return(false, nil)
 
 	err := users.IsValid(invalidUser, goodPasswd)
 
@@ -61,16 +114,14 @@ func TestUser_InvalidUsername(t *testing.T) {
 func TestUser_InvalidPassword(t *testing.T) {
 	g := NewWithT(t)
 
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-	registry := mock_authentication.NewMockUserRegistry(ctrl)
+	users, registry, hasher, finish := newUsers(t)
+	defer finish()
 
 	registry.
 		EXPECT().
 		GetPasswordFor(goodUser).
-		Return(goodPasswd, nil)
-
-	users := authentication.New(registry)
+		Return(goodHash, nil)
+	hasher.EXPECT().Verify(goodHash, badPassword).Return(false, nil)
 
 	err := users.IsValid(goodUser, badPassword)
 
@@ -88,3 +139,126 @@ func TestInvalidCredentials(t *testing.T) {
 	err = authentication.NewInvalidCredentials("user")
 	g.Expect(authentication.IsInvalidCredentialsError(err)).To(BeTrue())
 }
+
+func TestUser_Register(t *testing.T) {
+	g := NewWithT(t)
+
+	users, registry, hasher, finish := newUsers(t)
+	defer finish()
+
+	hasher.EXPECT().Hash(goodPasswd).Return(goodHash, nil)
+	registry.
+		EXPECT().
+		AddUser(gomock.Any()).
+		DoAndReturn(func(user *userStore.User) error {
+			g.Expect(user.Name).To(Equal("New User"))
+			g.Expect(user.Email).To(Equal(userStore.Email(goodUser)))
+			g.Expect(user.Password).To(Equal(goodHash))
+			user.ID = 3
+			return nil
+		})
+
+	profile, err := users.Register(" New User ", " "+strings.ToUpper(goodUser)+" ", goodPasswd)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(profile).To(Equal(&userStore.Profile{ID: 3, Name: "New User", Email: goodUser, Roles: []rbac.Role{rbac.Customer}}))
+}
+
+func TestUser_Register_EmptyPassword(t *testing.T) {
+	g := NewWithT(t)
+
+	users, registry, _, finish := newUsers(t)
+	defer finish()
+
+	registry.
+		EXPECT().
+		AddUser(gomock.Any()).
+		DoAndReturn(func(user *userStore.User) error {
+			g.Expect(user.Password).To(BeEmpty(), "an empty password is not hashed, so that it is refused")
+			return userStore.NewInvalidUser(user.Validate())
+		})
+
+	_, err := users.Register("New User", goodUser, "")
+
+	g.Expect(userStore.IsInvalidUserError(err)).To(BeTrue())
+}
+
+func TestUser_Register_DuplicateEmail(t *testing.T) {
+	g := NewWithT(t)
+
+	users, registry, hasher, finish := newUsers(t)
+	defer finish()
+
+	hasher.EXPECT().Hash(goodPasswd).Return(goodHash, nil)
+	registry.
+		EXPECT().
+		AddUser(gomock.Any()).
+		Return(userStore.NewDuplicateEmail(goodUser))
+
+	_, err := users.Register("New User", goodUser, goodPasswd)
+
+	g.Expect(userStore.IsDuplicateEmailError(err)).To(BeTrue())
+}
+
+func TestUser_UpdateProfile(t *testing.T) {
+	g := NewWithT(t)
+
+	users, registry, _, finish := newUsers(t)
+	defer finish()
+
+	name := " New Name "
+	registry.
+		EXPECT().
+		UpdateProfile(goodUser, gomock.Any()).
+		DoAndReturn(func(email string, update *userStore.ProfileUpdate) (*userStore.User, error) {
+			g.Expect(*update.Name).To(Equal("New Name"))
+			g.Expect(update.Phone).To(BeNil(), "only the given fields are changed")
+			return &userStore.User{ID: 1, Name: *update.Name, Email: goodUser, Password: goodHash}, nil
+		})
+
+	profile, err := users.UpdateProfile(goodUser, &userStore.ProfileUpdate{Name: &name})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(profile).To(Equal(&userStore.Profile{ID: 1, Name: "New Name", Email: goodUser, Roles: []rbac.Role{rbac.Customer}}))
+}
+
+func TestUser_GetEmailForUser(t *testing.T) {
+	g := NewWithT(t)
+
+	users, registry, _, finish := newUsers(t)
+	defer finish()
+
+	registry.EXPECT().GetUserByID(uint(1)).Return(&userStore.User{ID: 1, Email: goodUser}, nil)
+
+	email, err := users.GetEmailForUser(1)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(email).To(Equal(goodUser))
+}
+
+func TestUser_SetRoles(t *testing.T) {
+	g := NewWithT(t)
+
+	users, registry, _, finish := newUsers(t)
+	defer finish()
+
+	roles := []rbac.Role{rbac.Admin, rbac.Customer}
+	registry.EXPECT().GetUserByID(uint(1)).Return(&userStore.User{ID: 1, Email: goodUser}, nil)
+	registry.EXPECT().SetRolesFor(goodUser, roles).Return(&userStore.User{ID: 1, Email: goodUser, Roles: roles}, nil)
+
+	profile, err := users.SetRoles(1, roles)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(profile.Roles).To(Equal(roles))
+}
+
+func TestUser_SetRoles_None(t *testing.T) {
+	g := NewWithT(t)
+
+	users, _, _, finish := newUsers(t)
+	defer finish()
+
+	_, err := users.SetRoles(1, nil)
+
+	g.Expect(userStore.IsInvalidUserError(err)).To(BeTrue())
+}
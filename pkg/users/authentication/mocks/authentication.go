@@ -6,6 +6,8 @@ package mock_authentication
 
 import (
 	gomock "github.com/golang/mock/gomock"
+	rbac "github.com/mimatache/go-shop/internal/rbac"
+	store "github.com/mimatache/go-shop/pkg/users/store"
 	reflect "reflect"
 )
 
@@ -46,3 +48,198 @@ func (mr *MockUserRegistryMockRecorder) GetPasswordFor(email interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordFor", reflect.TypeOf((*MockUserRegistry)(nil).GetPasswordFor), email)
 }
+
+// SetPasswordFor mocks base method
+func (m *MockUserRegistry) SetPasswordFor(email, hash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPasswordFor", email, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPasswordFor indicates an expected call of SetPasswordFor
+func (mr *MockUserRegistryMockRecorder) SetPasswordFor(email, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPasswordFor", reflect.TypeOf((*MockUserRegistry)(nil).SetPasswordFor), email, hash)
+}
+
+// AddUser mocks base method
+func (m *MockUserRegistry) AddUser(user *store.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddUser", user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddUser indicates an expected call of AddUser
+func (mr *MockUserRegistryMockRecorder) AddUser(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUser", reflect.TypeOf((*MockUserRegistry)(nil).AddUser), user)
+}
+
+// GetUser mocks base method
+func (m *MockUserRegistry) GetUser(email string) (*store.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", email)
+	ret0, _ := ret[0].(*store.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser
+func (mr *MockUserRegistryMockRecorder) GetUser(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockUserRegistry)(nil).GetUser), email)
+}
+
+// GetUserByID mocks base method
+func (m *MockUserRegistry) GetUserByID(id uint) (*store.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", id)
+	ret0, _ := ret[0].(*store.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID
+func (mr *MockUserRegistryMockRecorder) GetUserByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserRegistry)(nil).GetUserByID), id)
+}
+
+// UpdateProfile mocks base method
+func (m *MockUserRegistry) UpdateProfile(email string, update *store.ProfileUpdate) (*store.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", email, update)
+	ret0, _ := ret[0].(*store.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile
+func (mr *MockUserRegistryMockRecorder) UpdateProfile(email, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockUserRegistry)(nil).UpdateProfile), email, update)
+}
+
+// SetRolesFor mocks base method
+func (m *MockUserRegistry) SetRolesFor(email string, roles []rbac.Role) (*store.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRolesFor", email, roles)
+	ret0, _ := ret[0].(*store.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetRolesFor indicates an expected call of SetRolesFor
+func (mr *MockUserRegistryMockRecorder) SetRolesFor(email, roles interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRolesFor", reflect.TypeOf((*MockUserRegistry)(nil).SetRolesFor), email, roles)
+}
+
+// MockPasswordHasher is a mock of PasswordHasher interface
+type MockPasswordHasher struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordHasherMockRecorder
+}
+
+// MockPasswordHasherMockRecorder is the mock recorder for MockPasswordHasher
+type MockPasswordHasherMockRecorder struct {
+	mock *MockPasswordHasher
+}
+
+// NewMockPasswordHasher creates a new mock instance
+func NewMockPasswordHasher(ctrl *gomock.Controller) *MockPasswordHasher {
+	mock := &MockPasswordHasher{ctrl: ctrl}
+	mock.recorder = &MockPasswordHasherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPasswordHasher) EXPECT() *MockPasswordHasherMockRecorder {
+	return m.recorder
+}
+
+// Hash mocks base method
+func (m *MockPasswordHasher) Hash(password string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hash", password)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Hash indicates an expected call of Hash
+func (mr *MockPasswordHasherMockRecorder) Hash(password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hash", reflect.TypeOf((*MockPasswordHasher)(nil).Hash), password)
+}
+
+// Verify mocks base method
+func (m *MockPasswordHasher) Verify(hash, password string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", hash, password)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify
+func (mr *MockPasswordHasherMockRecorder) Verify(hash, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockPasswordHasher)(nil).Verify), hash, password)
+}
+
+// NeedsRehash mocks base method
+func (m *MockPasswordHasher) NeedsRehash(hash string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NeedsRehash", hash)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// NeedsRehash indicates an expected call of NeedsRehash
+func (mr *MockPasswordHasherMockRecorder) NeedsRehash(hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NeedsRehash", reflect.TypeOf((*MockPasswordHasher)(nil).NeedsRehash), hash)
+}
+
+// Mocklogger is a mock of logger interface
+type Mocklogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockloggerMockRecorder
+}
+
+// MockloggerMockRecorder is the mock recorder for Mocklogger
+type MockloggerMockRecorder struct {
+	mock *Mocklogger
+}
+
+// NewMocklogger creates a new mock instance
+func NewMocklogger(ctrl *gomock.Controller) *Mocklogger {
+	mock := &Mocklogger{ctrl: ctrl}
+	mock.recorder = &MockloggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *Mocklogger) EXPECT() *MockloggerMockRecorder {
+	return m.recorder
+}
+
+// Errorw mocks base method
+func (m *Mocklogger) Errorw(msg string, keysAndValues ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range keysAndValues {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Errorw", varargs...)
+}
+
+// Errorw indicates an expected call of Errorw
+func (mr *MockloggerMockRecorder) Errorw(msg interface{}, keysAndValues ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, keysAndValues...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Errorw", reflect.TypeOf((*Mocklogger)(nil).Errorw), varargs...)
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mimatache/go-shop/internal/http/authorization"
+	"github.com/mimatache/go-shop/internal/http/helpers"
+	"github.com/mimatache/go-shop/internal/http/middleware"
+	"github.com/mimatache/go-shop/internal/rbac"
+	"github.com/mimatache/go-shop/pkg/users/apikeys"
+	"github.com/mimatache/go-shop/pkg/users/store"
+)
+
+// apiKeyManagement lets the users create and revoke the API keys of their machine clients
+type apiKeyManagement interface {
+	Create(email string, name string, scopes []rbac.Permission) (*apikeys.CreatedAPIKey, error)
+	GetAPIKeys(email string) ([]*store.APIKey, error)
+	Revoke(email string, id string) error
+}
+
+// apiKeyRequest is the message expected when creating an API key
+type apiKeyRequest struct {
+	Name   string            `json:"name"`
+	Scopes []rbac.Permission `json:"scopes"`
+}
+
+// NewAPIKeyAPI creates a new APIKeyAPI
+func NewAPIKeyAPI(keys apiKeyManagement) *APIKeyAPI {
+	return &APIKeyAPI{keys: keys}
+}
+
+// APIKeyAPI lets the users manage the API keys of their machine clients
+type APIKeyAPI struct {
+	keys apiKeyManagement
+}
+
+func (a *APIKeyAPI) getAPIKeys(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	keys, err := a.keys.GetAPIKeys(userID)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, keys, http.StatusOK)
+}
+
+func (a *APIKeyAPI) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var request apiKeyRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	created, err := a.keys.Create(userID, request.Name, request.Scopes)
+	if err != nil {
+		if apikeys.IsInvalidAPIKeyError(err) {
+			helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, created, http.StatusCreated)
+}
+
+func (a *APIKeyAPI) revokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err := a.keys.Revoke(userID, mux.Vars(r)["id"]); err != nil {
+		formatError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddRoutes registers the API routes to a router. Every route requires logging in with a JWT token rather than
+// an API key, so that a leaked key cannot be used to create others
+func (a *APIKeyAPI) AddRoutes(router *mux.Router) {
+	router.Handle("/me/api-keys", middleware.JWTAuthorization(http.HandlerFunc(a.getAPIKeys))).Methods(http.MethodGet)
+	router.Handle("/me/api-keys", middleware.JWTAuthorization(http.HandlerFunc(a.createAPIKey))).Methods(http.MethodPost)
+	router.Handle("/me/api-keys/{id}", middleware.JWTAuthorization(http.HandlerFunc(a.revokeAPIKey))).Methods(http.MethodDelete)
+}
@@ -1,7 +1,9 @@
 package http
 
 import (
+	"net"
 	"net/http"
+	"strconv"
 
 	"github.com/gorilla/mux"
 
@@ -9,20 +11,104 @@ import (
 	"github.com/mimatache/go-shop/internal/http/helpers"
 	"github.com/mimatache/go-shop/internal/http/middleware"
 	"github.com/mimatache/go-shop/pkg/users/authentication"
+	"github.com/mimatache/go-shop/pkg/users/lockout"
+	"github.com/mimatache/go-shop/pkg/users/sessions"
+	"github.com/mimatache/go-shop/pkg/users/store"
+	"github.com/mimatache/go-shop/pkg/users/twofactor"
 )
 
+// totpFactor is the second factor asked for after the password, when the user enabled it
+const totpFactor = "totp"
+
 // AuthenticationAPI is used to authenticate users
 type AuthenticationAPI struct {
-	users userAuthentication
+	users        userAuthentication
+	carts        CartMerger
+	verifier     emailVerification
+	guard        loginGuard
+	secondFactor secondFactor
+	sessions     sessionManager
+	log          logger
 }
 
 type userAuthentication interface {
 	IsValid(email, password string) error
+	Register(name string, email string, password string) (*store.Profile, error)
+	GetProfile(email string) (*store.Profile, error)
+}
+
+// registration is the message expected when registering a user
+type registration struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// emailVerification sends the tokens proving the users own their email address and consumes them
+type emailVerification interface {
+	RequestPasswordReset(email string) error
+	ResetPassword(token string, password string) error
+	RequestVerification(email string) error
+	VerifyEmail(token string) error
+}
+
+// loginGuard slows down and locks out the logins of accounts and IP addresses with too many failures
+type loginGuard interface {
+	Attempt(email string, ip string) (*lockout.Attempt, error)
+	Succeeded(email string) error
+}
+
+// secondFactor checks the second factor of the users who enabled it
+type secondFactor interface {
+	IsEnabled(email string) (bool, error)
+	Verify(email string, code string) error
+}
+
+// secondFactorChallenge is the response to a correct password, when the user still has to give their second factor
+type secondFactorChallenge struct {
+	SecondFactor string `json:"secondFactor"`
+}
+
+// secondFactorCode is the message expected when giving a code of the second factor, or a recovery code
+type secondFactorCode struct {
+	Code string `json:"code"`
+}
+
+// sessionManager keeps the users logged in with rotating refresh tokens
+type sessionManager interface {
+	Start(email string, ip string, userAgent string) (string, *store.Session, error)
+	Refresh(token string, ip string, userAgent string) (string, *store.Session, error)
+	RevokeToken(token string) error
+}
+
+// CartMerger merges the cart a visitor filled before logging in into the cart of the user
+type CartMerger interface {
+	MergeCarts(guestID string, userID string) error
+}
+
+type logger interface {
+	Errorw(msg string, keysAndValues ...interface{})
 }
 
 // New creates a new AuthenticationApi
-func New(users userAuthentication) *AuthenticationAPI {
-	return &AuthenticationAPI{users: users}
+func New(
+	users userAuthentication,
+	carts CartMerger,
+	verifier emailVerification,
+	guard loginGuard,
+	secondFactor secondFactor,
+	sessions sessionManager,
+	log logger,
+) *AuthenticationAPI {
+	return &AuthenticationAPI{
+		users:        users,
+		carts:        carts,
+		verifier:     verifier,
+		guard:        guard,
+		secondFactor: secondFactor,
+		sessions:     sessions,
+		log:          log,
+	}
 }
 
 func (u *AuthenticationAPI) login() http.Handler {
@@ -32,24 +118,215 @@ func (u *AuthenticationAPI) login() http.Handler {
 			helpers.FormatError(w, authentication.NewInvalidCredentials(username).Error(), http.StatusUnauthorized)
 			return
 		}
+		// the email address is the user ID of the tokens, so it is used the way it is stored
+		username = store.NormalizeEmail(username)
+		attempt, ok := u.attemptLogin(w, username, remoteIP(r))
+		if !ok {
+			return
+		}
 		err := u.users.IsValid(username, providedPassword)
 		if err != nil {
 			if authentication.IsInvalidCredentialsError(err) {
 				helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
 				return
 			}
+			u.forgetAttempt(attempt, username)
 			helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		u.forgetAttempt(attempt, username)
 
-		tokenString, expirationTime, err := authorization.GenerateJWTToken(username)
+		enabled, err := u.secondFactor.IsEnabled(username)
+		if err != nil {
+			helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if enabled {
+			tokenString, expirationTime, err := authorization.GeneratePartialToken(username)
+			if err != nil {
+				helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			authorization.SetPartialAuthCookie(w, tokenString, expirationTime)
+			helpers.FormatResponse(w, secondFactorChallenge{SecondFactor: totpFactor}, http.StatusAccepted)
+			return
+		}
+		u.completeLogin(w, r, username)
+	}
+	return http.HandlerFunc(fn)
+}
 
+// loginSecondFactor completes the login of the users with a second factor, once their password was checked
+func (u *AuthenticationAPI) loginSecondFactor() http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		token, err := authorization.GetPartialAuthToken(r)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			helpers.FormatError(w, "log in with your password first", http.StatusUnauthorized)
+			return
+		}
+		claim, err := authorization.ValidatePartialToken(token)
+		if err != nil {
+			helpers.FormatError(w, "log in with your password first", http.StatusUnauthorized)
+			return
+		}
+		var request secondFactorCode
+		if !decode(w, r, &request) {
+			return
+		}
+		attempt, ok := u.attemptLogin(w, claim.Username, remoteIP(r))
+		if !ok {
+			return
+		}
+		if err := u.secondFactor.Verify(claim.Username, request.Code); err != nil {
+			if twofactor.IsInvalidCodeError(err) {
+				helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			u.forgetAttempt(attempt, claim.Username)
+			helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		u.forgetAttempt(attempt, claim.Username)
+		// the partial token cannot be used again
+		authorization.BlacklistToken(token)
+		authorization.ClearPartialAuthCookie(w)
+		u.completeLogin(w, r, claim.Username)
+	}
+	return http.HandlerFunc(fn)
+}
 
+// attemptLogin verifies that a login can be attempted, writing the response when it cannot. The attempt counts as
+// failed until it is forgotten, so that parallel guesses cannot get past the limits
+func (u *AuthenticationAPI) attemptLogin(w http.ResponseWriter, username string, ip string) (*lockout.Attempt, bool) {
+	attempt, err := u.guard.Attempt(username, ip)
+	if err == nil {
+		return attempt, true
+	}
+	if lockout.IsTooManyAttemptsError(err) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(lockout.RetryAfter(err).Seconds())))
+		helpers.FormatError(w, err.Error(), http.StatusTooManyRequests)
+		return nil, false
+	}
+	helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+	return nil, false
+}
+
+// forgetAttempt takes back the failure recorded for a login whose password or code was right, or could not be
+// checked. The login goes on even if the failure cannot be taken back
+func (u *AuthenticationAPI) forgetAttempt(attempt *lockout.Attempt, username string) {
+	if err := attempt.Forget(); err != nil {
+		u.log.Errorw("could not forget login attempt", "user", username, "err", err)
+	}
+}
+
+// completeLogin gives the user the token used for subsequent requests, and merges the cart they filled as a guest
+func (u *AuthenticationAPI) completeLogin(w http.ResponseWriter, r *http.Request, username string) {
+	// the failures are only forgotten once every factor was given, so that the second one cannot be guessed forever
+	if err := u.guard.Succeeded(username); err != nil {
+		u.log.Errorw("could not reset failed logins", "user", username, "err", err)
+	}
+
+	profile, err := u.users.GetProfile(username)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	refreshToken, session, err := u.sessions.Start(username, remoteIP(r), r.UserAgent())
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	tokenString, expirationTime, err := authorization.GenerateJWTToken(username, profile.Roles)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	authorization.SetAuthCookie(w, tokenString, expirationTime)
+	authorization.SetRefreshCookie(w, refreshToken, session.ExpiresAt)
+
+	// the guest cookie is kept if merging fails, so that the cart is not lost and merging is retried on the next login
+	guestID, err := authorization.GetGuestID(r)
+	if err != nil {
+		return
+	}
+	err = u.carts.MergeCarts(authorization.GuestUserID(guestID), username)
+	if err != nil {
+		u.log.Errorw("could not merge guest cart", "user", username, "err", err)
+		return
+	}
+	authorization.ClearGuestCookie(w)
+}
+
+// remoteIP returns the IP address the request comes from. Forwarding headers are not trusted, since any client can set them
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// refresh gives a new access token and rotates the refresh token. The roles are read again, so that role changes
+// apply from the next refresh
+func (u *AuthenticationAPI) refresh() http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		token, err := authorization.GetRefreshToken(r)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		refreshToken, session, err := u.sessions.Refresh(token, remoteIP(r), r.UserAgent())
+		if err != nil {
+			if sessions.IsInvalidRefreshTokenError(err) {
+				authorization.ClearRefreshCookie(w)
+				helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		profile, err := u.users.GetProfile(session.Email)
+		if err != nil {
+			helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		tokenString, expirationTime, err := authorization.GenerateJWTToken(session.Email, profile.Roles)
+		if err != nil {
+			helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
 		authorization.SetAuthCookie(w, tokenString, expirationTime)
+		authorization.SetRefreshCookie(w, refreshToken, session.ExpiresAt)
+		w.WriteHeader(http.StatusNoContent)
+	}
+	return http.HandlerFunc(fn)
+}
+
+func (u *AuthenticationAPI) register() http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		var request registration
+		if !decode(w, r, &request) {
+			return
+		}
+		profile, err := u.users.Register(request.Name, request.Email, request.Password)
+		if err != nil {
+			switch {
+			case store.IsInvalidUserError(err):
+				helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+			case store.IsDuplicateEmailError(err):
+				helpers.FormatError(w, err.Error(), http.StatusConflict)
+			default:
+				helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		// the user can ask for another verification email if this one is not sent
+		if err := u.verifier.RequestVerification(string(profile.Email)); err != nil {
+			u.log.Errorw("could not send verification email", "user", profile.Email, "err", err)
+		}
+		helpers.FormatResponse(w, profile, http.StatusCreated)
 	}
 	return http.HandlerFunc(fn)
 }
@@ -66,6 +343,16 @@ func (u *AuthenticationAPI) logout() http.Handler {
 			return
 		}
 		authorization.BlacklistToken(token)
+		// the session ends as well, so that the refresh token cannot log the user in again
+		refreshToken, err := authorization.GetRefreshToken(r)
+		if err != nil {
+			return
+		}
+		if err := u.sessions.RevokeToken(refreshToken); err != nil {
+			u.log.Errorw("could not revoke session", "err", err)
+			return
+		}
+		authorization.ClearRefreshCookie(w)
 	}
 
 	return http.HandlerFunc(fn)
@@ -74,5 +361,12 @@ func (u *AuthenticationAPI) logout() http.Handler {
 // RegisterToRouter adds the API routes to the given router
 func (u *AuthenticationAPI) RegisterToRouter(router *mux.Router) {
 	router.Handle("/login", u.login()).Methods(http.MethodGet)
+	router.Handle("/login/2fa", u.loginSecondFactor()).Methods(http.MethodPost)
+	router.Handle("/token/refresh", u.refresh()).Methods(http.MethodPost)
+	router.Handle("/users", u.register()).Methods(http.MethodPost)
+	router.Handle("/users/verification", u.requestVerification()).Methods(http.MethodPost)
+	router.Handle("/users/verify", u.verifyEmail()).Methods(http.MethodGet)
+	router.Handle("/password-reset", u.requestPasswordReset()).Methods(http.MethodPost)
+	router.Handle("/password-reset/confirm", u.resetPassword()).Methods(http.MethodPost)
 	router.Handle("/logout", middleware.JWTAuthorization(u.logout())).Methods(http.MethodGet)
 }
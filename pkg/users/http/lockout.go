@@ -0,0 +1,90 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mimatache/go-shop/internal/http/authorization"
+	"github.com/mimatache/go-shop/internal/http/helpers"
+	"github.com/mimatache/go-shop/internal/rbac"
+	"github.com/mimatache/go-shop/pkg/users/store"
+)
+
+// keyActor is recorded in the audit entries of the changes made with the administrative key, which identifies no user
+const keyActor = "admin-key"
+
+// lockoutAdministration lets administrators review and lift the lockouts
+type lockoutAdministration interface {
+	GetLockouts() ([]*store.Attempts, error)
+	GetAuditEntries() ([]*store.AuditEntry, error)
+	UnlockAccount(email string, actor string) error
+	UnlockIP(ip string, actor string) error
+}
+
+// unlockRequest is the message expected when lifting a lockout. Exactly one of the fields is expected
+type unlockRequest struct {
+	Email string `json:"email"`
+	IP    string `json:"ip"`
+}
+
+// NewLockoutAPI creates a new LockoutAPI
+func NewLockoutAPI(lockouts lockoutAdministration) *LockoutAPI {
+	return &LockoutAPI{lockouts: lockouts}
+}
+
+// LockoutAPI lets administrators review and lift the lockouts of the logins
+type LockoutAPI struct {
+	lockouts lockoutAdministration
+}
+
+func (l *LockoutAPI) getLockouts(w http.ResponseWriter, r *http.Request) {
+	lockouts, err := l.lockouts.GetLockouts()
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, lockouts, http.StatusOK)
+}
+
+func (l *LockoutAPI) getAuditEntries(w http.ResponseWriter, r *http.Request) {
+	entries, err := l.lockouts.GetAuditEntries()
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, entries, http.StatusOK)
+}
+
+func (l *LockoutAPI) unlock(w http.ResponseWriter, r *http.Request) {
+	var request unlockRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	actor, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		actor = keyActor
+	}
+	switch {
+	case request.Email != "" && request.IP == "":
+		err = l.lockouts.UnlockAccount(store.NormalizeEmail(request.Email), actor)
+	case request.IP != "" && request.Email == "":
+		err = l.lockouts.UnlockIP(request.IP, actor)
+	default:
+		helpers.FormatError(w, "either an email or an IP address is expected", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddRoutes registers the API routes to a router. Every route requires managing the users
+func (l *LockoutAPI) AddRoutes(router *mux.Router, adminHandler func(permission rbac.Permission) func(http.Handler) http.Handler) {
+	manageUsers := adminHandler(rbac.ManageUsers)
+	router.Handle("/admin/lockouts", manageUsers(http.HandlerFunc(l.getLockouts))).Methods(http.MethodGet)
+	router.Handle("/admin/lockouts/unlock", manageUsers(http.HandlerFunc(l.unlock))).Methods(http.MethodPost)
+	router.Handle("/admin/lockouts/audit", manageUsers(http.HandlerFunc(l.getAuditEntries))).Methods(http.MethodGet)
+}
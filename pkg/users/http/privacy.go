@@ -0,0 +1,95 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mimatache/go-shop/internal/http/authorization"
+	"github.com/mimatache/go-shop/internal/http/helpers"
+	"github.com/mimatache/go-shop/internal/http/middleware"
+	internalStore "github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/users/authentication"
+	"github.com/mimatache/go-shop/pkg/users/privacy"
+)
+
+// dataRequests exports the personal data of the users and deletes their accounts
+type dataRequests interface {
+	Export(email string) (*privacy.Archive, error)
+	DeleteAccount(email string, password string) error
+}
+
+// accountDeletion is the message expected when deleting an account
+type accountDeletion struct {
+	Password string `json:"password"`
+}
+
+// NewPrivacyAPI creates a new PrivacyAPI
+func NewPrivacyAPI(requests dataRequests) *PrivacyAPI {
+	return &PrivacyAPI{requests: requests}
+}
+
+// PrivacyAPI lets the users download their personal data and delete their account
+type PrivacyAPI struct {
+	requests dataRequests
+}
+
+func (p *PrivacyAPI) export(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	archive, err := p.requests.Export(userID)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	body, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("go-shop-export-%s.json", archive.ExportedAt.Format("20060102-150405"))))
+	_, _ = w.Write(body)
+}
+
+func (p *PrivacyAPI) deleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var request accountDeletion
+	if !decode(w, r, &request) {
+		return
+	}
+	if err := p.requests.DeleteAccount(userID, request.Password); err != nil {
+		switch {
+		case authentication.IsInvalidCredentialsError(err):
+			helpers.FormatError(w, "the password is wrong", http.StatusForbidden)
+		case internalStore.IsCannotEraseError(err):
+			helpers.FormatError(w, err.Error(), http.StatusConflict)
+		default:
+			formatError(w, err)
+		}
+		return
+	}
+	// the account is gone, so the token it was reached with must not be used again
+	if token, err := authorization.GetAuthToken(r); err == nil {
+		authorization.BlacklistToken(token)
+	}
+	authorization.ClearRefreshCookie(w)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddRoutes registers the API routes to a router. Every route requires logging in with a JWT token rather than
+// an API key, since they give away or remove everything about the user. They have to be registered before the
+// profile routes
+func (p *PrivacyAPI) AddRoutes(router *mux.Router) {
+	router.Handle("/me/export", middleware.JWTAuthorization(http.HandlerFunc(p.export))).Methods(http.MethodGet)
+	router.Handle("/me", middleware.JWTAuthorization(http.HandlerFunc(p.deleteAccount))).Methods(http.MethodDelete)
+}
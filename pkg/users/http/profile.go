@@ -0,0 +1,239 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mimatache/go-shop/internal/http/authorization"
+	"github.com/mimatache/go-shop/internal/http/helpers"
+	"github.com/mimatache/go-shop/internal/rbac"
+	internalStore "github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/users/store"
+)
+
+// userProfiles reads and changes the profiles of the users
+type userProfiles interface {
+	GetProfile(email string) (*store.Profile, error)
+	UpdateProfile(email string, update *store.ProfileUpdate) (*store.Profile, error)
+	SetRoles(id uint, roles []rbac.Role) (*store.Profile, error)
+}
+
+// roleAssignment is the message expected when changing the roles of a user
+type roleAssignment struct {
+	Roles []rbac.Role `json:"roles"`
+}
+
+// addressBook manages the addresses of the users
+type addressBook interface {
+	GetAddresses(userID string) ([]*store.Address, error)
+	GetAddress(userID string, addressID uint) (*store.Address, error)
+	AddAddress(userID string, address *store.Address) (*store.Address, error)
+	UpdateAddress(userID string, addressID uint, address *store.Address) (*store.Address, error)
+	RemoveAddress(userID string, addressID uint) error
+}
+
+// NewProfileAPI creates a new ProfileAPI
+func NewProfileAPI(profiles userProfiles, addresses addressBook) *ProfileAPI {
+	return &ProfileAPI{
+		profiles:  profiles,
+		addresses: addresses,
+	}
+}
+
+// ProfileAPI lets the users manage their profile and address book
+type ProfileAPI struct {
+	profiles  userProfiles
+	addresses addressBook
+}
+
+func (p *ProfileAPI) getProfile(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	profile, err := p.profiles.GetProfile(userID)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, profile, http.StatusOK)
+}
+
+func (p *ProfileAPI) updateProfile(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var update store.ProfileUpdate
+	if !decode(w, r, &update) {
+		return
+	}
+	profile, err := p.profiles.UpdateProfile(userID, &update)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, profile, http.StatusOK)
+}
+
+func (p *ProfileAPI) setRoles(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		helpers.FormatError(w, "invalid user ID", http.StatusBadRequest)
+		return
+	}
+	var request roleAssignment
+	if !decode(w, r, &request) {
+		return
+	}
+	profile, err := p.profiles.SetRoles(uint(userID), request.Roles)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, profile, http.StatusOK)
+}
+
+func (p *ProfileAPI) getAddresses(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	addresses, err := p.addresses.GetAddresses(userID)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, addresses, http.StatusOK)
+}
+
+func (p *ProfileAPI) addAddress(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var address store.Address
+	if !decode(w, r, &address) {
+		return
+	}
+	added, err := p.addresses.AddAddress(userID, &address)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, added, http.StatusCreated)
+}
+
+func (p *ProfileAPI) getAddress(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	addressID, ok := getAddressID(w, r)
+	if !ok {
+		return
+	}
+	address, err := p.addresses.GetAddress(userID, addressID)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, address, http.StatusOK)
+}
+
+func (p *ProfileAPI) updateAddress(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	addressID, ok := getAddressID(w, r)
+	if !ok {
+		return
+	}
+	var address store.Address
+	if !decode(w, r, &address) {
+		return
+	}
+	updated, err := p.addresses.UpdateAddress(userID, addressID, &address)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, updated, http.StatusOK)
+}
+
+func (p *ProfileAPI) removeAddress(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	addressID, ok := getAddressID(w, r)
+	if !ok {
+		return
+	}
+	if err := p.addresses.RemoveAddress(userID, addressID); err != nil {
+		formatError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func getAddressID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	addressID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		helpers.FormatError(w, "invalid address ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(addressID), true
+}
+
+func decode(w http.ResponseWriter, r *http.Request, value interface{}) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(value)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func formatError(w http.ResponseWriter, err error) {
+	switch {
+	case internalStore.IsNotFoundError(err):
+		helpers.FormatError(w, err.Error(), http.StatusNotFound)
+	case store.IsInvalidUserError(err), store.IsInvalidAddressError(err):
+		helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+	default:
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// AddRoutes registers the API routes to a router. Every route requires logging in,
+// and changing the roles of the users requires managing them
+func (p *ProfileAPI) AddRoutes(
+	router *mux.Router,
+	userHandler func(http.Handler) http.Handler,
+	adminHandler func(permission rbac.Permission) func(http.Handler) http.Handler,
+) {
+	meRouter := router.PathPrefix("/me").Subrouter()
+	meRouter.Handle("", userHandler(http.HandlerFunc(p.getProfile))).Methods(http.MethodGet)
+	meRouter.Handle("", userHandler(http.HandlerFunc(p.updateProfile))).Methods(http.MethodPatch)
+	meRouter.Handle("/addresses", userHandler(http.HandlerFunc(p.getAddresses))).Methods(http.MethodGet)
+	meRouter.Handle("/addresses", userHandler(http.HandlerFunc(p.addAddress))).Methods(http.MethodPost)
+	meRouter.Handle("/addresses/{id}", userHandler(http.HandlerFunc(p.getAddress))).Methods(http.MethodGet)
+	meRouter.Handle("/addresses/{id}", userHandler(http.HandlerFunc(p.updateAddress))).Methods(http.MethodPut)
+	meRouter.Handle("/addresses/{id}", userHandler(http.HandlerFunc(p.removeAddress))).Methods(http.MethodDelete)
+
+	router.Handle("/admin/users/{id}/roles", adminHandler(rbac.ManageUsers)(http.HandlerFunc(p.setRoles))).Methods(http.MethodPut)
+}
@@ -0,0 +1,75 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/http/middleware"
+	userHTTP "github.com/mimatache/go-shop/pkg/users/http"
+)
+
+// TestRoutes_RefuseAPIKeys checks that the routes able to take over an account cannot be reached with an API key,
+// whether it is sent in its own header or as a bearer token
+func TestRoutes_RefuseAPIKeys(t *testing.T) {
+	router := mux.NewRouter()
+	userHTTP.NewTwoFactorAPI(nil).AddRoutes(router)
+	userHTTP.NewSessionAPI(nil).AddRoutes(router)
+	userHTTP.NewAPIKeyAPI(nil).AddRoutes(router)
+	userHTTP.NewPrivacyAPI(nil).AddRoutes(router)
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{method: http.MethodGet, path: "/me/2fa"},
+		{method: http.MethodPost, path: "/me/2fa"},
+		{method: http.MethodDelete, path: "/me/2fa"},
+		{method: http.MethodPost, path: "/me/2fa/confirm"},
+		{method: http.MethodPost, path: "/me/2fa/recovery-codes"},
+		{method: http.MethodGet, path: "/me/sessions"},
+		{method: http.MethodDelete, path: "/me/sessions"},
+		{method: http.MethodDelete, path: "/me/sessions/session"},
+		{method: http.MethodGet, path: "/me/api-keys"},
+		{method: http.MethodPost, path: "/me/api-keys"},
+		{method: http.MethodDelete, path: "/me/api-keys/key"},
+		{method: http.MethodGet, path: "/me/export"},
+		{method: http.MethodDelete, path: "/me"},
+	}
+	headers := map[string]string{
+		middleware.APIKeyHeader: "shop_key",
+		"Authorization":         "Bearer shop_key",
+	}
+	for _, test := range tests {
+		for header, value := range headers {
+			t.Run(test.method+" "+test.path+" "+header, func(t *testing.T) {
+				g := NewWithT(t)
+
+				r := httptest.NewRequest(test.method, test.path, nil)
+				r.Header.Set(header, value)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, r)
+
+				g.Expect(w.Code).To(BeElementOf(http.StatusUnauthorized, http.StatusForbidden))
+			})
+		}
+	}
+}
+
+func TestRegister_RefusesUnknownFields(t *testing.T) {
+	g := NewWithT(t)
+
+	router := mux.NewRouter()
+	userHTTP.New(nil, nil, nil, nil, nil, nil, nil).RegisterToRouter(router)
+
+	body := `{"name":"John","email":"john@email.com","password":"1234","roles":["admin"]}`
+	r := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	g.Expect(w.Code).To(Equal(http.StatusBadRequest))
+}
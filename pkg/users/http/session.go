@@ -0,0 +1,79 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mimatache/go-shop/internal/http/authorization"
+	"github.com/mimatache/go-shop/internal/http/helpers"
+	"github.com/mimatache/go-shop/internal/http/middleware"
+	"github.com/mimatache/go-shop/pkg/users/store"
+)
+
+// userSessions lists and revokes the sessions of the users
+type userSessions interface {
+	GetSessions(email string) ([]*store.Session, error)
+	Revoke(email string, sessionID string) error
+	RevokeAll(email string) error
+}
+
+// NewSessionAPI creates a new SessionAPI
+func NewSessionAPI(sessions userSessions) *SessionAPI {
+	return &SessionAPI{sessions: sessions}
+}
+
+// SessionAPI lets the users review where they are logged in and log out from anywhere. Revoking a session stops
+// its refresh token from working, while its access token stays valid until it expires
+type SessionAPI struct {
+	sessions userSessions
+}
+
+func (s *SessionAPI) getSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	sessions, err := s.sessions.GetSessions(userID)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, sessions, http.StatusOK)
+}
+
+func (s *SessionAPI) revokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err := s.sessions.Revoke(userID, mux.Vars(r)["id"]); err != nil {
+		formatError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *SessionAPI) revokeSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err := s.sessions.RevokeAll(userID); err != nil {
+		formatError(w, err)
+		return
+	}
+	authorization.ClearRefreshCookie(w)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddRoutes registers the API routes to a router. Every route requires logging in with a JWT token rather than
+// an API key, so that a leaked key cannot be used to watch or end the sessions of the user
+func (s *SessionAPI) AddRoutes(router *mux.Router) {
+	router.Handle("/me/sessions", middleware.JWTAuthorization(http.HandlerFunc(s.getSessions))).Methods(http.MethodGet)
+	router.Handle("/me/sessions", middleware.JWTAuthorization(http.HandlerFunc(s.revokeSessions))).Methods(http.MethodDelete)
+	router.Handle("/me/sessions/{id}", middleware.JWTAuthorization(http.HandlerFunc(s.revokeSession))).Methods(http.MethodDelete)
+}
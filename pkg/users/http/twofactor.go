@@ -0,0 +1,143 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mimatache/go-shop/internal/http/authorization"
+	"github.com/mimatache/go-shop/internal/http/helpers"
+	"github.com/mimatache/go-shop/internal/http/middleware"
+	"github.com/mimatache/go-shop/pkg/users/twofactor"
+)
+
+// secondFactorEnrollment lets the users enable and disable their second factor
+type secondFactorEnrollment interface {
+	IsEnabled(email string) (bool, error)
+	Enroll(email string) (*twofactor.Enrollment, error)
+	Confirm(email string, code string) ([]string, error)
+	RegenerateRecoveryCodes(email string, code string) ([]string, error)
+	Disable(email string, code string) error
+}
+
+// secondFactorStatus tells whether logging in requires a second factor
+type secondFactorStatus struct {
+	Enabled bool `json:"enabled"`
+}
+
+// recoveryCodes are shown once, when the second factor is enabled or when they are replaced
+type recoveryCodes struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// NewTwoFactorAPI creates a new TwoFactorAPI
+func NewTwoFactorAPI(secondFactors secondFactorEnrollment) *TwoFactorAPI {
+	return &TwoFactorAPI{secondFactors: secondFactors}
+}
+
+// TwoFactorAPI lets the users manage the TOTP second factor of their login
+type TwoFactorAPI struct {
+	secondFactors secondFactorEnrollment
+}
+
+func (t *TwoFactorAPI) getStatus(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	enabled, err := t.secondFactors.IsEnabled(userID)
+	if err != nil {
+		formatSecondFactorError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, secondFactorStatus{Enabled: enabled}, http.StatusOK)
+}
+
+func (t *TwoFactorAPI) enroll(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	enrollment, err := t.secondFactors.Enroll(userID)
+	if err != nil {
+		formatSecondFactorError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, enrollment, http.StatusCreated)
+}
+
+func (t *TwoFactorAPI) confirm(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var request secondFactorCode
+	if !decode(w, r, &request) {
+		return
+	}
+	codes, err := t.secondFactors.Confirm(userID, request.Code)
+	if err != nil {
+		formatSecondFactorError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, recoveryCodes{RecoveryCodes: codes}, http.StatusOK)
+}
+
+func (t *TwoFactorAPI) regenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var request secondFactorCode
+	if !decode(w, r, &request) {
+		return
+	}
+	codes, err := t.secondFactors.RegenerateRecoveryCodes(userID, request.Code)
+	if err != nil {
+		formatSecondFactorError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, recoveryCodes{RecoveryCodes: codes}, http.StatusOK)
+}
+
+func (t *TwoFactorAPI) disable(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var request secondFactorCode
+	if !decode(w, r, &request) {
+		return
+	}
+	if err := t.secondFactors.Disable(userID, request.Code); err != nil {
+		formatSecondFactorError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func formatSecondFactorError(w http.ResponseWriter, err error) {
+	switch {
+	case twofactor.IsInvalidCodeError(err):
+		helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+	case twofactor.IsAlreadyEnabledError(err):
+		helpers.FormatError(w, err.Error(), http.StatusConflict)
+	default:
+		formatError(w, err)
+	}
+}
+
+// AddRoutes registers the API routes to a router. Every route requires logging in with a JWT token rather than
+// an API key, so that a leaked key cannot be used to take over the second factor of the user
+func (t *TwoFactorAPI) AddRoutes(router *mux.Router) {
+	router.Handle("/me/2fa", middleware.JWTAuthorization(http.HandlerFunc(t.getStatus))).Methods(http.MethodGet)
+	router.Handle("/me/2fa", middleware.JWTAuthorization(http.HandlerFunc(t.enroll))).Methods(http.MethodPost)
+	router.Handle("/me/2fa", middleware.JWTAuthorization(http.HandlerFunc(t.disable))).Methods(http.MethodDelete)
+	router.Handle("/me/2fa/confirm", middleware.JWTAuthorization(http.HandlerFunc(t.confirm))).Methods(http.MethodPost)
+	router.Handle("/me/2fa/recovery-codes", middleware.JWTAuthorization(http.HandlerFunc(t.regenerateRecoveryCodes))).Methods(http.MethodPost)
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/mimatache/go-shop/internal/http/helpers"
+	"github.com/mimatache/go-shop/pkg/users/store"
+	"github.com/mimatache/go-shop/pkg/users/verification"
+)
+
+// tokenRequest is the message expected when asking for a token to be sent by email
+type tokenRequest struct {
+	Email string `json:"email"`
+}
+
+// passwordReset is the message expected when choosing a new password
+type passwordReset struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// requested acknowledges a request for a token the same way whether the email address is known or not,
+// and whether the email could be sent or not, so that the response cannot tell which addresses have an account
+func (u *AuthenticationAPI) requested(w http.ResponseWriter, r *http.Request, request func(email string) error) {
+	var body tokenRequest
+	if !decode(w, r, &body) {
+		return
+	}
+	if err := request(body.Email); err != nil {
+		u.log.Errorw("could not send token", "user", body.Email, "err", err)
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (u *AuthenticationAPI) requestPasswordReset() http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		u.requested(w, r, u.verifier.RequestPasswordReset)
+	}
+	return http.HandlerFunc(fn)
+}
+
+func (u *AuthenticationAPI) requestVerification() http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		u.requested(w, r, u.verifier.RequestVerification)
+	}
+	return http.HandlerFunc(fn)
+}
+
+func (u *AuthenticationAPI) resetPassword() http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		var body passwordReset
+		if !decode(w, r, &body) {
+			return
+		}
+		u.consumed(w, u.verifier.ResetPassword(body.Token, body.Password))
+	}
+	return http.HandlerFunc(fn)
+}
+
+func (u *AuthenticationAPI) verifyEmail() http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		u.consumed(w, u.verifier.VerifyEmail(r.URL.Query().Get("token")))
+	}
+	return http.HandlerFunc(fn)
+}
+
+func (u *AuthenticationAPI) consumed(w http.ResponseWriter, err error) {
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case verification.IsInvalidTokenError(err), store.IsInvalidUserError(err):
+		helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+	default:
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,433 @@
+package lockout
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mimatache/go-shop/internal/store"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+)
+
+//go:generate mockgen -source ./lockout.go -destination mocks/lockout.go
+
+const (
+	accountPrefix = "account:"
+	ipPrefix      = "ip:"
+	// systemActor is the actor of the changes that are not made by an administrator
+	systemActor = "system"
+	idBytes     = 8
+)
+
+type errors []error
+
+func (e errors) Error() string {
+	b := bytes.NewBufferString("")
+	for _, err := range e {
+		_, _ = fmt.Fprintf(b, "\t%s\n", err)
+	}
+	return b.String()
+}
+
+// AttemptRegistry stores the failed logins and the audit entries of the lockouts
+type AttemptRegistry interface {
+	GetAttempts(id string) (*userStore.Attempts, error)
+	GetAllAttempts() ([]*userStore.Attempts, error)
+	SaveAttempts(attempts *userStore.Attempts) error
+	RemoveAttempts(id string) error
+	AddAuditEntry(entry *userStore.AuditEntry) error
+	GetAuditEntries() ([]*userStore.AuditEntry, error)
+}
+
+type logger interface {
+	Infow(msg string, keysAndValues ...interface{})
+}
+
+type tooManyAttempts struct {
+	retryAt time.Time
+	wait    time.Duration
+}
+
+func (t tooManyAttempts) Error() string {
+	return fmt.Sprintf("too many failed logins, try again in %s", t.wait)
+}
+
+// IsTooManyAttemptsError verifies if a given error refers to a login attempted before it is allowed
+func IsTooManyAttemptsError(err error) bool {
+	switch err.(type) {
+	case tooManyAttempts:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewTooManyAttempts creates a new too many attempts error, for a login that can be attempted again after the given wait
+func NewTooManyAttempts(retryAt time.Time, wait time.Duration) error {
+	return tooManyAttempts{retryAt: retryAt, wait: wait}
+}
+
+// RetryAfter returns how long to wait before attempting a login again, if the error refers to too many attempts
+func RetryAfter(err error) time.Duration {
+	if t, ok := err.(tooManyAttempts); ok {
+		return t.wait
+	}
+	return 0
+}
+
+// Config holds the thresholds of the lockouts
+type Config struct {
+	// AccountFailures is how many logins can fail for an account before it is locked out
+	AccountFailures uint
+	// IPFailures is how many logins can fail from an IP address, whatever the account, before it is locked out
+	IPFailures uint
+	// BaseDelay is how long to wait after the first failed login. It doubles with every failure, up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// LockoutDuration is how long a lockout lasts, unless an administrator lifts it before
+	LockoutDuration time.Duration
+	// ResetAfter is how long after the last failure the failures are forgotten
+	ResetAfter time.Duration
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+}
+
+// Validate checks that the thresholds are consistent
+func (c Config) Validate() error {
+	var errs errors
+	if c.AccountFailures == 0 {
+		errs = append(errs, fmt.Errorf("account failures must be positive"))
+	}
+	if c.IPFailures == 0 {
+		errs = append(errs, fmt.Errorf("IP address failures must be positive"))
+	}
+	if c.BaseDelay <= 0 {
+		errs = append(errs, fmt.Errorf("base delay must be positive"))
+	}
+	if c.MaxDelay < c.BaseDelay {
+		errs = append(errs, fmt.Errorf("max delay must not be lower than the base delay"))
+	}
+	if c.LockoutDuration <= 0 {
+		errs = append(errs, fmt.Errorf("lockout duration must be positive"))
+	}
+	if c.ResetAfter <= 0 {
+		errs = append(errs, fmt.Errorf("reset threshold must be positive"))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// New creates a new Guard
+func New(log logger, attempts AttemptRegistry, config Config) (*Guard, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	now := config.Now
+	if now == nil {
+		now = time.Now
+	}
+	return &Guard{
+		log:      log,
+		attempts: attempts,
+		config:   config,
+		now:      now,
+	}, nil
+}
+
+// Guard slows down and locks out the logins of accounts and IP addresses with too many failures. After every failure
+// of an account, its next login has to wait twice as long as after the previous one. An IP address is only slowed
+// down once more of its logins failed than an account is allowed, so that users sharing an address do not wait
+// for each other's typos. Once the failures reach their threshold the account or IP address is locked out.
+// Unknown accounts are tracked as well, so that lockouts do not tell which accounts exist
+type Guard struct {
+	log      logger
+	attempts AttemptRegistry
+	config   Config
+	now      func() time.Time
+	// lock makes the updates of the failures atomic
+	lock sync.Mutex
+}
+
+// Attempt verifies that a login can be attempted for the account from the IP address and records it as failed at
+// once, so that parallel logins cannot all get past the limits before any of them fails. The failure is taken back
+// with Forget once the password or code turns out to be right
+func (g *Guard) Attempt(email string, ip string) (*Attempt, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	now := g.now()
+	if err := g.check(email, ip, now); err != nil {
+		return nil, err
+	}
+	attempt := &Attempt{guard: g, email: email, ip: ip}
+	var err error
+	if attempt.account, err = g.current(accountPrefix+email, now); err != nil {
+		return nil, err
+	}
+	if attempt.address, err = g.current(ipPrefix+ip, now); err != nil {
+		return nil, err
+	}
+	if err := g.failed(email, ip, now); err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+// Attempt is a login being attempted, which counts as failed unless it is forgotten
+type Attempt struct {
+	guard *Guard
+	email string
+	ip    string
+	// account and address are the failures before the attempt, nil when there were none
+	account *userStore.Attempts
+	address *userStore.Attempts
+}
+
+// Forget takes back the failure recorded for the attempt, when the password or code was right or could not be checked
+func (a *Attempt) Forget() error {
+	a.guard.lock.Lock()
+	defer a.guard.lock.Unlock()
+	if err := a.guard.restore(accountPrefix+a.email, a.account); err != nil {
+		return err
+	}
+	return a.guard.restore(ipPrefix+a.ip, a.address)
+}
+
+func (g *Guard) check(email string, ip string, now time.Time) error {
+	var retryAt time.Time
+	for _, id := range []string{accountPrefix + email, ipPrefix + ip} {
+		attempts, err := g.current(id, now)
+		if err != nil {
+			return err
+		}
+		if attempts == nil {
+			continue
+		}
+		for _, until := range []time.Time{attempts.RetryAt, attempts.LockedUntil} {
+			if until.After(now) && until.After(retryAt) {
+				retryAt = until
+			}
+		}
+	}
+	if retryAt.IsZero() {
+		return nil
+	}
+	// the wait is rounded up to the second, so that retrying after it is never too early
+	wait := retryAt.Sub(now)
+	if rounded := wait.Truncate(time.Second); rounded < wait {
+		wait = rounded + time.Second
+	}
+	return NewTooManyAttempts(retryAt, wait)
+}
+
+func (g *Guard) failed(email string, ip string, now time.Time) error {
+	if err := g.fail(accountPrefix+email, g.config.AccountFailures, 0, now); err != nil {
+		return err
+	}
+	return g.fail(ipPrefix+ip, g.config.IPFailures, g.config.AccountFailures, now)
+}
+
+// Succeeded forgets the failed logins of the account. The failures of the IP address are kept, so that logging in to
+// one account does not allow guessing the passwords of others
+func (g *Guard) Succeeded(email string) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.remove(accountPrefix + email)
+}
+
+// UnlockAccount lifts the lockout of the account, on behalf of the given administrator
+func (g *Guard) UnlockAccount(email string, actor string) error {
+	return g.unlock(accountPrefix+email, actor)
+}
+
+// UnlockIP lifts the lockout of the IP address, on behalf of the given administrator
+func (g *Guard) UnlockIP(ip string, actor string) error {
+	return g.unlock(ipPrefix+ip, actor)
+}
+
+// GetLockouts returns the accounts and IP addresses that are locked out
+func (g *Guard) GetLockouts() ([]*userStore.Attempts, error) {
+	all, err := g.attempts.GetAllAttempts()
+	if err != nil {
+		return nil, err
+	}
+	now := g.now()
+	lockouts := []*userStore.Attempts{}
+	for _, attempts := range all {
+		if attempts.LockedUntil.After(now) {
+			lockouts = append(lockouts, attempts)
+		}
+	}
+	sort.Slice(lockouts, func(i, j int) bool {
+		return lockouts[i].ID < lockouts[j].ID
+	})
+	return lockouts, nil
+}
+
+// GetAuditEntries returns the audit entries of the lockouts, the latest first
+func (g *Guard) GetAuditEntries() ([]*userStore.AuditEntry, error) {
+	entries, err := g.attempts.GetAuditEntries()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+	return entries, nil
+}
+
+// current returns the failures of the account or IP address that are still relevant, or nil if there are none.
+// Expired lockouts and failures older than the reset threshold are forgotten
+func (g *Guard) current(id string, now time.Time) (*userStore.Attempts, error) {
+	attempts, err := g.attempts.GetAttempts(id)
+	if err != nil {
+		if store.IsNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !attempts.LockedUntil.IsZero() && !attempts.LockedUntil.After(now) {
+		if err := g.audit(userStore.Unlocked, attempts, systemActor, attempts.LockedUntil); err != nil {
+			return nil, err
+		}
+		return nil, g.remove(id)
+	}
+	if attempts.LockedUntil.IsZero() && now.Sub(attempts.LastFailure) >= g.config.ResetAfter {
+		return nil, g.remove(id)
+	}
+	return attempts, nil
+}
+
+// fail records a failure, locking out once there are threshold failures. The failures within the grace are not slowed down
+func (g *Guard) fail(id string, threshold uint, grace uint, now time.Time) error {
+	current, err := g.current(id, now)
+	if err != nil {
+		return err
+	}
+	attempts := &userStore.Attempts{ID: id}
+	if current != nil {
+		copied := *current
+		attempts = &copied
+	}
+	// failures while locked out do not extend the lockout
+	if attempts.LockedUntil.After(now) {
+		return nil
+	}
+	attempts.Failures++
+	attempts.LastFailure = now
+	if attempts.Failures > grace {
+		attempts.RetryAt = now.Add(g.delay(attempts.Failures - grace))
+	}
+	if attempts.Failures >= threshold {
+		attempts.LockedUntil = now.Add(g.config.LockoutDuration)
+		g.log.Infow("login locked out", "subject", id, "failures", attempts.Failures, "until", attempts.LockedUntil)
+		if err := g.audit(userStore.Locked, attempts, systemActor, now); err != nil {
+			return err
+		}
+	}
+	return g.attempts.SaveAttempts(attempts)
+}
+
+// restore takes back one failure of the account or IP address. The failures are put back as they were before it
+// when nothing else failed since, and otherwise only the count is lowered, keeping the waits of the other failures
+func (g *Guard) restore(id string, before *userStore.Attempts) error {
+	attempts, err := g.attempts.GetAttempts(id)
+	if err != nil {
+		if store.IsNotFoundError(err) {
+			return nil
+		}
+		return err
+	}
+	var beforeFailures uint
+	if before != nil {
+		beforeFailures = before.Failures
+	}
+	if attempts.Failures != beforeFailures+1 {
+		if attempts.Failures <= beforeFailures {
+			// the failures were reset since, so there is nothing to take back
+			return nil
+		}
+		lowered := *attempts
+		lowered.Failures--
+		return g.attempts.SaveAttempts(&lowered)
+	}
+	now := g.now()
+	if attempts.LockedUntil.After(now) && (before == nil || !before.LockedUntil.After(now)) {
+		g.log.Infow("login unlocked", "subject", id, "actor", systemActor)
+		if err := g.audit(userStore.Unlocked, attempts, systemActor, now); err != nil {
+			return err
+		}
+	}
+	if before == nil {
+		return g.remove(id)
+	}
+	restored := *before
+	return g.attempts.SaveAttempts(&restored)
+}
+
+// delay returns how long to wait after the given number of failures
+func (g *Guard) delay(failures uint) time.Duration {
+	multiplier := math.Pow(2, float64(failures-1))
+	if multiplier >= float64(g.config.MaxDelay/g.config.BaseDelay) {
+		return g.config.MaxDelay
+	}
+	return time.Duration(multiplier) * g.config.BaseDelay
+}
+
+func (g *Guard) unlock(id string, actor string) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	attempts, err := g.attempts.GetAttempts(id)
+	if err != nil {
+		return err
+	}
+	if attempts.LockedUntil.After(g.now()) {
+		g.log.Infow("login unlocked", "subject", id, "actor", actor)
+		if err := g.audit(userStore.Unlocked, attempts, actor, g.now()); err != nil {
+			return err
+		}
+	}
+	return g.remove(id)
+}
+
+func (g *Guard) remove(id string) error {
+	err := g.attempts.RemoveAttempts(id)
+	if err != nil && !store.IsNotFoundError(err) {
+		return err
+	}
+	return nil
+}
+
+func (g *Guard) audit(event userStore.AuditEvent, attempts *userStore.Attempts, actor string, at time.Time) error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	entry := &userStore.AuditEntry{
+		ID:       id,
+		Event:    event,
+		Subject:  attempts.ID,
+		Actor:    actor,
+		Failures: attempts.Failures,
+		Time:     at,
+	}
+	if event == userStore.Locked {
+		until := attempts.LockedUntil
+		entry.Until = &until
+	}
+	return g.attempts.AddAuditEntry(entry)
+}
+
+func newID() (string, error) {
+	b := make([]byte, idBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
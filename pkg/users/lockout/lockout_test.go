@@ -0,0 +1,331 @@
+package lockout_test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/users/lockout"
+	mock_lockout "github.com/mimatache/go-shop/pkg/users/lockout/mocks"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+)
+
+const (
+	userEmail = "user@email.com"
+	userIP    = "10.0.0.1"
+	adminUser = "admin@email.com"
+)
+
+var config = lockout.Config{
+	AccountFailures: 3,
+	IPFailures:      5,
+	BaseDelay:       time.Second,
+	MaxDelay:        3 * time.Second,
+	LockoutDuration: 15 * time.Minute,
+	ResetAfter:      time.Hour,
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Infow(msg string, keysAndValues ...interface{}) {}
+
+// clock is moved forward by the tests instead of waiting
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newGuard(t *testing.T) (*lockout.Guard, *clock) {
+	g := NewWithT(t)
+
+	log, _, _ := logger.New("test", true)
+
+	schema := store.NewSchema()
+	schema.AddToSchema(userStore.GetAttemptsTable())
+	schema.AddToSchema(userStore.GetAuditTable())
+	db, err := store.New(schema)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	c := &clock{now: time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)}
+	withClock := config
+	withClock.Now = c.Now
+	guard, err := lockout.New(nopLogger{}, userStore.NewLockoutStore(log, db), withClock)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	return guard, c
+}
+
+// fail makes a login with a wrong password
+func fail(guard *lockout.Guard, email string, ip string) error {
+	_, err := guard.Attempt(email, ip)
+	return err
+}
+
+// check verifies that a login can be attempted, without counting it as failed
+func check(guard *lockout.Guard, email string, ip string) error {
+	attempt, err := guard.Attempt(email, ip)
+	if err != nil {
+		return err
+	}
+	return attempt.Forget()
+}
+
+func TestGuard_Backoff(t *testing.T) {
+	g := NewWithT(t)
+
+	guard, c := newGuard(t)
+	g.Expect(fail(guard, userEmail, userIP)).To(Succeed())
+	err := check(guard, userEmail, userIP)
+	g.Expect(lockout.IsTooManyAttemptsError(err)).To(BeTrue())
+	g.Expect(lockout.RetryAfter(err)).To(Equal(time.Second))
+	g.Expect(check(guard, "other@email.com", userIP)).To(Succeed(), "other users of the IP address do not wait")
+
+	c.Advance(time.Second)
+	g.Expect(fail(guard, userEmail, userIP)).To(Succeed())
+	g.Expect(lockout.RetryAfter(check(guard, userEmail, userIP))).To(Equal(2*time.Second), "the wait doubles")
+
+	c.Advance(2 * time.Second)
+	g.Expect(guard.Succeeded(userEmail)).To(Succeed())
+	g.Expect(check(guard, userEmail, "10.0.0.2")).To(Succeed(), "the failures of the account are forgotten")
+	g.Expect(check(guard, "other@email.com", userIP)).To(Succeed())
+}
+
+func TestGuard_IPBackoff(t *testing.T) {
+	g := NewWithT(t)
+
+	guard, c := newGuard(t)
+	for i := 0; i < int(config.AccountFailures); i++ {
+		g.Expect(fail(guard, fmt.Sprintf("user%d@email.com", i), userIP)).To(Succeed())
+	}
+	g.Expect(check(guard, userEmail, userIP)).To(Succeed(), "the IP address failed no more than an account can")
+
+	g.Expect(fail(guard, userEmail, userIP)).To(Succeed())
+	err := check(guard, "other@email.com", userIP)
+	g.Expect(lockout.RetryAfter(err)).To(Equal(time.Second))
+
+	c.Advance(time.Second)
+	g.Expect(check(guard, "other@email.com", userIP)).To(Succeed())
+}
+
+func TestGuard_LocksOutAccount(t *testing.T) {
+	g := NewWithT(t)
+
+	guard, c := newGuard(t)
+	for i := 0; i < int(config.AccountFailures); i++ {
+		g.Expect(fail(guard, userEmail, fmt.Sprintf("10.0.0.%d", i))).To(Succeed())
+		c.Advance(config.MaxDelay)
+	}
+
+	err := check(guard, userEmail, "10.0.1.1")
+	g.Expect(lockout.IsTooManyAttemptsError(err)).To(BeTrue())
+	g.Expect(lockout.RetryAfter(err)).To(Equal(config.LockoutDuration - config.MaxDelay))
+
+	// logins refused while locked out do not extend the lockout
+	g.Expect(lockout.IsTooManyAttemptsError(fail(guard, userEmail, "10.0.1.1"))).To(BeTrue())
+
+	lockouts, err := guard.GetLockouts()
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(lockouts).To(HaveLen(1))
+	g.Expect(lockouts[0].ID).To(Equal("account:" + userEmail))
+	g.Expect(lockouts[0].Failures).To(Equal(config.AccountFailures))
+
+	c.Advance(config.LockoutDuration)
+	g.Expect(check(guard, userEmail, "10.0.1.1")).To(Succeed(), "the lockout expired")
+
+	entries, err := guard.GetAuditEntries()
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(entries).To(HaveLen(2))
+	g.Expect(entries[0].Event).To(Equal(userStore.Unlocked))
+	g.Expect(entries[0].Actor).To(Equal("system"))
+	g.Expect(entries[1].Event).To(Equal(userStore.Locked))
+	g.Expect(entries[1].Subject).To(Equal("account:" + userEmail))
+	g.Expect(*entries[1].Until).To(Equal(lockouts[0].LockedUntil))
+	g.Expect(entries[0].Until).To(BeNil())
+}
+
+func TestGuard_LocksOutIP(t *testing.T) {
+	g := NewWithT(t)
+
+	guard, c := newGuard(t)
+	for i := 0; i < int(config.IPFailures); i++ {
+		g.Expect(fail(guard, fmt.Sprintf("user%d@email.com", i), userIP)).To(Succeed())
+		c.Advance(config.MaxDelay)
+	}
+
+	g.Expect(lockout.IsTooManyAttemptsError(check(guard, userEmail, userIP))).To(BeTrue())
+	g.Expect(check(guard, userEmail, "10.0.0.2")).To(Succeed())
+
+	g.Expect(guard.UnlockIP(userIP, adminUser)).To(Succeed())
+	g.Expect(check(guard, userEmail, userIP)).To(Succeed())
+
+	entries, err := guard.GetAuditEntries()
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(entries).To(HaveLen(2))
+	g.Expect(entries[0].Event).To(Equal(userStore.Unlocked))
+	g.Expect(entries[0].Subject).To(Equal("ip:" + userIP))
+	g.Expect(entries[0].Actor).To(Equal(adminUser))
+}
+
+func TestGuard_UnlockAccount(t *testing.T) {
+	g := NewWithT(t)
+
+	guard, _ := newGuard(t)
+	err := guard.UnlockAccount(userEmail, adminUser)
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue())
+
+	g.Expect(fail(guard, userEmail, userIP)).To(Succeed())
+	g.Expect(guard.UnlockAccount(userEmail, adminUser)).To(Succeed())
+	g.Expect(check(guard, userEmail, "10.0.0.2")).To(Succeed())
+
+	entries, err := guard.GetAuditEntries()
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(entries).To(BeEmpty(), "only lifting a lockout is audited")
+}
+
+func TestGuard_ForgetsOldFailures(t *testing.T) {
+	g := NewWithT(t)
+
+	guard, c := newGuard(t)
+	for i := 0; i < int(config.AccountFailures)-1; i++ {
+		g.Expect(fail(guard, userEmail, userIP)).To(Succeed())
+		c.Advance(config.MaxDelay)
+	}
+
+	c.Advance(config.ResetAfter)
+	g.Expect(fail(guard, userEmail, userIP)).To(Succeed())
+	err := check(guard, userEmail, userIP)
+	g.Expect(lockout.RetryAfter(err)).To(Equal(time.Second), "the failures start over")
+
+	lockouts, err := guard.GetLockouts()
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(lockouts).To(BeEmpty())
+}
+
+// attemptInParallel attempts the logins all at once, each with a wrong password, and returns how many of them got
+// to check the password
+func attemptInParallel(guard *lockout.Guard, logins [][2]string) int {
+	var checked int32
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for _, login := range logins {
+		wg.Add(1)
+		go func(email string, ip string) {
+			defer wg.Done()
+			<-start
+			if _, err := guard.Attempt(email, ip); err == nil {
+				// the password is wrong, so the attempt is not forgotten
+				atomic.AddInt32(&checked, 1)
+			}
+		}(login[0], login[1])
+	}
+	close(start)
+	wg.Wait()
+	return int(checked)
+}
+
+func TestGuard_Attempt_ParallelAccount(t *testing.T) {
+	g := NewWithT(t)
+
+	guard, _ := newGuard(t)
+	logins := [][2]string{}
+	for i := 0; i < 10*int(config.AccountFailures); i++ {
+		logins = append(logins, [2]string{userEmail, fmt.Sprintf("10.0.0.%d", i)})
+	}
+
+	checked := attemptInParallel(guard, logins)
+	g.Expect(checked).To(BeNumerically(">", 0))
+	g.Expect(checked).To(BeNumerically("<=", config.AccountFailures))
+	err := check(guard, userEmail, "10.0.1.1")
+	g.Expect(lockout.IsTooManyAttemptsError(err)).To(BeTrue(), "the attempts that got through count as failed")
+}
+
+func TestGuard_Attempt_ParallelIP(t *testing.T) {
+	g := NewWithT(t)
+
+	guard, _ := newGuard(t)
+	logins := [][2]string{}
+	for i := 0; i < 10*int(config.IPFailures); i++ {
+		logins = append(logins, [2]string{fmt.Sprintf("user%d@email.com", i), userIP})
+	}
+
+	checked := attemptInParallel(guard, logins)
+	g.Expect(checked).To(BeNumerically(">", 0))
+	g.Expect(checked).To(BeNumerically("<=", config.IPFailures))
+}
+
+func TestGuard_Attempt_Forget(t *testing.T) {
+	g := NewWithT(t)
+
+	guard, c := newGuard(t)
+	attempt, err := guard.Attempt(userEmail, userIP)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(lockout.IsTooManyAttemptsError(check(guard, userEmail, userIP))).To(BeTrue(), "the attempt counts as failed")
+	g.Expect(attempt.Forget()).To(Succeed())
+	g.Expect(check(guard, userEmail, userIP)).To(Succeed(), "the failure was taken back")
+
+	for i := 0; i < int(config.AccountFailures)-1; i++ {
+		g.Expect(fail(guard, userEmail, userIP)).To(Succeed())
+		c.Advance(config.MaxDelay)
+	}
+	attempt, err = guard.Attempt(userEmail, userIP)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	lockouts, err := guard.GetLockouts()
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(lockouts).To(HaveLen(1), "the attempt reached the threshold")
+
+	g.Expect(attempt.Forget()).To(Succeed())
+	lockouts, err = guard.GetLockouts()
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(lockouts).To(BeEmpty())
+
+	// the failures before the attempt are kept, so the next one locks the account out
+	g.Expect(fail(guard, userEmail, userIP)).To(Succeed())
+	lockouts, err = guard.GetLockouts()
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(lockouts).To(HaveLen(1))
+
+	entries, err := guard.GetAuditEntries()
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(entries).To(HaveLen(3), "the lockout of the forgotten attempt is audited as lifted")
+}
+
+func TestGuard_StoreFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	attempts := mock_lockout.NewMockAttemptRegistry(ctrl)
+	guard, err := lockout.New(nopLogger{}, attempts, config)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	attempts.EXPECT().GetAttempts("account:"+userEmail).Return(nil, fmt.Errorf("store unavailable"))
+
+	_, err = guard.Attempt(userEmail, userIP)
+	g.Expect(err).Should(HaveOccurred())
+	g.Expect(lockout.IsTooManyAttemptsError(err)).To(BeFalse())
+}
+
+func TestConfig_Validate(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(config.Validate()).To(Succeed())
+
+	invalid := config
+	invalid.AccountFailures = 0
+	invalid.MaxDelay = time.Millisecond
+	_, err := lockout.New(nopLogger{}, nil, invalid)
+	g.Expect(err).Should(HaveOccurred())
+}
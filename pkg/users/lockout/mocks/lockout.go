@@ -0,0 +1,161 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./lockout.go
+
+// Package mock_lockout is a generated GoMock package.
+package mock_lockout
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	store "github.com/mimatache/go-shop/pkg/users/store"
+	reflect "reflect"
+)
+
+// MockAttemptRegistry is a mock of AttemptRegistry interface
+type MockAttemptRegistry struct {
+	ctrl     *gomock.Controller
+	recorder *MockAttemptRegistryMockRecorder
+}
+
+// MockAttemptRegistryMockRecorder is the mock recorder for MockAttemptRegistry
+type MockAttemptRegistryMockRecorder struct {
+	mock *MockAttemptRegistry
+}
+
+// NewMockAttemptRegistry creates a new mock instance
+func NewMockAttemptRegistry(ctrl *gomock.Controller) *MockAttemptRegistry {
+	mock := &MockAttemptRegistry{ctrl: ctrl}
+	mock.recorder = &MockAttemptRegistryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAttemptRegistry) EXPECT() *MockAttemptRegistryMockRecorder {
+	return m.recorder
+}
+
+// GetAttempts mocks base method
+func (m *MockAttemptRegistry) GetAttempts(id string) (*store.Attempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttempts", id)
+	ret0, _ := ret[0].(*store.Attempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAttempts indicates an expected call of GetAttempts
+func (mr *MockAttemptRegistryMockRecorder) GetAttempts(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttempts", reflect.TypeOf((*MockAttemptRegistry)(nil).GetAttempts), id)
+}
+
+// GetAllAttempts mocks base method
+func (m *MockAttemptRegistry) GetAllAttempts() ([]*store.Attempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllAttempts")
+	ret0, _ := ret[0].([]*store.Attempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllAttempts indicates an expected call of GetAllAttempts
+func (mr *MockAttemptRegistryMockRecorder) GetAllAttempts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllAttempts", reflect.TypeOf((*MockAttemptRegistry)(nil).GetAllAttempts))
+}
+
+// SaveAttempts mocks base method
+func (m *MockAttemptRegistry) SaveAttempts(attempts *store.Attempts) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAttempts", attempts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAttempts indicates an expected call of SaveAttempts
+func (mr *MockAttemptRegistryMockRecorder) SaveAttempts(attempts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAttempts", reflect.TypeOf((*MockAttemptRegistry)(nil).SaveAttempts), attempts)
+}
+
+// RemoveAttempts mocks base method
+func (m *MockAttemptRegistry) RemoveAttempts(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveAttempts", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveAttempts indicates an expected call of RemoveAttempts
+func (mr *MockAttemptRegistryMockRecorder) RemoveAttempts(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAttempts", reflect.TypeOf((*MockAttemptRegistry)(nil).RemoveAttempts), id)
+}
+
+// AddAuditEntry mocks base method
+func (m *MockAttemptRegistry) AddAuditEntry(entry *store.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAuditEntry", entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAuditEntry indicates an expected call of AddAuditEntry
+func (mr *MockAttemptRegistryMockRecorder) AddAuditEntry(entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAuditEntry", reflect.TypeOf((*MockAttemptRegistry)(nil).AddAuditEntry), entry)
+}
+
+// GetAuditEntries mocks base method
+func (m *MockAttemptRegistry) GetAuditEntries() ([]*store.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEntries")
+	ret0, _ := ret[0].([]*store.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEntries indicates an expected call of GetAuditEntries
+func (mr *MockAttemptRegistryMockRecorder) GetAuditEntries() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEntries", reflect.TypeOf((*MockAttemptRegistry)(nil).GetAuditEntries))
+}
+
+// Mocklogger is a mock of logger interface
+type Mocklogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockloggerMockRecorder
+}
+
+// MockloggerMockRecorder is the mock recorder for Mocklogger
+type MockloggerMockRecorder struct {
+	mock *Mocklogger
+}
+
+// NewMocklogger creates a new mock instance
+func NewMocklogger(ctrl *gomock.Controller) *Mocklogger {
+	mock := &Mocklogger{ctrl: ctrl}
+	mock.recorder = &MockloggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *Mocklogger) EXPECT() *MockloggerMockRecorder {
+	return m.recorder
+}
+
+// Infow mocks base method
+func (m *Mocklogger) Infow(msg string, keysAndValues ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range keysAndValues {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Infow", varargs...)
+}
+
+// Infow indicates an expected call of Infow
+func (mr *MockloggerMockRecorder) Infow(msg interface{}, keysAndValues ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, keysAndValues...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Infow", reflect.TypeOf((*Mocklogger)(nil).Infow), varargs...)
+}
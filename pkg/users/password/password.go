@@ -0,0 +1,209 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// Argon2id hashes the passwords with argon2id
+	Argon2id = "argon2id"
+	// Bcrypt hashes the passwords with bcrypt
+	Bcrypt = "bcrypt"
+)
+
+const (
+	saltBytes = 16
+	keyBytes  = 32
+
+	argon2Prefix = "$argon2id$"
+	argon2Format = "$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s"
+)
+
+// bcryptPrefixes are the versions of bcrypt a hash can start with
+var bcryptPrefixes = []string{"$2a$", "$2b$", "$2y$"}
+
+// Config selects the algorithm passwords are hashed with and its cost. Zero values are replaced by defaults
+type Config struct {
+	Algorithm string
+	// BcryptCost is the logarithm of the number of bcrypt rounds
+	BcryptCost int
+	// Argon2Time is the number of passes argon2id makes over the memory
+	Argon2Time uint32
+	// Argon2Memory is the memory argon2id uses, in KiB
+	Argon2Memory uint32
+	// Argon2Threads is the number of threads argon2id uses
+	Argon2Threads uint8
+}
+
+func (c Config) withDefaults() Config {
+	if c.Algorithm == "" {
+		c.Algorithm = Argon2id
+	}
+	if c.BcryptCost == 0 {
+		c.BcryptCost = bcrypt.DefaultCost
+	}
+	if c.Argon2Time == 0 {
+		c.Argon2Time = 1
+	}
+	if c.Argon2Memory == 0 {
+		c.Argon2Memory = 64 * 1024
+	}
+	if c.Argon2Threads == 0 {
+		c.Argon2Threads = 4
+	}
+	return c
+}
+
+// Validate checks that the configuration can be used to hash passwords
+func (c Config) Validate() error {
+	switch c.Algorithm {
+	case Argon2id:
+		if c.Argon2Memory < 8*uint32(c.Argon2Threads) {
+			return fmt.Errorf("argon2id needs at least 8KiB of memory per thread")
+		}
+	case Bcrypt:
+		if c.BcryptCost < bcrypt.MinCost || c.BcryptCost > bcrypt.MaxCost {
+			return fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	default:
+		return fmt.Errorf("unknown password hashing algorithm %s", c.Algorithm)
+	}
+	return nil
+}
+
+// New creates a hasher using the given configuration
+func New(config Config) (*Hasher, error) {
+	config = config.withDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &Hasher{config: config}, nil
+}
+
+// Hasher hashes passwords with a random salt and checks passwords against their hashes. The hashes carry the algorithm
+// and the parameters they were made with, so that hashes made with another configuration can still be checked
+type Hasher struct {
+	config Config
+}
+
+// Hash returns the salted hash of the password
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.config.Algorithm == Bcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.config.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	}
+	salt := make([]byte, saltBytes)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.config.Argon2Time, h.config.Argon2Memory, h.config.Argon2Threads, keyBytes)
+	return fmt.Sprintf(
+		argon2Format,
+		argon2.Version,
+		h.config.Argon2Memory,
+		h.config.Argon2Time,
+		h.config.Argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify checks if the password matches the hash. The comparison takes the same time wherever the hashes differ
+func (h *Hasher) Verify(hash string, password string) (bool, error) {
+	if isBcrypt(hash) {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			return false, nil
+		}
+		return err == nil, err
+	}
+	params, salt, key, err := decodeArgon2(hash)
+	if err != nil {
+		return false, err
+	}
+	computed := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, computed) == 1, nil
+}
+
+// NeedsRehash checks if the hash was made with another algorithm or other parameters than the configured ones
+func (h *Hasher) NeedsRehash(hash string) bool {
+	if isBcrypt(hash) {
+		if h.config.Algorithm != Bcrypt {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != h.config.BcryptCost
+	}
+	if h.config.Algorithm != Argon2id {
+		return true
+	}
+	params, _, _, err := decodeArgon2(hash)
+	if err != nil {
+		return true
+	}
+	return params.time != h.config.Argon2Time || params.memory != h.config.Argon2Memory || params.threads != h.config.Argon2Threads
+}
+
+// IsHash checks if the value is a hash this package can verify, as opposed to a password in plain text
+func (h *Hasher) IsHash(value string) bool {
+	return isBcrypt(value) || strings.HasPrefix(value, argon2Prefix)
+}
+
+func isBcrypt(hash string) bool {
+	for _, prefix := range bcryptPrefixes {
+		if strings.HasPrefix(hash, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+type argon2Params struct {
+	time    uint32
+	memory  uint32
+	threads uint8
+}
+
+// decodeArgon2 reads the parameters, the salt and the key of an argon2id hash
+func decodeArgon2(hash string) (*argon2Params, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != Argon2id {
+		return nil, nil, nil, fmt.Errorf("not an argon2id hash")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid argon2id version: %w", err)
+	}
+	if version != argon2.Version {
+		return nil, nil, nil, fmt.Errorf("unsupported argon2id version %d", version)
+	}
+	params := &argon2Params{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+	if params.time == 0 || params.threads == 0 {
+		return nil, nil, nil, fmt.Errorf("invalid argon2id parameters: %s", parts[3])
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid argon2id key: %w", err)
+	}
+	if len(salt) == 0 || len(key) == 0 {
+		return nil, nil, nil, fmt.Errorf("argon2id hash without salt or key")
+	}
+	return params, salt, key, nil
+}
@@ -0,0 +1,136 @@
+package password_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/pkg/users/password"
+)
+
+// the cheap configurations keep the hashes fast enough for tests
+var (
+	cheapArgon2 = password.Config{Algorithm: password.Argon2id, Argon2Time: 1, Argon2Memory: 64, Argon2Threads: 1}
+	cheapBcrypt = password.Config{Algorithm: password.Bcrypt, BcryptCost: 4}
+)
+
+func TestHasher_HashAndVerify(t *testing.T) {
+	configs := map[string]password.Config{
+		"argon2id": cheapArgon2,
+		"bcrypt":   cheapBcrypt,
+	}
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			hasher, err := password.New(config)
+			g.Expect(err).ShouldNot(HaveOccurred())
+
+			hash, err := hasher.Hash("1234")
+			g.Expect(err).ShouldNot(HaveOccurred())
+			g.Expect(hash).ShouldNot(ContainSubstring("1234"))
+			g.Expect(hasher.IsHash(hash)).To(BeTrue())
+			g.Expect(hasher.NeedsRehash(hash)).To(BeFalse())
+
+			other, err := hasher.Hash("1234")
+			g.Expect(err).ShouldNot(HaveOccurred())
+			g.Expect(other).ShouldNot(Equal(hash), "every hash is salted")
+
+			ok, err := hasher.Verify(hash, "1234")
+			g.Expect(err).ShouldNot(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+
+			ok, err = hasher.Verify(hash, "12345")
+			g.Expect(err).ShouldNot(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+		})
+	}
+}
+
+func TestHasher_Argon2idFormat(t *testing.T) {
+	g := NewWithT(t)
+
+	hasher, err := password.New(cheapArgon2)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	hash, err := hasher.Hash("1234")
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$")).To(BeTrue(), hash)
+}
+
+func TestHasher_NeedsRehash(t *testing.T) {
+	g := NewWithT(t)
+
+	argon2, err := password.New(cheapArgon2)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	bcrypt, err := password.New(cheapBcrypt)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	stronger := cheapArgon2
+	stronger.Argon2Time = 2
+	strongerArgon2, err := password.New(stronger)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	costlier := cheapBcrypt
+	costlier.BcryptCost = 5
+	costlierBcrypt, err := password.New(costlier)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	argon2Hash, err := argon2.Hash("1234")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	bcryptHash, err := bcrypt.Hash("1234")
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	g.Expect(bcrypt.NeedsRehash(argon2Hash)).To(BeTrue(), "the algorithm changed")
+	g.Expect(argon2.NeedsRehash(bcryptHash)).To(BeTrue(), "the algorithm changed")
+	g.Expect(strongerArgon2.NeedsRehash(argon2Hash)).To(BeTrue(), "the argon2id parameters changed")
+	g.Expect(costlierBcrypt.NeedsRehash(bcryptHash)).To(BeTrue(), "the bcrypt cost changed")
+
+	ok, err := argon2.Verify(bcryptHash, "1234")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(ok).To(BeTrue(), "hashes made with another algorithm can still be checked")
+	ok, err = strongerArgon2.Verify(argon2Hash, "1234")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(ok).To(BeTrue(), "hashes made with other parameters can still be checked")
+}
+
+func TestHasher_VerifyInvalidHash(t *testing.T) {
+	hashes := []string{
+		"1234",
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdA",
+		"$argon2id$v=19$m=64,t=0,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdA$",
+		"$argon2id$v=18$m=64,t=1,p=1$c2FsdA$a2V5",
+		"$2a$04$short",
+	}
+	for _, hash := range hashes {
+		t.Run(hash, func(t *testing.T) {
+			g := NewWithT(t)
+
+			hasher, err := password.New(cheapArgon2)
+			g.Expect(err).ShouldNot(HaveOccurred())
+
+			ok, err := hasher.Verify(hash, "1234")
+
+			g.Expect(err).Should(HaveOccurred())
+			g.Expect(ok).To(BeFalse())
+		})
+	}
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	configs := map[string]password.Config{
+		"unknown algorithm": {Algorithm: "md5"},
+		"bcrypt cost":       {Algorithm: password.Bcrypt, BcryptCost: 32},
+		"argon2id memory":   {Algorithm: password.Argon2id, Argon2Memory: 8, Argon2Threads: 2},
+	}
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			_, err := password.New(config)
+
+			g.Expect(err).Should(HaveOccurred())
+		})
+	}
+}
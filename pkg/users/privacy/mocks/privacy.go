@@ -0,0 +1,139 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./privacy.go
+
+// Package mock_privacy is a generated GoMock package.
+package mock_privacy
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockPersonalData is a mock of PersonalData interface
+type MockPersonalData struct {
+	ctrl     *gomock.Controller
+	recorder *MockPersonalDataMockRecorder
+}
+
+// MockPersonalDataMockRecorder is the mock recorder for MockPersonalData
+type MockPersonalDataMockRecorder struct {
+	mock *MockPersonalData
+}
+
+// NewMockPersonalData creates a new mock instance
+func NewMockPersonalData(ctrl *gomock.Controller) *MockPersonalData {
+	mock := &MockPersonalData{ctrl: ctrl}
+	mock.recorder = &MockPersonalDataMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPersonalData) EXPECT() *MockPersonalDataMockRecorder {
+	return m.recorder
+}
+
+// Export mocks base method
+func (m *MockPersonalData) Export(userID string) (map[string][]interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", userID)
+	ret0, _ := ret[0].(map[string][]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export
+func (mr *MockPersonalDataMockRecorder) Export(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockPersonalData)(nil).Export), userID)
+}
+
+// Erase mocks base method
+func (m *MockPersonalData) Erase(userID, pseudonym string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Erase", userID, pseudonym)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Erase indicates an expected call of Erase
+func (mr *MockPersonalDataMockRecorder) Erase(userID, pseudonym interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Erase", reflect.TypeOf((*MockPersonalData)(nil).Erase), userID, pseudonym)
+}
+
+// MockCredentialChecker is a mock of CredentialChecker interface
+type MockCredentialChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockCredentialCheckerMockRecorder
+}
+
+// MockCredentialCheckerMockRecorder is the mock recorder for MockCredentialChecker
+type MockCredentialCheckerMockRecorder struct {
+	mock *MockCredentialChecker
+}
+
+// NewMockCredentialChecker creates a new mock instance
+func NewMockCredentialChecker(ctrl *gomock.Controller) *MockCredentialChecker {
+	mock := &MockCredentialChecker{ctrl: ctrl}
+	mock.recorder = &MockCredentialCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCredentialChecker) EXPECT() *MockCredentialCheckerMockRecorder {
+	return m.recorder
+}
+
+// IsValid mocks base method
+func (m *MockCredentialChecker) IsValid(username, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsValid", username, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IsValid indicates an expected call of IsValid
+func (mr *MockCredentialCheckerMockRecorder) IsValid(username, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsValid", reflect.TypeOf((*MockCredentialChecker)(nil).IsValid), username, password)
+}
+
+// Mocklogger is a mock of logger interface
+type Mocklogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockloggerMockRecorder
+}
+
+// MockloggerMockRecorder is the mock recorder for Mocklogger
+type MockloggerMockRecorder struct {
+	mock *Mocklogger
+}
+
+// NewMocklogger creates a new mock instance
+func NewMocklogger(ctrl *gomock.Controller) *Mocklogger {
+	mock := &Mocklogger{ctrl: ctrl}
+	mock.recorder = &MockloggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *Mocklogger) EXPECT() *MockloggerMockRecorder {
+	return m.recorder
+}
+
+// Infow mocks base method
+func (m *Mocklogger) Infow(msg string, keysAndValues ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range keysAndValues {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Infow", varargs...)
+}
+
+// Infow indicates an expected call of Infow
+func (mr *MockloggerMockRecorder) Infow(msg interface{}, keysAndValues ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, keysAndValues...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Infow", reflect.TypeOf((*Mocklogger)(nil).Infow), varargs...)
+}
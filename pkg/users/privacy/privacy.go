@@ -0,0 +1,101 @@
+package privacy
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/mimatache/go-shop/internal/store"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+)
+
+//go:generate mockgen -source ./privacy.go -destination mocks/privacy.go
+
+const (
+	// pseudonymPrefix starts the pseudonym the records kept after an account is deleted are left under
+	pseudonymPrefix = "deleted-"
+	pseudonymBytes  = 8
+)
+
+// PersonalData exports and erases the personal data of the users, from every table holding it
+type PersonalData interface {
+	Export(userID string) (map[string][]interface{}, error)
+	Erase(userID string, pseudonym string) error
+}
+
+// CredentialChecker checks the passwords of the users
+type CredentialChecker interface {
+	IsValid(username, password string) error
+}
+
+type logger interface {
+	Infow(msg string, keysAndValues ...interface{})
+}
+
+// Archive holds everything the shop stores about a user, by table
+type Archive struct {
+	User       string                   `json:"user"`
+	ExportedAt time.Time                `json:"exportedAt"`
+	Data       map[string][]interface{} `json:"data"`
+}
+
+// Config sets how the requests are handled
+type Config struct {
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+}
+
+// New creates a new Requests
+func New(log logger, data PersonalData, credentials CredentialChecker, config Config) *Requests {
+	now := config.Now
+	if now == nil {
+		now = time.Now
+	}
+	return &Requests{
+		log:         log,
+		data:        data,
+		credentials: credentials,
+		now:         now,
+	}
+}
+
+// Requests handles the requests of the users about their personal data: exporting it and deleting their account.
+// Deleting an account removes the personal data, while the orders, payments and other records the shop has to
+// keep are left under a pseudonym, stripped of anything that identifies the user
+type Requests struct {
+	log         logger
+	data        PersonalData
+	credentials CredentialChecker
+	now         func() time.Time
+}
+
+// Export returns everything the shop stores about the user
+func (r *Requests) Export(email string) (*Archive, error) {
+	data, err := r.data.Export(email)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := data[userStore.GetTable().GetName()]; !ok {
+		return nil, store.NewNotFoundError(userStore.GetTable().GetName(), "email", email)
+	}
+	r.log.Infow("personal data exported", "user", email)
+	return &Archive{User: email, ExportedAt: r.now(), Data: data}, nil
+}
+
+// DeleteAccount checks the password of the user before deleting their account. Accounts with orders still to ship
+// cannot be deleted, which is reported with a store.CannotErase error
+func (r *Requests) DeleteAccount(email string, password string) error {
+	if err := r.credentials.IsValid(email, password); err != nil {
+		return err
+	}
+	b := make([]byte, pseudonymBytes)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	pseudonym := pseudonymPrefix + hex.EncodeToString(b)
+	if err := r.data.Erase(email, pseudonym); err != nil {
+		return err
+	}
+	r.log.Infow("account deleted", "pseudonym", pseudonym)
+	return nil
+}
@@ -0,0 +1,125 @@
+package privacy_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/users/authentication"
+	"github.com/mimatache/go-shop/pkg/users/privacy"
+	mock_privacy "github.com/mimatache/go-shop/pkg/users/privacy/mocks"
+)
+
+const (
+	userEmail = "user@email.com"
+	password  = "1234"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Infow(msg string, keysAndValues ...interface{}) {}
+
+func newRequests(t *testing.T, now time.Time) (*privacy.Requests, *mock_privacy.MockPersonalData, *mock_privacy.MockCredentialChecker, func()) {
+	ctrl := gomock.NewController(t)
+	data := mock_privacy.NewMockPersonalData(ctrl)
+	credentials := mock_privacy.NewMockCredentialChecker(ctrl)
+	requests := privacy.New(nopLogger{}, data, credentials, privacy.Config{Now: func() time.Time { return now }})
+	return requests, data, credentials, ctrl.Finish
+}
+
+func TestRequests_Export(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+	requests, data, _, finish := newRequests(t, now)
+	defer finish()
+
+	exported := map[string][]interface{}{
+		"user":  {"profile"},
+		"order": {"first", "second"},
+	}
+	data.EXPECT().Export(userEmail).Return(exported, nil)
+
+	archive, err := requests.Export(userEmail)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(archive).To(Equal(&privacy.Archive{User: userEmail, ExportedAt: now, Data: exported}))
+}
+
+func TestRequests_Export_UnknownUser(t *testing.T) {
+	g := NewWithT(t)
+
+	requests, data, _, finish := newRequests(t, time.Now())
+	defer finish()
+
+	data.EXPECT().Export(userEmail).Return(map[string][]interface{}{}, nil)
+
+	_, err := requests.Export(userEmail)
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue())
+}
+
+func TestRequests_DeleteAccount(t *testing.T) {
+	g := NewWithT(t)
+
+	requests, data, credentials, finish := newRequests(t, time.Now())
+	defer finish()
+
+	var pseudonyms []string
+	credentials.EXPECT().IsValid(userEmail, password).Return(nil).Times(2)
+	data.EXPECT().Erase(userEmail, gomock.Any()).DoAndReturn(func(userID string, pseudonym string) error {
+		pseudonyms = append(pseudonyms, pseudonym)
+		return nil
+	}).Times(2)
+
+	g.Expect(requests.DeleteAccount(userEmail, password)).To(Succeed())
+	g.Expect(requests.DeleteAccount(userEmail, password)).To(Succeed())
+	g.Expect(pseudonyms).To(HaveLen(2))
+	g.Expect(strings.HasPrefix(pseudonyms[0], "deleted-")).To(BeTrue())
+	g.Expect(pseudonyms[0]).ToNot(ContainSubstring(userEmail))
+	g.Expect(pseudonyms[0]).ToNot(Equal(pseudonyms[1]), "every deletion gets its own pseudonym")
+}
+
+func TestRequests_DeleteAccount_Refused(t *testing.T) {
+	tests := []struct {
+		name        string
+		credentials error
+		erase       error
+		check       func(err error) bool
+	}{
+		{
+			name:        "wrong password",
+			credentials: authentication.NewInvalidCredentials(userEmail),
+			check:       authentication.IsInvalidCredentialsError,
+		},
+		{
+			name:  "orders to ship",
+			erase: store.NewCannotEraseError("order", "order 1 still has items to ship"),
+			check: store.IsCannotEraseError,
+		},
+		{
+			name:  "store failure",
+			erase: fmt.Errorf("store unavailable"),
+			check: func(err error) bool { return err != nil },
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			requests, data, credentials, finish := newRequests(t, time.Now())
+			defer finish()
+
+			credentials.EXPECT().IsValid(userEmail, password).Return(test.credentials)
+			if test.credentials == nil {
+				data.EXPECT().Erase(userEmail, gomock.Any()).Return(test.erase)
+			}
+
+			err := requests.DeleteAccount(userEmail, password)
+			g.Expect(test.check(err)).To(BeTrue(), "%v", err)
+		})
+	}
+}
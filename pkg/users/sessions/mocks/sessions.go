@@ -0,0 +1,161 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./sessions.go
+
+// Package mock_sessions is a generated GoMock package.
+package mock_sessions
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	store "github.com/mimatache/go-shop/pkg/users/store"
+	reflect "reflect"
+)
+
+// MockSessionRegistry is a mock of SessionRegistry interface
+type MockSessionRegistry struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionRegistryMockRecorder
+}
+
+// MockSessionRegistryMockRecorder is the mock recorder for MockSessionRegistry
+type MockSessionRegistryMockRecorder struct {
+	mock *MockSessionRegistry
+}
+
+// NewMockSessionRegistry creates a new mock instance
+func NewMockSessionRegistry(ctrl *gomock.Controller) *MockSessionRegistry {
+	mock := &MockSessionRegistry{ctrl: ctrl}
+	mock.recorder = &MockSessionRegistryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSessionRegistry) EXPECT() *MockSessionRegistryMockRecorder {
+	return m.recorder
+}
+
+// GetSession mocks base method
+func (m *MockSessionRegistry) GetSession(id string) (*store.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", id)
+	ret0, _ := ret[0].(*store.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession
+func (mr *MockSessionRegistryMockRecorder) GetSession(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockSessionRegistry)(nil).GetSession), id)
+}
+
+// GetSessionsFor mocks base method
+func (m *MockSessionRegistry) GetSessionsFor(email string) ([]*store.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionsFor", email)
+	ret0, _ := ret[0].([]*store.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionsFor indicates an expected call of GetSessionsFor
+func (mr *MockSessionRegistryMockRecorder) GetSessionsFor(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionsFor", reflect.TypeOf((*MockSessionRegistry)(nil).GetSessionsFor), email)
+}
+
+// SaveSession mocks base method
+func (m *MockSessionRegistry) SaveSession(session *store.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSession", session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSession indicates an expected call of SaveSession
+func (mr *MockSessionRegistryMockRecorder) SaveSession(session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSession", reflect.TypeOf((*MockSessionRegistry)(nil).SaveSession), session)
+}
+
+// RemoveSession mocks base method
+func (m *MockSessionRegistry) RemoveSession(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveSession", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveSession indicates an expected call of RemoveSession
+func (mr *MockSessionRegistryMockRecorder) RemoveSession(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveSession", reflect.TypeOf((*MockSessionRegistry)(nil).RemoveSession), id)
+}
+
+// GetRefreshToken mocks base method
+func (m *MockSessionRegistry) GetRefreshToken(id string) (*store.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshToken", id)
+	ret0, _ := ret[0].(*store.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshToken indicates an expected call of GetRefreshToken
+func (mr *MockSessionRegistryMockRecorder) GetRefreshToken(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshToken", reflect.TypeOf((*MockSessionRegistry)(nil).GetRefreshToken), id)
+}
+
+// SaveRefreshToken mocks base method
+func (m *MockSessionRegistry) SaveRefreshToken(token *store.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRefreshToken", token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRefreshToken indicates an expected call of SaveRefreshToken
+func (mr *MockSessionRegistryMockRecorder) SaveRefreshToken(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRefreshToken", reflect.TypeOf((*MockSessionRegistry)(nil).SaveRefreshToken), token)
+}
+
+// Mocklogger is a mock of logger interface
+type Mocklogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockloggerMockRecorder
+}
+
+// MockloggerMockRecorder is the mock recorder for Mocklogger
+type MockloggerMockRecorder struct {
+	mock *Mocklogger
+}
+
+// NewMocklogger creates a new mock instance
+func NewMocklogger(ctrl *gomock.Controller) *Mocklogger {
+	mock := &Mocklogger{ctrl: ctrl}
+	mock.recorder = &MockloggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *Mocklogger) EXPECT() *MockloggerMockRecorder {
+	return m.recorder
+}
+
+// Infow mocks base method
+func (m *Mocklogger) Infow(msg string, keysAndValues ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range keysAndValues {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Infow", varargs...)
+}
+
+// Infow indicates an expected call of Infow
+func (mr *MockloggerMockRecorder) Infow(msg interface{}, keysAndValues ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, keysAndValues...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Infow", reflect.TypeOf((*Mocklogger)(nil).Infow), varargs...)
+}
@@ -0,0 +1,275 @@
+package sessions
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mimatache/go-shop/internal/store"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+)
+
+//go:generate mockgen -source ./sessions.go -destination mocks/sessions.go
+
+const (
+	tokenBytes     = 32
+	sessionIDBytes = 16
+)
+
+// SessionRegistry stores the sessions and their refresh tokens
+type SessionRegistry interface {
+	GetSession(id string) (*userStore.Session, error)
+	GetSessionsFor(email string) ([]*userStore.Session, error)
+	SaveSession(session *userStore.Session) error
+	RemoveSession(id string) error
+	GetRefreshToken(id string) (*userStore.RefreshToken, error)
+	SaveRefreshToken(token *userStore.RefreshToken) error
+}
+
+type logger interface {
+	Infow(msg string, keysAndValues ...interface{})
+}
+
+type invalidRefreshToken struct{}
+
+func (i invalidRefreshToken) Error() string {
+	return "the refresh token is invalid, expired or was revoked"
+}
+
+// IsInvalidRefreshTokenError verifies if a given error refers to a refresh token that cannot be used
+func IsInvalidRefreshTokenError(err error) bool {
+	switch err.(type) {
+	case invalidRefreshToken:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewInvalidRefreshToken creates a new invalid refresh token error
+func NewInvalidRefreshToken() error {
+	return invalidRefreshToken{}
+}
+
+// Config sets how long the sessions last
+type Config struct {
+	// RefreshLifetime is how long a refresh token can be used. Every refresh extends the session by as much
+	RefreshLifetime time.Duration
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+}
+
+// Validate checks that the configuration is complete
+func (c Config) Validate() error {
+	if c.RefreshLifetime <= 0 {
+		return fmt.Errorf("refresh token lifetime must be positive")
+	}
+	return nil
+}
+
+// New creates a new Manager
+func New(log logger, sessions SessionRegistry, config Config) (*Manager, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	now := config.Now
+	if now == nil {
+		now = time.Now
+	}
+	return &Manager{
+		log:      log,
+		sessions: sessions,
+		config:   config,
+		now:      now,
+	}, nil
+}
+
+// Manager keeps the users logged in with rotating refresh tokens. Every refresh token can be used once, and gives
+// the next token of its session. Using a token again means it was stolen, so the whole session is revoked, both for
+// the thief and for the user
+type Manager struct {
+	log      logger
+	sessions SessionRegistry
+	config   Config
+	now      func() time.Time
+	// lock makes using a refresh token atomic, so that it cannot be used twice
+	lock sync.Mutex
+}
+
+// Start creates a new session for the user, returning its first refresh token
+func (m *Manager) Start(email string, ip string, userAgent string) (string, *userStore.Session, error) {
+	id, err := randomString(sessionIDBytes, hex.EncodeToString)
+	if err != nil {
+		return "", nil, err
+	}
+	now := m.now()
+	session := &userStore.Session{
+		ID:         id,
+		Email:      email,
+		IP:         ip,
+		UserAgent:  userAgent,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(m.config.RefreshLifetime),
+	}
+	if err := m.sessions.SaveSession(session); err != nil {
+		return "", nil, err
+	}
+	token, err := m.issue(session)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, session, nil
+}
+
+// Refresh uses the refresh token, returning the next one and the session it belongs to
+func (m *Manager) Refresh(token string, ip string, userAgent string) (string, *userStore.Session, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	stored, err := m.sessions.GetRefreshToken(hash(token))
+	if err != nil {
+		if store.IsNotFoundError(err) {
+			return "", nil, NewInvalidRefreshToken()
+		}
+		return "", nil, err
+	}
+	if stored.Used {
+		m.log.Infow("refresh token used again, revoking its session", "session", stored.SessionID, "ip", ip)
+		if err := m.sessions.RemoveSession(stored.SessionID); err != nil {
+			return "", nil, err
+		}
+		return "", nil, NewInvalidRefreshToken()
+	}
+	now := m.now()
+	if !stored.ExpiresAt.After(now) {
+		if err := m.sessions.RemoveSession(stored.SessionID); err != nil {
+			return "", nil, err
+		}
+		return "", nil, NewInvalidRefreshToken()
+	}
+	session, err := m.sessions.GetSession(stored.SessionID)
+	if err != nil {
+		if store.IsNotFoundError(err) {
+			return "", nil, NewInvalidRefreshToken()
+		}
+		return "", nil, err
+	}
+
+	used := *stored
+	used.Used = true
+	if err := m.sessions.SaveRefreshToken(&used); err != nil {
+		return "", nil, err
+	}
+	updated := *session
+	updated.IP = ip
+	updated.UserAgent = userAgent
+	updated.LastUsedAt = now
+	updated.ExpiresAt = now.Add(m.config.RefreshLifetime)
+	if err := m.sessions.SaveSession(&updated); err != nil {
+		return "", nil, err
+	}
+	next, err := m.issue(&updated)
+	if err != nil {
+		return "", nil, err
+	}
+	return next, &updated, nil
+}
+
+// GetSessions returns the active sessions of the user, the most recently used first
+func (m *Manager) GetSessions(email string) ([]*userStore.Session, error) {
+	all, err := m.sessions.GetSessionsFor(email)
+	if err != nil {
+		return nil, err
+	}
+	now := m.now()
+	active := []*userStore.Session{}
+	for _, session := range all {
+		if session.ExpiresAt.After(now) {
+			active = append(active, session)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].LastUsedAt.After(active[j].LastUsedAt)
+	})
+	return active, nil
+}
+
+// Revoke ends the session of the user, so that its refresh tokens can no longer be used
+func (m *Manager) Revoke(email string, sessionID string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	session, err := m.sessions.GetSession(sessionID)
+	if err != nil {
+		return err
+	}
+	// the sessions of other users are reported as missing, so that their IDs cannot be probed
+	if session.Email != email {
+		return store.NewNotFoundError(userStore.GetSessionTable().GetName(), "id", sessionID)
+	}
+	return m.sessions.RemoveSession(sessionID)
+}
+
+// RevokeAll ends every session of the user
+func (m *Manager) RevokeAll(email string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	sessions, err := m.sessions.GetSessionsFor(email)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if err := m.sessions.RemoveSession(session.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevokeToken ends the session the refresh token belongs to, whether the token was used or not
+func (m *Manager) RevokeToken(token string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	stored, err := m.sessions.GetRefreshToken(hash(token))
+	if err != nil {
+		if store.IsNotFoundError(err) {
+			return nil
+		}
+		return err
+	}
+	return m.sessions.RemoveSession(stored.SessionID)
+}
+
+// issue generates a new refresh token for the session, storing only its hash
+func (m *Manager) issue(session *userStore.Session) (string, error) {
+	token, err := randomString(tokenBytes, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return "", err
+	}
+	err = m.sessions.SaveRefreshToken(&userStore.RefreshToken{
+		ID:        hash(token),
+		SessionID: session.ID,
+		ExpiresAt: session.ExpiresAt,
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func randomString(size int, encode func([]byte) string) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encode(b), nil
+}
+
+func hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,185 @@
+package sessions_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/users/sessions"
+	mock_sessions "github.com/mimatache/go-shop/pkg/users/sessions/mocks"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+)
+
+const (
+	userEmail = "user@email.com"
+	userIP    = "10.0.0.1"
+	userAgent = "curl/7.68.0"
+	lifetime  = 24 * time.Hour
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Infow(msg string, keysAndValues ...interface{}) {}
+
+// clock is moved forward by the tests instead of waiting
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newManager(t *testing.T) (*sessions.Manager, *clock) {
+	g := NewWithT(t)
+
+	log, _, _ := logger.New("test", true)
+
+	schema := store.NewSchema()
+	schema.AddToSchema(userStore.GetSessionTable())
+	schema.AddToSchema(userStore.GetRefreshTokenTable())
+	db, err := store.New(schema)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	c := &clock{now: time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)}
+	manager, err := sessions.New(nopLogger{}, userStore.NewSessionStore(log, db), sessions.Config{RefreshLifetime: lifetime, Now: c.Now})
+	g.Expect(err).ShouldNot(HaveOccurred())
+	return manager, c
+}
+
+func TestManager_Refresh(t *testing.T) {
+	g := NewWithT(t)
+
+	manager, c := newManager(t)
+	first, session, err := manager.Start(userEmail, userIP, userAgent)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(session.ExpiresAt).To(Equal(c.Now().Add(lifetime)))
+
+	c.Advance(time.Hour)
+	second, refreshed, err := manager.Refresh(first, "10.0.0.2", userAgent)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(second).ToNot(Equal(first))
+	g.Expect(refreshed.ID).To(Equal(session.ID))
+	g.Expect(refreshed.Email).To(Equal(userEmail))
+	g.Expect(refreshed.IP).To(Equal("10.0.0.2"))
+	g.Expect(refreshed.LastUsedAt).To(Equal(c.Now()))
+	g.Expect(refreshed.ExpiresAt).To(Equal(c.Now().Add(lifetime)), "refreshing extends the session")
+
+	_, _, err = manager.Refresh("unknown", userIP, userAgent)
+	g.Expect(sessions.IsInvalidRefreshTokenError(err)).To(BeTrue())
+}
+
+func TestManager_Refresh_Reuse(t *testing.T) {
+	g := NewWithT(t)
+
+	manager, _ := newManager(t)
+	first, _, err := manager.Start(userEmail, userIP, userAgent)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	other, _, err := manager.Start(userEmail, userIP, userAgent)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	second, _, err := manager.Refresh(first, userIP, userAgent)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	_, _, err = manager.Refresh(first, userIP, userAgent)
+	g.Expect(sessions.IsInvalidRefreshTokenError(err)).To(BeTrue())
+	_, _, err = manager.Refresh(second, userIP, userAgent)
+	g.Expect(sessions.IsInvalidRefreshTokenError(err)).To(BeTrue(), "the whole family is revoked")
+
+	_, _, err = manager.Refresh(other, userIP, userAgent)
+	g.Expect(err).ShouldNot(HaveOccurred(), "other sessions are kept")
+	active, err := manager.GetSessions(userEmail)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(active).To(HaveLen(1))
+}
+
+func TestManager_Refresh_Expired(t *testing.T) {
+	g := NewWithT(t)
+
+	manager, c := newManager(t)
+	token, _, err := manager.Start(userEmail, userIP, userAgent)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	c.Advance(lifetime)
+	active, err := manager.GetSessions(userEmail)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(active).To(BeEmpty())
+	_, _, err = manager.Refresh(token, userIP, userAgent)
+	g.Expect(sessions.IsInvalidRefreshTokenError(err)).To(BeTrue())
+}
+
+func TestManager_Revoke(t *testing.T) {
+	g := NewWithT(t)
+
+	manager, c := newManager(t)
+	first, session, err := manager.Start(userEmail, userIP, userAgent)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	c.Advance(time.Minute)
+	second, _, err := manager.Start(userEmail, "10.0.0.2", userAgent)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	_, _, err = manager.Start("other@email.com", userIP, userAgent)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	active, err := manager.GetSessions(userEmail)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(active).To(HaveLen(2))
+	g.Expect(active[0].IP).To(Equal("10.0.0.2"), "the most recently used session comes first")
+
+	err = manager.Revoke("other@email.com", session.ID)
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue(), "the session belongs to another user")
+
+	g.Expect(manager.Revoke(userEmail, session.ID)).To(Succeed())
+	_, _, err = manager.Refresh(first, userIP, userAgent)
+	g.Expect(sessions.IsInvalidRefreshTokenError(err)).To(BeTrue())
+
+	g.Expect(manager.RevokeAll(userEmail)).To(Succeed())
+	_, _, err = manager.Refresh(second, userIP, userAgent)
+	g.Expect(sessions.IsInvalidRefreshTokenError(err)).To(BeTrue())
+	others, err := manager.GetSessions("other@email.com")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(others).To(HaveLen(1))
+}
+
+func TestManager_RevokeToken(t *testing.T) {
+	g := NewWithT(t)
+
+	manager, _ := newManager(t)
+	token, _, err := manager.Start(userEmail, userIP, userAgent)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	g.Expect(manager.RevokeToken(token)).To(Succeed())
+	g.Expect(manager.RevokeToken(token)).To(Succeed(), "revoking twice is allowed")
+	_, _, err = manager.Refresh(token, userIP, userAgent)
+	g.Expect(sessions.IsInvalidRefreshTokenError(err)).To(BeTrue())
+}
+
+func TestManager_StoreFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	registry := mock_sessions.NewMockSessionRegistry(ctrl)
+	manager, err := sessions.New(nopLogger{}, registry, sessions.Config{RefreshLifetime: lifetime})
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	registry.EXPECT().GetRefreshToken(gomock.Any()).Return(nil, fmt.Errorf("store unavailable"))
+
+	_, _, err = manager.Refresh("token", userIP, userAgent)
+	g.Expect(err).Should(HaveOccurred())
+	g.Expect(sessions.IsInvalidRefreshTokenError(err)).To(BeFalse())
+}
+
+func TestConfig_Validate(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(sessions.Config{RefreshLifetime: lifetime}.Validate()).To(Succeed())
+	g.Expect(sessions.Config{}.Validate()).ShouldNot(Succeed())
+}
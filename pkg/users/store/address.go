@@ -0,0 +1,318 @@
+package store
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/mimatache/go-shop/internal/store"
+)
+
+//go:generate mockgen -source ./address.go -destination mocks/address.go
+
+var (
+	addressTable = &AddressTable{name: "userAddress"}
+)
+
+// GetAddressTable returns the table of the address books of the users
+func GetAddressTable() *AddressTable {
+	return addressTable
+}
+
+// AddressTable the schema of the address table
+type AddressTable struct {
+	name string
+}
+
+// GetName returns the name of the address table
+func (a *AddressTable) GetName() string {
+	return a.name
+}
+
+// GetTableSchema returns the schema of the address table
+func (a *AddressTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: a.name,
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:    "id",
+				Unique:  true,
+				Indexer: &memdb.UintFieldIndex{Field: "ID"},
+			},
+			"user": {
+				Name:    "user",
+				Unique:  false,
+				Indexer: &memdb.StringFieldIndex{Field: "UserID"},
+			},
+		},
+	}
+}
+
+// GetPersonalRows returns the addresses of the user
+func (a *AddressTable) GetPersonalRows(db store.Reader, userID string) ([]interface{}, error) {
+	return db.ReadAll(a.name, "user", userID)
+}
+
+// Export returns the address
+func (a *AddressTable) Export(row interface{}) interface{} {
+	return row
+}
+
+// Anonymize removes the address
+func (a *AddressTable) Anonymize(row interface{}, userID string, pseudonym string) (interface{}, error) {
+	return nil, nil
+}
+
+type invalidAddress struct {
+	msg string
+}
+
+func (i invalidAddress) Error() string {
+	return i.msg
+}
+
+// IsInvalidAddressError verifies if a given error refers to an address that does not adhere to constraints
+func IsInvalidAddressError(err error) bool {
+	switch err.(type) {
+	case invalidAddress:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewInvalidAddress creates a new invalid address error
+func NewInvalidAddress(reason error) error {
+	return invalidAddress{msg: fmt.Sprintf("invalid address:\n%s", reason)}
+}
+
+// Address is an entry of the address book of a user. Every user has at most one default shipping
+// and one default billing address
+type Address struct {
+	ID     uint   `json:"id"`
+	UserID string `json:"-"`
+	// Label tells the addresses of a user apart, such as home or work
+	Label      string `json:"label,omitempty"`
+	Name       string `json:"name"`
+	Street     string `json:"street"`
+	City       string `json:"city"`
+	PostalCode string `json:"postalCode"`
+	// Region is the code of the country of the address
+	Region          string `json:"region"`
+	Phone           string `json:"phone,omitempty"`
+	DefaultShipping bool   `json:"defaultShipping"`
+	DefaultBilling  bool   `json:"defaultBilling"`
+}
+
+// Validate checks that an address adheres to constraints
+func (a Address) Validate() error {
+	var errs errors
+	if a.UserID == "" {
+		errs = append(errs, fmt.Errorf("user is mandatory"))
+	}
+	if a.Name == "" {
+		errs = append(errs, fmt.Errorf("name is mandatory"))
+	}
+	if a.Street == "" {
+		errs = append(errs, fmt.Errorf("street is mandatory"))
+	}
+	if a.City == "" {
+		errs = append(errs, fmt.Errorf("city is mandatory"))
+	}
+	if a.Region == "" {
+		errs = append(errs, fmt.Errorf("region is mandatory"))
+	}
+	if err := validatePhone(a.Phone); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// AddressStore represents the store of the address books of the users
+type AddressStore interface {
+	// GetAddresses returns the address book of the user
+	GetAddresses(userID string) ([]*Address, error)
+	// GetAddress returns an address of the user
+	GetAddress(userID string, addressID uint) (*Address, error)
+	// SaveAddress stores the address, under the next free ID if it has none. The first address of a user becomes
+	// its default shipping and billing address, and an address made default takes the place of the previous default
+	SaveAddress(address *Address) error
+	// RemoveAddress removes an address of the user
+	RemoveAddress(userID string, addressID uint) error
+}
+
+// NewAddressStore creates a new address store instance
+func NewAddressStore(log logger, db UnderlyingStore) AddressStore {
+	return &addressLogger{
+		log:  log,
+		next: &addressStore{db: db},
+	}
+}
+
+type addressStore struct {
+	db UnderlyingStore
+	// lock serializes the changes, so that no two addresses get the same ID and every user keeps a single default
+	lock sync.Mutex
+}
+
+func (a *addressStore) GetAddresses(userID string) ([]*Address, error) {
+	rows, err := a.db.ReadAll(addressTable.GetName(), "user", userID)
+	if err != nil {
+		return nil, err
+	}
+	addresses := make([]*Address, 0, len(rows))
+	for _, row := range rows {
+		addresses = append(addresses, row.(*Address))
+	}
+	return addresses, nil
+}
+
+func (a *addressStore) GetAddress(userID string, addressID uint) (*Address, error) {
+	raw, err := a.db.Read(addressTable.GetName(), "id", addressID)
+	if err != nil {
+		return nil, err
+	}
+	address := raw.(*Address)
+	// the addresses of other users are reported as missing, so that their IDs cannot be probed
+	if address.UserID != userID {
+		return nil, store.NewNotFoundError(addressTable.GetName(), "id", addressID)
+	}
+	return address, nil
+}
+
+func (a *addressStore) SaveAddress(address *Address) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	addresses, err := a.GetAddresses(address.UserID)
+	if err != nil {
+		return err
+	}
+	isNew := address.ID == 0
+	if isNew {
+		if address.ID, err = a.nextID(); err != nil {
+			return err
+		}
+	} else if !contains(addresses, address.ID) {
+		return store.NewNotFoundError(addressTable.GetName(), "id", address.ID)
+	}
+	if err := address.Validate(); err != nil {
+		if isNew {
+			address.ID = 0
+		}
+		return NewInvalidAddress(err)
+	}
+	if len(addresses) == 0 {
+		address.DefaultShipping = true
+		address.DefaultBilling = true
+	}
+	changed := []interface{}{address}
+	for _, existing := range addresses {
+		if existing.ID == address.ID {
+			continue
+		}
+		if (address.DefaultShipping && existing.DefaultShipping) || (address.DefaultBilling && existing.DefaultBilling) {
+			previous := *existing
+			previous.DefaultShipping = previous.DefaultShipping && !address.DefaultShipping
+			previous.DefaultBilling = previous.DefaultBilling && !address.DefaultBilling
+			changed = append(changed, &previous)
+		}
+	}
+	return a.db.Write(addressTable.GetName(), changed...)
+}
+
+func (a *addressStore) RemoveAddress(userID string, addressID uint) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if _, err := a.GetAddress(userID, addressID); err != nil {
+		return err
+	}
+	return a.db.Remove(addressTable.GetName(), "id", addressID)
+}
+
+func (a *addressStore) nextID() (uint, error) {
+	rows, err := a.db.ReadAll(addressTable.GetName(), "id")
+	if err != nil {
+		return 0, err
+	}
+	var lastID uint
+	for _, row := range rows {
+		if existing := row.(*Address); existing.ID > lastID {
+			lastID = existing.ID
+		}
+	}
+	return lastID + 1, nil
+}
+
+func contains(addresses []*Address, addressID uint) bool {
+	for _, address := range addresses {
+		if address.ID == addressID {
+			return true
+		}
+	}
+	return false
+}
+
+type addressLogger struct {
+	log  logger
+	next AddressStore
+}
+
+func (a *addressLogger) GetAddresses(userID string) ([]*Address, error) {
+	var err error
+	defer func() {
+		if err != nil {
+			a.log.Debugf("could not retrieve addresses of user %s", userID)
+			a.log.Debugf("%v", err)
+			return
+		}
+		a.log.Debugf("Retrieved addresses of user %s", userID)
+	}()
+	addresses, err := a.next.GetAddresses(userID)
+	return addresses, err
+}
+
+func (a *addressLogger) GetAddress(userID string, addressID uint) (*Address, error) {
+	var err error
+	defer func() {
+		if err != nil {
+			a.log.Debugf("could not retrieve address %d of user %s", addressID, userID)
+			a.log.Debugf("%v", err)
+			return
+		}
+		a.log.Debugf("Retrieved address %d of user %s", addressID, userID)
+	}()
+	address, err := a.next.GetAddress(userID, addressID)
+	return address, err
+}
+
+func (a *addressLogger) SaveAddress(address *Address) error {
+	var err error
+	defer func() {
+		if err != nil {
+			a.log.Debugf("could not save address of user %s", address.UserID)
+			a.log.Debugf("%v", err)
+			return
+		}
+		a.log.Debugf("Saved address %d of user %s", address.ID, address.UserID)
+	}()
+	err = a.next.SaveAddress(address)
+	return err
+}
+
+func (a *addressLogger) RemoveAddress(userID string, addressID uint) error {
+	var err error
+	defer func() {
+		if err != nil {
+			a.log.Debugf("could not remove address %d of user %s", addressID, userID)
+			a.log.Debugf("%v", err)
+			return
+		}
+		a.log.Debugf("Removed address %d of user %s", addressID, userID)
+	}()
+	err = a.next.RemoveAddress(userID, addressID)
+	return err
+}
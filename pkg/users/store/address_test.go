@@ -0,0 +1,99 @@
+package store_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/internal/store"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+)
+
+func newAddressStore(t *testing.T) userStore.AddressStore {
+	g := NewWithT(t)
+
+	log, _, _ := logger.New("test", true)
+
+	schema := store.NewSchema()
+	schema.AddToSchema(userStore.GetTable())
+	schema.AddToSchema(userStore.GetAddressTable())
+	db, err := store.New(schema)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	return userStore.NewAddressStore(log, db)
+}
+
+func newAddress(userID string) *userStore.Address {
+	return &userStore.Address{UserID: userID, Name: "John Doe", Street: "Main Street", City: "Bucharest", Region: "RO"}
+}
+
+func TestAddressStore_SaveAddress_Defaults(t *testing.T) {
+	g := NewWithT(t)
+
+	addresses := newAddressStore(t)
+
+	home := newAddress(userEmail)
+	g.Expect(addresses.SaveAddress(home)).To(Succeed())
+	g.Expect(home.ID).To(Equal(uint(1)))
+	g.Expect(home.DefaultShipping).To(BeTrue(), "the first address is the default")
+	g.Expect(home.DefaultBilling).To(BeTrue(), "the first address is the default")
+
+	work := newAddress(userEmail)
+	work.DefaultShipping = true
+	g.Expect(addresses.SaveAddress(work)).To(Succeed())
+	g.Expect(work.ID).To(Equal(uint(2)))
+
+	stored, err := addresses.GetAddress(userEmail, home.ID)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(stored.DefaultShipping).To(BeFalse(), "the new default shipping address replaces the previous one")
+	g.Expect(stored.DefaultBilling).To(BeTrue())
+
+	other := newAddress("other@email.com")
+	g.Expect(addresses.SaveAddress(other)).To(Succeed())
+	g.Expect(other.ID).To(Equal(uint(3)))
+	g.Expect(other.DefaultShipping).To(BeTrue(), "the defaults are kept per user")
+
+	all, err := addresses.GetAddresses(userEmail)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(all).To(HaveLen(2))
+}
+
+func TestAddressStore_OtherUser(t *testing.T) {
+	g := NewWithT(t)
+
+	addresses := newAddressStore(t)
+	address := newAddress(userEmail)
+	g.Expect(addresses.SaveAddress(address)).To(Succeed())
+
+	_, err := addresses.GetAddress("other@email.com", address.ID)
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue())
+
+	stolen := newAddress("other@email.com")
+	stolen.ID = address.ID
+	err = addresses.SaveAddress(stolen)
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue(), "the addresses of other users cannot be replaced")
+
+	err = addresses.RemoveAddress("other@email.com", address.ID)
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue())
+
+	g.Expect(addresses.RemoveAddress(userEmail, address.ID)).To(Succeed())
+	_, err = addresses.GetAddress(userEmail, address.ID)
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue())
+}
+
+func TestAddressStore_SaveAddress_Invalid(t *testing.T) {
+	g := NewWithT(t)
+
+	addresses := newAddressStore(t)
+	address := newAddress(userEmail)
+	address.Street = ""
+	address.Phone = "call me"
+
+	err := addresses.SaveAddress(address)
+
+	g.Expect(userStore.IsInvalidAddressError(err)).To(BeTrue())
+	g.Expect(err.Error()).To(ContainSubstring("street is mandatory"))
+	g.Expect(err.Error()).To(ContainSubstring("invalid phone number"))
+	g.Expect(address.ID).To(BeZero())
+}
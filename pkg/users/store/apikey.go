@@ -0,0 +1,220 @@
+package store
+
+import (
+	"time"
+
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/mimatache/go-shop/internal/rbac"
+	"github.com/mimatache/go-shop/internal/store"
+)
+
+//go:generate mockgen -source ./apikey.go -destination mocks/apikey.go
+
+var (
+	apiKeyTable = &APIKeyTable{name: "userAPIKey"}
+)
+
+// GetAPIKeyTable returns the table of the API keys of the users
+func GetAPIKeyTable() *APIKeyTable {
+	return apiKeyTable
+}
+
+// APIKeyTable the schema of the API key table
+type APIKeyTable struct {
+	name string
+}
+
+// GetName returns the name of the API key table
+func (a *APIKeyTable) GetName() string {
+	return a.name
+}
+
+// GetTableSchema returns the schema of the API key table
+func (a *APIKeyTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: a.name,
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:    "id",
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "ID"},
+			},
+			"hash": {
+				Name:    "hash",
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "Hash"},
+			},
+			"user": {
+				Name:    "user",
+				Unique:  false,
+				Indexer: &memdb.StringFieldIndex{Field: "Email"},
+			},
+		},
+	}
+}
+
+// GetPersonalRows returns the API keys of the user
+func (a *APIKeyTable) GetPersonalRows(db store.Reader, userID string) ([]interface{}, error) {
+	return db.ReadAll(a.name, "user", userID)
+}
+
+// Export returns the API key, which only holds the hash of the key
+func (a *APIKeyTable) Export(row interface{}) interface{} {
+	return row
+}
+
+// Anonymize removes the API key
+func (a *APIKeyTable) Anonymize(row interface{}, userID string, pseudonym string) (interface{}, error) {
+	return nil, nil
+}
+
+// APIKey lets a machine client act on behalf of a user, within its scopes. Only the hash of the key is stored,
+// while its prefix is kept so that users can tell their keys apart
+type APIKey struct {
+	ID         string            `json:"id"`
+	Email      string            `json:"-"`
+	Name       string            `json:"name"`
+	Prefix     string            `json:"prefix"`
+	Hash       string            `json:"-"`
+	Scopes     []rbac.Permission `json:"scopes"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	LastUsedAt *time.Time        `json:"lastUsedAt,omitempty"`
+}
+
+// APIKeyStore represents the store of the API keys
+type APIKeyStore interface {
+	// GetAPIKey returns the API key with the given ID
+	GetAPIKey(id string) (*APIKey, error)
+	// GetAPIKeyByHash returns the API key with the given hash
+	GetAPIKeyByHash(hash string) (*APIKey, error)
+	// GetAPIKeysFor returns every API key of the user
+	GetAPIKeysFor(email string) ([]*APIKey, error)
+	// SaveAPIKey stores the API key, replacing any previous version
+	SaveAPIKey(key *APIKey) error
+	// RemoveAPIKey removes the API key with the given ID
+	RemoveAPIKey(id string) error
+}
+
+// NewAPIKeyStore creates a new API key store instance
+func NewAPIKeyStore(log logger, db UnderlyingStore) APIKeyStore {
+	return &apiKeyLogger{
+		log:  log,
+		next: &apiKeyStore{db: db},
+	}
+}
+
+type apiKeyStore struct {
+	db UnderlyingStore
+}
+
+func (a *apiKeyStore) GetAPIKey(id string) (*APIKey, error) {
+	raw, err := a.db.Read(apiKeyTable.GetName(), "id", id)
+	if err != nil {
+		return nil, err
+	}
+	return raw.(*APIKey), nil
+}
+
+func (a *apiKeyStore) GetAPIKeyByHash(hash string) (*APIKey, error) {
+	raw, err := a.db.Read(apiKeyTable.GetName(), "hash", hash)
+	if err != nil {
+		return nil, err
+	}
+	return raw.(*APIKey), nil
+}
+
+func (a *apiKeyStore) GetAPIKeysFor(email string) ([]*APIKey, error) {
+	rows, err := a.db.ReadAll(apiKeyTable.GetName(), "user", email)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*APIKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, row.(*APIKey))
+	}
+	return keys, nil
+}
+
+func (a *apiKeyStore) SaveAPIKey(key *APIKey) error {
+	return a.db.Write(apiKeyTable.GetName(), key)
+}
+
+func (a *apiKeyStore) RemoveAPIKey(id string) error {
+	return a.db.Remove(apiKeyTable.GetName(), "id", id)
+}
+
+type apiKeyLogger struct {
+	log  logger
+	next APIKeyStore
+}
+
+func (a *apiKeyLogger) GetAPIKey(id string) (*APIKey, error) {
+	var err error
+	defer func() {
+		if err != nil {
+			a.log.Debugf("could not retrieve API key %s", id)
+			a.log.Debugf("%v", err)
+			return
+		}
+		a.log.Debugf("Retrieved API key %s", id)
+	}()
+	key, err := a.next.GetAPIKey(id)
+	return key, err
+}
+
+func (a *apiKeyLogger) GetAPIKeyByHash(hash string) (*APIKey, error) {
+	var err error
+	defer func() {
+		if err != nil {
+			a.log.Debugf("could not retrieve API key by hash")
+			a.log.Debugf("%v", err)
+			return
+		}
+		a.log.Debugf("Retrieved API key by hash")
+	}()
+	key, err := a.next.GetAPIKeyByHash(hash)
+	return key, err
+}
+
+func (a *apiKeyLogger) GetAPIKeysFor(email string) ([]*APIKey, error) {
+	var err error
+	defer func() {
+		if err != nil {
+			a.log.Debugf("could not retrieve API keys of %s", email)
+			a.log.Debugf("%v", err)
+			return
+		}
+		a.log.Debugf("Retrieved API keys of %s", email)
+	}()
+	keys, err := a.next.GetAPIKeysFor(email)
+	return keys, err
+}
+
+func (a *apiKeyLogger) SaveAPIKey(key *APIKey) error {
+	var err error
+	defer func() {
+		if err != nil {
+			a.log.Debugf("could not save API key %s", key.ID)
+			a.log.Debugf("%v", err)
+			return
+		}
+		a.log.Debugf("Saved API key %s", key.ID)
+	}()
+	err = a.next.SaveAPIKey(key)
+	return err
+}
+
+func (a *apiKeyLogger) RemoveAPIKey(id string) error {
+	var err error
+	defer func() {
+		if err != nil {
+			a.log.Debugf("could not remove API key %s", id)
+			a.log.Debugf("%v", err)
+			return
+		}
+		a.log.Debugf("Removed API key %s", id)
+	}()
+	err = a.next.RemoveAPIKey(id)
+	return err
+}
@@ -0,0 +1,43 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/internal/rbac"
+	"github.com/mimatache/go-shop/internal/store"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+)
+
+func TestAPIKeyStore(t *testing.T) {
+	g := NewWithT(t)
+
+	log, _, _ := logger.New("test", true)
+
+	schema := store.NewSchema()
+	schema.AddToSchema(userStore.GetAPIKeyTable())
+	db, err := store.New(schema)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	keys := userStore.NewAPIKeyStore(log, db)
+
+	key := &userStore.APIKey{ID: "key", Email: userEmail, Name: "script", Hash: "hash", Scopes: []rbac.Permission{rbac.UseAccount}, CreatedAt: time.Now()}
+	other := &userStore.APIKey{ID: "other", Email: "other@email.com", Name: "script", Hash: "other-hash", CreatedAt: time.Now()}
+	g.Expect(keys.SaveAPIKey(key)).To(Succeed())
+	g.Expect(keys.SaveAPIKey(other)).To(Succeed())
+
+	stored, err := keys.GetAPIKeyByHash("hash")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(stored).To(Equal(key))
+	owned, err := keys.GetAPIKeysFor(userEmail)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(owned).To(ConsistOf(key))
+
+	g.Expect(keys.RemoveAPIKey("key")).To(Succeed())
+	_, err = keys.GetAPIKey("key")
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue())
+	_, err = keys.GetAPIKeyByHash("hash")
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue())
+}
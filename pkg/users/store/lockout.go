@@ -0,0 +1,323 @@
+package store
+
+import (
+	"time"
+
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/mimatache/go-shop/internal/store"
+)
+
+//go:generate mockgen -source ./lockout.go -destination mocks/lockout.go
+
+var (
+	attemptsTable = &AttemptsTable{name: "loginAttempts"}
+	auditTable    = &AuditTable{name: "loginAudit"}
+)
+
+// GetAttemptsTable returns the table of the failed login attempts
+func GetAttemptsTable() *AttemptsTable {
+	return attemptsTable
+}
+
+// AttemptsTable the schema of the failed login attempts table
+type AttemptsTable struct {
+	name string
+}
+
+// GetName returns the name of the failed login attempts table
+func (a *AttemptsTable) GetName() string {
+	return a.name
+}
+
+// GetTableSchema returns the schema of the failed login attempts table
+func (a *AttemptsTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: a.name,
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:    "id",
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "ID"},
+			},
+		},
+	}
+}
+
+// GetPersonalRows returns the failed logins of the account of the user
+func (a *AttemptsTable) GetPersonalRows(db store.Reader, userID string) ([]interface{}, error) {
+	return db.ReadAll(a.name, "id", accountSubject(userID))
+}
+
+// Export returns the failed logins
+func (a *AttemptsTable) Export(row interface{}) interface{} {
+	return row
+}
+
+// Anonymize removes the failed logins
+func (a *AttemptsTable) Anonymize(row interface{}, userID string, pseudonym string) (interface{}, error) {
+	return nil, nil
+}
+
+// GetAuditTable returns the table of the login audit entries
+func GetAuditTable() *AuditTable {
+	return auditTable
+}
+
+// AuditTable the schema of the login audit table
+type AuditTable struct {
+	name string
+}
+
+// GetName returns the name of the login audit table
+func (a *AuditTable) GetName() string {
+	return a.name
+}
+
+// GetTableSchema returns the schema of the login audit table
+func (a *AuditTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: a.name,
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:    "id",
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "ID"},
+			},
+		},
+	}
+}
+
+// GetPersonalRows returns the audit entries about the account of the user, or made by the user
+func (a *AuditTable) GetPersonalRows(db store.Reader, userID string) ([]interface{}, error) {
+	rows, err := db.ReadAll(a.name, "id_prefix", "")
+	if err != nil {
+		return nil, err
+	}
+	entries := []interface{}{}
+	for _, row := range rows {
+		entry := row.(*AuditEntry)
+		if entry.Subject == accountSubject(userID) || entry.Actor == userID {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// Export returns the audit entry
+func (a *AuditTable) Export(row interface{}) interface{} {
+	return row
+}
+
+// Anonymize keeps the audit entry, replacing the user with the pseudonym, so that the audit log stays complete
+func (a *AuditTable) Anonymize(row interface{}, userID string, pseudonym string) (interface{}, error) {
+	anonymized := *row.(*AuditEntry)
+	if anonymized.Subject == accountSubject(userID) {
+		anonymized.Subject = accountSubject(pseudonym)
+	}
+	if anonymized.Actor == userID {
+		anonymized.Actor = pseudonym
+	}
+	return &anonymized, nil
+}
+
+// accountSubject returns the ID the failed logins and the audit entries of an account are recorded under
+func accountSubject(email string) string {
+	return "account:" + email
+}
+
+// Attempts tracks the failed logins of an account or of an IP address
+type Attempts struct {
+	// ID is the account or the IP address the logins failed for, such as account:jane@company.com or ip:10.0.0.1
+	ID          string    `json:"id"`
+	Failures    uint      `json:"failures"`
+	LastFailure time.Time `json:"lastFailure"`
+	// RetryAt is when the next login can be attempted
+	RetryAt time.Time `json:"retryAt"`
+	// LockedUntil is set when too many logins failed, until when no login can be attempted
+	LockedUntil time.Time `json:"lockedUntil"`
+}
+
+// AuditEvent is what an audit entry records
+type AuditEvent string
+
+const (
+	// Locked is recorded when an account or an IP address is locked out
+	Locked AuditEvent = "locked"
+	// Unlocked is recorded when a lockout ends, either because it expired or because an administrator lifted it
+	Unlocked AuditEvent = "unlocked"
+)
+
+// AuditEntry records a change of the lockouts
+type AuditEntry struct {
+	ID      string     `json:"id"`
+	Event   AuditEvent `json:"event"`
+	Subject string     `json:"subject"`
+	// Actor is the administrator who made the change, or system when it was automatic
+	Actor    string `json:"actor"`
+	Failures uint   `json:"failures,omitempty"`
+	// Until is when a lockout ends, only set when it starts
+	Until *time.Time `json:"until,omitempty"`
+	Time  time.Time  `json:"time"`
+}
+
+// LockoutStore represents the store of the failed login attempts and of their audit entries
+type LockoutStore interface {
+	// GetAttempts returns the failed logins of an account or IP address
+	GetAttempts(id string) (*Attempts, error)
+	// GetAllAttempts returns the failed logins of every account and IP address
+	GetAllAttempts() ([]*Attempts, error)
+	// SaveAttempts stores the failed logins
+	SaveAttempts(attempts *Attempts) error
+	// RemoveAttempts forgets the failed logins of an account or IP address
+	RemoveAttempts(id string) error
+	// AddAuditEntry records a change of the lockouts
+	AddAuditEntry(entry *AuditEntry) error
+	// GetAuditEntries returns every audit entry
+	GetAuditEntries() ([]*AuditEntry, error)
+}
+
+// NewLockoutStore creates a new lockout store instance
+func NewLockoutStore(log logger, db UnderlyingStore) LockoutStore {
+	return &lockoutLogger{
+		log:  log,
+		next: &lockoutStore{db: db},
+	}
+}
+
+type lockoutStore struct {
+	db UnderlyingStore
+}
+
+func (l *lockoutStore) GetAttempts(id string) (*Attempts, error) {
+	raw, err := l.db.Read(attemptsTable.GetName(), "id", id)
+	if err != nil {
+		return nil, err
+	}
+	return raw.(*Attempts), nil
+}
+
+func (l *lockoutStore) GetAllAttempts() ([]*Attempts, error) {
+	rows, err := l.db.ReadAll(attemptsTable.GetName(), "id_prefix", "")
+	if err != nil {
+		return nil, err
+	}
+	attempts := make([]*Attempts, 0, len(rows))
+	for _, row := range rows {
+		attempts = append(attempts, row.(*Attempts))
+	}
+	return attempts, nil
+}
+
+func (l *lockoutStore) SaveAttempts(attempts *Attempts) error {
+	return l.db.Write(attemptsTable.GetName(), attempts)
+}
+
+func (l *lockoutStore) RemoveAttempts(id string) error {
+	return l.db.Remove(attemptsTable.GetName(), "id", id)
+}
+
+func (l *lockoutStore) AddAuditEntry(entry *AuditEntry) error {
+	return l.db.Write(auditTable.GetName(), entry)
+}
+
+func (l *lockoutStore) GetAuditEntries() ([]*AuditEntry, error) {
+	rows, err := l.db.ReadAll(auditTable.GetName(), "id_prefix", "")
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*AuditEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, row.(*AuditEntry))
+	}
+	return entries, nil
+}
+
+type lockoutLogger struct {
+	log  logger
+	next LockoutStore
+}
+
+func (l *lockoutLogger) GetAttempts(id string) (*Attempts, error) {
+	var err error
+	defer func() {
+		if err != nil {
+			l.log.Debugf("could not retrieve failed logins of %s", id)
+			l.log.Debugf("%v", err)
+			return
+		}
+		l.log.Debugf("Retrieved failed logins of %s", id)
+	}()
+	attempts, err := l.next.GetAttempts(id)
+	return attempts, err
+}
+
+func (l *lockoutLogger) GetAllAttempts() ([]*Attempts, error) {
+	var err error
+	defer func() {
+		if err != nil {
+			l.log.Debugf("could not retrieve failed logins")
+			l.log.Debugf("%v", err)
+			return
+		}
+		l.log.Debugf("Retrieved failed logins")
+	}()
+	attempts, err := l.next.GetAllAttempts()
+	return attempts, err
+}
+
+func (l *lockoutLogger) SaveAttempts(attempts *Attempts) error {
+	var err error
+	defer func() {
+		if err != nil {
+			l.log.Debugf("could not save failed logins of %s", attempts.ID)
+			l.log.Debugf("%v", err)
+			return
+		}
+		l.log.Debugf("Saved %d failed logins of %s", attempts.Failures, attempts.ID)
+	}()
+	err = l.next.SaveAttempts(attempts)
+	return err
+}
+
+func (l *lockoutLogger) RemoveAttempts(id string) error {
+	var err error
+	defer func() {
+		if err != nil {
+			l.log.Debugf("could not remove failed logins of %s", id)
+			l.log.Debugf("%v", err)
+			return
+		}
+		l.log.Debugf("Removed failed logins of %s", id)
+	}()
+	err = l.next.RemoveAttempts(id)
+	return err
+}
+
+func (l *lockoutLogger) AddAuditEntry(entry *AuditEntry) error {
+	var err error
+	defer func() {
+		if err != nil {
+			l.log.Debugf("could not add %s audit entry for %s", entry.Event, entry.Subject)
+			l.log.Debugf("%v", err)
+			return
+		}
+		l.log.Debugf("Added %s audit entry for %s", entry.Event, entry.Subject)
+	}()
+	err = l.next.AddAuditEntry(entry)
+	return err
+}
+
+func (l *lockoutLogger) GetAuditEntries() ([]*AuditEntry, error) {
+	var err error
+	defer func() {
+		if err != nil {
+			l.log.Debugf("could not retrieve login audit entries")
+			l.log.Debugf("%v", err)
+			return
+		}
+		l.log.Debugf("Retrieved login audit entries")
+	}()
+	entries, err := l.next.GetAuditEntries()
+	return entries, err
+}
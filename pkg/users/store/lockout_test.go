@@ -0,0 +1,62 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/internal/store"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+)
+
+func newLockoutStore(t *testing.T) userStore.LockoutStore {
+	g := NewWithT(t)
+
+	log, _, _ := logger.New("test", true)
+
+	schema := store.NewSchema()
+	schema.AddToSchema(userStore.GetAttemptsTable())
+	schema.AddToSchema(userStore.GetAuditTable())
+	db, err := store.New(schema)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	return userStore.NewLockoutStore(log, db)
+}
+
+func TestLockoutStore_Attempts(t *testing.T) {
+	g := NewWithT(t)
+
+	lockouts := newLockoutStore(t)
+	account := &userStore.Attempts{ID: "account:" + userEmail, Failures: 1, LastFailure: time.Now()}
+	ip := &userStore.Attempts{ID: "ip:10.0.0.1", Failures: 2, LastFailure: time.Now()}
+	g.Expect(lockouts.SaveAttempts(account)).To(Succeed())
+	g.Expect(lockouts.SaveAttempts(ip)).To(Succeed())
+
+	stored, err := lockouts.GetAttempts(account.ID)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(stored).To(Equal(account))
+
+	all, err := lockouts.GetAllAttempts()
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(all).To(ConsistOf(account, ip))
+
+	g.Expect(lockouts.RemoveAttempts(account.ID)).To(Succeed())
+	_, err = lockouts.GetAttempts(account.ID)
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue())
+}
+
+func TestLockoutStore_AuditEntries(t *testing.T) {
+	g := NewWithT(t)
+
+	lockouts := newLockoutStore(t)
+	locked := &userStore.AuditEntry{ID: "1", Event: userStore.Locked, Subject: "ip:10.0.0.1", Actor: "system", Time: time.Now()}
+	unlocked := &userStore.AuditEntry{ID: "2", Event: userStore.Unlocked, Subject: "ip:10.0.0.1", Actor: "admin@email.com", Time: time.Now()}
+	g.Expect(lockouts.AddAuditEntry(locked)).To(Succeed())
+	g.Expect(lockouts.AddAuditEntry(unlocked)).To(Succeed())
+
+	entries, err := lockouts.GetAuditEntries()
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(entries).To(ConsistOf(locked, unlocked))
+}
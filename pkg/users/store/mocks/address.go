@@ -0,0 +1,92 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./address.go
+
+// Package mock_store is a generated GoMock package.
+package mock_store
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	store "github.com/mimatache/go-shop/pkg/users/store"
+	reflect "reflect"
+)
+
+// MockAddressStore is a mock of AddressStore interface
+type MockAddressStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockAddressStoreMockRecorder
+}
+
+// MockAddressStoreMockRecorder is the mock recorder for MockAddressStore
+type MockAddressStoreMockRecorder struct {
+	mock *MockAddressStore
+}
+
+// NewMockAddressStore creates a new mock instance
+func NewMockAddressStore(ctrl *gomock.Controller) *MockAddressStore {
+	mock := &MockAddressStore{ctrl: ctrl}
+	mock.recorder = &MockAddressStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAddressStore) EXPECT() *MockAddressStoreMockRecorder {
+	return m.recorder
+}
+
+// GetAddresses mocks base method
+func (m *MockAddressStore) GetAddresses(userID string) ([]*store.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAddresses", userID)
+	ret0, _ := ret[0].([]*store.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAddresses indicates an expected call of GetAddresses
+func (mr *MockAddressStoreMockRecorder) GetAddresses(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAddresses", reflect.TypeOf((*MockAddressStore)(nil).GetAddresses), userID)
+}
+
+// GetAddress mocks base method
+func (m *MockAddressStore) GetAddress(userID string, addressID uint) (*store.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAddress", userID, addressID)
+	ret0, _ := ret[0].(*store.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAddress indicates an expected call of GetAddress
+func (mr *MockAddressStoreMockRecorder) GetAddress(userID, addressID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAddress", reflect.TypeOf((*MockAddressStore)(nil).GetAddress), userID, addressID)
+}
+
+// SaveAddress mocks base method
+func (m *MockAddressStore) SaveAddress(address *store.Address) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAddress", address)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAddress indicates an expected call of SaveAddress
+func (mr *MockAddressStoreMockRecorder) SaveAddress(address interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAddress", reflect.TypeOf((*MockAddressStore)(nil).SaveAddress), address)
+}
+
+// RemoveAddress mocks base method
+func (m *MockAddressStore) RemoveAddress(userID string, addressID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveAddress", userID, addressID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveAddress indicates an expected call of RemoveAddress
+func (mr *MockAddressStoreMockRecorder) RemoveAddress(userID, addressID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAddress", reflect.TypeOf((*MockAddressStore)(nil).RemoveAddress), userID, addressID)
+}
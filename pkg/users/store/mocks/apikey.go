@@ -0,0 +1,107 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./apikey.go
+
+// Package mock_store is a generated GoMock package.
+package mock_store
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	store "github.com/mimatache/go-shop/pkg/users/store"
+	reflect "reflect"
+)
+
+// MockAPIKeyStore is a mock of APIKeyStore interface
+type MockAPIKeyStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyStoreMockRecorder
+}
+
+// MockAPIKeyStoreMockRecorder is the mock recorder for MockAPIKeyStore
+type MockAPIKeyStoreMockRecorder struct {
+	mock *MockAPIKeyStore
+}
+
+// NewMockAPIKeyStore creates a new mock instance
+func NewMockAPIKeyStore(ctrl *gomock.Controller) *MockAPIKeyStore {
+	mock := &MockAPIKeyStore{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAPIKeyStore) EXPECT() *MockAPIKeyStoreMockRecorder {
+	return m.recorder
+}
+
+// GetAPIKey mocks base method
+func (m *MockAPIKeyStore) GetAPIKey(id string) (*store.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKey", id)
+	ret0, _ := ret[0].(*store.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKey indicates an expected call of GetAPIKey
+func (mr *MockAPIKeyStoreMockRecorder) GetAPIKey(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKey", reflect.TypeOf((*MockAPIKeyStore)(nil).GetAPIKey), id)
+}
+
+// GetAPIKeyByHash mocks base method
+func (m *MockAPIKeyStore) GetAPIKeyByHash(hash string) (*store.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByHash", hash)
+	ret0, _ := ret[0].(*store.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByHash indicates an expected call of GetAPIKeyByHash
+func (mr *MockAPIKeyStoreMockRecorder) GetAPIKeyByHash(hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByHash", reflect.TypeOf((*MockAPIKeyStore)(nil).GetAPIKeyByHash), hash)
+}
+
+// GetAPIKeysFor mocks base method
+func (m *MockAPIKeyStore) GetAPIKeysFor(email string) ([]*store.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeysFor", email)
+	ret0, _ := ret[0].([]*store.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeysFor indicates an expected call of GetAPIKeysFor
+func (mr *MockAPIKeyStoreMockRecorder) GetAPIKeysFor(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeysFor", reflect.TypeOf((*MockAPIKeyStore)(nil).GetAPIKeysFor), email)
+}
+
+// SaveAPIKey mocks base method
+func (m *MockAPIKeyStore) SaveAPIKey(key *store.APIKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAPIKey", key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAPIKey indicates an expected call of SaveAPIKey
+func (mr *MockAPIKeyStoreMockRecorder) SaveAPIKey(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAPIKey", reflect.TypeOf((*MockAPIKeyStore)(nil).SaveAPIKey), key)
+}
+
+// RemoveAPIKey mocks base method
+func (m *MockAPIKeyStore) RemoveAPIKey(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveAPIKey", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveAPIKey indicates an expected call of RemoveAPIKey
+func (mr *MockAPIKeyStoreMockRecorder) RemoveAPIKey(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAPIKey", reflect.TypeOf((*MockAPIKeyStore)(nil).RemoveAPIKey), id)
+}
@@ -0,0 +1,121 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./lockout.go
+
+// Package mock_store is a generated GoMock package.
+package mock_store
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	store "github.com/mimatache/go-shop/pkg/users/store"
+	reflect "reflect"
+)
+
+// MockLockoutStore is a mock of LockoutStore interface
+type MockLockoutStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockLockoutStoreMockRecorder
+}
+
+// MockLockoutStoreMockRecorder is the mock recorder for MockLockoutStore
+type MockLockoutStoreMockRecorder struct {
+	mock *MockLockoutStore
+}
+
+// NewMockLockoutStore creates a new mock instance
+func NewMockLockoutStore(ctrl *gomock.Controller) *MockLockoutStore {
+	mock := &MockLockoutStore{ctrl: ctrl}
+	mock.recorder = &MockLockoutStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockLockoutStore) EXPECT() *MockLockoutStoreMockRecorder {
+	return m.recorder
+}
+
+// GetAttempts mocks base method
+func (m *MockLockoutStore) GetAttempts(id string) (*store.Attempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttempts", id)
+	ret0, _ := ret[0].(*store.Attempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAttempts indicates an expected call of GetAttempts
+func (mr *MockLockoutStoreMockRecorder) GetAttempts(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttempts", reflect.TypeOf((*MockLockoutStore)(nil).GetAttempts), id)
+}
+
+// GetAllAttempts mocks base method
+func (m *MockLockoutStore) GetAllAttempts() ([]*store.Attempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllAttempts")
+	ret0, _ := ret[0].([]*store.Attempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllAttempts indicates an expected call of GetAllAttempts
+func (mr *MockLockoutStoreMockRecorder) GetAllAttempts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllAttempts", reflect.TypeOf((*MockLockoutStore)(nil).GetAllAttempts))
+}
+
+// SaveAttempts mocks base method
+func (m *MockLockoutStore) SaveAttempts(attempts *store.Attempts) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAttempts", attempts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAttempts indicates an expected call of SaveAttempts
+func (mr *MockLockoutStoreMockRecorder) SaveAttempts(attempts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAttempts", reflect.TypeOf((*MockLockoutStore)(nil).SaveAttempts), attempts)
+}
+
+// RemoveAttempts mocks base method
+func (m *MockLockoutStore) RemoveAttempts(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveAttempts", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveAttempts indicates an expected call of RemoveAttempts
+func (mr *MockLockoutStoreMockRecorder) RemoveAttempts(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAttempts", reflect.TypeOf((*MockLockoutStore)(nil).RemoveAttempts), id)
+}
+
+// AddAuditEntry mocks base method
+func (m *MockLockoutStore) AddAuditEntry(entry *store.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAuditEntry", entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAuditEntry indicates an expected call of AddAuditEntry
+func (mr *MockLockoutStoreMockRecorder) AddAuditEntry(entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAuditEntry", reflect.TypeOf((*MockLockoutStore)(nil).AddAuditEntry), entry)
+}
+
+// GetAuditEntries mocks base method
+func (m *MockLockoutStore) GetAuditEntries() ([]*store.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEntries")
+	ret0, _ := ret[0].([]*store.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEntries indicates an expected call of GetAuditEntries
+func (mr *MockLockoutStoreMockRecorder) GetAuditEntries() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEntries", reflect.TypeOf((*MockLockoutStore)(nil).GetAuditEntries))
+}
@@ -6,6 +6,8 @@ package mock_store
 
 import (
 	gomock "github.com/golang/mock/gomock"
+	rbac "github.com/mimatache/go-shop/internal/rbac"
+	store "github.com/mimatache/go-shop/pkg/users/store"
 	reflect "reflect"
 )
 
@@ -104,6 +106,26 @@ func (mr *MockUnderlyingStoreMockRecorder) Read(table, key, value interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockUnderlyingStore)(nil).Read), table, key, value)
 }
 
+// ReadAll mocks base method
+func (m *MockUnderlyingStore) ReadAll(table, key string, args ...interface{}) ([]interface{}, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{table, key}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReadAll", varargs...)
+	ret0, _ := ret[0].([]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAll indicates an expected call of ReadAll
+func (mr *MockUnderlyingStoreMockRecorder) ReadAll(table, key interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{table, key}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAll", reflect.TypeOf((*MockUnderlyingStore)(nil).ReadAll), varargs...)
+}
+
 // Write mocks base method
 func (m *MockUnderlyingStore) Write(table string, value ...interface{}) error {
 	m.ctrl.T.Helper()
@@ -123,6 +145,72 @@ func (mr *MockUnderlyingStoreMockRecorder) Write(table interface{}, value ...int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockUnderlyingStore)(nil).Write), varargs...)
 }
 
+// Remove mocks base method
+func (m *MockUnderlyingStore) Remove(table, key string, value interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", table, key, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove
+func (mr *MockUnderlyingStoreMockRecorder) Remove(table, key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockUnderlyingStore)(nil).Remove), table, key, value)
+}
+
+// MockPasswordHasher is a mock of PasswordHasher interface
+type MockPasswordHasher struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordHasherMockRecorder
+}
+
+// MockPasswordHasherMockRecorder is the mock recorder for MockPasswordHasher
+type MockPasswordHasherMockRecorder struct {
+	mock *MockPasswordHasher
+}
+
+// NewMockPasswordHasher creates a new mock instance
+func NewMockPasswordHasher(ctrl *gomock.Controller) *MockPasswordHasher {
+	mock := &MockPasswordHasher{ctrl: ctrl}
+	mock.recorder = &MockPasswordHasherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPasswordHasher) EXPECT() *MockPasswordHasherMockRecorder {
+	return m.recorder
+}
+
+// Hash mocks base method
+func (m *MockPasswordHasher) Hash(password string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hash", password)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Hash indicates an expected call of Hash
+func (mr *MockPasswordHasherMockRecorder) Hash(password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hash", reflect.TypeOf((*MockPasswordHasher)(nil).Hash), password)
+}
+
+// IsHash mocks base method
+func (m *MockPasswordHasher) IsHash(value string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsHash", value)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsHash indicates an expected call of IsHash
+func (mr *MockPasswordHasherMockRecorder) IsHash(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsHash", reflect.TypeOf((*MockPasswordHasher)(nil).IsHash), value)
+}
+
 // MockUserStore is a mock of UserStore interface
 type MockUserStore struct {
 	ctrl     *gomock.Controller
@@ -160,3 +248,105 @@ func (mr *MockUserStoreMockRecorder) GetPasswordFor(name interface{}) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordFor", reflect.TypeOf((*MockUserStore)(nil).GetPasswordFor), name)
 }
+
+// SetPasswordFor mocks base method
+func (m *MockUserStore) SetPasswordFor(email, hash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPasswordFor", email, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPasswordFor indicates an expected call of SetPasswordFor
+func (mr *MockUserStoreMockRecorder) SetPasswordFor(email, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPasswordFor", reflect.TypeOf((*MockUserStore)(nil).SetPasswordFor), email, hash)
+}
+
+// GetUser mocks base method
+func (m *MockUserStore) GetUser(email string) (*store.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", email)
+	ret0, _ := ret[0].(*store.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser
+func (mr *MockUserStoreMockRecorder) GetUser(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockUserStore)(nil).GetUser), email)
+}
+
+// GetUserByID mocks base method
+func (m *MockUserStore) GetUserByID(id uint) (*store.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", id)
+	ret0, _ := ret[0].(*store.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID
+func (mr *MockUserStoreMockRecorder) GetUserByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserStore)(nil).GetUserByID), id)
+}
+
+// UpdateProfile mocks base method
+func (m *MockUserStore) UpdateProfile(email string, update *store.ProfileUpdate) (*store.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", email, update)
+	ret0, _ := ret[0].(*store.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile
+func (mr *MockUserStoreMockRecorder) UpdateProfile(email, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockUserStore)(nil).UpdateProfile), email, update)
+}
+
+// SetEmailVerified mocks base method
+func (m *MockUserStore) SetEmailVerified(email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEmailVerified", email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetEmailVerified indicates an expected call of SetEmailVerified
+func (mr *MockUserStoreMockRecorder) SetEmailVerified(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmailVerified", reflect.TypeOf((*MockUserStore)(nil).SetEmailVerified), email)
+}
+
+// SetRolesFor mocks base method
+func (m *MockUserStore) SetRolesFor(email string, roles []rbac.Role) (*store.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRolesFor", email, roles)
+	ret0, _ := ret[0].(*store.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetRolesFor indicates an expected call of SetRolesFor
+func (mr *MockUserStoreMockRecorder) SetRolesFor(email, roles interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRolesFor", reflect.TypeOf((*MockUserStore)(nil).SetRolesFor), email, roles)
+}
+
+// AddUser mocks base method
+func (m *MockUserStore) AddUser(user *store.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddUser", user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddUser indicates an expected call of AddUser
+func (mr *MockUserStoreMockRecorder) AddUser(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUser", reflect.TypeOf((*MockUserStore)(nil).AddUser), user)
+}
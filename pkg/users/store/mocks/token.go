@@ -0,0 +1,77 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./token.go
+
+// Package mock_store is a generated GoMock package.
+package mock_store
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	store "github.com/mimatache/go-shop/pkg/users/store"
+	reflect "reflect"
+)
+
+// MockTokenStore is a mock of TokenStore interface
+type MockTokenStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenStoreMockRecorder
+}
+
+// MockTokenStoreMockRecorder is the mock recorder for MockTokenStore
+type MockTokenStoreMockRecorder struct {
+	mock *MockTokenStore
+}
+
+// NewMockTokenStore creates a new mock instance
+func NewMockTokenStore(ctrl *gomock.Controller) *MockTokenStore {
+	mock := &MockTokenStore{ctrl: ctrl}
+	mock.recorder = &MockTokenStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTokenStore) EXPECT() *MockTokenStoreMockRecorder {
+	return m.recorder
+}
+
+// AddToken mocks base method
+func (m *MockTokenStore) AddToken(token *store.Token) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddToken", token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddToken indicates an expected call of AddToken
+func (mr *MockTokenStoreMockRecorder) AddToken(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddToken", reflect.TypeOf((*MockTokenStore)(nil).AddToken), token)
+}
+
+// ConsumeToken mocks base method
+func (m *MockTokenStore) ConsumeToken(tokenID string, purpose store.Purpose) (*store.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeToken", tokenID, purpose)
+	ret0, _ := ret[0].(*store.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeToken indicates an expected call of ConsumeToken
+func (mr *MockTokenStoreMockRecorder) ConsumeToken(tokenID, purpose interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeToken", reflect.TypeOf((*MockTokenStore)(nil).ConsumeToken), tokenID, purpose)
+}
+
+// RemoveTokensFor mocks base method
+func (m *MockTokenStore) RemoveTokensFor(email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTokensFor", email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveTokensFor indicates an expected call of RemoveTokensFor
+func (mr *MockTokenStoreMockRecorder) RemoveTokensFor(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTokensFor", reflect.TypeOf((*MockTokenStore)(nil).RemoveTokensFor), email)
+}
@@ -0,0 +1,77 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./twofactor.go
+
+// Package mock_store is a generated GoMock package.
+package mock_store
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	store "github.com/mimatache/go-shop/pkg/users/store"
+	reflect "reflect"
+)
+
+// MockTwoFactorStore is a mock of TwoFactorStore interface
+type MockTwoFactorStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorStoreMockRecorder
+}
+
+// MockTwoFactorStoreMockRecorder is the mock recorder for MockTwoFactorStore
+type MockTwoFactorStoreMockRecorder struct {
+	mock *MockTwoFactorStore
+}
+
+// NewMockTwoFactorStore creates a new mock instance
+func NewMockTwoFactorStore(ctrl *gomock.Controller) *MockTwoFactorStore {
+	mock := &MockTwoFactorStore{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTwoFactorStore) EXPECT() *MockTwoFactorStoreMockRecorder {
+	return m.recorder
+}
+
+// GetTwoFactor mocks base method
+func (m *MockTwoFactorStore) GetTwoFactor(email string) (*store.TwoFactor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTwoFactor", email)
+	ret0, _ := ret[0].(*store.TwoFactor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTwoFactor indicates an expected call of GetTwoFactor
+func (mr *MockTwoFactorStoreMockRecorder) GetTwoFactor(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTwoFactor", reflect.TypeOf((*MockTwoFactorStore)(nil).GetTwoFactor), email)
+}
+
+// SaveTwoFactor mocks base method
+func (m *MockTwoFactorStore) SaveTwoFactor(twoFactor *store.TwoFactor) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTwoFactor", twoFactor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTwoFactor indicates an expected call of SaveTwoFactor
+func (mr *MockTwoFactorStoreMockRecorder) SaveTwoFactor(twoFactor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTwoFactor", reflect.TypeOf((*MockTwoFactorStore)(nil).SaveTwoFactor), twoFactor)
+}
+
+// RemoveTwoFactor mocks base method
+func (m *MockTwoFactorStore) RemoveTwoFactor(email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTwoFactor", email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveTwoFactor indicates an expected call of RemoveTwoFactor
+func (mr *MockTwoFactorStoreMockRecorder) RemoveTwoFactor(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTwoFactor", reflect.TypeOf((*MockTwoFactorStore)(nil).RemoveTwoFactor), email)
+}
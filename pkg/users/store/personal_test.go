@@ -0,0 +1,76 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/internal/rbac"
+	"github.com/mimatache/go-shop/internal/store"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+)
+
+func TestPersonalTables(t *testing.T) {
+	g := NewWithT(t)
+
+	log, _, _ := logger.New("test", true)
+
+	schema := store.NewSchema()
+	schema.AddToSchema(userStore.GetTable())
+	schema.AddToSchema(userStore.GetTokenTable())
+	schema.AddToSchema(userStore.GetAddressTable())
+	schema.AddToSchema(userStore.GetAttemptsTable())
+	schema.AddToSchema(userStore.GetAuditTable())
+	schema.AddToSchema(userStore.GetTwoFactorTable())
+	schema.AddToSchema(userStore.GetSessionTable())
+	schema.AddToSchema(userStore.GetRefreshTokenTable())
+	schema.AddToSchema(userStore.GetAPIKeyTable())
+	db, err := store.New(schema)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	users := userStore.New(log, db)
+	g.Expect(users.AddUser(&userStore.User{Name: "user", Email: userEmail, Password: "$argon2id$hash"})).To(Succeed())
+	g.Expect(users.AddUser(&userStore.User{Name: "other", Email: "other@email.com", Password: "$argon2id$hash"})).To(Succeed())
+	g.Expect(userStore.NewTokenStore(log, db).AddToken(&userStore.Token{ID: "token", Purpose: userStore.PasswordReset, Email: userEmail, ExpiresAt: time.Now()})).To(Succeed())
+	g.Expect(userStore.NewAddressStore(log, db).SaveAddress(newAddress(userEmail))).To(Succeed())
+	lockouts := userStore.NewLockoutStore(log, db)
+	g.Expect(lockouts.SaveAttempts(&userStore.Attempts{ID: "account:" + userEmail, Failures: 1})).To(Succeed())
+	g.Expect(lockouts.SaveAttempts(&userStore.Attempts{ID: "ip:10.0.0.1", Failures: 1})).To(Succeed())
+	g.Expect(lockouts.AddAuditEntry(&userStore.AuditEntry{ID: "locked", Event: userStore.Locked, Subject: "account:" + userEmail, Actor: "system"})).To(Succeed())
+	g.Expect(lockouts.AddAuditEntry(&userStore.AuditEntry{ID: "unlocked", Event: userStore.Unlocked, Subject: "account:other@email.com", Actor: userEmail})).To(Succeed())
+	g.Expect(lockouts.AddAuditEntry(&userStore.AuditEntry{ID: "other", Event: userStore.Locked, Subject: "ip:10.0.0.1", Actor: "system"})).To(Succeed())
+	g.Expect(userStore.NewTwoFactorStore(log, db).SaveTwoFactor(&userStore.TwoFactor{Email: userEmail, Secret: "secret", Enabled: true})).To(Succeed())
+	sessions := userStore.NewSessionStore(log, db)
+	g.Expect(sessions.SaveSession(&userStore.Session{ID: "session", Email: userEmail})).To(Succeed())
+	g.Expect(sessions.SaveRefreshToken(&userStore.RefreshToken{ID: "refresh", SessionID: "session"})).To(Succeed())
+	g.Expect(userStore.NewAPIKeyStore(log, db).SaveAPIKey(&userStore.APIKey{ID: "key", Email: userEmail, Hash: "hash", Scopes: []rbac.Permission{rbac.UseAccount}})).To(Succeed())
+
+	export, err := db.Export(userEmail)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(export).To(HaveLen(9))
+	g.Expect(export["user"]).To(ConsistOf(&userStore.Profile{ID: 1, Name: "user", Email: userEmail, Roles: []rbac.Role{rbac.Customer}}))
+	g.Expect(export["loginAttempts"]).To(HaveLen(1))
+	g.Expect(export["loginAudit"]).To(HaveLen(2))
+
+	g.Expect(db.Erase(userEmail, "deleted-1")).To(Succeed())
+
+	export, err = db.Export(userEmail)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(export).To(BeEmpty())
+	_, err = db.Read(userStore.GetRefreshTokenTable().GetName(), "id", "refresh")
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue())
+	_, err = users.GetUser("other@email.com")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	_, err = lockouts.GetAttempts("ip:10.0.0.1")
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	entries, err := lockouts.GetAuditEntries()
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(entries).To(ConsistOf(
+		&userStore.AuditEntry{ID: "locked", Event: userStore.Locked, Subject: "account:deleted-1", Actor: "system"},
+		&userStore.AuditEntry{ID: "unlocked", Event: userStore.Unlocked, Subject: "account:other@email.com", Actor: "deleted-1"},
+		&userStore.AuditEntry{ID: "other", Event: userStore.Locked, Subject: "ip:10.0.0.1", Actor: "system"},
+	), "the audit log is kept without the user")
+}
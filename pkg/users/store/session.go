@@ -0,0 +1,309 @@
+package store
+
+import (
+	"time"
+
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/mimatache/go-shop/internal/store"
+)
+
+//go:generate mockgen -source ./session.go -destination mocks/session.go
+
+var (
+	sessionTable      = &SessionTable{name: "userSession"}
+	refreshTokenTable = &RefreshTokenTable{name: "refreshToken"}
+)
+
+// GetSessionTable returns the table of the sessions of the users
+func GetSessionTable() *SessionTable {
+	return sessionTable
+}
+
+// SessionTable the schema of the session table
+type SessionTable struct {
+	name string
+}
+
+// GetName returns the name of the session table
+func (s *SessionTable) GetName() string {
+	return s.name
+}
+
+// GetTableSchema returns the schema of the session table
+func (s *SessionTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: s.name,
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:    "id",
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "ID"},
+			},
+			"user": {
+				Name:    "user",
+				Unique:  false,
+				Indexer: &memdb.StringFieldIndex{Field: "Email"},
+			},
+		},
+	}
+}
+
+// GetPersonalRows returns the sessions of the user
+func (s *SessionTable) GetPersonalRows(db store.Reader, userID string) ([]interface{}, error) {
+	return db.ReadAll(s.name, "user", userID)
+}
+
+// Export returns the session
+func (s *SessionTable) Export(row interface{}) interface{} {
+	return row
+}
+
+// Anonymize removes the session
+func (s *SessionTable) Anonymize(row interface{}, userID string, pseudonym string) (interface{}, error) {
+	return nil, nil
+}
+
+// GetRefreshTokenTable returns the table of the refresh tokens
+func GetRefreshTokenTable() *RefreshTokenTable {
+	return refreshTokenTable
+}
+
+// RefreshTokenTable the schema of the refresh token table
+type RefreshTokenTable struct {
+	name string
+}
+
+// GetName returns the name of the refresh token table
+func (r *RefreshTokenTable) GetName() string {
+	return r.name
+}
+
+// GetTableSchema returns the schema of the refresh token table
+func (r *RefreshTokenTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: r.name,
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:    "id",
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "ID"},
+			},
+			"session": {
+				Name:    "session",
+				Unique:  false,
+				Indexer: &memdb.StringFieldIndex{Field: "SessionID"},
+			},
+		},
+	}
+}
+
+// GetPersonalRows returns the refresh tokens of the sessions of the user
+func (r *RefreshTokenTable) GetPersonalRows(db store.Reader, userID string) ([]interface{}, error) {
+	sessions, err := db.ReadAll(sessionTable.GetName(), "user", userID)
+	if err != nil {
+		return nil, err
+	}
+	tokens := []interface{}{}
+	for _, session := range sessions {
+		found, err := db.ReadAll(r.name, "session", session.(*Session).ID)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, found...)
+	}
+	return tokens, nil
+}
+
+// Export returns the refresh token, which only holds the hash of the token
+func (r *RefreshTokenTable) Export(row interface{}) interface{} {
+	return row
+}
+
+// Anonymize removes the refresh token
+func (r *RefreshTokenTable) Anonymize(row interface{}, userID string, pseudonym string) (interface{}, error) {
+	return nil, nil
+}
+
+// Session is a login of a user, kept alive by refreshing its tokens. Every refresh token issued for a session
+// belongs to its family
+type Session struct {
+	ID         string    `json:"id"`
+	Email      string    `json:"-"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"userAgent"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	// ExpiresAt is when the last refresh token of the session expires
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// RefreshToken gives a new access token once. Only the hash of the token is stored, as its ID. Used tokens are kept
+// until their session ends, so that using one again can be detected
+type RefreshToken struct {
+	ID        string    `json:"id"`
+	SessionID string    `json:"sessionId"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Used      bool      `json:"used"`
+}
+
+// SessionStore represents the store of the sessions and of their refresh tokens
+type SessionStore interface {
+	// GetSession returns the session with the given ID
+	GetSession(id string) (*Session, error)
+	// GetSessionsFor returns every session of the user
+	GetSessionsFor(email string) ([]*Session, error)
+	// SaveSession stores the session, replacing any previous version
+	SaveSession(session *Session) error
+	// RemoveSession removes the session and every refresh token issued for it
+	RemoveSession(id string) error
+	// GetRefreshToken returns the refresh token with the given ID
+	GetRefreshToken(id string) (*RefreshToken, error)
+	// SaveRefreshToken stores the refresh token, replacing any previous version
+	SaveRefreshToken(token *RefreshToken) error
+}
+
+// NewSessionStore creates a new session store instance
+func NewSessionStore(log logger, db UnderlyingStore) SessionStore {
+	return &sessionLogger{
+		log:  log,
+		next: &sessionStore{db: db},
+	}
+}
+
+type sessionStore struct {
+	db UnderlyingStore
+}
+
+func (s *sessionStore) GetSession(id string) (*Session, error) {
+	raw, err := s.db.Read(sessionTable.GetName(), "id", id)
+	if err != nil {
+		return nil, err
+	}
+	return raw.(*Session), nil
+}
+
+func (s *sessionStore) GetSessionsFor(email string) ([]*Session, error) {
+	rows, err := s.db.ReadAll(sessionTable.GetName(), "user", email)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]*Session, 0, len(rows))
+	for _, row := range rows {
+		sessions = append(sessions, row.(*Session))
+	}
+	return sessions, nil
+}
+
+func (s *sessionStore) SaveSession(session *Session) error {
+	return s.db.Write(sessionTable.GetName(), session)
+}
+
+func (s *sessionStore) RemoveSession(id string) error {
+	// the tokens are removed first, so that a failure leaves no token without its session
+	if err := s.db.Remove(refreshTokenTable.GetName(), "session", id); err != nil {
+		return err
+	}
+	return s.db.Remove(sessionTable.GetName(), "id", id)
+}
+
+func (s *sessionStore) GetRefreshToken(id string) (*RefreshToken, error) {
+	raw, err := s.db.Read(refreshTokenTable.GetName(), "id", id)
+	if err != nil {
+		return nil, err
+	}
+	return raw.(*RefreshToken), nil
+}
+
+func (s *sessionStore) SaveRefreshToken(token *RefreshToken) error {
+	return s.db.Write(refreshTokenTable.GetName(), token)
+}
+
+type sessionLogger struct {
+	log  logger
+	next SessionStore
+}
+
+func (s *sessionLogger) GetSession(id string) (*Session, error) {
+	var err error
+	defer func() {
+		if err != nil {
+			s.log.Debugf("could not retrieve session %s", id)
+			s.log.Debugf("%v", err)
+			return
+		}
+		s.log.Debugf("Retrieved session %s", id)
+	}()
+	session, err := s.next.GetSession(id)
+	return session, err
+}
+
+func (s *sessionLogger) GetSessionsFor(email string) ([]*Session, error) {
+	var err error
+	defer func() {
+		if err != nil {
+			s.log.Debugf("could not retrieve sessions of %s", email)
+			s.log.Debugf("%v", err)
+			return
+		}
+		s.log.Debugf("Retrieved sessions of %s", email)
+	}()
+	sessions, err := s.next.GetSessionsFor(email)
+	return sessions, err
+}
+
+func (s *sessionLogger) SaveSession(session *Session) error {
+	var err error
+	defer func() {
+		if err != nil {
+			s.log.Debugf("could not save session %s", session.ID)
+			s.log.Debugf("%v", err)
+			return
+		}
+		s.log.Debugf("Saved session %s", session.ID)
+	}()
+	err = s.next.SaveSession(session)
+	return err
+}
+
+func (s *sessionLogger) RemoveSession(id string) error {
+	var err error
+	defer func() {
+		if err != nil {
+			s.log.Debugf("could not remove session %s", id)
+			s.log.Debugf("%v", err)
+			return
+		}
+		s.log.Debugf("Removed session %s", id)
+	}()
+	err = s.next.RemoveSession(id)
+	return err
+}
+
+func (s *sessionLogger) GetRefreshToken(id string) (*RefreshToken, error) {
+	var err error
+	defer func() {
+		if err != nil {
+			s.log.Debugf("could not retrieve refresh token")
+			s.log.Debugf("%v", err)
+			return
+		}
+		s.log.Debugf("Retrieved refresh token")
+	}()
+	token, err := s.next.GetRefreshToken(id)
+	return token, err
+}
+
+func (s *sessionLogger) SaveRefreshToken(token *RefreshToken) error {
+	var err error
+	defer func() {
+		if err != nil {
+			s.log.Debugf("could not save refresh token of session %s", token.SessionID)
+			s.log.Debugf("%v", err)
+			return
+		}
+		s.log.Debugf("Saved refresh token of session %s", token.SessionID)
+	}()
+	err = s.next.SaveRefreshToken(token)
+	return err
+}
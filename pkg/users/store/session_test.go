@@ -0,0 +1,49 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/internal/store"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+)
+
+func TestSessionStore_RemoveSession(t *testing.T) {
+	g := NewWithT(t)
+
+	log, _, _ := logger.New("test", true)
+
+	schema := store.NewSchema()
+	schema.AddToSchema(userStore.GetSessionTable())
+	schema.AddToSchema(userStore.GetRefreshTokenTable())
+	db, err := store.New(schema)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	sessions := userStore.NewSessionStore(log, db)
+
+	session := &userStore.Session{ID: "session", Email: userEmail, ExpiresAt: time.Now().Add(time.Hour)}
+	other := &userStore.Session{ID: "other", Email: userEmail, ExpiresAt: time.Now().Add(time.Hour)}
+	g.Expect(sessions.SaveSession(session)).To(Succeed())
+	g.Expect(sessions.SaveSession(other)).To(Succeed())
+	g.Expect(sessions.SaveRefreshToken(&userStore.RefreshToken{ID: "used", SessionID: "session", Used: true})).To(Succeed())
+	g.Expect(sessions.SaveRefreshToken(&userStore.RefreshToken{ID: "current", SessionID: "session"})).To(Succeed())
+	g.Expect(sessions.SaveRefreshToken(&userStore.RefreshToken{ID: "kept", SessionID: "other"})).To(Succeed())
+
+	stored, err := sessions.GetSessionsFor(userEmail)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(stored).To(ConsistOf(session, other))
+
+	g.Expect(sessions.RemoveSession("session")).To(Succeed())
+
+	_, err = sessions.GetSession("session")
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue())
+	for _, id := range []string{"used", "current"} {
+		_, err = sessions.GetRefreshToken(id)
+		g.Expect(store.IsNotFoundError(err)).To(BeTrue(), "the tokens of the session are removed with it")
+	}
+	kept, err := sessions.GetRefreshToken("kept")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(kept.SessionID).To(Equal("other"))
+}
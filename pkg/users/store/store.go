@@ -2,9 +2,14 @@ package store
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
+	"sync"
 
 	"github.com/hashicorp/go-memdb"
+
+	"github.com/mimatache/go-shop/internal/rbac"
+	"github.com/mimatache/go-shop/internal/store"
 )
 
 //go:generate mockgen -source ./store.go -destination mocks/store.go
@@ -43,24 +48,82 @@ func (u *UserTable) GetTableSchema() *memdb.TableSchema {
 				Unique:  false,
 				Indexer: &memdb.StringFieldIndex{Field: "Name"},
 			},
-			"password": {
-				Name:    "password",
-				Unique:  false,
-				Indexer: &memdb.StringFieldIndex{Field: "Password"},
-			},
 			"email": {
 				Name:    "email",
 				Unique:  true,
-				Indexer: &memdb.StringFieldIndex{Field: "Email"},
+				Indexer: &memdb.StringFieldIndex{Field: "Email", Lowercase: true},
 			},
 		},
 	}
 }
 
+// GetPersonalRows returns the user
+func (u *UserTable) GetPersonalRows(db store.Reader, userID string) ([]interface{}, error) {
+	return db.ReadAll(u.name, "email", userID)
+}
+
+// Export returns the profile of the user, leaving out the password hash
+func (u *UserTable) Export(row interface{}) interface{} {
+	return row.(*User).Profile()
+}
+
+// Anonymize removes the user
+func (u *UserTable) Anonymize(row interface{}, userID string, pseudonym string) (interface{}, error) {
+	return nil, nil
+}
+
 // UnderlyingStore represents the interface that the DB should implement to be usable
 type UnderlyingStore interface {
 	Read(table string, key string, value interface{}) (interface{}, error)
+	ReadAll(table string, key string, args ...interface{}) ([]interface{}, error)
 	Write(table string, value ...interface{}) error
+	Remove(table string, key string, value interface{}) error
+}
+
+type duplicateEmail struct {
+	email Email
+}
+
+func (d duplicateEmail) Error() string {
+	return fmt.Sprintf("a user with the email %s already exists", d.email)
+}
+
+// IsDuplicateEmailError verifies if a given error refers to an email address that is already used by another user
+func IsDuplicateEmailError(err error) bool {
+	switch err.(type) {
+	case duplicateEmail:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewDuplicateEmail creates a new duplicate email error
+func NewDuplicateEmail(email Email) error {
+	return duplicateEmail{email: email}
+}
+
+type invalidUser struct {
+	msg string
+}
+
+func (i invalidUser) Error() string {
+	return i.msg
+}
+
+// IsInvalidUserError verifies if a given error refers to a user that does not adhere to constraints
+func IsInvalidUserError(err error) bool {
+	switch err.(type) {
+	case invalidUser:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewInvalidUser creates a new invalid user error
+func NewInvalidUser(reason error) error {
+	return invalidUser{msg: fmt.Sprintf("invalid user:\n%s", reason)}
 }
 
 // GetTable returns the user table for the schema
@@ -68,8 +131,14 @@ func GetTable() *UserTable {
 	return table
 }
 
-// LoadSeeds write the seed information to the DB.
-func LoadSeeds(seed io.Reader, db UnderlyingStore) error {
+// PasswordHasher hashes the passwords before they are stored
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	IsHash(value string) bool
+}
+
+// LoadSeeds write the seed information to the DB. Passwords given in plain text are hashed before being stored
+func LoadSeeds(seed io.Reader, db UnderlyingStore, hasher PasswordHasher) error {
 	var users []*User
 
 	err := json.NewDecoder(seed).Decode(&users)
@@ -77,6 +146,13 @@ func LoadSeeds(seed io.Reader, db UnderlyingStore) error {
 		return err
 	}
 	for _, user := range users {
+		user.Email = Email(NormalizeEmail(string(user.Email)))
+		if !hasher.IsHash(user.Password) {
+			user.Password, err = hasher.Hash(user.Password)
+			if err != nil {
+				return err
+			}
+		}
 		err = db.Write(table.GetName(), user)
 		if err != nil {
 			return err
@@ -95,12 +171,29 @@ func New(log logger, db UnderlyingStore) UserStore {
 
 // UserStore models the user DB
 type UserStore interface {
-	// GetPasswordFor returns the password for the given user
+	// GetPasswordFor returns the password hash for the given user
 	GetPasswordFor(name string) (string, error)
+	// SetPasswordFor replaces the password hash of the given user
+	SetPasswordFor(email string, hash string) error
+	// GetUser returns the user with the given email address
+	GetUser(email string) (*User, error)
+	// GetUserByID returns the user with the given ID
+	GetUserByID(id uint) (*User, error)
+	// UpdateProfile applies the changes to the profile of the given user and returns the updated user
+	UpdateProfile(email string, update *ProfileUpdate) (*User, error)
+	// SetEmailVerified marks the email address of the given user as verified
+	SetEmailVerified(email string) error
+	// SetRolesFor replaces the roles of the given user and returns the updated user
+	SetRolesFor(email string, roles []rbac.Role) (*User, error)
+	// AddUser stores a new user, under the next free ID
+	AddUser(user *User) error
 }
 
 type userStore struct {
 	db UnderlyingStore
+	// lock serializes the registrations and updates, so that no two users get the same ID or email address
+	// and no change is lost
+	lock sync.Mutex
 }
 
 func (u *userStore) GetPasswordFor(email string) (string, error) {
@@ -111,6 +204,96 @@ func (u *userStore) GetPasswordFor(email string) (string, error) {
 	return user.Password, nil
 }
 
+func (u *userStore) SetPasswordFor(email string, hash string) error {
+	_, err := u.update(email, func(user *User) {
+		user.Password = hash
+	})
+	return err
+}
+
+func (u *userStore) GetUser(email string) (*User, error) {
+	return checkAndReturn(u.db.Read(table.GetName(), "email", email))
+}
+
+func (u *userStore) GetUserByID(id uint) (*User, error) {
+	return checkAndReturn(u.db.Read(table.GetName(), "id", id))
+}
+
+func (u *userStore) SetEmailVerified(email string) error {
+	_, err := u.update(email, func(user *User) {
+		user.EmailVerified = true
+	})
+	return err
+}
+
+func (u *userStore) SetRolesFor(email string, roles []rbac.Role) (*User, error) {
+	return u.update(email, func(user *User) {
+		user.Roles = roles
+	})
+}
+
+func (u *userStore) UpdateProfile(email string, update *ProfileUpdate) (*User, error) {
+	return u.update(email, func(user *User) {
+		if update.Name != nil {
+			user.Name = *update.Name
+		}
+		if update.Phone != nil {
+			user.Phone = *update.Phone
+		}
+	})
+}
+
+// update applies the change to a copy of the user with the given email address and stores it,
+// if the changed user still adheres to constraints
+func (u *userStore) update(email string, change func(user *User)) (*User, error) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	stored, err := checkAndReturn(u.db.Read(table.GetName(), "email", email))
+	if err != nil {
+		return nil, err
+	}
+	user := *stored
+	change(&user)
+	if err := user.Validate(); err != nil {
+		return nil, NewInvalidUser(err)
+	}
+	if err := u.db.Write(table.GetName(), &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// AddUser assigns the next free ID to the user and stores it. The email address is looked up in the unique email index
+// first, and users whose address is already taken are refused
+func (u *userStore) AddUser(user *User) error {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	user.Email = Email(NormalizeEmail(string(user.Email)))
+	_, err := u.db.Read(table.GetName(), "email", string(user.Email))
+	if err == nil {
+		return NewDuplicateEmail(user.Email)
+	}
+	if !store.IsNotFoundError(err) {
+		return err
+	}
+	rows, err := u.db.ReadAll(table.GetName(), "id")
+	if err != nil {
+		return err
+	}
+	var lastID uint
+	for _, row := range rows {
+		if existing := row.(*User); existing.ID > lastID {
+			lastID = existing.ID
+		}
+	}
+	user.ID = lastID + 1
+	if err := user.Validate(); err != nil {
+		user.ID = 0
+		return NewInvalidUser(err)
+	}
+	return u.db.Write(table.GetName(), user)
+}
+
 type userLogger struct {
 	log   logger
 	store UserStore
@@ -130,6 +313,104 @@ func (u *userLogger) GetPasswordFor(name string) (string, error) {
 	return user, err
 }
 
+func (u *userLogger) SetPasswordFor(email string, hash string) error {
+	var err error
+	defer func() {
+		if err != nil {
+			u.log.Debugf("could not set password for user %s", email)
+			u.log.Debugf("%v", err)
+			return
+		}
+		u.log.Debugf("Set password for user %s", email)
+	}()
+	err = u.store.SetPasswordFor(email, hash)
+	return err
+}
+
+func (u *userLogger) GetUser(email string) (*User, error) {
+	var err error
+	defer func() {
+		if err != nil {
+			u.log.Debugf("could not retrieve user %s", email)
+			u.log.Debugf("%v", err)
+			return
+		}
+		u.log.Debugf("Retrieved user %s", email)
+	}()
+	user, err := u.store.GetUser(email)
+	return user, err
+}
+
+func (u *userLogger) GetUserByID(id uint) (*User, error) {
+	var err error
+	defer func() {
+		if err != nil {
+			u.log.Debugf("could not retrieve user with ID %d", id)
+			u.log.Debugf("%v", err)
+			return
+		}
+		u.log.Debugf("Retrieved user with ID %d", id)
+	}()
+	user, err := u.store.GetUserByID(id)
+	return user, err
+}
+
+func (u *userLogger) UpdateProfile(email string, update *ProfileUpdate) (*User, error) {
+	var err error
+	defer func() {
+		if err != nil {
+			u.log.Debugf("could not update profile of user %s", email)
+			u.log.Debugf("%v", err)
+			return
+		}
+		u.log.Debugf("Updated profile of user %s", email)
+	}()
+	user, err := u.store.UpdateProfile(email, update)
+	return user, err
+}
+
+func (u *userLogger) SetRolesFor(email string, roles []rbac.Role) (*User, error) {
+	var err error
+	defer func() {
+		if err != nil {
+			u.log.Debugf("could not set roles of user %s", email)
+			u.log.Debugf("%v", err)
+			return
+		}
+		u.log.Debugf("Set roles of user %s to %v", email, roles)
+	}()
+	user, err := u.store.SetRolesFor(email, roles)
+	return user, err
+}
+
+func (u *userLogger) SetEmailVerified(email string) error {
+	var err error
+	defer func() {
+		if err != nil {
+			u.log.Debugf("could not verify email of user %s", email)
+			u.log.Debugf("%v", err)
+			return
+		}
+		u.log.Debugf("Verified email of user %s", email)
+	}()
+	err = u.store.SetEmailVerified(email)
+	return err
+}
+
+func (u *userLogger) AddUser(user *User) error {
+	var err error
+	defer func() {
+		if err != nil {
+			u.log.Debugf("could not add user %s", user.Email)
+			u.log.Debugf("%v", err)
+			return
+		}
+		u.log.Debugf("Added user %s with ID %d", user.Email, user.ID)
+	}()
+	err = u.store.AddUser(user)
+	return err
+}
+
 // checkAndReturn reads the output from the DB and returns a User instance if no error occurred.
 // This will panic if the DB does not return and error but the output is not an User.
 // Intentinally left to do this as if this happens it means we have an incosistency in the DB that should be resolve immediately
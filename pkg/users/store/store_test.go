@@ -1,7 +1,9 @@
 package store_test
 
 import (
-	// "fmt"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -71,3 +73,201 @@ func TestUserStore_GiveInvalidEmail(t *testing.T) {
 
 	g.Expect(err).Should(HaveOccurred())
 }
+
+func TestUserStore_AddUser(t *testing.T) {
+	g := NewWithT(t)
+
+	log, _, _ := logger.New("test", true)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mock_store.NewMockUnderlyingStore(ctrl)
+
+	users := userStore.New(log, mockStore)
+
+	newUser := &userStore.User{Name: "new", Email: "new@email.com", Password: password}
+	mockStore.
+		EXPECT().
+		Read("user", "email", "new@email.com").
+		Return(nil, store.NewNotFoundError("user", "email", "new@email.com"))
+	mockStore.
+		EXPECT().
+		ReadAll("user", "id").
+		Return([]interface{}{user, &userStore.User{ID: 7}}, nil)
+	mockStore.
+		EXPECT().
+		Write("user", newUser).
+		Return(nil)
+
+	err := users.AddUser(newUser)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(newUser.ID).To(Equal(uint(8)))
+}
+
+func TestUserStore_AddUser_DuplicateEmail(t *testing.T) {
+	g := NewWithT(t)
+
+	log, _, _ := logger.New("test", true)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mock_store.NewMockUnderlyingStore(ctrl)
+
+	users := userStore.New(log, mockStore)
+
+	mockStore.
+		EXPECT().
+		Read("user", "email", userEmail).
+		Return(user, nil)
+
+	err := users.AddUser(&userStore.User{Name: "other", Email: userEmail, Password: password})
+
+	g.Expect(userStore.IsDuplicateEmailError(err)).To(BeTrue())
+}
+
+func TestUserStore_AddUser_DuplicateEmailOtherCase(t *testing.T) {
+	g := NewWithT(t)
+
+	log, _, _ := logger.New("test", true)
+
+	schema := store.NewSchema()
+	schema.AddToSchema(userStore.GetTable())
+	db, err := store.New(schema)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	users := userStore.New(log, db)
+
+	g.Expect(users.AddUser(&userStore.User{Name: "user", Email: " User@Email.com ", Password: password})).To(Succeed())
+	err = users.AddUser(&userStore.User{Name: "other", Email: "USER@EMAIL.COM", Password: password})
+	g.Expect(userStore.IsDuplicateEmailError(err)).To(BeTrue())
+
+	stored, err := users.GetUser("uSeR@eMaIl.CoM")
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(stored.Email).To(Equal(userStore.Email(userEmail)))
+}
+
+func TestUserStore_AddUser_Invalid(t *testing.T) {
+	g := NewWithT(t)
+
+	log, _, _ := logger.New("test", true)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mock_store.NewMockUnderlyingStore(ctrl)
+
+	users := userStore.New(log, mockStore)
+
+	mockStore.
+		EXPECT().
+		Read("user", "email", "not an email").
+		Return(nil, store.NewNotFoundError("user", "email", "not an email"))
+	mockStore.
+		EXPECT().
+		ReadAll("user", "id").
+		Return([]interface{}{}, nil)
+
+	invalid := &userStore.User{Email: "not an email"}
+	err := users.AddUser(invalid)
+
+	g.Expect(userStore.IsInvalidUserError(err)).To(BeTrue())
+	g.Expect(invalid.ID).To(BeZero())
+}
+
+func TestUserStore_AddUser_Concurrent(t *testing.T) {
+	g := NewWithT(t)
+
+	log, _, _ := logger.New("test", true)
+
+	schema := store.NewSchema()
+	schema.AddToSchema(userStore.GetTable())
+	db, err := store.New(schema)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	users := userStore.New(log, db)
+
+	const registrations = 20
+	var wg sync.WaitGroup
+	added := make([]*userStore.User, registrations)
+	errs := make([]error, registrations)
+	for i := 0; i < registrations; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// every address is registered twice
+			added[i] = &userStore.User{Name: "user", Email: userStore.Email(fmt.Sprintf("user%d@email.com", i/2)), Password: password}
+			errs[i] = users.AddUser(added[i])
+		}(i)
+	}
+	wg.Wait()
+
+	ids := map[uint]bool{}
+	duplicates := 0
+	for i := range added {
+		if errs[i] != nil {
+			g.Expect(userStore.IsDuplicateEmailError(errs[i])).To(BeTrue())
+			duplicates++
+			continue
+		}
+		g.Expect(ids).ShouldNot(HaveKey(added[i].ID))
+		ids[added[i].ID] = true
+	}
+	g.Expect(duplicates).To(Equal(registrations / 2))
+	g.Expect(ids).To(HaveLen(registrations / 2))
+}
+
+type fakeHasher struct{}
+
+func (fakeHasher) Hash(password string) (string, error) { return "$hash$" + password, nil }
+func (fakeHasher) IsHash(value string) bool             { return strings.HasPrefix(value, "$hash$") }
+
+func TestLoadSeeds_HashesPlainPasswords(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mock_store.NewMockUnderlyingStore(ctrl)
+
+	seeds := `[{"ID":1,"Name":"plain","Password":"1234","Email":"plain@email.com"},{"ID":2,"Name":"hashed","Password":"$hash$5678","Email":"hashed@email.com"}]`
+	mockStore.EXPECT().Write("user", gomock.Any()).DoAndReturn(func(_ string, users ...interface{}) error {
+		g.Expect(users[0].(*userStore.User).Password).To(Equal("$hash$1234"))
+		return nil
+	})
+	mockStore.EXPECT().Write("user", gomock.Any()).DoAndReturn(func(_ string, users ...interface{}) error {
+		g.Expect(users[0].(*userStore.User).Password).To(Equal("$hash$5678"), "hashed seeds are kept")
+		return nil
+	})
+
+	g.Expect(userStore.LoadSeeds(strings.NewReader(seeds), mockStore, fakeHasher{})).To(Succeed())
+}
+
+func TestUserStore_UpdateProfile(t *testing.T) {
+	g := NewWithT(t)
+
+	log, _, _ := logger.New("test", true)
+
+	schema := store.NewSchema()
+	schema.AddToSchema(userStore.GetTable())
+	db, err := store.New(schema)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	users := userStore.New(log, db)
+	g.Expect(users.AddUser(&userStore.User{Name: "user", Email: userEmail, Password: password})).To(Succeed())
+
+	phone := "+40 721 000 000"
+	updated, err := users.UpdateProfile(userEmail, &userStore.ProfileUpdate{Phone: &phone})
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(updated.Name).To(Equal("user"), "fields that are not given are kept")
+	g.Expect(updated.Phone).To(Equal(phone))
+
+	empty := ""
+	_, err = users.UpdateProfile(userEmail, &userStore.ProfileUpdate{Name: &empty})
+	g.Expect(userStore.IsInvalidUserError(err)).To(BeTrue())
+
+	stored, err := users.GetUser(userEmail)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(stored.Name).To(Equal("user"), "invalid changes are not stored")
+}
@@ -0,0 +1,183 @@
+package store
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/mimatache/go-shop/internal/store"
+)
+
+//go:generate mockgen -source ./token.go -destination mocks/token.go
+
+var (
+	tokenTable = &TokenTable{name: "userToken"}
+)
+
+// GetTokenTable returns the table of the tokens sent to the users by email
+func GetTokenTable() *TokenTable {
+	return tokenTable
+}
+
+// TokenTable the schema of the token table
+type TokenTable struct {
+	name string
+}
+
+// GetName returns the name of the token table
+func (t *TokenTable) GetName() string {
+	return t.name
+}
+
+// GetTableSchema returns the schema of the token table
+func (t *TokenTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: t.name,
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:    "id",
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "ID"},
+			},
+			"email": {
+				Name:    "email",
+				Unique:  false,
+				Indexer: &memdb.StringFieldIndex{Field: "Email"},
+			},
+		},
+	}
+}
+
+// GetPersonalRows returns the tokens sent to the user
+func (t *TokenTable) GetPersonalRows(db store.Reader, userID string) ([]interface{}, error) {
+	return db.ReadAll(t.name, "email", userID)
+}
+
+// Export returns the token, which only holds the hash of what was sent
+func (t *TokenTable) Export(row interface{}) interface{} {
+	return row
+}
+
+// Anonymize removes the token
+func (t *TokenTable) Anonymize(row interface{}, userID string, pseudonym string) (interface{}, error) {
+	return nil, nil
+}
+
+// Purpose is what a token can be used for
+type Purpose string
+
+const (
+	// PasswordReset tokens allow choosing a new password
+	PasswordReset Purpose = "password_reset"
+	// EmailVerification tokens confirm that the user owns the email address
+	EmailVerification Purpose = "email_verification"
+)
+
+// Token is a single use token sent to a user by email. Only the hash of the token is stored, as its ID,
+// so that the tokens cannot be used by anyone reading the store
+type Token struct {
+	ID        string    `json:"id"`
+	Purpose   Purpose   `json:"purpose"`
+	Email     string    `json:"email"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// TokenStore represents the store of the tokens sent to the users by email
+type TokenStore interface {
+	// AddToken stores a new token
+	AddToken(token *Token) error
+	// ConsumeToken removes the token with the given ID and returns it, if it was issued for the given purpose
+	ConsumeToken(tokenID string, purpose Purpose) (*Token, error)
+	// RemoveTokensFor removes every token issued for the email address
+	RemoveTokensFor(email string) error
+}
+
+// NewTokenStore creates a new token store instance
+func NewTokenStore(log logger, db UnderlyingStore) TokenStore {
+	return &tokenLogger{
+		log:  log,
+		next: &tokenStore{db: db},
+	}
+}
+
+type tokenStore struct {
+	db UnderlyingStore
+	// lock makes consuming a token atomic, so that it cannot be used twice
+	lock sync.Mutex
+}
+
+func (t *tokenStore) AddToken(token *Token) error {
+	return t.db.Write(tokenTable.GetName(), token)
+}
+
+func (t *tokenStore) ConsumeToken(tokenID string, purpose Purpose) (*Token, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	raw, err := t.db.Read(tokenTable.GetName(), "id", tokenID)
+	if err != nil {
+		return nil, err
+	}
+	token := raw.(*Token)
+	if token.Purpose != purpose {
+		return nil, store.NewNotFoundError(tokenTable.GetName(), "id", tokenID)
+	}
+	if err := t.db.Remove(tokenTable.GetName(), "id", tokenID); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (t *tokenStore) RemoveTokensFor(email string) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.db.Remove(tokenTable.GetName(), "email", email)
+}
+
+type tokenLogger struct {
+	log  logger
+	next TokenStore
+}
+
+func (t *tokenLogger) AddToken(token *Token) error {
+	var err error
+	defer func() {
+		if err != nil {
+			t.log.Debugf("could not add %s token for user %s", token.Purpose, token.Email)
+			t.log.Debugf("%v", err)
+			return
+		}
+		t.log.Debugf("Added %s token for user %s", token.Purpose, token.Email)
+	}()
+	err = t.next.AddToken(token)
+	return err
+}
+
+func (t *tokenLogger) ConsumeToken(tokenID string, purpose Purpose) (*Token, error) {
+	var err error
+	var token *Token
+	defer func() {
+		if err != nil {
+			t.log.Debugf("could not consume %s token", purpose)
+			t.log.Debugf("%v", err)
+			return
+		}
+		t.log.Debugf("Consumed %s token of user %s", purpose, token.Email)
+	}()
+	token, err = t.next.ConsumeToken(tokenID, purpose)
+	return token, err
+}
+
+func (t *tokenLogger) RemoveTokensFor(email string) error {
+	var err error
+	defer func() {
+		if err != nil {
+			t.log.Debugf("could not remove tokens of user %s", email)
+			t.log.Debugf("%v", err)
+			return
+		}
+		t.log.Debugf("Removed tokens of user %s", email)
+	}()
+	err = t.next.RemoveTokensFor(email)
+	return err
+}
@@ -0,0 +1,62 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/internal/store"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+)
+
+func newTokenStore(t *testing.T) userStore.TokenStore {
+	g := NewWithT(t)
+
+	log, _, _ := logger.New("test", true)
+
+	schema := store.NewSchema()
+	schema.AddToSchema(userStore.GetTable())
+	schema.AddToSchema(userStore.GetTokenTable())
+	db, err := store.New(schema)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	return userStore.NewTokenStore(log, db)
+}
+
+func TestTokenStore_ConsumeToken(t *testing.T) {
+	g := NewWithT(t)
+
+	tokens := newTokenStore(t)
+	token := &userStore.Token{ID: "hash", Purpose: userStore.PasswordReset, Email: userEmail, ExpiresAt: time.Now().Add(time.Hour)}
+	g.Expect(tokens.AddToken(token)).To(Succeed())
+
+	_, err := tokens.ConsumeToken("hash", userStore.EmailVerification)
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue(), "the token was issued for another purpose")
+
+	consumed, err := tokens.ConsumeToken("hash", userStore.PasswordReset)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(consumed).To(Equal(token))
+
+	_, err = tokens.ConsumeToken("hash", userStore.PasswordReset)
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue(), "the token can only be used once")
+}
+
+func TestTokenStore_RemoveTokensFor(t *testing.T) {
+	g := NewWithT(t)
+
+	tokens := newTokenStore(t)
+	g.Expect(tokens.AddToken(&userStore.Token{ID: "reset", Purpose: userStore.PasswordReset, Email: userEmail})).To(Succeed())
+	g.Expect(tokens.AddToken(&userStore.Token{ID: "verify", Purpose: userStore.EmailVerification, Email: userEmail})).To(Succeed())
+	g.Expect(tokens.AddToken(&userStore.Token{ID: "other", Purpose: userStore.PasswordReset, Email: "other@email.com"})).To(Succeed())
+
+	g.Expect(tokens.RemoveTokensFor(userEmail)).To(Succeed())
+
+	_, err := tokens.ConsumeToken("reset", userStore.PasswordReset)
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue())
+	_, err = tokens.ConsumeToken("verify", userStore.EmailVerification)
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue())
+	_, err = tokens.ConsumeToken("other", userStore.PasswordReset)
+	g.Expect(err).ShouldNot(HaveOccurred(), "the tokens of other users are kept")
+}
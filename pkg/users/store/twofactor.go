@@ -0,0 +1,154 @@
+package store
+
+import (
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/mimatache/go-shop/internal/store"
+)
+
+//go:generate mockgen -source ./twofactor.go -destination mocks/twofactor.go
+
+var (
+	twoFactorTable = &TwoFactorTable{name: "userTwoFactor"}
+)
+
+// GetTwoFactorTable returns the table of the second factors of the users
+func GetTwoFactorTable() *TwoFactorTable {
+	return twoFactorTable
+}
+
+// TwoFactorTable the schema of the second factor table
+type TwoFactorTable struct {
+	name string
+}
+
+// GetName returns the name of the second factor table
+func (t *TwoFactorTable) GetName() string {
+	return t.name
+}
+
+// GetTableSchema returns the schema of the second factor table
+func (t *TwoFactorTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: t.name,
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:    "id",
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "Email"},
+			},
+		},
+	}
+}
+
+// GetPersonalRows returns the two-factor authentication of the user
+func (t *TwoFactorTable) GetPersonalRows(db store.Reader, userID string) ([]interface{}, error) {
+	return db.ReadAll(t.name, "id", userID)
+}
+
+// Export returns whether two-factor authentication is enabled, leaving out the secret and the recovery codes
+func (t *TwoFactorTable) Export(row interface{}) interface{} {
+	return row
+}
+
+// Anonymize removes the two-factor authentication
+func (t *TwoFactorTable) Anonymize(row interface{}, userID string, pseudonym string) (interface{}, error) {
+	return nil, nil
+}
+
+// TwoFactor holds the TOTP second factor of a user. The secret is kept as is, since the codes are computed from it,
+// while only the hashes of the recovery codes are stored
+type TwoFactor struct {
+	Email  string `json:"email"`
+	Secret string `json:"-"`
+	// Enabled is set once the user proved they can generate codes. Until then, logging in only requires the password
+	Enabled       bool     `json:"enabled"`
+	RecoveryCodes []string `json:"-"`
+	// LastStep is the time step of the last code used, so that a code cannot be used twice
+	LastStep uint64 `json:"-"`
+}
+
+// TwoFactorStore represents the store of the second factors of the users
+type TwoFactorStore interface {
+	// GetTwoFactor returns the second factor of the user
+	GetTwoFactor(email string) (*TwoFactor, error)
+	// SaveTwoFactor stores the second factor of the user, replacing any previous one
+	SaveTwoFactor(twoFactor *TwoFactor) error
+	// RemoveTwoFactor removes the second factor of the user
+	RemoveTwoFactor(email string) error
+}
+
+// NewTwoFactorStore creates a new second factor store instance
+func NewTwoFactorStore(log logger, db UnderlyingStore) TwoFactorStore {
+	return &twoFactorLogger{
+		log:  log,
+		next: &twoFactorStore{db: db},
+	}
+}
+
+type twoFactorStore struct {
+	db UnderlyingStore
+}
+
+func (t *twoFactorStore) GetTwoFactor(email string) (*TwoFactor, error) {
+	raw, err := t.db.Read(twoFactorTable.GetName(), "id", email)
+	if err != nil {
+		return nil, err
+	}
+	return raw.(*TwoFactor), nil
+}
+
+func (t *twoFactorStore) SaveTwoFactor(twoFactor *TwoFactor) error {
+	return t.db.Write(twoFactorTable.GetName(), twoFactor)
+}
+
+func (t *twoFactorStore) RemoveTwoFactor(email string) error {
+	return t.db.Remove(twoFactorTable.GetName(), "id", email)
+}
+
+type twoFactorLogger struct {
+	log  logger
+	next TwoFactorStore
+}
+
+func (t *twoFactorLogger) GetTwoFactor(email string) (*TwoFactor, error) {
+	var err error
+	defer func() {
+		if err != nil {
+			t.log.Debugf("could not retrieve second factor of %s", email)
+			t.log.Debugf("%v", err)
+			return
+		}
+		t.log.Debugf("Retrieved second factor of %s", email)
+	}()
+	twoFactor, err := t.next.GetTwoFactor(email)
+	return twoFactor, err
+}
+
+func (t *twoFactorLogger) SaveTwoFactor(twoFactor *TwoFactor) error {
+	var err error
+	defer func() {
+		if err != nil {
+			t.log.Debugf("could not save second factor of %s", twoFactor.Email)
+			t.log.Debugf("%v", err)
+			return
+		}
+		t.log.Debugf("Saved second factor of %s", twoFactor.Email)
+	}()
+	err = t.next.SaveTwoFactor(twoFactor)
+	return err
+}
+
+func (t *twoFactorLogger) RemoveTwoFactor(email string) error {
+	var err error
+	defer func() {
+		if err != nil {
+			t.log.Debugf("could not remove second factor of %s", email)
+			t.log.Debugf("%v", err)
+			return
+		}
+		t.log.Debugf("Removed second factor of %s", email)
+	}()
+	err = t.next.RemoveTwoFactor(email)
+	return err
+}
@@ -0,0 +1,36 @@
+package store_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/internal/store"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+)
+
+func TestTwoFactorStore(t *testing.T) {
+	g := NewWithT(t)
+
+	log, _, _ := logger.New("test", true)
+
+	schema := store.NewSchema()
+	schema.AddToSchema(userStore.GetTwoFactorTable())
+	db, err := store.New(schema)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	twoFactors := userStore.NewTwoFactorStore(log, db)
+
+	_, err = twoFactors.GetTwoFactor(userEmail)
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue())
+
+	twoFactor := &userStore.TwoFactor{Email: userEmail, Secret: "SECRET", Enabled: true, RecoveryCodes: []string{"hash"}, LastStep: 42}
+	g.Expect(twoFactors.SaveTwoFactor(twoFactor)).To(Succeed())
+	stored, err := twoFactors.GetTwoFactor(userEmail)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(stored).To(Equal(twoFactor))
+
+	g.Expect(twoFactors.RemoveTwoFactor(userEmail)).To(Succeed())
+	_, err = twoFactors.GetTwoFactor(userEmail)
+	g.Expect(store.IsNotFoundError(err)).To(BeTrue())
+}
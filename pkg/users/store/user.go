@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"fmt"
 	"regexp"
+	"strings"
+
+	"github.com/mimatache/go-shop/internal/rbac"
 )
 
 type errors []error
@@ -36,12 +39,42 @@ func (e Email) Validate() error {
 	return nil
 }
 
+// NormalizeEmail trims and lower cases an email address, so that an address is found however it is typed
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+var phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 ()-]{5,19}$`)
+
+// validatePhone verifies the phone number, which is optional
+func validatePhone(phone string) error {
+	if phone != "" && !phonePattern.MatchString(phone) {
+		return fmt.Errorf("invalid phone number: %s", phone)
+	}
+	return nil
+}
+
 // User models a shop user
 type User struct {
-	ID       uint   `json:"ID"`
-	Name     string `json:"Name"`
+	ID   uint   `json:"ID"`
+	Name string `json:"Name"`
+	// Password is the salted hash of the password. Seed files can give it in plain text, it is hashed when loaded
 	Password string `json:"Password"`
 	Email    Email  `json:"Email"`
+	// EmailVerified is set once the user proves owning the email address
+	EmailVerified bool `json:"EmailVerified"`
+	// Phone is the optional phone number of the user
+	Phone string `json:"Phone,omitempty"`
+	// Roles grant the permissions of the user. Users without roles are customers
+	Roles []rbac.Role `json:"Roles,omitempty"`
+}
+
+// GetRoles returns the roles of the user, which are the customer role when none is set
+func (u User) GetRoles() []rbac.Role {
+	if len(u.Roles) == 0 {
+		return []rbac.Role{rbac.Customer}
+	}
+	return u.Roles
 }
 
 // Validate checks that a user adheres to constraints
@@ -59,8 +92,45 @@ func (u User) Validate() error {
 	if err := u.Email.Validate(); err != nil {
 		errs = append(errs, err)
 	}
+	if err := validatePhone(u.Phone); err != nil {
+		errs = append(errs, err)
+	}
+	for _, role := range u.Roles {
+		if err := role.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	if len(errs) > 0 {
 		return errs
 	}
 	return nil
 }
+
+// Profile is what is shown of a user, leaving out the password
+type Profile struct {
+	ID            uint        `json:"id"`
+	Name          string      `json:"name"`
+	Email         Email       `json:"email"`
+	EmailVerified bool        `json:"emailVerified"`
+	Phone         string      `json:"phone,omitempty"`
+	Roles         []rbac.Role `json:"roles"`
+}
+
+// ProfileUpdate holds the changes of a profile. Only the fields that are given are changed,
+// and the email address cannot be changed
+type ProfileUpdate struct {
+	Name  *string `json:"name"`
+	Phone *string `json:"phone"`
+}
+
+// Profile returns the profile of the user
+func (u User) Profile() *Profile {
+	return &Profile{
+		ID:            u.ID,
+		Name:          u.Name,
+		Email:         u.Email,
+		EmailVerified: u.EmailVerified,
+		Phone:         u.Phone,
+		Roles:         u.GetRoles(),
+	}
+}
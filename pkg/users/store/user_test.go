@@ -5,6 +5,7 @@ import (
 
 	. "github.com/onsi/gomega"
 
+	"github.com/mimatache/go-shop/internal/rbac"
 	"github.com/mimatache/go-shop/pkg/users/store"
 )
 
@@ -46,3 +47,18 @@ func TestEmail_InvalidAddresses(t *testing.T) {
 		})
 	}
 }
+
+func TestUser_Roles(t *testing.T) {
+	g := NewWithT(t)
+
+	user := store.User{ID: 1, Name: "user", Password: "hash", Email: "user@email.com"}
+	g.Expect(user.GetRoles()).To(Equal([]rbac.Role{rbac.Customer}), "users without roles are customers")
+	g.Expect(user.Validate()).To(Succeed())
+
+	user.Roles = []rbac.Role{rbac.Admin}
+	g.Expect(user.GetRoles()).To(Equal([]rbac.Role{rbac.Admin}))
+	g.Expect(user.Validate()).To(Succeed())
+
+	user.Roles = []rbac.Role{"root"}
+	g.Expect(user.Validate()).ShouldNot(Succeed(), "unknown roles are refused")
+}
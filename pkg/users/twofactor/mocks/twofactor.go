@@ -0,0 +1,154 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./twofactor.go
+
+// Package mock_twofactor is a generated GoMock package.
+package mock_twofactor
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	store "github.com/mimatache/go-shop/pkg/users/store"
+	reflect "reflect"
+)
+
+// MockTwoFactorRegistry is a mock of TwoFactorRegistry interface
+type MockTwoFactorRegistry struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorRegistryMockRecorder
+}
+
+// MockTwoFactorRegistryMockRecorder is the mock recorder for MockTwoFactorRegistry
+type MockTwoFactorRegistryMockRecorder struct {
+	mock *MockTwoFactorRegistry
+}
+
+// NewMockTwoFactorRegistry creates a new mock instance
+func NewMockTwoFactorRegistry(ctrl *gomock.Controller) *MockTwoFactorRegistry {
+	mock := &MockTwoFactorRegistry{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorRegistryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTwoFactorRegistry) EXPECT() *MockTwoFactorRegistryMockRecorder {
+	return m.recorder
+}
+
+// GetTwoFactor mocks base method
+func (m *MockTwoFactorRegistry) GetTwoFactor(email string) (*store.TwoFactor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTwoFactor", email)
+	ret0, _ := ret[0].(*store.TwoFactor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTwoFactor indicates an expected call of GetTwoFactor
+func (mr *MockTwoFactorRegistryMockRecorder) GetTwoFactor(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTwoFactor", reflect.TypeOf((*MockTwoFactorRegistry)(nil).GetTwoFactor), email)
+}
+
+// SaveTwoFactor mocks base method
+func (m *MockTwoFactorRegistry) SaveTwoFactor(twoFactor *store.TwoFactor) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTwoFactor", twoFactor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTwoFactor indicates an expected call of SaveTwoFactor
+func (mr *MockTwoFactorRegistryMockRecorder) SaveTwoFactor(twoFactor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTwoFactor", reflect.TypeOf((*MockTwoFactorRegistry)(nil).SaveTwoFactor), twoFactor)
+}
+
+// RemoveTwoFactor mocks base method
+func (m *MockTwoFactorRegistry) RemoveTwoFactor(email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTwoFactor", email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveTwoFactor indicates an expected call of RemoveTwoFactor
+func (mr *MockTwoFactorRegistryMockRecorder) RemoveTwoFactor(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTwoFactor", reflect.TypeOf((*MockTwoFactorRegistry)(nil).RemoveTwoFactor), email)
+}
+
+// MockSessionRevoker is a mock of SessionRevoker interface
+type MockSessionRevoker struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionRevokerMockRecorder
+}
+
+// MockSessionRevokerMockRecorder is the mock recorder for MockSessionRevoker
+type MockSessionRevokerMockRecorder struct {
+	mock *MockSessionRevoker
+}
+
+// NewMockSessionRevoker creates a new mock instance
+func NewMockSessionRevoker(ctrl *gomock.Controller) *MockSessionRevoker {
+	mock := &MockSessionRevoker{ctrl: ctrl}
+	mock.recorder = &MockSessionRevokerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSessionRevoker) EXPECT() *MockSessionRevokerMockRecorder {
+	return m.recorder
+}
+
+// RevokeAll mocks base method
+func (m *MockSessionRevoker) RevokeAll(email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAll", email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAll indicates an expected call of RevokeAll
+func (mr *MockSessionRevokerMockRecorder) RevokeAll(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAll", reflect.TypeOf((*MockSessionRevoker)(nil).RevokeAll), email)
+}
+
+// Mocklogger is a mock of logger interface
+type Mocklogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockloggerMockRecorder
+}
+
+// MockloggerMockRecorder is the mock recorder for Mocklogger
+type MockloggerMockRecorder struct {
+	mock *Mocklogger
+}
+
+// NewMocklogger creates a new mock instance
+func NewMocklogger(ctrl *gomock.Controller) *Mocklogger {
+	mock := &Mocklogger{ctrl: ctrl}
+	mock.recorder = &MockloggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *Mocklogger) EXPECT() *MockloggerMockRecorder {
+	return m.recorder
+}
+
+// Infow mocks base method
+func (m *Mocklogger) Infow(msg string, keysAndValues ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range keysAndValues {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Infow", varargs...)
+}
+
+// Infow indicates an expected call of Infow
+func (mr *MockloggerMockRecorder) Infow(msg interface{}, keysAndValues ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, keysAndValues...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Infow", reflect.TypeOf((*Mocklogger)(nil).Infow), varargs...)
+}
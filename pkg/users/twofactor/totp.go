@@ -0,0 +1,68 @@
+package twofactor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	secretBytes = 20
+	digits      = 6
+	period      = 30 * time.Second
+)
+
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret generates a random TOTP secret, encoded as unpadded base32 as authenticator apps expect it
+func GenerateSecret() (string, error) {
+	b := make([]byte, secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return secretEncoding.EncodeToString(b), nil
+}
+
+// URI returns the otpauth URI authenticator apps are enrolled with, usually shown as a QR code
+func URI(issuer string, account string, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(digits))
+	query.Set("period", fmt.Sprint(int(period.Seconds())))
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, query.Encode())
+}
+
+// Step returns the time step a moment falls into
+func Step(t time.Time) uint64 {
+	return uint64(t.Unix()) / uint64(period.Seconds())
+}
+
+// Code computes the code of the secret for a time step, as described by RFC 6238. It uses HMAC-SHA1, which is what
+// authenticator apps expect
+func Code(secret string, step uint64) (string, error) {
+	key, err := secretEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, step)
+	mac := hmac.New(sha1.New, key)
+	_, _ = mac.Write(message)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	truncated := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	modulo := uint32(1)
+	for i := 0; i < digits; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, truncated%modulo), nil
+}
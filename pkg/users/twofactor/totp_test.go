@@ -0,0 +1,62 @@
+package twofactor_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/pkg/users/twofactor"
+)
+
+// rfcSecret is the key of the RFC 6238 test vectors, 12345678901234567890, encoded as base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// the RFC gives 8 digit codes, of which the last 6 are expected
+	tests := []struct {
+		time int64
+		code string
+	}{
+		{time: 59, code: "287082"},
+		{time: 1111111109, code: "081804"},
+		{time: 1111111111, code: "050471"},
+		{time: 1234567890, code: "005924"},
+		{time: 2000000000, code: "279037"},
+	}
+	for _, test := range tests {
+		t.Run(test.code, func(t *testing.T) {
+			g := NewWithT(t)
+
+			code, err := twofactor.Code(rfcSecret, twofactor.Step(time.Unix(test.time, 0)))
+			g.Expect(err).ShouldNot(HaveOccurred())
+			g.Expect(code).To(Equal(test.code))
+		})
+	}
+}
+
+func TestCode_InvalidSecret(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := twofactor.Code("not base32!", 1)
+	g.Expect(err).Should(HaveOccurred())
+}
+
+func TestURI(t *testing.T) {
+	g := NewWithT(t)
+
+	secret, err := twofactor.GenerateSecret()
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(secret).To(HaveLen(32))
+
+	uri, err := url.Parse(twofactor.URI("go-shop", "jane.doe@company.com", secret))
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(uri.Scheme).To(Equal("otpauth"))
+	g.Expect(uri.Host).To(Equal("totp"))
+	g.Expect(uri.Path).To(Equal("/go-shop:jane.doe@company.com"))
+	g.Expect(uri.Query().Get("secret")).To(Equal(secret))
+	g.Expect(uri.Query().Get("issuer")).To(Equal("go-shop"))
+	g.Expect(uri.Query().Get("digits")).To(Equal("6"))
+	g.Expect(uri.Query().Get("period")).To(Equal("30"))
+}
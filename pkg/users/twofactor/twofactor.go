@@ -0,0 +1,361 @@
+package twofactor
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mimatache/go-shop/internal/store"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+)
+
+//go:generate mockgen -source ./twofactor.go -destination mocks/twofactor.go
+
+const (
+	recoveryCodes     = 10
+	recoveryCodeBytes = 5
+	// skew is how many time steps before and after the current one are accepted, to allow for clock drift
+	skew = 1
+)
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type errors []error
+
+func (e errors) Error() string {
+	b := bytes.NewBufferString("")
+	for _, err := range e {
+		_, _ = fmt.Fprintf(b, "\t%s\n", err)
+	}
+	return b.String()
+}
+
+// TwoFactorRegistry stores the second factors of the users
+type TwoFactorRegistry interface {
+	GetTwoFactor(email string) (*userStore.TwoFactor, error)
+	SaveTwoFactor(twoFactor *userStore.TwoFactor) error
+	RemoveTwoFactor(email string) error
+}
+
+// SessionRevoker ends the sessions of a user whose second factor is removed
+type SessionRevoker interface {
+	RevokeAll(email string) error
+}
+
+type logger interface {
+	Infow(msg string, keysAndValues ...interface{})
+}
+
+type invalidCode struct{}
+
+func (i invalidCode) Error() string {
+	return "the code is invalid or was already used"
+}
+
+// IsInvalidCodeError verifies if a given error refers to a code that cannot be used
+func IsInvalidCodeError(err error) bool {
+	switch err.(type) {
+	case invalidCode:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewInvalidCode creates a new invalid code error
+func NewInvalidCode() error {
+	return invalidCode{}
+}
+
+type alreadyEnabled struct {
+	email string
+}
+
+func (a alreadyEnabled) Error() string {
+	return fmt.Sprintf("two-factor authentication is already enabled for %s", a.email)
+}
+
+// IsAlreadyEnabledError verifies if a given error refers to enrolling a user whose second factor is already enabled
+func IsAlreadyEnabledError(err error) bool {
+	switch err.(type) {
+	case alreadyEnabled:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewAlreadyEnabled creates a new already enabled error
+func NewAlreadyEnabled(email string) error {
+	return alreadyEnabled{email: email}
+}
+
+// Enrollment is what the user needs to add the shop to an authenticator app
+type Enrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// Config sets how the shop appears in the authenticator apps
+type Config struct {
+	// Issuer names the shop in the authenticator apps
+	Issuer string
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+}
+
+// Validate checks that the configuration is complete
+func (c Config) Validate() error {
+	var errs errors
+	if c.Issuer == "" {
+		errs = append(errs, fmt.Errorf("issuer is mandatory"))
+	}
+	if strings.Contains(c.Issuer, ":") {
+		errs = append(errs, fmt.Errorf("issuer cannot contain a colon"))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// New creates a new Authenticator
+func New(log logger, twoFactors TwoFactorRegistry, sessions SessionRevoker, config Config) (*Authenticator, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	now := config.Now
+	if now == nil {
+		now = time.Now
+	}
+	return &Authenticator{
+		log:        log,
+		twoFactors: twoFactors,
+		sessions:   sessions,
+		config:     config,
+		now:        now,
+	}, nil
+}
+
+// Authenticator manages the optional TOTP second factor of the users. Users enroll an authenticator app, then prove
+// it works with a first code, which enables the second factor and gives them single use recovery codes, for when
+// the app is lost. From then on, logging in requires a code from the app or a recovery code
+type Authenticator struct {
+	log        logger
+	twoFactors TwoFactorRegistry
+	sessions   SessionRevoker
+	config     Config
+	now        func() time.Time
+	// lock makes the use of the codes atomic, so that none can be used twice
+	lock sync.Mutex
+}
+
+// IsEnabled checks if logging in requires a second factor for the user
+func (a *Authenticator) IsEnabled(email string) (bool, error) {
+	twoFactor, err := a.twoFactors.GetTwoFactor(email)
+	if err != nil {
+		if store.IsNotFoundError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return twoFactor.Enabled, nil
+}
+
+// Enroll generates a new secret for the user. The second factor is only enabled once confirmed with a code, so
+// enrolling again before that replaces the secret
+func (a *Authenticator) Enroll(email string) (*Enrollment, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	enabled, err := a.IsEnabled(email)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, NewAlreadyEnabled(email)
+	}
+	secret, err := GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.twoFactors.SaveTwoFactor(&userStore.TwoFactor{Email: email, Secret: secret}); err != nil {
+		return nil, err
+	}
+	return &Enrollment{
+		Secret: secret,
+		URI:    URI(a.config.Issuer, email, secret),
+	}, nil
+}
+
+// Confirm enables the second factor of the user, if the code was generated from the enrolled secret.
+// It returns the recovery codes, which are not shown again
+func (a *Authenticator) Confirm(email string, code string) ([]string, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	twoFactor, err := a.twoFactors.GetTwoFactor(email)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor.Enabled {
+		return nil, NewAlreadyEnabled(email)
+	}
+	updated := *twoFactor
+	ok, err := a.useTOTP(&updated, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, NewInvalidCode()
+	}
+	codes, err := a.newRecoveryCodes(&updated)
+	if err != nil {
+		return nil, err
+	}
+	updated.Enabled = true
+	if err := a.twoFactors.SaveTwoFactor(&updated); err != nil {
+		return nil, err
+	}
+	a.log.Infow("two-factor authentication enabled", "user", email)
+	return codes, nil
+}
+
+// Verify checks the second factor of the user, given either as a code from the authenticator app or as a
+// recovery code. Both can only be used once
+func (a *Authenticator) Verify(email string, code string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	_, err := a.use(email, code)
+	return err
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the user, after checking the second factor.
+// It returns the new recovery codes, which are not shown again
+func (a *Authenticator) RegenerateRecoveryCodes(email string, code string) ([]string, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	twoFactor, err := a.use(email, code)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := a.newRecoveryCodes(twoFactor)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.twoFactors.SaveTwoFactor(twoFactor); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable removes the second factor of the user, after checking it. The sessions of the user are ended, so that
+// a session started by whoever disabled it does not outlive the change
+func (a *Authenticator) Disable(email string, code string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if _, err := a.use(email, code); err != nil {
+		return err
+	}
+	if err := a.twoFactors.RemoveTwoFactor(email); err != nil {
+		return err
+	}
+	a.log.Infow("two-factor authentication disabled", "user", email)
+	return a.sessions.RevokeAll(email)
+}
+
+// use checks the code against the enabled second factor of the user and stores that it was used.
+// It returns the updated second factor
+func (a *Authenticator) use(email string, code string) (*userStore.TwoFactor, error) {
+	twoFactor, err := a.twoFactors.GetTwoFactor(email)
+	if err != nil {
+		if store.IsNotFoundError(err) {
+			return nil, NewInvalidCode()
+		}
+		return nil, err
+	}
+	if !twoFactor.Enabled {
+		return nil, NewInvalidCode()
+	}
+	updated := *twoFactor
+	ok, err := a.useTOTP(&updated, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok && !a.useRecoveryCode(&updated, code) {
+		return nil, NewInvalidCode()
+	}
+	if err := a.twoFactors.SaveTwoFactor(&updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// useTOTP checks the code against the time steps around the current one. Steps up to the last one used are refused,
+// so that a code cannot be used twice
+func (a *Authenticator) useTOTP(twoFactor *userStore.TwoFactor, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) != digits {
+		return false, nil
+	}
+	current := Step(a.now())
+	for step := current - skew; step <= current+skew; step++ {
+		if step <= twoFactor.LastStep {
+			continue
+		}
+		expected, err := Code(twoFactor.Secret, step)
+		if err != nil {
+			return false, err
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			twoFactor.LastStep = step
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// useRecoveryCode removes the recovery code from the second factor, if it is one of its codes
+func (a *Authenticator) useRecoveryCode(twoFactor *userStore.TwoFactor, code string) bool {
+	hashed := hashRecoveryCode(code)
+	for i, stored := range twoFactor.RecoveryCodes {
+		if subtle.ConstantTimeCompare([]byte(stored), []byte(hashed)) == 1 {
+			remaining := make([]string, 0, len(twoFactor.RecoveryCodes)-1)
+			remaining = append(remaining, twoFactor.RecoveryCodes[:i]...)
+			twoFactor.RecoveryCodes = append(remaining, twoFactor.RecoveryCodes[i+1:]...)
+			a.log.Infow("recovery code used", "user", twoFactor.Email, "remaining", len(twoFactor.RecoveryCodes))
+			return true
+		}
+	}
+	return false
+}
+
+// newRecoveryCodes replaces the recovery codes of the second factor and returns them
+func (a *Authenticator) newRecoveryCodes(twoFactor *userStore.TwoFactor) ([]string, error) {
+	codes := make([]string, 0, recoveryCodes)
+	hashes := make([]string, 0, recoveryCodes)
+	for i := 0; i < recoveryCodes; i++ {
+		b := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		encoded := strings.ToLower(recoveryEncoding.EncodeToString(b))
+		code := encoded[:4] + "-" + encoded[4:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	twoFactor.RecoveryCodes = hashes
+	return codes, nil
+}
+
+// hashRecoveryCode hashes a recovery code, ignoring its case and separators
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
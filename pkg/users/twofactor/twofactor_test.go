@@ -0,0 +1,182 @@
+package twofactor_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/internal/store"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+	"github.com/mimatache/go-shop/pkg/users/twofactor"
+	mock_twofactor "github.com/mimatache/go-shop/pkg/users/twofactor/mocks"
+)
+
+const userEmail = "user@email.com"
+
+type nopLogger struct{}
+
+func (nopLogger) Infow(msg string, keysAndValues ...interface{}) {}
+
+// clock is moved forward by the tests instead of waiting
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// revoker records the users whose sessions were ended
+type revoker struct {
+	revoked []string
+}
+
+func (r *revoker) RevokeAll(email string) error {
+	r.revoked = append(r.revoked, email)
+	return nil
+}
+
+func newAuthenticator(t *testing.T) (*twofactor.Authenticator, *clock, *revoker) {
+	g := NewWithT(t)
+
+	log, _, _ := logger.New("test", true)
+
+	schema := store.NewSchema()
+	schema.AddToSchema(userStore.GetTwoFactorTable())
+	db, err := store.New(schema)
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	c := &clock{now: time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)}
+	sessions := &revoker{}
+	authenticator, err := twofactor.New(nopLogger{}, userStore.NewTwoFactorStore(log, db), sessions, twofactor.Config{Issuer: "go-shop", Now: c.Now})
+	g.Expect(err).ShouldNot(HaveOccurred())
+	return authenticator, c, sessions
+}
+
+func code(t *testing.T, secret string, at time.Time) string {
+	g := NewWithT(t)
+
+	code, err := twofactor.Code(secret, twofactor.Step(at))
+	g.Expect(err).ShouldNot(HaveOccurred())
+	return code
+}
+
+// enable enrolls the user and confirms the second factor, returning the secret and the recovery codes
+func enable(t *testing.T, authenticator *twofactor.Authenticator, c *clock) (string, []string) {
+	g := NewWithT(t)
+
+	enrollment, err := authenticator.Enroll(userEmail)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	codes, err := authenticator.Confirm(userEmail, code(t, enrollment.Secret, c.Now()))
+	g.Expect(err).ShouldNot(HaveOccurred())
+	c.Advance(30 * time.Second)
+	return enrollment.Secret, codes
+}
+
+func TestAuthenticator_Enroll(t *testing.T) {
+	g := NewWithT(t)
+
+	authenticator, c, _ := newAuthenticator(t)
+	enrollment, err := authenticator.Enroll(userEmail)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(enrollment.URI).To(ContainSubstring("secret=" + enrollment.Secret))
+
+	enabled, err := authenticator.IsEnabled(userEmail)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(enabled).To(BeFalse(), "the second factor is only enabled once confirmed")
+
+	_, err = authenticator.Confirm(userEmail, "000000")
+	g.Expect(twofactor.IsInvalidCodeError(err)).To(BeTrue())
+
+	codes, err := authenticator.Confirm(userEmail, code(t, enrollment.Secret, c.Now().Add(-30*time.Second)))
+	g.Expect(err).ShouldNot(HaveOccurred(), "the previous code is still accepted")
+	g.Expect(codes).To(HaveLen(10))
+
+	enabled, err = authenticator.IsEnabled(userEmail)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(enabled).To(BeTrue())
+
+	_, err = authenticator.Enroll(userEmail)
+	g.Expect(twofactor.IsAlreadyEnabledError(err)).To(BeTrue())
+}
+
+func TestAuthenticator_Verify(t *testing.T) {
+	g := NewWithT(t)
+
+	authenticator, c, _ := newAuthenticator(t)
+	g.Expect(twofactor.IsInvalidCodeError(authenticator.Verify(userEmail, "000000"))).To(BeTrue(), "the user has no second factor")
+
+	secret, _ := enable(t, authenticator, c)
+	current := code(t, secret, c.Now())
+	g.Expect(authenticator.Verify(userEmail, current)).To(Succeed())
+	g.Expect(twofactor.IsInvalidCodeError(authenticator.Verify(userEmail, current))).To(BeTrue(), "a code can only be used once")
+
+	c.Advance(30 * time.Second)
+	g.Expect(twofactor.IsInvalidCodeError(authenticator.Verify(userEmail, code(t, secret, c.Now().Add(-time.Minute))))).To(BeTrue(), "the code is too old")
+	g.Expect(authenticator.Verify(userEmail, code(t, secret, c.Now().Add(30*time.Second)))).To(Succeed(), "the clock of the app can be ahead")
+}
+
+func TestAuthenticator_RecoveryCodes(t *testing.T) {
+	g := NewWithT(t)
+
+	authenticator, c, _ := newAuthenticator(t)
+	secret, codes := enable(t, authenticator, c)
+
+	g.Expect(authenticator.Verify(userEmail, codes[0])).To(Succeed())
+	g.Expect(twofactor.IsInvalidCodeError(authenticator.Verify(userEmail, codes[0]))).To(BeTrue(), "a recovery code can only be used once")
+	g.Expect(authenticator.Verify(userEmail, fmt.Sprintf(" %s ", codes[1][:4]+codes[1][5:]))).To(Succeed(), "separators are ignored")
+
+	regenerated, err := authenticator.RegenerateRecoveryCodes(userEmail, code(t, secret, c.Now()))
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(regenerated).To(HaveLen(10))
+	g.Expect(twofactor.IsInvalidCodeError(authenticator.Verify(userEmail, codes[2]))).To(BeTrue(), "the previous codes are revoked")
+	g.Expect(authenticator.Verify(userEmail, regenerated[0])).To(Succeed())
+}
+
+func TestAuthenticator_Disable(t *testing.T) {
+	g := NewWithT(t)
+
+	authenticator, c, sessions := newAuthenticator(t)
+	_, codes := enable(t, authenticator, c)
+
+	g.Expect(twofactor.IsInvalidCodeError(authenticator.Disable(userEmail, "000000"))).To(BeTrue())
+	g.Expect(sessions.revoked).To(BeEmpty(), "the sessions are kept when the code is wrong")
+	g.Expect(authenticator.Disable(userEmail, codes[0])).To(Succeed())
+	g.Expect(sessions.revoked).To(Equal([]string{userEmail}))
+
+	enabled, err := authenticator.IsEnabled(userEmail)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(enabled).To(BeFalse())
+}
+
+func TestAuthenticator_StoreFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	twoFactors := mock_twofactor.NewMockTwoFactorRegistry(ctrl)
+	authenticator, err := twofactor.New(nopLogger{}, twoFactors, &revoker{}, twofactor.Config{Issuer: "go-shop"})
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	twoFactors.EXPECT().GetTwoFactor(userEmail).Return(nil, fmt.Errorf("store unavailable"))
+
+	err = authenticator.Verify(userEmail, "000000")
+	g.Expect(err).Should(HaveOccurred())
+	g.Expect(twofactor.IsInvalidCodeError(err)).To(BeFalse())
+}
+
+func TestConfig_Validate(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(twofactor.Config{Issuer: "go-shop"}.Validate()).To(Succeed())
+	g.Expect(twofactor.Config{}.Validate()).ShouldNot(Succeed())
+	g.Expect(twofactor.Config{Issuer: "go:shop"}.Validate()).ShouldNot(Succeed())
+}
@@ -1,19 +1,80 @@
 package users
 
 import (
+	netHTTP "net/http"
+
 	"github.com/gorilla/mux"
 
 	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/internal/mail"
+	"github.com/mimatache/go-shop/internal/rbac"
+	"github.com/mimatache/go-shop/pkg/users/addresses"
+	"github.com/mimatache/go-shop/pkg/users/apikeys"
 	"github.com/mimatache/go-shop/pkg/users/authentication"
 	"github.com/mimatache/go-shop/pkg/users/http"
+	"github.com/mimatache/go-shop/pkg/users/lockout"
+	"github.com/mimatache/go-shop/pkg/users/password"
+	"github.com/mimatache/go-shop/pkg/users/privacy"
+	"github.com/mimatache/go-shop/pkg/users/sessions"
 	"github.com/mimatache/go-shop/pkg/users/store"
+	"github.com/mimatache/go-shop/pkg/users/twofactor"
+	"github.com/mimatache/go-shop/pkg/users/verification"
 )
 
-// NewAPI instantiates a new user API and storage
-func NewAPI(log logger.Logger, router *mux.Router, db store.UnderlyingStore) *authentication.User {
+// NewAddressBook instantiates the address books of the users. It is created on its own, so that checkout can use it
+func NewAddressBook(log logger.Logger, db store.UnderlyingStore) *addresses.Book {
+	return addresses.New(store.NewAddressStore(log, db))
+}
+
+// NewAPIKeys instantiates the API keys of the users. It is created on its own, so that the authorization
+// middlewares can use it
+func NewAPIKeys(log logger.Logger, db store.UnderlyingStore) *apikeys.Keyring {
+	return apikeys.New(log, store.NewAPIKeyStore(log, db), store.New(log, db), apikeys.Config{Policy: rbac.DefaultPolicy})
+}
+
+// NewAPI instantiates a new user API and storage. Passwords are hashed with the given hasher, and the tokens
+// for password resets and email verification are sent through the given sender. Logins failing too often are
+// locked out as configured, and users can add a TOTP second factor to their login. Users stay logged in
+// with rotating refresh tokens, valid as configured
+func NewAPI(
+	log logger.Logger,
+	router *mux.Router,
+	db store.UnderlyingStore,
+	carts http.CartMerger,
+	addressBook *addresses.Book,
+	apiKeys *apikeys.Keyring,
+	personalData privacy.PersonalData,
+	hasher *password.Hasher,
+	sender mail.Sender,
+	verificationConfig verification.Config,
+	lockoutConfig lockout.Config,
+	twoFactorConfig twofactor.Config,
+	sessionConfig sessions.Config,
+	userHandler func(netHTTP.Handler) netHTTP.Handler,
+	adminHandler func(permission rbac.Permission) func(netHTTP.Handler) netHTTP.Handler,
+) (*authentication.User, error) {
 	users := store.New(log, db)
-	authentication := authentication.New(users)
-	webAPI := http.New(authentication)
+	authentication := authentication.New(users, hasher, log)
+	sessionManager, err := sessions.New(log, store.NewSessionStore(log, db), sessionConfig)
+	if err != nil {
+		return nil, err
+	}
+	verifier := verification.New(log, users, store.NewTokenStore(log, db), sessionManager, hasher, sender, verificationConfig)
+	guard, err := lockout.New(log, store.NewLockoutStore(log, db), lockoutConfig)
+	if err != nil {
+		return nil, err
+	}
+	secondFactor, err := twofactor.New(log, store.NewTwoFactorStore(log, db), sessionManager, twoFactorConfig)
+	if err != nil {
+		return nil, err
+	}
+	webAPI := http.New(authentication, carts, verifier, guard, secondFactor, sessionManager, log)
 	webAPI.RegisterToRouter(router)
-	return authentication
+	http.NewTwoFactorAPI(secondFactor).AddRoutes(router)
+	http.NewSessionAPI(sessionManager).AddRoutes(router)
+	http.NewAPIKeyAPI(apiKeys).AddRoutes(router)
+	http.NewPrivacyAPI(privacy.New(log, personalData, authentication, privacy.Config{})).AddRoutes(router)
+	http.NewProfileAPI(authentication, addressBook).AddRoutes(router, userHandler, adminHandler)
+	http.NewLockoutAPI(guard).AddRoutes(router, adminHandler)
+	return authentication, nil
 }
@@ -0,0 +1,296 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./verification.go
+
+// Package mock_verification is a generated GoMock package.
+package mock_verification
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	mail "github.com/mimatache/go-shop/internal/mail"
+	store "github.com/mimatache/go-shop/pkg/users/store"
+	reflect "reflect"
+)
+
+// MockUserRegistry is a mock of UserRegistry interface
+type MockUserRegistry struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserRegistryMockRecorder
+}
+
+// MockUserRegistryMockRecorder is the mock recorder for MockUserRegistry
+type MockUserRegistryMockRecorder struct {
+	mock *MockUserRegistry
+}
+
+// NewMockUserRegistry creates a new mock instance
+func NewMockUserRegistry(ctrl *gomock.Controller) *MockUserRegistry {
+	mock := &MockUserRegistry{ctrl: ctrl}
+	mock.recorder = &MockUserRegistryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockUserRegistry) EXPECT() *MockUserRegistryMockRecorder {
+	return m.recorder
+}
+
+// GetUser mocks base method
+func (m *MockUserRegistry) GetUser(email string) (*store.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", email)
+	ret0, _ := ret[0].(*store.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser
+func (mr *MockUserRegistryMockRecorder) GetUser(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockUserRegistry)(nil).GetUser), email)
+}
+
+// SetPasswordFor mocks base method
+func (m *MockUserRegistry) SetPasswordFor(email, hash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPasswordFor", email, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPasswordFor indicates an expected call of SetPasswordFor
+func (mr *MockUserRegistryMockRecorder) SetPasswordFor(email, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPasswordFor", reflect.TypeOf((*MockUserRegistry)(nil).SetPasswordFor), email, hash)
+}
+
+// SetEmailVerified mocks base method
+func (m *MockUserRegistry) SetEmailVerified(email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEmailVerified", email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetEmailVerified indicates an expected call of SetEmailVerified
+func (mr *MockUserRegistryMockRecorder) SetEmailVerified(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmailVerified", reflect.TypeOf((*MockUserRegistry)(nil).SetEmailVerified), email)
+}
+
+// MockTokenRegistry is a mock of TokenRegistry interface
+type MockTokenRegistry struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenRegistryMockRecorder
+}
+
+// MockTokenRegistryMockRecorder is the mock recorder for MockTokenRegistry
+type MockTokenRegistryMockRecorder struct {
+	mock *MockTokenRegistry
+}
+
+// NewMockTokenRegistry creates a new mock instance
+func NewMockTokenRegistry(ctrl *gomock.Controller) *MockTokenRegistry {
+	mock := &MockTokenRegistry{ctrl: ctrl}
+	mock.recorder = &MockTokenRegistryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTokenRegistry) EXPECT() *MockTokenRegistryMockRecorder {
+	return m.recorder
+}
+
+// AddToken mocks base method
+func (m *MockTokenRegistry) AddToken(token *store.Token) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddToken", token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddToken indicates an expected call of AddToken
+func (mr *MockTokenRegistryMockRecorder) AddToken(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddToken", reflect.TypeOf((*MockTokenRegistry)(nil).AddToken), token)
+}
+
+// ConsumeToken mocks base method
+func (m *MockTokenRegistry) ConsumeToken(tokenID string, purpose store.Purpose) (*store.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeToken", tokenID, purpose)
+	ret0, _ := ret[0].(*store.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeToken indicates an expected call of ConsumeToken
+func (mr *MockTokenRegistryMockRecorder) ConsumeToken(tokenID, purpose interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeToken", reflect.TypeOf((*MockTokenRegistry)(nil).ConsumeToken), tokenID, purpose)
+}
+
+// RemoveTokensFor mocks base method
+func (m *MockTokenRegistry) RemoveTokensFor(email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTokensFor", email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveTokensFor indicates an expected call of RemoveTokensFor
+func (mr *MockTokenRegistryMockRecorder) RemoveTokensFor(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTokensFor", reflect.TypeOf((*MockTokenRegistry)(nil).RemoveTokensFor), email)
+}
+
+// MockSessionRevoker is a mock of SessionRevoker interface
+type MockSessionRevoker struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionRevokerMockRecorder
+}
+
+// MockSessionRevokerMockRecorder is the mock recorder for MockSessionRevoker
+type MockSessionRevokerMockRecorder struct {
+	mock *MockSessionRevoker
+}
+
+// NewMockSessionRevoker creates a new mock instance
+func NewMockSessionRevoker(ctrl *gomock.Controller) *MockSessionRevoker {
+	mock := &MockSessionRevoker{ctrl: ctrl}
+	mock.recorder = &MockSessionRevokerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSessionRevoker) EXPECT() *MockSessionRevokerMockRecorder {
+	return m.recorder
+}
+
+// RevokeAll mocks base method
+func (m *MockSessionRevoker) RevokeAll(email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAll", email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAll indicates an expected call of RevokeAll
+func (mr *MockSessionRevokerMockRecorder) RevokeAll(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAll", reflect.TypeOf((*MockSessionRevoker)(nil).RevokeAll), email)
+}
+
+// MockMailSender is a mock of MailSender interface
+type MockMailSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockMailSenderMockRecorder
+}
+
+// MockMailSenderMockRecorder is the mock recorder for MockMailSender
+type MockMailSenderMockRecorder struct {
+	mock *MockMailSender
+}
+
+// NewMockMailSender creates a new mock instance
+func NewMockMailSender(ctrl *gomock.Controller) *MockMailSender {
+	mock := &MockMailSender{ctrl: ctrl}
+	mock.recorder = &MockMailSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockMailSender) EXPECT() *MockMailSenderMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method
+func (m *MockMailSender) Send(message *mail.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send
+func (mr *MockMailSenderMockRecorder) Send(message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockMailSender)(nil).Send), message)
+}
+
+// MockPasswordHasher is a mock of PasswordHasher interface
+type MockPasswordHasher struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordHasherMockRecorder
+}
+
+// MockPasswordHasherMockRecorder is the mock recorder for MockPasswordHasher
+type MockPasswordHasherMockRecorder struct {
+	mock *MockPasswordHasher
+}
+
+// NewMockPasswordHasher creates a new mock instance
+func NewMockPasswordHasher(ctrl *gomock.Controller) *MockPasswordHasher {
+	mock := &MockPasswordHasher{ctrl: ctrl}
+	mock.recorder = &MockPasswordHasherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPasswordHasher) EXPECT() *MockPasswordHasherMockRecorder {
+	return m.recorder
+}
+
+// Hash mocks base method
+func (m *MockPasswordHasher) Hash(password string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hash", password)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Hash indicates an expected call of Hash
+func (mr *MockPasswordHasherMockRecorder) Hash(password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hash", reflect.TypeOf((*MockPasswordHasher)(nil).Hash), password)
+}
+
+// Mocklogger is a mock of logger interface
+type Mocklogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockloggerMockRecorder
+}
+
+// MockloggerMockRecorder is the mock recorder for Mocklogger
+type MockloggerMockRecorder struct {
+	mock *Mocklogger
+}
+
+// NewMocklogger creates a new mock instance
+func NewMocklogger(ctrl *gomock.Controller) *Mocklogger {
+	mock := &Mocklogger{ctrl: ctrl}
+	mock.recorder = &MockloggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *Mocklogger) EXPECT() *MockloggerMockRecorder {
+	return m.recorder
+}
+
+// Infow mocks base method
+func (m *Mocklogger) Infow(msg string, keysAndValues ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range keysAndValues {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Infow", varargs...)
+}
+
+// Infow indicates an expected call of Infow
+func (mr *MockloggerMockRecorder) Infow(msg interface{}, keysAndValues ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, keysAndValues...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Infow", reflect.TypeOf((*Mocklogger)(nil).Infow), varargs...)
+}
@@ -0,0 +1,297 @@
+package verification
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mimatache/go-shop/internal/mail"
+	"github.com/mimatache/go-shop/internal/store"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+)
+
+//go:generate mockgen -source ./verification.go -destination mocks/verification.go
+
+const (
+	tokenBytes = 32
+	// maxPending bounds the requests handled in the background at once, so that a flood of requests cannot start
+	// an unbounded amount of work
+	maxPending = 32
+)
+
+// UserRegistry gives access to the users the tokens are sent to
+type UserRegistry interface {
+	GetUser(email string) (*userStore.User, error)
+	SetPasswordFor(email string, hash string) error
+	SetEmailVerified(email string) error
+}
+
+// TokenRegistry stores the tokens sent to the users
+type TokenRegistry interface {
+	AddToken(token *userStore.Token) error
+	ConsumeToken(tokenID string, purpose userStore.Purpose) (*userStore.Token, error)
+	RemoveTokensFor(email string) error
+}
+
+// SessionRevoker ends the sessions of a user whose password was reset
+type SessionRevoker interface {
+	RevokeAll(email string) error
+}
+
+// MailSender delivers the emails carrying the tokens
+type MailSender interface {
+	Send(message *mail.Message) error
+}
+
+// PasswordHasher hashes the new passwords
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+}
+
+type logger interface {
+	Infow(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+}
+
+type invalidToken struct{}
+
+func (i invalidToken) Error() string {
+	return "the token is invalid, expired or was already used"
+}
+
+// IsInvalidTokenError verifies if a given error refers to a token that cannot be used
+func IsInvalidTokenError(err error) bool {
+	switch err.(type) {
+	case invalidToken:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewInvalidToken creates a new invalid token error
+func NewInvalidToken() error {
+	return invalidToken{}
+}
+
+// Config sets how long the tokens can be used and where the links sent to the users point to
+type Config struct {
+	// PublicURL is the public address of the shop, used in the links sent to the users
+	PublicURL            string
+	ResetLifetime        time.Duration
+	VerificationLifetime time.Duration
+}
+
+// New creates a new Verifier
+func New(
+	log logger, users UserRegistry, tokens TokenRegistry, sessions SessionRevoker, hasher PasswordHasher, sender MailSender, config Config,
+) *Verifier {
+	return &Verifier{
+		log:      log,
+		users:    users,
+		tokens:   tokens,
+		sessions: sessions,
+		hasher:   hasher,
+		sender:   sender,
+		config:   config,
+		now:      time.Now,
+		pending:  make(chan struct{}, maxPending),
+	}
+}
+
+// Verifier sends single use, time limited tokens by email, to let the users reset their password and verify their
+// email address. Requests for unknown email addresses are ignored without telling, so that they cannot be used
+// to find out which addresses have an account. The tokens are issued and sent in the background, so that answering
+// a request takes as long whether the address has an account or not
+type Verifier struct {
+	log      logger
+	users    UserRegistry
+	tokens   TokenRegistry
+	sessions SessionRevoker
+	hasher   PasswordHasher
+	sender   MailSender
+	config   Config
+	now      func() time.Time
+	// pending holds a slot for every request handled in the background
+	pending chan struct{}
+	wg      sync.WaitGroup
+}
+
+// RequestPasswordReset emails a password reset token to the user with the given email address, if there is one
+func (v *Verifier) RequestPasswordReset(email string) error {
+	return v.background(email, v.sendPasswordReset)
+}
+
+// RequestVerification emails a verification token to the user with the given email address,
+// if there is one and the address is not verified yet
+func (v *Verifier) RequestVerification(email string) error {
+	return v.background(email, v.sendVerification)
+}
+
+// Wait blocks until the requests handled in the background are done
+func (v *Verifier) Wait() {
+	v.wg.Wait()
+}
+
+// background handles the request for the email address in a go routine. Requests beyond maxPending are dropped
+func (v *Verifier) background(email string, send func(email string) error) error {
+	email = userStore.NormalizeEmail(email)
+	select {
+	case v.pending <- struct{}{}:
+	default:
+		return fmt.Errorf("too many pending requests, dropped the one for %s", email)
+	}
+	v.wg.Add(1)
+	go func() {
+		defer v.wg.Done()
+		defer func() { <-v.pending }()
+		if err := send(email); err != nil {
+			v.log.Errorw("could not send token", "email", email, "error", err)
+		}
+	}()
+	return nil
+}
+
+func (v *Verifier) sendPasswordReset(email string) error {
+	user, err := v.users.GetUser(email)
+	if err != nil {
+		if store.IsNotFoundError(err) {
+			v.log.Infow("password reset requested for an unknown email address", "email", email)
+			return nil
+		}
+		return err
+	}
+	token, err := v.issue(user, userStore.PasswordReset, v.config.ResetLifetime)
+	if err != nil {
+		return err
+	}
+	return v.sender.Send(&mail.Message{
+		To:      string(user.Email),
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nSomeone asked to reset the password of your account. To choose a new password, send it together with "+
+				"this token to %s/api/v1/password-reset/confirm:\n\n%s\n\nThe token can be used once, within %s. "+
+				"If you did not ask for it, you can ignore this email.\n",
+			user.Name, v.config.PublicURL, token, v.config.ResetLifetime,
+		),
+	})
+}
+
+// ResetPassword replaces the password of the user the token was sent to. Using the token proves owning the email
+// address, so the address is verified as well, and every other token sent to the user is revoked. Whoever knew the old
+// password may be logged in, so every session of the user is ended
+func (v *Verifier) ResetPassword(token string, password string) error {
+	if password == "" {
+		return userStore.NewInvalidUser(fmt.Errorf("password is mandatory"))
+	}
+	consumed, err := v.consume(token, userStore.PasswordReset)
+	if err != nil {
+		return err
+	}
+	hash, err := v.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+	if err := v.users.SetPasswordFor(consumed.Email, hash); err != nil {
+		if store.IsNotFoundError(err) {
+			return NewInvalidToken()
+		}
+		return err
+	}
+	if err := v.users.SetEmailVerified(consumed.Email); err != nil {
+		return err
+	}
+	v.log.Infow("password reset", "email", consumed.Email)
+	if err := v.tokens.RemoveTokensFor(consumed.Email); err != nil {
+		return err
+	}
+	return v.sessions.RevokeAll(consumed.Email)
+}
+
+func (v *Verifier) sendVerification(email string) error {
+	user, err := v.users.GetUser(email)
+	if err != nil {
+		if store.IsNotFoundError(err) {
+			v.log.Infow("email verification requested for an unknown email address", "email", email)
+			return nil
+		}
+		return err
+	}
+	if user.EmailVerified {
+		return nil
+	}
+	token, err := v.issue(user, userStore.EmailVerification, v.config.VerificationLifetime)
+	if err != nil {
+		return err
+	}
+	return v.sender.Send(&mail.Message{
+		To:      string(user.Email),
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nPlease confirm your email address by opening %s/api/v1/users/verify?token=%s within %s.\n",
+			user.Name, v.config.PublicURL, token, v.config.VerificationLifetime,
+		),
+	})
+}
+
+// VerifyEmail marks the email address the token was sent to as verified
+func (v *Verifier) VerifyEmail(token string) error {
+	consumed, err := v.consume(token, userStore.EmailVerification)
+	if err != nil {
+		return err
+	}
+	if err := v.users.SetEmailVerified(consumed.Email); err != nil {
+		if store.IsNotFoundError(err) {
+			return NewInvalidToken()
+		}
+		return err
+	}
+	v.log.Infow("email verified", "email", consumed.Email)
+	return nil
+}
+
+// issue stores a new token for the user and returns it. Only the hash of the token is stored
+func (v *Verifier) issue(user *userStore.User, purpose userStore.Purpose, lifetime time.Duration) (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	err := v.tokens.AddToken(&userStore.Token{
+		ID:        hashToken(token),
+		Purpose:   purpose,
+		Email:     string(user.Email),
+		ExpiresAt: v.now().Add(lifetime),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// consume uses up the token, which has to be issued for the purpose and not expired
+func (v *Verifier) consume(token string, purpose userStore.Purpose) (*userStore.Token, error) {
+	if token == "" {
+		return nil, NewInvalidToken()
+	}
+	consumed, err := v.tokens.ConsumeToken(hashToken(token), purpose)
+	if err != nil {
+		if store.IsNotFoundError(err) {
+			return nil, NewInvalidToken()
+		}
+		return nil, err
+	}
+	if !v.now().Before(consumed.ExpiresAt) {
+		return nil, NewInvalidToken()
+	}
+	return consumed, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,232 @@
+package verification_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/mail"
+	"github.com/mimatache/go-shop/internal/store"
+	userStore "github.com/mimatache/go-shop/pkg/users/store"
+	"github.com/mimatache/go-shop/pkg/users/verification"
+	mock_verification "github.com/mimatache/go-shop/pkg/users/verification/mocks"
+)
+
+const (
+	userEmail = "user@email.com"
+	newHash   = "$argon2id$new"
+)
+
+var (
+	user = &userStore.User{ID: 1, Name: "user", Email: userEmail, Password: "$argon2id$old"}
+
+	// tokenPattern matches the 32 random bytes of a token, encoded as unpadded base64
+	tokenPattern = regexp.MustCompile(`[A-Za-z0-9_-]{43}`)
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Infow(msg string, keysAndValues ...interface{}) {}
+
+func (nopLogger) Errorw(msg string, keysAndValues ...interface{}) {}
+
+type mocks struct {
+	users    *mock_verification.MockUserRegistry
+	tokens   *mock_verification.MockTokenRegistry
+	sessions *mock_verification.MockSessionRevoker
+	hasher   *mock_verification.MockPasswordHasher
+	sender   *mock_verification.MockMailSender
+}
+
+func newVerifier(t *testing.T) (*verification.Verifier, *mocks, func()) {
+	ctrl := gomock.NewController(t)
+	m := &mocks{
+		users:    mock_verification.NewMockUserRegistry(ctrl),
+		tokens:   mock_verification.NewMockTokenRegistry(ctrl),
+		sessions: mock_verification.NewMockSessionRevoker(ctrl),
+		hasher:   mock_verification.NewMockPasswordHasher(ctrl),
+		sender:   mock_verification.NewMockMailSender(ctrl),
+	}
+	config := verification.Config{PublicURL: "https://shop.com", ResetLifetime: time.Hour, VerificationLifetime: 48 * time.Hour}
+	return verification.New(nopLogger{}, m.users, m.tokens, m.sessions, m.hasher, m.sender, config), m, ctrl.Finish
+}
+
+func hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestVerifier_ResetPassword(t *testing.T) {
+	g := NewWithT(t)
+
+	verifier, m, finish := newVerifier(t)
+	defer finish()
+
+	var stored *userStore.Token
+	var token string
+	m.users.EXPECT().GetUser(userEmail).Return(user, nil)
+	m.tokens.EXPECT().AddToken(gomock.Any()).DoAndReturn(func(t *userStore.Token) error {
+		stored = t
+		return nil
+	})
+	m.sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(message *mail.Message) error {
+		g.Expect(message.To).To(Equal(userEmail))
+		g.Expect(message.Body).To(ContainSubstring("https://shop.com/api/v1/password-reset/confirm"))
+		token = tokenPattern.FindString(message.Body)
+		return nil
+	})
+
+	g.Expect(verifier.RequestPasswordReset(" "+strings.ToUpper(userEmail))).To(Succeed(), "the address is looked up normalized")
+	verifier.Wait()
+	g.Expect(token).ShouldNot(BeEmpty())
+	g.Expect(stored.ID).To(Equal(hash(token)), "only the hash of the token is stored")
+	g.Expect(stored.Purpose).To(Equal(userStore.PasswordReset))
+	g.Expect(stored.ExpiresAt).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+
+	m.tokens.EXPECT().ConsumeToken(hash(token), userStore.PasswordReset).Return(stored, nil)
+	m.hasher.EXPECT().Hash("new password").Return(newHash, nil)
+	m.users.EXPECT().SetPasswordFor(userEmail, newHash).Return(nil)
+	m.users.EXPECT().SetEmailVerified(userEmail).Return(nil)
+	m.tokens.EXPECT().RemoveTokensFor(userEmail).Return(nil)
+	m.sessions.EXPECT().RevokeAll(userEmail).Return(nil)
+
+	g.Expect(verifier.ResetPassword(token, "new password")).To(Succeed())
+}
+
+func TestVerifier_RequestPasswordReset_UnknownEmail(t *testing.T) {
+	g := NewWithT(t)
+
+	verifier, m, finish := newVerifier(t)
+	defer finish()
+
+	m.users.EXPECT().GetUser("unknown@email.com").Return(nil, store.NewNotFoundError("user", "email", "unknown@email.com"))
+
+	g.Expect(verifier.RequestPasswordReset("unknown@email.com")).To(Succeed(), "unknown addresses are not disclosed")
+	verifier.Wait()
+}
+
+func TestVerifier_RequestPasswordReset_Background(t *testing.T) {
+	g := NewWithT(t)
+
+	verifier, m, finish := newVerifier(t)
+	defer finish()
+
+	release := make(chan struct{})
+	m.users.EXPECT().GetUser(userEmail).Return(user, nil)
+	m.tokens.EXPECT().AddToken(gomock.Any()).Return(nil)
+	m.sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(message *mail.Message) error {
+		<-release
+		return nil
+	})
+
+	g.Expect(verifier.RequestPasswordReset(userEmail)).To(Succeed(), "the request does not wait for the email to be sent")
+	close(release)
+	verifier.Wait()
+}
+
+func TestVerifier_ResetPassword_InvalidToken(t *testing.T) {
+	tokens := map[string]func(m *mocks){
+		"unknown": func(m *mocks) {
+			m.tokens.EXPECT().ConsumeToken(hash("token"), userStore.PasswordReset).Return(nil, store.NewNotFoundError("userToken", "id", "token"))
+		},
+		"expired": func(m *mocks) {
+			m.tokens.EXPECT().ConsumeToken(hash("token"), userStore.PasswordReset).Return(&userStore.Token{
+				ID:        hash("token"),
+				Purpose:   userStore.PasswordReset,
+				Email:     userEmail,
+				ExpiresAt: time.Now().Add(-time.Minute),
+			}, nil)
+		},
+		"user removed": func(m *mocks) {
+			m.tokens.EXPECT().ConsumeToken(hash("token"), userStore.PasswordReset).Return(&userStore.Token{
+				ID:        hash("token"),
+				Purpose:   userStore.PasswordReset,
+				Email:     userEmail,
+				ExpiresAt: time.Now().Add(time.Minute),
+			}, nil)
+			m.hasher.EXPECT().Hash("new password").Return(newHash, nil)
+			m.users.EXPECT().SetPasswordFor(userEmail, newHash).Return(store.NewNotFoundError("user", "email", userEmail))
+		},
+	}
+	for name, expect := range tokens {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			verifier, m, finish := newVerifier(t)
+			defer finish()
+			expect(m)
+
+			err := verifier.ResetPassword("token", "new password")
+
+			g.Expect(verification.IsInvalidTokenError(err)).To(BeTrue(), "%v", err)
+		})
+	}
+}
+
+func TestVerifier_ResetPassword_EmptyPassword(t *testing.T) {
+	g := NewWithT(t)
+
+	verifier, _, finish := newVerifier(t)
+	defer finish()
+
+	err := verifier.ResetPassword("token", "")
+
+	g.Expect(userStore.IsInvalidUserError(err)).To(BeTrue(), "the token is not used up by an invalid password")
+}
+
+func TestVerifier_VerifyEmail(t *testing.T) {
+	g := NewWithT(t)
+
+	verifier, m, finish := newVerifier(t)
+	defer finish()
+
+	var stored *userStore.Token
+	var token string
+	m.users.EXPECT().GetUser(userEmail).Return(user, nil)
+	m.tokens.EXPECT().AddToken(gomock.Any()).DoAndReturn(func(t *userStore.Token) error {
+		stored = t
+		return nil
+	})
+	m.sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(message *mail.Message) error {
+		g.Expect(message.Body).To(ContainSubstring("https://shop.com/api/v1/users/verify?token="))
+		token = tokenPattern.FindString(message.Body)
+		return nil
+	})
+
+	g.Expect(verifier.RequestVerification(userEmail)).To(Succeed())
+	verifier.Wait()
+	g.Expect(stored.Purpose).To(Equal(userStore.EmailVerification))
+	g.Expect(stored.ExpiresAt).To(BeTemporally("~", time.Now().Add(48*time.Hour), time.Minute))
+
+	m.tokens.EXPECT().ConsumeToken(hash(token), userStore.EmailVerification).Return(stored, nil)
+	m.users.EXPECT().SetEmailVerified(userEmail).Return(nil)
+
+	g.Expect(verifier.VerifyEmail(token)).To(Succeed())
+}
+
+func TestVerifier_RequestVerification_AlreadyVerified(t *testing.T) {
+	g := NewWithT(t)
+
+	verifier, m, finish := newVerifier(t)
+	defer finish()
+
+	verified := *user
+	verified.EmailVerified = true
+	m.users.EXPECT().GetUser(userEmail).Return(&verified, nil)
+
+	g.Expect(verifier.RequestVerification(userEmail)).To(Succeed())
+	verifier.Wait()
+}
+
+func TestInvalidToken(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(verification.IsInvalidTokenError(store.NewNotFoundError("userToken", "id", "token"))).To(BeFalse())
+	g.Expect(verification.IsInvalidTokenError(verification.NewInvalidToken())).To(BeTrue())
+}
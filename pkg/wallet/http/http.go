@@ -0,0 +1,122 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mimatache/go-shop/internal/http/authorization"
+	"github.com/mimatache/go-shop/internal/http/helpers"
+	"github.com/mimatache/go-shop/internal/rbac"
+	"github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/wallet/wallet"
+)
+
+type giftCardCode struct {
+	Code string `json:"code"`
+}
+
+type giftCardIssue struct {
+	Amount    uint      `json:"amount"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func New(wallet *wallet.Wallet) *WalletAPI {
+	return &WalletAPI{
+		wallet: wallet,
+	}
+}
+
+type WalletAPI struct {
+	wallet *wallet.Wallet
+}
+
+func (wa *WalletAPI) getStatement(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	statement, err := wa.wallet.GetStatement(userID)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, statement, http.StatusOK)
+}
+
+func (wa *WalletAPI) redeemGiftCard(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var request giftCardCode
+	if !decode(w, r, &request) {
+		return
+	}
+	statement, err := wa.wallet.RedeemGiftCard(userID, request.Code)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, statement, http.StatusOK)
+}
+
+func (wa *WalletAPI) issueGiftCard(w http.ResponseWriter, r *http.Request) {
+	var request giftCardIssue
+	if !decode(w, r, &request) {
+		return
+	}
+	giftCard, err := wa.wallet.IssueGiftCard(request.Amount, request.ExpiresAt)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, giftCard, http.StatusCreated)
+}
+
+func (wa *WalletAPI) getGiftCard(w http.ResponseWriter, r *http.Request) {
+	giftCard, err := wa.wallet.GetGiftCard(mux.Vars(r)["code"])
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, giftCard, http.StatusOK)
+}
+
+func decode(w http.ResponseWriter, r *http.Request, value interface{}) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(value)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func formatError(w http.ResponseWriter, err error) {
+	switch {
+	case store.IsNotFoundError(err):
+		helpers.FormatError(w, err.Error(), http.StatusNotFound)
+	case wallet.IsInvalidTenderError(err):
+		helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+	default:
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// AddRoutes registers the API routes to a router.
+// Users can see their wallet and redeem gift cards into it, while gift cards are issued by administrators
+func (wa *WalletAPI) AddRoutes(router *mux.Router, userHandler func(http.Handler) http.Handler, adminHandler func(permission rbac.Permission) func(http.Handler) http.Handler) {
+	walletRouter := router.PathPrefix("/wallet").Subrouter()
+	walletRouter.Handle("", userHandler(http.HandlerFunc(wa.getStatement))).Methods(http.MethodGet)
+	walletRouter.Handle("/gift-cards", userHandler(http.HandlerFunc(wa.redeemGiftCard))).Methods(http.MethodPost)
+
+	adminRouter := router.PathPrefix("/admin/gift-cards").Subrouter()
+	adminRouter.Handle("", adminHandler(rbac.ManageGiftCards)(http.HandlerFunc(wa.issueGiftCard))).Methods(http.MethodPost)
+	adminRouter.Handle("/{code}", adminHandler(rbac.ManageGiftCards)(http.HandlerFunc(wa.getGiftCard))).Methods(http.MethodGet)
+}
@@ -0,0 +1,259 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./store.go
+
+// Package mock_store is a generated GoMock package.
+package mock_store
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	store "github.com/mimatache/go-shop/pkg/wallet/store"
+	reflect "reflect"
+)
+
+// Mocklogger is a mock of logger interface
+type Mocklogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockloggerMockRecorder
+}
+
+// MockloggerMockRecorder is the mock recorder for Mocklogger
+type MockloggerMockRecorder struct {
+	mock *Mocklogger
+}
+
+// NewMocklogger creates a new mock instance
+func NewMocklogger(ctrl *gomock.Controller) *Mocklogger {
+	mock := &Mocklogger{ctrl: ctrl}
+	mock.recorder = &MockloggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *Mocklogger) EXPECT() *MockloggerMockRecorder {
+	return m.recorder
+}
+
+// Debugf mocks base method
+func (m *Mocklogger) Debugf(msg string, args ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Debugf", varargs...)
+}
+
+// Debugf indicates an expected call of Debugf
+func (mr *MockloggerMockRecorder) Debugf(msg interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Debugf", reflect.TypeOf((*Mocklogger)(nil).Debugf), varargs...)
+}
+
+// Debugw mocks base method
+func (m *Mocklogger) Debugw(msg string, keysAndValues ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range keysAndValues {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Debugw", varargs...)
+}
+
+// Debugw indicates an expected call of Debugw
+func (mr *MockloggerMockRecorder) Debugw(msg interface{}, keysAndValues ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, keysAndValues...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Debugw", reflect.TypeOf((*Mocklogger)(nil).Debugw), varargs...)
+}
+
+// MockUnderlyingStore is a mock of UnderlyingStore interface
+type MockUnderlyingStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockUnderlyingStoreMockRecorder
+}
+
+// MockUnderlyingStoreMockRecorder is the mock recorder for MockUnderlyingStore
+type MockUnderlyingStoreMockRecorder struct {
+	mock *MockUnderlyingStore
+}
+
+// NewMockUnderlyingStore creates a new mock instance
+func NewMockUnderlyingStore(ctrl *gomock.Controller) *MockUnderlyingStore {
+	mock := &MockUnderlyingStore{ctrl: ctrl}
+	mock.recorder = &MockUnderlyingStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockUnderlyingStore) EXPECT() *MockUnderlyingStoreMockRecorder {
+	return m.recorder
+}
+
+// Read mocks base method
+func (m *MockUnderlyingStore) Read(table, key string, value interface{}) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", table, key, value)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read
+func (mr *MockUnderlyingStoreMockRecorder) Read(table, key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockUnderlyingStore)(nil).Read), table, key, value)
+}
+
+// ReadAll mocks base method
+func (m *MockUnderlyingStore) ReadAll(table, key string, args ...interface{}) ([]interface{}, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{table, key}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReadAll", varargs...)
+	ret0, _ := ret[0].([]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAll indicates an expected call of ReadAll
+func (mr *MockUnderlyingStoreMockRecorder) ReadAll(table, key interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{table, key}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAll", reflect.TypeOf((*MockUnderlyingStore)(nil).ReadAll), varargs...)
+}
+
+// Write mocks base method
+func (m *MockUnderlyingStore) Write(table string, objs ...interface{}) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{table}
+	for _, a := range objs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Write", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Write indicates an expected call of Write
+func (mr *MockUnderlyingStoreMockRecorder) Write(table interface{}, objs ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{table}, objs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockUnderlyingStore)(nil).Write), varargs...)
+}
+
+// MockWalletStore is a mock of WalletStore interface
+type MockWalletStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockWalletStoreMockRecorder
+}
+
+// MockWalletStoreMockRecorder is the mock recorder for MockWalletStore
+type MockWalletStoreMockRecorder struct {
+	mock *MockWalletStore
+}
+
+// NewMockWalletStore creates a new mock instance
+func NewMockWalletStore(ctrl *gomock.Controller) *MockWalletStore {
+	mock := &MockWalletStore{ctrl: ctrl}
+	mock.recorder = &MockWalletStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWalletStore) EXPECT() *MockWalletStoreMockRecorder {
+	return m.recorder
+}
+
+// AddEntries mocks base method
+func (m *MockWalletStore) AddEntries(entries ...*store.Entry) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range entries {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "AddEntries", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddEntries indicates an expected call of AddEntries
+func (mr *MockWalletStoreMockRecorder) AddEntries(entries ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddEntries", reflect.TypeOf((*MockWalletStore)(nil).AddEntries), entries...)
+}
+
+// GetEntriesForUser mocks base method
+func (m *MockWalletStore) GetEntriesForUser(userID string) ([]*store.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntriesForUser", userID)
+	ret0, _ := ret[0].([]*store.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntriesForUser indicates an expected call of GetEntriesForUser
+func (mr *MockWalletStoreMockRecorder) GetEntriesForUser(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntriesForUser", reflect.TypeOf((*MockWalletStore)(nil).GetEntriesForUser), userID)
+}
+
+// SetGiftCard mocks base method
+func (m *MockWalletStore) SetGiftCard(giftCard *store.GiftCard) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetGiftCard", giftCard)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetGiftCard indicates an expected call of SetGiftCard
+func (mr *MockWalletStoreMockRecorder) SetGiftCard(giftCard interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetGiftCard", reflect.TypeOf((*MockWalletStore)(nil).SetGiftCard), giftCard)
+}
+
+// GetGiftCard mocks base method
+func (m *MockWalletStore) GetGiftCard(code string) (*store.GiftCard, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGiftCard", code)
+	ret0, _ := ret[0].(*store.GiftCard)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGiftCard indicates an expected call of GetGiftCard
+func (mr *MockWalletStoreMockRecorder) GetGiftCard(code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGiftCard", reflect.TypeOf((*MockWalletStore)(nil).GetGiftCard), code)
+}
+
+// SetTender mocks base method
+func (m *MockWalletStore) SetTender(tender *store.Tender) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTender", tender)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTender indicates an expected call of SetTender
+func (mr *MockWalletStoreMockRecorder) SetTender(tender interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTender", reflect.TypeOf((*MockWalletStore)(nil).SetTender), tender)
+}
+
+// GetTender mocks base method
+func (m *MockWalletStore) GetTender(tenderID string) (*store.Tender, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTender", tenderID)
+	ret0, _ := ret[0].(*store.Tender)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTender indicates an expected call of GetTender
+func (mr *MockWalletStoreMockRecorder) GetTender(tenderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTender", reflect.TypeOf((*MockWalletStore)(nil).GetTender), tenderID)
+}
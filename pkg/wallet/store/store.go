@@ -0,0 +1,343 @@
+package store
+
+import (
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/mimatache/go-shop/internal/store"
+)
+
+//go:generate mockgen -source ./store.go -destination mocks/store.go
+
+type logger interface {
+	Debugf(msg string, args ...interface{})
+	Debugw(msg string, keysAndValues ...interface{})
+}
+
+const (
+	id   = "id"
+	user = "user"
+)
+
+var (
+	entryTable    = &EntryTable{name: "walletEntry"}
+	giftCardTable = &GiftCardTable{name: "giftCard"}
+	tenderTable   = &TenderTable{name: "tender"}
+)
+
+// GetTable returns the wallet entry table
+func GetTable() *EntryTable {
+	return entryTable
+}
+
+// GetGiftCardTable returns the gift card table
+func GetGiftCardTable() *GiftCardTable {
+	return giftCardTable
+}
+
+// GetTenderTable returns the tender table
+func GetTenderTable() *TenderTable {
+	return tenderTable
+}
+
+// EntryTable the schema of the wallet entry table
+type EntryTable struct {
+	name string
+}
+
+// GetName returns the name of the wallet entry table
+func (e *EntryTable) GetName() string {
+	return e.name
+}
+
+// GetTableSchema returns the schema of the wallet entry table
+func (e *EntryTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: e.name,
+		Indexes: map[string]*memdb.IndexSchema{
+			id: {
+				Name:    id,
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "ID"},
+			},
+			user: {
+				Name:    user,
+				Unique:  false,
+				Indexer: &memdb.StringFieldIndex{Field: "UserID"},
+			},
+		},
+	}
+}
+
+// GetPersonalRows returns the wallet entries of the user
+func (e *EntryTable) GetPersonalRows(db store.Reader, userID string) ([]interface{}, error) {
+	return db.ReadAll(e.name, user, userID)
+}
+
+// Export returns the wallet entry
+func (e *EntryTable) Export(row interface{}) interface{} {
+	return row
+}
+
+// Anonymize keeps the wallet entry for the pseudonym, since it is needed for the accounts of the shop
+func (e *EntryTable) Anonymize(row interface{}, userID string, pseudonym string) (interface{}, error) {
+	anonymized := *row.(*Entry)
+	anonymized.UserID = pseudonym
+	return &anonymized, nil
+}
+
+// GiftCardTable the schema of the gift card table
+type GiftCardTable struct {
+	name string
+}
+
+// GetName returns the name of the gift card table
+func (g *GiftCardTable) GetName() string {
+	return g.name
+}
+
+// GetTableSchema returns the schema of the gift card table
+func (g *GiftCardTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: g.name,
+		Indexes: map[string]*memdb.IndexSchema{
+			id: {
+				Name:    id,
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "Code"},
+			},
+		},
+	}
+}
+
+// TenderTable the schema of the tender table
+type TenderTable struct {
+	name string
+}
+
+// GetName returns the name of the tender table
+func (t *TenderTable) GetName() string {
+	return t.name
+}
+
+// GetTableSchema returns the schema of the tender table
+func (t *TenderTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: t.name,
+		Indexes: map[string]*memdb.IndexSchema{
+			id: {
+				Name:    id,
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "ID"},
+			},
+		},
+	}
+}
+
+// GetPersonalRows returns the tenders of the user
+func (t *TenderTable) GetPersonalRows(db store.Reader, userID string) ([]interface{}, error) {
+	rows, err := db.ReadAll(t.name, id+"_prefix", "")
+	if err != nil {
+		return nil, err
+	}
+	tenders := []interface{}{}
+	for _, row := range rows {
+		if row.(*Tender).UserID == userID {
+			tenders = append(tenders, row)
+		}
+	}
+	return tenders, nil
+}
+
+// Export returns the tender
+func (t *TenderTable) Export(row interface{}) interface{} {
+	return row
+}
+
+// Anonymize keeps the tender for the pseudonym, since it is needed for the accounts of the shop
+func (t *TenderTable) Anonymize(row interface{}, userID string, pseudonym string) (interface{}, error) {
+	anonymized := *row.(*Tender)
+	anonymized.UserID = pseudonym
+	return &anonymized, nil
+}
+
+// UnderlyingStore represents the interface that the DB should implement to be usable
+type UnderlyingStore interface {
+	Read(table string, key string, value interface{}) (interface{}, error)
+	ReadAll(table string, key string, args ...interface{}) ([]interface{}, error)
+	Write(table string, objs ...interface{}) error
+}
+
+// WalletStore represents the wallet store. Wallet entries can only be added
+type WalletStore interface {
+	AddEntries(entries ...*Entry) error
+	GetEntriesForUser(userID string) ([]*Entry, error)
+	SetGiftCard(giftCard *GiftCard) error
+	GetGiftCard(code string) (*GiftCard, error)
+	SetTender(tender *Tender) error
+	GetTender(tenderID string) (*Tender, error)
+}
+
+// New start a new instance of the wallet store
+func New(log logger, db UnderlyingStore) WalletStore {
+	return &walletLogger{
+		log:  log,
+		next: &walletStore{db: db},
+	}
+}
+
+type walletStore struct {
+	db UnderlyingStore
+}
+
+// AddEntries appends entries to the wallets
+func (w *walletStore) AddEntries(entries ...*Entry) error {
+	objs := make([]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		if err := entry.Validate(); err != nil {
+			return err
+		}
+		objs = append(objs, entry)
+	}
+	return w.db.Write(entryTable.GetName(), objs...)
+}
+
+// GetEntriesForUser returns all the entries of the wallet of a user
+func (w *walletStore) GetEntriesForUser(userID string) ([]*Entry, error) {
+	rows, err := w.db.ReadAll(entryTable.GetName(), user, userID)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*Entry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, row.(*Entry))
+	}
+	return entries, nil
+}
+
+// SetGiftCard creates or replaces a gift card
+func (w *walletStore) SetGiftCard(giftCard *GiftCard) error {
+	if err := giftCard.Validate(); err != nil {
+		return err
+	}
+	return w.db.Write(giftCardTable.GetName(), giftCard)
+}
+
+// GetGiftCard returns the gift card with the given code
+func (w *walletStore) GetGiftCard(code string) (*GiftCard, error) {
+	item, err := w.db.Read(giftCardTable.GetName(), id, code)
+	if err != nil {
+		return nil, err
+	}
+	return item.(*GiftCard), nil
+}
+
+// SetTender creates or replaces a tender
+func (w *walletStore) SetTender(tender *Tender) error {
+	if err := tender.Validate(); err != nil {
+		return err
+	}
+	return w.db.Write(tenderTable.GetName(), tender)
+}
+
+// GetTender returns the tender with the given ID
+func (w *walletStore) GetTender(tenderID string) (*Tender, error) {
+	item, err := w.db.Read(tenderTable.GetName(), id, tenderID)
+	if err != nil {
+		return nil, err
+	}
+	return item.(*Tender), nil
+}
+
+type walletLogger struct {
+	log  logger
+	next WalletStore
+}
+
+func (w *walletLogger) AddEntries(entries ...*Entry) error {
+	var err error
+	defer func() {
+		if err != nil {
+			w.log.Debugf("could not add %d wallet entries err: %s", len(entries), err.Error())
+			return
+		}
+		for _, entry := range entries {
+			w.log.Debugw("added wallet entry", "id", entry.ID, "user", entry.UserID, "type", entry.Type, "amount", entry.Amount)
+		}
+	}()
+
+	err = w.next.AddEntries(entries...)
+	return err
+}
+
+func (w *walletLogger) GetEntriesForUser(userID string) ([]*Entry, error) {
+	var err error
+	var entries []*Entry
+	defer func() {
+		if err != nil {
+			w.log.Debugf("could not retrieve the wallet entries of user %s err: %s", userID, err.Error())
+			return
+		}
+		w.log.Debugf("retrieved %d wallet entries of user %s", len(entries), userID)
+	}()
+
+	entries, err = w.next.GetEntriesForUser(userID)
+	return entries, err
+}
+
+func (w *walletLogger) SetGiftCard(giftCard *GiftCard) error {
+	var err error
+	defer func() {
+		if err != nil {
+			w.log.Debugf("could not store gift card err: %s", err.Error())
+			return
+		}
+		w.log.Debugw("stored gift card", "balance", giftCard.Balance, "expiresAt", giftCard.ExpiresAt)
+	}()
+
+	err = w.next.SetGiftCard(giftCard)
+	return err
+}
+
+func (w *walletLogger) GetGiftCard(code string) (*GiftCard, error) {
+	var err error
+	var giftCard *GiftCard
+	defer func() {
+		if err != nil {
+			w.log.Debugf("could not retrieve gift card err: %s", err.Error())
+			return
+		}
+		w.log.Debugf("retrieved gift card")
+	}()
+
+	giftCard, err = w.next.GetGiftCard(code)
+	return giftCard, err
+}
+
+func (w *walletLogger) SetTender(tender *Tender) error {
+	var err error
+	defer func() {
+		if err != nil {
+			w.log.Debugf("could not store tender %s err: %s", tender.ID, err.Error())
+			return
+		}
+		w.log.Debugw("stored tender", "id", tender.ID, "user", tender.UserID, "total", tender.Total, "reversed", tender.Reversed)
+	}()
+
+	err = w.next.SetTender(tender)
+	return err
+}
+
+func (w *walletLogger) GetTender(tenderID string) (*Tender, error) {
+	var err error
+	var tender *Tender
+	defer func() {
+		if err != nil {
+			w.log.Debugf("could not retrieve tender %s err: %s", tenderID, err.Error())
+			return
+		}
+		w.log.Debugf("retrieved tender %s", tenderID)
+	}()
+
+	tender, err = w.next.GetTender(tenderID)
+	return tender, err
+}
@@ -0,0 +1,154 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+type errors []error
+
+func (e errors) Error() string {
+	b := bytes.NewBufferString("")
+	for _, err := range e {
+		_, _ = fmt.Fprintf(b, "\t%s\n", err)
+	}
+	return b.String()
+}
+
+// EntryType explains why the balance of a wallet changed
+type EntryType string
+
+const (
+	// GiftCardRedemption the balance of a gift card was moved into the wallet
+	GiftCardRedemption EntryType = "gift_card"
+	// Purchase store credit was spent at checkout
+	Purchase EntryType = "purchase"
+	// Reversal store credit spent at a checkout that failed was given back
+	Reversal EntryType = "reversal"
+	// Refund money paid for an order was given back as store credit
+	Refund EntryType = "refund"
+)
+
+// Entry is a change of the balance of a wallet. Entries are never changed or removed, the balance of a wallet
+// is the sum of its entries
+type Entry struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"-"`
+	Type      EntryType `json:"type"`
+	Amount    int       `json:"amount"`
+	Reference string    `json:"reference,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Validate checks that an entry adheres to constraints
+func (e Entry) Validate() error {
+	var errs errors
+	if e.ID == "" {
+		errs = append(errs, fmt.Errorf("ID cannot be empty"))
+	}
+	if e.UserID == "" {
+		errs = append(errs, fmt.Errorf("user ID cannot be empty"))
+	}
+	if e.Amount == 0 {
+		errs = append(errs, fmt.Errorf("amount cannot be 0"))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// GiftCard is a prepaid amount that can be spent by whoever knows its code, until it expires
+type GiftCard struct {
+	Code      string    `json:"code"`
+	Amount    uint      `json:"amount"`
+	Balance   uint      `json:"balance"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// IsExpired checks if the gift card can no longer be used at the given time
+func (g GiftCard) IsExpired(now time.Time) bool {
+	return !now.Before(g.ExpiresAt)
+}
+
+// Validate checks that a gift card adheres to constraints
+func (g GiftCard) Validate() error {
+	var errs errors
+	if g.Code == "" {
+		errs = append(errs, fmt.Errorf("code cannot be empty"))
+	}
+	if g.Amount == 0 {
+		errs = append(errs, fmt.Errorf("amount cannot be 0"))
+	}
+	if g.Balance > g.Amount {
+		errs = append(errs, fmt.Errorf("balance %d cannot exceed the amount %d", g.Balance, g.Amount))
+	}
+	if g.ExpiresAt.IsZero() {
+		errs = append(errs, fmt.Errorf("expiry cannot be empty"))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Copy returns a copy of the gift card that can be changed without altering the stored one
+func (g *GiftCard) Copy() *GiftCard {
+	giftCard := *g
+	return &giftCard
+}
+
+// Source is what pays for a leg of a tender
+type Source string
+
+const (
+	// StoreCredit the leg is paid from the wallet of the user
+	StoreCredit Source = "store_credit"
+	// GiftCardSource the leg is paid from a gift card
+	GiftCardSource Source = "gift_card"
+)
+
+// Leg is the part of a checkout paid from a single source
+type Leg struct {
+	Source Source `json:"source"`
+	// Code is the gift card paying for the leg
+	Code string `json:"-"`
+	// GiftCard shows only the last characters of the code, which are enough for the user to recognize the card
+	GiftCard string `json:"giftCard,omitempty"`
+	Amount   uint   `json:"amount"`
+}
+
+// Tender is what a checkout paid with store credit and gift cards. The rest is paid through the payment provider
+type Tender struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"-"`
+	Legs      []*Leg    `json:"legs"`
+	Total     uint      `json:"total"`
+	Reversed  bool      `json:"reversed"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Validate checks that a tender adheres to constraints
+func (t Tender) Validate() error {
+	var errs errors
+	if t.ID == "" {
+		errs = append(errs, fmt.Errorf("ID cannot be empty"))
+	}
+	if t.UserID == "" {
+		errs = append(errs, fmt.Errorf("user ID cannot be empty"))
+	}
+	var total uint
+	for _, leg := range t.Legs {
+		total += leg.Amount
+	}
+	if total != t.Total {
+		errs = append(errs, fmt.Errorf("the legs add up to %d instead of %d", total, t.Total))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
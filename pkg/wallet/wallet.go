@@ -0,0 +1,29 @@
+package wallet
+
+import (
+	netHTTP "net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mimatache/go-shop/internal/logger"
+	"github.com/mimatache/go-shop/internal/rbac"
+
+	"github.com/mimatache/go-shop/pkg/wallet/http"
+	"github.com/mimatache/go-shop/pkg/wallet/store"
+	"github.com/mimatache/go-shop/pkg/wallet/wallet"
+)
+
+// NewAPI instantiates a new wallet API
+func NewAPI(
+	logger logger.Logger,
+	db store.UnderlyingStore,
+	router *mux.Router,
+	userHandler func(netHTTP.Handler) netHTTP.Handler,
+	adminHandler func(permission rbac.Permission) func(netHTTP.Handler) netHTTP.Handler,
+) *wallet.Wallet {
+	walletStore := store.New(logger, db)
+	userWallet := wallet.New(walletStore)
+	walletAPI := http.New(userWallet)
+	walletAPI.AddRoutes(router, userHandler, adminHandler)
+	return userWallet
+}
@@ -0,0 +1,429 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mimatache/go-shop/internal/money"
+	internalStore "github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/wallet/store"
+)
+
+const (
+	idBytes = 8
+	// codeAlphabet leaves out the characters that are easily mistaken for one another
+	codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	codeGroups   = 4
+	codeGroupLen = 4
+	// DefaultGiftCardLifetime is how long a gift card is valid when no expiry is given
+	DefaultGiftCardLifetime = 365 * 24 * time.Hour
+)
+
+type errors []error
+
+func (e errors) Error() string {
+	b := bytes.NewBufferString("")
+	for _, err := range e {
+		_, _ = fmt.Fprintf(b, "\t%s\n", err)
+	}
+	return b.String()
+}
+
+type invalidTender struct {
+	msg string
+}
+
+func (i invalidTender) Error() string {
+	return i.msg
+}
+
+// IsInvalidTenderError verifies if a given error refers to store credit or a gift card that cannot be used
+func IsInvalidTenderError(err error) bool {
+	switch err.(type) {
+	case invalidTender:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewInvalidTender creates a new invalid tender error
+func NewInvalidTender(reason string) error {
+	return invalidTender{msg: reason}
+}
+
+// Statement is the balance of a wallet together with the entries it adds up from, newest first
+type Statement struct {
+	Balance uint           `json:"balance"`
+	Entries []*store.Entry `json:"entries"`
+}
+
+// New creates the wallets
+func New(storage store.WalletStore) *Wallet {
+	return &Wallet{
+		storage: storage,
+		now:     time.Now,
+	}
+}
+
+// Wallet keeps the store credit of the users and the gift cards. Both can pay for a part of a checkout,
+// the rest being paid through the payment provider
+type Wallet struct {
+	storage store.WalletStore
+	now     func() time.Time
+	sync.Mutex
+}
+
+// GetStatement returns the balance and the entries of the wallet of a user
+func (w *Wallet) GetStatement(userID string) (*Statement, error) {
+	entries, err := w.storage.GetEntriesForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+	credit, err := balance(entries)
+	if err != nil {
+		return nil, err
+	}
+	return &Statement{Balance: credit, Entries: entries}, nil
+}
+
+// IssueGiftCard creates a gift card worth the amount, valid until expiresAt or for DefaultGiftCardLifetime
+func (w *Wallet) IssueGiftCard(amount uint, expiresAt time.Time) (*store.GiftCard, error) {
+	now := w.now()
+	if amount == 0 {
+		return nil, NewInvalidTender("the amount of a gift card must be greater than 0")
+	}
+	if expiresAt.IsZero() {
+		expiresAt = now.Add(DefaultGiftCardLifetime)
+	}
+	if !expiresAt.After(now) {
+		return nil, NewInvalidTender("the expiry of a gift card must be in the future")
+	}
+	code, err := newCode()
+	if err != nil {
+		return nil, err
+	}
+	giftCard := &store.GiftCard{
+		Code:      code,
+		Amount:    amount,
+		Balance:   amount,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := w.storage.SetGiftCard(giftCard); err != nil {
+		return nil, err
+	}
+	return giftCard, nil
+}
+
+// GetGiftCard returns the gift card with the given code
+func (w *Wallet) GetGiftCard(code string) (*store.GiftCard, error) {
+	return w.storage.GetGiftCard(normalize(code))
+}
+
+// RedeemGiftCard moves the balance of a gift card into the wallet of the user
+func (w *Wallet) RedeemGiftCard(userID string, code string) (*Statement, error) {
+	w.Lock()
+	giftCard, err := w.usableGiftCard(normalize(code))
+	if err != nil {
+		w.Unlock()
+		return nil, err
+	}
+	err = w.redeem(userID, giftCard)
+	w.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return w.GetStatement(userID)
+}
+
+func (w *Wallet) redeem(userID string, giftCard *store.GiftCard) error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	redeemed := giftCard.Copy()
+	redeemed.Balance = 0
+	redeemed.UpdatedAt = w.now()
+	if err := w.storage.SetGiftCard(redeemed); err != nil {
+		return err
+	}
+	entry := &store.Entry{
+		ID:        id,
+		UserID:    userID,
+		Type:      store.GiftCardRedemption,
+		Amount:    int(giftCard.Balance),
+		Reference: mask(giftCard.Code),
+		CreatedAt: w.now(),
+	}
+	if err := w.storage.AddEntries(entry); err != nil {
+		return rollback(err, w.storage.SetGiftCard(giftCard))
+	}
+	return nil
+}
+
+// Spend takes up to amount from the given gift cards, in the given order, and then from the store credit of the user,
+// when asked to. Either all the legs of the returned tender are taken or none of them
+func (w *Wallet) Spend(userID string, amount uint, codes []string, storeCredit bool) (*store.Tender, error) {
+	w.Lock()
+	defer w.Unlock()
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	tender := &store.Tender{
+		ID:        id,
+		UserID:    userID,
+		CreatedAt: w.now(),
+	}
+	giftCards := []*store.GiftCard{}
+	seen := map[string]bool{}
+	for _, code := range codes {
+		code = normalize(code)
+		if seen[code] {
+			return nil, NewInvalidTender(fmt.Sprintf("gift card %s is given more than once", mask(code)))
+		}
+		seen[code] = true
+		giftCard, err := w.usableGiftCard(code)
+		if err != nil {
+			return nil, err
+		}
+		if tender.Total == amount {
+			continue
+		}
+		leg := &store.Leg{Source: store.GiftCardSource, Code: code, GiftCard: mask(code), Amount: min(giftCard.Balance, amount-tender.Total)}
+		tender.Legs = append(tender.Legs, leg)
+		if tender.Total, err = money.Add(tender.Total, leg.Amount); err != nil {
+			return nil, err
+		}
+		giftCards = append(giftCards, giftCard)
+	}
+	if storeCredit && tender.Total < amount {
+		entries, err := w.storage.GetEntriesForUser(userID)
+		if err != nil {
+			return nil, err
+		}
+		credit, err := balance(entries)
+		if err != nil {
+			return nil, err
+		}
+		if credit > 0 {
+			leg := &store.Leg{Source: store.StoreCredit, Amount: min(credit, amount-tender.Total)}
+			tender.Legs = append(tender.Legs, leg)
+			if tender.Total, err = money.Add(tender.Total, leg.Amount); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for i, leg := range tender.Legs {
+		if err := w.take(tender, leg, giftCards); err != nil {
+			return nil, rollback(err, w.giveBack(tender, tender.Legs[:i]))
+		}
+	}
+	if err := w.storage.SetTender(tender); err != nil {
+		return nil, rollback(err, w.giveBack(tender, tender.Legs))
+	}
+	return tender, nil
+}
+
+// Reverse gives back everything taken by a tender, when the checkout it paid for failed
+func (w *Wallet) Reverse(tenderID string) error {
+	w.Lock()
+	defer w.Unlock()
+	stored, err := w.storage.GetTender(tenderID)
+	if err != nil {
+		return err
+	}
+	if stored.Reversed {
+		return nil
+	}
+	if err := w.giveBack(stored, stored.Legs); err != nil {
+		return err
+	}
+	tender := *stored
+	tender.Reversed = true
+	return w.storage.SetTender(&tender)
+}
+
+// Refund gives money back to the user as store credit
+func (w *Wallet) Refund(userID string, amount uint, reference string) (*store.Entry, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	entry := &store.Entry{
+		ID:        id,
+		UserID:    userID,
+		Type:      store.Refund,
+		Amount:    int(amount),
+		Reference: reference,
+		CreatedAt: w.now(),
+	}
+	if err := w.storage.AddEntries(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// usableGiftCard returns a gift card that did not expire and still has money on it
+func (w *Wallet) usableGiftCard(code string) (*store.GiftCard, error) {
+	giftCard, err := w.storage.GetGiftCard(code)
+	if err != nil {
+		if internalStore.IsNotFoundError(err) {
+			return nil, NewInvalidTender(fmt.Sprintf("unknown gift card %s", mask(code)))
+		}
+		return nil, err
+	}
+	if giftCard.IsExpired(w.now()) {
+		return nil, NewInvalidTender(fmt.Sprintf("gift card %s expired", mask(code)))
+	}
+	if giftCard.Balance == 0 {
+		return nil, NewInvalidTender(fmt.Sprintf("gift card %s was used up", mask(code)))
+	}
+	return giftCard, nil
+}
+
+// take takes the amount of a leg from its source
+func (w *Wallet) take(tender *store.Tender, leg *store.Leg, giftCards []*store.GiftCard) error {
+	if leg.Source == store.StoreCredit {
+		return w.addEntry(tender.UserID, store.Purchase, -int(leg.Amount), tender.ID)
+	}
+	for _, giftCard := range giftCards {
+		if giftCard.Code == leg.Code {
+			spent := giftCard.Copy()
+			spent.Balance -= leg.Amount
+			spent.UpdatedAt = w.now()
+			return w.storage.SetGiftCard(spent)
+		}
+	}
+	return fmt.Errorf("gift card %s is not part of the tender", mask(leg.Code))
+}
+
+// giveBack returns the amounts of the legs to their sources. Gift cards get their money back even if they expired
+func (w *Wallet) giveBack(tender *store.Tender, legs []*store.Leg) error {
+	var errs errors
+	for _, leg := range legs {
+		if leg.Source == store.StoreCredit {
+			if err := w.addEntry(tender.UserID, store.Reversal, int(leg.Amount), tender.ID); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		giftCard, err := w.storage.GetGiftCard(leg.Code)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		restored := giftCard.Copy()
+		if restored.Balance, err = money.Add(restored.Balance, leg.Amount); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		restored.UpdatedAt = w.now()
+		if err := w.storage.SetGiftCard(restored); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (w *Wallet) addEntry(userID string, entryType store.EntryType, amount int, reference string) error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	return w.storage.AddEntries(&store.Entry{
+		ID:        id,
+		UserID:    userID,
+		Type:      entryType,
+		Amount:    amount,
+		Reference: reference,
+		CreatedAt: w.now(),
+	})
+}
+
+// rollback returns the cause of a failure, followed by the failure to undo what was done before it
+func rollback(cause error, undo error) error {
+	if undo == nil {
+		return cause
+	}
+	return errors{cause, undo}
+}
+
+// balance returns the credit left in the wallet, failing if the entries add up to more than can be represented
+func balance(entries []*store.Entry) (uint, error) {
+	var credited, debited uint
+	for _, entry := range entries {
+		var err error
+		if entry.Amount < 0 {
+			debited, err = money.Add(debited, uint(-entry.Amount))
+		} else {
+			credited, err = money.Add(credited, uint(entry.Amount))
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	if debited > credited {
+		return 0, nil
+	}
+	return credited - debited, nil
+}
+
+func min(a, b uint) uint {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// normalize lets the users type the gift card codes in lower case and with surrounding spaces
+func normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// mask hides all but the last characters of a gift card code
+func mask(code string) string {
+	if len(code) <= codeGroupLen {
+		return code
+	}
+	return "****" + code[len(code)-codeGroupLen:]
+}
+
+func newCode() (string, error) {
+	b := make([]byte, codeGroups*codeGroupLen)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	groups := make([]string, 0, codeGroups)
+	for i := 0; i < codeGroups; i++ {
+		group := make([]byte, codeGroupLen)
+		for j := range group {
+			group[j] = codeAlphabet[int(b[i*codeGroupLen+j])%len(codeAlphabet)]
+		}
+		groups = append(groups, string(group))
+	}
+	return strings.Join(groups, "-"), nil
+}
+
+func newID() (string, error) {
+	b := make([]byte, idBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
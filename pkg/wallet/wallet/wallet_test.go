@@ -0,0 +1,206 @@
+package wallet_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	"github.com/mimatache/go-shop/internal/money"
+	internalStore "github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/wallet/store"
+	mock_store "github.com/mimatache/go-shop/pkg/wallet/store/mocks"
+	"github.com/mimatache/go-shop/pkg/wallet/wallet"
+)
+
+const (
+	userID = "user@email.com"
+	code   = "ABCD-EFGH-JKLM-NPQR"
+)
+
+func newWallet(t *testing.T) (*wallet.Wallet, *mock_store.MockWalletStore, func()) {
+	ctrl := gomock.NewController(t)
+	storage := mock_store.NewMockWalletStore(ctrl)
+	return wallet.New(storage), storage, ctrl.Finish
+}
+
+func newGiftCard(balance uint) *store.GiftCard {
+	return &store.GiftCard{Code: code, Amount: 50, Balance: balance, ExpiresAt: time.Now().Add(time.Hour)}
+}
+
+func TestWallet_IssueGiftCard(t *testing.T) {
+	g := NewWithT(t)
+
+	userWallet, storage, finish := newWallet(t)
+	defer finish()
+
+	storage.EXPECT().SetGiftCard(gomock.Any()).Return(nil)
+
+	giftCard, err := userWallet.IssueGiftCard(50, time.Time{})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(giftCard.Code).To(MatchRegexp(`^[A-Z2-9]{4}(-[A-Z2-9]{4}){3}$`))
+	g.Expect(giftCard.Balance).To(Equal(uint(50)))
+	g.Expect(giftCard.ExpiresAt).To(BeTemporally("~", time.Now().Add(wallet.DefaultGiftCardLifetime), time.Minute))
+}
+
+func TestWallet_RedeemGiftCard(t *testing.T) {
+	g := NewWithT(t)
+
+	userWallet, storage, finish := newWallet(t)
+	defer finish()
+
+	storage.EXPECT().GetGiftCard(code).Return(newGiftCard(30), nil)
+	storage.EXPECT().SetGiftCard(gomock.Any()).DoAndReturn(func(giftCard *store.GiftCard) error {
+		g.Expect(giftCard.Balance).To(BeZero())
+		return nil
+	})
+	storage.EXPECT().AddEntries(gomock.Any()).DoAndReturn(func(entries ...*store.Entry) error {
+		g.Expect(entries[0].Type).To(Equal(store.GiftCardRedemption))
+		g.Expect(entries[0].Amount).To(Equal(30))
+		return nil
+	})
+	storage.EXPECT().GetEntriesForUser(userID).Return([]*store.Entry{{Amount: 30}, {Amount: -10}}, nil)
+
+	statement, err := userWallet.RedeemGiftCard(userID, " abcd-efgh-jklm-npqr ")
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(statement.Balance).To(Equal(uint(20)))
+}
+
+func TestWallet_GetStatement_Overflow(t *testing.T) {
+	g := NewWithT(t)
+
+	userWallet, storage, finish := newWallet(t)
+	defer finish()
+
+	largest := int(^uint(0) >> 1)
+	storage.EXPECT().GetEntriesForUser(userID).Return([]*store.Entry{{Amount: largest}, {Amount: largest}, {Amount: largest}}, nil)
+
+	_, err := userWallet.GetStatement(userID)
+
+	g.Expect(money.IsOverflowError(err)).To(BeTrue())
+}
+
+func TestWallet_RedeemGiftCard_Unusable(t *testing.T) {
+	expired := newGiftCard(30)
+	expired.ExpiresAt = time.Now().Add(-time.Hour)
+	tests := []struct {
+		name     string
+		giftCard *store.GiftCard
+		err      error
+	}{
+		{name: "unknown", err: internalStore.NewNotFoundError("giftCard", "id", code)},
+		{name: "expired", giftCard: expired},
+		{name: "used up", giftCard: newGiftCard(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			userWallet, storage, finish := newWallet(t)
+			defer finish()
+
+			storage.EXPECT().GetGiftCard(code).Return(tt.giftCard, tt.err)
+
+			_, err := userWallet.RedeemGiftCard(userID, code)
+
+			g.Expect(wallet.IsInvalidTenderError(err)).To(BeTrue())
+		})
+	}
+}
+
+func TestWallet_Spend(t *testing.T) {
+	g := NewWithT(t)
+
+	userWallet, storage, finish := newWallet(t)
+	defer finish()
+
+	storage.EXPECT().GetGiftCard(code).Return(newGiftCard(30), nil)
+	storage.EXPECT().GetEntriesForUser(userID).Return([]*store.Entry{{Amount: 100}}, nil)
+	storage.EXPECT().SetGiftCard(gomock.Any()).DoAndReturn(func(giftCard *store.GiftCard) error {
+		g.Expect(giftCard.Balance).To(BeZero())
+		return nil
+	})
+	storage.EXPECT().AddEntries(gomock.Any()).DoAndReturn(func(entries ...*store.Entry) error {
+		g.Expect(entries[0].Type).To(Equal(store.Purchase))
+		g.Expect(entries[0].Amount).To(Equal(-50))
+		return nil
+	})
+	storage.EXPECT().SetTender(gomock.Any()).Return(nil)
+
+	tender, err := userWallet.Spend(userID, 80, []string{code}, true)
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(tender.Total).To(Equal(uint(80)))
+	g.Expect(tender.Legs).To(Equal([]*store.Leg{
+		{Source: store.GiftCardSource, Code: code, GiftCard: "****NPQR", Amount: 30},
+		{Source: store.StoreCredit, Amount: 50},
+	}))
+}
+
+func TestWallet_Spend_GivesBackWhenALegFails(t *testing.T) {
+	g := NewWithT(t)
+
+	userWallet, storage, finish := newWallet(t)
+	defer finish()
+
+	storage.EXPECT().GetGiftCard(code).Return(newGiftCard(30), nil)
+	storage.EXPECT().GetEntriesForUser(userID).Return([]*store.Entry{{Amount: 100}}, nil)
+	storage.EXPECT().SetGiftCard(gomock.Any()).Return(nil)
+	storage.EXPECT().AddEntries(gomock.Any()).Return(fmt.Errorf("boom"))
+	storage.EXPECT().GetGiftCard(code).Return(newGiftCard(0), nil)
+	storage.EXPECT().SetGiftCard(gomock.Any()).DoAndReturn(func(giftCard *store.GiftCard) error {
+		g.Expect(giftCard.Balance).To(Equal(uint(30)))
+		return nil
+	})
+
+	_, err := userWallet.Spend(userID, 80, []string{code}, true)
+
+	g.Expect(err).To(MatchError("boom"))
+}
+
+func TestWallet_Spend_InvalidGiftCard(t *testing.T) {
+	g := NewWithT(t)
+
+	userWallet, storage, finish := newWallet(t)
+	defer finish()
+
+	storage.EXPECT().GetGiftCard(code).Return(newGiftCard(30), nil)
+
+	_, err := userWallet.Spend(userID, 80, []string{code, code}, false)
+
+	g.Expect(wallet.IsInvalidTenderError(err)).To(BeTrue())
+}
+
+func TestWallet_Reverse(t *testing.T) {
+	g := NewWithT(t)
+
+	userWallet, storage, finish := newWallet(t)
+	defer finish()
+
+	tender := &store.Tender{ID: "tender", UserID: userID, Total: 80, Legs: []*store.Leg{
+		{Source: store.GiftCardSource, Code: code, Amount: 30},
+		{Source: store.StoreCredit, Amount: 50},
+	}}
+	storage.EXPECT().GetTender("tender").Return(tender, nil)
+	storage.EXPECT().GetGiftCard(code).Return(newGiftCard(0), nil)
+	storage.EXPECT().SetGiftCard(gomock.Any()).DoAndReturn(func(giftCard *store.GiftCard) error {
+		g.Expect(giftCard.Balance).To(Equal(uint(30)))
+		return nil
+	})
+	storage.EXPECT().AddEntries(gomock.Any()).DoAndReturn(func(entries ...*store.Entry) error {
+		g.Expect(entries[0].Type).To(Equal(store.Reversal))
+		g.Expect(entries[0].Amount).To(Equal(50))
+		return nil
+	})
+	storage.EXPECT().SetTender(gomock.Any()).DoAndReturn(func(reversed *store.Tender) error {
+		g.Expect(reversed.Reversed).To(BeTrue())
+		return nil
+	})
+
+	g.Expect(userWallet.Reverse("tender")).To(Succeed())
+	g.Expect(tender.Reversed).To(BeFalse())
+}
@@ -0,0 +1,239 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mimatache/go-shop/internal/http/authorization"
+	"github.com/mimatache/go-shop/internal/http/helpers"
+	"github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/cart/cart"
+	"github.com/mimatache/go-shop/pkg/wishlist/wishlist"
+)
+
+// NewWishlist holds the details needed to create a wishlist
+type NewWishlist struct {
+	Name string `json:"name"`
+}
+
+func New(wishlists *wishlist.Wishlists) *WishlistAPI {
+	return &WishlistAPI{
+		wishlists: wishlists,
+	}
+}
+
+type WishlistAPI struct {
+	wishlists *wishlist.Wishlists
+}
+
+func (a *WishlistAPI) list(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	wishlists, err := a.wishlists.List(userID)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, wishlists, http.StatusOK)
+}
+
+func (a *WishlistAPI) create(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var details NewWishlist
+	if !decode(w, r, &details) {
+		return
+	}
+	created, err := a.wishlists.Create(userID, details.Name)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, created, http.StatusCreated)
+}
+
+func (a *WishlistAPI) get(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	found, err := a.wishlists.Get(userID, mux.Vars(r)["id"])
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, found, http.StatusOK)
+}
+
+func (a *WishlistAPI) delete(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	err = a.wishlists.Delete(userID, mux.Vars(r)["id"])
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *WishlistAPI) addProduct(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var prod cart.Product
+	if !decode(w, r, &prod) {
+		return
+	}
+	updated, err := a.wishlists.AddProduct(userID, mux.Vars(r)["id"], prod)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, updated, http.StatusOK)
+}
+
+func (a *WishlistAPI) removeProduct(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	productID, err := strconv.ParseUint(mux.Vars(r)["productID"], 10, 64)
+	if err != nil {
+		helpers.FormatError(w, "invalid product ID", http.StatusBadRequest)
+		return
+	}
+	updated, err := a.wishlists.RemoveProduct(userID, mux.Vars(r)["id"], uint(productID))
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, updated, http.StatusOK)
+}
+
+func (a *WishlistAPI) share(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	shared, err := a.wishlists.Share(userID, mux.Vars(r)["id"])
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, shared, http.StatusOK)
+}
+
+func (a *WishlistAPI) unshare(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	private, err := a.wishlists.Unshare(userID, mux.Vars(r)["id"])
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, private, http.StatusOK)
+}
+
+func (a *WishlistAPI) getShared(w http.ResponseWriter, r *http.Request) {
+	shared, err := a.wishlists.GetShared(mux.Vars(r)["token"])
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, shared, http.StatusOK)
+}
+
+func (a *WishlistAPI) moveToCart(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var prod cart.Product
+	if !decode(w, r, &prod) {
+		return
+	}
+	contents, err := a.wishlists.MoveToCart(userID, mux.Vars(r)["id"], prod)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, contents, http.StatusOK)
+}
+
+func (a *WishlistAPI) saveForLater(w http.ResponseWriter, r *http.Request) {
+	userID, err := authorization.GetUserIDFromRequest(r)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var prod cart.Product
+	if !decode(w, r, &prod) {
+		return
+	}
+	updated, err := a.wishlists.SaveForLater(userID, mux.Vars(r)["id"], prod)
+	if err != nil {
+		formatError(w, err)
+		return
+	}
+	helpers.FormatResponse(w, updated, http.StatusOK)
+}
+
+func decode(w http.ResponseWriter, r *http.Request, value interface{}) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(value)
+	if err != nil {
+		helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func formatError(w http.ResponseWriter, err error) {
+	switch {
+	case store.IsNotFoundError(err):
+		helpers.FormatError(w, err.Error(), http.StatusNotFound)
+	case wishlist.IsInvalidWishlistError(err), cart.IsInvalidQuantityError(err):
+		helpers.FormatError(w, err.Error(), http.StatusBadRequest)
+	default:
+		helpers.FormatError(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// AddRoutes registers the API routes to a router.
+// Shared wishlists can be read by anyone holding the share token, all the other routes are wrapped by the user handler
+func (a *WishlistAPI) AddRoutes(router *mux.Router, userHandler func(http.Handler) http.Handler) {
+	wishlistRouter := router.PathPrefix("/wishlists").Subrouter()
+	wishlistRouter.HandleFunc("/shared/{token}", a.getShared).Methods(http.MethodGet)
+	wishlistRouter.Handle("", userHandler(http.HandlerFunc(a.list))).Methods(http.MethodGet)
+	wishlistRouter.Handle("", userHandler(http.HandlerFunc(a.create))).Methods(http.MethodPost)
+	wishlistRouter.Handle("/{id}", userHandler(http.HandlerFunc(a.get))).Methods(http.MethodGet)
+	wishlistRouter.Handle("/{id}", userHandler(http.HandlerFunc(a.delete))).Methods(http.MethodDelete)
+	wishlistRouter.Handle("/{id}/products", userHandler(http.HandlerFunc(a.addProduct))).Methods(http.MethodPost)
+	wishlistRouter.Handle("/{id}/products/{productID}", userHandler(http.HandlerFunc(a.removeProduct))).Methods(http.MethodDelete)
+	wishlistRouter.Handle("/{id}/share", userHandler(http.HandlerFunc(a.share))).Methods(http.MethodPost)
+	wishlistRouter.Handle("/{id}/share", userHandler(http.HandlerFunc(a.unshare))).Methods(http.MethodDelete)
+	wishlistRouter.Handle("/{id}/move-to-cart", userHandler(http.HandlerFunc(a.moveToCart))).Methods(http.MethodPost)
+	wishlistRouter.Handle("/{id}/save-for-later", userHandler(http.HandlerFunc(a.saveForLater))).Methods(http.MethodPost)
+}
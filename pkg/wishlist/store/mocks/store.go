@@ -0,0 +1,315 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./store.go
+
+// Package mock_store is a generated GoMock package.
+package mock_store
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	store "github.com/mimatache/go-shop/pkg/wishlist/store"
+	reflect "reflect"
+)
+
+// Mocklogger is a mock of logger interface
+type Mocklogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockloggerMockRecorder
+}
+
+// MockloggerMockRecorder is the mock recorder for Mocklogger
+type MockloggerMockRecorder struct {
+	mock *Mocklogger
+}
+
+// NewMocklogger creates a new mock instance
+func NewMocklogger(ctrl *gomock.Controller) *Mocklogger {
+	mock := &Mocklogger{ctrl: ctrl}
+	mock.recorder = &MockloggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *Mocklogger) EXPECT() *MockloggerMockRecorder {
+	return m.recorder
+}
+
+// Infof mocks base method
+func (m *Mocklogger) Infof(msg string, args ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Infof", varargs...)
+}
+
+// Infof indicates an expected call of Infof
+func (mr *MockloggerMockRecorder) Infof(msg interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Infof", reflect.TypeOf((*Mocklogger)(nil).Infof), varargs...)
+}
+
+// Debugf mocks base method
+func (m *Mocklogger) Debugf(msg string, args ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Debugf", varargs...)
+}
+
+// Debugf indicates an expected call of Debugf
+func (mr *MockloggerMockRecorder) Debugf(msg interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Debugf", reflect.TypeOf((*Mocklogger)(nil).Debugf), varargs...)
+}
+
+// Debugw mocks base method
+func (m *Mocklogger) Debugw(msg string, keysAndValues ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{msg}
+	for _, a := range keysAndValues {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Debugw", varargs...)
+}
+
+// Debugw indicates an expected call of Debugw
+func (mr *MockloggerMockRecorder) Debugw(msg interface{}, keysAndValues ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{msg}, keysAndValues...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Debugw", reflect.TypeOf((*Mocklogger)(nil).Debugw), varargs...)
+}
+
+// MockUnderlyingStore is a mock of UnderlyingStore interface
+type MockUnderlyingStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockUnderlyingStoreMockRecorder
+}
+
+// MockUnderlyingStoreMockRecorder is the mock recorder for MockUnderlyingStore
+type MockUnderlyingStoreMockRecorder struct {
+	mock *MockUnderlyingStore
+}
+
+// NewMockUnderlyingStore creates a new mock instance
+func NewMockUnderlyingStore(ctrl *gomock.Controller) *MockUnderlyingStore {
+	mock := &MockUnderlyingStore{ctrl: ctrl}
+	mock.recorder = &MockUnderlyingStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockUnderlyingStore) EXPECT() *MockUnderlyingStoreMockRecorder {
+	return m.recorder
+}
+
+// Read mocks base method
+func (m *MockUnderlyingStore) Read(table, key string, value interface{}) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", table, key, value)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read
+func (mr *MockUnderlyingStoreMockRecorder) Read(table, key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockUnderlyingStore)(nil).Read), table, key, value)
+}
+
+// ReadAll mocks base method
+func (m *MockUnderlyingStore) ReadAll(table, key string, args ...interface{}) ([]interface{}, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{table, key}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReadAll", varargs...)
+	ret0, _ := ret[0].([]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAll indicates an expected call of ReadAll
+func (mr *MockUnderlyingStoreMockRecorder) ReadAll(table, key interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{table, key}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAll", reflect.TypeOf((*MockUnderlyingStore)(nil).ReadAll), varargs...)
+}
+
+// Write mocks base method
+func (m *MockUnderlyingStore) Write(table string, objs ...interface{}) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{table}
+	for _, a := range objs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Write", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Write indicates an expected call of Write
+func (mr *MockUnderlyingStoreMockRecorder) Write(table interface{}, objs ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{table}, objs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockUnderlyingStore)(nil).Write), varargs...)
+}
+
+// Remove mocks base method
+func (m *MockUnderlyingStore) Remove(table, key string, value interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", table, key, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove
+func (mr *MockUnderlyingStoreMockRecorder) Remove(table, key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockUnderlyingStore)(nil).Remove), table, key, value)
+}
+
+// MockWishlistStore is a mock of WishlistStore interface
+type MockWishlistStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockWishlistStoreMockRecorder
+}
+
+// MockWishlistStoreMockRecorder is the mock recorder for MockWishlistStore
+type MockWishlistStoreMockRecorder struct {
+	mock *MockWishlistStore
+}
+
+// NewMockWishlistStore creates a new mock instance
+func NewMockWishlistStore(ctrl *gomock.Controller) *MockWishlistStore {
+	mock := &MockWishlistStore{ctrl: ctrl}
+	mock.recorder = &MockWishlistStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWishlistStore) EXPECT() *MockWishlistStoreMockRecorder {
+	return m.recorder
+}
+
+// SetWishlist mocks base method
+func (m *MockWishlistStore) SetWishlist(wishlist *store.Wishlist) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWishlist", wishlist)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetWishlist indicates an expected call of SetWishlist
+func (mr *MockWishlistStoreMockRecorder) SetWishlist(wishlist interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWishlist", reflect.TypeOf((*MockWishlistStore)(nil).SetWishlist), wishlist)
+}
+
+// GetWishlist mocks base method
+func (m *MockWishlistStore) GetWishlist(wishlistID string) (*store.Wishlist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWishlist", wishlistID)
+	ret0, _ := ret[0].(*store.Wishlist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWishlist indicates an expected call of GetWishlist
+func (mr *MockWishlistStoreMockRecorder) GetWishlist(wishlistID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWishlist", reflect.TypeOf((*MockWishlistStore)(nil).GetWishlist), wishlistID)
+}
+
+// GetWishlistsForUser mocks base method
+func (m *MockWishlistStore) GetWishlistsForUser(userID string) ([]*store.Wishlist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWishlistsForUser", userID)
+	ret0, _ := ret[0].([]*store.Wishlist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWishlistsForUser indicates an expected call of GetWishlistsForUser
+func (mr *MockWishlistStoreMockRecorder) GetWishlistsForUser(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWishlistsForUser", reflect.TypeOf((*MockWishlistStore)(nil).GetWishlistsForUser), userID)
+}
+
+// GetSharedWishlist mocks base method
+func (m *MockWishlistStore) GetSharedWishlist(token string) (*store.Wishlist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSharedWishlist", token)
+	ret0, _ := ret[0].(*store.Wishlist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSharedWishlist indicates an expected call of GetSharedWishlist
+func (mr *MockWishlistStoreMockRecorder) GetSharedWishlist(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharedWishlist", reflect.TypeOf((*MockWishlistStore)(nil).GetSharedWishlist), token)
+}
+
+// RemoveWishlist mocks base method
+func (m *MockWishlistStore) RemoveWishlist(wishlistID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveWishlist", wishlistID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveWishlist indicates an expected call of RemoveWishlist
+func (mr *MockWishlistStoreMockRecorder) RemoveWishlist(wishlistID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveWishlist", reflect.TypeOf((*MockWishlistStore)(nil).RemoveWishlist), wishlistID)
+}
+
+// AddAlert mocks base method
+func (m *MockWishlistStore) AddAlert(alert *store.StockAlert) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAlert", alert)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAlert indicates an expected call of AddAlert
+func (mr *MockWishlistStoreMockRecorder) AddAlert(alert interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAlert", reflect.TypeOf((*MockWishlistStore)(nil).AddAlert), alert)
+}
+
+// GetAlerts mocks base method
+func (m *MockWishlistStore) GetAlerts() ([]*store.StockAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlerts")
+	ret0, _ := ret[0].([]*store.StockAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAlerts indicates an expected call of GetAlerts
+func (mr *MockWishlistStoreMockRecorder) GetAlerts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlerts", reflect.TypeOf((*MockWishlistStore)(nil).GetAlerts))
+}
+
+// RemoveAlert mocks base method
+func (m *MockWishlistStore) RemoveAlert(alertID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveAlert", alertID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveAlert indicates an expected call of RemoveAlert
+func (mr *MockWishlistStoreMockRecorder) RemoveAlert(alertID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAlert", reflect.TypeOf((*MockWishlistStore)(nil).RemoveAlert), alertID)
+}
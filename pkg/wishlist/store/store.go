@@ -0,0 +1,350 @@
+package store
+
+import (
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/mimatache/go-shop/internal/store"
+)
+
+//go:generate mockgen -source ./store.go -destination mocks/store.go
+
+type logger interface {
+	Infof(msg string, args ...interface{})
+	Debugf(msg string, args ...interface{})
+	Debugw(msg string, keysAndValues ...interface{})
+}
+
+const (
+	id    = "id"
+	user  = "user"
+	share = "share"
+)
+
+var (
+	table      = &WishlistTable{name: "wishlist"}
+	alertTable = &StockAlertTable{name: "stockAlert"}
+)
+
+// GetTable returns the wishlist table
+func GetTable() *WishlistTable {
+	return table
+}
+
+// GetAlertTable returns the back in stock alert table
+func GetAlertTable() *StockAlertTable {
+	return alertTable
+}
+
+// WishlistTable the wishlist table schema
+type WishlistTable struct {
+	name string
+}
+
+// GetName returns the name of the wishlist table
+func (w *WishlistTable) GetName() string {
+	return w.name
+}
+
+// GetTableSchema returns the schema of the wishlist table
+func (w *WishlistTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: w.name,
+		Indexes: map[string]*memdb.IndexSchema{
+			id: {
+				Name:    id,
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "ID"},
+			},
+			user: {
+				Name:    user,
+				Unique:  false,
+				Indexer: &memdb.StringFieldIndex{Field: "UserID"},
+			},
+			share: {
+				Name:         share,
+				Unique:       true,
+				AllowMissing: true,
+				Indexer:      &memdb.StringFieldIndex{Field: "ShareToken"},
+			},
+		},
+	}
+}
+
+// GetPersonalRows returns the wishlists of the user
+func (w *WishlistTable) GetPersonalRows(db store.Reader, userID string) ([]interface{}, error) {
+	return db.ReadAll(w.name, user, userID)
+}
+
+// Export returns the wishlist
+func (w *WishlistTable) Export(row interface{}) interface{} {
+	return row
+}
+
+// Anonymize removes the wishlist
+func (w *WishlistTable) Anonymize(row interface{}, userID string, pseudonym string) (interface{}, error) {
+	return nil, nil
+}
+
+// StockAlertTable the back in stock alert table schema
+type StockAlertTable struct {
+	name string
+}
+
+// GetName returns the name of the back in stock alert table
+func (s *StockAlertTable) GetName() string {
+	return s.name
+}
+
+// GetTableSchema returns the schema of the back in stock alert table
+func (s *StockAlertTable) GetTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: s.name,
+		Indexes: map[string]*memdb.IndexSchema{
+			id: {
+				Name:    id,
+				Unique:  true,
+				Indexer: &memdb.StringFieldIndex{Field: "ID"},
+			},
+			user: {
+				Name:    user,
+				Unique:  false,
+				Indexer: &memdb.StringFieldIndex{Field: "UserID"},
+			},
+		},
+	}
+}
+
+// GetPersonalRows returns the back in stock alerts of the user
+func (s *StockAlertTable) GetPersonalRows(db store.Reader, userID string) ([]interface{}, error) {
+	return db.ReadAll(s.name, user, userID)
+}
+
+// Export returns the back in stock alert
+func (s *StockAlertTable) Export(row interface{}) interface{} {
+	return row
+}
+
+// Anonymize removes the back in stock alert
+func (s *StockAlertTable) Anonymize(row interface{}, userID string, pseudonym string) (interface{}, error) {
+	return nil, nil
+}
+
+// UnderlyingStore represents the interface that the DB should implement to be usable
+type UnderlyingStore interface {
+	Read(table string, key string, value interface{}) (interface{}, error)
+	ReadAll(table string, key string, args ...interface{}) ([]interface{}, error)
+	Write(table string, objs ...interface{}) error
+	Remove(table string, key string, value interface{}) error
+}
+
+// WishlistStore represents the wishlist store
+type WishlistStore interface {
+	SetWishlist(wishlist *Wishlist) error
+	GetWishlist(wishlistID string) (*Wishlist, error)
+	GetWishlistsForUser(userID string) ([]*Wishlist, error)
+	GetSharedWishlist(token string) (*Wishlist, error)
+	RemoveWishlist(wishlistID string) error
+	AddAlert(alert *StockAlert) error
+	GetAlerts() ([]*StockAlert, error)
+	RemoveAlert(alertID string) error
+}
+
+// New start a new instance of the wishlist store
+func New(log logger, db UnderlyingStore) WishlistStore {
+	return &wishlistLogger{
+		log:  log,
+		next: &wishlistStore{db: db},
+	}
+}
+
+type wishlistStore struct {
+	db UnderlyingStore
+}
+
+// SetWishlist creates or replaces a wishlist
+func (w *wishlistStore) SetWishlist(wishlist *Wishlist) error {
+	if err := wishlist.Validate(); err != nil {
+		return err
+	}
+	return w.db.Write(table.GetName(), wishlist)
+}
+
+// GetWishlist returns the wishlist with the given ID
+func (w *wishlistStore) GetWishlist(wishlistID string) (*Wishlist, error) {
+	item, err := w.db.Read(table.GetName(), id, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+	return item.(*Wishlist), nil
+}
+
+// GetWishlistsForUser returns all the wishlists of a user
+func (w *wishlistStore) GetWishlistsForUser(userID string) ([]*Wishlist, error) {
+	rows, err := w.db.ReadAll(table.GetName(), user, userID)
+	if err != nil {
+		return nil, err
+	}
+	wishlists := make([]*Wishlist, 0, len(rows))
+	for _, row := range rows {
+		wishlists = append(wishlists, row.(*Wishlist))
+	}
+	return wishlists, nil
+}
+
+// GetSharedWishlist returns the wishlist shared under the given token
+func (w *wishlistStore) GetSharedWishlist(token string) (*Wishlist, error) {
+	item, err := w.db.Read(table.GetName(), share, token)
+	if err != nil {
+		return nil, err
+	}
+	return item.(*Wishlist), nil
+}
+
+// RemoveWishlist removes a wishlist
+func (w *wishlistStore) RemoveWishlist(wishlistID string) error {
+	return w.db.Remove(table.GetName(), id, wishlistID)
+}
+
+// AddAlert stores a back in stock alert, replacing the previous alert of the user for the same product
+func (w *wishlistStore) AddAlert(alert *StockAlert) error {
+	return w.db.Write(alertTable.GetName(), alert)
+}
+
+// GetAlerts returns all the back in stock alerts
+func (w *wishlistStore) GetAlerts() ([]*StockAlert, error) {
+	rows, err := w.db.ReadAll(alertTable.GetName(), id+"_prefix", "")
+	if err != nil {
+		return nil, err
+	}
+	alerts := make([]*StockAlert, 0, len(rows))
+	for _, row := range rows {
+		alerts = append(alerts, row.(*StockAlert))
+	}
+	return alerts, nil
+}
+
+// RemoveAlert removes a back in stock alert
+func (w *wishlistStore) RemoveAlert(alertID string) error {
+	return w.db.Remove(alertTable.GetName(), id, alertID)
+}
+
+type wishlistLogger struct {
+	log  logger
+	next WishlistStore
+}
+
+func (w *wishlistLogger) SetWishlist(wishlist *Wishlist) error {
+	var err error
+	defer func() {
+		if err != nil {
+			w.log.Debugf("could not store wishlist %s err: %s", wishlist.ID, err.Error())
+			return
+		}
+		w.log.Debugw("stored wishlist", "id", wishlist.ID, "user", wishlist.UserID, "products", wishlist.Products)
+	}()
+
+	err = w.next.SetWishlist(wishlist)
+	return err
+}
+
+func (w *wishlistLogger) GetWishlist(wishlistID string) (*Wishlist, error) {
+	var err error
+	var wishlist *Wishlist
+	defer func() {
+		if err != nil {
+			w.log.Debugf("could not retrieve wishlist %s err: %s", wishlistID, err.Error())
+			return
+		}
+		w.log.Debugf("retrieved wishlist %s", wishlistID)
+	}()
+
+	wishlist, err = w.next.GetWishlist(wishlistID)
+	return wishlist, err
+}
+
+func (w *wishlistLogger) GetWishlistsForUser(userID string) ([]*Wishlist, error) {
+	var err error
+	var wishlists []*Wishlist
+	defer func() {
+		if err != nil {
+			w.log.Debugf("could not retrieve the wishlists of user %s err: %s", userID, err.Error())
+			return
+		}
+		w.log.Debugf("retrieved %d wishlists of user %s", len(wishlists), userID)
+	}()
+
+	wishlists, err = w.next.GetWishlistsForUser(userID)
+	return wishlists, err
+}
+
+func (w *wishlistLogger) GetSharedWishlist(token string) (*Wishlist, error) {
+	var err error
+	var wishlist *Wishlist
+	defer func() {
+		if err != nil {
+			w.log.Debugf("could not retrieve shared wishlist err: %s", err.Error())
+			return
+		}
+		w.log.Debugf("retrieved shared wishlist %s", wishlist.ID)
+	}()
+
+	wishlist, err = w.next.GetSharedWishlist(token)
+	return wishlist, err
+}
+
+func (w *wishlistLogger) RemoveWishlist(wishlistID string) error {
+	var err error
+	defer func() {
+		if err != nil {
+			w.log.Debugf("could not remove wishlist %s err: %s", wishlistID, err.Error())
+			return
+		}
+		w.log.Debugf("removed wishlist %s", wishlistID)
+	}()
+
+	err = w.next.RemoveWishlist(wishlistID)
+	return err
+}
+
+func (w *wishlistLogger) AddAlert(alert *StockAlert) error {
+	var err error
+	defer func() {
+		if err != nil {
+			w.log.Debugf("could not add back in stock alert %s err: %s", alert.ID, err.Error())
+			return
+		}
+		w.log.Debugf("added back in stock alert %s", alert.ID)
+	}()
+
+	err = w.next.AddAlert(alert)
+	return err
+}
+
+func (w *wishlistLogger) GetAlerts() ([]*StockAlert, error) {
+	var err error
+	var alerts []*StockAlert
+	defer func() {
+		if err != nil {
+			w.log.Debugf("could not retrieve back in stock alerts err: %s", err.Error())
+			return
+		}
+		w.log.Debugf("retrieved %d back in stock alerts", len(alerts))
+	}()
+
+	alerts, err = w.next.GetAlerts()
+	return alerts, err
+}
+
+func (w *wishlistLogger) RemoveAlert(alertID string) error {
+	var err error
+	defer func() {
+		if err != nil {
+			w.log.Debugf("could not remove back in stock alert %s err: %s", alertID, err.Error())
+			return
+		}
+		w.log.Debugf("removed back in stock alert %s", alertID)
+	}()
+
+	err = w.next.RemoveAlert(alertID)
+	return err
+}
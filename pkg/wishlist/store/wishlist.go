@@ -0,0 +1,64 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+type errors []error
+
+func (e errors) Error() string {
+	b := bytes.NewBufferString("")
+	for _, err := range e {
+		_, _ = fmt.Fprintf(b, "\t%s\n", err)
+	}
+	return b.String()
+}
+
+// Wishlist is a named list of products a user wants to keep without holding them in the cart
+type Wishlist struct {
+	ID       string        `json:"id"`
+	UserID   string        `json:"-"`
+	Name     string        `json:"name"`
+	Products map[uint]uint `json:"products"`
+	// ShareToken gives public, read only, access to the wishlist. Empty when the wishlist is private
+	ShareToken string    `json:"shareToken,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Validate checks that a wishlist adheres to constraints
+func (w Wishlist) Validate() error {
+	var errs errors
+	if w.ID == "" {
+		errs = append(errs, fmt.Errorf("ID cannot be empty"))
+	}
+	if w.UserID == "" {
+		errs = append(errs, fmt.Errorf("user ID cannot be empty"))
+	}
+	if w.Name == "" {
+		errs = append(errs, fmt.Errorf("name is mandatory"))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// StockAlert asks for the user to be notified when an out of stock product is available again
+type StockAlert struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"userID"`
+	ProductID uint      `json:"productID"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// NewStockAlert creates the alert of a user for a product. A user has at most one alert per product
+func NewStockAlert(userID string, productID uint, createdAt time.Time) *StockAlert {
+	return &StockAlert{
+		ID:        fmt.Sprintf("%s/%d", userID, productID),
+		UserID:    userID,
+		ProductID: productID,
+		CreatedAt: createdAt,
+	}
+}
@@ -0,0 +1,39 @@
+package wishlist
+
+import (
+	netHTTP "net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mimatache/go-shop/internal/logger"
+
+	"github.com/mimatache/go-shop/pkg/wishlist/http"
+	"github.com/mimatache/go-shop/pkg/wishlist/store"
+	"github.com/mimatache/go-shop/pkg/wishlist/wishlist"
+)
+
+// NewAPI instantiates a new wishlist API
+func NewAPI(
+	logger logger.Logger,
+	inventory wishlist.InventoryAPI,
+	carts wishlist.CartAPI,
+	db store.UnderlyingStore,
+	router *mux.Router,
+	userHandler func(netHTTP.Handler) netHTTP.Handler,
+) *wishlist.Wishlists {
+	wishlistStore := store.New(logger, db)
+	wishlists := wishlist.New(wishlistStore, inventory, carts)
+	wishlistAPI := http.New(wishlists)
+	wishlistAPI.AddRoutes(router, userHandler)
+	return wishlists
+}
+
+// NewWatcher instantiates the watcher that notifies users when wishlisted products are back in stock
+func NewWatcher(
+	logger logger.Logger,
+	inventory wishlist.InventoryAPI,
+	db store.UnderlyingStore,
+	notifier wishlist.Notifier,
+) *wishlist.Watcher {
+	return wishlist.NewWatcher(logger, store.New(logger, db), inventory, notifier)
+}
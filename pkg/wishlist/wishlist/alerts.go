@@ -0,0 +1,149 @@
+package wishlist
+
+import (
+	"context"
+	"time"
+
+	"github.com/mimatache/go-shop/internal/outbox"
+	internalStore "github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/wishlist/store"
+)
+
+//go:generate mockgen -source ./alerts.go -destination mocks/alerts.go
+
+type logger interface {
+	Infof(msg string, args ...interface{})
+	Errorf(msg string, args ...interface{})
+}
+
+// Notifier delivers back in stock events to the users
+type Notifier interface {
+	Notify(event *BackInStock) error
+}
+
+// BackInStock is emitted when a wishlisted product that was out of stock becomes available
+type BackInStock struct {
+	UserID    string `json:"userID"`
+	ProductID uint   `json:"productID"`
+	Stock     uint   `json:"stock"`
+	// Wishlists are the names of the wishlists of the user holding the product
+	Wishlists []string  `json:"wishlists"`
+	Time      time.Time `json:"time"`
+}
+
+// NewFileOutbox creates a notifier that appends every event to a local outbox file,
+// from where it can be picked up and delivered by a separate process
+func NewFileOutbox(path string) (Notifier, error) {
+	file, err := outbox.NewFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &fileOutbox{file: file}, nil
+}
+
+type fileOutbox struct {
+	file *outbox.File
+}
+
+// Notify appends the event to the outbox file
+func (f *fileOutbox) Notify(event *BackInStock) error {
+	return f.file.Append(event)
+}
+
+// NewWatcher creates a watcher for the back in stock alerts
+func NewWatcher(log logger, storage store.WishlistStore, inventory InventoryAPI, notifier Notifier) *Watcher {
+	return &Watcher{
+		log:       log,
+		storage:   storage,
+		inventory: inventory,
+		notifier:  notifier,
+		now:       time.Now,
+	}
+}
+
+// Watcher notifies users when the out of stock products in their wishlists become available
+type Watcher struct {
+	log       logger
+	storage   store.WishlistStore
+	inventory InventoryAPI
+	notifier  Notifier
+	now       func() time.Time
+}
+
+// Run starts a go routine that checks the alerts at every interval, until the context is done
+func (w *Watcher) Run(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := w.Scan(); err != nil {
+					w.log.Errorf("could not check back in stock alerts:\n%s", err)
+				}
+			}
+		}
+	}()
+}
+
+// Scan goes once over the alerts. Users are notified of the products that are in stock again, after which the
+// alert is removed. Alerts for products that are no longer sold, or no longer in any wishlist of the user,
+// are removed without notifying
+func (w *Watcher) Scan() error {
+	alerts, err := w.storage.GetAlerts()
+	if err != nil {
+		return err
+	}
+	var errs errors
+	for _, alert := range alerts {
+		if err := w.check(alert); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (w *Watcher) check(alert *store.StockAlert) error {
+	wishlists, err := w.storage.GetWishlistsForUser(alert.UserID)
+	if err != nil {
+		return err
+	}
+	names := []string{}
+	for _, wishlist := range wishlists {
+		if _, ok := wishlist.Products[alert.ProductID]; ok {
+			names = append(names, wishlist.Name)
+		}
+	}
+	if len(names) == 0 {
+		return w.storage.RemoveAlert(alert.ID)
+	}
+
+	stock, err := w.inventory.GetProductStock(alert.ProductID)
+	if err != nil {
+		if internalStore.IsNotFoundError(err) {
+			return w.storage.RemoveAlert(alert.ID)
+		}
+		return err
+	}
+	if stock == 0 {
+		return nil
+	}
+
+	err = w.notifier.Notify(&BackInStock{
+		UserID:    alert.UserID,
+		ProductID: alert.ProductID,
+		Stock:     stock,
+		Wishlists: names,
+		Time:      w.now(),
+	})
+	if err != nil {
+		return err
+	}
+	w.log.Infof("product %d is back in stock for user %s", alert.ProductID, alert.UserID)
+	return w.storage.RemoveAlert(alert.ID)
+}
@@ -0,0 +1,83 @@
+package wishlist_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	internalStore "github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/wishlist/store"
+	mock_store "github.com/mimatache/go-shop/pkg/wishlist/store/mocks"
+	"github.com/mimatache/go-shop/pkg/wishlist/wishlist"
+	mock_wishlist "github.com/mimatache/go-shop/pkg/wishlist/wishlist/mocks"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Infof(msg string, args ...interface{})  {}
+func (nopLogger) Errorf(msg string, args ...interface{}) {}
+
+func newWatcher(t *testing.T) (*wishlist.Watcher, *mock_store.MockWishlistStore, *mock_wishlist.MockInventoryAPI, *mock_wishlist.MockNotifier, func()) {
+	ctrl := gomock.NewController(t)
+	storage := mock_store.NewMockWishlistStore(ctrl)
+	inventory := mock_wishlist.NewMockInventoryAPI(ctrl)
+	notifier := mock_wishlist.NewMockNotifier(ctrl)
+	return wishlist.NewWatcher(nopLogger{}, storage, inventory, notifier), storage, inventory, notifier, ctrl.Finish
+}
+
+func TestWatcher_Scan_BackInStock(t *testing.T) {
+	g := NewWithT(t)
+
+	watcher, storage, inventory, notifier, finish := newWatcher(t)
+	defer finish()
+
+	alert := store.NewStockAlert(userID, 2, now())
+	storage.EXPECT().GetAlerts().Return([]*store.StockAlert{alert}, nil)
+	storage.EXPECT().GetWishlistsForUser(userID).Return([]*store.Wishlist{stored(map[uint]uint{2: 1})}, nil)
+	inventory.EXPECT().GetProductStock(uint(2)).Return(uint(3), nil)
+	notifier.EXPECT().Notify(gomock.Any()).DoAndReturn(func(event *wishlist.BackInStock) error {
+		g.Expect(event.UserID).To(Equal(userID))
+		g.Expect(event.Stock).To(Equal(uint(3)))
+		g.Expect(event.Wishlists).To(ConsistOf("birthday"))
+		return nil
+	})
+	storage.EXPECT().RemoveAlert(alert.ID).Return(nil)
+
+	g.Expect(watcher.Scan()).To(Succeed())
+}
+
+func TestWatcher_Scan_StillOutOfStock(t *testing.T) {
+	g := NewWithT(t)
+
+	watcher, storage, inventory, _, finish := newWatcher(t)
+	defer finish()
+
+	storage.EXPECT().GetAlerts().Return([]*store.StockAlert{store.NewStockAlert(userID, 2, now())}, nil)
+	storage.EXPECT().GetWishlistsForUser(userID).Return([]*store.Wishlist{stored(map[uint]uint{2: 1})}, nil)
+	inventory.EXPECT().GetProductStock(uint(2)).Return(uint(0), nil)
+
+	g.Expect(watcher.Scan()).To(Succeed())
+}
+
+func TestWatcher_Scan_DropsStaleAlerts(t *testing.T) {
+	g := NewWithT(t)
+
+	watcher, storage, inventory, _, finish := newWatcher(t)
+	defer finish()
+
+	removed := store.NewStockAlert(userID, 2, now())
+	discontinued := store.NewStockAlert(userID, 3, now())
+	storage.EXPECT().GetAlerts().Return([]*store.StockAlert{removed, discontinued}, nil)
+	storage.EXPECT().GetWishlistsForUser(userID).Return([]*store.Wishlist{stored(map[uint]uint{3: 1})}, nil).Times(2)
+	inventory.EXPECT().GetProductStock(uint(3)).Return(uint(0), internalStore.NewNotFoundError("products", "id", 3))
+	storage.EXPECT().RemoveAlert(removed.ID).Return(nil)
+	storage.EXPECT().RemoveAlert(discontinued.ID).Return(nil)
+
+	g.Expect(watcher.Scan()).To(Succeed())
+}
+
+func now() time.Time {
+	return time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./wishlist.go
+
+// Package mock_wishlist is a generated GoMock package.
+package mock_wishlist
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	cart "github.com/mimatache/go-shop/pkg/cart/cart"
+	reflect "reflect"
+)
+
+// MockInventoryAPI is a mock of InventoryAPI interface
+type MockInventoryAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockInventoryAPIMockRecorder
+}
+
+// MockInventoryAPIMockRecorder is the mock recorder for MockInventoryAPI
+type MockInventoryAPIMockRecorder struct {
+	mock *MockInventoryAPI
+}
+
+// NewMockInventoryAPI creates a new mock instance
+func NewMockInventoryAPI(ctrl *gomock.Controller) *MockInventoryAPI {
+	mock := &MockInventoryAPI{ctrl: ctrl}
+	mock.recorder = &MockInventoryAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockInventoryAPI) EXPECT() *MockInventoryAPIMockRecorder {
+	return m.recorder
+}
+
+// GetProductStock mocks base method
+func (m *MockInventoryAPI) GetProductStock(productID uint) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductStock", productID)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductStock indicates an expected call of GetProductStock
+func (mr *MockInventoryAPIMockRecorder) GetProductStock(productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductStock", reflect.TypeOf((*MockInventoryAPI)(nil).GetProductStock), productID)
+}
+
+// MockCartAPI is a mock of CartAPI interface
+type MockCartAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockCartAPIMockRecorder
+}
+
+// MockCartAPIMockRecorder is the mock recorder for MockCartAPI
+type MockCartAPIMockRecorder struct {
+	mock *MockCartAPI
+}
+
+// NewMockCartAPI creates a new mock instance
+func NewMockCartAPI(ctrl *gomock.Controller) *MockCartAPI {
+	mock := &MockCartAPI{ctrl: ctrl}
+	mock.recorder = &MockCartAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCartAPI) EXPECT() *MockCartAPIMockRecorder {
+	return m.recorder
+}
+
+// AddProductToCart mocks base method
+func (m *MockCartAPI) AddProductToCart(userID string, prod cart.Product) (*cart.Contents, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddProductToCart", userID, prod)
+	ret0, _ := ret[0].(*cart.Contents)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddProductToCart indicates an expected call of AddProductToCart
+func (mr *MockCartAPIMockRecorder) AddProductToCart(userID, prod interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProductToCart", reflect.TypeOf((*MockCartAPI)(nil).AddProductToCart), userID, prod)
+}
+
+// RemoveProductFromCart mocks base method
+func (m *MockCartAPI) RemoveProductFromCart(userID string, prod cart.Product) (*cart.Contents, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveProductFromCart", userID, prod)
+	ret0, _ := ret[0].(*cart.Contents)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveProductFromCart indicates an expected call of RemoveProductFromCart
+func (mr *MockCartAPIMockRecorder) RemoveProductFromCart(userID, prod interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveProductFromCart", reflect.TypeOf((*MockCartAPI)(nil).RemoveProductFromCart), userID, prod)
+}
@@ -0,0 +1,371 @@
+package wishlist
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	internalStore "github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/cart/cart"
+	"github.com/mimatache/go-shop/pkg/wishlist/store"
+)
+
+type errors []error
+
+func (e errors) Error() string {
+	b := bytes.NewBufferString("")
+	for _, err := range e {
+		_, _ = fmt.Fprintf(b, "\t%s\n", err)
+	}
+	return b.String()
+}
+
+//go:generate mockgen -source ./wishlist.go -destination mocks/wishlist.go
+
+const (
+	idBytes    = 8
+	tokenBytes = 16
+)
+
+// InventoryAPI represents the methods that need to be implemented by the inventory API
+type InventoryAPI interface {
+	// GetProductStock returns the quantity of an item left in stock
+	GetProductStock(productID uint) (uint, error)
+}
+
+// CartAPI represents the methods that need to be implemented by the cart API
+type CartAPI interface {
+	// AddProductToCart adds a product to the cart of the user
+	AddProductToCart(userID string, prod cart.Product) (*cart.Contents, error)
+	// RemoveProductFromCart takes a product out of the cart of the user
+	RemoveProductFromCart(userID string, prod cart.Product) (*cart.Contents, error)
+}
+
+type invalidWishlist struct {
+	msg string
+}
+
+func (i invalidWishlist) Error() string {
+	return i.msg
+}
+
+// IsInvalidWishlistError verifies if a given error refers to a request that cannot be applied to a wishlist
+func IsInvalidWishlistError(err error) bool {
+	switch err.(type) {
+	case invalidWishlist:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewInvalidWishlist creates a new invalid wishlist error
+func NewInvalidWishlist(msg string, args ...interface{}) error {
+	return invalidWishlist{msg: fmt.Sprintf(msg, args...)}
+}
+
+// Wishlist represents a wishlist as shown to users
+type Wishlist struct {
+	ID         string          `json:"id,omitempty"`
+	Name       string          `json:"name"`
+	Products   []*cart.Product `json:"products"`
+	ShareToken string          `json:"shareToken,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+// NewWishlistView converts a stored wishlist to the shape shown to users. Public views, shown to the people a
+// wishlist is shared with, do not identify the wishlist
+func NewWishlistView(wishlist *store.Wishlist, public bool) *Wishlist {
+	view := &Wishlist{
+		Name:      wishlist.Name,
+		Products:  []*cart.Product{},
+		CreatedAt: wishlist.CreatedAt,
+	}
+	if !public {
+		view.ID = wishlist.ID
+		view.ShareToken = wishlist.ShareToken
+	}
+	for prodID, quantity := range wishlist.Products {
+		view.Products = append(view.Products, &cart.Product{ID: prodID, Quantity: quantity})
+	}
+	sort.Slice(view.Products, func(i, j int) bool {
+		return view.Products[i].ID < view.Products[j].ID
+	})
+	return view
+}
+
+// New creates the wishlists API
+func New(storage store.WishlistStore, inventory InventoryAPI, carts CartAPI) *Wishlists {
+	return &Wishlists{
+		storage:   storage,
+		inventory: inventory,
+		carts:     carts,
+		now:       time.Now,
+	}
+}
+
+// Wishlists manages the wishlists of the users
+type Wishlists struct {
+	storage   store.WishlistStore
+	inventory InventoryAPI
+	carts     CartAPI
+	now       func() time.Time
+	sync.Mutex
+}
+
+// Create adds a new, private, wishlist for the user. The names of the wishlists of a user are unique
+func (w *Wishlists) Create(userID string, name string) (*Wishlist, error) {
+	w.Lock()
+	defer w.Unlock()
+	if name == "" {
+		return nil, NewInvalidWishlist("name is mandatory")
+	}
+	wishlists, err := w.storage.GetWishlistsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, wishlist := range wishlists {
+		if wishlist.Name == name {
+			return nil, NewInvalidWishlist("a wishlist named %s already exists", name)
+		}
+	}
+	wishlistID, err := newRandom(idBytes)
+	if err != nil {
+		return nil, err
+	}
+	wishlist := &store.Wishlist{
+		ID:        wishlistID,
+		UserID:    userID,
+		Name:      name,
+		Products:  map[uint]uint{},
+		CreatedAt: w.now(),
+	}
+	err = w.storage.SetWishlist(wishlist)
+	if err != nil {
+		return nil, err
+	}
+	return NewWishlistView(wishlist, false), nil
+}
+
+// List returns the wishlists of the user, ordered by creation time
+func (w *Wishlists) List(userID string) ([]*Wishlist, error) {
+	wishlists, err := w.storage.GetWishlistsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(wishlists, func(i, j int) bool {
+		return wishlists[i].CreatedAt.Before(wishlists[j].CreatedAt)
+	})
+	views := make([]*Wishlist, 0, len(wishlists))
+	for _, wishlist := range wishlists {
+		views = append(views, NewWishlistView(wishlist, false))
+	}
+	return views, nil
+}
+
+// Get returns a wishlist of the user
+func (w *Wishlists) Get(userID string, wishlistID string) (*Wishlist, error) {
+	wishlist, err := w.get(userID, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+	return NewWishlistView(wishlist, false), nil
+}
+
+// Delete removes a wishlist of the user
+func (w *Wishlists) Delete(userID string, wishlistID string) error {
+	w.Lock()
+	defer w.Unlock()
+	_, err := w.get(userID, wishlistID)
+	if err != nil {
+		return err
+	}
+	return w.storage.RemoveWishlist(wishlistID)
+}
+
+// AddProduct adds a product to a wishlist of the user. When the product is out of stock, the user is notified
+// once it is available again
+func (w *Wishlists) AddProduct(userID string, wishlistID string, prod cart.Product) (*Wishlist, error) {
+	w.Lock()
+	defer w.Unlock()
+	return w.addProduct(userID, wishlistID, prod)
+}
+
+// RemoveProduct removes a product from a wishlist of the user
+func (w *Wishlists) RemoveProduct(userID string, wishlistID string, productID uint) (*Wishlist, error) {
+	w.Lock()
+	defer w.Unlock()
+	wishlist, err := w.get(userID, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := wishlist.Products[productID]; !ok {
+		return nil, internalStore.NewNotFoundError(store.GetTable().GetName(), "product", productID)
+	}
+	updated := copyWishlist(wishlist)
+	delete(updated.Products, productID)
+	err = w.storage.SetWishlist(updated)
+	if err != nil {
+		return nil, err
+	}
+	return NewWishlistView(updated, false), nil
+}
+
+// Share makes a wishlist readable by anyone holding its share token
+func (w *Wishlists) Share(userID string, wishlistID string) (*Wishlist, error) {
+	w.Lock()
+	defer w.Unlock()
+	wishlist, err := w.get(userID, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+	if wishlist.ShareToken != "" {
+		return NewWishlistView(wishlist, false), nil
+	}
+	updated := copyWishlist(wishlist)
+	updated.ShareToken, err = newRandom(tokenBytes)
+	if err != nil {
+		return nil, err
+	}
+	err = w.storage.SetWishlist(updated)
+	if err != nil {
+		return nil, err
+	}
+	return NewWishlistView(updated, false), nil
+}
+
+// Unshare makes a wishlist private again. Previous share tokens can no longer be used
+func (w *Wishlists) Unshare(userID string, wishlistID string) (*Wishlist, error) {
+	w.Lock()
+	defer w.Unlock()
+	wishlist, err := w.get(userID, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+	updated := copyWishlist(wishlist)
+	updated.ShareToken = ""
+	err = w.storage.SetWishlist(updated)
+	if err != nil {
+		return nil, err
+	}
+	return NewWishlistView(updated, false), nil
+}
+
+// GetShared returns the public view of the wishlist shared under the token
+func (w *Wishlists) GetShared(token string) (*Wishlist, error) {
+	if token == "" {
+		return nil, internalStore.NewNotFoundError(store.GetTable().GetName(), "share token", token)
+	}
+	wishlist, err := w.storage.GetSharedWishlist(token)
+	if err != nil {
+		return nil, err
+	}
+	return NewWishlistView(wishlist, true), nil
+}
+
+// MoveToCart moves a quantity of a product from a wishlist of the user into the cart
+func (w *Wishlists) MoveToCart(userID string, wishlistID string, prod cart.Product) (*cart.Contents, error) {
+	w.Lock()
+	defer w.Unlock()
+	wishlist, err := w.get(userID, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+	quantity := wishlist.Products[prod.ID]
+	if prod.Quantity == 0 || prod.Quantity > quantity {
+		return nil, NewInvalidWishlist("cannot move %d of product %d, the wishlist holds %d", prod.Quantity, prod.ID, quantity)
+	}
+	contents, err := w.carts.AddProductToCart(userID, prod)
+	if err != nil {
+		return nil, err
+	}
+	updated := copyWishlist(wishlist)
+	if quantity == prod.Quantity {
+		delete(updated.Products, prod.ID)
+	} else {
+		updated.Products[prod.ID] = quantity - prod.Quantity
+	}
+	err = w.storage.SetWishlist(updated)
+	if err != nil {
+		return nil, err
+	}
+	return contents, nil
+}
+
+// SaveForLater moves a quantity of a product from the cart of the user into a wishlist
+func (w *Wishlists) SaveForLater(userID string, wishlistID string, prod cart.Product) (*Wishlist, error) {
+	w.Lock()
+	defer w.Unlock()
+	if prod.Quantity == 0 {
+		return nil, NewInvalidWishlist("quantity is mandatory")
+	}
+	_, err := w.get(userID, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = w.carts.RemoveProductFromCart(userID, prod)
+	if err != nil {
+		return nil, err
+	}
+	return w.addProduct(userID, wishlistID, prod)
+}
+
+func (w *Wishlists) addProduct(userID string, wishlistID string, prod cart.Product) (*Wishlist, error) {
+	if prod.Quantity == 0 {
+		return nil, NewInvalidWishlist("quantity is mandatory")
+	}
+	wishlist, err := w.get(userID, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+	stock, err := w.inventory.GetProductStock(prod.ID)
+	if err != nil {
+		return nil, err
+	}
+	updated := copyWishlist(wishlist)
+	updated.Products[prod.ID] += prod.Quantity
+	err = w.storage.SetWishlist(updated)
+	if err != nil {
+		return nil, err
+	}
+	if stock == 0 {
+		err = w.storage.AddAlert(store.NewStockAlert(userID, prod.ID, w.now()))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return NewWishlistView(updated, false), nil
+}
+
+// get returns a wishlist, as long as it belongs to the user. The wishlists of other users are reported as missing
+func (w *Wishlists) get(userID string, wishlistID string) (*store.Wishlist, error) {
+	wishlist, err := w.storage.GetWishlist(wishlistID)
+	if err != nil {
+		return nil, err
+	}
+	if wishlist.UserID != userID {
+		return nil, internalStore.NewNotFoundError(store.GetTable().GetName(), "id", wishlistID)
+	}
+	return wishlist, nil
+}
+
+func copyWishlist(wishlist *store.Wishlist) *store.Wishlist {
+	updated := *wishlist
+	updated.Products = map[uint]uint{}
+	for k, v := range wishlist.Products {
+		updated.Products[k] = v
+	}
+	return &updated
+}
+
+func newRandom(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,175 @@
+package wishlist_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	internalStore "github.com/mimatache/go-shop/internal/store"
+	"github.com/mimatache/go-shop/pkg/cart/cart"
+	"github.com/mimatache/go-shop/pkg/wishlist/store"
+	mock_store "github.com/mimatache/go-shop/pkg/wishlist/store/mocks"
+	"github.com/mimatache/go-shop/pkg/wishlist/wishlist"
+	mock_wishlist "github.com/mimatache/go-shop/pkg/wishlist/wishlist/mocks"
+)
+
+const (
+	userID     = "user@email.com"
+	wishlistID = "list"
+)
+
+type mocks struct {
+	storage   *mock_store.MockWishlistStore
+	inventory *mock_wishlist.MockInventoryAPI
+	carts     *mock_wishlist.MockCartAPI
+}
+
+func newWishlists(t *testing.T) (*wishlist.Wishlists, *mocks, func()) {
+	ctrl := gomock.NewController(t)
+	m := &mocks{
+		storage:   mock_store.NewMockWishlistStore(ctrl),
+		inventory: mock_wishlist.NewMockInventoryAPI(ctrl),
+		carts:     mock_wishlist.NewMockCartAPI(ctrl),
+	}
+	return wishlist.New(m.storage, m.inventory, m.carts), m, ctrl.Finish
+}
+
+func stored(products map[uint]uint) *store.Wishlist {
+	return &store.Wishlist{ID: wishlistID, UserID: userID, Name: "birthday", Products: products}
+}
+
+func TestWishlists_Create_DuplicateName(t *testing.T) {
+	g := NewWithT(t)
+
+	wishlists, m, finish := newWishlists(t)
+	defer finish()
+
+	m.storage.EXPECT().GetWishlistsForUser(userID).Return([]*store.Wishlist{stored(nil)}, nil)
+
+	_, err := wishlists.Create(userID, "birthday")
+
+	g.Expect(wishlist.IsInvalidWishlistError(err)).To(BeTrue())
+}
+
+func TestWishlists_Get_OtherUser(t *testing.T) {
+	g := NewWithT(t)
+
+	wishlists, m, finish := newWishlists(t)
+	defer finish()
+
+	m.storage.EXPECT().GetWishlist(wishlistID).Return(stored(nil), nil)
+
+	_, err := wishlists.Get("other@email.com", wishlistID)
+
+	g.Expect(internalStore.IsNotFoundError(err)).To(BeTrue(), "wishlists of other users should not be visible")
+}
+
+func TestWishlists_AddProduct_OutOfStock(t *testing.T) {
+	g := NewWithT(t)
+
+	wishlists, m, finish := newWishlists(t)
+	defer finish()
+
+	m.storage.EXPECT().GetWishlist(wishlistID).Return(stored(map[uint]uint{1: 1}), nil)
+	m.inventory.EXPECT().GetProductStock(uint(2)).Return(uint(0), nil)
+	m.storage.EXPECT().SetWishlist(gomock.Any()).Return(nil)
+	m.storage.EXPECT().AddAlert(gomock.Any()).DoAndReturn(func(alert *store.StockAlert) error {
+		g.Expect(alert.UserID).To(Equal(userID))
+		g.Expect(alert.ProductID).To(Equal(uint(2)))
+		return nil
+	})
+
+	updated, err := wishlists.AddProduct(userID, wishlistID, cart.Product{ID: 2, Quantity: 1})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(updated.Products).To(Equal([]*cart.Product{{ID: 1, Quantity: 1}, {ID: 2, Quantity: 1}}))
+}
+
+func TestWishlists_MoveToCart(t *testing.T) {
+	g := NewWithT(t)
+
+	wishlists, m, finish := newWishlists(t)
+	defer finish()
+
+	m.storage.EXPECT().GetWishlist(wishlistID).Return(stored(map[uint]uint{1: 3}), nil)
+	m.carts.EXPECT().AddProductToCart(userID, cart.Product{ID: 1, Quantity: 2}).Return(&cart.Contents{}, nil)
+	m.storage.EXPECT().SetWishlist(gomock.Any()).DoAndReturn(func(w *store.Wishlist) error {
+		g.Expect(w.Products).To(Equal(map[uint]uint{1: 1}))
+		return nil
+	})
+
+	_, err := wishlists.MoveToCart(userID, wishlistID, cart.Product{ID: 1, Quantity: 2})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+}
+
+func TestWishlists_MoveToCart_MoreThanWishlisted(t *testing.T) {
+	g := NewWithT(t)
+
+	wishlists, m, finish := newWishlists(t)
+	defer finish()
+
+	m.storage.EXPECT().GetWishlist(wishlistID).Return(stored(map[uint]uint{1: 1}), nil)
+
+	_, err := wishlists.MoveToCart(userID, wishlistID, cart.Product{ID: 1, Quantity: 2})
+
+	g.Expect(wishlist.IsInvalidWishlistError(err)).To(BeTrue())
+}
+
+func TestWishlists_MoveToCart_KeepsWishlistOnCartError(t *testing.T) {
+	g := NewWithT(t)
+
+	wishlists, m, finish := newWishlists(t)
+	defer finish()
+
+	m.storage.EXPECT().GetWishlist(wishlistID).Return(stored(map[uint]uint{1: 1}), nil)
+	m.carts.EXPECT().AddProductToCart(userID, cart.Product{ID: 1, Quantity: 1}).Return(nil, cart.NewInvalidQuantity(1, 1, 0))
+
+	_, err := wishlists.MoveToCart(userID, wishlistID, cart.Product{ID: 1, Quantity: 1})
+
+	g.Expect(err).Should(HaveOccurred())
+}
+
+func TestWishlists_SaveForLater(t *testing.T) {
+	g := NewWithT(t)
+
+	wishlists, m, finish := newWishlists(t)
+	defer finish()
+
+	m.storage.EXPECT().GetWishlist(wishlistID).Return(stored(map[uint]uint{}), nil).Times(2)
+	m.carts.EXPECT().RemoveProductFromCart(userID, cart.Product{ID: 1, Quantity: 2}).Return(&cart.Contents{}, nil)
+	m.inventory.EXPECT().GetProductStock(uint(1)).Return(uint(5), nil)
+	m.storage.EXPECT().SetWishlist(gomock.Any()).Return(nil)
+
+	updated, err := wishlists.SaveForLater(userID, wishlistID, cart.Product{ID: 1, Quantity: 2})
+
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(updated.Products).To(Equal([]*cart.Product{{ID: 1, Quantity: 2}}))
+}
+
+func TestWishlists_Share(t *testing.T) {
+	g := NewWithT(t)
+
+	wishlists, m, finish := newWishlists(t)
+	defer finish()
+
+	var shared *store.Wishlist
+	m.storage.EXPECT().GetWishlist(wishlistID).Return(stored(map[uint]uint{1: 1}), nil)
+	m.storage.EXPECT().SetWishlist(gomock.Any()).DoAndReturn(func(w *store.Wishlist) error {
+		shared = w
+		return nil
+	})
+
+	view, err := wishlists.Share(userID, wishlistID)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(view.ShareToken).ToNot(BeEmpty())
+
+	m.storage.EXPECT().GetSharedWishlist(view.ShareToken).Return(shared, nil)
+
+	public, err := wishlists.GetShared(view.ShareToken)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(public.ID).To(BeEmpty())
+	g.Expect(public.ShareToken).To(BeEmpty())
+	g.Expect(public.Products).To(HaveLen(1))
+}